@@ -0,0 +1,58 @@
+// Package main provides a CLI tool that writes synthetic bank-export CSVs in
+// the layouts of major banks, for testing the loader's column mappings.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"budget2/internal/models"
+	"budget2/internal/testutil"
+)
+
+func main() {
+	out := flag.String("out", "testdata/banks", "Directory to write the CSV files to")
+	days := flag.Int("days", 90, "Days of transactions per file")
+	bank := flag.String("bank", "", "Only write this bank's layout (chase, bofa, amex, fidelity)")
+	flag.Parse()
+
+	exports := testutil.BankExports
+	if *bank != "" {
+		b, ok := testutil.FindBankExport(*bank)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Unknown bank %q\n", *bank)
+			os.Exit(1)
+		}
+		exports = []testutil.BankExport{b}
+	}
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", *out, err)
+		os.Exit(1)
+	}
+
+	transactions := testutil.SyntheticTransactions(*days)
+	for _, b := range exports {
+		path := filepath.Join(*out, b.Name+".csv")
+		if err := writeExport(path, b, transactions); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %s (%s, %d transactions)\n", path, b.Title, len(transactions))
+	}
+}
+
+// writeExport writes transactions to path in b's layout
+func writeExport(path string, b testutil.BankExport, transactions []models.Transaction) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := b.Write(f, transactions); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -1,13 +1,14 @@
 package main
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/fs"
 	"log"
 	"net/http"
 	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,14 +17,54 @@ import (
 	"golang.org/x/term"
 
 	"budget2/internal/config"
+	"budget2/internal/handlers/accounts"
+	"budget2/internal/handlers/auth"
 	"budget2/internal/handlers/backup"
+	"budget2/internal/handlers/budgets"
 	"budget2/internal/handlers/dashboard"
 	"budget2/internal/handlers/explorer"
 	"budget2/internal/handlers/insights"
+	"budget2/internal/handlers/networth"
+	"budget2/internal/handlers/rules"
+	"budget2/internal/handlers/status"
+	"budget2/internal/handlers/system"
 	"budget2/internal/handlers/whatif"
+	apphttp "budget2/internal/http"
+	"budget2/internal/models"
+	useraccounts "budget2/internal/services/accounts"
+	"budget2/internal/services/amazon"
+	"budget2/internal/services/banksync"
+	"budget2/internal/services/benchmarks"
+	categorybudgets "budget2/internal/services/budgets"
+	"budget2/internal/services/categories"
+	"budget2/internal/services/cohorts"
+	"budget2/internal/services/columnmaps"
 	"budget2/internal/services/dataloader"
+	"budget2/internal/services/giving"
+	"budget2/internal/services/monthclose"
+	"budget2/internal/services/mqtt"
+	accountbalances "budget2/internal/services/networth"
+	"budget2/internal/services/notify"
+	"budget2/internal/services/overrides"
+	"budget2/internal/services/planned"
+	"budget2/internal/services/relocation"
 	"budget2/internal/services/retirement"
+	"budget2/internal/services/routestats"
+	categoryrules "budget2/internal/services/rules"
+	"budget2/internal/services/savings"
+	"budget2/internal/services/sessions"
+	"budget2/internal/services/sheets"
+	"budget2/internal/services/signs"
+	"budget2/internal/services/splits"
+	"budget2/internal/services/statements"
 	"budget2/internal/services/storage"
+	"budget2/internal/services/subscriptions"
+	"budget2/internal/services/tags"
+	"budget2/internal/services/tlscert"
+	"budget2/internal/services/txstore"
+	"budget2/internal/services/visits"
+	"budget2/internal/services/warmup"
+	"budget2/internal/services/watchlist"
 	"budget2/internal/templates"
 	"budget2/internal/version"
 	"budget2/web"
@@ -35,6 +76,12 @@ var (
 	loader        *dataloader.DataLoader
 	renderer      *templates.Renderer
 	retirementMgr *retirement.SettingsManager
+	warmer        *warmup.Warmer
+	publisher     *mqtt.Publisher
+	notifier      *notify.Notifier
+	bankSync      *banksync.Service
+	sheetSync     *sheets.Service
+	routeStats    *routestats.Recorder
 )
 
 // SetupDependencies initializes all global dependencies with the given config.
@@ -44,6 +91,16 @@ func SetupDependencies(c *config.Config) error {
 
 	// Initialize data loader with storage
 	loader = dataloader.New(cfg.DataDirectory, store)
+	loader.SetDedupeMode(cfg.DedupeMode)
+	loader.SetStrict(cfg.StrictLoading)
+	if cfg.TransactionDB != "" {
+		db, err := txstore.Open(cfg.TransactionDB)
+		if err != nil {
+			log.Printf("Warning: %v; data files will be parsed on every load", err)
+		} else {
+			loader.SetTransactionStore(db)
+		}
+	}
 
 	// Initialize template renderer
 	var err error
@@ -60,15 +117,186 @@ func SetupDependencies(c *config.Config) error {
 	}
 
 	// Initialize retirement settings manager with storage
-	settingsDir := filepath.Join(cfg.DataDirectory, "settings")
+	settingsDir := cfg.SettingsDirectory
 	retirementMgr = retirement.NewSettingsManager(settingsDir, store)
+	cancellations := subscriptions.NewTracker(settingsDir, store)
+	lastVisits := visits.NewTracker(settingsDir, store)
+	baselines := benchmarks.NewManager(settingsDir, store)
+	closes := monthclose.NewManager(settingsDir, store)
+	cycles := statements.NewManager(settingsDir, store)
+	donations := giving.NewManager(settingsDir, store)
+	savedCohorts := cohorts.NewManager(settingsDir, store)
+	savingsPlan := savings.NewManager(settingsDir, store)
+	relocations := relocation.NewManager(settingsDir, store)
+	readiness := retirement.NewReadinessHistory(settingsDir, store)
+	watched := watchlist.NewManager(settingsDir, store)
+	categoryBudgets := categorybudgets.NewManager(settingsDir, store)
+	plannedPurchases := planned.NewManager(settingsDir, store)
+	styles := categories.NewRegistry(settingsDir, store)
+	amazonOrders := amazon.NewManager(settingsDir, store)
+	categoryRules := categoryrules.NewManager(settingsDir, store)
+	categoryOverrides := overrides.NewManager(settingsDir, store)
+	signConventions := signs.NewManager(settingsDir, store)
+	columnMappings := columnmaps.NewManager(settingsDir, store)
+	transactionSplits := splits.NewManager(settingsDir, store)
+	transactionTags := tags.NewManager(settingsDir, store)
+	userAccounts := useraccounts.NewManager(settingsDir, store)
+	balances := accountbalances.NewManager(settingsDir, store)
+	loader.SetSignOverrides(signConventions)
+	loader.SetColumnMappings(columnMappings)
+	loader.SetAccounts(userAccounts)
+	loader.AddCategorizer(categoryRules)
+	loader.AddCategorizer(categoryOverrides)
+	loader.AddEnricher(amazonOrders)
+	loader.AddEnricher(transactionTags)
+	loader.AddEnricher(transactionSplits)
+	categories.SetDefault(styles)
+	loader.OnLoad(func(ts *models.TransactionSet) {
+		if err := styles.Assign(ts.Categories()); err != nil {
+			log.Printf("Warning: failed to assign category styles: %v", err)
+		}
+	})
+
+	// Login is on when a username is set, and then needs a password
+	if cfg.AuthUsername != "" && cfg.AuthPassword == "" {
+		return fmt.Errorf("BUDGET_AUTH_USERNAME is set without BUDGET_AUTH_PASSWORD")
+	}
+	// HTTPS needs both halves of the key pair
+	if (cfg.TLSCert == "") != (cfg.TLSKey == "") {
+		return fmt.Errorf("BUDGET_TLS_CERT and BUDGET_TLS_KEY must be set together")
+	}
+
+	auth.Initialize(renderer, sessions.NewStore(time.Duration(cfg.SessionHours)*time.Hour), cfg.AuthUsername, cfg.AuthPassword)
+
+	// Route analytics keep the latest requests in memory
+	routeStats = nil
+	if cfg.RouteStats > 0 {
+		routeStats = routestats.NewRecorder(cfg.RouteStats)
+	}
+	system.Initialize(renderer, routeStats)
+
+	// Bank sync runs only when a provider is configured
+	bankSync = nil
+	var providers []banksync.Provider
+	if cfg.SimpleFINAccessURL != "" {
+		providers = append(providers, &banksync.SimpleFIN{AccessURL: cfg.SimpleFINAccessURL})
+	}
+	if cfg.PlaidClientID != "" && len(cfg.PlaidAccessTokens) > 0 {
+		providers = append(providers, &banksync.Plaid{
+			ClientID:     cfg.PlaidClientID,
+			Secret:       cfg.PlaidSecret,
+			AccessTokens: cfg.PlaidAccessTokens,
+			Environment:  cfg.PlaidEnvironment,
+		})
+	}
+	if len(providers) > 0 {
+		bankSync = banksync.NewService(banksync.Config{
+			DataDir:     cfg.DataDirectory,
+			SettingsDir: settingsDir,
+			Interval:    time.Duration(cfg.BankSyncHours) * time.Hour,
+			Providers:   providers,
+		}, store, loader)
+	}
+
+	// Google Sheets import runs only when sheets and credentials are configured
+	sheetSync = nil
+	if len(cfg.GoogleSheets) > 0 {
+		client := &sheets.Client{APIKey: cfg.GoogleAPIKey}
+		if cfg.GoogleCredentialsFile != "" {
+			data, err := os.ReadFile(cfg.GoogleCredentialsFile)
+			if err != nil {
+				return fmt.Errorf("reading BUDGET_GOOGLE_CREDENTIALS: %w", err)
+			}
+			if client.ServiceAccount, err = sheets.ParseServiceAccount(data); err != nil {
+				return err
+			}
+		}
+		if client.APIKey == "" && client.ServiceAccount == nil {
+			log.Printf("Warning: BUDGET_GOOGLE_SHEETS is set without BUDGET_GOOGLE_API_KEY or BUDGET_GOOGLE_CREDENTIALS; not importing sheets")
+		} else {
+			sheetSync = sheets.NewService(sheets.Config{
+				DataDir:     cfg.DataDirectory,
+				SettingsDir: settingsDir,
+				Interval:    time.Duration(cfg.GoogleSheetsMinutes) * time.Minute,
+				Sheets:      cfg.GoogleSheets,
+				Range:       cfg.GoogleSheetsRange,
+			}, client, store, loader)
+		}
+	}
+
+	// Budget cycle, weekly for variable income
+	cycle := models.BudgetCycle{Cycle: cfg.BudgetCycle, WeekStart: time.Monday, WeeklyBudget: cfg.WeeklyBudget}
+	if day, ok := models.ParseWeekday(cfg.WeekStart); ok {
+		cycle.WeekStart = day
+	} else if cfg.WeekStart != "" {
+		log.Printf("Warning: unknown week start %q, weeks start on Monday", cfg.WeekStart)
+	}
 
 	// Initialize handler packages
-	dashboard.Initialize(loader, renderer)
-	explorer.Initialize(loader, renderer, cfg, store)
-	whatif.Initialize(loader, renderer, retirementMgr)
-	insights.Initialize(loader, renderer)
-	backup.Initialize(cfg, store)
+	dashboard.Initialize(loader, renderer, lastVisits, watched, categoryBudgets, balances, userAccounts, cfg.SparklineMonths, cfg.MaxAlerts, cycle)
+	explorer.Initialize(loader, renderer, cfg, store, styles, closes, amazonOrders, categoryOverrides, signConventions, transactionSplits, transactionTags, userAccounts, columnMappings, bankSync, sheetSync)
+	whatif.Initialize(loader, renderer, retirementMgr, savingsPlan, relocations, readiness)
+	insights.Initialize(loader, renderer, cancellations, baselines, closes, cycles, donations, savedCohorts, retirementMgr,
+		models.TrendThresholds{MinSpend: cfg.TrendMinSpend, MinShare: cfg.TrendMinShare}, cfg.MonthlyBudget, cycle)
+	status.Initialize(loader, watched, cfg.StatusToken, cfg.MonthlyBudget)
+	rules.Initialize(loader, renderer, categoryRules)
+	budgets.Initialize(loader, renderer, categoryBudgets, plannedPurchases)
+	accounts.Initialize(loader, renderer, userAccounts)
+	networth.Initialize(loader, renderer, balances, userAccounts)
+
+	// MQTT publishing starts with the server, only when a broker is set
+	if cfg.MQTTBroker != "" {
+		publisher = mqtt.NewPublisher(mqtt.Config{
+			Broker: mqtt.BrokerConfig{
+				Address:  cfg.MQTTBroker,
+				ClientID: cfg.MQTTClientID,
+				Username: cfg.MQTTUsername,
+				Password: cfg.MQTTPassword,
+			},
+			Topic:           cfg.MQTTTopic,
+			DiscoveryPrefix: cfg.MQTTDiscovery,
+			Interval:        time.Duration(cfg.MQTTIntervalSec) * time.Second,
+			MonthlyBudget:   cfg.MonthlyBudget,
+		}, loader, watched)
+	}
+
+	// Alert notifications start with the server, only when a webhook is set
+	if cfg.AlertWebhookURL != "" {
+		notifier = notify.NewNotifier(notify.Config{
+			URL:      cfg.AlertWebhookURL,
+			Format:   cfg.AlertWebhookFormat,
+			Interval: time.Duration(cfg.AlertWebhookIntervalSec) * time.Second,
+		}, loader, settingsDir, store)
+	}
+
+	// Warm-up work runs after the server starts listening; without it the
+	// warmer reports ready straight away. The scheduled refresh warms up
+	// with the same steps it repeats, so its results never expire.
+	loadData := warmup.Step{Name: "data", Run: func() error {
+		_, err := loader.LoadData()
+		return err
+	}}
+	var steps, refresh []warmup.Step
+	if cfg.RefreshMinutes > 0 {
+		refresh = []warmup.Step{
+			loadData,
+			{Name: "dashboard", Run: dashboard.Refresh},
+			{Name: "insights", Run: insights.Refresh},
+		}
+		steps = append(slices.Clone(refresh), warmup.Step{Name: "whatif", Run: whatif.Warm})
+	} else if cfg.WarmStart {
+		steps = []warmup.Step{
+			loadData,
+			{Name: "dashboard", Run: dashboard.Warm},
+			{Name: "insights", Run: insights.Warm},
+			{Name: "whatif", Run: whatif.Warm},
+		}
+	}
+	warmer = warmup.New(steps...)
+	if len(refresh) > 0 {
+		warmer.Refresh(time.Duration(cfg.RefreshMinutes)*time.Minute, refresh...)
+	}
+	backup.Initialize(cfg, store, loader, warmer, renderer)
 
 	return nil
 }
@@ -78,8 +306,12 @@ func SetupDependencies(c *config.Config) error {
 func SetupRouter() chi.Router {
 	r := chi.NewRouter()
 
-	// Middleware
+	// Middleware; proxies go first so the log shows the real client
+	r.Use(apphttp.TrustProxies(cfg.TrustedProxies))
 	r.Use(middleware.Logger)
+	if routeStats != nil {
+		r.Use(routeStats.Middleware)
+	}
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Compress(5))
 
@@ -99,30 +331,67 @@ func SetupRouter() chi.Router {
 
 	r.Handle("/static/*", http.StripPrefix("/static/", fileServer))
 
-	// Root redirect
-	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		http.Redirect(w, r, "/dashboard", http.StatusTemporaryRedirect)
+	// Reachable without logging in: health checks, the login page, and the
+	// status endpoints, which check their own token
+	r.Get("/api/health", backup.HandleHealth)
+	auth.RegisterRoutes(r)
+	status.RegisterRoutes(r)
+
+	// JSON API, behind the API keys when any are configured and otherwise
+	// the login
+	r.Group(func(api chi.Router) {
+		if len(cfg.APIKeys) > 0 {
+			api.Use(apphttp.RequireAPIKey(cfg.APIKeys))
+		} else {
+			api.Use(auth.RequireLogin)
+		}
+		dashboard.RegisterAPIRoutes(api)
+		explorer.RegisterAPIRoutes(api)
+		insights.RegisterAPIRoutes(api)
+		whatif.RegisterAPIRoutes(api)
+		budgets.RegisterAPIRoutes(api)
 	})
 
-	// Register handler packages
-	dashboard.RegisterRoutes(r)
-	explorer.RegisterRoutes(r)
-	whatif.RegisterRoutes(r)
-	insights.RegisterRoutes(r)
-
-	// Health and control endpoints
-	r.Get("/api/health", backup.HandleHealth)
-	r.Get("/api/version", handleVersion)
-	r.Get("/killme", backup.HandleKillServer)
+	// Everything else needs a login when one is configured
+	r.Group(func(r chi.Router) {
+		r.Use(auth.RequireLogin)
+
+		// Root redirect
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/dashboard", http.StatusTemporaryRedirect)
+		})
+
+		// Register handler packages
+		dashboard.RegisterRoutes(r)
+		explorer.RegisterRoutes(r)
+		whatif.RegisterRoutes(r)
+		insights.RegisterRoutes(r)
+		rules.RegisterRoutes(r)
+		budgets.RegisterRoutes(r)
+		accounts.RegisterRoutes(r)
+		networth.RegisterRoutes(r)
+		system.RegisterRoutes(r)
+
+		// Control endpoints
+		r.Get("/api/version", handleVersion)
+		r.Get("/api/data/version", handleDataVersion)
+		r.Get("/killme", backup.HandleKillServer)
+
+		// Profiling is opt-in: profiles expose internals and cost CPU
+		if cfg.Pprof {
+			r.Mount("/debug", middleware.Profiler())
+		}
 
-	// File manager page
-	r.Get("/filemanager", explorer.HandleFileManagerPage)
+		// File manager page
+		r.Get("/filemanager", explorer.HandleFileManagerPage)
 
-	// Backup and restore routes
-	r.Get("/backup", backup.HandleBackup)
-	r.Post("/restore", backup.HandleRestore)
-	r.Post("/restore/test-data", backup.HandleRestoreTestData)
-	r.Delete("/data/all", backup.HandleDeleteAllData)
+		// Backup and restore routes
+		r.Get("/backup", backup.HandleBackup)
+		r.Post("/restore", backup.HandleRestore)
+		r.Post("/restore/verify", backup.HandleVerifyBackup)
+		r.Post("/restore/test-data", backup.HandleRestoreTestData)
+		r.Delete("/data/all", backup.HandleDeleteAllData)
+	})
 
 	return r
 }
@@ -141,8 +410,24 @@ func main() {
 	log.Printf("Data directory: %s", c.DataDirectory)
 
 	// Initialize storage
-	var err error
-	store, err = storage.New(c.DataDirectory)
+	backend, err := storage.NewBackend(c.DataDirectory, storage.BackendOptions{
+		Type:           c.StorageBackend,
+		S3Endpoint:     c.S3Endpoint,
+		S3Region:       c.S3Region,
+		S3Bucket:       c.S3Bucket,
+		S3Prefix:       c.S3Prefix,
+		S3AccessKey:    c.S3AccessKey,
+		S3SecretKey:    c.S3SecretKey,
+		WebDAVURL:      c.WebDAVURL,
+		WebDAVUser:     c.WebDAVUser,
+		WebDAVPassword: c.WebDAVPassword,
+	})
+	if err != nil {
+		log.Fatalf("FATAL: Failed to initialize storage backend: %v", err)
+	}
+	log.Printf("Storage backend: %s", c.StorageBackend)
+
+	store, err = storage.NewWithBackend(c.DataDirectory, backend)
 	if err != nil {
 		log.Fatalf("FATAL: Failed to initialize storage: %v", err)
 	}
@@ -157,8 +442,24 @@ func main() {
 		log.Printf("Encrypted storage unlocked successfully")
 	}
 
+	// "budget2 ingest" updates the transaction database and exits
+	if len(os.Args) > 1 && os.Args[1] == "ingest" {
+		os.Exit(runIngest(c))
+	}
+
+	// Generate a certificate when asked to serve HTTPS without one
+	if c.TLSSelfSigned {
+		generated, err := tlscert.EnsureSelfSigned(c.TLSCert, c.TLSKey)
+		if err != nil {
+			log.Fatalf("FATAL: Failed to create self-signed certificate: %v", err)
+		}
+		if generated {
+			log.Printf("Generated self-signed certificate %s", c.TLSCert)
+		}
+	}
+
 	// Kill any previous instance running on this port
-	killPreviousInstance(c.ListenAddr)
+	killPreviousInstance(c.ListenAddr, c.TLSCert != "")
 
 	// Setup dependencies
 	if err := SetupDependencies(c); err != nil {
@@ -168,9 +469,59 @@ func main() {
 	// Setup router
 	r := SetupRouter()
 
+	// Warm caches in the background while the server starts, then keep
+	// them current if a refresh interval is set
+	if cfg.RefreshMinutes > 0 {
+		log.Printf("Refreshing dashboard and insights every %d minutes", cfg.RefreshMinutes)
+		warmer.Start()
+	} else if cfg.WarmStart {
+		log.Printf("Warm-up enabled, preloading data and analyses")
+		warmer.Start()
+	}
+
+	// Watch local data files so page loads don't stat every file
+	if strings.EqualFold(cfg.StorageBackend, storage.BackendLocal) || cfg.StorageBackend == "" {
+		if _, err := loader.Watch(); err != nil {
+			log.Printf("Warning: not watching data directory, files will be checked on every load: %v", err)
+		}
+	}
+
+	// Publish metrics to MQTT as data changes
+	if publisher != nil {
+		log.Printf("Publishing metrics to MQTT broker %s", cfg.MQTTBroker)
+		publisher.Start()
+	}
+
+	// Post new spending alerts to a webhook as data changes
+	if notifier != nil {
+		log.Printf("Sending new spending alerts to a %s webhook", notifier.Format())
+		notifier.Start()
+	}
+
+	// Pull bank transactions on a schedule
+	if bankSync != nil {
+		log.Printf("Syncing transactions from %s every %d hours", strings.Join(bankSync.Providers(), " and "), cfg.BankSyncHours)
+		bankSync.Start()
+	}
+
+	// Import spending logs from Google Sheets on a schedule
+	if sheetSync != nil {
+		log.Printf("Importing %d Google Sheets every %d minutes", len(cfg.GoogleSheets), cfg.GoogleSheetsMinutes)
+		sheetSync.Start()
+	}
+
 	// Start server
+	server := &http.Server{
+		Addr:              cfg.ListenAddr,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if cfg.TLSCert != "" {
+		log.Printf("Server starting on %s (HTTPS)", cfg.ListenAddr)
+		log.Fatal(server.ListenAndServeTLS(cfg.TLSCert, cfg.TLSKey))
+	}
 	log.Printf("Server starting on %s", cfg.ListenAddr)
-	log.Fatal(http.ListenAndServe(cfg.ListenAddr, r))
+	log.Fatal(server.ListenAndServe())
 }
 
 // getEncryptionPassword prompts for or reads the encryption password
@@ -190,29 +541,106 @@ func getEncryptionPassword() string {
 	return string(password)
 }
 
+// runIngest parses new and changed data files into the transaction database,
+// returning the process exit code
+func runIngest(c *config.Config) int {
+	if c.TransactionDB == "" {
+		log.Printf("Transaction database is disabled (BUDGET_TRANSACTION_DB=off)")
+		return 1
+	}
+	db, err := txstore.Open(c.TransactionDB)
+	if err != nil {
+		log.Printf("%v (is the server running? it ingests changed files itself)", err)
+		return 1
+	}
+	defer db.Close()
+
+	ingestLoader := dataloader.New(c.DataDirectory, store)
+	ingestLoader.SetTransactionStore(db)
+	results, removed, err := ingestLoader.Ingest()
+	if err != nil {
+		log.Printf("Ingest failed: %v", err)
+		return 1
+	}
+
+	code := 0
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			fmt.Printf("%-40s error: %v\n", r.File, r.Err)
+			code = 1
+		case r.Parsed:
+			fmt.Printf("%-40s %6d transactions ingested\n", r.File, r.Transactions)
+		default:
+			fmt.Printf("%-40s %6d transactions, unchanged\n", r.File, r.Transactions)
+		}
+	}
+	fmt.Printf("%d files in %s, %d removed\n", len(results), c.TransactionDB, removed)
+	return code
+}
+
 // handleVersion returns version information as JSON
 func handleVersion(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(version.Get())
 }
 
+// handleDataVersion returns the loader's data version token so clients can
+// poll cheaply before fetching heavy partials. The token doubles as an ETag:
+// a matching If-None-Match gets 304 with no body.
+func handleDataVersion(w http.ResponseWriter, r *http.Request) {
+	dataVersion, err := loader.DataVersion()
+	if err != nil {
+		http.Error(w, "Error reading data version: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	etag := `"` + dataVersion + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"version": dataVersion})
+}
+
 // killPreviousInstance attempts to shut down any existing server on the same address
-func killPreviousInstance(addr string) {
+func killPreviousInstance(addr string, useTLS bool) {
 	// Build the killme URL
 	host := addr
 	if strings.HasPrefix(host, ":") {
 		host = "localhost" + host
 	}
-	killURL := fmt.Sprintf("http://%s/killme", host)
-
-	// Try to contact the existing server
-	client := &http.Client{Timeout: 2 * time.Second}
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	killURL := fmt.Sprintf("%s://%s/killme", scheme, host)
+
+	// Try to contact the existing server, without following a redirect to
+	// its login page. It is this same app, so its certificate (likely self
+	// signed) isn't checked.
+	client := &http.Client{
+		Timeout: 2 * time.Second,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
 	resp, err := client.Get(killURL)
 	if err != nil {
 		// No server running or not reachable - that's fine
 		return
 	}
 	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		// With login on, the old server won't shut down for an anonymous request
+		log.Printf("Warning: previous instance refused to shut down (status %d)", resp.StatusCode)
+		return
+	}
 
 	log.Printf("Sent shutdown signal to previous instance, waiting...")
 
@@ -220,7 +648,7 @@ func killPreviousInstance(addr string) {
 	for i := 0; i < 30; i++ {
 		time.Sleep(100 * time.Millisecond)
 		// Try to connect - if it fails, the old server is gone
-		resp, err := client.Get(fmt.Sprintf("http://%s/health", host))
+		resp, err := client.Get(fmt.Sprintf("%s://%s/health", scheme, host))
 		if err != nil {
 			log.Printf("Previous instance terminated")
 			return
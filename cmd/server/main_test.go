@@ -1,11 +1,28 @@
 package main
 
 import (
+	"archive/zip"
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"math"
+	"mime/multipart"
 	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
 
 	"budget2/internal/config"
+	"budget2/internal/models"
+	"budget2/internal/services/retirement"
 	"budget2/internal/services/storage"
 	"budget2/internal/testutil"
 )
@@ -21,9 +38,10 @@ func setupTestServer(t *testing.T) *testutil.TestServer {
 		Debug:              true,
 		DataDirectory:      testutil.TestDataDir(),
 		UploadsDirectory:   testutil.TestDataDir() + "/uploads",
-		SettingsDirectory:  testutil.TestDataDir() + "/settings",
+		SettingsDirectory:  testutil.SettingsDir(t),
 		TemplatesDirectory: root + "/web/templates",
 		StaticDirectory:    root + "/web/static",
+		StatusToken:        "status-test-token",
 	}
 
 	// Initialize storage (unencrypted for tests)
@@ -52,264 +70,2884 @@ func TestHealthEndpoint(t *testing.T) {
 	testutil.AssertResponse(t, resp).
 		StatusOK().
 		ContentTypeJSON().
-		Contains(`"status":"ok"`)
+		Contains(`"status":"ok"`).
+		Contains(`"ready":true`)
 }
 
-// TestRootRedirect tests that / redirects to /dashboard
-func TestRootRedirect(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
+// TestBackgroundRefresh tests that a refresh interval warms up and reports
+// the scheduled refresh in /api/health
+func TestBackgroundRefresh(t *testing.T) {
+	setupTestServer(t).Close()
 
-	// Don't follow redirects
-	client := &http.Client{
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
+	cfg.RefreshMinutes = 15
+	if err := SetupDependencies(cfg); err != nil {
+		t.Fatalf("Failed to setup dependencies: %v", err)
 	}
+	ts := testutil.NewTestServer(t, SetupRouter())
+	defer ts.Close()
 
-	resp, err := client.Get(ts.BaseURL + "/")
-	if err != nil {
-		t.Fatalf("GET / failed: %v", err)
-	}
-	defer resp.Body.Close()
+	resp := ts.GET("/api/health")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(`"ready":false`, `"interval":"15m0s"`).
+		NotContains(`"last_run"`)
 
-	if resp.StatusCode != http.StatusTemporaryRedirect {
-		t.Errorf("Expected status %d, got %d", http.StatusTemporaryRedirect, resp.StatusCode)
-	}
+	// Run the startup steps and one refresh in place of the background loop
+	warmer.Run()
+	warmer.RunRefresh()
 
-	location := resp.Header.Get("Location")
-	if location != "/dashboard" {
-		t.Errorf("Expected redirect to /dashboard, got %s", location)
+	resp = ts.GET("/api/health")
+	body := testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(`"ready":true`, `"last_run"`, `"name":"dashboard"`, `"name":"insights"`, `"name":"whatif"`).
+		Body()
+	if strings.Contains(body, `"error"`) {
+		t.Errorf("a refresh step failed: %s", body)
 	}
+
+	resp = ts.GET("/dashboard")
+	testutil.AssertResponse(t, resp).StatusOK()
+	resp = ts.GET("/insights")
+	testutil.AssertResponse(t, resp).StatusOK()
 }
 
-// TestDashboard tests the main dashboard page
-func TestDashboard(t *testing.T) {
+// TestDataVersionEndpoint tests the change detection token and its ETag
+func TestDataVersionEndpoint(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp := ts.GET("/dashboard")
-	testutil.AssertResponse(t, resp).
-		StatusOK().
-		ContentTypeHTML().
-		ContainsAll(
-			"Dashboard",
-			"Total Income",
-			"Total Expenses",
-			"Net Savings",
-		)
+	resp := ts.GET("/api/data/version")
+	var body map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if body["version"] == "" {
+		t.Fatal("expected a data version token")
+	}
+	etag := resp.Header.Get("ETag")
+	if etag != `"`+body["version"]+`"` {
+		t.Errorf("ETag = %q, want the quoted version %q", etag, body["version"])
+	}
+
+	req, _ := http.NewRequest("GET", ts.BaseURL+"/api/data/version", nil)
+	req.Header.Set("If-None-Match", etag)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("unchanged data should return 304, got %d", resp.StatusCode)
+	}
 }
 
-// TestDashboardKPIsPartial tests the KPIs partial endpoint
-func TestDashboardKPIsPartial(t *testing.T) {
+// TestCategoryStyles tests the category color registry is shared by charts
+// and templates and can be overridden
+func TestCategoryStyles(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp := ts.GET("/dashboard/kpis")
+	resp := ts.GET("/categories/styles")
 	testutil.AssertResponse(t, resp).
 		StatusOK().
 		ContentTypeHTML().
-		ContainsAll("Total Income", "Total Expenses")
-}
+		ContainsAll("Groceries", `type="color"`)
 
-// TestDashboardAlertsPartial tests the alerts partial endpoint
-func TestDashboardAlertsPartial(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
+	resp = ts.POST("/categories/styles", "application/x-www-form-urlencoded",
+		strings.NewReader("category=Groceries&color=%23123456&icon="))
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(`value="#123456"`, "Reset")
 
-	resp := ts.GET("/dashboard/alerts")
+	resp = ts.GET("/dashboard/charts/data/category")
 	testutil.AssertResponse(t, resp).
 		StatusOK().
-		ContentTypeHTML()
+		Contains(`"#123456"`)
+
+	resp = ts.POST("/categories/styles", "application/x-www-form-urlencoded",
+		strings.NewReader("category=Groceries&color=green"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
 }
 
-// TestDashboardChartData tests chart data endpoints
-func TestDashboardChartData(t *testing.T) {
+// TestSinceLastVisit tests the dashboard's changes panel
+func TestSinceLastVisit(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	chartTypes := []string{
-		"monthly",
-		"category",
-		"cashflow",
-		"merchants",
-		"weekly",
-		"cumulative",
+	// First visit has nothing to compare against
+	body := testutil.ReadBody(t, ts.GET("/dashboard/changes"))
+	if strings.Contains(body, "Since your last visit") {
+		t.Error("first visit should not show a changes panel")
 	}
 
-	for _, chartType := range chartTypes {
-		t.Run(chartType, func(t *testing.T) {
-			resp := ts.GET("/dashboard/charts/data/" + chartType)
-			testutil.AssertResponse(t, resp).
-				StatusOK().
-				ContentTypeJSON()
+	// Pretend the last visit was two days ago, before any data was loaded
+	visitsPath := filepath.Join(cfg.SettingsDirectory, "visits.json")
+	old := time.Now().Add(-48 * time.Hour).Format(time.RFC3339)
+	if err := os.WriteFile(visitsPath, []byte(`{"latest":{"taken_at":"`+old+`"}}`), 0644); err != nil {
+		t.Fatalf("failed to write visit log: %v", err)
+	}
 
-			// Verify it's valid JSON
-			body := testutil.ReadBody(t, resp)
-			var data interface{}
-			if err := json.Unmarshal([]byte(body), &data); err != nil {
-				t.Errorf("Invalid JSON for chart %s: %v", chartType, err)
-			}
-		})
+	resp := ts.GET("/dashboard/changes")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Since your last visit", "New transactions", "Mark as seen")
+
+	resp = ts.POST("/dashboard/changes/dismiss", "application/x-www-form-urlencoded", nil)
+	testutil.AssertResponse(t, resp).StatusOK()
+
+	body = testutil.ReadBody(t, ts.GET("/dashboard/changes"))
+	if strings.Contains(body, "Since your last visit") {
+		t.Error("changes should be cleared after marking as seen")
 	}
 }
 
-// TestExplorer tests the explorer page
-func TestExplorer(t *testing.T) {
+// TestMonthClose tests the month close checklist and locking
+func TestMonthClose(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp := ts.GET("/explorer")
+	form := "application/x-www-form-urlencoded"
+
+	resp := ts.GET("/insights/close")
 	testutil.AssertResponse(t, resp).
 		StatusOK().
-		ContentTypeHTML().
-		ContainsAll(
-			"Explorer",
-			"Search",
-			"Category",
-		)
-}
+		ContainsAll("Dec 2025", "Uncategorized cleared", "1 uncategorized", "Reconciled with statements")
 
-// TestExplorerTransactionsPartial tests the transactions partial
-func TestExplorerTransactionsPartial(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
+	// December still has an uncategorized transaction
+	resp = ts.POST("/insights/close/2025-12", form, nil)
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
 
-	resp := ts.GET("/explorer/transactions")
+	resp = ts.POST("/insights/close/2025-03/checklist", form, strings.NewReader("item=bogus&done=true"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+
+	for _, item := range []string{"reconciled", "budget_reviewed"} {
+		resp = ts.POST("/insights/close/2025-03/checklist", form, strings.NewReader("item="+item+"&done=true"))
+		testutil.AssertResponse(t, resp).StatusOK()
+	}
+
+	resp = ts.POST("/insights/close/2025-03", form, nil)
 	testutil.AssertResponse(t, resp).
 		StatusOK().
-		ContentTypeHTML()
+		ContainsAll("Closed", "Reopen")
+
+	// Closed months are locked
+	resp = ts.POST("/insights/close/2025-03/checklist", form, strings.NewReader("item=reconciled&done=false"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+
+	resp = ts.POST("/insights/close/2025-03/reopen", form, nil)
+	testutil.AssertResponse(t, resp).StatusOK()
+	resp = ts.POST("/insights/close/2025-03/checklist", form, strings.NewReader("item=reconciled&done=false"))
+	testutil.AssertResponse(t, resp).StatusOK()
 }
 
-// TestExplorerFiltering tests transaction filtering
-func TestExplorerFiltering(t *testing.T) {
+// TestMerchantWatchlist tests watched merchant limits and their alerts
+func TestMerchantWatchlist(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	tests := []struct {
-		name  string
-		query map[string]string
-	}{
-		{"search", map[string]string{"search": "ACME"}},
-		{"category", map[string]string{"category": "Groceries"}},
-		{"type-income", map[string]string{"type": "income"}},
-		{"type-expense", map[string]string{"type": "expense"}},
-		{"date-range", map[string]string{"start": "2025-01-01", "end": "2025-06-30"}},
-	}
+	form := "application/x-www-form-urlencoded"
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			resp := ts.GETWithQuery("/explorer/transactions", tt.query)
-			testutil.AssertResponse(t, resp).
-				StatusOK().
-				ContentTypeHTML()
-		})
-	}
+	resp := ts.GET("/dashboard/watchlist")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("No merchants watched")
+
+	resp = ts.POST("/dashboard/watchlist", form, strings.NewReader("name=Costco&limit=abc"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+
+	// Dec 2025 has $198.45 at Costco and $156.78 at Whole Foods
+	resp = ts.POST("/dashboard/watchlist", form, strings.NewReader("name=Costco&match=costco&limit=150"))
+	testutil.AssertResponse(t, resp).StatusOK()
+	resp = ts.POST("/dashboard/watchlist", form, strings.NewReader("name=Whole+Foods&limit=180"))
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Costco", "Whole Foods", "$198.45", "$156.78")
+
+	resp = ts.GET("/dashboard/alerts")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Costco Over Limit", "Whole Foods Near Limit")
 }
 
-// TestWhatIf tests the what-if analysis page
-func TestWhatIf(t *testing.T) {
+// TestBudgets tests setting category budgets and their dashboard alerts
+func TestBudgets(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp := ts.GET("/whatif")
+	form := "application/x-www-form-urlencoded"
+
+	resp := ts.GET("/budgets")
 	testutil.AssertResponse(t, resp).
 		StatusOK().
 		ContentTypeHTML().
-		ContainsAll(
-			"What-If",
-			"Portfolio Value",
-		)
+		ContainsAll("Budgets", "No budgets yet")
+
+	resp = ts.POST("/budgets", form, strings.NewReader("category=Groceries&limit=abc"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+	resp = ts.POST("/budgets", form, strings.NewReader("category=&limit=100"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+
+	// Dec 2025 has $355.23 of Groceries and $399.59 of Utilities
+	resp = ts.POST("/budgets", form, strings.NewReader("category=Groceries&limit=300"))
+	testutil.AssertResponse(t, resp).StatusOK()
+	resp = ts.POST("/budgets", form, strings.NewReader("category=Utilities&limit=450&threshold=85"))
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Dec 2025", "Groceries", "$355.23", "$55.23 over", "Utilities", "$399.59", "Alerts at 85%")
+
+	resp = ts.GET("/dashboard/alerts")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Groceries Over Budget", "Utilities Near Budget", "category=Groceries")
+
+	req, _ := http.NewRequest("DELETE", ts.BaseURL+"/budgets/Groceries", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("Utilities").
+		NotContains("Groceries</a>")
+
+	resp = ts.GET("/dashboard/alerts")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		NotContains("Groceries Over Budget")
 }
 
-// TestWhatIfProjectionChart tests the projection chart endpoint
-func TestWhatIfProjectionChart(t *testing.T) {
+// TestBudgetActualsAPI tests the month-by-month budget against actual
+// spending in the JSON API
+func TestBudgetActualsAPI(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp := ts.GET("/whatif/chart/projection")
+	resp := ts.GET("/api/v1/budgets/actuals")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON().
+		Contains(`"rows":[]`)
+
+	resp = ts.POST("/budgets", "application/x-www-form-urlencoded", strings.NewReader("category=Groceries&limit=300"))
+	testutil.AssertResponse(t, resp).StatusOK()
+
+	resp = ts.GET("/api/v1/budgets/actuals?start=2025-11-01&end=2025-12-31")
 	testutil.AssertResponse(t, resp).
 		StatusOK().
 		ContentTypeJSON()
+	var actuals models.BudgetActuals
+	if err := json.NewDecoder(resp.Body).Decode(&actuals); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(actuals.Rows) != 2 || actuals.Rows[0].Month != "2025-11" || actuals.TotalBudget != 600 {
+		t.Fatalf("actuals = %+v, want Groceries in Nov and Dec 2025", actuals)
+	}
+	// Dec 2025 has $355.23 of Groceries
+	if dec := actuals.Rows[1]; dec.Month != "2025-12" || dec.Category != "Groceries" || dec.Actual != 355.23 || dec.Variance != -55.23 || dec.Partial {
+		t.Errorf("December = %+v, want $355.23 spent, $55.23 over", dec)
+	}
+
+	resp = ts.GET("/api/v1/budgets/actuals?end=December")
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
 }
 
-// TestInsights tests the insights page
-func TestInsights(t *testing.T) {
+// TestPlannedPurchases tests planned purchases and their variance against
+// the receipts that match them
+func TestPlannedPurchases(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp := ts.GET("/insights")
+	form := "application/x-www-form-urlencoded"
+
+	resp := ts.GET("/budgets")
 	testutil.AssertResponse(t, resp).
 		StatusOK().
-		ContentTypeHTML().
-		ContainsAll(
-			"Insights",
-			"Recurring",
-		)
-}
+		ContainsAll("Planned Purchases", "No planned purchases yet")
 
-// TestInsightsRecurringPartial tests the recurring payments partial
-func TestInsightsRecurringPartial(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
+	resp = ts.POST("/budgets/planned", form, strings.NewReader("item=Tile&cost=abc&month=2025-10"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+	resp = ts.POST("/budgets/planned", form, strings.NewReader("item=Tile&cost=100&month=October"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
 
-	resp := ts.GET("/insights/recurring")
+	// CAR REPAIR ESTIMATE is $3,500 in Oct 2025 and HOLIDAY GIFTS $456.78 in
+	// Dec 2025; nothing matches the deck stain
+	resp = ts.POST("/budgets/planned", form, strings.NewReader("item=Car+repair&cost=3000&month=2025-10"))
+	testutil.AssertResponse(t, resp).StatusOK()
+	resp = ts.POST("/budgets/planned", form, strings.NewReader("item=Gifts&cost=500&month=2025-12&match=holiday&category=Shopping"))
+	testutil.AssertResponse(t, resp).StatusOK()
+	resp = ts.POST("/budgets/planned", form, strings.NewReader("item=Deck+stain&cost=150&month=2025-06&match=sherwin"))
 	testutil.AssertResponse(t, resp).
 		StatusOK().
-		ContentTypeHTML()
+		ContainsAll("Car repair", "+$500.00", "&#43;17%", "Gifts", "-$43.22", "-9%", "Deck stain", "No receipts",
+			"search=holiday", "category=Shopping", "start=2025-11-01", "end=2026-01-31")
+
+	resp = ts.GET("/budgets")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Car repair", "$3,650.00", "+$456.78")
+
+	// Purchases are listed by month, so the deck stain comes first
+	var id string
+	resp = ts.GET("/budgets/planned")
+	body := testutil.AssertResponse(t, resp).StatusOK().Body()
+	if i := strings.Index(body, `hx-delete="/budgets/planned/`); i >= 0 {
+		id = strings.SplitN(body[i+len(`hx-delete="/budgets/planned/`):], `"`, 2)[0]
+	}
+	if id == "" {
+		t.Fatal("no remove button for a planned purchase")
+	}
+
+	req, _ := http.NewRequest("DELETE", ts.BaseURL+"/budgets/planned/"+id, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("Car repair").
+		NotContains("Deck stain")
 }
 
-// TestInsightsTrendsPartial tests the trends partial
-func TestInsightsTrendsPartial(t *testing.T) {
+// TestWeeklyBudget tests the weekly budget cycle's dashboard card and
+// weekly burn-down
+func TestWeeklyBudget(t *testing.T) {
+	// The monthly cycle has no This Week card
 	ts := setupTestServer(t)
+	testutil.AssertResponse(t, ts.GET("/dashboard")).StatusOK().NotContains("This Week")
+	ts.Close()
+
+	cfg.BudgetCycle = "weekly"
+	cfg.WeeklyBudget = 500
+	if err := SetupDependencies(cfg); err != nil {
+		t.Fatalf("Failed to setup dependencies: %v", err)
+	}
+	ts = testutil.NewTestServer(t, SetupRouter())
 	defer ts.Close()
 
-	resp := ts.GET("/insights/trends")
+	testutil.AssertResponse(t, ts.GET("/dashboard")).
+		StatusOK().
+		ContainsAll("This Week", `hx-get="/dashboard/weekly"`, "chart-weekly-budget")
+
+	// The test data ends on Wednesday, Dec 31, 2025
+	testutil.AssertResponse(t, ts.GET("/dashboard/weekly")).
+		StatusOK().
+		ContainsAll("Smoothed Income", "weekly budget", "$500.00", "/explorer?start=2025-12-29&end=2026-01-04")
+
+	resp := ts.GET("/dashboard/charts/weekly-budget")
 	testutil.AssertResponse(t, resp).
 		StatusOK().
-		ContentTypeHTML()
+		ContentTypeJSON().
+		ContainsAll(`"name":"Spent"`, `"name":"Smoothed Income"`, `"name":"Target"`, `"2025-12-29"`)
+
+	testutil.AssertResponse(t, ts.GET("/insights/velocity/burndown")).
+		StatusOK().
+		ContainsAll(`"title":"Week of 2025-12-29"`, `"name":"Last Week"`, `"Mon"`, `"name":"80% Range"`, `"fill":"tonexty"`)
 }
 
-// TestInsightsTrendsChartData tests the trends chart data endpoint
-func TestInsightsTrendsChartData(t *testing.T) {
+// TestSavingsStrip tests the savings rate strip, which covers all data
+// whatever the date range
+func TestSavingsStrip(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp := ts.GET("/insights/trends/chart")
+	resp := ts.GET("/dashboard")
 	testutil.AssertResponse(t, resp).
 		StatusOK().
-		ContentTypeJSON()
+		Contains("/dashboard/charts/savings-strip")
+
+	resp = ts.GET("/dashboard/charts/savings-strip?start=2025-12-01&end=2025-12-31")
+	testutil.AssertResponse(t, resp).StatusOK().ContentTypeJSON()
+	var chart struct {
+		Data []struct {
+			Type string   `json:"type"`
+			X    []string `json:"x"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&chart); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(chart.Data) != 1 || chart.Data[0].Type != "heatmap" {
+		t.Fatalf("expected one heatmap trace, got %+v", chart.Data)
+	}
+	if months := chart.Data[0].X; len(months) < 12 || months[len(months)-1] != "2025-12" {
+		t.Errorf("months = %v, want the whole history through 2025-12", months)
+	}
 }
 
-// TestInsightsVelocityPartial tests the velocity partial
-func TestInsightsVelocityPartial(t *testing.T) {
+// TestUpcomingBills tests the bills forecast from recurring payments on the
+// dashboard calendar and the insights list
+func TestUpcomingBills(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp := ts.GET("/insights/velocity")
+	resp := ts.GET("/dashboard")
+	testutil.AssertResponse(t, resp).StatusOK().ContainsAll("Upcoming Bills", `hx-get="/dashboard/bills?sources=`)
+	resp = ts.GET("/insights")
+	testutil.AssertResponse(t, resp).StatusOK().Contains(`hx-get="/insights/bills?view=`)
+
+	// The test data ends 2025-12-31, so the forecast runs through January 30
+	resp = ts.GET("/dashboard/bills")
 	testutil.AssertResponse(t, resp).
 		StatusOK().
-		ContentTypeHTML()
+		ContainsAll("$1,926.97", "due by 2026-01-30", `title="rent payment apt 204 $1,850.00"`).
+		NotContains("Missed")
+
+	resp = ts.GET("/insights/bills")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Due by 2026-01-30", "2026-01-20", "rent payment apt 204", "netflix subscription", "As of 2025-12-31")
+
+	// A subscription marked cancelled drops out of the forecast, whichever
+	// card's wording it was cancelled under
+	resp = ts.POST("/insights/cancellations", "application/x-www-form-urlencoded",
+		strings.NewReader("description=NETFLIX+SUBSCRIPTION+866-579-7172&amount=15.99&frequency=monthly&cancelled_on=2025-12-31"))
+	testutil.AssertResponse(t, resp).StatusOK()
+	resp = ts.GET("/insights/bills")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("$1,910.98").
+		NotContains("netflix subscription")
 }
 
-// TestInsightsIncomePartial tests the income analysis partial
-func TestInsightsIncomePartial(t *testing.T) {
+// TestSavingsRateWaterfall tests explaining a savings rate change against
+// the comparison period
+func TestSavingsRateWaterfall(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp := ts.GET("/insights/income")
+	resp := ts.GET("/dashboard/kpi/savings-rate?start=2025-12-01&end=2025-12-31&comparison=month")
 	testutil.AssertResponse(t, resp).
 		StatusOK().
-		ContentTypeHTML()
+		ContainsAll("chart-savings-waterfall", "Income", "Utilities spending", "other categories")
+
+	resp = ts.GET("/dashboard/kpi/savings-rate?start=2025-12-01&end=2025-12-31")
+	testutil.AssertResponse(t, resp).StatusOK().NotContains("chart-savings-waterfall")
+
+	resp = ts.GET("/dashboard/charts/savings-waterfall?start=2025-12-01&end=2025-12-31&comparison=month")
+	testutil.AssertResponse(t, resp).StatusOK().ContentTypeJSON()
+	var chart struct {
+		Data []struct {
+			Type    string   `json:"type"`
+			X       []string `json:"x"`
+			Measure []string `json:"measure"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&chart); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(chart.Data) != 1 || chart.Data[0].Type != "waterfall" {
+		t.Fatalf("expected one waterfall trace, got %+v", chart.Data)
+	}
+	if x := chart.Data[0].X; len(x) < 3 || x[0] != "Previous rate" || x[1] != "Income" || x[len(x)-1] != "Current rate" {
+		t.Errorf("bars = %v, want previous rate, income, categories, current rate", x)
+	}
+
+	// No data before the history starts, so nothing to compare
+	resp = ts.GET("/dashboard/charts/savings-waterfall?start=2024-07-01&end=2024-07-31&comparison=month")
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
 }
 
-// TestFileManager tests the file manager page
-func TestFileManager(t *testing.T) {
+// TestNetWorth tests recording balances and the net worth page, API and KPI
+func TestNetWorth(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp := ts.GET("/explorer/files")
+	form := "application/x-www-form-urlencoded"
+
+	resp := ts.GET("/networth")
 	testutil.AssertResponse(t, resp).
 		StatusOK().
-		ContentTypeHTML()
+		ContentTypeHTML().
+		ContainsAll("Net Worth", "No balances yet")
+
+	resp = ts.GET("/dashboard")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		NotContains("Net Worth</p>")
+
+	resp = ts.POST("/networth/balances", form, strings.NewReader("account=Brokerage&date=2025-12-31&balance=lots"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+	resp = ts.POST("/networth/balances", form, strings.NewReader("account=Brokerage&date=12/31/2025&balance=100"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+
+	resp = ts.POST("/networth/balances", form, strings.NewReader("account=Brokerage&date=2025-11-30&balance=24000"))
+	testutil.AssertResponse(t, resp).StatusOK()
+	resp = ts.POST("/networth/balances", form, strings.NewReader("account=Brokerage&date=2025-12-31&balance=25000"))
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Net worth as of 2025-12-31", "$25,000.00", "Recorded balances (2)")
+
+	resp = ts.GET("/api/networth?date=2025-12-15")
+	testutil.AssertResponse(t, resp).StatusOK().ContentTypeJSON()
+	var nw models.NetWorth
+	if err := json.NewDecoder(resp.Body).Decode(&nw); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if nw.Total != 24000 || len(nw.Accounts) != 1 || nw.Accounts[0].Source != models.BalanceManual {
+		t.Errorf("net worth on 2025-12-15 = %+v, want the November balance", nw)
+	}
+
+	resp = ts.GET("/networth/chart")
+	testutil.AssertResponse(t, resp).StatusOK().ContentTypeJSON()
+
+	resp = ts.GET("/dashboard?start=2025-01-01&end=2025-12-31")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Net Worth</p>", "$25,000.00")
+
+	req, _ := http.NewRequest("DELETE", ts.BaseURL+"/networth/balances/Brokerage/2025-12-31", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Net worth as of 2025-11-30", "Recorded balances (1)")
+}
+
+// TestAccounts tests setting up an account and filtering views by account
+func TestAccounts(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	form := "application/x-www-form-urlencoded"
+
+	// Without set-up accounts, each file is an account named after it
+	resp := ts.GET("/accounts")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll("Accounts", "transactions_edge", "not set up")
+
+	resp = ts.POST("/accounts", form, strings.NewReader("name=Checking&type=brokerage"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+	resp = ts.POST("/accounts", form, strings.NewReader("name=&type=bank"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+
+	resp = ts.POST("/accounts", form, strings.NewReader("name=Household+Checking&type=bank&institution=Chase&files=transactions.csv"))
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Household Checking", "Chase", "transactions.csv")
+
+	resp = ts.GET("/dashboard")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(`name="account"`, `value="Household Checking"`, `value="transactions_edge"`)
+
+	resp = ts.GET("/explorer/transactions?search=payroll&account=household+checking")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("DIRECT DEP ACME CORP PAYROLL")
+
+	resp = ts.GET("/explorer/transactions?search=payroll&account=transactions_edge")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		NotContains("DIRECT DEP ACME CORP PAYROLL")
+
+	req, _ := http.NewRequest("DELETE", ts.BaseURL+"/accounts/Household%20Checking", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		NotContains("Household Checking")
+}
+
+// TestCategoryRules tests adding, applying, editing and removing category rules
+func TestCategoryRules(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	form := "application/x-www-form-urlencoded"
+
+	resp := ts.GET("/rules")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll("Category Rules", "No category rules yet")
+
+	resp = ts.POST("/rules", form, strings.NewReader("match=regex&pattern=SPOTIFY+(&category=Music"))
+	testutil.AssertResponse(t, resp).
+		Status(http.StatusBadRequest).
+		Contains("invalid regular expression")
+
+	resp = ts.POST("/rules", form, strings.NewReader("match=contains&pattern=spotify&max_amount=20&category=Music"))
+	body := testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("spotify", "Music", "up to $20.00", "18 transactions").
+		Body()
+
+	resp = ts.GET("/explorer/transactions?search=spotify")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("Music").
+		NotContains("Entertainment")
+
+	id := regexp.MustCompile(`hx-delete="/rules/([^"]+)"`).FindStringSubmatch(body)
+	if id == nil {
+		t.Fatal("rule ID not found in response")
+	}
+
+	req, _ := http.NewRequest("PUT", ts.BaseURL+"/rules/"+id[1], strings.NewReader("pattern=spotify&max_amount=5&category=Music"))
+	req.Header.Set("Content-Type", form)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PUT failed: %v", err)
+	}
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("up to $5.00", "No matches")
+
+	req, _ = http.NewRequest("DELETE", ts.BaseURL+"/rules/"+id[1], nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("No category rules yet")
+}
+
+// TestCategoryRulesImportExport tests sharing rules between installs as CSV
+// or JSON files
+func TestCategoryRulesImportExport(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	upload := func(name, content string, replace bool) *http.Response {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		if replace {
+			mw.WriteField("replace", "on")
+		}
+		part, _ := mw.CreateFormFile("file", name)
+		part.Write([]byte(content))
+		mw.Close()
+		return ts.POST("/rules/import", mw.FormDataContentType(), &body)
+	}
+
+	resp := upload("rules.csv", "Pattern,Category\nspotify,\n", false)
+	testutil.AssertResponse(t, resp).
+		Status(http.StatusBadRequest).
+		Contains("category is required")
+
+	resp = upload("rules.csv", "Category,Pattern,Max Amount\nMusic,spotify,20\nCoffee,blue bottle,\n", false)
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("spotify", "blue bottle", "&rarr; Music")
+
+	resp = ts.GET("/rules/export")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentType("text/csv").
+		Contains("Match,Pattern,Min Amount,Max Amount,Category\ncontains,spotify,,20,Music\ncontains,blue bottle,,,Coffee\n")
+
+	resp = ts.GET("/rules/export?format=json")
+	testutil.AssertResponse(t, resp).ContentTypeJSON()
+	exported := testutil.ReadBody(t, resp)
+
+	// Importing the same rules again adds nothing
+	resp = upload("rules.json", exported, false)
+	testutil.AssertResponse(t, resp).StatusOK()
+	resp = ts.GET("/rules/export?format=json")
+	var list []models.CategoryRule
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil || len(list) != 2 {
+		t.Errorf("rules after re-import = %d (%v), want 2", len(list), err)
+	}
+
+	resp = upload("rules.json", `[{"match":"regex","pattern":"^NETFLIX","category":"Streaming"}]`, true)
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("^NETFLIX").
+		NotContains("spotify")
+}
+
+// TestVerifyBackup tests checking a backup and previewing a restore without
+// changing any files
+func TestVerifyBackup(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	current, err := os.ReadFile(filepath.Join(testutil.TestDataDir(), "transactions.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	add := func(name, content string) {
+		// Stored uncompressed so a flipped byte fails only the checksum
+		f, _ := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		f.Write([]byte(content))
+	}
+	add("transactions.csv", string(current))
+	add("transactions_edge.csv", "Date,Description,Amount\n2025-01-01,CHANGED,-1\n")
+	add("new_bank.csv", "Date,Description,Amount\n2025-01-02,NEW,-2\n")
+	add("settings/budgets.json", `[{"category":"Groceries","amount":500}]`)
+	add("settings/category_rules.json", `{"rules":"not a list"}`)
+	add("settings/visits.json", `{"snapshots":[]}`)
+	add("settings/whatif.json", `{"schema_version":999}`)
+	add("settings/notifications.json", `{"thresholds":{"unusual_day":"high"}}`)
+	add("notes.txt", "hello")
+	add("corrupt.csv", "Date,Description,Amount\nCORRUPTME\n")
+	zw.Close()
+	data := bytes.Replace(archive.Bytes(), []byte("CORRUPTME"), []byte("CORRUPTED"), 1)
+
+	verify := func(path string) *http.Response {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, _ := mw.CreateFormFile("file", "budget_backup.zip")
+		part.Write(data)
+		mw.Close()
+		return ts.POST(path, mw.FormDataContentType(), &body)
+	}
+
+	resp := verify("/restore/verify")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("budget_backup.zip has 4 damaged files", "Restoring adds 1, replaces 1 and leaves 1",
+			"corrupt.csv", "checksum error", "category_rules.json schema", "new_bank.csv", "Restore anyway",
+			"settings schema version 999 is newer", "notifications.json schema",
+			"Settings checked but not restored: settings/budgets.json, settings/visits.json")
+
+	if _, err := os.Stat(filepath.Join(testutil.TestDataDir(), "new_bank.csv")); !os.IsNotExist(err) {
+		t.Error("verifying a backup should not restore any files")
+	}
+	if after, _ := os.ReadFile(filepath.Join(testutil.TestDataDir(), "transactions_edge.csv")); bytes.Contains(after, []byte("CHANGED")) {
+		t.Error("verifying a backup should not replace any files")
+	}
+
+	resp = ts.POST("/restore/verify", "multipart/form-data; boundary=x", strings.NewReader("--x--"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+}
+
+// TestBankSync tests syncing transactions from a SimpleFIN bridge into a
+// data file and showing the account's status in the file manager
+func TestBankSync(t *testing.T) {
+	ts := setupTestServer(t)
+	resp := ts.POST("/explorer/banksync", "application/x-www-form-urlencoded", nil)
+	testutil.AssertResponse(t, resp).Status(http.StatusNotFound)
+	ts.Close()
+
+	posted := time.Now().AddDate(0, 0, -3).Unix()
+	bridge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"errors": [], "accounts": [{"id": "ACT-1", "name": "Checking", "org": {"name": "Test Bank"},
+			"balance": "2048.00", "transactions": [{"id": "T1", "posted": %d, "amount": "-23.45", "description": "SYNCED CAFE"}]}]}`, posted)
+	}))
+	defer bridge.Close()
+
+	cfg.SimpleFINAccessURL = bridge.URL
+	if err := SetupDependencies(cfg); err != nil {
+		t.Fatalf("Failed to setup dependencies: %v", err)
+	}
+	t.Cleanup(func() {
+		os.Remove(filepath.Join(cfg.DataDirectory, "bank-test-bank-checking.csv"))
+	})
+	ts = testutil.NewTestServer(t, SetupRouter())
+	defer ts.Close()
+
+	resp = ts.GET("/filemanager")
+	testutil.AssertResponse(t, resp).StatusOK().ContainsAll("Bank Sync", "Waiting for the first sync")
+
+	// The sync redirects back to the file manager
+	resp = ts.POST("/explorer/banksync", "application/x-www-form-urlencoded", nil)
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("bank-test-bank-checking.csv", "Synced from SimpleFIN: Test Bank Checking, balance $2,048.00", "1 new").
+		NotContains("Waiting for the first sync")
+
+	resp = ts.GET("/explorer/transactions?search=synced+cafe")
+	testutil.AssertResponse(t, resp).StatusOK().Contains("SYNCED CAFE")
+}
+
+// TestGoogleSheets tests the Google Sheets import card; the import itself
+// is tested against a fake Sheets API in the sheets package
+func TestGoogleSheets(t *testing.T) {
+	ts := setupTestServer(t)
+	resp := ts.POST("/explorer/sheets", "application/x-www-form-urlencoded", nil)
+	testutil.AssertResponse(t, resp).Status(http.StatusNotFound)
+	ts.Close()
+
+	// Sheets without credentials leave the import off
+	cfg.GoogleSheets = []string{"https://docs.google.com/spreadsheets/d/1SpendLog_abc/edit#gid=0"}
+	if err := SetupDependencies(cfg); err != nil {
+		t.Fatalf("Failed to setup dependencies: %v", err)
+	}
+	if sheetSync != nil {
+		t.Error("expected no import without an API key or service account")
+	}
+
+	cfg.GoogleCredentialsFile = filepath.Join(t.TempDir(), "missing.json")
+	if err := SetupDependencies(cfg); err == nil {
+		t.Error("expected an error for a missing credentials file")
+	}
+
+	cfg.GoogleCredentialsFile = ""
+	cfg.GoogleAPIKey = "test-key"
+	cfg.GoogleSheetsMinutes = 30
+	if err := SetupDependencies(cfg); err != nil {
+		t.Fatalf("Failed to setup dependencies: %v", err)
+	}
+	ts = testutil.NewTestServer(t, SetupRouter())
+	defer ts.Close()
+
+	resp = ts.GET("/filemanager")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Google Sheets", "every 30 minutes", "https://docs.google.com/spreadsheets/d/1SpendLog_abc/edit", "Never imported")
+}
+
+// TestRouteStats tests the per-route request counts and latency page
+func TestRouteStats(t *testing.T) {
+	setupTestServer(t).Close()
+	cfg.RouteStats = 100
+	if err := SetupDependencies(cfg); err != nil {
+		t.Fatalf("Failed to setup dependencies: %v", err)
+	}
+	ts := testutil.NewTestServer(t, SetupRouter())
+	defer ts.Close()
+
+	ts.GET("/dashboard").Body.Close()
+	ts.GET("/dashboard").Body.Close()
+	ts.GET("/static/css/nonexistent.css").Body.Close()
+
+	// Static files aren't counted, nor is this page until it has rendered
+	resp := ts.GET("/system/routes")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Route Analytics", "GET /dashboard", "2 requests kept in memory", "routes without a request", "GET /budgets").
+		NotContains("GET /static/")
+
+	// hx-get isn't a URL attribute to html/template, so the route is
+	// escaped for the query by hand
+	resp = ts.GET("/system/routes?route=" + url.QueryEscape("GET /dashboard"))
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains(`hx-get="/system/routes/chart?route=GET%20%2Fdashboard"`)
+
+	resp = ts.GET("/system/routes/chart?route=" + url.QueryEscape("GET /dashboard"))
+	var chart map[string]interface{}
+	testutil.AssertResponse(t, resp).StatusOK().ContentTypeJSON()
+	if err := json.NewDecoder(resp.Body).Decode(&chart); err != nil {
+		t.Fatalf("chart isn't JSON: %v", err)
+	}
+	data, _ := chart["data"].([]interface{})
+	if len(data) != 2 {
+		t.Errorf("chart has %d traces, want 2", len(data))
+	}
+}
+
+// TestRecategorizeTransaction tests changing one transaction's category
+// from the explorer
+func TestRecategorizeTransaction(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	patch := func(path, body string) *http.Response {
+		req, _ := http.NewRequest("PATCH", ts.BaseURL+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("PATCH failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := ts.GET("/explorer/transactions?search=spotify")
+	body := testutil.AssertResponse(t, resp).
+		StatusOK().
+		NotContains(">edited<").
+		Body()
+	match := regexp.MustCompile(`hx-patch="(/explorer/transactions/[0-9a-f]+/category)"`).FindStringSubmatch(body)
+	if match == nil {
+		t.Fatal("category edit form not found in explorer rows")
+	}
+	path := match[1]
+
+	resp = patch(path, "category=Music")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Music", ">edited<", "Undo the manual category")
+
+	// The override survives reloading and shows in the rows
+	resp = ts.GET("/explorer/transactions?search=spotify&category=Music")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(path, ">edited<")
+
+	resp = patch(path, "category=")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("Entertainment").
+		NotContains(">edited<")
+
+	resp = patch("/explorer/transactions/0000000000000000/category", "category=Music")
+	testutil.AssertResponse(t, resp).Status(http.StatusNotFound)
+}
+
+// TestCategoryHierarchy tests that subcategories roll up under their parent
+// in the explorer's filters and the dashboard treemap
+func TestCategoryHierarchy(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	recategorize := func(search, category string) {
+		resp := ts.GET("/explorer/transactions?" + search)
+		body := testutil.AssertResponse(t, resp).StatusOK().Body()
+		match := regexp.MustCompile(`hx-patch="(/explorer/transactions/[0-9a-f]+/category)"`).FindStringSubmatch(body)
+		if match == nil {
+			t.Fatalf("no transaction found for %s", search)
+		}
+		req, _ := http.NewRequest("PATCH", ts.BaseURL+match[1], strings.NewReader("category="+url.QueryEscape(category)))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("PATCH failed: %v", err)
+		}
+		testutil.AssertResponse(t, resp).StatusOK()
+	}
+	recategorize("search=chipotle", "Food:Restaurants")
+	recategorize("search=whole+foods&start=2025-12-01&end=2025-12-31", "Food : Groceries")
+
+	resp := ts.GET("/explorer/transactions?category=Food")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("CHIPOTLE RESTAURANT", "WHOLE FOODS MARKET").
+		NotContains("WALMART GROCERY").
+		NotContains("RESTAURANT DINNER")
+
+	resp = ts.GET("/explorer/transactions?search=chipotle&exclude=food")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		NotContains("CHIPOTLE RESTAURANT")
+
+	resp = ts.GET("/explorer")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(`value="Food"`, "Show only Food and its subcategories", `value="Food:Groceries"`)
+
+	resp = ts.GET("/dashboard/charts/data/treemap?start=2025-12-01&end=2025-12-31")
+	testutil.AssertResponse(t, resp).StatusOK()
+	var chart struct {
+		Data []struct {
+			Type    string    `json:"type"`
+			IDs     []string  `json:"ids"`
+			Parents []string  `json:"parents"`
+			Values  []float64 `json:"values"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&chart); err != nil {
+		t.Fatalf("treemap isn't JSON: %v", err)
+	}
+	if len(chart.Data) != 1 || chart.Data[0].Type != "treemap" {
+		t.Fatalf("treemap traces = %+v", chart.Data)
+	}
+	trace := chart.Data[0]
+	found := false
+	for i, id := range trace.IDs {
+		if id == "Food:Groceries" {
+			found = true
+			if trace.Parents[i] != "Food" || trace.Values[i] != 156.78 {
+				t.Errorf("Food:Groceries has parent %q and value %.2f, want Food and 156.78", trace.Parents[i], trace.Values[i])
+			}
+		}
+	}
+	if !found {
+		t.Errorf("treemap ids = %v, want Food:Groceries", trace.IDs)
+	}
+}
+
+// TestSplitTransaction tests splitting a transaction among categories
+func TestSplitTransaction(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	send := func(method, path, body string) *http.Response {
+		req, _ := http.NewRequest(method, ts.BaseURL+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s failed: %v", method, err)
+		}
+		return resp
+	}
+
+	resp := ts.GET("/explorer/transactions?search=spotify&start=2024-07-01&end=2024-07-31")
+	body := testutil.AssertResponse(t, resp).StatusOK().Body()
+	match := regexp.MustCompile(`hx-get="(/explorer/transactions/[0-9a-f]+/split)"`).FindStringSubmatch(body)
+	if match == nil {
+		t.Fatal("split button not found in explorer rows")
+	}
+	path := match[1]
+
+	resp = ts.GET(path)
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Split SPOTIFY PREMIUM", `value="10.99"`).
+		NotContains("Remove split")
+
+	resp = send("PUT", path, "category=Entertainment&amount=6&category=Podcasts&amount=4.99&category=&amount=")
+	testutil.AssertResponse(t, resp).Status(http.StatusNoContent)
+
+	// The shares load as their own rows and count toward their categories
+	resp = ts.GET("/explorer/transactions?search=spotify&category=Podcasts")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("SPOTIFY PREMIUM", "4.99", ">split<")
+
+	resp = ts.GET("/dashboard/category/Podcasts?start=2024-07-01&end=2024-07-31")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("SPOTIFY PREMIUM", "4.99")
+
+	resp = ts.GET(path)
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(`value="Podcasts"`, `value="6.00"`, "Remove split")
+
+	resp = send("PUT", path, "category=Entertainment&amount=6&category=Podcasts&amount=1")
+	testutil.AssertResponse(t, resp).
+		Status(http.StatusBadRequest).
+		Contains("parts add up to 7.00, not 10.99")
+
+	resp = send("DELETE", path, "")
+	testutil.AssertResponse(t, resp).Status(http.StatusNoContent)
+
+	resp = ts.GET("/explorer/transactions?search=spotify&category=Podcasts")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		NotContains("SPOTIFY PREMIUM")
+
+	resp = ts.GET("/explorer/transactions/0000000000000000/split")
+	testutil.AssertResponse(t, resp).Status(http.StatusNotFound)
+}
+
+// TestTagTransaction tests tagging a transaction and filtering and totalling
+// by tag
+func TestTagTransaction(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	patch := func(path, body string) *http.Response {
+		req, _ := http.NewRequest("PATCH", ts.BaseURL+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("PATCH failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := ts.GET("/explorer/transactions?search=spotify&start=2024-07-01&end=2024-07-31")
+	body := testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("+tag").
+		Body()
+	match := regexp.MustCompile(`hx-patch="(/explorer/transactions/[0-9a-f]+/tags)"`).FindStringSubmatch(body)
+	if match == nil {
+		t.Fatal("tag edit form not found in explorer rows")
+	}
+	path := match[1]
+
+	resp = patch(path, "tags=%23Vacation 2024, reimbursable, vacation-2024")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("#reimbursable", "#vacation-2024", `value="reimbursable, vacation-2024"`)
+
+	// The tags survive reloading, filter the rows and total in insights
+	resp = ts.GET("/explorer?tag=reimbursable")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("All Tags", "SPOTIFY PREMIUM", ">1</span> transactions")
+
+	resp = ts.GET("/api/v1/transactions?tag=Vacation-2024")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(`"tags":["reimbursable","vacation-2024"]`, `"total":1`)
+
+	resp = ts.GET("/insights?start=2024-01-01&end=2024-12-31")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(`id="tags"`, "/explorer?tag=reimbursable")
+
+	resp = patch(path, "tags=")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		NotContains("#reimbursable")
+
+	resp = ts.GET("/explorer?tag=reimbursable")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		NotContains("SPOTIFY PREMIUM")
+
+	resp = patch("/explorer/transactions/0000000000000000/tags", "tags=x")
+	testutil.AssertResponse(t, resp).Status(http.StatusNotFound)
+}
+
+// TestInsightsCohort tests saving a cohort and scoping insights to it
+func TestInsightsCohort(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	form := "application/x-www-form-urlencoded"
+	resp := ts.POST("/insights/cohorts", form, strings.NewReader("name=Streaming&filter=color:red"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+
+	resp = ts.POST("/insights/cohorts", form, strings.NewReader(url.Values{
+		"name":   {"Streaming"},
+		"filter": {"text:netflix OR text:spotify"},
+	}.Encode()))
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(`href="/insights?view=streaming"`, "text:netflix OR text:spotify")
+
+	resp = ts.GET("/insights?start=2024-01-01&end=2024-12-31")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(`name="view"`, `<option value="streaming"`)
+
+	resp = ts.GET("/insights?start=2024-01-01&end=2024-12-31&view=streaming")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Only the Streaming cohort", "&view=streaming")
+
+	resp = ts.GETWithQuery("/api/v1/insights", map[string]string{"start": "2024-01-01", "end": "2024-12-31", "view": "streaming"})
+	var bundle struct {
+		View     models.Cohort       `json:"view"`
+		Insights models.InsightsData `json:"insights"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&bundle); err != nil {
+		t.Fatalf("decode insights: %v", err)
+	}
+	if bundle.View.Name != "Streaming" {
+		t.Errorf("view = %+v, want the Streaming cohort", bundle.View)
+	}
+	if len(bundle.Insights.RecurringPayments) == 0 {
+		t.Fatal("the cohort should still find its subscriptions")
+	}
+	for _, rp := range bundle.Insights.RecurringPayments {
+		if d := strings.ToUpper(rp.Description); !strings.Contains(d, "NETFLIX") && !strings.Contains(d, "SPOTIFY") {
+			t.Errorf("recurring payment %q is outside the cohort", rp.Description)
+		}
+	}
+
+	resp = ts.GET("/insights?view=nope")
+	testutil.AssertResponse(t, resp).Status(http.StatusNotFound)
+
+	req, _ := http.NewRequest("DELETE", ts.BaseURL+"/insights/cohorts/streaming", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	testutil.AssertResponse(t, resp).StatusOK().Contains("No cohorts yet")
+}
+
+// TestFileSignConvention tests choosing how a file's amounts are signed
+func TestFileSignConvention(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	setSign := func(convention string) *http.Response {
+		return ts.POST("/explorer/files/sign", "application/x-www-form-urlencoded",
+			strings.NewReader("file=transactions.csv&convention="+convention))
+	}
+
+	resp := ts.GET("/explorer/files")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("/explorer/files/sign", "Detected: money out negative")
+
+	resp = setSign("debits_positive")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Detect automatically", `value="debits_positive" selected`)
+
+	// Paychecks now load as money out
+	resp = ts.GET("/explorer/transactions?search=payroll")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("DIRECT DEP ACME CORP PAYROLL").
+		NotContains("text-green-600")
+
+	resp = setSign("")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("Detected: money out negative")
+
+	resp = setSign("sideways")
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+}
+
+// TestFileAccountType tests marking a file as a credit card account
+func TestFileAccountType(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	setType := func(accountType string) *http.Response {
+		return ts.POST("/explorer/files/account", "application/x-www-form-urlencoded",
+			strings.NewReader("file=transactions.csv&type="+accountType))
+	}
+
+	resp := ts.GET("/explorer/files")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("/explorer/files/account", `value="bank" selected`)
+
+	resp = setType("credit_card")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains(`value="credit_card" selected`)
+
+	// Credits on a card aren't income, so paychecks no longer count as such
+	resp = ts.GET("/explorer/transactions?search=payroll")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("DIRECT DEP ACME CORP PAYROLL").
+		NotContains("text-green-600")
+
+	resp = setType("bank")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains(`value="bank" selected`)
+
+	resp = ts.GET("/explorer/transactions?search=payroll")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("text-green-600")
+
+	resp = setType("brokerage")
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+}
+
+// TestInsightsSpendingRhythm tests the weekday/weekend and pay cycle breakdowns
+func TestInsightsSpendingRhythm(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/insights/rhythm")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Weekday avg/day", "Weekend avg/day", "Paydays from")
+
+	for _, chart := range []string{"weekday", "payday"} {
+		resp = ts.GET("/insights/rhythm/chart/" + chart)
+		testutil.AssertResponse(t, resp).StatusOK()
+		var chartData map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&chartData); err != nil {
+			t.Fatalf("%s chart: invalid JSON: %v", chart, err)
+		}
+		if traces, _ := chartData["data"].([]interface{}); len(traces) != 1 {
+			t.Errorf("%s chart: expected one trace, got %v", chart, chartData["data"])
+		}
+	}
+}
+
+// TestInsightsStatements tests grouping a card's file by statement cycle
+func TestInsightsStatements(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	form := "application/x-www-form-urlencoded"
+
+	resp := ts.GET("/insights/statements")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("No cards set up")
+
+	resp = ts.POST("/insights/statements", form, strings.NewReader("source=transactions.csv&closing_day=32"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+
+	resp = ts.POST("/insights/statements", form, strings.NewReader("source=transactions.csv&name=Visa&closing_day=15"))
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Visa", "closes on day 15", "Projected balance", "Dec 16, 2025")
+
+	req, _ := http.NewRequest("DELETE", ts.BaseURL+"/insights/statements/transactions.csv", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("No cards set up")
+}
+
+// TestInsightsGiving tests the annual giving summary and its tax export
+func TestInsightsGiving(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	// 2025 has a $250 charitable donation and three gifts filed under Shopping
+	resp := ts.GET("/insights/giving")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("CHARITABLE DONATION", "HOLIDAY GIFTS", "$846.78", "$250.00", "$596.78")
+
+	resp = ts.GET("/insights/giving/export?year=2025")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("2025-12-10,CHARITABLE DONATION,Charity,250.00,Yes", "Tax-deductible total,250.00", "Total giving,846.78")
+
+	resp = ts.POST("/insights/giving/categories?year=2025", "application/x-www-form-urlencoded", strings.NewReader("category=Charity"))
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Giving categories:", "$846.78", "$0.00")
+}
+
+// TestPprofDisabledByDefault checks profiles are only served when enabled
+func TestPprofDisabledByDefault(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/debug/pprof/")
+	testutil.AssertResponse(t, resp).Status(http.StatusNotFound)
+
+	cfg.Pprof = true
+	defer func() { cfg.Pprof = false }()
+	enabled := testutil.NewTestServer(t, SetupRouter())
+	defer enabled.Close()
+
+	resp = enabled.GET("/debug/pprof/")
+	testutil.AssertResponse(t, resp).StatusOK()
+}
+
+// TestRootRedirect tests that / redirects to /dashboard
+func TestRootRedirect(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	// Don't follow redirects
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(ts.BaseURL + "/")
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("Expected status %d, got %d", http.StatusTemporaryRedirect, resp.StatusCode)
+	}
+
+	location := resp.Header.Get("Location")
+	if location != "/dashboard" {
+		t.Errorf("Expected redirect to /dashboard, got %s", location)
+	}
+}
+
+// TestDashboard tests the main dashboard page
+func TestDashboard(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/dashboard")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll(
+			"Dashboard",
+			"Total Income",
+			"Total Expenses",
+			"Net Savings",
+		)
+}
+
+// TestDashboardKPIsPartial tests the KPIs partial endpoint
+func TestDashboardKPIsPartial(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/dashboard/kpis")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll("Total Income", "Total Expenses", "last 6 months")
+
+	// A wider sparkline window picks up the year-over-year overlay
+	resp = ts.GETWithQuery("/dashboard/kpis", map[string]string{"trend": "12"})
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("last 12 months", "a year earlier", "data-yoy")
+
+	// Unsupported windows fall back to the default
+	resp = ts.GETWithQuery("/dashboard/kpis", map[string]string{"trend": "9"})
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("last 6 months")
+
+	// March against February, per day
+	resp = ts.GETWithQuery("/dashboard/kpis", map[string]string{
+		"start":      "2025-03-01",
+		"end":        "2025-03-31",
+		"comparison": "month",
+		"normalize":  "day",
+	})
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("per day (31 vs 28 days)")
+}
+
+// TestDashboardAlertsPartial tests the alerts partial endpoint
+func TestDashboardAlertsPartial(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/dashboard/alerts")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML()
+}
+
+// TestDashboardChartData tests chart data endpoints
+func TestDashboardChartData(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	chartTypes := []string{
+		"monthly",
+		"category",
+		"treemap",
+		"income",
+		"accounts",
+		"cashflow",
+		"merchants",
+		"weekly",
+		"cumulative",
+	}
+
+	for _, chartType := range chartTypes {
+		t.Run(chartType, func(t *testing.T) {
+			resp := ts.GET("/dashboard/charts/data/" + chartType)
+			testutil.AssertResponse(t, resp).
+				StatusOK().
+				ContentTypeJSON()
+
+			// Verify it's valid JSON
+			body := testutil.ReadBody(t, resp)
+			var data interface{}
+			if err := json.Unmarshal([]byte(body), &data); err != nil {
+				t.Errorf("Invalid JSON for chart %s: %v", chartType, err)
+			}
+		})
+	}
+
+	resp := ts.GET("/dashboard/charts/data/nonexistent?start=2025-01-01")
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+}
+
+// TestIncomeByCategory tests the income breakdown API and chart
+func TestIncomeByCategory(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	// Dec 2025 has two $3,500 paychecks and a $3,000 bonus
+	resp := ts.GET("/api/income/categories?start=2025-12-01&end=2025-12-31&sources=transactions.csv")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON()
+	var breakdown models.CategoryBreakdown
+	if err := json.NewDecoder(resp.Body).Decode(&breakdown); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if breakdown.Total != 10000 || len(breakdown.Categories) != 2 {
+		t.Fatalf("breakdown = %+v, want $10,000 across 2 categories", breakdown)
+	}
+	if paycheck := breakdown.Categories[0]; paycheck.Category != "Paycheck" || paycheck.Amount != 7000 || paycheck.Count != 2 || paycheck.Percentage != 70 {
+		t.Errorf("largest category = %+v, want Paycheck with $7,000 from 2 deposits", paycheck)
+	}
+
+	resp = ts.GET("/dashboard/charts/data/income?start=2025-01-01&end=2025-12-31")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Paycheck", "Bonus", "Other Income").
+		NotContains("Groceries")
+}
+
+// TestAPIv1 tests the versioned JSON API's views, filters and pagination
+func TestAPIv1(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/api/v1/transactions?sources=transactions.csv&search=payroll&perPage=10&page=2&sort=date&order=asc")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON()
+	var page models.TransactionPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if p := page.Pagination; p.Page != 2 || p.PerPage != 10 || p.Total != 38 || p.TotalPages != 4 {
+		t.Errorf("pagination = %+v, want page 2 of 4 over 38 paychecks", p)
+	}
+	if len(page.Transactions) != 10 || page.Transactions[0].Date.After(page.Transactions[9].Date) || page.TotalExpenses != 0 {
+		t.Errorf("page = %d transactions, want 10 paychecks oldest first", len(page.Transactions))
+	}
+
+	resp = ts.GET("/api/v1/transactions?search=no-such-merchant")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains(`"transactions":[]`)
+
+	resp = ts.GET("/api/v1/categories?start=2025-12-01&end=2025-12-31&sources=transactions.csv&type=Income")
+	var breakdown models.CategoryBreakdown
+	if err := json.NewDecoder(resp.Body).Decode(&breakdown); err != nil || breakdown.Total != 10000 {
+		t.Errorf("income categories = %+v (%v), want $10,000", breakdown, err)
+	}
+	resp = ts.GET("/api/v1/categories?start=2025-12-01&end=2025-12-31")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(`"Groceries"`, `"Rent"`).
+		NotContains(`"Paycheck"`)
+
+	resp = ts.GET("/api/v1/metrics?start=2025-01-01&end=2025-12-31")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON().
+		ContainsAll(`"start":"2025-01-01"`, `"total_income"`, `"savings_rate"`, `"alerts":`)
+
+	resp = ts.GET("/api/v1/metrics?start=last-year")
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+
+	resp = ts.GET("/api/v1/insights?start=2025-01-01&end=2025-12-31")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON().
+		ContainsAll(`"recurring_payments"`, `"velocity"`)
+	if disposition := resp.Header.Get("Content-Disposition"); disposition != "" {
+		t.Errorf("API response has Content-Disposition %q, want it inline", disposition)
+	}
+
+	resp = ts.GET("/api/v1/whatif")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON()
+	var whatIf map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&whatIf); err != nil || whatIf["settings"] == nil || whatIf["analysis"] == nil {
+		t.Errorf("what-if = %v keys (%v), want settings and analysis", len(whatIf), err)
+	}
+
+	resp = ts.GET("/api/v1/whatif/sweep?param=monthly_living_expenses&from=4000&to=7000&step=500&runs=100")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON()
+	var sweep models.SweepResult
+	if err := json.NewDecoder(resp.Body).Decode(&sweep); err != nil || len(sweep.Points) != 7 || sweep.Points[6].Value != 7000 {
+		t.Errorf("sweep = %+v (%v), want 7 points from 4000 to 7000", sweep.Points, err)
+	}
+	for _, query := range []string{"param=pets&values=1,2", "param=inflation_rate", "param=inflation_rate&from=1&to=100&step=1", "param=inflation_rate&values=2,x"} {
+		resp = ts.GET("/api/v1/whatif/sweep?" + query)
+		testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+	}
+}
+
+// TestLogin tests that a configured login guards pages and the API, leaving
+// health checks and the token-guarded status endpoints open
+func TestLogin(t *testing.T) {
+	setupTestServer(t).Close()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	cfg.AuthUsername = "alex"
+	cfg.AuthPassword = string(hash)
+	cfg.SessionHours = 1
+	if err := SetupDependencies(cfg); err != nil {
+		t.Fatalf("Failed to setup dependencies: %v", err)
+	}
+	ts := testutil.NewTestServer(t, SetupRouter())
+	defer ts.Close()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	var session *http.Cookie
+	do := func(method, path string, form url.Values, header ...string) *http.Response {
+		req, _ := http.NewRequest(method, ts.BaseURL+path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		if session != nil {
+			req.AddCookie(session)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		return resp
+	}
+
+	testutil.AssertResponse(t, do("GET", "/api/health", nil)).StatusOK()
+	testutil.AssertResponse(t, do("GET", "/api/status?token=status-test-token", nil)).StatusOK()
+
+	resp := do("GET", "/dashboard?trend=12", nil)
+	testutil.AssertResponse(t, resp).Status(http.StatusSeeOther)
+	if loc := resp.Header.Get("Location"); loc != "/login?next=%2Fdashboard%3Ftrend%3D12" {
+		t.Errorf("redirect = %q, want the login page returning to the dashboard", loc)
+	}
+	resp = do("GET", "/dashboard/alerts", nil, "HX-Request", "true")
+	testutil.AssertResponse(t, resp).Status(http.StatusUnauthorized)
+	if resp.Header.Get("HX-Redirect") == "" {
+		t.Error("HTMX request without a session has no HX-Redirect")
+	}
+	testutil.AssertResponse(t, do("GET", "/api/v1/metrics", nil)).Status(http.StatusUnauthorized)
+	testutil.AssertResponse(t, do("DELETE", "/data/all", nil)).Status(http.StatusUnauthorized)
+
+	resp = do("GET", "/login", nil)
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("Sign In").
+		NotContains(`href="/explorer"`)
+
+	resp = do("POST", "/login", url.Values{"username": {"alex"}, "password": {"wrong"}})
+	testutil.AssertResponse(t, resp).
+		Status(http.StatusUnauthorized).
+		Contains("Incorrect username or password")
+
+	resp = do("POST", "/login", url.Values{"username": {"alex"}, "password": {"correct horse"}, "next": {"//evil.example"}})
+	testutil.AssertResponse(t, resp).Status(http.StatusSeeOther)
+	if loc := resp.Header.Get("Location"); loc != "/" {
+		t.Errorf("redirect after login = %q, want / for an off-site next", loc)
+	}
+	resp = do("POST", "/login", url.Values{"username": {"alex"}, "password": {"correct horse"}, "next": {"/insights"}})
+	if loc := resp.Header.Get("Location"); loc != "/insights" {
+		t.Errorf("redirect after login = %q, want /insights", loc)
+	}
+	for _, c := range resp.Cookies() {
+		if c.Name == "budget_session" {
+			session = c
+		}
+	}
+	if session == nil || !session.HttpOnly {
+		t.Fatalf("login set no HttpOnly session cookie: %v", resp.Cookies())
+	}
+
+	testutil.AssertResponse(t, do("GET", "/dashboard", nil)).StatusOK()
+	testutil.AssertResponse(t, do("GET", "/api/v1/metrics", nil)).StatusOK()
+	testutil.AssertResponse(t, do("GET", "/session", nil)).Contains("Sign Out")
+
+	resp = do("POST", "/logout", nil)
+	testutil.AssertResponse(t, resp).Status(http.StatusSeeOther)
+	testutil.AssertResponse(t, do("GET", "/dashboard", nil)).Status(http.StatusSeeOther)
+}
+
+// TestAPIKeys tests that configured API keys guard the /api/v1 endpoints only
+func TestAPIKeys(t *testing.T) {
+	setupTestServer(t).Close()
+	cfg.APIKeys = config.ParseAPIKeys("reader-key, writer-key:write, bad-key:admin")
+	if len(cfg.APIKeys) != 2 {
+		t.Fatalf("parsed %d API keys, want 2 skipping the unknown scope", len(cfg.APIKeys))
+	}
+	ts := testutil.NewTestServer(t, SetupRouter())
+	defer ts.Close()
+
+	get := func(path, header, value string) *http.Response {
+		req, _ := http.NewRequest("GET", ts.BaseURL+path, nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		return resp
+	}
+
+	resp := get("/api/v1/metrics", "", "")
+	testutil.AssertResponse(t, resp).Status(http.StatusUnauthorized)
+	if resp.Header.Get("WWW-Authenticate") == "" {
+		t.Error("401 response has no WWW-Authenticate header")
+	}
+	resp = get("/api/v1/metrics", "Authorization", "Bearer wrong-key")
+	testutil.AssertResponse(t, resp).Status(http.StatusUnauthorized)
+	resp = get("/api/v1/metrics?token=reader-key", "", "")
+	testutil.AssertResponse(t, resp).Status(http.StatusUnauthorized)
+
+	resp = get("/api/v1/metrics", "Authorization", "Bearer reader-key")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON()
+	resp = get("/api/v1/transactions?perPage=1", "X-API-Key", "writer-key")
+	var page models.TransactionPage
+	if err := json.Unmarshal([]byte(testutil.AssertResponse(t, resp).StatusOK().Body()), &page); err != nil || len(page.Transactions) == 0 {
+		t.Fatalf("expected a page of transactions: %v", err)
+	}
+
+	// Only write keys may change data
+	recategorize := func(key string) *http.Response {
+		path := "/api/v1/transactions/" + page.Transactions[0].Hash + "/category"
+		req, _ := http.NewRequest("PATCH", ts.BaseURL+path, strings.NewReader("category=Travel"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer "+key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("PATCH %s failed: %v", path, err)
+		}
+		return resp
+	}
+	resp = recategorize("reader-key")
+	testutil.AssertResponse(t, resp).Status(http.StatusForbidden)
+	resp = recategorize("writer-key")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON().
+		Contains(`"category":"Travel"`)
+
+	// Pages and the token-guarded briefing don't take API keys
+	resp = get("/dashboard", "", "")
+	testutil.AssertResponse(t, resp).StatusOK()
+	resp = get("/api/v1/briefing?token=status-test-token", "", "")
+	testutil.AssertResponse(t, resp).StatusOK()
+}
+
+// TestDashboardChartCategoryFilter tests multi-category and exclusion filters on chart data
+func TestDashboardChartCategoryFilter(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/dashboard/charts/data/category?category=Groceries&category=Rent")
+	body := testutil.ReadBody(t, resp)
+	if !strings.Contains(body, "Groceries") || !strings.Contains(body, "Rent") || strings.Contains(body, "Utilities") {
+		t.Errorf("expected only Groceries and Rent in chart, got %s", body)
+	}
+
+	resp = ts.GET("/dashboard/charts/data/category?exclude=Rent")
+	body = testutil.ReadBody(t, resp)
+	if strings.Contains(body, "Rent") || !strings.Contains(body, "Groceries") {
+		t.Errorf("expected Rent to be excluded from chart, got %s", body)
+	}
+}
+
+// TestSourcesFilter tests that the sources parameter narrows views to one file
+func TestSourcesFilter(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/dashboard?sources=transactions_edge.csv")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll("Showing only transactions_edge.csv", `name="sources" value="transactions_edge.csv"`)
+
+	resp = ts.GET("/explorer/transactions?sources=transactions_edge.csv&search=ACME")
+	body := testutil.ReadBody(t, resp)
+	if strings.Contains(body, "ACME CORP PAYROLL") {
+		t.Error("transactions from other files should be hidden by the sources filter")
+	}
+
+	for _, path := range []string{
+		"/dashboard/charts/data/monthly?sources=transactions.csv",
+		"/insights?sources=transactions.csv",
+		"/insights/trends/chart?sources=transactions.csv",
+	} {
+		testutil.AssertResponse(t, ts.GET(path)).StatusOK()
+	}
+}
+
+// TestExplorer tests the explorer page
+func TestExplorer(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/explorer")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll(
+			"Explorer",
+			"Search",
+			"Category",
+		)
+}
+
+// TestExplorerCategorySelection tests that selected and excluded categories are checked
+func TestExplorerCategorySelection(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/explorer?category=Groceries&category=Rent&exclude=Utilities")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll(
+			"2 categories",
+			`name="category" value="Groceries" checked`,
+			`name="exclude" value="Utilities" checked`,
+		)
+}
+
+// TestExplorerTransactionsPartial tests the transactions partial
+func TestExplorerTransactionsPartial(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/explorer/transactions")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML()
+}
+
+// TestExplorerFiltering tests transaction filtering
+func TestExplorerFiltering(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	tests := []struct {
+		name  string
+		query map[string]string
+	}{
+		{"search", map[string]string{"search": "ACME"}},
+		{"category", map[string]string{"category": "Groceries"}},
+		{"type-income", map[string]string{"type": "income"}},
+		{"type-expense", map[string]string{"type": "expense"}},
+		{"date-range", map[string]string{"start": "2025-01-01", "end": "2025-06-30"}},
+		{"amount-range", map[string]string{"minAmount": "75", "maxAmount": "85"}},
+		{"min-amount-only", map[string]string{"minAmount": "1000"}},
+		{"weekend", map[string]string{"weekday": "weekend"}},
+		{"single-day", map[string]string{"weekday": "Saturday"}},
+		{"exclude", map[string]string{"exclude": "Rent"}},
+		{"uncategorized", map[string]string{"uncategorized": "true"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := ts.GETWithQuery("/explorer/transactions", tt.query)
+			testutil.AssertResponse(t, resp).
+				StatusOK().
+				ContentTypeHTML()
+		})
+	}
+}
+
+// TestExplorerInfiniteScroll tests paging the explorer by cursor, the summary
+// sent with each page and the table starting over on a stale cursor
+func TestExplorerInfiniteScroll(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	cursorRe := regexp.MustCompile(`cursor=(\d+-[0-9a-f]+)`)
+	next := func(body string) string {
+		if m := cursorRe.FindStringSubmatch(body); m != nil {
+			return m[1]
+		}
+		return ""
+	}
+
+	body := testutil.AssertResponse(t, ts.GET("/explorer/transactions?perPage=100")).
+		StatusOK().
+		Contains(`id="summary-stats"`).
+		Body()
+	cursor := next(body)
+	if !strings.HasPrefix(cursor, "2-") {
+		t.Fatalf("first page cursor = %q, want page 2", cursor)
+	}
+
+	// Appending the next pages keeps the summary and ends without a cursor
+	for page := 2; cursor != ""; page++ {
+		resp := ts.GET("/explorer/transactions?perPage=100&append=true&cursor=" + cursor)
+		if resp.Header.Get("HX-Retarget") != "" {
+			t.Fatalf("page %d: unexpected table reload", page)
+		}
+		body = testutil.AssertResponse(t, resp).
+			StatusOK().
+			Contains(`id="summary-stats"`).
+			NotContains(`id="transaction-rows"`).
+			Body()
+		cursor = next(body)
+		if page > 10 {
+			t.Fatal("cursor never ran out")
+		}
+	}
+	if strings.Contains(body, "Scroll for more") {
+		t.Error("last page still says to scroll for more")
+	}
+
+	// A cursor from before the filter changed reloads the whole table
+	first := next(testutil.ReadBody(t, ts.GET("/explorer/transactions?perPage=100")))
+	resp := ts.GET("/explorer/transactions?perPage=100&search=ACME&append=true&cursor=" + first)
+	if got := resp.Header.Get("HX-Retarget"); got != "#transactions-container" {
+		t.Errorf("HX-Retarget = %q, want the table container", got)
+	}
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(`id="transaction-rows"`, `id="summary-stats"`)
+
+	// Past the last page an append gets no rows instead of the last page again
+	_, fingerprint, _ := strings.Cut(first, "-")
+	testutil.AssertResponse(t, ts.GET("/explorer/transactions?perPage=100&append=true&cursor=99-"+fingerprint)).
+		StatusOK().
+		NotContains("<tr").
+		Contains(`id="summary-stats"`)
+}
+
+// TestTransactionPermalink tests the page for a single transaction
+func TestTransactionPermalink(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var page models.TransactionPage
+	resp := ts.GET("/api/v1/transactions?perPage=1&search=ACME")
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil || len(page.Transactions) == 0 {
+		t.Fatalf("no transaction to link to: %v", err)
+	}
+	txn := page.Transactions[0]
+
+	testutil.AssertResponse(t, ts.GET("/t/"+txn.Hash)).
+		StatusOK().
+		ContainsAll(txn.Description, "Same day", "History with", "Open in Data Explorer")
+
+	testutil.AssertResponse(t, ts.GET("/t/0000000000000000")).
+		Status(http.StatusNotFound)
+}
+
+// TestWhatIf tests the what-if analysis page
+func TestWhatIf(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/whatif")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll(
+			"What-If",
+			"Portfolio Value",
+			"Estate Projection",
+			"Legacy Target",
+			"Yearly QCD",
+			"Crash Recovery",
+			"Inflation/Return Correlation",
+			"Health",
+			"Median age at death",
+			"Match inflation",
+			"By age",
+			"Bridge to Age 67",
+			"rung Treasury or CD ladder",
+			`name="essential_floor"`,
+		)
+
+	// Impossible values are rejected before anything is saved; unrealistic
+	// ones are only warned about (see TestSanityWarnings)
+	rejected := map[string]string{
+		"essential_floor=-100":       "Essential floor can't be negative",
+		"portfolio_value=-1":         "Portfolio value can't be negative",
+		"investment_return=80":       "Investment return must be between -50% and 50%",
+		"inflation_rate=45":          "Inflation must be between -10% and 30%",
+		"spending_decline_rate=-1":   "Spending decline must be between 0% and 20%",
+		"monthly_living_expenses=-5": "Monthly expenses can't be negative",
+	}
+	for form, want := range rejected {
+		resp = ts.POST("/whatif/settings", "application/x-www-form-urlencoded", strings.NewReader(form))
+		testutil.AssertResponse(t, resp).Status(http.StatusBadRequest).Contains(want)
+	}
+}
+
+// TestWhatIfProjectionChart tests the projection chart endpoint
+func TestWhatIfProjectionChart(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/whatif/chart/projection")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON()
+}
+
+// TestWhatIfSavings tests the per-payday savings transfer suggestion
+func TestWhatIfSavings(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/whatif/savings")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll("Automatic Savings Transfer", "Emergency Fund", "FIRE Date")
+
+	resp = ts.POST("/whatif/savings", "application/x-www-form-urlencoded",
+		strings.NewReader("emergency_fund=2500&target_months=3&withdrawal_rate=3.5"))
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("$2,500", "3 months", "3.5% rule")
+
+	resp = ts.POST("/whatif/savings", "application/x-www-form-urlencoded",
+		strings.NewReader("target_months=0"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+}
+
+// TestWhatIfRelocation tests comparing the plan across states
+func TestWhatIfRelocation(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/whatif/relocation")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll("Relocation Comparison", "Where you live now")
+
+	resp = ts.POST("/whatif/relocation/current", "application/x-www-form-urlencoded",
+		strings.NewReader("state=California&income_tax_rate=9.3&property_tax=9000"))
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("California")
+
+	resp = ts.POST("/whatif/relocation", "application/x-www-form-urlencoded",
+		strings.NewReader("state=Florida&cost_of_living=0.85&income_tax_rate=0&property_tax=5000"))
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("California", "Florida", "0.85×", "$416.67/mo")
+
+	resp = ts.POST("/whatif/relocation", "application/x-www-form-urlencoded",
+		strings.NewReader("state=Nowhere&cost_of_living=0"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+}
+
+// TestWhatIfStress tests running a stress preset against the plan
+func TestWhatIfStress(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/whatif/stress")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll("Stress Tests", "2008 Replay", "Stagflation", "Lost Decade")
+
+	resp = ts.GETWithQuery("/whatif/stress", map[string]string{"scenario": "stagflation"})
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Success Rate", "Median Final Balance", "-26.5%", "12.3%")
+
+	resp = ts.GETWithQuery("/whatif/stress", map[string]string{"scenario": "tulip-mania"})
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+}
+
+// TestWhatIfSyncWindows tests comparing the plan across expense sync windows
+func TestWhatIfSyncWindows(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/whatif/sync-windows")
+	body := testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll("Expense Sync Window", "6 months", "12 months, no one-offs", "24 months, no one-offs", "Withdrawal Rate").
+		Body()
+	// The test settings sync over the default 12 months with one-offs included
+	if n := strings.Count(body, "bg-indigo-50"); n != 1 {
+		t.Errorf("%d windows highlighted, want the selected one", n)
+	}
+
+	resp = ts.GET("/whatif")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(`name="sync_window_months"`, `<option value="12" selected>`, "Exclude one-off purchases")
+}
+
+// TestWhatIfBuckets tests the portfolio buckets card and its validation
+func TestWhatIfBuckets(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/whatif")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Portfolio Buckets", `hx-post="/whatif/bucket"`, "No buckets; the portfolio grows at one blended return", `id="portfolio-slider"`)
+
+	tests := []struct {
+		name string
+		form string
+		want string
+	}{
+		{"missing name", "value=1000&return=4", "Bucket name is required"},
+		{"negative value", "name=Cash&value=-1&return=2", "value cannot be negative"},
+		{"return out of range", "name=Stocks&value=1000&return=80", "return must be between"},
+		{"bad priority", "name=Bonds&value=1000&return=4&priority=first", "invalid priority"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := ts.POST("/whatif/bucket", "application/x-www-form-urlencoded", strings.NewReader(tt.form))
+			testutil.AssertResponse(t, resp).Status(http.StatusBadRequest).Contains(tt.want)
+		})
+	}
+
+	// Without buckets the chart shows the single portfolio balance
+	resp = ts.GET("/whatif/chart/projection")
+	testutil.AssertResponse(t, resp).StatusOK().ContentTypeJSON().Contains("Portfolio Balance").NotContains("stackgroup")
+}
+
+// TestWhatIfAssumptions tests the assumptions report downloads
+func TestWhatIfAssumptions(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/whatif/assumptions?format=json")
+	testutil.AssertResponse(t, resp).StatusOK().ContentTypeJSON()
+	var report models.AssumptionsReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	sources := make(map[string]string)
+	for _, a := range report.Assumptions {
+		sources[a.Name] = a.Source
+	}
+	// The test settings raise the return above the default and keep inflation
+	if sources["Investment return"] != models.AssumptionUser || sources["Inflation"] != models.AssumptionDefault {
+		t.Errorf("sources = %v, want the return entered and inflation default", sources)
+	}
+	if sources["2008 Replay"] != models.AssumptionDefault {
+		t.Error("stress scenarios should be listed as built-in")
+	}
+
+	resp = ts.GET("/whatif/assumptions")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("%PDF-1.4").
+		Contains("Retirement Projection Assumptions")
+	if ct := resp.Header.Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("Content-Type = %q, want application/pdf", ct)
+	}
+}
+
+// TestWithholdingSchedule tests the RMD withholding schedule downloads
+func TestWithholdingSchedule(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	// The test settings are 65 today, so RMDs start in eight years
+	firstYear := fmt.Sprint(time.Now().Year() + 8)
+	resp := ts.GET("/whatif/rmd/withholding")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentType("text/csv").
+		ContainsAll("Tax Year,Age,RMD", "Withhold % of RMD", "\n"+firstYear+",73,")
+
+	resp = ts.GET("/whatif/rmd/withholding?format=json")
+	testutil.AssertResponse(t, resp).StatusOK().ContentTypeJSON()
+	var schedule []models.RMDProjection
+	if err := json.NewDecoder(resp.Body).Decode(&schedule); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(schedule) == 0 || schedule[0].Age != 73 || schedule[0].RMDTax <= 0 || schedule[0].WithholdingPercent <= 0 {
+		t.Errorf("schedule starts %+v, want tax to withhold at 73", schedule[0:min(1, len(schedule))])
+	}
+
+	resp = ts.GET("/whatif")
+	testutil.AssertResponse(t, resp).StatusOK().Contains("/whatif/rmd/withholding")
+}
+
+// TestReadinessReport tests the quarterly readiness summary downloads
+func TestReadinessReport(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/whatif/readiness")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("%PDF-1.4", "Retirement Readiness", "Recommended actions")
+	if ct := resp.Header.Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("Content-Type = %q, want application/pdf", ct)
+	}
+
+	resp = ts.GET("/whatif/readiness?format=text")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentType("text/plain").
+		ContainsAll("Retirement readiness, "+retirement.Quarter(time.Now()), "Success rate: ", "Biggest assumption risks", "- Lower returns")
+
+	resp = ts.GET("/whatif/readiness?format=json")
+	testutil.AssertResponse(t, resp).StatusOK().ContentTypeJSON()
+	var report models.ReadinessReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if report.Score == 0 || len(report.Risks) == 0 || len(report.Actions) == 0 {
+		t.Errorf("report = %+v, want a score, risks and actions", report)
+	}
+
+	resp = ts.GET("/whatif")
+	testutil.AssertResponse(t, resp).StatusOK().Contains("/whatif/readiness")
+}
+
+// TestInsights tests the insights page
+func TestInsights(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/insights")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll(
+			"Insights",
+			"Recurring",
+			"Ignore categories under $",
+			"Ranked by impact",
+		)
+}
+
+// TestProjectionRanges tests the confidence ranges on projected numbers
+func TestProjectionRanges(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	testutil.AssertResponse(t, ts.GET("/insights")).
+		StatusOK().
+		ContainsAll("Next Month", "80% likely range")
+
+	testutil.AssertResponse(t, ts.GET("/api/v1/insights")).
+		StatusOK().
+		ContentTypeJSON().
+		ContainsAll(`"month_range":{"low":`, `"projection_range":{"low":`, `"level":80`)
+}
+
+// TestStatusEndpoint tests the token-protected month summary for home dashboards
+func TestStatusEndpoint(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/api/status")
+	testutil.AssertResponse(t, resp).Status(http.StatusUnauthorized)
+
+	resp = ts.GETWithQuery("/api/status", map[string]string{"token": "wrong"})
+	testutil.AssertResponse(t, resp).Status(http.StatusUnauthorized)
+
+	req, _ := http.NewRequest("GET", ts.BaseURL+"/api/status", nil)
+	req.Header.Set("Authorization", "Bearer status-test-token")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON()
+
+	var status models.MonthStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if status.Month != "2025-12" || status.Spent <= 0 || status.BudgetSource != models.BudgetAverage {
+		t.Errorf("status = %+v, want December 2025 spending against the average", status)
+	}
+}
+
+// TestBriefingEndpoint tests the spoken summary for voice assistants
+func TestBriefingEndpoint(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/api/v1/briefing")
+	testutil.AssertResponse(t, resp).Status(http.StatusUnauthorized)
+
+	resp = ts.GETWithQuery("/api/v1/briefing", map[string]string{"token": "status-test-token"})
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON()
+
+	var briefing models.Briefing
+	if err := json.NewDecoder(resp.Body).Decode(&briefing); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if briefing.Status == nil || briefing.Status.Month != "2025-12" || briefing.TypicalSpent <= 0 {
+		t.Errorf("briefing = %+v, want December 2025 against typical spending", briefing)
+	}
+	if !strings.HasPrefix(briefing.Speech, "So far in December you've spent $") {
+		t.Errorf("speech = %q", briefing.Speech)
+	}
+
+	resp = ts.GETWithQuery("/api/v1/briefing", map[string]string{"token": "status-test-token", "format": "text"})
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentType("text/plain").
+		Contains("by this point in the month").
+		NotContains("{")
+}
+
+// TestInsightsExport tests downloading the insights analysis as JSON
+func TestInsightsExport(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GETWithQuery("/insights/export", map[string]string{"start": "2025-01-01", "end": "2025-06-30"})
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON()
+
+	var bundle struct {
+		Start    string              `json:"start"`
+		End      string              `json:"end"`
+		Insights models.InsightsData `json:"insights"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&bundle); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if bundle.Start != "2025-01-01" || bundle.End != "2025-06-30" {
+		t.Errorf("range = %s to %s, want the requested range", bundle.Start, bundle.End)
+	}
+	in := bundle.Insights
+	if len(in.RecurringPayments) == 0 || len(in.CategoryTrends) == 0 || in.Velocity == nil {
+		t.Fatalf("bundle is missing analyses: %+v", in)
+	}
+	for _, rp := range in.RecurringPayments {
+		if len(rp.Transactions) != 0 {
+			t.Errorf("%s: export should omit transactions", rp.Description)
+		}
+	}
+
+	resp = ts.GETWithQuery("/insights/export", map[string]string{"start": "June"})
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+}
+
+// TestInsightsFees tests the money lost to fees KPI and fee drill-down
+func TestInsightsFees(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/insights")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Lost to Fees", "Interest &amp; Fees", "CREDIT CARD ANNUAL FEE", "ATM FEE").
+		NotContains("INTEREST INCOME")
+}
+
+// TestInsightsRecurringPartial tests the recurring payments partial
+func TestInsightsRecurringPartial(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/insights/recurring")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML()
+}
+
+// TestInsightsTrendsPartial tests the trends partial
+func TestInsightsTrendsPartial(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/insights/trends")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		Contains("Impact")
+
+	// A threshold above every category's spending hides them all
+	resp = ts.GETWithQuery("/insights/trends", map[string]string{"min_spend": "1000000"})
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("smaller categories hidden").
+		NotContains("<td")
+}
+
+// TestInsightsTrendsChartData tests the trends chart data endpoint
+func TestInsightsTrendsChartData(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/insights/trends/chart")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON()
+}
+
+// TestInsightsVelocityPartial tests the velocity partial
+func TestInsightsVelocityPartial(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/insights/velocity")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML()
+}
+
+// TestInsightsBurnDownChart tests the month burn-down chart
+func TestInsightsBurnDownChart(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/insights/velocity/burndown")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeJSON().
+		ContainsAll(`"Spent"`, `"Budget Pace"`, `"Last Month"`, "Day of 2025-12")
+
+	resp = ts.GET("/insights")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains(`id="chart-burndown"`)
+}
+
+// TestInsightsIncomePartial tests the income analysis partial
+func TestInsightsIncomePartial(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/insights/income")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML()
+}
+
+// TestFileManager tests the file manager page
+func TestFileManager(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	// transactions_edge.csv repeats two of its own rows
+	resp := ts.GET("/explorer/files")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll("duplicate transactions dropped (normalized matching)", "2 duplicates dropped")
+}
+
+// TestAmazonOrderImport tests enriching Amazon charges from an order history export
+func TestAmazonOrderImport(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	upload := func(csv string) *http.Response {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, _ := mw.CreateFormFile("file", "Retail.OrderHistory.1.csv")
+		part.Write([]byte(csv))
+		mw.Close()
+		return ts.POST("/explorer/amazon", mw.FormDataContentType(), &body)
+	}
+
+	resp := ts.GET("/explorer/amazon")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("No orders imported")
+
+	resp = upload("Date,Description,Amount\n2025-07-18,AMAZON PURCHASE,-45.67\n")
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+
+	// Matches the $45.67 AMAZON PURCHASE charged on 2025-07-18
+	resp = upload("Order Date,Order ID,Title,Category,Item Total\n07/15/2025,112-1,Anker USB-C Charger,Electronics,$45.67\n")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("1 order imported", "1 charge matched")
+
+	resp = ts.GET("/explorer/transactions?search=anker")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Amazon: Anker USB-C Charger", "Electronics")
+
+	req, _ := http.NewRequest("DELETE", ts.BaseURL+"/explorer/amazon", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("No orders imported")
+}
+
+// TestInsightsRecurringExport tests the recurring payments export in both
+// formats lists the payments shown in the panel
+func TestInsightsRecurringExport(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	// A date range is ignored: the export matches the panel, which covers all data
+	resp := ts.GETWithQuery("/insights/recurring/export", map[string]string{"format": "json", "start": "2024-09-01", "end": "2024-09-30"})
+	var rows []models.RecurringPayment
+	if err := json.Unmarshal([]byte(testutil.AssertResponse(t, resp).StatusOK().ContentTypeJSON().Body()), &rows); err != nil {
+		t.Fatalf("invalid export JSON: %v", err)
+	}
+	if len(rows) == 0 {
+		t.Fatal("expected recurring payments in the export")
+	}
+	var netflix *models.RecurringPayment
+	for i, row := range rows {
+		if i > 0 && row.AnnualCost > rows[i-1].AnnualCost {
+			t.Errorf("rows not ordered by annual cost: %.2f after %.2f", row.AnnualCost, rows[i-1].AnnualCost)
+		}
+		if len(row.Transactions) != 0 {
+			t.Errorf("%s: export should omit transactions", row.Description)
+		}
+		if row.Description == "netflix subscription" {
+			netflix = &rows[i]
+		}
+	}
+	if netflix == nil {
+		t.Fatalf("netflix subscription missing from export: %+v", rows)
+	}
+	if math.Abs(netflix.Amount-15.99) > 0.005 || netflix.Frequency != "monthly" || netflix.Occurrences < 12 {
+		t.Errorf("netflix subscription = %.2f %s x%d, want 15.99 monthly at least 12 times",
+			netflix.Amount, netflix.Frequency, netflix.Occurrences)
+	}
+
+	panel := ts.GET("/insights/recurring")
+	panelHTML := testutil.AssertResponse(t, panel).StatusOK().Body()
+	for _, row := range rows {
+		if !strings.Contains(panelHTML, row.Description) {
+			t.Errorf("exported %q is not in the recurring panel", row.Description)
+		}
+	}
+
+	resp = ts.GET("/insights/recurring/export")
+	body := testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("Description,Amount,Frequency,Annual Cost,Last Date,Next Expected,Occurrences,Confidence,Accounts").
+		Body()
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	if len(lines) != len(rows)+1 {
+		t.Errorf("CSV has %d rows, JSON has %d", len(lines)-1, len(rows))
+	}
+	if want := fmt.Sprintf("%s,%.2f,%s,%.2f,", rows[0].Description, rows[0].Amount, rows[0].Frequency, rows[0].AnnualCost); len(lines) > 1 && !strings.HasPrefix(lines[1], want) {
+		t.Errorf("first CSV row = %q, want prefix %q", lines[1], want)
+	}
+}
+
+// TestInsightsCancellationsPartial tests the cancelled subscriptions panel
+func TestInsightsCancellationsPartial(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/insights/cancellations")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML()
+}
+
+// TestCancelCandidates tests simulating cancelling recurring payments and
+// applying the savings to the what-if plan
+func TestCancelCandidates(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	form := "application/x-www-form-urlencoded"
+	livingExpenses := func() float64 {
+		resp := ts.GET("/api/v1/whatif")
+		var body struct {
+			Settings models.WhatIfSettings `json:"settings"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("invalid what-if JSON: %v", err)
+		}
+		return body.Settings.MonthlyLivingExpenses
+	}
+	before := livingExpenses()
+	if before <= 0 {
+		t.Fatalf("what-if living expenses = %.2f, want some to lower", before)
+	}
+
+	resp := ts.GET("/insights/candidates")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("No cancel candidates")
+
+	resp = ts.POST("/insights/candidates", form, strings.NewReader("description=&annual_cost=10"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+
+	resp = ts.POST("/insights/candidates", form,
+		strings.NewReader("description=netflix+subscription&amount=15.99&frequency=monthly&annual_cost=191.88"))
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("netflix subscription", "$191.88", "$15.99/month", "Savings Rate", "Retirement Success", "Apply to What-If")
+
+	resp = ts.POST("/insights/candidates/apply", form, nil)
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("What-if living expenses lowered").
+		Contains("No cancel candidates")
+	if after := livingExpenses(); math.Abs(after-(before-15.99)) > 0.005 {
+		t.Errorf("living expenses after applying = %.2f, want %.2f", after, before-15.99)
+	}
+
+	// Nothing is left to apply twice
+	resp = ts.POST("/insights/candidates/apply", form, nil)
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+
+	// Marking a candidate cancelled takes it off the list
+	ts.POST("/insights/candidates", form, strings.NewReader("description=netflix+subscription&annual_cost=191.88"))
+	ts.POST("/insights/cancellations", form, strings.NewReader("description=netflix+subscription&cancelled_on=2025-12-31"))
+	resp = ts.GET("/insights/candidates")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("No cancel candidates")
+}
+
+// TestInsightsBenchmarksPartial tests the category benchmark comparison panel
+func TestInsightsBenchmarksPartial(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GETWithQuery("/insights/benchmarks", map[string]string{
+		"start": "2024-07-01",
+		"end":   "2024-12-31",
+	})
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContentTypeHTML().
+		ContainsAll("Housing", "Groceries", "Rent", "Your spending")
+}
+
+// TestCategoryDrilldownExport tests the drilldown's CSV export and its
+// budget shortcut
+func TestCategoryDrilldownExport(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/dashboard/category/Groceries/export?start=2025-12-01&end=2025-12-31")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Date,Description,Category,Amount,Account,Source File",
+			"2025-12-25,COSTCO WHOLESALE,Groceries,198.45", "2025-12-02,WHOLE FOODS MARKET,Groceries,156.78").
+		NotContains("2025-11-")
+	if got := resp.Header.Get("Content-Disposition"); got != `attachment; filename="groceries_2025-12-01_to_2025-12-31.csv"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+
+	// Sep through Nov 2025 average $274.88 of Groceries
+	resp = ts.GET("/dashboard/category/Groceries?start=2025-12-01&end=2025-12-31")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("exportCategoryToCSV", `hx-post="/budgets"`, `value="275"`, "3-month average $274.88").
+		NotContains("current budget")
+
+	form := "application/x-www-form-urlencoded"
+	resp = ts.POST("/budgets", form, strings.NewReader("category=Groceries&limit=300&threshold=90"))
+	testutil.AssertResponse(t, resp).StatusOK()
+
+	resp = ts.GET("/dashboard/category/Groceries?start=2025-12-01&end=2025-12-31")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("current budget $300.00", `name="threshold" value="90"`)
+}
+
+// TestDashboardExcelExport tests downloading the dashboard's range as an
+// Excel workbook
+func TestDashboardExcelExport(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/dashboard")
+	testutil.AssertResponse(t, resp).StatusOK().Contains("Export Excel")
+
+	resp = ts.GET("/dashboard/export?start=2025-12-01&end=2025-12-31")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Disposition"); got != `attachment; filename="budget_2025-12-01_to_2025-12-31.xlsx"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	body := testutil.ReadBody(t, resp)
+	z, err := zip.NewReader(bytes.NewReader([]byte(body)), int64(len(body)))
+	if err != nil {
+		t.Fatalf("export isn't a ZIP file: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, f := range z.File {
+		r, _ := f.Open()
+		var b bytes.Buffer
+		b.ReadFrom(r)
+		r.Close()
+		parts[f.Name] = b.String()
+	}
+
+	if workbook := parts["xl/workbook.xml"]; !strings.Contains(workbook, `name="Summary"`) ||
+		!strings.Contains(workbook, `name="Monthly"`) || !strings.Contains(workbook, `name="Transactions"`) {
+		t.Errorf("workbook sheets = %s", workbook)
+	}
+	// Text is kept in the shared strings table, numbers in the sheets
+	text := parts["xl/sharedStrings.xml"]
+	if !strings.Contains(text, "2025-12-01 to 2025-12-31") || !strings.Contains(text, "Groceries") {
+		t.Error("summary sheet should show the period and spending by category")
+	}
+	if !strings.Contains(text, ">2025-12<") || strings.Contains(text, ">2025-11<") {
+		t.Error("monthly sheet should cover only December")
+	}
+	if !strings.Contains(text, "COSTCO WHOLESALE") || !strings.Contains(parts["xl/worksheets/sheet3.xml"], "<v>-198.45</v>") {
+		t.Error("transactions sheet should list December's transactions with signed amounts")
+	}
+}
+
+// TestDashboardJournalExport tests downloading the dashboard's range as a
+// Beancount or Ledger journal
+func TestDashboardJournalExport(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := ts.GET("/dashboard")
+	testutil.AssertResponse(t, resp).StatusOK().ContainsAll("/dashboard/export?format=beancount", "/dashboard/export?format=ledger")
+
+	resp = ts.GET("/dashboard/export?format=beancount&start=2025-12-01&end=2025-12-31")
+	if got := resp.Header.Get("Content-Disposition"); got != `attachment; filename="budget_2025-12-01_to_2025-12-31.beancount"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll(`option "operating_currency" "USD"`, "open Expenses:Groceries USD", "open Assets:Transactions USD",
+			`2025-12-20 * "RENT PAYMENT APT 204"`, "Expenses:Rent", "-1850.00 USD").
+		NotContains("2025-11-")
+
+	resp = ts.GET("/dashboard/export?format=ledger&start=2025-12-01&end=2025-12-31")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("account Expenses:Groceries", "2025/12/20 * RENT PAYMENT APT 204", "Income:Paycheck")
+
+	resp = ts.GET("/dashboard/export?format=qif")
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+}
+
+// TestColumnMapping tests mapping the columns of a CSV layout the loader
+// doesn't recognize
+func TestColumnMapping(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	file := filepath.Join(testutil.TestDataDir(), "mystery_2025-01.csv")
+	content := "Posting Dt,Memo Line,Out,In\n2025-01-14,LANTERN BOOKSHOP,23.50,\n2025-01-15,GIFT FROM GRANDMA,,100.00\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(file) })
+
+	resp := ts.GET("/filemanager")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("No rows load: no column for Date, Description, Amount or Debit/Credit", "/explorer/files/mystery_2025-01.csv/map")
+
+	resp = ts.GET("/explorer/files/mystery_2025-01.csv/map")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Map columns", "Posting Dt", "LANTERN BOOKSHOP", `value="mystery_*.csv"`)
+
+	resp = ts.GET("/explorer/files/nothing.csv/map")
+	testutil.AssertResponse(t, resp).Status(http.StatusNotFound)
+
+	form := "application/x-www-form-urlencoded"
+	path := "/explorer/files/mystery_2025-01.csv/map"
+	resp = ts.POST(path, form, strings.NewReader("pattern=other_*.csv&Date=Posting+Dt&Description=Memo+Line&Debit=Out&Credit=In"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest).Contains("doesn't match")
+	resp = ts.POST(path, form, strings.NewReader("pattern=mystery_*.csv&Description=Memo+Line&Debit=Out&Credit=In"))
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest).Contains("choose the Date column")
+
+	// Saving goes back to the file manager, where the file now loads
+	resp = ts.POST(path, form, strings.NewReader("pattern=mystery_*.csv&Date=Posting+Dt&Description=Memo+Line&Debit=Out&Credit=In"))
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("Columns mapped for <code>mystery_*.csv</code>", "2025-01-14 - 2025-01-15").
+		NotContains("No rows load")
+
+	resp = ts.GET("/explorer/transactions?search=lantern")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("LANTERN BOOKSHOP", "23.50")
+
+	req, _ := http.NewRequest("DELETE", ts.BaseURL+path, nil)
+	req.Header.Set("HX-Request", "true")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	testutil.AssertResponse(t, resp).Status(http.StatusNoContent)
+	if got := resp.Header.Get("HX-Redirect"); got != "/filemanager" {
+		t.Errorf("HX-Redirect = %q, want /filemanager", got)
+	}
+
+	resp = ts.GET("/explorer/transactions?search=lantern")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		NotContains("LANTERN BOOKSHOP")
+}
+
+// TestWhatIfSandbox tests that sandbox edits stay off the saved plan until
+// they're committed
+func TestWhatIfSandbox(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	jar, _ := cookiejar.New(nil)
+	browser := &http.Client{Jar: jar}
+	send := func(method, path, body string) *http.Response {
+		req, _ := http.NewRequest(method, ts.BaseURL+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := browser.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		return resp
+	}
+	livingExpenses := func() float64 {
+		resp := ts.GET("/api/v1/whatif")
+		var body struct {
+			Settings models.WhatIfSettings `json:"settings"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("invalid what-if JSON: %v", err)
+		}
+		return body.Settings.MonthlyLivingExpenses
+	}
+	before := livingExpenses()
+
+	// Read after the first load, which migrates the fixture in place
+	settingsPath := filepath.Join(cfg.SettingsDirectory, "whatif.json")
+	saved, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatalf("reading what-if settings: %v", err)
+	}
+
+	resp := send("GET", "/whatif", "")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("Try in sandbox")
+
+	resp = send("POST", "/whatif/sandbox", "")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		ContainsAll("aren't saved to your plan", "/whatif/sandbox/commit").
+		NotContains("Try in sandbox")
+
+	resp = send("POST", "/whatif/settings", "monthly_living_expenses=1234")
+	testutil.AssertResponse(t, resp).StatusOK()
+	if current, _ := os.ReadFile(settingsPath); !bytes.Equal(current, saved) {
+		t.Error("sandbox edit was written to the saved settings")
+	}
+	if got := livingExpenses(); got != before {
+		t.Errorf("saved living expenses = %.2f during sandbox, want %.2f", got, before)
+	}
+	resp = send("GET", "/whatif", "")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains(`value="1234"`)
+
+	// Discarding drops the edit
+	resp = send("DELETE", "/whatif/sandbox", "")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("Try in sandbox").
+		NotContains(`value="1234"`)
+
+	// Committing saves it
+	send("POST", "/whatif/sandbox", "")
+	send("POST", "/whatif/settings", "monthly_living_expenses=1234")
+	resp = send("POST", "/whatif/sandbox/commit", "")
+	testutil.AssertResponse(t, resp).
+		StatusOK().
+		Contains("Try in sandbox")
+	if got := livingExpenses(); got != 1234 {
+		t.Errorf("saved living expenses = %.2f after commit, want 1234", got)
+	}
+
+	// With the sandbox closed there's nothing left to commit
+	resp = send("POST", "/whatif/sandbox/commit", "")
+	testutil.AssertResponse(t, resp).Status(http.StatusBadRequest)
+
+	req, _ := http.NewRequest("POST", ts.BaseURL+"/whatif/sandbox/commit", nil)
+	req.AddCookie(&http.Cookie{Name: "budget_whatif_sandbox", Value: "expired"})
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	testutil.AssertResponse(t, resp).Status(http.StatusConflict)
 }
@@ -29,8 +29,10 @@ var endpoints = []endpoint{
 	// Dashboard partials
 	{path: "/dashboard/kpis", method: "GET", contentType: "text/html", contains: []string{"Total Income"}},
 	{path: "/dashboard/alerts", method: "GET", contentType: "text/html", contains: nil},
+	{path: "/dashboard/watchlist", method: "GET", contentType: "text/html", contains: nil},
 	{path: "/dashboard/charts/data/monthly", method: "GET", contentType: "application/json", contains: nil},
 	{path: "/dashboard/charts/data/category", method: "GET", contentType: "application/json", contains: nil},
+	{path: "/dashboard/charts/data/treemap", method: "GET", contentType: "application/json", contains: nil},
 	{path: "/dashboard/charts/data/cashflow", method: "GET", contentType: "application/json", contains: nil},
 	{path: "/dashboard/charts/data/merchants", method: "GET", contentType: "application/json", contains: nil},
 	{path: "/dashboard/charts/data/weekly", method: "GET", contentType: "application/json", contains: nil},
@@ -39,6 +41,7 @@ var endpoints = []endpoint{
 	// Explorer
 	{path: "/explorer/transactions", method: "GET", contentType: "text/html", contains: nil},
 	{path: "/explorer/files", method: "GET", contentType: "text/html", contains: nil},
+	{path: "/explorer/amazon", method: "GET", contentType: "text/html", contains: nil},
 
 	// Insights partials
 	{path: "/insights/recurring", method: "GET", contentType: "text/html", contains: nil},
@@ -46,12 +49,22 @@ var endpoints = []endpoint{
 	{path: "/insights/trends/chart", method: "GET", contentType: "application/json", contains: nil},
 	{path: "/insights/velocity", method: "GET", contentType: "text/html", contains: nil},
 	{path: "/insights/income", method: "GET", contentType: "text/html", contains: nil},
+	{path: "/insights/close", method: "GET", contentType: "text/html", contains: nil},
+	{path: "/insights/giving", method: "GET", contentType: "text/html", contains: nil},
+	{path: "/insights/statements", method: "GET", contentType: "text/html", contains: nil},
+	{path: "/insights/rhythm", method: "GET", contentType: "text/html", contains: nil},
+	{path: "/insights/rhythm/chart/weekday", method: "GET", contentType: "application/json", contains: nil},
+	{path: "/insights/rhythm/chart/payday", method: "GET", contentType: "application/json", contains: nil},
 
 	// What-if
 	{path: "/whatif/chart/projection", method: "GET", contentType: "application/json", contains: nil},
+	{path: "/whatif/savings", method: "GET", contentType: "text/html", contains: []string{"Automatic Savings Transfer"}},
+	{path: "/whatif/relocation", method: "GET", contentType: "text/html", contains: []string{"Relocation Comparison"}},
+	{path: "/whatif/stress", method: "GET", contentType: "text/html", contains: []string{"Stress Tests"}},
 
 	// API
 	{path: "/api/health", method: "GET", contentType: "application/json", contains: []string{`"status":"ok"`}},
+	{path: "/api/data/version", method: "GET", contentType: "application/json", contains: []string{`"version":`}},
 }
 
 type result struct {
@@ -0,0 +1,122 @@
+package accounts
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/models"
+	"budget2/internal/services/accounts"
+	"budget2/internal/services/analytics"
+	"budget2/internal/services/dataloader"
+	"budget2/internal/templates"
+)
+
+var (
+	loader   *dataloader.DataLoader
+	renderer *templates.Renderer
+	manager  *accounts.Manager
+)
+
+// Initialize sets up the accounts package with required dependencies
+func Initialize(l *dataloader.DataLoader, r *templates.Renderer, m *accounts.Manager) {
+	loader = l
+	renderer = r
+	manager = m
+}
+
+// RegisterRoutes registers the account routes
+func RegisterRoutes(r chi.Router) {
+	r.Get("/accounts", handleAccountsPage)
+	r.Get("/accounts/list", handleAccountsPartial)
+	r.Post("/accounts", handleAccountSave)
+	r.Delete("/accounts/{name}", handleAccountRemove)
+}
+
+func handleAccountsPage(w http.ResponseWriter, r *http.Request) {
+	data, err := accountsData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data["Title"] = "Accounts"
+	data["ActiveTab"] = "accounts"
+	renderer.Render(w, "base", data)
+}
+
+func handleAccountsPartial(w http.ResponseWriter, r *http.Request) {
+	renderAccounts(w)
+}
+
+// handleAccountSave adds an account, or replaces the one named by the
+// previous field (or the same name) so an account can be renamed
+func handleAccountSave(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	account := models.Account{
+		Name:        r.FormValue("name"),
+		Type:        r.FormValue("type"),
+		Institution: r.FormValue("institution"),
+		Files:       r.Form["files"],
+	}
+	if _, err := manager.SaveAccount(account, r.FormValue("previous")); err != nil {
+		http.Error(w, "Failed to save account: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderAccounts(w)
+}
+
+func handleAccountRemove(w http.ResponseWriter, r *http.Request) {
+	if _, err := manager.RemoveAccount(chi.URLParam(r, "name")); err != nil {
+		http.Error(w, "Failed to remove account: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderAccounts(w)
+}
+
+// accountsData gathers each account with its totals across the enabled
+// files, plus the data files the form can map to an account
+func accountsData() (map[string]interface{}, error) {
+	defined, err := manager.Accounts()
+	if err != nil {
+		return nil, err
+	}
+	data, err := loader.LoadData()
+	if err != nil {
+		return nil, err
+	}
+	files, err := loader.GetFileInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	return map[string]interface{}{
+		"Accounts":     analytics.AccountSummaries(defined, data),
+		"Files":        names,
+		"AccountTypes": models.AccountTypes,
+	}, nil
+}
+
+// renderAccounts renders the account list, or JSON without a renderer
+func renderAccounts(w http.ResponseWriter) {
+	data, err := accountsData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "account-list", data)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(data)
+	}
+}
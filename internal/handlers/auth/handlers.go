@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	apphttp "budget2/internal/http"
+	"budget2/internal/services/sessions"
+	"budget2/internal/templates"
+)
+
+// cookieName is the session cookie set on login
+const cookieName = "budget_session"
+
+var (
+	renderer *templates.Renderer
+	store    *sessions.Store
+	username string
+	password string
+)
+
+// Initialize sets up the auth package with required dependencies. An empty
+// user turns login off.
+func Initialize(r *templates.Renderer, s *sessions.Store, user, pass string) {
+	renderer = r
+	store = s
+	username = user
+	password = pass
+}
+
+// RegisterRoutes registers the login routes, which must stay reachable
+// without a session
+func RegisterRoutes(r chi.Router) {
+	r.Get("/login", handleLoginPage)
+	r.Post("/login", handleLogin)
+	r.Post("/logout", handleLogout)
+	r.Get("/session", handleSessionMenu)
+}
+
+// Enabled reports whether a username is configured, so pages need a login
+func Enabled() bool {
+	return username != ""
+}
+
+// RequireLogin lets requests with a valid session through. Others are sent
+// to the login page when they are page loads, told to go there by HTMX when
+// they are partial requests, and refused otherwise. With login off every
+// request passes.
+func RequireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Enabled() || signedIn(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		loginURL := "/login?next=" + url.QueryEscape(r.URL.RequestURI())
+		switch {
+		case r.Header.Get("HX-Request") == "true":
+			w.Header().Set("HX-Redirect", loginURL)
+			http.Error(w, "Login required", http.StatusUnauthorized)
+		case r.Method == http.MethodGet && !strings.HasPrefix(r.URL.Path, "/api/"):
+			http.Redirect(w, r, loginURL, http.StatusSeeOther)
+		default:
+			http.Error(w, "Login required", http.StatusUnauthorized)
+		}
+	})
+}
+
+// signedIn reports whether the request carries a live session cookie
+func signedIn(r *http.Request) bool {
+	cookie, err := r.Cookie(cookieName)
+	return err == nil && store.Valid(cookie.Value)
+}
+
+func handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	if !Enabled() || signedIn(r) {
+		http.Redirect(w, r, safeNext(r.URL.Query().Get("next")), http.StatusSeeOther)
+		return
+	}
+	renderLogin(w, r.URL.Query().Get("next"), "")
+}
+
+func handleLogin(w http.ResponseWriter, r *http.Request) {
+	if !Enabled() {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	next := r.FormValue("next")
+	if !checkCredentials(r.FormValue("username"), r.FormValue("password")) {
+		log.Printf("Failed login for %q from %s", r.FormValue("username"), r.RemoteAddr)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusUnauthorized)
+		renderLogin(w, next, "Incorrect username or password")
+		return
+	}
+
+	token, expires, err := store.Create()
+	if err != nil {
+		http.Error(w, "Failed to start session: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   apphttp.IsHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, safeNext(next), http.StatusSeeOther)
+}
+
+func handleLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(cookieName); err == nil {
+		store.Revoke(cookie.Value)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// handleSessionMenu renders the navigation's sign out button, which is
+// empty when login is off
+func handleSessionMenu(w http.ResponseWriter, r *http.Request) {
+	renderer.RenderPartial(w, "session-menu", map[string]interface{}{
+		"SignedIn": Enabled() && signedIn(r),
+		"Username": username,
+	})
+}
+
+// checkCredentials compares a login with the configured one. A password
+// starting with "$2" is a bcrypt hash; anything else is compared as text.
+func checkCredentials(user, pass string) bool {
+	userOK := subtle.ConstantTimeCompare([]byte(user), []byte(username)) == 1
+	var passOK bool
+	if strings.HasPrefix(password, "$2") {
+		passOK = bcrypt.CompareHashAndPassword([]byte(password), []byte(pass)) == nil
+	} else {
+		passOK = subtle.ConstantTimeCompare([]byte(pass), []byte(password)) == 1
+	}
+	return userOK && passOK
+}
+
+// safeNext returns where to go after logging in, allowing only paths on
+// this server so the login page can't redirect elsewhere
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
+
+// renderLogin renders the login page with an optional error
+func renderLogin(w http.ResponseWriter, next, errMsg string) {
+	renderer.Render(w, "base", map[string]interface{}{
+		"Title":     "Sign In",
+		"ActiveTab": "login",
+		"Next":      next,
+		"Error":     errMsg,
+	})
+}
@@ -14,24 +14,48 @@ import (
 	"time"
 
 	"budget2/internal/config"
+	"budget2/internal/services/dataloader"
 	"budget2/internal/services/storage"
+	"budget2/internal/services/warmup"
+	"budget2/internal/templates"
 	"budget2/testdata"
 )
 
 var (
-	cfg   *config.Config
-	store *storage.Storage
+	cfg      *config.Config
+	store    *storage.Storage
+	loader   *dataloader.DataLoader
+	warmer   *warmup.Warmer
+	renderer *templates.Renderer
 )
 
 // Initialize sets up the backup package with required dependencies
-func Initialize(c *config.Config, s *storage.Storage) {
+func Initialize(c *config.Config, s *storage.Storage, l *dataloader.DataLoader, w *warmup.Warmer, r *templates.Renderer) {
 	cfg = c
 	store = s
+	loader = l
+	warmer = w
+	renderer = r
 }
 
+// HandleHealth reports liveness plus warm-up readiness and the scheduled
+// refresh. status stays "ok" while warming so liveness checks pass; ready
+// flips once caches are built.
 func HandleHealth(w http.ResponseWriter, r *http.Request) {
+	health := map[string]interface{}{"status": "ok", "ready": true}
+	if warmer != nil {
+		status := warmer.Status()
+		health["ready"] = status.Ready
+		if len(status.Steps) > 0 {
+			health["warmup"] = status.Steps
+		}
+		if status.Refresh != nil {
+			health["refresh"] = status.Refresh
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	json.NewEncoder(w).Encode(health)
 }
 
 func HandleKillServer(w http.ResponseWriter, r *http.Request) {
@@ -59,13 +83,17 @@ func HandleBackup(w http.ResponseWriter, r *http.Request) {
 
 	// Walk the data directory
 	dataDir := cfg.DataDirectory
-	err := filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
+	err := store.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip directories
+		// Skip directories, and the cache directory since everything in it
+		// (downloads, the transaction database) is rebuilt on demand
 		if info.IsDir() {
+			if info.Name() == "cache" {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
@@ -140,35 +168,17 @@ func HandleRestore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Extract all CSV files from the zip
+	// Extract all data files from the zip
 	restoredCount := 0
 	for _, zipFile := range zipReader.File {
-		// Skip directories
-		if zipFile.FileInfo().IsDir() {
-			continue
-		}
-
-		// Only extract CSV files
-		if !strings.HasSuffix(strings.ToLower(zipFile.Name), ".csv") {
-			continue
-		}
-
-		// Sanitize filename - use only the base name to prevent path traversal
-		baseName := filepath.Base(zipFile.Name)
-		if strings.Contains(baseName, "..") {
-			continue
-		}
-
-		// Open the file in the zip
-		rc, err := zipFile.Open()
-		if err != nil {
-			log.Printf("Error opening zip entry %s: %v", zipFile.Name, err)
+		// Only extract CSV and QIF files, by base name
+		baseName, ok := restoreName(zipFile)
+		if !ok {
 			continue
 		}
 
 		// Read content from zip
-		data, err := io.ReadAll(rc)
-		rc.Close()
+		data, err := readEntry(zipFile)
 		if err != nil {
 			log.Printf("Error reading zip entry %s: %v", zipFile.Name, err)
 			continue
@@ -184,9 +194,10 @@ func HandleRestore(w http.ResponseWriter, r *http.Request) {
 		restoredCount++
 		log.Printf("Restored file: %s", baseName)
 	}
+	loader.Invalidate()
 
 	if restoredCount == 0 {
-		http.Error(w, "No CSV files found in backup", http.StatusBadRequest)
+		http.Error(w, "No CSV or QIF files found in backup", http.StatusBadRequest)
 		return
 	}
 
@@ -254,6 +265,7 @@ func HandleRestoreTestData(w http.ResponseWriter, r *http.Request) {
 		restoredCount++
 		log.Printf("Restored file from test data: %s", baseName)
 	}
+	loader.Invalidate()
 
 	if restoredCount == 0 {
 		http.Error(w, "No CSV files found in test backup", http.StatusBadRequest)
@@ -266,31 +278,27 @@ func HandleRestoreTestData(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleDeleteAllData(w http.ResponseWriter, r *http.Request) {
-	// Read data directory
-	entries, err := os.ReadDir(cfg.DataDirectory)
+	// Find data files in the data directory (directories and other files are kept)
+	files, err := store.Glob(filepath.Join(cfg.DataDirectory, "*"))
 	if err != nil {
 		http.Error(w, "Error reading data directory", http.StatusInternalServerError)
 		return
 	}
 
 	deletedCount := 0
-	for _, entry := range entries {
-		// Only delete CSV files, skip directories and other files
-		if entry.IsDir() {
-			continue
-		}
-		if !strings.HasSuffix(strings.ToLower(entry.Name()), ".csv") {
+	for _, filePath := range files {
+		if !dataloader.IsDataFile(filePath) {
 			continue
 		}
 
-		filePath := filepath.Join(cfg.DataDirectory, entry.Name())
 		if err := store.Remove(filePath); err != nil {
 			log.Printf("Error deleting file %s: %v", filePath, err)
 			continue
 		}
 		deletedCount++
-		log.Printf("Deleted file: %s", entry.Name())
+		log.Printf("Deleted file: %s", filepath.Base(filePath))
 	}
+	loader.Invalidate()
 
 	log.Printf("Deleted %d data files", deletedCount)
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,133 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"budget2/internal/models"
+	"budget2/internal/services/dataloader"
+	"budget2/internal/services/storage"
+)
+
+// restoreName returns the name a restore writes an archive entry under, and
+// whether it restores the entry at all. Only data files are restored, by
+// base name so an entry can't escape the data directory.
+func restoreName(f *zip.File) (string, bool) {
+	if f.FileInfo().IsDir() || !dataloader.IsDataFile(f.Name) {
+		return "", false
+	}
+	baseName := filepath.Base(f.Name)
+	if strings.Contains(baseName, "..") {
+		return "", false
+	}
+	return baseName, true
+}
+
+// HandleVerifyBackup checks an uploaded backup without restoring it: every
+// entry must read back with a matching checksum and settings files must
+// match the schema their writer registered. Settings are checked but never
+// restored. The report lists which data files a restore would
+// add, replace or leave unchanged.
+func HandleVerifyBackup(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(50 << 20); err != nil {
+		http.Error(w, "File too large", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Error reading file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Error reading file", http.StatusInternalServerError)
+		return
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		http.Error(w, "Invalid ZIP file", http.StatusBadRequest)
+		return
+	}
+
+	report := verifyArchive(zipReader)
+	report.Filename = header.Filename
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "backup-report", report)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// verifyArchive reads every entry of a backup, comparing its data files
+// with the current ones
+func verifyArchive(zr *zip.Reader) models.BackupReport {
+	report := models.BackupReport{
+		Added:     []models.BackupFile{},
+		Replaced:  []models.BackupFile{},
+		Unchanged: []models.BackupFile{},
+		Settings:  []models.BackupFile{},
+		Skipped:   []string{},
+		Problems:  []models.BackupProblem{},
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		report.Entries++
+
+		// Reading an entry to the end checks its CRC-32
+		data, err := readEntry(f)
+		if err != nil {
+			report.Problems = append(report.Problems, models.BackupProblem{Name: f.Name, Error: err.Error()})
+			continue
+		}
+		entry := models.BackupFile{Name: f.Name, Size: int64(len(data))}
+
+		if name, ok := restoreName(f); ok {
+			entry.Name = name
+			current, err := store.ReadFile(filepath.Join(cfg.DataDirectory, name))
+			switch {
+			case err != nil:
+				report.Added = append(report.Added, entry)
+			case bytes.Equal(current, data):
+				report.Unchanged = append(report.Unchanged, entry)
+			default:
+				entry.CurrentSize = int64(len(current))
+				report.Replaced = append(report.Replaced, entry)
+			}
+			continue
+		}
+
+		if path.Dir(f.Name) == "settings" && path.Ext(f.Name) == ".json" {
+			if err := storage.CheckSettings(path.Base(f.Name), data); err != nil {
+				report.Problems = append(report.Problems, models.BackupProblem{Name: f.Name, Error: err.Error()})
+			} else {
+				report.Settings = append(report.Settings, entry)
+			}
+			continue
+		}
+		report.Skipped = append(report.Skipped, f.Name)
+	}
+	return report
+}
+
+// readEntry reads an archive entry, failing if its checksum doesn't match
+func readEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
@@ -0,0 +1,46 @@
+package budgets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	apphttp "budget2/internal/http"
+	"budget2/internal/services/budgets"
+)
+
+// handleActualsAPI returns every category budget against actual spending,
+// month by month over the request's range, as JSON for external dashboards
+func handleActualsAPI(w http.ResponseWriter, r *http.Request) {
+	list, err := manager.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err := loader.LoadSources(apphttp.ParseSources(r.URL.Query()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if accounts := apphttp.ParseAccounts(r.URL.Query()); len(accounts) > 0 {
+		data = data.FilterByAccounts(accounts)
+	}
+
+	start, end := data.MinDate(), data.MaxDate()
+	if s := r.URL.Query().Get("start"); s != "" {
+		if start, err = time.Parse("2006-01-02", s); err != nil {
+			http.Error(w, fmt.Sprintf("start must be YYYY-MM-DD, got %q", s), http.StatusBadRequest)
+			return
+		}
+	}
+	if e := r.URL.Query().Get("end"); e != "" {
+		if end, err = time.Parse("2006-01-02", e); err != nil {
+			http.Error(w, fmt.Sprintf("end must be YYYY-MM-DD, got %q", e), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budgets.Actuals(list, data, start, end))
+}
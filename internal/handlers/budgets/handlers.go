@@ -0,0 +1,149 @@
+package budgets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/models"
+	"budget2/internal/services/budgets"
+	"budget2/internal/services/dataloader"
+	"budget2/internal/services/planned"
+	"budget2/internal/templates"
+)
+
+var (
+	loader   *dataloader.DataLoader
+	renderer *templates.Renderer
+	manager  *budgets.Manager
+	plans    *planned.Manager
+)
+
+// Initialize sets up the budgets package with required dependencies
+func Initialize(l *dataloader.DataLoader, r *templates.Renderer, m *budgets.Manager, p *planned.Manager) {
+	loader = l
+	renderer = r
+	manager = m
+	plans = p
+}
+
+// RegisterAPIRoutes registers the category budget JSON API routes
+func RegisterAPIRoutes(r chi.Router) {
+	r.Get("/api/v1/budgets/actuals", handleActualsAPI)
+}
+
+// RegisterRoutes registers the category budget routes
+func RegisterRoutes(r chi.Router) {
+	r.Get("/budgets", handleBudgetsPage)
+	r.Get("/budgets/list", handleBudgetsPartial)
+	r.Post("/budgets", handleBudgetSet)
+	r.Delete("/budgets/{category}", handleBudgetRemove)
+	r.Get("/budgets/planned", handlePlannedPartial)
+	r.Post("/budgets/planned", handlePlannedAdd)
+	r.Delete("/budgets/planned/{id}", handlePlannedRemove)
+}
+
+func handleBudgetsPage(w http.ResponseWriter, r *http.Request) {
+	data, err := budgetsData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	purchases, err := plannedData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data["Planned"] = purchases["Planned"]
+	data["Title"] = "Budgets"
+	data["ActiveTab"] = "budgets"
+	renderer.Render(w, "base", data)
+}
+
+func handleBudgetsPartial(w http.ResponseWriter, r *http.Request) {
+	renderBudgets(w)
+}
+
+// handleBudgetSet adds a category's budget, or replaces the one it has
+func handleBudgetSet(w http.ResponseWriter, r *http.Request) {
+	budget, err := parseBudget(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := manager.Set(budget); err != nil {
+		http.Error(w, "Failed to save budget: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderBudgets(w)
+}
+
+func handleBudgetRemove(w http.ResponseWriter, r *http.Request) {
+	if _, err := manager.Remove(chi.URLParam(r, "category")); err != nil {
+		http.Error(w, "Failed to remove budget: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderBudgets(w)
+}
+
+// parseBudget reads a category's monthly budget from a form. The alert
+// threshold is an optional percentage of the budget.
+func parseBudget(r *http.Request) (models.CategoryBudget, error) {
+	if err := r.ParseForm(); err != nil {
+		return models.CategoryBudget{}, err
+	}
+
+	budget := models.CategoryBudget{Category: r.FormValue("category")}
+	limit, err := strconv.ParseFloat(strings.TrimSpace(r.FormValue("limit")), 64)
+	if err != nil {
+		return budget, fmt.Errorf("monthly budget must be a number")
+	}
+	budget.Limit = limit
+
+	if s := strings.TrimSpace(r.FormValue("threshold")); s != "" {
+		percent, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return budget, fmt.Errorf("alert threshold must be a number")
+		}
+		budget.AlertThreshold = percent / 100
+	}
+	return budget, nil
+}
+
+// budgetsData gathers this month's progress against each budget, plus the
+// known categories for the form's suggestions
+func budgetsData() (map[string]interface{}, error) {
+	list, err := manager.List()
+	if err != nil {
+		return nil, err
+	}
+	data, err := loader.LoadData()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"Budgets":    budgets.Evaluate(list, data),
+		"Categories": data.Categories(),
+	}, nil
+}
+
+// renderBudgets renders the budgets list, or JSON without a renderer
+func renderBudgets(w http.ResponseWriter) {
+	data, err := budgetsData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "category-budgets", data)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(data)
+	}
+}
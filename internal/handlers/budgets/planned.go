@@ -0,0 +1,100 @@
+package budgets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"budget2/internal/models"
+	"budget2/internal/services/planned"
+)
+
+func handlePlannedPartial(w http.ResponseWriter, r *http.Request) {
+	renderPlanned(w)
+}
+
+// handlePlannedAdd saves a planned purchase from the form
+func handlePlannedAdd(w http.ResponseWriter, r *http.Request) {
+	p, err := parsePlanned(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := plans.Add(p); err != nil {
+		http.Error(w, "Failed to save planned purchase: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderPlanned(w)
+}
+
+func handlePlannedRemove(w http.ResponseWriter, r *http.Request) {
+	if _, err := plans.Remove(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, "Failed to remove planned purchase: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderPlanned(w)
+}
+
+// parsePlanned reads a planned purchase from a form. Match text and a
+// category narrow which outflows count as its receipts; both are optional.
+func parsePlanned(r *http.Request) (models.PlannedPurchase, error) {
+	if err := r.ParseForm(); err != nil {
+		return models.PlannedPurchase{}, err
+	}
+
+	p := models.PlannedPurchase{
+		ID:        uuid.New().String(),
+		Item:      r.FormValue("item"),
+		Month:     strings.TrimSpace(r.FormValue("month")),
+		Match:     r.FormValue("match"),
+		Category:  r.FormValue("category"),
+		CreatedAt: time.Now(),
+	}
+	cost, err := strconv.ParseFloat(strings.TrimSpace(r.FormValue("cost")), 64)
+	if err != nil {
+		return p, fmt.Errorf("expected cost must be a number")
+	}
+	p.ExpectedCost = cost
+	return p, nil
+}
+
+// plannedData compares each planned purchase with its receipts across all
+// files, plus the known categories for the form's suggestions
+func plannedData() (map[string]interface{}, error) {
+	list, err := plans.List()
+	if err != nil {
+		return nil, err
+	}
+	data, err := loader.LoadData()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"Planned":    planned.Evaluate(list, data),
+		"Categories": data.Categories(),
+	}, nil
+}
+
+// renderPlanned renders the planned purchases panel, or JSON without a
+// renderer
+func renderPlanned(w http.ResponseWriter) {
+	data, err := plannedData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "planned-purchases", data)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(data)
+	}
+}
@@ -0,0 +1,89 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/analytics"
+)
+
+// metricsResponse is the JSON served by /api/v1/metrics
+type metricsResponse struct {
+	Start    string                   `json:"start"`
+	End      string                   `json:"end"`
+	Metrics  *models.DashboardMetrics `json:"metrics"`
+	NetWorth *models.NetWorth         `json:"net_worth,omitempty"`
+	Alerts   []models.SpendingAlert   `json:"alerts"`
+}
+
+// handleMetricsAPI returns the dashboard's KPIs, net worth and alerts for
+// the request's range as JSON
+func handleMetricsAPI(w http.ResponseWriter, r *http.Request) {
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	startDate, endDate, err := apiRange(r, data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filtered := data.FilterByDateRange(startDate, endDate)
+	alerts := dashboardAlerts(data, filtered)
+	if alerts == nil {
+		alerts = []models.SpendingAlert{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metricsResponse{
+		Start:    startDate.Format("2006-01-02"),
+		End:      endDate.Format("2006-01-02"),
+		Metrics:  calculateMetrics(r, startDate, endDate, data),
+		NetWorth: netWorth(r, data, endDate),
+		Alerts:   alerts,
+	})
+}
+
+// handleCategoriesAPI returns spending by category for the request's range
+// as JSON, or income by category with type=Income
+func handleCategoriesAPI(w http.ResponseWriter, r *http.Request) {
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	startDate, endDate, err := apiRange(r, data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	breakdown := analytics.SpendingByCategory(data, startDate, endDate)
+	if r.URL.Query().Get("type") == "Income" {
+		breakdown = analytics.IncomeByCategory(data, startDate, endDate)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(breakdown)
+}
+
+// apiRange reads the start and end parameters, defaulting to all of data
+func apiRange(r *http.Request, data *models.TransactionSet) (start, end time.Time, err error) {
+	start, end = data.MinDate(), data.MaxDate()
+	if s := r.URL.Query().Get("start"); s != "" {
+		if start, err = time.Parse("2006-01-02", s); err != nil {
+			return start, end, fmt.Errorf("start must be YYYY-MM-DD, got %q", s)
+		}
+	}
+	if e := r.URL.Query().Get("end"); e != "" {
+		if end, err = time.Parse("2006-01-02", e); err != nil {
+			return start, end, fmt.Errorf("end must be YYYY-MM-DD, got %q", e)
+		}
+	}
+	return start, end, nil
+}
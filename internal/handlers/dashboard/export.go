@@ -0,0 +1,133 @@
+package dashboard
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	apphttp "budget2/internal/http"
+	"budget2/internal/models"
+	"budget2/internal/services/journal"
+	"budget2/internal/services/xlsx"
+)
+
+// handleExport downloads the dashboard's range as an Excel workbook: a
+// summary sheet with the KPIs and spending by category, a monthly
+// breakdown, and every transaction. format=beancount or format=ledger
+// downloads the transactions as a plain-text accounting journal instead.
+// It honors the same dates, files and account as the dashboard.
+func handleExport(w http.ResponseWriter, r *http.Request) {
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	q := r.URL.Query()
+	startDate, endDate := apphttp.ParseDateRange(q.Get("start"), q.Get("end"), data.MinDate(), data.MaxDate())
+	filtered := data.FilterByDateRange(startDate, endDate)
+	if format := q.Get("format"); format != "" && format != "xlsx" {
+		f, ok := journal.ParseFormat(format)
+		if !ok {
+			http.Error(w, "Unknown export format "+format, http.StatusBadRequest)
+			return
+		}
+		filename := fmt.Sprintf("budget_%s_to_%s%s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), f.Extension())
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+		if err := journal.Write(w, f, filtered.Transactions); err != nil {
+			log.Printf("Warning: writing %s export: %v", f, err)
+		}
+		return
+	}
+	metrics := calculateMetrics(r, startDate, endDate, data)
+
+	wb := xlsx.New()
+	summary := wb.Sheet("Summary", 28, 16, 18)
+	summary.Row(xlsx.Bold("Budget summary"))
+	summary.Row("Period", startDate.Format("2006-01-02")+" to "+endDate.Format("2006-01-02"))
+	if sources := apphttp.ParseSources(q); len(sources) > 0 {
+		summary.Row("Files", strings.Join(sources, ", "))
+	}
+	if accounts := apphttp.ParseAccounts(q); len(accounts) > 0 {
+		summary.Row("Accounts", strings.Join(accounts, ", "))
+	}
+	summary.Blank()
+	summary.Row("Total income", xlsx.Money(metrics.TotalIncome))
+	summary.Row("Total expenses", xlsx.Money(metrics.TotalExpenses))
+	summary.Row("Net savings", xlsx.Money(metrics.NetSavings))
+	summary.Row("Savings rate", xlsx.Percent(metrics.SavingsRate))
+	summary.Row("Transactions", metrics.TransactionCount)
+	if nw := netWorth(r, data, endDate); nw != nil {
+		summary.Row("Net worth on "+nw.Date, xlsx.Money(nw.Total))
+	}
+
+	// Spending by category, biggest first
+	spending := filtered.FilterByType(models.Outflow).CategoryTotals()
+	categories := make([]string, 0, len(spending))
+	for c := range spending {
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if spending[categories[i]] != spending[categories[j]] {
+			return spending[categories[i]] > spending[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+	if len(categories) > 0 {
+		summary.Blank()
+		summary.Header("Category", "Spent", "Share of expenses")
+		for _, c := range categories {
+			var share interface{}
+			if metrics.TotalExpenses > 0 {
+				share = xlsx.Percent(spending[c] / metrics.TotalExpenses * 100)
+			}
+			summary.Row(c, xlsx.Money(spending[c]), share)
+		}
+	}
+
+	monthly := wb.Sheet("Monthly", 10, 14, 14, 14, 14)
+	monthly.Header("Month", "Income", "Expenses", "Savings", "Savings rate")
+	monthlyIncome := filtered.FilterByType(models.Income).GroupByMonth()
+	monthlyOutflows := filtered.FilterByType(models.Outflow).GroupByMonth()
+	monthSet := make(map[string]bool)
+	for m := range monthlyIncome {
+		monthSet[m] = true
+	}
+	for m := range monthlyOutflows {
+		monthSet[m] = true
+	}
+	months := make([]string, 0, len(monthSet))
+	for m := range monthSet {
+		months = append(months, m)
+	}
+	sort.Strings(months)
+	for _, m := range months {
+		var income, expenses float64
+		if inc, ok := monthlyIncome[m]; ok {
+			income = inc.SumAmount()
+		}
+		if exp, ok := monthlyOutflows[m]; ok {
+			expenses = exp.SumAbsAmount()
+		}
+		var rate interface{}
+		if income > 0 {
+			rate = xlsx.Percent((income - expenses) / income * 100)
+		}
+		monthly.Row(m, xlsx.Money(income), xlsx.Money(expenses), xlsx.Money(income-expenses), rate)
+	}
+
+	txns := wb.Sheet("Transactions", 12, 40, 20, 10, 12, 20, 20, 24)
+	txns.Header("Date", "Description", "Category", "Type", "Amount", "Account", "Tags", "Source File")
+	for _, t := range filtered.SortByDateDesc().Transactions {
+		txns.Row(t.Date, t.Description, t.Category, string(t.TransactionType), xlsx.Money(t.Amount),
+			t.Account, strings.Join(t.Tags, ", "), t.SourceFile)
+	}
+
+	filename := fmt.Sprintf("budget_%s_to_%s.xlsx", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	wb.WriteTo(w)
+}
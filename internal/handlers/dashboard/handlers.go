@@ -7,41 +7,249 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/go-chi/chi/v5"
 	"net/http"
 
+	"budget2/internal/handlers/insights"
+	apphttp "budget2/internal/http"
 	"budget2/internal/models"
+	"budget2/internal/services/accounts"
+	"budget2/internal/services/analytics"
+	"budget2/internal/services/budgets"
+	"budget2/internal/services/cache"
 	"budget2/internal/services/dataloader"
+	"budget2/internal/services/networth"
+	"budget2/internal/services/visits"
+	"budget2/internal/services/watchlist"
 	"budget2/internal/templates"
 )
 
 var (
 	loader   *dataloader.DataLoader
 	renderer *templates.Renderer
+	tracker  *visits.Tracker
+	watched  *watchlist.Manager
+	budgeted *budgets.Manager
+	balances *networth.Manager
+	defined  *accounts.Manager
+
+	// defaultTrendMonths is the sparkline window when the request doesn't
+	// pick one
+	defaultTrendMonths = analytics.DefaultTrendMonths
+
+	// maxAlerts is how many spending alerts the dashboard shows
+	maxAlerts = analytics.DefaultMaxAlerts
+
+	// budgetCycle is the configured budget cycle; the weekly cycle adds the
+	// This Week card
+	budgetCycle models.BudgetCycle
 )
 
-// Initialize sets up the dashboard package with required dependencies
-func Initialize(l *dataloader.DataLoader, r *templates.Renderer) {
+// chartCache briefly memoizes filtered data and chart results so a burst of
+// identical chart requests is served from one computation
+var chartCache = cache.New(30 * time.Second)
+
+// Initialize sets up the dashboard package with required dependencies.
+// trendMonths is the configured sparkline window; anything other than one of
+// analytics.TrendWindows keeps the default, as does an alerts limit under one.
+func Initialize(l *dataloader.DataLoader, r *templates.Renderer, v *visits.Tracker, wl *watchlist.Manager, b *budgets.Manager,
+	nw *networth.Manager, a *accounts.Manager, trendMonths, alerts int, cycle models.BudgetCycle) {
 	loader = l
 	renderer = r
+	tracker = v
+	watched = wl
+	budgeted = b
+	balances = nw
+	defined = a
+	if analytics.ValidTrendMonths(trendMonths) {
+		defaultTrendMonths = trendMonths
+	}
+	if alerts > 0 {
+		maxAlerts = alerts
+	}
+	budgetCycle = cycle
+}
+
+// parseTrendMonths returns the sparkline window from the trend query
+// parameter, falling back to the configured default
+func parseTrendMonths(r *http.Request) int {
+	if months, err := strconv.Atoi(r.URL.Query().Get("trend")); err == nil && analytics.ValidTrendMonths(months) {
+		return months
+	}
+	return defaultTrendMonths
+}
+
+// metricsCache holds dashboard KPIs by range and request scope. The
+// background refresh keeps the default view's entry current.
+var metricsCache = cache.New(5 * time.Minute)
+
+// calculateMetrics computes the KPIs for start to end in all with the
+// request's sparkline window and year-over-year overlays, cached by the
+// request's sources and accounts
+func calculateMetrics(r *http.Request, start, end time.Time, all *models.TransactionSet) *models.DashboardMetrics {
+	trendMonths := parseTrendMonths(r)
+	compute := func() interface{} {
+		return computeMetrics(all, start, end, trendMonths)
+	}
+	version, err := loader.DataVersion()
+	if err != nil {
+		return compute().(*models.DashboardMetrics)
+	}
+	key := metricsKey(start, end, trendMonths,
+		strings.Join(apphttp.ParseSources(r.URL.Query()), ","), strings.Join(apphttp.ParseAccounts(r.URL.Query()), ","))
+	return metricsCache.GetOrCompute(version, key, compute).(*models.DashboardMetrics)
+}
+
+// computeMetrics computes the KPIs for start to end in all
+func computeMetrics(all *models.TransactionSet, start, end time.Time, trendMonths int) *models.DashboardMetrics {
+	metrics := analytics.CalculateMetricsWithTrend(all.FilterByDateRange(start, end), trendMonths)
+	analytics.AddYearOverYear(metrics, all)
+	return metrics
+}
+
+// metricsKey is the metrics cache key for a range, sparkline window and
+// request scope
+func metricsKey(start, end time.Time, trendMonths int, sources, accounts string) string {
+	return cache.Key("metrics", start, end, trendMonths, sources, accounts)
+}
+
+// defaultRange is the dashboard's range when the request doesn't pick one:
+// the year to date, or all time if the data ends before this year
+func defaultRange(data *models.TransactionSet, now time.Time) (start, end time.Time) {
+	minDate, maxDate := data.MinDate(), data.MaxDate()
+	start = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.Local)
+	if !maxDate.IsZero() && start.After(maxDate) {
+		start = minDate
+	} else if start.Before(minDate) {
+		start = minDate
+	}
+	return start, maxDate
+}
+
+// Warm precomputes the default dashboard's KPIs so the first visit after a
+// restart is served from cache
+func Warm() error {
+	return precompute(metricsCache.GetOrCompute)
+}
+
+// Refresh recomputes the default dashboard's KPIs for the background
+// refresh, keeping them cached until the next run
+func Refresh() error {
+	return precompute(metricsCache.Refresh)
+}
+
+// precompute stores the default dashboard's KPIs with store
+func precompute(store func(version, key string, compute func() interface{}) interface{}) error {
+	version, err := loader.DataVersion()
+	if err != nil {
+		return err
+	}
+	data, err := loader.LoadData()
+	if err != nil {
+		return err
+	}
+
+	start, end := defaultRange(data, time.Now())
+	store(version, metricsKey(start, end, defaultTrendMonths, "", ""), func() interface{} {
+		return computeMetrics(data, start, end, defaultTrendMonths)
+	})
+	return nil
+}
+
+// loadData loads the request's transactions, narrowed to the CSV files in
+// the sources parameter and the accounts in the account parameter if given
+func loadData(r *http.Request) (*models.TransactionSet, error) {
+	data, err := loader.LoadSources(apphttp.ParseSources(r.URL.Query()))
+	if err != nil {
+		return nil, err
+	}
+	if accounts := apphttp.ParseAccounts(r.URL.Query()); len(accounts) > 0 {
+		data = data.FilterByAccounts(accounts)
+	}
+	return data, nil
+}
+
+// netWorth returns net worth as of end for the request's accounts, or nil
+// when no balances are known. With a sources or account filter, only the
+// accounts in data count.
+func netWorth(r *http.Request, data *models.TransactionSet, end time.Time) *models.NetWorth {
+	if balances == nil {
+		return nil
+	}
+	var accountList []models.Account
+	if defined != nil {
+		accountList, _ = defined.Accounts()
+	}
+	list, err := balances.Balances(accountList, data)
+	if err != nil {
+		log.Printf("Warning: failed to load balances: %v", err)
+		return nil
+	}
+
+	q := r.URL.Query()
+	if len(apphttp.ParseSources(q)) > 0 || len(apphttp.ParseAccounts(q)) > 0 {
+		names := append(data.Accounts(), apphttp.ParseAccounts(q)...)
+		list = slices.DeleteFunc(list, func(b models.AccountBalance) bool {
+			return !slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, b.Account) })
+		})
+	}
+
+	nw := networth.At(list, end.Format("2006-01-02"))
+	if len(nw.Accounts) == 0 {
+		return nil
+	}
+	return &nw
+}
+
+// accountNames lists the accounts in the files the request covers, for the
+// account filter
+func accountNames(r *http.Request) []string {
+	data, err := loader.LoadSources(apphttp.ParseSources(r.URL.Query()))
+	if err != nil {
+		return nil
+	}
+	return data.Accounts()
 }
 
 // RegisterRoutes registers all dashboard routes
 func RegisterRoutes(r chi.Router) {
 	r.Get("/dashboard", handleDashboard)
 	r.Get("/dashboard/kpis", handleKPIsPartial)
+	r.Get("/dashboard/export", handleExport)
 	r.Get("/dashboard/charts/data/{chartType}", handleChartData)
+	r.Get("/dashboard/charts/savings-strip", handleSavingsStrip)
+	r.Get("/dashboard/charts/savings-waterfall", handleSavingsWaterfall)
 	r.Get("/dashboard/alerts", handleAlertsPartial)
+	r.Get("/dashboard/weekly", handleWeeklyPartial)
+	r.Get("/dashboard/charts/weekly-budget", handleWeeklyBudgetChart)
+	r.Get("/dashboard/bills", handleBillsPartial)
+	r.Get("/dashboard/changes", handleChangesPartial)
+	r.Post("/dashboard/changes/dismiss", handleChangesDismiss)
+	r.Get("/dashboard/watchlist", handleWatchlistPartial)
+	r.Post("/dashboard/watchlist", handleWatchlistAdd)
+	r.Delete("/dashboard/watchlist/{id}", handleWatchlistRemove)
 	r.Get("/dashboard/category/{category}", handleCategoryDrilldown)
+	r.Get("/dashboard/category/{category}/export", handleCategoryExport)
 	r.Get("/dashboard/kpi/{kpiType}", handleKPIDetail)
 	r.Get("/dashboard/kpi/{kpiType}/export", handleKPIExport)
+	r.Get("/api/income/categories", handleIncomeCategories)
+}
+
+// RegisterAPIRoutes registers the dashboard's JSON API routes
+func RegisterAPIRoutes(r chi.Router) {
+	r.Get("/api/v1/metrics", handleMetricsAPI)
+	r.Get("/api/v1/categories", handleCategoriesAPI)
 }
 
 func handleDashboard(w http.ResponseWriter, r *http.Request) {
-	data, err := loader.LoadData()
+	data, err := loadData(r)
 	if err != nil {
 		log.Printf("Error loading data: %v", err)
 		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
@@ -56,32 +264,21 @@ func handleDashboard(w http.ResponseWriter, r *http.Request) {
 	minDate := data.MinDate()
 	maxDate := data.MaxDate()
 
-	var startDate, endDate time.Time
+	// Default to YTD
+	startDate, endDate := defaultRange(data, time.Now())
 	if startStr != "" {
 		startDate, _ = time.Parse("2006-01-02", startStr)
-	} else {
-		// Default to YTD
-		startDate = time.Date(time.Now().Year(), 1, 1, 0, 0, 0, 0, time.Local)
-		// If YTD range starts after our data ends, default to all-time
-		if !maxDate.IsZero() && startDate.After(maxDate) {
-			startDate = minDate
-		} else if startDate.Before(minDate) {
-			startDate = minDate
-		}
 	}
 	if endStr != "" {
 		endDate, _ = time.Parse("2006-01-02", endStr)
-	} else {
-		endDate = maxDate
 	}
 
-	filtered := data.FilterByDateRange(startDate, endDate)
-	metrics := calculateMetrics(filtered)
+	metrics := calculateMetrics(r, startDate, endDate, data)
 
 	// Calculate period comparison if requested
 	var periodComparison *models.PeriodComparison
 	if comparison != "" {
-		periodComparison = calculateComparison(data, startDate, endDate, comparison)
+		periodComparison = analytics.CalculateNormalizedComparison(data, startDate, endDate, comparison, r.URL.Query().Get("normalize"))
 	}
 
 	pageData := map[string]interface{}{
@@ -89,11 +286,18 @@ func handleDashboard(w http.ResponseWriter, r *http.Request) {
 		"ActiveTab":        "dashboard",
 		"Metrics":          metrics,
 		"PeriodComparison": periodComparison,
+		"NetWorth":         netWorth(r, data, endDate),
 		"StartDate":        startDate.Format("2006-01-02"),
 		"EndDate":          endDate.Format("2006-01-02"),
 		"MinDate":          minDate.Format("2006-01-02"),
 		"MaxDate":          maxDate.Format("2006-01-02"),
 		"Comparison":       comparison,
+		"Normalize":        r.URL.Query().Get("normalize"),
+		"Sources":          apphttp.ParseSources(r.URL.Query()),
+		"Account":          r.URL.Query().Get("account"),
+		"Accounts":         accountNames(r),
+		"TrendWindows":     analytics.TrendWindows,
+		"Weekly":           budgetCycle.Weekly(),
 	}
 
 	if renderer != nil {
@@ -105,7 +309,7 @@ func handleDashboard(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleKPIsPartial(w http.ResponseWriter, r *http.Request) {
-	data, err := loader.LoadData()
+	data, err := loadData(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -125,17 +329,17 @@ func handleKPIsPartial(w http.ResponseWriter, r *http.Request) {
 		endDate = data.MaxDate()
 	}
 
-	filtered := data.FilterByDateRange(startDate, endDate)
-	metrics := calculateMetrics(filtered)
+	metrics := calculateMetrics(r, startDate, endDate, data)
 
 	var periodComparison *models.PeriodComparison
 	if comparison != "" {
-		periodComparison = calculateComparison(data, startDate, endDate, comparison)
+		periodComparison = analytics.CalculateNormalizedComparison(data, startDate, endDate, comparison, r.URL.Query().Get("normalize"))
 	}
 
 	partialData := map[string]interface{}{
 		"Metrics":          metrics,
 		"PeriodComparison": periodComparison,
+		"NetWorth":         netWorth(r, data, endDate),
 	}
 
 	if renderer != nil {
@@ -146,9 +350,15 @@ func handleKPIsPartial(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleChartData(w http.ResponseWriter, r *http.Request) {
+	// Checked before it's part of a cache key, so made-up chart types
+	// don't fill the cache
 	chartType := chi.URLParam(r, "chartType")
+	if !slices.Contains(analytics.ChartTypes, chartType) {
+		http.Error(w, "Unknown chart type", http.StatusBadRequest)
+		return
+	}
 
-	data, err := loader.LoadData()
+	data, err := loadData(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -167,25 +377,37 @@ func handleChartData(w http.ResponseWriter, r *http.Request) {
 		endDate = data.MaxDate()
 	}
 
-	filtered := data.FilterByDateRange(startDate, endDate)
-
-	var chartData interface{}
-
-	switch chartType {
-	case "monthly":
-		chartData = buildMonthlyChartData(filtered)
-	case "category":
-		chartData = buildCategoryChartData(filtered)
-	case "cashflow":
-		chartData = buildCashflowChartData(filtered)
-	case "merchants":
-		chartData = buildMerchantsChartData(filtered)
-	case "weekly":
-		chartData = buildWeeklyPatternChartData(filtered)
-	case "cumulative":
-		chartData = buildCumulativeChartData(filtered)
-	default:
-		http.Error(w, "Unknown chart type", http.StatusBadRequest)
+	categoryFilter := apphttp.ParseCategoryFilter(r.URL.Query())
+	filterData := func() *models.TransactionSet {
+		filtered := apphttp.LookupTransactions(loader, r.URL.Query(), data, startDate, endDate, categoryFilter.Include)
+		if len(categoryFilter.Exclude) > 0 || categoryFilter.Uncategorized {
+			filtered = filtered.FilterByCategories(models.CategoryFilter{Exclude: categoryFilter.Exclude, Uncategorized: categoryFilter.Uncategorized})
+		}
+		return filtered
+	}
+
+	var chartData map[string]interface{}
+	if version, err := loader.DataVersion(); err == nil {
+		// The dashboard requests every chart for the same range at once:
+		// filter once per range and build each chart once
+		scope := cache.Key(startDate, endDate,
+			strings.Join(categoryFilter.Include, ","), strings.Join(categoryFilter.Exclude, ","), categoryFilter.Uncategorized,
+			strings.Join(apphttp.ParseSources(r.URL.Query()), ","), strings.Join(apphttp.ParseAccounts(r.URL.Query()), ","))
+		chartData, _ = chartCache.GetOrCompute(version, cache.Key(chartType, scope), func() interface{} {
+			filtered, ok := chartCache.GetOrCompute(version, cache.Key("filtered", scope), func() interface{} {
+				return filterData()
+			}).(*models.TransactionSet)
+			if !ok {
+				filtered = filterData()
+			}
+			chartData, _ := analytics.Chart(chartType, filtered)
+			return chartData
+		}).(map[string]interface{})
+	} else {
+		chartData, _ = analytics.Chart(chartType, filterData())
+	}
+	if chartData == nil {
+		http.Error(w, "Failed to build the "+chartType+" chart", http.StatusInternalServerError)
 		return
 	}
 
@@ -193,8 +415,82 @@ func handleChartData(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(chartData)
 }
 
+// handleSavingsStrip returns the savings rate strip chart over all data,
+// whatever the date range, so the whole history can be compared
+func handleSavingsStrip(w http.ResponseWriter, r *http.Request) {
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analytics.SavingsStripChart(data))
+}
+
+// savingsDecomposition explains the change in savings rate from the period
+// the request's comparison selects to [start, end], or returns nil when
+// there's no comparison or either period lacks income
+func savingsDecomposition(r *http.Request, data *models.TransactionSet, start, end time.Time) *models.SavingsRateDecomposition {
+	compStart, compEnd, ok := analytics.ComparisonPeriod(start, end, r.URL.Query().Get("comparison"))
+	if !ok {
+		return nil
+	}
+	return analytics.DecomposeSavingsRate(data.FilterByDateRange(start, end), data.FilterByDateRange(compStart, compEnd))
+}
+
+// handleSavingsWaterfall returns a waterfall chart of what changed the
+// savings rate between the start and end dates and the comparison period
+func handleSavingsWaterfall(w http.ResponseWriter, r *http.Request) {
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	startDate, _ := time.Parse("2006-01-02", r.URL.Query().Get("start"))
+	endDate, _ := time.Parse("2006-01-02", r.URL.Query().Get("end"))
+	if startDate.IsZero() {
+		startDate = data.MinDate()
+	}
+	if endDate.IsZero() {
+		endDate = data.MaxDate()
+	}
+
+	decomposition := savingsDecomposition(r, data, startDate, endDate)
+	if decomposition == nil {
+		http.Error(w, "Both periods need income to compare savings rates", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analytics.SavingsRateWaterfallChart(decomposition))
+}
+
+// handleIncomeCategories returns income by category over the start and end
+// dates (all data by default) as JSON
+func handleIncomeCategories(w http.ResponseWriter, r *http.Request) {
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	startDate, _ := time.Parse("2006-01-02", r.URL.Query().Get("start"))
+	endDate, _ := time.Parse("2006-01-02", r.URL.Query().Get("end"))
+	if startDate.IsZero() {
+		startDate = data.MinDate()
+	}
+	if endDate.IsZero() {
+		endDate = data.MaxDate()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analytics.IncomeByCategory(data, startDate, endDate))
+}
+
 func handleAlertsPartial(w http.ResponseWriter, r *http.Request) {
-	data, err := loader.LoadData()
+	data, err := loadData(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -214,7 +510,7 @@ func handleAlertsPartial(w http.ResponseWriter, r *http.Request) {
 	}
 
 	filtered := data.FilterByDateRange(startDate, endDate)
-	alerts := detectAlerts(filtered)
+	alerts := dashboardAlerts(data, filtered)
 
 	partialData := map[string]interface{}{
 		"Alerts": alerts,
@@ -228,8 +524,83 @@ func handleAlertsPartial(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func handleCategoryDrilldown(w http.ResponseWriter, r *http.Request) {
-	category := chi.URLParam(r, "category")
+// handleWeeklyPartial renders the This Week card: the latest week's spending
+// against its target and the weeks before it. Weeks always run up to the
+// latest transaction, whatever the dashboard's date range.
+func handleWeeklyPartial(w http.ResponseWriter, r *http.Request) {
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	partialData := map[string]interface{}{
+		"Status": analytics.CalculateWeeklyStatus(data, budgetCycle, analytics.DefaultWeeks),
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "weekly-budget", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
+
+// handleWeeklyBudgetChart returns the week-over-week spending chart
+func handleWeeklyBudgetChart(w http.ResponseWriter, r *http.Request) {
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status := analytics.CalculateWeeklyStatus(data, budgetCycle, analytics.DefaultWeeks)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analytics.WeeklyBudgetChart(status))
+}
+
+// handleBillsPartial renders the Upcoming Bills calendar: recurring
+// payments due over the month after the latest transaction, and any missed
+func handleBillsPartial(w http.ResponseWriter, r *http.Request) {
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The full dashboard shares the insights page's cached recurring
+	// payments, which the scheduled refresh keeps warm; narrowed views
+	// detect over just their files and accounts
+	var recurring []models.RecurringPayment
+	if len(apphttp.ParseSources(r.URL.Query())) == 0 && len(apphttp.ParseAccounts(r.URL.Query())) == 0 {
+		recurring, err = insights.RecurringPayments()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		recurring = insights.DetectRecurringPayments(data)
+	}
+
+	partialData := map[string]interface{}{
+		"Forecast": insights.UpcomingBills(data, recurring),
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "bill-calendar", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
+
+// handleChangesPartial records this visit and renders what changed since the
+// previous one. Narrowed views (sources) are skipped so they don't become
+// the baseline for the full dashboard.
+func handleChangesPartial(w http.ResponseWriter, r *http.Request) {
+	if len(apphttp.ParseSources(r.URL.Query())) > 0 || len(apphttp.ParseAccounts(r.URL.Query())) > 0 {
+		return
+	}
 
 	data, err := loader.LoadData()
 	if err != nil {
@@ -237,12 +608,53 @@ func handleCategoryDrilldown(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	startStr := r.URL.Query().Get("start")
-	endStr := r.URL.Query().Get("end")
+	startDate, endDate := apphttp.ParseDateRange("", "", data.MinDate(), data.MaxDate())
+	filtered := data.FilterByDateRange(startDate, endDate)
+	alerts := dashboardAlerts(data, filtered)
 
-	startDate, _ := time.Parse("2006-01-02", startStr)
-	endDate, _ := time.Parse("2006-01-02", endStr)
+	current := visits.TakeSnapshot(data, filtered, alerts, time.Now())
+	previous, err := tracker.Record(current)
+	if err != nil {
+		log.Printf("Error recording dashboard visit: %v", err)
+		return
+	}
+
+	var changes *models.VisitChanges
+	if previous != nil {
+		changes = visits.Diff(*previous, current, data, alerts)
+	}
+
+	partialData := map[string]interface{}{
+		"Changes": changes,
+	}
 
+	if renderer != nil {
+		renderer.RenderPartial(w, "since-last-visit", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
+
+// handleChangesDismiss marks the current data as seen
+func handleChangesDismiss(w http.ResponseWriter, r *http.Request) {
+	if err := tracker.Dismiss(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// categoryTransactions returns the request's category's outflows in its
+// date range, newest first, with the range
+func categoryTransactions(r *http.Request) (*models.TransactionSet, time.Time, time.Time, error) {
+	data, err := loadData(r)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+
+	startDate, _ := time.Parse("2006-01-02", r.URL.Query().Get("start"))
+	endDate, _ := time.Parse("2006-01-02", r.URL.Query().Get("end"))
 	if startDate.IsZero() {
 		startDate = data.MinDate()
 	}
@@ -250,9 +662,18 @@ func handleCategoryDrilldown(w http.ResponseWriter, r *http.Request) {
 		endDate = data.MaxDate()
 	}
 
-	filtered := data.FilterByDateRange(startDate, endDate)
-	outflows := filtered.FilterByType(models.Outflow)
-	categoryTxns := outflows.FilterByCategory(category).SortByDateDesc()
+	outflows := data.FilterByDateRange(startDate, endDate).FilterByType(models.Outflow)
+	return outflows.FilterByCategory(chi.URLParam(r, "category")).SortByDateDesc(), startDate, endDate, nil
+}
+
+func handleCategoryDrilldown(w http.ResponseWriter, r *http.Request) {
+	category := chi.URLParam(r, "category")
+
+	categoryTxns, _, _, err := categoryTransactions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	// Calculate category stats
 	total := categoryTxns.SumAbsAmount()
@@ -270,6 +691,25 @@ func handleCategoryDrilldown(w http.ResponseWriter, r *http.Request) {
 		"AvgAmount":    avgAmount,
 	}
 
+	// The budget shortcut suggests the category's recent monthly spending
+	// across all accounts, as budgets are tracked
+	if budgeted != nil {
+		if all, err := loader.LoadData(); err == nil {
+			average := budgets.TrailingAverage(all, category, budgets.TrailingMonths)
+			partialData["TrailingAverage"] = average
+			partialData["SuggestedBudget"] = math.Ceil(average)
+			partialData["TrailingMonths"] = budgets.TrailingMonths
+		}
+		if list, err := budgeted.List(); err == nil {
+			for _, b := range list {
+				if strings.EqualFold(b.Category, category) {
+					partialData["Budget"] = b
+					partialData["BudgetThreshold"] = math.Round(b.AlertThreshold * 100)
+				}
+			}
+		}
+	}
+
 	if renderer != nil {
 		renderer.RenderPartial(w, "category-drilldown", partialData)
 	} else {
@@ -278,10 +718,45 @@ func handleCategoryDrilldown(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleCategoryExport downloads the drilldown's transactions as CSV
+func handleCategoryExport(w http.ResponseWriter, r *http.Request) {
+	categoryTxns, startDate, endDate, err := categoryTransactions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"Date", "Description", "Category", "Amount", "Account", "Source File"})
+	for _, t := range categoryTxns.Transactions {
+		writer.Write([]string{
+			t.Date.Format("2006-01-02"),
+			t.Description,
+			t.Category,
+			fmt.Sprintf("%.2f", math.Abs(t.Amount)),
+			t.Account,
+			t.SourceFile,
+		})
+	}
+	writer.Flush()
+
+	slug := strings.Trim(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, chi.URLParam(r, "category")), "-")
+	filename := fmt.Sprintf("%s_%s_to_%s.csv", slug, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Write(buf.Bytes())
+}
+
 func handleKPIDetail(w http.ResponseWriter, r *http.Request) {
 	kpiType := chi.URLParam(r, "kpiType")
 
-	data, err := loader.LoadData()
+	data, err := loadData(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -427,6 +902,11 @@ func handleKPIDetail(w http.ResponseWriter, r *http.Request) {
 		"IsRate":    kpiType == "savings-rate",
 		"IsSavings": kpiType == "savings",
 	}
+	if kpiType == "savings-rate" && r.URL.Query().Get("comparison") != "" {
+		partialData["Comparison"] = r.URL.Query().Get("comparison")
+		partialData["Decomposition"] = savingsDecomposition(r, data, startDate, endDate)
+		partialData["Query"] = r.URL.RawQuery
+	}
 
 	if renderer != nil {
 		renderer.RenderPartial(w, "kpi-detail", partialData)
@@ -439,7 +919,7 @@ func handleKPIDetail(w http.ResponseWriter, r *http.Request) {
 func handleKPIExport(w http.ResponseWriter, r *http.Request) {
 	kpiType := chi.URLParam(r, "kpiType")
 
-	data, err := loader.LoadData()
+	data, err := loadData(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -531,524 +1011,3 @@ func handleKPIExport(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
 	w.Write(buf.Bytes())
 }
-
-// Utility Functions
-
-func detectAlerts(ts *models.TransactionSet) []models.SpendingAlert {
-	var alerts []models.SpendingAlert
-
-	outflows := ts.FilterByType(models.Outflow)
-	if outflows.Len() == 0 {
-		return alerts
-	}
-
-	// Group by date to find unusual days
-	daily := outflows.GroupByDate()
-
-	// Calculate mean and std dev of daily spending
-	var dailyTotals []float64
-	var sum, sumSq float64
-
-	for _, dayTxns := range daily {
-		total := dayTxns.SumAbsAmount()
-		dailyTotals = append(dailyTotals, total)
-		sum += total
-		sumSq += total * total
-	}
-
-	n := float64(len(dailyTotals))
-	if n < 7 { // Need at least a week of data
-		return alerts
-	}
-
-	mean := sum / n
-	variance := (sumSq / n) - (mean * mean)
-	stdDev := math.Sqrt(variance)
-	threshold := mean + 2*stdDev
-
-	// Find unusual days (more than 2 standard deviations above mean)
-	for dateStr, dayTxns := range daily {
-		total := dayTxns.SumAbsAmount()
-		if total > threshold && total > mean*1.5 { // Must be 50% above mean too
-			date, _ := time.Parse("2006-01-02", dateStr)
-			// Sort transactions by amount (largest first) for display
-			txnsCopy := make([]models.Transaction, len(dayTxns.Transactions))
-			copy(txnsCopy, dayTxns.Transactions)
-			sort.Slice(txnsCopy, func(i, j int) bool {
-				return math.Abs(txnsCopy[i].Amount) > math.Abs(txnsCopy[j].Amount)
-			})
-			alerts = append(alerts, models.SpendingAlert{
-				Type:         "unusual_day",
-				Severity:     "warning",
-				Title:        "High Spending Day",
-				Message:      fmt.Sprintf("$%.0f spent on %s (%.0f%% above average)", total, date.Format("Jan 2"), ((total-mean)/mean)*100),
-				Date:         &date,
-				Amount:       total,
-				Transactions: txnsCopy,
-			})
-		}
-	}
-
-	// Find large individual transactions (top 5% by amount)
-	sortedTxns := make([]models.Transaction, len(outflows.Transactions))
-	copy(sortedTxns, outflows.Transactions)
-	sort.Slice(sortedTxns, func(i, j int) bool {
-		return math.Abs(sortedTxns[i].Amount) > math.Abs(sortedTxns[j].Amount)
-	})
-
-	// Take top 3 largest transactions if they're significant
-	for i := 0; i < 3 && i < len(sortedTxns); i++ {
-		t := sortedTxns[i]
-		amt := math.Abs(t.Amount)
-		if amt > mean*3 { // Must be 3x average daily spending
-			date := t.Date
-			alerts = append(alerts, models.SpendingAlert{
-				Type:     "large_transaction",
-				Severity: "info",
-				Title:    "Large Transaction",
-				Message:  fmt.Sprintf("$%.0f at %s", amt, t.Description),
-				Detail:   t.Description,
-				Date:     &date,
-				Amount:   amt,
-			})
-		}
-	}
-
-	// Sort alerts by date (most recent first)
-	sort.Slice(alerts, func(i, j int) bool {
-		if alerts[i].Date == nil || alerts[j].Date == nil {
-			return false
-		}
-		return alerts[i].Date.After(*alerts[j].Date)
-	})
-
-	// Limit to 5 alerts
-	if len(alerts) > 5 {
-		alerts = alerts[:5]
-	}
-
-	return alerts
-}
-
-func calculateMetrics(ts *models.TransactionSet) *models.DashboardMetrics {
-	income := ts.FilterByType(models.Income)
-	outflows := ts.FilterByType(models.Outflow)
-
-	totalIncome := income.SumAmount()
-	totalExpenses := outflows.SumAbsAmount()
-	netSavings := totalIncome - totalExpenses
-
-	var savingsRate float64
-	if totalIncome > 0 {
-		savingsRate = (netSavings / totalIncome) * 100
-	}
-
-	// Calculate monthly trends
-	var incomeTrend, expensesTrend, savingsTrend []float64
-	var trendLabels []string
-
-	monthlyIncome := income.GroupByMonth()
-	monthlyOutflows := outflows.GroupByMonth()
-
-	// Get sorted months
-	monthSet := make(map[string]bool)
-	for m := range monthlyIncome {
-		monthSet[m] = true
-	}
-	for m := range monthlyOutflows {
-		monthSet[m] = true
-	}
-
-	var months []string
-	for m := range monthSet {
-		months = append(months, m)
-	}
-	sort.Strings(months)
-
-	// Take last 6 months
-	if len(months) > 6 {
-		months = months[len(months)-6:]
-	}
-
-	for _, m := range months {
-		incAmt := 0.0
-		if inc, ok := monthlyIncome[m]; ok {
-			incAmt = inc.SumAmount()
-		}
-
-		expAmt := 0.0
-		if exp, ok := monthlyOutflows[m]; ok {
-			expAmt = exp.SumAbsAmount()
-		}
-
-		incomeTrend = append(incomeTrend, incAmt)
-		expensesTrend = append(expensesTrend, expAmt)
-		savingsTrend = append(savingsTrend, incAmt-expAmt)
-		trendLabels = append(trendLabels, m)
-	}
-
-	return &models.DashboardMetrics{
-		TotalIncome:      totalIncome,
-		TotalExpenses:    totalExpenses,
-		NetSavings:       netSavings,
-		SavingsRate:      savingsRate,
-		TransactionCount: ts.Len(),
-		StartDate:        ts.MinDate(),
-		EndDate:          ts.MaxDate(),
-		IncomeTrend:      incomeTrend,
-		ExpensesTrend:    expensesTrend,
-		SavingsTrend:     savingsTrend,
-		TrendLabels:      trendLabels,
-	}
-}
-
-func calculateComparison(data *models.TransactionSet, start, end time.Time, compType string) *models.PeriodComparison {
-	duration := end.Sub(start)
-
-	var compStart, compEnd time.Time
-
-	switch compType {
-	case "previous":
-		compEnd = start.Add(-24 * time.Hour) // Day before start
-		compStart = compEnd.Add(-duration)
-	case "year":
-		compStart = start.AddDate(-1, 0, 0)
-		compEnd = end.AddDate(-1, 0, 0)
-	default:
-		return nil
-	}
-
-	currentFiltered := data.FilterByDateRange(start, end)
-	compFiltered := data.FilterByDateRange(compStart, compEnd)
-
-	if compFiltered.Len() == 0 {
-		return &models.PeriodComparison{HasData: false}
-	}
-
-	currentMetrics := calculateMetrics(currentFiltered)
-	compMetrics := calculateMetrics(compFiltered)
-
-	incomeChange := percentChange(currentMetrics.TotalIncome, compMetrics.TotalIncome)
-	expensesChange := percentChange(currentMetrics.TotalExpenses, compMetrics.TotalExpenses)
-	savingsChange := percentChange(currentMetrics.NetSavings, compMetrics.NetSavings)
-	savingsRateChange := currentMetrics.SavingsRate - compMetrics.SavingsRate
-
-	return &models.PeriodComparison{
-		Current:           currentMetrics,
-		Previous:          compMetrics,
-		HasData:           true,
-		IncomeChange:      incomeChange,
-		ExpensesChange:    expensesChange,
-		SavingsChange:     savingsChange,
-		SavingsRateChange: savingsRateChange,
-	}
-}
-
-func percentChange(current, previous float64) float64 {
-	if previous == 0 {
-		if current == 0 {
-			return 0
-		}
-		return 100
-	}
-	return ((current - previous) / math.Abs(previous)) * 100
-}
-
-func buildMonthlyChartData(ts *models.TransactionSet) map[string]interface{} {
-	income := ts.FilterByType(models.Income)
-	outflows := ts.FilterByType(models.Outflow)
-
-	monthlyIncome := income.MonthlyTotals()
-	monthlyOutflows := outflows.MonthlyTotals()
-
-	// Combine and sort months
-	monthSet := make(map[string]bool)
-	for m := range monthlyIncome {
-		monthSet[m] = true
-	}
-	for m := range monthlyOutflows {
-		monthSet[m] = true
-	}
-
-	var months []string
-	for m := range monthSet {
-		months = append(months, m)
-	}
-	sort.Strings(months)
-
-	var incomeValues, expenseValues []float64
-	for _, m := range months {
-		incomeValues = append(incomeValues, monthlyIncome[m])
-		expenseValues = append(expenseValues, math.Abs(monthlyOutflows[m]))
-	}
-
-	return map[string]interface{}{
-		"data": []map[string]interface{}{
-			{
-				"type": "bar",
-				"name": "Income",
-				"x":    months,
-				"y":    incomeValues,
-				"marker": map[string]string{
-					"color": "#22c55e",
-				},
-			},
-			{
-				"type": "bar",
-				"name": "Expenses",
-				"x":    months,
-				"y":    expenseValues,
-				"marker": map[string]string{
-					"color": "#ef4444",
-				},
-			},
-		},
-		"layout": map[string]interface{}{
-			"barmode": "group",
-		},
-	}
-}
-
-func buildCategoryChartData(ts *models.TransactionSet) map[string]interface{} {
-	outflows := ts.FilterByType(models.Outflow)
-	categoryTotals := outflows.CategoryTotals()
-
-	// Sort by value
-	type catVal struct {
-		cat string
-		val float64
-	}
-	var sorted []catVal
-	for cat, val := range categoryTotals {
-		sorted = append(sorted, catVal{cat, val})
-	}
-	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].val > sorted[j].val
-	})
-
-	// Take top 10
-	if len(sorted) > 10 {
-		other := 0.0
-		for _, cv := range sorted[10:] {
-			other += cv.val
-		}
-		sorted = sorted[:10]
-		if other > 0 {
-			sorted = append(sorted, catVal{"Other", other})
-		}
-	}
-
-	var labels []string
-	var values []float64
-	for _, cv := range sorted {
-		labels = append(labels, cv.cat)
-		values = append(values, cv.val)
-	}
-
-	return map[string]interface{}{
-		"data": []map[string]interface{}{
-			{
-				"type":   "pie",
-				"labels": labels,
-				"values": values,
-				"hole":   0.4,
-			},
-		},
-	}
-}
-
-func buildCashflowChartData(ts *models.TransactionSet) map[string]interface{} {
-	sorted := ts.SortByDate()
-	daily := sorted.GroupByDate()
-
-	// Sort dates
-	var dates []string
-	for d := range daily {
-		dates = append(dates, d)
-	}
-	sort.Strings(dates)
-
-	var dateLabels []string
-	var amounts []float64
-
-	for _, d := range dates {
-		dayTotal := daily[d].SumAmount()
-		dateLabels = append(dateLabels, d)
-		amounts = append(amounts, dayTotal)
-	}
-
-	return map[string]interface{}{
-		"data": []map[string]interface{}{
-			{
-				"type": "scatter",
-				"mode": "lines",
-				"name": "Cash Flow",
-				"x":    dateLabels,
-				"y":    amounts,
-				"line": map[string]interface{}{
-					"color": "#6366f1",
-					"width": 2,
-				},
-				"fill":      "tozeroy",
-				"fillcolor": "rgba(99, 102, 241, 0.1)",
-			},
-		},
-	}
-}
-
-func buildMerchantsChartData(ts *models.TransactionSet) map[string]interface{} {
-	outflows := ts.FilterByType(models.Outflow)
-
-	// Group by description (merchant)
-	merchantTotals := make(map[string]float64)
-	for _, t := range outflows.Transactions {
-		merchantTotals[t.Description] += math.Abs(t.Amount)
-	}
-
-	// Sort by value
-	type merchVal struct {
-		name string
-		val  float64
-	}
-	var sorted []merchVal
-	for name, val := range merchantTotals {
-		sorted = append(sorted, merchVal{name, val})
-	}
-	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].val > sorted[j].val
-	})
-
-	// Take top 10
-	if len(sorted) > 10 {
-		sorted = sorted[:10]
-	}
-
-	// Reverse for horizontal bar chart
-	var labels []string
-	var values []float64
-	for i := len(sorted) - 1; i >= 0; i-- {
-		labels = append(labels, sorted[i].name)
-		values = append(values, sorted[i].val)
-	}
-
-	return map[string]interface{}{
-		"data": []map[string]interface{}{
-			{
-				"type":        "bar",
-				"orientation": "h",
-				"x":           values,
-				"y":           labels,
-				"marker": map[string]string{
-					"color": "#8b5cf6",
-				},
-			},
-		},
-	}
-}
-
-func buildWeeklyPatternChartData(ts *models.TransactionSet) map[string]interface{} {
-	outflows := ts.FilterByType(models.Outflow)
-
-	// Group by day of week
-	dayTotals := make(map[int]float64)
-	dayCounts := make(map[int]int)
-	dayNames := []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
-
-	for _, t := range outflows.Transactions {
-		dow := int(t.Date.Weekday())
-		dayTotals[dow] += math.Abs(t.Amount)
-		dayCounts[dow]++
-	}
-
-	// Calculate averages per day
-	var values []float64
-	for i := 0; i < 7; i++ {
-		if dayCounts[i] > 0 {
-			// Get number of weeks in the data
-			minDate := ts.MinDate()
-			maxDate := ts.MaxDate()
-			weeks := maxDate.Sub(minDate).Hours() / 24 / 7
-			if weeks < 1 {
-				weeks = 1
-			}
-			values = append(values, dayTotals[i]/weeks)
-		} else {
-			values = append(values, 0)
-		}
-	}
-
-	return map[string]interface{}{
-		"data": []map[string]interface{}{
-			{
-				"type": "bar",
-				"x":    dayNames,
-				"y":    values,
-				"marker": map[string]interface{}{
-					"color": []string{
-						"#94a3b8", "#3b82f6", "#3b82f6", "#3b82f6",
-						"#3b82f6", "#3b82f6", "#94a3b8",
-					},
-				},
-			},
-		},
-		"layout": map[string]interface{}{
-			"yaxis": map[string]interface{}{
-				"title": "Avg Spending ($)",
-			},
-		},
-	}
-}
-
-func buildCumulativeChartData(ts *models.TransactionSet) map[string]interface{} {
-	sorted := ts.SortByDate()
-	daily := sorted.GroupByDate()
-
-	// Sort dates
-	var dates []string
-	for d := range daily {
-		dates = append(dates, d)
-	}
-	sort.Strings(dates)
-
-	var dateLabels []string
-	var cumulative []float64
-	var runningTotal float64
-
-	for _, d := range dates {
-		dayTotal := daily[d].SumAmount()
-		runningTotal += dayTotal
-		dateLabels = append(dateLabels, d)
-		cumulative = append(cumulative, runningTotal)
-	}
-
-	// Determine line color based on final value
-	lineColor := "#22c55e"
-	fillColor := "rgba(34, 197, 94, 0.1)"
-	if runningTotal < 0 {
-		lineColor = "#ef4444"
-		fillColor = "rgba(239, 68, 68, 0.1)"
-	}
-
-	return map[string]interface{}{
-		"data": []map[string]interface{}{
-			{
-				"type": "scatter",
-				"mode": "lines",
-				"name": "Cumulative Balance",
-				"x":    dateLabels,
-				"y":    cumulative,
-				"line": map[string]interface{}{
-					"color": lineColor,
-					"width": 2,
-				},
-				"fill":      "tozeroy",
-				"fillcolor": fillColor,
-			},
-		},
-		"layout": map[string]interface{}{
-			"yaxis": map[string]interface{}{
-				"title": "Cumulative ($)",
-			},
-		},
-	}
-}
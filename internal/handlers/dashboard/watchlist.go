@@ -0,0 +1,104 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"budget2/internal/models"
+	"budget2/internal/services/analytics"
+	"budget2/internal/services/budgets"
+	"budget2/internal/services/watchlist"
+)
+
+// dashboardAlerts combines category budget and watchlist alerts for the
+// latest month of data with the anomaly alerts for the selected range,
+// ranked by severity and grouped by day
+func dashboardAlerts(data, filtered *models.TransactionSet) []models.SpendingAlert {
+	var alerts []models.SpendingAlert
+	if budgeted != nil {
+		if list, err := budgeted.List(); err != nil {
+			log.Printf("Error loading category budgets: %v", err)
+		} else {
+			alerts = append(alerts, budgets.Alerts(budgets.Evaluate(list, data))...)
+		}
+	}
+	if watched != nil {
+		if list, err := watched.List(); err != nil {
+			log.Printf("Error loading merchant watchlist: %v", err)
+		} else {
+			alerts = append(alerts, watchlist.Alerts(watchlist.Evaluate(list, data))...)
+		}
+	}
+	alerts = append(alerts, analytics.AnomalyAlerts(filtered)...)
+	return analytics.RankAlerts(alerts, data.MaxDate(), maxAlerts)
+}
+
+func handleWatchlistPartial(w http.ResponseWriter, r *http.Request) {
+	list, err := watched.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderWatchlist(w, r, list)
+}
+
+func handleWatchlistAdd(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	limit, err := strconv.ParseFloat(r.FormValue("limit"), 64)
+	if err != nil {
+		http.Error(w, "Monthly limit must be a number", http.StatusBadRequest)
+		return
+	}
+
+	list, err := watched.Add(models.WatchedMerchant{
+		ID:           uuid.New().String(),
+		Name:         r.FormValue("name"),
+		Match:        r.FormValue("match"),
+		MonthlyLimit: limit,
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		http.Error(w, "Failed to watch merchant: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderWatchlist(w, r, list)
+}
+
+func handleWatchlistRemove(w http.ResponseWriter, r *http.Request) {
+	list, err := watched.Remove(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Failed to remove merchant: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderWatchlist(w, r, list)
+}
+
+// renderWatchlist totals month-to-date spending for the watchlist and renders the panel
+func renderWatchlist(w http.ResponseWriter, r *http.Request, list []models.WatchedMerchant) {
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	partialData := map[string]interface{}{
+		"Watchlist": watchlist.Evaluate(list, data),
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "merchant-watchlist", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
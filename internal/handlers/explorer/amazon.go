@@ -0,0 +1,88 @@
+package explorer
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"budget2/internal/services/amazon"
+)
+
+// handleAmazonOrders shows imported Amazon orders and how many charges matched
+func handleAmazonOrders(w http.ResponseWriter, r *http.Request) {
+	renderAmazonOrders(w)
+}
+
+// handleAmazonImport imports an Amazon order-history CSV
+func handleAmazonImport(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		http.Error(w, "File too large", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Error reading file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	parsed, err := amazon.ParseOrders(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := orders.Import(parsed); err != nil {
+		http.Error(w, "Error saving orders: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Imported %d Amazon orders from %s", len(parsed), header.Filename)
+	renderAmazonOrders(w)
+}
+
+// handleAmazonClear removes imported orders, restoring the original charges
+func handleAmazonClear(w http.ResponseWriter, r *http.Request) {
+	if err := orders.Clear(); err != nil {
+		http.Error(w, "Error clearing orders: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderAmazonOrders(w)
+}
+
+// renderAmazonOrders renders the Amazon orders panel
+func renderAmazonOrders(w http.ResponseWriter) {
+	list, err := orders.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err := loader.LoadData()
+	if err != nil {
+		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var matched, unmatched int
+	for i := range data.Transactions {
+		t := &data.Transactions[i]
+		if amazon.IsEnriched(t) {
+			matched++
+		} else if amazon.IsCharge(t) {
+			unmatched++
+		}
+	}
+
+	partialData := map[string]interface{}{
+		"Orders":    list,
+		"Matched":   matched,
+		"Unmatched": unmatched,
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "amazon-orders", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
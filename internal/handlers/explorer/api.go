@@ -0,0 +1,51 @@
+package explorer
+
+import (
+	"encoding/json"
+	"net/http"
+
+	apphttp "budget2/internal/http"
+	"budget2/internal/models"
+)
+
+// handleTransactionsAPI returns a page of the transactions matching the
+// explorer's filter, sort and account parameters as JSON
+func handleTransactionsAPI(w http.ResponseWriter, r *http.Request) {
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	q := r.URL.Query()
+	filtered, startDate, endDate := filterTransactions(q, data)
+	sortField, order := q.Get("sort"), q.Get("order")
+	if sortField == "" {
+		sortField = "date"
+	}
+	if order == "" {
+		order = "desc"
+	}
+	filtered = sortTransactions(filtered, sortField, order)
+
+	page, perPage := apphttp.ParsePage(q)
+	result := models.TransactionPage{
+		Start:         startDate.Format("2006-01-02"),
+		End:           endDate.Format("2006-01-02"),
+		Transactions:  []models.Transaction{},
+		TotalIncome:   filtered.FilterByType(models.Income).SumAmount(),
+		TotalExpenses: filtered.FilterByType(models.Outflow).SumAbsAmount(),
+		Pagination: models.Pagination{
+			Page:       page,
+			PerPage:    perPage,
+			Total:      filtered.Len(),
+			TotalPages: filtered.TotalPages(perPage),
+		},
+	}
+	if paginated := filtered.Paginate(page, perPage); paginated.Len() > 0 {
+		result.Transactions = paginated.Transactions
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,57 @@
+package explorer
+
+import (
+	"log"
+	"net/http"
+
+	"budget2/internal/models"
+)
+
+// fileInfo lists the data files, noting which ones bank sync or the Google
+// Sheets import writes and how their last run went
+func fileInfo() ([]models.FileInfo, error) {
+	files, err := loader.GetFileInfo()
+	if err != nil {
+		return files, err
+	}
+	status := bankSyncStatus()
+	sheetStatus := sheetSyncStatus()
+	for i := range files {
+		files[i].Sync = status.Account(files[i].Name)
+		files[i].Sheet = sheetStatus.File(files[i].Name)
+	}
+	return files, nil
+}
+
+// bankSyncStatus returns how the last bank sync went, or nil when bank
+// sync is off
+func bankSyncStatus() *models.BankSyncStatus {
+	if syncer == nil {
+		return nil
+	}
+	status, err := syncer.Status()
+	if err != nil {
+		log.Printf("Warning: reading bank sync status: %v", err)
+		return nil
+	}
+	return status
+}
+
+// handleBankSync syncs every provider now rather than waiting for the
+// schedule. Provider failures are recorded in the sync status the file
+// manager shows, so only a failure to save that status is an error here.
+func handleBankSync(w http.ResponseWriter, r *http.Request) {
+	if syncer == nil {
+		http.Error(w, "Bank sync is not configured", http.StatusNotFound)
+		return
+	}
+	status, err := syncer.Sync()
+	if status == nil {
+		http.Error(w, "Bank sync failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		log.Printf("Warning: bank sync failed: %v", err)
+	}
+	backToFiles(w, r)
+}
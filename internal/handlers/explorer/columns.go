@@ -0,0 +1,129 @@
+package explorer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/models"
+	"budget2/internal/services/columnmaps"
+)
+
+// previewRows is how many of a file's rows the column mapping page shows
+const previewRows = 8
+
+// mappedFile returns the data file named in the URL, failing with the
+// status to respond with when it's invalid or missing
+func mappedFile(r *http.Request) (string, int, error) {
+	filename, err := url.PathUnescape(chi.URLParam(r, "filename"))
+	if err != nil {
+		return "", http.StatusBadRequest, fmt.Errorf("invalid filename encoding")
+	}
+	if strings.Contains(filename, "/") || strings.Contains(filename, "\\") || strings.Contains(filename, "..") {
+		return "", http.StatusBadRequest, fmt.Errorf("invalid filename")
+	}
+	if !strings.EqualFold(filepath.Ext(filename), ".csv") {
+		return "", http.StatusBadRequest, fmt.Errorf("only CSV files have columns to map")
+	}
+	if _, err := store.Stat(filepath.Join(cfg.DataDirectory, filename)); os.IsNotExist(err) {
+		return "", http.StatusNotFound, fmt.Errorf("file not found")
+	}
+	return filename, http.StatusOK, nil
+}
+
+// handleColumnMapPage previews a CSV file with the field each column is
+// read as, for assigning columns the loader doesn't recognize by hand
+func handleColumnMapPage(w http.ResponseWriter, r *http.Request) {
+	filename, status, err := mappedFile(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	preview, err := loader.PreviewColumns(filename, previewRows)
+	if err != nil {
+		http.Error(w, "Error reading file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if renderer == nil {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(preview)
+		return
+	}
+
+	pattern := columnmaps.SuggestPattern(filename)
+	if preview.Mapping != nil {
+		pattern = preview.Mapping.Pattern
+	}
+	renderer.Render(w, "base", map[string]interface{}{
+		"Title":     "Map columns of " + filename,
+		"ActiveTab": "columnmap",
+		"Preview":   preview,
+		"Pattern":   pattern,
+		"Fields":    models.MappableColumns,
+	})
+}
+
+// handleColumnMapSave saves the columns chosen for a file's fields under a
+// file pattern, so later exports named like it load the same way
+func handleColumnMapSave(w http.ResponseWriter, r *http.Request) {
+	filename, status, err := mappedFile(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mapping := models.ColumnMapping{
+		Pattern: strings.TrimSpace(r.FormValue("pattern")),
+		Columns: make(map[string]string),
+	}
+	for _, field := range models.MappableColumns {
+		mapping.Columns[field] = r.FormValue(field)
+	}
+	if ok, _ := filepath.Match(mapping.Pattern, filename); mapping.Pattern != "" && !ok {
+		http.Error(w, fmt.Sprintf("Pattern %q doesn't match %s", mapping.Pattern, filename), http.StatusBadRequest)
+		return
+	}
+	if err := mapper.Set(mapping); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	backToFiles(w, r)
+}
+
+// handleColumnMapRemove deletes the mapping applied to a file, so its
+// columns are recognized by name again
+func handleColumnMapRemove(w http.ResponseWriter, r *http.Request) {
+	filename, status, err := mappedFile(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if mapping := mapper.Mapping(filename); mapping != nil {
+		if err := mapper.Remove(mapping.Pattern); err != nil {
+			http.Error(w, "Failed to remove mapping: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	backToFiles(w, r)
+}
+
+// backToFiles sends the browser back to the file manager after a change
+func backToFiles(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", "/filemanager")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	http.Redirect(w, r, "/filemanager", http.StatusSeeOther)
+}
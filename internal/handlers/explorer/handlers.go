@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
@@ -16,9 +17,21 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"budget2/internal/config"
+	apphttp "budget2/internal/http"
 	"budget2/internal/models"
+	"budget2/internal/services/accounts"
+	"budget2/internal/services/amazon"
+	"budget2/internal/services/banksync"
+	"budget2/internal/services/categories"
+	"budget2/internal/services/columnmaps"
 	"budget2/internal/services/dataloader"
+	"budget2/internal/services/monthclose"
+	"budget2/internal/services/overrides"
+	"budget2/internal/services/sheets"
+	"budget2/internal/services/signs"
+	"budget2/internal/services/splits"
 	"budget2/internal/services/storage"
+	"budget2/internal/services/tags"
 	"budget2/internal/templates"
 )
 
@@ -27,28 +40,138 @@ var (
 	renderer *templates.Renderer
 	cfg      *config.Config
 	store    *storage.Storage
+	styles   *categories.Registry
+	closer   *monthclose.Manager
+	orders   *amazon.Manager
+	edits    *overrides.Manager
+	signing  *signs.Manager
+	splitter *splits.Manager
+	tagger   *tags.Manager
+	typing   *accounts.Manager
+	mapper   *columnmaps.Manager
+	syncer   *banksync.Service
+	importer *sheets.Service
 )
 
 // Initialize sets up the explorer package with required dependencies
-func Initialize(l *dataloader.DataLoader, r *templates.Renderer, c *config.Config, s *storage.Storage) {
+func Initialize(l *dataloader.DataLoader, r *templates.Renderer, c *config.Config, s *storage.Storage, cs *categories.Registry, mc *monthclose.Manager, am *amazon.Manager, om *overrides.Manager, sm *signs.Manager, sp *splits.Manager, tm *tags.Manager, at *accounts.Manager, cm *columnmaps.Manager, bs *banksync.Service, ss *sheets.Service) {
 	loader = l
 	renderer = r
 	cfg = c
 	store = s
+	styles = cs
+	closer = mc
+	orders = am
+	edits = om
+	signing = sm
+	splitter = sp
+	tagger = tm
+	typing = at
+	mapper = cm
+	syncer = bs
+	importer = ss
+}
+
+// loadData honors the sources and account parameters so the explorer can
+// show a subset of files or accounts without touching the enabled-file
+// selection
+func loadData(r *http.Request) (*models.TransactionSet, error) {
+	data, err := loader.LoadSources(apphttp.ParseSources(r.URL.Query()))
+	if err != nil {
+		return nil, err
+	}
+	if accounts := apphttp.ParseAccounts(r.URL.Query()); len(accounts) > 0 {
+		data = data.FilterByAccounts(accounts)
+	}
+	return data, nil
+}
+
+// accountNames lists the accounts in the files the request covers, for the
+// account filter
+func accountNames(r *http.Request) []string {
+	data, err := loader.LoadSources(apphttp.ParseSources(r.URL.Query()))
+	if err != nil {
+		return nil
+	}
+	return data.Accounts()
+}
+
+// filterTransactions applies the explorer's filter parameters to data:
+// start and end (all of data by default), category, exclude, uncategorized,
+// tag, search, type, minAmount, maxAmount and weekday. It returns the matches and
+// the date range used.
+func filterTransactions(q url.Values, data *models.TransactionSet) (filtered *models.TransactionSet, start, end time.Time) {
+	start, end = data.MinDate(), data.MaxDate()
+	if s := q.Get("start"); s != "" {
+		start, _ = time.Parse("2006-01-02", s)
+	}
+	if e := q.Get("end"); e != "" {
+		end, _ = time.Parse("2006-01-02", e)
+	}
+	categoryFilter := apphttp.ParseCategoryFilter(q)
+	filtered = apphttp.LookupTransactions(loader, q, data, start, end, categoryFilter.Include)
+
+	if len(categoryFilter.Exclude) > 0 || categoryFilter.Uncategorized {
+		filtered = filtered.FilterByCategories(models.CategoryFilter{Exclude: categoryFilter.Exclude, Uncategorized: categoryFilter.Uncategorized})
+	}
+	if tags := q["tag"]; len(tags) > 0 {
+		filtered = filtered.FilterByTags(tags)
+	}
+	if search := q.Get("search"); search != "" {
+		filtered = filtered.FilterBySearch(search)
+	}
+	switch q.Get("type") {
+	case "Income":
+		filtered = filtered.FilterByType(models.Income)
+	case "Outflow":
+		filtered = filtered.FilterByType(models.Outflow)
+	}
+	if minAmount, maxAmount := q.Get("minAmount"), q.Get("maxAmount"); minAmount != "" || maxAmount != "" {
+		filtered = filtered.FilterByAmountRange(parseAmountRange(minAmount, maxAmount))
+	}
+	if days := parseWeekdays(q.Get("weekday")); len(days) > 0 {
+		filtered = filtered.FilterByWeekdays(days)
+	}
+	return filtered, start, end
+}
+
+// RegisterAPIRoutes registers the explorer's JSON API routes
+func RegisterAPIRoutes(r chi.Router) {
+	r.Get("/api/v1/transactions", handleTransactionsAPI)
+	r.Patch("/api/v1/transactions/{id}/category", handleRecategorizeAPI)
 }
 
 // RegisterRoutes registers all explorer routes
 func RegisterRoutes(r chi.Router) {
 	r.Get("/explorer", handleExplorer)
 	r.Get("/explorer/transactions", handleTransactionsPartial)
+	r.Patch("/explorer/transactions/{id}/category", handleRecategorize)
+	r.Get("/explorer/transactions/{id}/split", handleSplitEditor)
+	r.Put("/explorer/transactions/{id}/split", handleSplitSave)
+	r.Delete("/explorer/transactions/{id}/split", handleSplitDelete)
+	r.Patch("/explorer/transactions/{id}/tags", handleRetag)
+	r.Get("/t/{id}", handleTransactionPage)
 	r.Get("/explorer/files", handleFileManager)
 	r.Post("/explorer/files/toggle", handleFileToggle)
+	r.Post("/explorer/files/sign", handleFileSign)
+	r.Post("/explorer/files/account", handleFileAccount)
 	r.Post("/explorer/upload", handleFileUpload)
 	r.Delete("/explorer/files/{filename}", handleFileDelete)
+	r.Post("/explorer/banksync", handleBankSync)
+	r.Post("/explorer/sheets", handleSheetSync)
+	r.Get("/explorer/files/{filename}/map", handleColumnMapPage)
+	r.Post("/explorer/files/{filename}/map", handleColumnMapSave)
+	r.Delete("/explorer/files/{filename}/map", handleColumnMapRemove)
+	r.Get("/explorer/amazon", handleAmazonOrders)
+	r.Post("/explorer/amazon", handleAmazonImport)
+	r.Delete("/explorer/amazon", handleAmazonClear)
+	r.Get("/categories/styles", handleCategoryStyles)
+	r.Post("/categories/styles", handleCategoryStyleSet)
+	r.Post("/categories/styles/reset", handleCategoryStyleReset)
 }
 
 func handleExplorer(w http.ResponseWriter, r *http.Request) {
-	data, err := loader.LoadData()
+	data, err := loadData(r)
 	if err != nil {
 		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -56,10 +179,11 @@ func handleExplorer(w http.ResponseWriter, r *http.Request) {
 
 	// Get filter parameters
 	search := r.URL.Query().Get("search")
-	category := r.URL.Query().Get("category")
+	categoryFilter := apphttp.ParseCategoryFilter(r.URL.Query())
 	txnType := r.URL.Query().Get("type")
-	startStr := r.URL.Query().Get("start")
-	endStr := r.URL.Query().Get("end")
+	minAmountStr := r.URL.Query().Get("minAmount")
+	maxAmountStr := r.URL.Query().Get("maxAmount")
+	weekday := r.URL.Query().Get("weekday")
 	sortField := r.URL.Query().Get("sort")
 	order := r.URL.Query().Get("order")
 	pageStr := r.URL.Query().Get("page")
@@ -81,38 +205,13 @@ func handleExplorer(w http.ResponseWriter, r *http.Request) {
 	if perPage < 1 {
 		perPage = 25
 	}
+	perPage = min(perPage, apphttp.MaxPerPage)
 
 	minDate := data.MinDate()
 	maxDate := data.MaxDate()
 
-	var startDate, endDate time.Time
-	if startStr != "" {
-		startDate, _ = time.Parse("2006-01-02", startStr)
-	} else {
-		startDate = minDate
-	}
-	if endStr != "" {
-		endDate, _ = time.Parse("2006-01-02", endStr)
-	} else {
-		endDate = maxDate
-	}
-
 	// Apply filters
-	filtered := data.FilterByDateRange(startDate, endDate)
-
-	if category != "" {
-		filtered = filtered.FilterByCategory(category)
-	}
-	if search != "" {
-		filtered = filtered.FilterBySearch(search)
-	}
-	if txnType != "" {
-		if txnType == "Income" {
-			filtered = filtered.FilterByType(models.Income)
-		} else if txnType == "Outflow" {
-			filtered = filtered.FilterByType(models.Outflow)
-		}
-	}
+	filtered, startDate, endDate := filterTransactions(r.URL.Query(), data)
 
 	// Calculate totals before pagination
 	totalCount := filtered.Len()
@@ -143,12 +242,23 @@ func handleExplorer(w http.ResponseWriter, r *http.Request) {
 
 	pageData := map[string]interface{}{
 		"Title":         "Data Explorer",
+		"Sources":       apphttp.ParseSources(r.URL.Query()),
+		"Account":       r.URL.Query().Get("account"),
+		"Accounts":      accountNames(r),
 		"ActiveTab":     "explorer",
 		"Transactions":  paginated.Transactions,
 		"Categories":    data.Categories(),
+		"CategoryTree":  data.CategoryTree(),
+		"Tags":          data.Tags(),
+		"Tag":           r.URL.Query()["tag"],
 		"Search":        search,
-		"Category":      category,
+		"Category":      categoryFilter.Include,
+		"Exclude":       categoryFilter.Exclude,
+		"Uncategorized": categoryFilter.Uncategorized,
 		"Type":          txnType,
+		"MinAmount":     minAmountStr,
+		"MaxAmount":     maxAmountStr,
+		"Weekday":       weekday,
 		"StartDate":     startDate.Format("2006-01-02"),
 		"EndDate":       endDate.Format("2006-01-02"),
 		"MinDate":       minDate.Format("2006-01-02"),
@@ -165,6 +275,7 @@ func handleExplorer(w http.ResponseWriter, r *http.Request) {
 		"PageRange":     pageRange,
 		"PageStart":     pageStart,
 		"PageEnd":       pageEnd,
+		"NextCursor":    scrollCursor(filtered, page, perPage, totalPages),
 	}
 
 	if renderer != nil {
@@ -175,8 +286,15 @@ func handleExplorer(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleTransactionsPartial renders the transactions table for the filters,
+// or with append=true only the rows infinite scroll adds. Either way the
+// response carries the summary totals as an out-of-band swap and the last
+// row carries the cursor for the next page. An append whose cursor was
+// issued for a different list of rows, because the filters, sort or data
+// changed since, starts the table over instead of appending rows that
+// repeat or skip some.
 func handleTransactionsPartial(w http.ResponseWriter, r *http.Request) {
-	data, err := loader.LoadData()
+	data, err := loadData(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -184,10 +302,11 @@ func handleTransactionsPartial(w http.ResponseWriter, r *http.Request) {
 
 	// Get filter parameters
 	search := r.URL.Query().Get("search")
-	category := r.URL.Query().Get("category")
+	categoryFilter := apphttp.ParseCategoryFilter(r.URL.Query())
 	txnType := r.URL.Query().Get("type")
-	startStr := r.URL.Query().Get("start")
-	endStr := r.URL.Query().Get("end")
+	minAmountStr := r.URL.Query().Get("minAmount")
+	maxAmountStr := r.URL.Query().Get("maxAmount")
+	weekday := r.URL.Query().Get("weekday")
 	sortField := r.URL.Query().Get("sort")
 	order := r.URL.Query().Get("order")
 	pageStr := r.URL.Query().Get("page")
@@ -209,38 +328,10 @@ func handleTransactionsPartial(w http.ResponseWriter, r *http.Request) {
 	if perPage < 1 {
 		perPage = 25
 	}
-
-	minDate := data.MinDate()
-	maxDate := data.MaxDate()
-
-	var startDate, endDate time.Time
-	if startStr != "" {
-		startDate, _ = time.Parse("2006-01-02", startStr)
-	} else {
-		startDate = minDate
-	}
-	if endStr != "" {
-		endDate, _ = time.Parse("2006-01-02", endStr)
-	} else {
-		endDate = maxDate
-	}
+	perPage = min(perPage, apphttp.MaxPerPage)
 
 	// Apply filters
-	filtered := data.FilterByDateRange(startDate, endDate)
-
-	if category != "" {
-		filtered = filtered.FilterByCategory(category)
-	}
-	if search != "" {
-		filtered = filtered.FilterBySearch(search)
-	}
-	if txnType != "" {
-		if txnType == "Income" {
-			filtered = filtered.FilterByType(models.Income)
-		} else if txnType == "Outflow" {
-			filtered = filtered.FilterByType(models.Outflow)
-		}
-	}
+	filtered, _, _ := filterTransactions(r.URL.Query(), data)
 
 	// Calculate totals before pagination
 	totalCount := filtered.Len()
@@ -251,9 +342,24 @@ func handleTransactionsPartial(w http.ResponseWriter, r *http.Request) {
 	// Apply sorting
 	filtered = sortTransactions(filtered, sortField, order)
 
-	// Apply pagination
+	// Infinite scroll pages by cursor; a stale one reloads the whole table
+	appendRows := r.URL.Query().Get("append") == "true"
+	if cursor := r.URL.Query().Get("cursor"); appendRows && cursor != "" {
+		next, fingerprint, ok := parseScrollCursor(cursor)
+		if ok && fingerprint == rowsFingerprint(filtered, perPage) {
+			page = next
+		} else {
+			appendRows = false
+			page = 1
+			w.Header().Set("HX-Retarget", "#transactions-container")
+			w.Header().Set("HX-Reswap", "innerHTML")
+		}
+	}
+
+	// Apply pagination. Appends past the last page get no rows rather than
+	// the last page again.
 	totalPages := filtered.TotalPages(perPage)
-	if page > totalPages && totalPages > 0 {
+	if page > totalPages && totalPages > 0 && !appendRows {
 		page = totalPages
 	}
 	paginated := filtered.Paginate(page, perPage)
@@ -264,18 +370,21 @@ func handleTransactionsPartial(w http.ResponseWriter, r *http.Request) {
 	// Calculate page start/end for display
 	pageStart := (page - 1) * perPage + 1
 	pageEnd := pageStart + paginated.Len() - 1
-	if totalCount == 0 {
+	if totalCount == 0 || paginated.Len() == 0 {
 		pageStart = 0
 		pageEnd = 0
 	}
 
-	appendRows := r.URL.Query().Get("append") == "true"
-
 	partialData := map[string]interface{}{
 		"Transactions":  paginated.Transactions,
 		"Search":        search,
-		"Category":      category,
+		"Category":      categoryFilter.Include,
+		"Exclude":       categoryFilter.Exclude,
+		"Uncategorized": categoryFilter.Uncategorized,
 		"Type":          txnType,
+		"MinAmount":     minAmountStr,
+		"MaxAmount":     maxAmountStr,
+		"Weekday":       weekday,
 		"Sort":          sortField,
 		"Order":         order,
 		"Page":          page,
@@ -288,6 +397,7 @@ func handleTransactionsPartial(w http.ResponseWriter, r *http.Request) {
 		"PageRange":     pageRange,
 		"PageStart":     pageStart,
 		"PageEnd":       pageEnd,
+		"NextCursor":    scrollCursor(filtered, page, perPage, totalPages),
 	}
 
 	if renderer != nil {
@@ -305,14 +415,16 @@ func handleTransactionsPartial(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleFileManager(w http.ResponseWriter, r *http.Request) {
-	files, err := loader.GetFileInfo()
+	files, err := fileInfo()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	dedupe, _ := loader.DedupeReport()
 	partialData := map[string]interface{}{
-		"Files": files,
+		"Files":  files,
+		"Dedupe": dedupe,
 	}
 
 	if renderer != nil {
@@ -324,21 +436,45 @@ func handleFileManager(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleFileManagerPage(w http.ResponseWriter, r *http.Request) {
-	files, err := loader.GetFileInfo()
+	files, err := fileInfo()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	dedupe, _ := loader.DedupeReport()
 	data := map[string]interface{}{
-		"Title":     "File Manager",
-		"ActiveTab": "filemanager",
-		"Files":     files,
+		"Title":         "File Manager",
+		"ActiveTab":     "filemanager",
+		"Files":         files,
+		"Dedupe":        dedupe,
+		"ChangedClosed": changedClosedMonths(),
+		"BankSync":      bankSyncStatus(),
+		"SyncHours":     cfg.BankSyncHours,
+		"Sheets":        sheetSyncStatus(),
+		"SheetsMinutes": cfg.GoogleSheetsMinutes,
 	}
 
 	renderer.Render(w, "base", data)
 }
 
+// changedClosedMonths returns closed months that imports have since changed,
+// so the file manager can warn right after an upload
+func changedClosedMonths() []models.MonthCloseStatus {
+	if closer == nil {
+		return nil
+	}
+	closes, err := closer.List()
+	if err != nil || len(closes) == 0 {
+		return nil
+	}
+	data, err := loader.LoadData()
+	if err != nil {
+		return nil
+	}
+	return monthclose.Modified(closes, data)
+}
+
 func handleFileToggle(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -371,7 +507,61 @@ func handleFileToggle(w http.ResponseWriter, r *http.Request) {
 	loader.SetEnabledFiles(enabledFiles)
 
 	// Return updated file list
-	files, _ = loader.GetFileInfo()
+	files, _ = fileInfo()
+	partialData := map[string]interface{}{
+		"Files": files,
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "file-list", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
+
+// handleFileSign sets how a file's amounts are signed, overriding the
+// convention the loader detected; a blank convention detects it again
+func handleFileSign(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := signing.Set(r.FormValue("file"), r.FormValue("convention")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Return updated file list
+	files, _ := fileInfo()
+	partialData := map[string]interface{}{
+		"Files": files,
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "file-list", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
+
+// handleFileAccount sets the kind of account a file holds, which decides
+// whether its credits count as income
+func handleFileAccount(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := typing.Set(r.FormValue("file"), r.FormValue("type")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Return updated file list
+	files, _ := fileInfo()
 	partialData := map[string]interface{}{
 		"Files": files,
 	}
@@ -399,8 +589,8 @@ func handleFileUpload(w http.ResponseWriter, r *http.Request) {
 	defer file.Close()
 
 	// Validate file extension
-	if !strings.HasSuffix(strings.ToLower(header.Filename), ".csv") {
-		http.Error(w, "Only CSV files are allowed", http.StatusBadRequest)
+	if !dataloader.IsDataFile(header.Filename) {
+		http.Error(w, "Only CSV and QIF files are allowed", http.StatusBadRequest)
 		return
 	}
 
@@ -419,9 +609,10 @@ func handleFileUpload(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("Uploaded file: %s", header.Filename)
+	loader.Invalidate()
 
 	// Return updated file list
-	files, _ := loader.GetFileInfo()
+	files, _ := fileInfo()
 	partialData := map[string]interface{}{
 		"Files": files,
 	}
@@ -466,9 +657,10 @@ func handleFileDelete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("Deleted file: %s", filename)
+	loader.Invalidate()
 
 	// Return updated file list
-	files, _ := loader.GetFileInfo()
+	files, _ := fileInfo()
 	partialData := map[string]interface{}{
 		"Files": files,
 	}
@@ -529,6 +721,13 @@ func sortTransactions(ts *models.TransactionSet, field, order string) *models.Tr
 			}
 			return sorted.Transactions[i].TransactionType > sorted.Transactions[j].TransactionType
 		})
+	case "account":
+		sort.Slice(sorted.Transactions, func(i, j int) bool {
+			if order == "asc" {
+				return strings.ToLower(sorted.Transactions[i].Account) < strings.ToLower(sorted.Transactions[j].Account)
+			}
+			return strings.ToLower(sorted.Transactions[i].Account) > strings.ToLower(sorted.Transactions[j].Account)
+		})
 	case "source":
 		sort.Slice(sorted.Transactions, func(i, j int) bool {
 			if order == "asc" {
@@ -546,6 +745,51 @@ func sortTransactions(ts *models.TransactionSet, field, order string) *models.Tr
 	return sorted
 }
 
+// parseAmountRange parses the min/max amount filters. Either bound may be
+// empty; "$" and thousands separators are ignored and reversed bounds swapped.
+func parseAmountRange(minStr, maxStr string) (float64, float64) {
+	clean := strings.NewReplacer("$", "", ",", "", " ", "")
+
+	lo, _ := strconv.ParseFloat(clean.Replace(minStr), 64)
+	hi := math.MaxFloat64
+	if maxStr != "" {
+		if v, err := strconv.ParseFloat(clean.Replace(maxStr), 64); err == nil {
+			hi = v
+		}
+	}
+	lo, hi = math.Abs(lo), math.Abs(hi)
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	return lo, hi
+}
+
+// parseWeekdays parses a comma-separated list of days ("sat,sun", "monday")
+// or the shortcuts "weekend" and "weekdays". Unknown values are ignored.
+func parseWeekdays(value string) []time.Weekday {
+	var days []time.Weekday
+	for _, part := range strings.Split(strings.ToLower(value), ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+			continue
+		case part == "weekend":
+			days = append(days, time.Saturday, time.Sunday)
+		case part == "weekdays":
+			days = append(days, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+		default:
+			for d := time.Sunday; d <= time.Saturday; d++ {
+				name := strings.ToLower(d.String())
+				if len(part) >= 3 && strings.HasPrefix(name, part) {
+					days = append(days, d)
+					break
+				}
+			}
+		}
+	}
+	return days
+}
+
 // calculatePageRange returns a slice of page numbers to display in pagination
 func calculatePageRange(currentPage, totalPages int) []int {
 	if totalPages <= 7 {
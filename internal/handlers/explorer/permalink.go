@@ -0,0 +1,91 @@
+package explorer
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/models"
+)
+
+// merchantHistoryLimit is how many of a merchant's other transactions a
+// permalink page lists
+const merchantHistoryLimit = 24
+
+// handleTransactionPage shows one transaction by hash with the rest of that
+// day and its merchant's history, so alerts and summaries can link to the
+// exact item
+func handleTransactionPage(w http.ResponseWriter, r *http.Request) {
+	txn, err := findTransaction(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if txn == nil {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+	data, err := loader.LoadData()
+	if err != nil {
+		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	context := transactionContext(data, *txn)
+
+	if renderer != nil {
+		renderer.Render(w, "base", map[string]interface{}{
+			"Title":     txn.Description,
+			"ActiveTab": "transaction",
+			"Context":   context,
+		})
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(context)
+	}
+}
+
+// transactionContext gathers the transactions on the same day and those with
+// the same merchant, matched as insights matches recurring payments
+func transactionContext(data *models.TransactionSet, txn models.Transaction) models.TransactionContext {
+	result := models.TransactionContext{
+		Transaction: txn,
+		SameDay:     []models.Transaction{},
+		Merchant:    models.MerchantKey(txn.Description),
+		History:     []models.Transaction{},
+	}
+
+	total := 0.0
+	day := txn.Date.Format("2006-01-02")
+	for _, t := range data.Transactions {
+		if t.Hash == txn.Hash {
+			result.HistoryCount++
+			total += math.Abs(t.Amount)
+			continue
+		}
+		if t.Date.Format("2006-01-02") == day {
+			result.SameDay = append(result.SameDay, t)
+		}
+		if models.MerchantKey(t.Description) == result.Merchant {
+			result.History = append(result.History, t)
+			result.HistoryCount++
+			total += math.Abs(t.Amount)
+		}
+	}
+	if result.HistoryCount > 0 {
+		result.AverageAmount = math.Round(total/float64(result.HistoryCount)*100) / 100
+	}
+
+	sort.SliceStable(result.SameDay, func(i, j int) bool {
+		return math.Abs(result.SameDay[i].Amount) > math.Abs(result.SameDay[j].Amount)
+	})
+	sort.SliceStable(result.History, func(i, j int) bool {
+		return result.History[i].Date.After(result.History[j].Date)
+	})
+	if len(result.History) > merchantHistoryLimit {
+		result.History = result.History[:merchantHistoryLimit]
+	}
+	return result
+}
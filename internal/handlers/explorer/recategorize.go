@@ -0,0 +1,82 @@
+package explorer
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/models"
+)
+
+// handleRecategorize sets one transaction's category by hand. The override
+// is kept by transaction hash, so it survives reloading the files; a blank
+// category reverts to the category from the file or a rule.
+func handleRecategorize(w http.ResponseWriter, r *http.Request) {
+	t := recategorize(w, r)
+	if t == nil {
+		return
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "transaction-category", t)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t)
+	}
+}
+
+// handleRecategorizeAPI sets a transaction's category as handleRecategorize
+// does and returns the transaction as JSON
+func handleRecategorizeAPI(w http.ResponseWriter, r *http.Request) {
+	t := recategorize(w, r)
+	if t == nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// recategorize saves the category form value for the transaction in the
+// URL and returns it reloaded, or writes the error and returns nil
+func recategorize(w http.ResponseWriter, r *http.Request) *models.Transaction {
+	id := chi.URLParam(r, "id")
+	t, err := findTransaction(id)
+	if err != nil {
+		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+	if t == nil {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return nil
+	}
+
+	if err := edits.Set(*t, r.FormValue("category")); err != nil {
+		http.Error(w, "Error saving category: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	// Reload so the response shows the category as the rest of the app sees it
+	t, err = findTransaction(id)
+	if err != nil || t == nil {
+		http.Error(w, "Error reloading transaction", http.StatusInternalServerError)
+		return nil
+	}
+	return t
+}
+
+// findTransaction returns the loaded transaction with the given hash, or
+// nil if there is none
+func findTransaction(hash string) (*models.Transaction, error) {
+	data, err := loader.LoadData()
+	if err != nil {
+		return nil, err
+	}
+	for i := range data.Transactions {
+		if data.Transactions[i].Hash == hash {
+			return &data.Transactions[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,47 @@
+package explorer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"budget2/internal/models"
+)
+
+// scrollCursor returns the cursor infinite scroll loads the page after page
+// with, or "" when page is the last. It pairs the next page number with a
+// fingerprint of the rows, so a later request can tell whether it is still
+// paging through the same list.
+func scrollCursor(sorted *models.TransactionSet, page, perPage, totalPages int) string {
+	if page >= totalPages {
+		return ""
+	}
+	return strconv.Itoa(page+1) + "-" + rowsFingerprint(sorted, perPage)
+}
+
+// parseScrollCursor splits a cursor into its page number and fingerprint
+func parseScrollCursor(cursor string) (int, string, bool) {
+	pageStr, fingerprint, ok := strings.Cut(cursor, "-")
+	if !ok {
+		return 0, "", false
+	}
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		return 0, "", false
+	}
+	return page, fingerprint, true
+}
+
+// rowsFingerprint hashes the page size and the order of the sorted rows.
+// Changing a filter or the sort, importing or deleting data, or editing a
+// transaction so it moves all change it.
+func rowsFingerprint(sorted *models.TransactionSet, perPage int) string {
+	h := sha256.New()
+	h.Write([]byte(strconv.Itoa(perPage)))
+	for _, t := range sorted.Transactions {
+		h.Write([]byte{0})
+		h.Write([]byte(t.Hash))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,41 @@
+package explorer
+
+import (
+	"log"
+	"net/http"
+
+	"budget2/internal/models"
+)
+
+// sheetSyncStatus returns how the last Google Sheets import went, or nil
+// when the import is off
+func sheetSyncStatus() *models.SheetSyncStatus {
+	if importer == nil {
+		return nil
+	}
+	status, err := importer.Status()
+	if err != nil {
+		log.Printf("Warning: reading Google Sheets import status: %v", err)
+		return nil
+	}
+	return status
+}
+
+// handleSheetSync imports every sheet now rather than waiting for the
+// schedule. Sheets that can't be read are recorded in the status the file
+// manager shows, so only a failure to save that status is an error here.
+func handleSheetSync(w http.ResponseWriter, r *http.Request) {
+	if importer == nil {
+		http.Error(w, "Google Sheets import is not configured", http.StatusNotFound)
+		return
+	}
+	status, err := importer.Sync()
+	if status == nil {
+		http.Error(w, "Google Sheets import failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		log.Printf("Warning: Google Sheets import failed: %v", err)
+	}
+	backToFiles(w, r)
+}
@@ -0,0 +1,139 @@
+package explorer
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/models"
+)
+
+// splitData is what the split editor shows for one transaction
+type splitData struct {
+	Transaction models.Transaction // As in the file, before splitting
+	Parts       []models.SplitPart // The current split, or the whole amount in one part
+	Split       bool
+}
+
+// handleSplitEditor shows the editor for splitting a transaction among
+// categories
+func handleSplitEditor(w http.ResponseWriter, r *http.Request) {
+	data, err := findSplit(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if data == nil {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "transaction-split", data)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(data)
+	}
+}
+
+// handleSplitSave splits a transaction among the categories and amounts
+// posted as parallel category and amount fields. The split is kept by
+// transaction hash, so it survives reloading the files.
+func handleSplitSave(w http.ResponseWriter, r *http.Request) {
+	data, err := findSplit(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if data == nil {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	categories, amounts := r.Form["category"], r.Form["amount"]
+	if len(categories) != len(amounts) {
+		http.Error(w, "Each part needs a category and an amount", http.StatusBadRequest)
+		return
+	}
+	parts := make([]models.SplitPart, len(categories))
+	for i := range categories {
+		parts[i].Category = categories[i]
+		amount := strings.TrimSpace(strings.NewReplacer("$", "", ",", "").Replace(amounts[i]))
+		if amount == "" {
+			continue
+		}
+		if parts[i].Amount, err = strconv.ParseFloat(amount, 64); err != nil {
+			http.Error(w, "Invalid amount: "+amounts[i], http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := splitter.Set(data.Transaction, parts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSplitDelete puts a split transaction back together
+func handleSplitDelete(w http.ResponseWriter, r *http.Request) {
+	data, err := findSplit(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if data == nil {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+
+	if err := splitter.Set(data.Transaction, nil); err != nil {
+		http.Error(w, "Error saving split: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findSplit returns the loaded transaction with the given hash and its
+// split, putting a split transaction back together from its parts. It
+// returns nil if there is no such transaction.
+func findSplit(hash string) (*splitData, error) {
+	data, err := loader.LoadData()
+	if err != nil {
+		return nil, err
+	}
+
+	var result *splitData
+	total := 0.0
+	for _, t := range data.Transactions {
+		switch hash {
+		case t.Hash:
+			return &splitData{
+				Transaction: t,
+				Parts:       []models.SplitPart{{Category: t.Category, Amount: math.Abs(t.Amount)}},
+			}, nil
+		case t.SplitOf:
+			if result == nil {
+				whole := t
+				whole.Hash = hash
+				whole.SplitOf = ""
+				whole.Category = ""
+				result = &splitData{Transaction: whole, Split: true}
+			}
+			result.Parts = append(result.Parts, models.SplitPart{Category: t.Category, Amount: math.Abs(t.Amount)})
+			total += t.Amount
+		}
+	}
+	if result != nil {
+		result.Transaction.Amount = math.Round(total*100) / 100
+	}
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package explorer
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// handleCategoryStyles lists the color and icon of every category, assigning
+// styles to categories in the data that don't have one yet
+func handleCategoryStyles(w http.ResponseWriter, r *http.Request) {
+	data, err := loader.LoadData()
+	if err != nil {
+		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := styles.Assign(data.Categories()); err != nil {
+		http.Error(w, "Error saving category styles: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderCategoryStyles(w)
+}
+
+// handleCategoryStyleSet overrides one category's color and icon
+func handleCategoryStyleSet(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	category := strings.TrimSpace(r.FormValue("category"))
+	if category == "" {
+		http.Error(w, "category is required", http.StatusBadRequest)
+		return
+	}
+	if _, err := styles.Set(category, r.FormValue("color"), r.FormValue("icon")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderCategoryStyles(w)
+}
+
+// handleCategoryStyleReset returns a category to its automatic style
+func handleCategoryStyleReset(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := styles.Reset(r.FormValue("category")); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderCategoryStyles(w)
+}
+
+// renderCategoryStyles renders the category styles panel
+func renderCategoryStyles(w http.ResponseWriter) {
+	list, err := styles.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	partialData := map[string]interface{}{
+		"Styles": list,
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "category-styles", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
@@ -0,0 +1,61 @@
+package explorer
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/models"
+)
+
+// handleRetag replaces one transaction's tags with the comma-separated
+// tags posted. Tags are kept by transaction hash, so they survive reloading
+// the files; a share of a split transaction tags the whole one, so every
+// share carries the same tags. Blank tags remove them all.
+func handleRetag(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	t, err := findTaggable(id)
+	if err != nil {
+		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if t == nil {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+
+	if err := tagger.Set(id, models.ParseTags(r.FormValue("tags"))); err != nil {
+		http.Error(w, "Error saving tags: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Reload so the row shows the tags as the rest of the app sees them
+	t, err = findTaggable(id)
+	if err != nil || t == nil {
+		http.Error(w, "Error reloading transaction", http.StatusInternalServerError)
+		return
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "transaction-tags", t)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t)
+	}
+}
+
+// findTaggable returns the loaded transaction with the given hash, or for
+// a split transaction its first share, or nil if there is neither
+func findTaggable(hash string) (*models.Transaction, error) {
+	data, err := loader.LoadData()
+	if err != nil {
+		return nil, err
+	}
+	for i := range data.Transactions {
+		if data.Transactions[i].Hash == hash || data.Transactions[i].SplitOf == hash {
+			return &data.Transactions[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,66 @@
+package insights
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"budget2/internal/models"
+	"budget2/internal/services/analytics"
+)
+
+// UpcomingBills forecasts the bills due in the month after data's latest
+// transaction from its recurring payments. Payments marked cancelled are
+// left out unless they've been charged since.
+func UpcomingBills(data *models.TransactionSet, recurring []models.RecurringPayment) *models.BillForecast {
+	if data.Len() == 0 {
+		return nil
+	}
+
+	var cancellations []models.SubscriptionCancellation
+	if tracker != nil {
+		var err error
+		if cancellations, err = tracker.List(); err != nil {
+			log.Printf("Warning: failed to load cancellations: %v", err)
+		}
+	}
+	active := make([]models.RecurringPayment, 0, len(recurring))
+	for _, r := range recurring {
+		cancelled := false
+		for _, c := range cancellations {
+			if c.Merchant == r.Merchant && !r.LastDate.After(c.CancelledOn) {
+				cancelled = true
+				break
+			}
+		}
+		if !cancelled {
+			active = append(active, r)
+		}
+	}
+	return analytics.ForecastBills(active, data.MaxDate(), analytics.BillForecastDays, budgetCycle.WeekStart)
+}
+
+// handleBillsPartial renders the bills due over the next month and the
+// ones missed
+func handleBillsPartial(w http.ResponseWriter, r *http.Request) {
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recurring := cachedInsight(r, recurringKey, func() interface{} {
+		return DetectRecurringPayments(data)
+	}).([]models.RecurringPayment)
+
+	partialData := map[string]interface{}{
+		"Forecast": UpcomingBills(data, recurring),
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "upcoming-bills", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
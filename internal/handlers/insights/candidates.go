@@ -0,0 +1,163 @@
+package insights
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"budget2/internal/models"
+	"budget2/internal/services/retirement"
+	"budget2/internal/services/subscriptions"
+)
+
+// impactRuns is how many Monte Carlo runs each side of the cancellation
+// what-if gets, as many as a sweep point gets by default
+const impactRuns = 500
+
+func handleCandidatesPartial(w http.ResponseWriter, r *http.Request) {
+	renderCandidates(w, nil)
+}
+
+// handleAddCandidate marks a recurring payment as a cancel candidate
+func handleAddCandidate(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	description := strings.TrimSpace(r.FormValue("description"))
+	if description == "" {
+		http.Error(w, "Description is required", http.StatusBadRequest)
+		return
+	}
+	amount, _ := strconv.ParseFloat(r.FormValue("amount"), 64)
+	annualCost, _ := strconv.ParseFloat(r.FormValue("annual_cost"), 64)
+
+	if _, err := tracker.AddCandidate(models.CancelCandidate{
+		ID:          uuid.New().String(),
+		Description: description,
+		Amount:      amount,
+		Frequency:   r.FormValue("frequency"),
+		AnnualCost:  annualCost,
+		AddedAt:     time.Now(),
+	}); err != nil {
+		http.Error(w, "Failed to save cancel candidate: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderCandidates(w, nil)
+}
+
+func handleRemoveCandidate(w http.ResponseWriter, r *http.Request) {
+	if _, err := tracker.RemoveCandidate(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, "Failed to remove cancel candidate: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderCandidates(w, nil)
+}
+
+// handleApplyCandidates takes the candidates' monthly cost off the what-if
+// plan's living expenses, then clears the candidates so the same savings
+// can't be taken off twice
+func handleApplyCandidates(w http.ResponseWriter, r *http.Request) {
+	list, err := tracker.Candidates()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(list) == 0 {
+		http.Error(w, "No cancel candidates to apply", http.StatusBadRequest)
+		return
+	}
+	var monthly float64
+	for _, c := range list {
+		monthly += c.AnnualCost / 12
+	}
+
+	applied := &models.CancellationWhatIf{}
+	_, err = planner.Modify(func(s *models.WhatIfSettings) error {
+		if s.MonthlyLivingExpenses <= 0 {
+			return fmt.Errorf("the what-if plan has no living expenses to lower")
+		}
+		applied.LivingExpenses = s.MonthlyLivingExpenses
+		s.MonthlyLivingExpenses = max(0, s.MonthlyLivingExpenses-monthly)
+		applied.NewLivingExpenses = s.MonthlyLivingExpenses
+		return nil
+	})
+	if err != nil {
+		http.Error(w, "Failed to update the what-if plan: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := tracker.ClearCandidates(); err != nil {
+		http.Error(w, "Failed to clear cancel candidates: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderCandidates(w, applied)
+}
+
+// cancellationWhatIf runs the saved what-if plan with its living expenses
+// and again with monthly taken off them, or returns nil when the plan has
+// no living expenses to lower
+func cancellationWhatIf(monthly float64) *models.CancellationWhatIf {
+	if monthly <= 0 {
+		return nil
+	}
+	settings, err := planner.Load()
+	if err != nil {
+		log.Printf("Warning: loading what-if settings: %v", err)
+		return nil
+	}
+	if settings.MonthlyLivingExpenses <= 0 {
+		return nil
+	}
+
+	living := settings.MonthlyLivingExpenses
+	sweep, err := retirement.NewCalculator(settings).RunSweep("monthly_living_expenses", []float64{living, max(0, living-monthly)}, impactRuns)
+	if err != nil {
+		log.Printf("Warning: cancellation what-if: %v", err)
+		return nil
+	}
+	return &models.CancellationWhatIf{
+		LivingExpenses:    living,
+		NewLivingExpenses: sweep.Points[1].Value,
+		Runs:              sweep.Runs,
+		Before:            sweep.Points[0],
+		After:             sweep.Points[1],
+	}
+}
+
+// renderCandidates renders the cancel candidates with what cancelling them
+// would save, noting the living expenses change when applied is set
+func renderCandidates(w http.ResponseWriter, applied *models.CancellationWhatIf) {
+	list, err := tracker.Candidates()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err := loader.LoadData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	impact := subscriptions.Impact(list, data)
+	impact.WhatIf = cancellationWhatIf(impact.MonthlySavings)
+
+	partialData := map[string]interface{}{
+		"Impact":  impact,
+		"Applied": applied,
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "cancel-candidates", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
@@ -0,0 +1,99 @@
+package insights
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/services/monthclose"
+)
+
+// Month close works on all enabled files: a month is closed for the
+// household, not for a narrowed view of it.
+
+func handleMonthClosePartial(w http.ResponseWriter, r *http.Request) {
+	renderMonthClose(w)
+}
+
+func handleMonthCloseItem(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	month := chi.URLParam(r, "month")
+	done := r.FormValue("done") == "true" || r.FormValue("done") == "on"
+	if err := closer.SetItem(month, r.FormValue("item"), done); err != nil {
+		http.Error(w, "Failed to update checklist: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderMonthClose(w)
+}
+
+func handleCloseMonth(w http.ResponseWriter, r *http.Request) {
+	month := chi.URLParam(r, "month")
+
+	data, err := loader.LoadData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	closes, err := closer.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	monthData, err := monthclose.MonthData(data, month)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, status := range monthclose.Evaluate(closes, data).Months {
+		if status.Month == month && !status.Ready {
+			http.Error(w, "Finish the checklist before closing "+status.Label, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := closer.Close(month, monthclose.TakeSnapshot(monthData), time.Now()); err != nil {
+		http.Error(w, "Failed to close month: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderMonthClose(w)
+}
+
+func handleReopenMonth(w http.ResponseWriter, r *http.Request) {
+	if err := closer.Reopen(chi.URLParam(r, "month")); err != nil {
+		http.Error(w, "Failed to reopen month: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderMonthClose(w)
+}
+
+// renderMonthClose checks saved closes against current data and renders the panel
+func renderMonthClose(w http.ResponseWriter) {
+	data, err := loader.LoadData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	closes, err := closer.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	partialData := map[string]interface{}{
+		"MonthClose": monthclose.Evaluate(closes, data),
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "month-close", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
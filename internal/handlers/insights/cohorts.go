@@ -0,0 +1,101 @@
+package insights
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/models"
+	"budget2/internal/services/cohorts"
+)
+
+// viewCohort returns the cohort named by the request's view parameter, or
+// nil when there is none
+func viewCohort(r *http.Request) (*models.Cohort, error) {
+	id := r.URL.Query().Get("view")
+	if id == "" {
+		return nil, nil
+	}
+	if grouped == nil {
+		return nil, fmt.Errorf("unknown view %q", id)
+	}
+	cohort, err := grouped.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if cohort == nil {
+		return nil, fmt.Errorf("unknown view %q", id)
+	}
+	return cohort, nil
+}
+
+// listCohorts returns the saved cohorts, or none without a manager
+func listCohorts() ([]models.Cohort, error) {
+	if grouped == nil {
+		return nil, nil
+	}
+	return grouped.List()
+}
+
+func handleCohortsPartial(w http.ResponseWriter, r *http.Request) {
+	list, err := grouped.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderCohorts(w, list)
+}
+
+// handleCohortAdd saves a cohort from its name and filter, replacing one
+// with the same name
+func handleCohortAdd(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	list, err := grouped.Add(models.Cohort{
+		Name:      r.FormValue("name"),
+		Filter:    r.FormValue("filter"),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		http.Error(w, "Failed to save cohort: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderCohorts(w, list)
+}
+
+func handleCohortRemove(w http.ResponseWriter, r *http.Request) {
+	list, err := grouped.Remove(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Failed to remove cohort: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderCohorts(w, list)
+}
+
+// renderCohorts counts each cohort's transactions across all files and
+// renders the panel
+func renderCohorts(w http.ResponseWriter, list []models.Cohort) {
+	data, err := loader.LoadData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	partialData := map[string]interface{}{
+		"Cohorts": cohorts.Statuses(list, data),
+		"Fields":  cohorts.FilterFields,
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "insights-cohorts", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
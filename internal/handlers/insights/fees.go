@@ -0,0 +1,83 @@
+package insights
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/classifier"
+)
+
+// addFee counts a fee toward the total for its kind
+func addFee(total *models.FeeTotal, f models.Fee) {
+	amount := math.Abs(f.Amount)
+	switch f.Kind {
+	case models.FeeInterest:
+		total.Interest += amount
+	case models.FeeLate:
+		total.LateFees += amount
+	default:
+		total.BankFees += amount
+	}
+	total.Total += amount
+	total.Count++
+}
+
+// sortedFeeTotals returns the totals in m, newest period first
+func sortedFeeTotals(m map[string]*models.FeeTotal) []models.FeeTotal {
+	result := make([]models.FeeTotal, 0, len(m))
+	for _, t := range m {
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Period > result[j].Period
+	})
+	return result
+}
+
+// analyzeFees finds interest charges and fees in ts across every account and
+// totals them by month and year. The monthly average spreads the total over
+// every month in [start, end], including months without fees.
+func analyzeFees(ts *models.TransactionSet, start, end time.Time) *models.FeeSummary {
+	summary := &models.FeeSummary{
+		ByMonth: []models.FeeTotal{},
+		ByYear:  []models.FeeTotal{},
+		Fees:    []models.Fee{},
+	}
+
+	months := make(map[string]*models.FeeTotal)
+	years := make(map[string]*models.FeeTotal)
+	for _, t := range ts.Transactions {
+		kind := classifier.FeeKind(&t)
+		if kind == "" {
+			continue
+		}
+		f := models.Fee{Transaction: t, Kind: kind}
+		summary.Fees = append(summary.Fees, f)
+		addFee(&summary.FeeTotal, f)
+
+		month := t.Date.Format("2006-01")
+		if months[month] == nil {
+			months[month] = &models.FeeTotal{Period: month, Label: t.Date.Format("Jan 2006")}
+		}
+		addFee(months[month], f)
+
+		year := t.Date.Format("2006")
+		if years[year] == nil {
+			years[year] = &models.FeeTotal{Period: year, Label: year}
+		}
+		addFee(years[year], f)
+	}
+
+	sort.SliceStable(summary.Fees, func(i, j int) bool {
+		return summary.Fees[i].Date.After(summary.Fees[j].Date)
+	})
+	summary.ByMonth = sortedFeeTotals(months)
+	summary.ByYear = sortedFeeTotals(years)
+
+	if span := (end.Year()-start.Year())*12 + int(end.Month()-start.Month()) + 1; span > 0 {
+		summary.MonthlyAverage = summary.Total / float64(span)
+	}
+	return summary
+}
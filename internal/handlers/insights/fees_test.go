@@ -0,0 +1,46 @@
+package insights
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+)
+
+func TestAnalyzeFees(t *testing.T) {
+	d := func(s string) time.Time {
+		date, _ := time.Parse("2006-01-02", s)
+		return date
+	}
+	data := models.NewTransactionSet([]models.Transaction{
+		{Date: d("2024-12-15"), Description: "INTEREST CHARGE ON PURCHASES", Amount: -21.50, TransactionType: models.Outflow, SourceFile: "visa.csv"},
+		{Date: d("2025-01-03"), Description: "LATE FEE", Amount: -39, TransactionType: models.Outflow, SourceFile: "visa.csv"},
+		{Date: d("2025-01-10"), Description: "OVERDRAFT ITEM", Amount: -35, TransactionType: models.Outflow, SourceFile: "checking.csv"},
+		{Date: d("2025-01-12"), Description: "WIRE OUT", Amount: -15, Category: "Bank Fees", TransactionType: models.Outflow, SourceFile: "checking.csv"},
+		// Not fees: interest earned, a fee reversal and a purchase
+		{Date: d("2025-01-05"), Description: "INTEREST INCOME", Amount: 12.34, Category: "Interest", TransactionType: models.Income},
+		{Date: d("2025-01-11"), Description: "OVERDRAFT FEE REVERSAL", Amount: 35, TransactionType: models.Outflow},
+		{Date: d("2025-01-20"), Description: "COFFEE SHOP", Amount: -5, TransactionType: models.Outflow},
+	})
+
+	fees := analyzeFees(data, d("2024-11-01"), d("2025-01-31"))
+
+	if fees.Count != 4 || fees.Total != 110.5 {
+		t.Fatalf("found %d fees totalling %.2f, want 4 totalling 110.50: %+v", fees.Count, fees.Total, fees.Fees)
+	}
+	if fees.Interest != 21.5 || fees.LateFees != 39 || fees.BankFees != 50 {
+		t.Errorf("by kind = %.2f interest, %.2f late, %.2f bank", fees.Interest, fees.LateFees, fees.BankFees)
+	}
+	if fees.MonthlyAverage != 110.5/3 {
+		t.Errorf("monthly average = %.2f, want the total over 3 months", fees.MonthlyAverage)
+	}
+	if len(fees.ByMonth) != 2 || fees.ByMonth[0].Period != "2025-01" || fees.ByMonth[0].Count != 3 {
+		t.Errorf("by month = %+v, want Jan 2025 first with 3 fees", fees.ByMonth)
+	}
+	if len(fees.ByYear) != 2 || fees.ByYear[1].Total != 21.5 {
+		t.Errorf("by year = %+v", fees.ByYear)
+	}
+	if fees.Fees[0].Description != "WIRE OUT" || fees.Fees[0].Kind != models.FeeBank {
+		t.Errorf("fees should be listed newest first, got %+v", fees.Fees[0])
+	}
+}
@@ -0,0 +1,152 @@
+package insights
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	apphttp "budget2/internal/http"
+	"budget2/internal/models"
+	"budget2/internal/services/giving"
+)
+
+// givingForRequest summarizes giving for the request's year, defaulting to
+// the latest year with giving
+func givingForRequest(r *http.Request) (*models.GivingSummary, []models.GivingCategory, *models.TransactionSet, error) {
+	data, err := loadData(r)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	categories, err := gifting.List()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	year, _ := strconv.Atoi(r.URL.Query().Get("year"))
+	return giving.Summarize(data, categories, year), categories, data, nil
+}
+
+func handleGivingPartial(w http.ResponseWriter, r *http.Request) {
+	renderGiving(w, r)
+}
+
+func handleSetGivingCategory(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	_, err := gifting.Set(models.GivingCategory{
+		Category:   r.PostFormValue("category"),
+		Deductible: r.PostFormValue("deductible") == "true",
+	})
+	if err != nil {
+		http.Error(w, "Failed to save giving category: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderGiving(w, r)
+}
+
+func handleRemoveGivingCategory(w http.ResponseWriter, r *http.Request) {
+	category, err := url.PathUnescape(chi.URLParam(r, "category"))
+	if err != nil {
+		http.Error(w, "Invalid category encoding", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := gifting.Remove(category); err != nil {
+		http.Error(w, "Failed to remove giving category: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderGiving(w, r)
+}
+
+// renderGiving renders the giving summary panel
+func renderGiving(w http.ResponseWriter, r *http.Request) {
+	summary, categories, data, err := givingForRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	partialData := map[string]interface{}{
+		"Giving":        summary,
+		"Categories":    categories,
+		"AllCategories": data.Categories(),
+		"Sources":       apphttp.ParseSources(r.URL.Query()),
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "giving-summary", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
+
+// handleGivingExport downloads a year's giving for tax filing: every gift,
+// then per-recipient totals and the deductible subtotal
+func handleGivingExport(w http.ResponseWriter, r *http.Request) {
+	summary, _, _, err := givingForRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("giving_%d", summary.Year)
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.json\"", filename))
+		json.NewEncoder(w).Encode(summary)
+		return
+	}
+
+	deductible := func(d bool) string {
+		if d {
+			return "Yes"
+		}
+		return "No"
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"Date", "Recipient", "Category", "Amount", "Tax Deductible", "Source File"})
+	for _, g := range summary.Gifts {
+		writer.Write([]string{
+			g.Date.Format("2006-01-02"),
+			g.Recipient,
+			g.Category,
+			fmt.Sprintf("%.2f", math.Abs(g.Amount)),
+			deductible(g.Deductible),
+			g.SourceFile,
+		})
+	}
+
+	writer.Write(nil)
+	writer.Write([]string{"Recipient", "Gifts", "Total", "Tax Deductible"})
+	for _, rec := range summary.Recipients {
+		writer.Write([]string{
+			rec.Recipient,
+			strconv.Itoa(rec.Count),
+			fmt.Sprintf("%.2f", rec.Total),
+			deductible(rec.Deductible),
+		})
+	}
+
+	writer.Write(nil)
+	writer.Write([]string{"Tax-deductible total", fmt.Sprintf("%.2f", summary.DeductibleTotal)})
+	writer.Write([]string{"Non-deductible gifts", fmt.Sprintf("%.2f", summary.GiftTotal)})
+	writer.Write([]string{"Total giving", fmt.Sprintf("%.2f", summary.Total)})
+	writer.Flush()
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.csv\"", filename))
+	w.Write(buf.Bytes())
+}
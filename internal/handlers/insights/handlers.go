@@ -1,48 +1,167 @@
 package insights
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
+	"log"
 	"math"
 	"net/http"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 
+	apphttp "budget2/internal/http"
 	"budget2/internal/models"
+	"budget2/internal/services/analytics"
+	"budget2/internal/services/benchmarks"
+	"budget2/internal/services/cache"
+	"budget2/internal/services/categories"
+	"budget2/internal/services/cohorts"
 	"budget2/internal/services/dataloader"
+	"budget2/internal/services/giving"
+	"budget2/internal/services/monthclose"
+	"budget2/internal/services/retirement"
+	"budget2/internal/services/statements"
+	"budget2/internal/services/subscriptions"
 	"budget2/internal/templates"
 )
 
 var (
 	loader   *dataloader.DataLoader
 	renderer *templates.Renderer
+	tracker  *subscriptions.Tracker
+	baseline *benchmarks.Manager
+	closer   *monthclose.Manager
+	cycles   *statements.Manager
+	gifting  *giving.Manager
+	grouped  *cohorts.Manager
+	planner  *retirement.SettingsManager
+
+	// monthlyBudget is the configured monthly budget for the burn-down
+	// chart; zero uses average spending
+	monthlyBudget float64
+
+	// budgetCycle picks a monthly or weekly burn-down and projection
+	budgetCycle models.BudgetCycle
 )
 
+// insightCache holds analysis results keyed by data version and date range
+var insightCache = cache.New(5 * time.Minute)
+
+// cachedInsight returns the cached result for key, computing it on a miss.
+// Entries are tied to the loader's data version so uploads invalidate them,
+// and keyed by the request's sources and view so narrowed views are cached
+// separately.
+func cachedInsight(r *http.Request, key string, compute func() interface{}) interface{} {
+	version, err := loader.DataVersion()
+	if err != nil {
+		return compute()
+	}
+	if sources := apphttp.ParseSources(r.URL.Query()); len(sources) > 0 {
+		key = cache.Key(key, strings.Join(sources, ","))
+	}
+	if view := r.URL.Query().Get("view"); view != "" {
+		key = cache.Key(key, "view", view, grouped.Version())
+	}
+	return insightCache.GetOrCompute(version, key, compute)
+}
+
 // Initialize sets up the insights package with required dependencies
-func Initialize(l *dataloader.DataLoader, r *templates.Renderer) {
+func Initialize(l *dataloader.DataLoader, r *templates.Renderer, t *subscriptions.Tracker, b *benchmarks.Manager, mc *monthclose.Manager, sc *statements.Manager, gv *giving.Manager, co *cohorts.Manager, rs *retirement.SettingsManager, th models.TrendThresholds, budget float64, cycle models.BudgetCycle) {
 	loader = l
 	renderer = r
+	tracker = t
+	baseline = b
+	closer = mc
+	cycles = sc
+	gifting = gv
+	grouped = co
+	planner = rs
+	trendDefaults = th
+	monthlyBudget = budget
+	budgetCycle = cycle
+}
+
+// loadData loads transactions for a request, limited to the files named in
+// its sources parameter and the cohort named in its view parameter when
+// present
+func loadData(r *http.Request) (*models.TransactionSet, error) {
+	data, err := loader.LoadSources(apphttp.ParseSources(r.URL.Query()))
+	if err != nil {
+		return nil, err
+	}
+	cohort, err := viewCohort(r)
+	if err != nil || cohort == nil {
+		return data, err
+	}
+	filter, err := cohorts.Parse(cohort.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("cohort %s: %w", cohort.Name, err)
+	}
+	return filter.Apply(data), nil
+}
+
+// RegisterAPIRoutes registers the insights JSON API routes
+func RegisterAPIRoutes(r chi.Router) {
+	r.Get("/api/v1/insights", handleInsightsAPI)
 }
 
 // RegisterRoutes registers all insights routes
 func RegisterRoutes(r chi.Router) {
 	r.Get("/insights", handleInsights)
+	r.Get("/insights/export", handleInsightsExport)
 	r.Get("/insights/recurring", handleRecurringPartial)
+	r.Get("/insights/recurring/export", handleRecurringExport)
+	r.Get("/insights/bills", handleBillsPartial)
+	r.Get("/insights/cancellations", handleCancellationsPartial)
+	r.Post("/insights/cancellations", handleAddCancellation)
+	r.Delete("/insights/cancellations/{id}", handleDeleteCancellation)
+	r.Get("/insights/candidates", handleCandidatesPartial)
+	r.Post("/insights/candidates", handleAddCandidate)
+	r.Post("/insights/candidates/apply", handleApplyCandidates)
+	r.Delete("/insights/candidates/{id}", handleRemoveCandidate)
+	r.Get("/insights/benchmarks", handleBenchmarksPartial)
+	r.Post("/insights/benchmarks", handleSetBenchmark)
+	r.Post("/insights/benchmarks/reset", handleResetBenchmarks)
+	r.Get("/insights/close", handleMonthClosePartial)
+	r.Post("/insights/close/{month}", handleCloseMonth)
+	r.Post("/insights/close/{month}/checklist", handleMonthCloseItem)
+	r.Post("/insights/close/{month}/reopen", handleReopenMonth)
+	r.Get("/insights/statements", handleStatementsPartial)
+	r.Post("/insights/statements", handleSetStatementCycle)
+	r.Delete("/insights/statements/{source}", handleRemoveStatementCycle)
+	r.Get("/insights/giving", handleGivingPartial)
+	r.Get("/insights/giving/export", handleGivingExport)
+	r.Post("/insights/giving/categories", handleSetGivingCategory)
+	r.Delete("/insights/giving/categories/{category}", handleRemoveGivingCategory)
 	r.Get("/insights/trends", handleTrendsPartial)
 	r.Get("/insights/trends/chart", handleTrendsChartData)
 	r.Get("/insights/velocity", handleVelocityPartial)
+	r.Get("/insights/velocity/burndown", handleBurnDownChart)
 	r.Get("/insights/income", handleIncomePartial)
+	r.Get("/insights/rhythm", handleRhythmPartial)
+	r.Get("/insights/rhythm/chart/weekday", handleRhythmWeekdayChart)
+	r.Get("/insights/rhythm/chart/payday", handleRhythmPaydayChart)
+	r.Get("/insights/cohorts", handleCohortsPartial)
+	r.Post("/insights/cohorts", handleCohortAdd)
+	r.Delete("/insights/cohorts/{id}", handleCohortRemove)
 }
 
 // Utility Functions
 
-func calculateInsights(allData, filtered *models.TransactionSet, startDate, endDate time.Time) *models.InsightsData {
-	recurring := detectRecurringPayments(filtered)
-	trends := analyzeCategoryTrends(allData, startDate, endDate)
+func calculateInsights(allData, filtered *models.TransactionSet, startDate, endDate time.Time, th models.TrendThresholds) *models.InsightsData {
+	recurring := DetectRecurringPayments(filtered)
+	trends, skipped := analyzeCategoryTrends(allData, startDate, endDate, th)
 	income := AnalyzeIncomePatterns(filtered)
-	velocity := calculateSpendingVelocity(filtered, allData)
+	velocity := calculateSpendingVelocity(filtered, allData, budgetCycle)
+	fees := analyzeFees(filtered, startDate, endDate)
 
 	var totalRecurring, monthlyRecurring, regularIncome float64
 	for _, r := range recurring {
@@ -59,15 +178,21 @@ func calculateInsights(allData, filtered *models.TransactionSet, startDate, endD
 	return &models.InsightsData{
 		RecurringPayments:  recurring,
 		CategoryTrends:     trends,
+		TrendThresholds:    th,
+		TrendsSkipped:      skipped,
 		IncomePatterns:     income,
 		Velocity:           velocity,
 		TotalRecurring:     totalRecurring,
 		MonthlyRecurring:   monthlyRecurring,
 		RegularIncomeTotal: regularIncome,
+		Fees:               fees,
+		Tags:               filtered.TagTotals(),
 	}
 }
 
-func detectRecurringPayments(ts *models.TransactionSet) []models.RecurringPayment {
+// DetectRecurringPayments finds subscriptions and other repeating outflows,
+// with when each is next expected
+func DetectRecurringPayments(ts *models.TransactionSet) []models.RecurringPayment {
 	var recurring []models.RecurringPayment
 
 	outflows := ts.FilterByType(models.Outflow)
@@ -75,17 +200,19 @@ func detectRecurringPayments(ts *models.TransactionSet) []models.RecurringPaymen
 		return recurring
 	}
 
+	// Group by merchant rather than exact description so a payment that
+	// moves between cards, whose exports word it differently, stays one
 	groups := make(map[string][]models.Transaction)
 	for _, t := range outflows.Transactions {
-		key := strings.ToLower(strings.TrimSpace(t.Description))
+		key := models.MerchantKey(t.Description)
 		groups[key] = append(groups[key], t)
 	}
 
-	// Track which descriptions matched strict criteria
+	// Track which merchants matched strict criteria
 	strictMatches := make(map[string]bool)
 
 	// First pass: strict recurring detection (consistent amounts and intervals)
-	for desc, txns := range groups {
+	for merchant, txns := range groups {
 		if len(txns) < 3 {
 			continue
 		}
@@ -180,7 +307,9 @@ func detectRecurringPayments(ts *models.TransactionSet) []models.RecurringPaymen
 		nextExpected := lastDate.AddDate(0, 0, int(medianInterval))
 
 		recurring = append(recurring, models.RecurringPayment{
-			Description:  desc,
+			Description:  latestDescription(txns),
+			Merchant:     merchant,
+			Accounts:     recurringAccounts(txns),
 			Amount:       avgAmount,
 			Frequency:    frequency,
 			LastDate:     lastDate,
@@ -190,14 +319,14 @@ func detectRecurringPayments(ts *models.TransactionSet) []models.RecurringPaymen
 			Confidence:   confidence,
 			Transactions: txns,
 		})
-		strictMatches[desc] = true
+		strictMatches[merchant] = true
 	}
 
 	// Second pass: ongoing payment detection (variable amounts but consistent relationship)
 	now := time.Now()
-	for desc, txns := range groups {
+	for merchant, txns := range groups {
 		// Skip if already matched by strict criteria
-		if strictMatches[desc] {
+		if strictMatches[merchant] {
 			continue
 		}
 
@@ -252,7 +381,9 @@ func detectRecurringPayments(ts *models.TransactionSet) []models.RecurringPaymen
 		nextExpected := lastDate.AddDate(0, 0, int(avgInterval))
 
 		recurring = append(recurring, models.RecurringPayment{
-			Description:  desc,
+			Description:  latestDescription(txns),
+			Merchant:     merchant,
+			Accounts:     recurringAccounts(txns),
 			Amount:       avgAmount,
 			Frequency:    "ongoing",
 			LastDate:     lastDate,
@@ -275,75 +406,22 @@ func detectRecurringPayments(ts *models.TransactionSet) []models.RecurringPaymen
 	return recurring
 }
 
-func analyzeCategoryTrends(ts *models.TransactionSet, currentStart, currentEnd time.Time) []models.CategoryTrend {
-	var trends []models.CategoryTrend
-
-	duration := currentEnd.Sub(currentStart)
-	prevStart := currentStart.Add(-duration - 24*time.Hour)
-	prevEnd := currentStart.Add(-24 * time.Hour)
-
-	currentFiltered := ts.FilterByDateRange(currentStart, currentEnd)
-	prevFiltered := ts.FilterByDateRange(prevStart, prevEnd)
-
-	currentOutflows := currentFiltered.FilterByType(models.Outflow)
-	prevOutflows := prevFiltered.FilterByType(models.Outflow)
-
-	currentTotals := currentOutflows.CategoryTotals()
-	prevTotals := prevOutflows.CategoryTotals()
-
-	catSet := make(map[string]bool)
-	for cat := range currentTotals {
-		catSet[cat] = true
-	}
-	for cat := range prevTotals {
-		catSet[cat] = true
-	}
-
-	for cat := range catSet {
-		current := currentTotals[cat]
-		previous := prevTotals[cat]
-
-		var changePercent float64
-		var direction string
+// latestDescription is the most recent occurrence's description, trimmed
+// and lowercased. txns must be sorted oldest first.
+func latestDescription(txns []models.Transaction) string {
+	return strings.ToLower(strings.TrimSpace(txns[len(txns)-1].Description))
+}
 
-		if previous == 0 {
-			if current == 0 {
-				changePercent = 0
-				direction = "stable"
-			} else {
-				changePercent = 100
-				direction = "up"
-			}
-		} else {
-			changePercent = ((current - previous) / previous) * 100
-			if changePercent > 5 {
-				direction = "up"
-			} else if changePercent < -5 {
-				direction = "down"
-			} else {
-				direction = "stable"
-			}
+// recurringAccounts lists the accounts a payment was made from, most
+// recently used first
+func recurringAccounts(txns []models.Transaction) []string {
+	var accounts []string
+	for i := len(txns) - 1; i >= 0; i-- {
+		if a := txns[i].Account; a != "" && !slices.Contains(accounts, a) {
+			accounts = append(accounts, a)
 		}
-
-		trends = append(trends, models.CategoryTrend{
-			Category:       cat,
-			CurrentAmount:  current,
-			PreviousAmount: previous,
-			ChangePercent:  changePercent,
-			ChangeAmount:   current - previous,
-			Direction:      direction,
-		})
 	}
-
-	sort.Slice(trends, func(i, j int) bool {
-		return math.Abs(trends[i].ChangeAmount) > math.Abs(trends[j].ChangeAmount)
-	})
-
-	if len(trends) > 10 {
-		trends = trends[:10]
-	}
-
-	return trends
+	return accounts
 }
 
 // AnalyzeIncomePatterns detects recurring income sources from transaction data.
@@ -417,14 +495,21 @@ func AnalyzeIncomePatterns(ts *models.TransactionSet) []models.IncomePattern {
 			frequency = "one-time"
 		}
 
-		patterns = append(patterns, models.IncomePattern{
-			Description: desc,
-			AvgAmount:   avg,
-			Frequency:   frequency,
-			IsRegular:   isRegular,
-			Occurrences: len(txns),
-			TotalAmount: total,
-		})
+		pattern := models.IncomePattern{
+			Description:   desc,
+			AvgAmount:     avg,
+			Frequency:     frequency,
+			IsRegular:     isRegular,
+			Occurrences:   len(txns),
+			TotalAmount:   total,
+			FirstDate:     txns[0].Date,
+			LastDate:      txns[len(txns)-1].Date,
+			CurrentAmount: avg,
+		}
+		if isRegular {
+			pattern.Changes, pattern.CurrentAmount = incomeChanges(txns)
+		}
+		patterns = append(patterns, pattern)
 	}
 
 	sort.Slice(patterns, func(i, j int) bool {
@@ -438,7 +523,11 @@ func AnalyzeIncomePatterns(ts *models.TransactionSet) []models.IncomePattern {
 	return patterns
 }
 
-func calculateSpendingVelocity(currentPeriod, allData *models.TransactionSet) *models.SpendingVelocity {
+// calculateSpendingVelocity compares the period's daily spending with all
+// of the data and projects the month, and on the weekly cycle the week,
+// at the period's daily average. Projections carry a range from the daily
+// spread of spending across all of the data.
+func calculateSpendingVelocity(currentPeriod, allData *models.TransactionSet, cycle models.BudgetCycle) *models.SpendingVelocity {
 	currentOutflows := currentPeriod.FilterByType(models.Outflow)
 	allOutflows := allData.FilterByType(models.Outflow)
 
@@ -479,19 +568,120 @@ func calculateSpendingVelocity(currentPeriod, allData *models.TransactionSet) *m
 		burnRateChange = ((dailyAvg - historicalDaily) / historicalDaily) * 100
 	}
 
-	return &models.SpendingVelocity{
+	stdDev := analytics.DailySpendStdDev(allData, allMin, allMax)
+	velocity := &models.SpendingVelocity{
 		DailyAverage:    dailyAvg,
 		HistoricalDaily: historicalDaily,
 		MonthProjection: monthProjection,
+		MonthRange:      spentRange(monthProjection, spentSoFar, stdDev, daysRemaining),
 		DaysRemaining:   daysRemaining,
 		BurnRateChange:  burnRateChange,
 	}
+
+	if cycle.Weekly() {
+		weekStart := models.WeekStartOf(now, cycle.WeekStart)
+		weekSpent := currentPeriod.FilterByDateRange(weekStart, now).FilterByType(models.Outflow).SumAbsAmount()
+		velocity.Cycle = models.CycleWeekly
+		velocity.WeekDaysRemaining = 6 - int(now.Weekday()-cycle.WeekStart+7)%7
+		velocity.WeekProjection = weekSpent + dailyAvg*float64(velocity.WeekDaysRemaining)
+		velocity.WeekRange = spentRange(velocity.WeekProjection, weekSpent, stdDev, velocity.WeekDaysRemaining)
+	}
+	return velocity
+}
+
+// spentRange is a spending projection's range over the days left, never
+// below what's already spent
+func spentRange(projection, spent, stdDev float64, days int) *models.ConfidenceRange {
+	r := analytics.ProjectionRange(projection, stdDev, days)
+	if r != nil && r.Low < spent {
+		r.Low = spent
+	}
+	return r
+}
+
+// defaultRange is the insights page's default window: the last 12 months of data
+func defaultRange(data *models.TransactionSet) (start, end time.Time) {
+	minDate, maxDate := data.MinDate(), data.MaxDate()
+	start = maxDate.AddDate(0, -12, 0)
+	if start.Before(minDate) {
+		start = minDate
+	}
+	return start, maxDate
+}
+
+// insightsKey is the cache key for the full insights page
+func insightsKey(start, end time.Time, th models.TrendThresholds) string {
+	return cache.Key("insights", start, end, th.MinSpend, th.MinShare)
+}
+
+// Warm precomputes the default insights page and recurring payments so the
+// first visit after a restart is served from cache
+func Warm() error {
+	return precompute(insightCache.GetOrCompute)
+}
+
+// Refresh recomputes the default insights page and recurring payments for
+// the background refresh, keeping them cached until the next run
+func Refresh() error {
+	return precompute(insightCache.Refresh)
+}
+
+// precompute stores the default insights page and recurring payments with
+// store
+func precompute(store func(version, key string, compute func() interface{}) interface{}) error {
+	version, err := loader.DataVersion()
+	if err != nil {
+		return err
+	}
+	data, err := loader.LoadData()
+	if err != nil {
+		return err
+	}
+
+	startDate, endDate := defaultRange(data)
+	filtered := data.FilterByDateRange(startDate, endDate)
+	store(version, insightsKey(startDate, endDate, trendDefaults), func() interface{} {
+		return calculateInsights(data, filtered, startDate, endDate, trendDefaults)
+	})
+	store(version, recurringKey, func() interface{} {
+		return DetectRecurringPayments(data)
+	})
+	return nil
+}
+
+// recurringKey is the cache key for recurring payments across all data
+const recurringKey = "recurring"
+
+// RecurringPayments returns the recurring payments across all loaded data,
+// from cache when they've already been detected
+func RecurringPayments() ([]models.RecurringPayment, error) {
+	data, err := loader.LoadData()
+	if err != nil {
+		return nil, err
+	}
+	version, err := loader.DataVersion()
+	if err != nil {
+		return DetectRecurringPayments(data), nil
+	}
+	return insightCache.GetOrCompute(version, recurringKey, func() interface{} {
+		return DetectRecurringPayments(data)
+	}).([]models.RecurringPayment), nil
 }
 
 // HTTP Handlers
 
 func handleInsights(w http.ResponseWriter, r *http.Request) {
-	data, err := loader.LoadData()
+	view, err := viewCohort(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	cohortList, err := listCohorts()
+	if err != nil {
+		log.Printf("Warning: failed to load cohorts: %v", err)
+	}
+
+	data, err := loadData(r)
 	if err != nil {
 		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -504,35 +694,36 @@ func handleInsights(w http.ResponseWriter, r *http.Request) {
 	minDate := data.MinDate()
 	maxDate := data.MaxDate()
 
-	var startDate, endDate time.Time
+	startDate, endDate := defaultRange(data)
 	if startStr != "" {
 		startDate, _ = time.Parse("2006-01-02", startStr)
 	} else {
-		startDate = maxDate.AddDate(0, -12, 0)
-		if startDate.Before(minDate) {
-			startDate = minDate
-		}
 		preset = "12m"
 	}
 	if endStr != "" {
 		endDate, _ = time.Parse("2006-01-02", endStr)
-	} else {
-		endDate = maxDate
 	}
 
 	filtered := data.FilterByDateRange(startDate, endDate)
 
-	insights := calculateInsights(data, filtered, startDate, endDate)
+	thresholds := parseTrendThresholds(r)
+	insights := cachedInsight(r, insightsKey(startDate, endDate, thresholds), func() interface{} {
+		return calculateInsights(data, filtered, startDate, endDate, thresholds)
+	}).(*models.InsightsData)
 
 	pageData := map[string]interface{}{
-		"Title":     "Insights",
-		"ActiveTab": "insights",
-		"Insights":  insights,
-		"StartDate": startDate.Format("2006-01-02"),
-		"EndDate":   endDate.Format("2006-01-02"),
-		"MinDate":   minDate.Format("2006-01-02"),
-		"MaxDate":   maxDate.Format("2006-01-02"),
-		"Preset":    preset,
+		"Title":         "Insights",
+		"ActiveTab":     "insights",
+		"Insights":      insights,
+		"StartDate":     startDate.Format("2006-01-02"),
+		"EndDate":       endDate.Format("2006-01-02"),
+		"MinDate":       minDate.Format("2006-01-02"),
+		"MaxDate":       maxDate.Format("2006-01-02"),
+		"Preset":        preset,
+		"Sources":       apphttp.ParseSources(r.URL.Query()),
+		"View":          view,
+		"Cohorts":       cohortList,
+		"TrendDefaults": trendDefaults,
 	}
 
 	if renderer != nil {
@@ -543,14 +734,99 @@ func handleInsights(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// insightsExport is the JSON bundle served by /insights/export and
+// /api/v1/insights
+type insightsExport struct {
+	Start    string               `json:"start"`
+	End      string               `json:"end"`
+	Sources  []string             `json:"sources,omitempty"`
+	View     *models.Cohort       `json:"view,omitempty"`
+	Insights *models.InsightsData `json:"insights"`
+}
+
+// handleInsightsExport serves the insights page's analysis as JSON for the
+// same range, sources and trend thresholds the page accepts
+func handleInsightsExport(w http.ResponseWriter, r *http.Request) {
+	writeInsights(w, r, true)
+}
+
+// handleInsightsAPI serves the same analysis as the export, inline
+func handleInsightsAPI(w http.ResponseWriter, r *http.Request) {
+	writeInsights(w, r, false)
+}
+
+// writeInsights encodes the insights bundle for the request, as a file
+// download when attachment is set
+func writeInsights(w http.ResponseWriter, r *http.Request, attachment bool) {
+	view, err := viewCohort(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	startDate, endDate := defaultRange(data)
+	if startStr := r.URL.Query().Get("start"); startStr != "" {
+		if startDate, err = time.Parse("2006-01-02", startStr); err != nil {
+			http.Error(w, "Invalid start date", http.StatusBadRequest)
+			return
+		}
+	}
+	if endStr := r.URL.Query().Get("end"); endStr != "" {
+		if endDate, err = time.Parse("2006-01-02", endStr); err != nil {
+			http.Error(w, "Invalid end date", http.StatusBadRequest)
+			return
+		}
+	}
+
+	filtered := data.FilterByDateRange(startDate, endDate)
+
+	thresholds := parseTrendThresholds(r)
+	insights := cachedInsight(r, insightsKey(startDate, endDate, thresholds), func() interface{} {
+		return calculateInsights(data, filtered, startDate, endDate, thresholds)
+	}).(*models.InsightsData)
+
+	// Omit the transactions behind each recurring payment to keep the export
+	// readable, copying so the cached analysis keeps them
+	bundle := *insights
+	bundle.RecurringPayments = make([]models.RecurringPayment, len(insights.RecurringPayments))
+	copy(bundle.RecurringPayments, insights.RecurringPayments)
+	for i := range bundle.RecurringPayments {
+		bundle.RecurringPayments[i].Transactions = nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if attachment {
+		filename := fmt.Sprintf("insights_%s_to_%s.json", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+		if view != nil {
+			filename = fmt.Sprintf("insights_%s_%s_to_%s.json", view.ID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	}
+	json.NewEncoder(w).Encode(insightsExport{
+		Start:    startDate.Format("2006-01-02"),
+		End:      endDate.Format("2006-01-02"),
+		Sources:  apphttp.ParseSources(r.URL.Query()),
+		View:     view,
+		Insights: &bundle,
+	})
+}
+
 func handleRecurringPartial(w http.ResponseWriter, r *http.Request) {
-	data, err := loader.LoadData()
+	data, err := loadData(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	recurring := detectRecurringPayments(data)
+	recurring := cachedInsight(r, recurringKey, func() interface{} {
+		return DetectRecurringPayments(data)
+	}).([]models.RecurringPayment)
 
 	var totalRecurring float64
 	for _, r := range recurring {
@@ -571,8 +847,65 @@ func handleRecurringPartial(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func handleRecurringExport(w http.ResponseWriter, r *http.Request) {
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Same detection and cache entry as the panel, so the export lists
+	// exactly the payments shown there
+	recurring := cachedInsight(r, recurringKey, func() interface{} {
+		return DetectRecurringPayments(data)
+	}).([]models.RecurringPayment)
+
+	// Highest annual cost first so the biggest subscriptions top the checklist
+	rows := make([]models.RecurringPayment, len(recurring))
+	copy(rows, recurring)
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].AnnualCost > rows[j].AnnualCost
+	})
+
+	filename := fmt.Sprintf("recurring_%s_to_%s", data.MinDate().Format("2006-01-02"), data.MaxDate().Format("2006-01-02"))
+
+	if r.URL.Query().Get("format") == "json" {
+		// Omit the underlying transactions to keep the export readable
+		for i := range rows {
+			rows[i].Transactions = nil
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.json\"", filename))
+		json.NewEncoder(w).Encode(rows)
+		return
+	}
+
+	// Build CSV
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"Description", "Amount", "Frequency", "Annual Cost", "Last Date", "Next Expected", "Occurrences", "Confidence", "Accounts"})
+	for _, rp := range rows {
+		writer.Write([]string{
+			rp.Description,
+			fmt.Sprintf("%.2f", rp.Amount),
+			rp.Frequency,
+			fmt.Sprintf("%.2f", rp.AnnualCost),
+			rp.LastDate.Format("2006-01-02"),
+			rp.NextExpected.Format("2006-01-02"),
+			fmt.Sprintf("%d", rp.Occurrences),
+			fmt.Sprintf("%.2f", rp.Confidence),
+			strings.Join(rp.Accounts, "; "),
+		})
+	}
+	writer.Flush()
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.csv\"", filename))
+	w.Write(buf.Bytes())
+}
+
 func handleTrendsPartial(w http.ResponseWriter, r *http.Request) {
-	data, err := loader.LoadData()
+	data, err := loadData(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -591,10 +924,11 @@ func handleTrendsPartial(w http.ResponseWriter, r *http.Request) {
 		endDate = data.MaxDate()
 	}
 
-	trends := analyzeCategoryTrends(data, startDate, endDate)
+	result := cachedTrends(r, data, startDate, endDate, parseTrendThresholds(r))
 
 	partialData := map[string]interface{}{
-		"CategoryTrends": trends,
+		"CategoryTrends": result.Trends,
+		"TrendsSkipped":  result.Skipped,
 	}
 
 	if renderer != nil {
@@ -606,7 +940,7 @@ func handleTrendsPartial(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleTrendsChartData(w http.ResponseWriter, r *http.Request) {
-	data, err := loader.LoadData()
+	data, err := loadData(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -625,24 +959,20 @@ func handleTrendsChartData(w http.ResponseWriter, r *http.Request) {
 		endDate = data.MaxDate()
 	}
 
-	trends := analyzeCategoryTrends(data, startDate, endDate)
+	trends := cachedTrends(r, data, startDate, endDate, parseTrendThresholds(r)).Trends
 
-	var categories []string
+	var names []string
 	var currentValues []float64
 	var previousValues []float64
 	var colors []string
 
+	// Bars use each category's registry color; the previous period is the
+	// same color faded so pairs read together
 	for _, t := range trends {
-		categories = append(categories, t.Category)
+		names = append(names, t.Category)
 		currentValues = append(currentValues, t.CurrentAmount)
 		previousValues = append(previousValues, t.PreviousAmount)
-		if t.Direction == "up" {
-			colors = append(colors, "#ef4444")
-		} else if t.Direction == "down" {
-			colors = append(colors, "#22c55e")
-		} else {
-			colors = append(colors, "#6b7280")
-		}
+		colors = append(colors, categories.Color(t.Category))
 	}
 
 	chartData := map[string]interface{}{
@@ -650,16 +980,16 @@ func handleTrendsChartData(w http.ResponseWriter, r *http.Request) {
 			{
 				"type":   "bar",
 				"name":   "Current Period",
-				"x":      categories,
+				"x":      names,
 				"y":      currentValues,
 				"marker": map[string]interface{}{"color": colors},
 			},
 			{
 				"type":   "bar",
 				"name":   "Previous Period",
-				"x":      categories,
+				"x":      names,
 				"y":      previousValues,
-				"marker": map[string]string{"color": "#94a3b8"},
+				"marker": map[string]interface{}{"color": colors, "opacity": 0.4},
 			},
 		},
 		"layout": map[string]interface{}{
@@ -672,27 +1002,13 @@ func handleTrendsChartData(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleVelocityPartial(w http.ResponseWriter, r *http.Request) {
-	data, err := loader.LoadData()
+	data, err := loadData(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	startStr := r.URL.Query().Get("start")
-	endStr := r.URL.Query().Get("end")
-
-	startDate, _ := time.Parse("2006-01-02", startStr)
-	endDate, _ := time.Parse("2006-01-02", endStr)
-
-	if startDate.IsZero() {
-		startDate = data.MinDate()
-	}
-	if endDate.IsZero() {
-		endDate = data.MaxDate()
-	}
-
-	filtered := data.FilterByDateRange(startDate, endDate)
-	velocity := calculateSpendingVelocity(filtered, data)
+	velocity := velocityFor(r, data)
 
 	partialData := map[string]interface{}{
 		"Velocity": velocity,
@@ -707,13 +1023,15 @@ func handleVelocityPartial(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleIncomePartial(w http.ResponseWriter, r *http.Request) {
-	data, err := loader.LoadData()
+	data, err := loadData(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	income := AnalyzeIncomePatterns(data)
+	income := cachedInsight(r, "income", func() interface{} {
+		return AnalyzeIncomePatterns(data)
+	}).([]models.IncomePattern)
 
 	var regularTotal float64
 	for _, ip := range income {
@@ -734,3 +1052,220 @@ func handleIncomePartial(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(partialData)
 	}
 }
+
+func handleCancellationsPartial(w http.ResponseWriter, r *http.Request) {
+	list, err := tracker.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderCancellations(w, list)
+}
+
+func handleAddCancellation(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	description := strings.TrimSpace(r.FormValue("description"))
+	if description == "" {
+		http.Error(w, "Description is required", http.StatusBadRequest)
+		return
+	}
+
+	// Date comes from the form or from an hx-prompt answer; defaults to today
+	dateStr := r.FormValue("cancelled_on")
+	if dateStr == "" {
+		dateStr = strings.TrimSpace(r.Header.Get("HX-Prompt"))
+	}
+	cancelledOn := time.Now().Truncate(24 * time.Hour)
+	if dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			http.Error(w, "Cancellation date must be YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		cancelledOn = parsed
+	}
+
+	amount, _ := strconv.ParseFloat(r.FormValue("amount"), 64)
+	annualCost, _ := strconv.ParseFloat(r.FormValue("annual_cost"), 64)
+
+	list, err := tracker.Add(models.SubscriptionCancellation{
+		ID:          uuid.New().String(),
+		Description: description,
+		Amount:      amount,
+		Frequency:   r.FormValue("frequency"),
+		AnnualCost:  annualCost,
+		CancelledOn: cancelledOn,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		http.Error(w, "Failed to save cancellation: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	renderCancellations(w, list)
+}
+
+func handleDeleteCancellation(w http.ResponseWriter, r *http.Request) {
+	list, err := tracker.Remove(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Failed to remove cancellation: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderCancellations(w, list)
+}
+
+// renderCancellations verifies cancellations against current data and renders the panel
+func renderCancellations(w http.ResponseWriter, list []models.SubscriptionCancellation) {
+	data, err := loader.LoadData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	summary := subscriptions.Evaluate(list, data, time.Now())
+
+	partialData := map[string]interface{}{
+		"Cancellations": summary,
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "subscription-cancellations", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
+
+func handleBenchmarksPartial(w http.ResponseWriter, r *http.Request) {
+	list, err := baseline.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderBenchmarks(w, r, list)
+}
+
+func handleSetBenchmark(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	category := strings.TrimSpace(r.FormValue("category"))
+	if category == "" {
+		http.Error(w, "Category is required", http.StatusBadRequest)
+		return
+	}
+	percent, err := strconv.ParseFloat(r.FormValue("percent"), 64)
+	if err != nil {
+		http.Error(w, "Target must be a percentage", http.StatusBadRequest)
+		return
+	}
+
+	list, err := baseline.SetTarget(category, percent)
+	if err != nil {
+		http.Error(w, "Failed to save benchmark: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderBenchmarks(w, r, list)
+}
+
+func handleResetBenchmarks(w http.ResponseWriter, r *http.Request) {
+	list, err := baseline.Reset()
+	if err != nil {
+		http.Error(w, "Failed to reset benchmarks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderBenchmarks(w, r, list)
+}
+
+// renderBenchmarks compares spending in the requested date range to the
+// benchmarks and renders the comparison panel
+func renderBenchmarks(w http.ResponseWriter, r *http.Request, list []models.CategoryBenchmark) {
+	// Sources may arrive in the query or, on POST, in the form body
+	r.ParseForm()
+	sources := apphttp.ParseSources(r.Form)
+
+	data, err := loader.LoadSources(sources)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	startDate, _ := time.Parse("2006-01-02", r.FormValue("start"))
+	endDate, _ := time.Parse("2006-01-02", r.FormValue("end"))
+
+	if startDate.IsZero() {
+		startDate = data.MinDate()
+	}
+	if endDate.IsZero() {
+		endDate = data.MaxDate()
+	}
+
+	comparisons := benchmarks.Compare(list, data.FilterByDateRange(startDate, endDate))
+
+	// Scale bars to the largest share so small categories stay visible
+	var maxPercent float64
+	for _, c := range comparisons {
+		maxPercent = math.Max(maxPercent, math.Max(c.ActualPercent, c.TargetPercent))
+	}
+
+	partialData := map[string]interface{}{
+		"Benchmarks": comparisons,
+		"MaxPercent": maxPercent,
+		"Sources":    sources,
+		"StartDate":  startDate.Format("2006-01-02"),
+		"EndDate":    endDate.Format("2006-01-02"),
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "category-benchmarks", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
+
+// handleBurnDownChart charts the latest month's spending against the budget
+// pace and last month, projected to the month's end at the velocity's daily
+// average over the request's range. On the weekly cycle it charts the latest
+// week against its target and the week before.
+func handleBurnDownChart(w http.ResponseWriter, r *http.Request) {
+	data, err := loadData(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	velocity := velocityFor(r, data)
+	burnDown := analytics.BurnDown(data, monthlyBudget, velocity.DailyAverage)
+	if budgetCycle.Weekly() {
+		burnDown = analytics.WeeklyBurnDown(data, budgetCycle, velocity.DailyAverage)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analytics.BurnDownChart(burnDown))
+}
+
+// velocityFor returns the spending velocity over the request's start and
+// end dates, all of data by default
+func velocityFor(r *http.Request, data *models.TransactionSet) *models.SpendingVelocity {
+	startDate, _ := time.Parse("2006-01-02", r.URL.Query().Get("start"))
+	endDate, _ := time.Parse("2006-01-02", r.URL.Query().Get("end"))
+
+	if startDate.IsZero() {
+		startDate = data.MinDate()
+	}
+	if endDate.IsZero() {
+		endDate = data.MaxDate()
+	}
+
+	return cachedInsight(r, cache.Key("velocity", startDate, endDate), func() interface{} {
+		filtered := data.FilterByDateRange(startDate, endDate)
+		return calculateSpendingVelocity(filtered, data, budgetCycle)
+	}).(*models.SpendingVelocity)
+}
@@ -0,0 +1,34 @@
+package insights
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+)
+
+func TestDetectRecurringAcrossAccounts(t *testing.T) {
+	// A subscription that moves between cards, each export wording it its own way
+	var txns []models.Transaction
+	for month := 1; month <= 6; month++ {
+		date := time.Date(2025, time.Month(month), 15, 0, 0, 0, 0, time.UTC)
+		t := models.Transaction{Date: date, Description: "NETFLIX.COM", Amount: -15.49, Account: "Visa", TransactionType: models.Outflow}
+		if month%2 == 0 {
+			t.Description, t.Account = "Netflix.com 866-579-7172 CA", "Amex"
+		}
+		txns = append(txns, t)
+	}
+
+	recurring := DetectRecurringPayments(models.NewTransactionSet(txns))
+	if len(recurring) != 1 {
+		t.Fatalf("recurring = %+v, want one monthly payment", recurring)
+	}
+	r := recurring[0]
+	if r.Merchant != "netflix" || r.Frequency != "monthly" || r.Occurrences != 6 {
+		t.Errorf("payment = %+v, want six monthly netflix charges", r)
+	}
+	if !slices.Equal(r.Accounts, []string{"Amex", "Visa"}) || r.Description != "netflix.com 866-579-7172 ca" {
+		t.Errorf("accounts = %v, description = %q; want Amex (latest) then Visa", r.Accounts, r.Description)
+	}
+}
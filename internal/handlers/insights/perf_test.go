@@ -0,0 +1,22 @@
+package insights
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/testutil"
+)
+
+// BenchmarkDetectRecurringPayments scans three years of data for subscriptions
+func BenchmarkDetectRecurringPayments(b *testing.B) {
+	ts := models.NewTransactionSet(testutil.SyntheticTransactions(3 * 365))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DetectRecurringPayments(ts)
+	}
+}
+
+func TestDetectRecurringPaymentsBudget(t *testing.T) {
+	testutil.CheckBudget(t, 30*time.Millisecond, BenchmarkDetectRecurringPayments)
+}
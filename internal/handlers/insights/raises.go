@@ -0,0 +1,70 @@
+package insights
+
+import (
+	"math"
+	"sort"
+
+	"budget2/internal/models"
+)
+
+const (
+	// raiseMinChange is the relative change in a paycheck that counts as a
+	// raise or cut rather than ordinary variation
+	raiseMinChange = 0.02
+	// raiseConfirm is how many payments in a row must hold the new amount,
+	// so a one-off bonus or short paycheck isn't taken for a step
+	raiseConfirm = 3
+)
+
+// incomeChanges finds the lasting steps in a regular payment's amount, given
+// its payments oldest first, and returns them with the amount since the
+// last step. The amount before a step is the median of the payments since
+// the one before, so small variations don't skew it.
+func incomeChanges(txns []models.Transaction) ([]models.IncomeChange, float64) {
+	var changes []models.IncomeChange
+	run := []float64{txns[0].Amount}
+	level := txns[0].Amount
+
+	for i := 1; i < len(txns); i++ {
+		amount := txns[i].Amount
+		if level <= 0 || math.Abs(amount-level)/level <= raiseMinChange {
+			run = append(run, amount)
+			level = medianOf(run)
+			continue
+		}
+		if i+raiseConfirm > len(txns) || !holds(txns[i:i+raiseConfirm], amount) {
+			continue
+		}
+
+		changes = append(changes, models.IncomeChange{
+			Date:    txns[i].Date,
+			From:    math.Round(level*100) / 100,
+			To:      amount,
+			Percent: math.Round((amount-level)/level*1000) / 10,
+		})
+		run = []float64{amount}
+		level = amount
+	}
+	return changes, math.Round(level*100) / 100
+}
+
+// holds reports whether every payment is within raiseMinChange of amount
+func holds(txns []models.Transaction, amount float64) bool {
+	for _, t := range txns {
+		if math.Abs(t.Amount-amount)/amount > raiseMinChange {
+			return false
+		}
+	}
+	return true
+}
+
+// medianOf returns the median of values without reordering them
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,53 @@
+package insights
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+)
+
+func TestIncomeChanges(t *testing.T) {
+	start := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	amounts := []float64{
+		2000, 2010, 1995, 2000, 2600, 2000, // a one-off bonus
+		2000, 2000, 2100, 2100, 2105, 2100, // a 5% raise
+		1500, 2100, 2100, // a short paycheck
+		1900, 1900, // too recent to tell
+	}
+	var txns []models.Transaction
+	for i, amount := range amounts {
+		txns = append(txns, models.Transaction{
+			Date: start.AddDate(0, 0, 14*i), Description: "ACME PAYROLL", Amount: amount, TransactionType: models.Income,
+		})
+	}
+
+	changes, current := incomeChanges(txns)
+	if len(changes) != 1 {
+		t.Fatalf("changes = %+v, want one raise", changes)
+	}
+	raise := changes[0]
+	if !raise.Date.Equal(start.AddDate(0, 0, 14*8)) || raise.From != 2000 || raise.To != 2100 || raise.Percent != 5 {
+		t.Errorf("raise = %+v, want 2000 to 2100 (+5%%) on the ninth payment", raise)
+	}
+	if current != 2100 {
+		t.Errorf("current amount = %.2f, want 2100", current)
+	}
+
+	patterns := AnalyzeIncomePatterns(models.NewTransactionSet(txns))
+	if len(patterns) != 1 || !patterns[0].IsRegular || len(patterns[0].Changes) != 1 || patterns[0].CurrentAmount != 2100 {
+		t.Errorf("patterns = %+v, want the raise on the regular paycheck", patterns)
+	}
+	if !patterns[0].FirstDate.Equal(start) || !patterns[0].LastDate.Equal(start.AddDate(0, 0, 14*16)) {
+		t.Errorf("dates = %s to %s", patterns[0].FirstDate, patterns[0].LastDate)
+	}
+
+	// A cut counts as well
+	for i := range txns[12:] {
+		txns[12+i].Amount = 1800
+	}
+	changes, current = incomeChanges(txns)
+	if len(changes) != 2 || changes[1].Percent != -14.3 || current != 1800 {
+		t.Errorf("changes = %+v current %.2f, want a 14.3%% cut to 1800", changes, current)
+	}
+}
@@ -0,0 +1,350 @@
+package insights
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/cache"
+)
+
+// SplurgeDays is how many days after payday count as "just paid"
+const SplurgeDays = 3
+
+// SplurgeThreshold is how much higher the daily average right after payday
+// must be than the rest of the cycle to call it a post-payday splurge
+const SplurgeThreshold = 1.25
+
+// weekdayOrder lists days Monday first, the way people think about a week
+var weekdayOrder = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+}
+
+// civilDay strips the time of day so dates compare as calendar days
+func civilDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// discretionaryOutflows drops detected recurring payments from ts's outflows.
+// Rent and subscriptions land on fixed dates, so leaving them in would make
+// every payday look like a splurge.
+func discretionaryOutflows(ts *models.TransactionSet) *models.TransactionSet {
+	recurring := make(map[string]bool)
+	for _, r := range DetectRecurringPayments(ts) {
+		recurring[r.Merchant] = true
+	}
+
+	result := &models.TransactionSet{}
+	for _, t := range ts.FilterByType(models.Outflow).Transactions {
+		if !recurring[models.MerchantKey(t.Description)] {
+			result.Transactions = append(result.Transactions, t)
+		}
+	}
+	return result
+}
+
+// dailySpending totals outflows per calendar day
+func dailySpending(outflows *models.TransactionSet) (totals map[string]float64, counts map[string]int) {
+	totals = make(map[string]float64)
+	counts = make(map[string]int)
+	for _, t := range outflows.Transactions {
+		key := t.Date.Format("2006-01-02")
+		totals[key] += math.Abs(t.Amount)
+		counts[key]++
+	}
+	return totals, counts
+}
+
+// daySpendingAcc tallies days, spending and transactions for a group of days
+type daySpendingAcc struct {
+	days  int
+	total float64
+	count int
+}
+
+func (acc *daySpendingAcc) add(other daySpendingAcc) {
+	acc.days += other.days
+	acc.total += other.total
+	acc.count += other.count
+}
+
+// finish computes the daily average once days and totals are tallied
+func (acc *daySpendingAcc) finish(label string) models.DaySpending {
+	s := models.DaySpending{Label: label, Days: acc.days, Total: acc.total, TransactionCount: acc.count}
+	if acc.days > 0 {
+		s.DailyAverage = acc.total / float64(acc.days)
+	}
+	return s
+}
+
+// analyzeWeekpart splits discretionary spending in [start, end] by day of week
+func analyzeWeekpart(outflows *models.TransactionSet, start, end time.Time) *models.WeekpartSpending {
+	totals, counts := dailySpending(outflows)
+
+	byDay := make(map[time.Weekday]*daySpendingAcc)
+	for _, wd := range weekdayOrder {
+		byDay[wd] = &daySpendingAcc{}
+	}
+	for d := civilDay(start); !d.After(civilDay(end)); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		acc := byDay[d.Weekday()]
+		acc.days++
+		acc.total += totals[key]
+		acc.count += counts[key]
+	}
+
+	result := &models.WeekpartSpending{ByDay: []models.DaySpending{}}
+	var weekday, weekend daySpendingAcc
+	for _, wd := range weekdayOrder {
+		acc := byDay[wd]
+		result.ByDay = append(result.ByDay, acc.finish(wd.String()[:3]))
+		if wd == time.Saturday || wd == time.Sunday {
+			weekend.add(*acc)
+		} else {
+			weekday.add(*acc)
+		}
+	}
+	result.Weekday = weekday.finish("Weekdays")
+	result.Weekend = weekend.finish("Weekends")
+	if result.Weekday.DailyAverage > 0 {
+		result.WeekendPremium = (result.Weekend.DailyAverage - result.Weekday.DailyAverage) / result.Weekday.DailyAverage * 100
+	}
+	return result
+}
+
+// analyzePayCycle places each day after the first detected payday in its pay
+// cycle and averages discretionary spending by days since payday. The
+// paycheck is the largest regular income source in ts.
+func analyzePayCycle(ts, outflows *models.TransactionSet, end time.Time) *models.PayCycleSpending {
+	var paycheck *models.IncomePattern
+	for _, p := range AnalyzeIncomePatterns(ts) {
+		if p.IsRegular {
+			p := p
+			paycheck = &p
+			break
+		}
+	}
+	if paycheck == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var paydays []time.Time
+	for _, t := range ts.FilterByType(models.Income).Transactions {
+		if strings.ToLower(strings.TrimSpace(t.Description)) != paycheck.Description {
+			continue
+		}
+		key := t.Date.Format("2006-01-02")
+		if !seen[key] {
+			seen[key] = true
+			paydays = append(paydays, civilDay(t.Date))
+		}
+	}
+	if len(paydays) < 2 {
+		return nil
+	}
+	sort.Slice(paydays, func(i, j int) bool {
+		return paydays[i].Before(paydays[j])
+	})
+
+	var intervals []int
+	for i := 1; i < len(paydays); i++ {
+		intervals = append(intervals, int(paydays[i].Sub(paydays[i-1]).Hours()/24+0.5))
+	}
+	sort.Ints(intervals)
+	cycleDays := intervals[len(intervals)/2]
+	if cycleDays <= SplurgeDays {
+		return nil
+	}
+
+	totals, counts := dailySpending(outflows)
+	byDay := make([]daySpendingAcc, cycleDays)
+	next := 0
+	for d := paydays[0]; !d.After(civilDay(end)); d = d.AddDate(0, 0, 1) {
+		for next+1 < len(paydays) && !paydays[next+1].After(d) {
+			next++
+		}
+		since := int(d.Sub(paydays[next]).Hours()/24 + 0.5)
+		// A late paycheck stretches the cycle; fold the extra days into its end
+		if since >= cycleDays {
+			since = cycleDays - 1
+		}
+		key := d.Format("2006-01-02")
+		byDay[since].days++
+		byDay[since].total += totals[key]
+		byDay[since].count += counts[key]
+	}
+
+	result := &models.PayCycleSpending{
+		Paycheck:  paycheck.Description,
+		Frequency: paycheck.Frequency,
+		CycleDays: cycleDays,
+		Paydays:   len(paydays),
+		ByDay:     []models.DaySpending{},
+	}
+	for i, acc := range byDay {
+		label := fmt.Sprintf("Day %d", i)
+		if i == 0 {
+			label = "Payday"
+		}
+		result.ByDay = append(result.ByDay, acc.finish(label))
+	}
+
+	// Early is the splurge window; the rest of the cycle splits in two
+	mid := SplurgeDays + (cycleDays-SplurgeDays)/2
+	bounds := []struct{ from, to int }{{0, SplurgeDays}, {SplurgeDays, mid}, {mid, cycleDays}}
+	for _, b := range bounds {
+		var acc daySpendingAcc
+		for i := b.from; i < b.to; i++ {
+			acc.add(byDay[i])
+		}
+		result.Buckets = append(result.Buckets, acc.finish(fmt.Sprintf("Days %d–%d", b.from, b.to-1)))
+	}
+
+	var rest daySpendingAcc
+	for i := SplurgeDays; i < cycleDays; i++ {
+		rest.add(byDay[i])
+	}
+	restAvg := rest.finish("").DailyAverage
+	if restAvg > 0 {
+		result.SplurgeRatio = result.Buckets[0].DailyAverage / restAvg
+		result.HasSplurge = result.SplurgeRatio >= SplurgeThreshold
+	}
+	return result
+}
+
+// analyzeSpendingRhythm looks at discretionary spending in filtered by day of
+// week and by position in the pay cycle
+func analyzeSpendingRhythm(filtered *models.TransactionSet, start, end time.Time) *models.SpendingRhythm {
+	outflows := discretionaryOutflows(filtered)
+	return &models.SpendingRhythm{
+		Weekpart: analyzeWeekpart(outflows, start, end),
+		PayCycle: analyzePayCycle(filtered, outflows, end),
+	}
+}
+
+// rhythmForRequest returns the cached spending rhythm for the request's range,
+// defaulting to the insights page's last 12 months
+func rhythmForRequest(r *http.Request) (*models.SpendingRhythm, error) {
+	data, err := loadData(r)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, endDate := defaultRange(data)
+	if s, err := time.Parse("2006-01-02", r.URL.Query().Get("start")); err == nil {
+		startDate = s
+	}
+	if e, err := time.Parse("2006-01-02", r.URL.Query().Get("end")); err == nil {
+		endDate = e
+	}
+
+	return cachedInsight(r, cache.Key("rhythm", startDate, endDate), func() interface{} {
+		return analyzeSpendingRhythm(data.FilterByDateRange(startDate, endDate), startDate, endDate)
+	}).(*models.SpendingRhythm), nil
+}
+
+func handleRhythmPartial(w http.ResponseWriter, r *http.Request) {
+	rhythm, err := rhythmForRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	partialData := map[string]interface{}{
+		"Rhythm": rhythm,
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "spending-rhythm", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
+
+func handleRhythmWeekdayChart(w http.ResponseWriter, r *http.Request) {
+	rhythm, err := rhythmForRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var labels []string
+	var values []float64
+	var colors []string
+	for _, d := range rhythm.Weekpart.ByDay {
+		labels = append(labels, d.Label)
+		values = append(values, d.DailyAverage)
+		if d.Label == "Sat" || d.Label == "Sun" {
+			colors = append(colors, "#f59e0b")
+		} else {
+			colors = append(colors, "#6366f1")
+		}
+	}
+
+	chartData := map[string]interface{}{
+		"data": []map[string]interface{}{
+			{
+				"type":   "bar",
+				"name":   "Avg per day",
+				"x":      labels,
+				"y":      values,
+				"marker": map[string]interface{}{"color": colors},
+			},
+		},
+		"layout": map[string]interface{}{
+			"yaxis": map[string]interface{}{"title": "Avg spending per day", "tickprefix": "$"},
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chartData)
+}
+
+func handleRhythmPaydayChart(w http.ResponseWriter, r *http.Request) {
+	rhythm, err := rhythmForRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	traces := []map[string]interface{}{}
+	if rhythm.PayCycle != nil {
+		var labels []string
+		var values []float64
+		var colors []string
+		for i, d := range rhythm.PayCycle.ByDay {
+			labels = append(labels, d.Label)
+			values = append(values, d.DailyAverage)
+			if i < SplurgeDays {
+				colors = append(colors, "#ef4444")
+			} else {
+				colors = append(colors, "#6366f1")
+			}
+		}
+		traces = append(traces, map[string]interface{}{
+			"type":   "bar",
+			"name":   "Avg per day",
+			"x":      labels,
+			"y":      values,
+			"marker": map[string]interface{}{"color": colors},
+		})
+	}
+
+	chartData := map[string]interface{}{
+		"data": traces,
+		"layout": map[string]interface{}{
+			"xaxis": map[string]interface{}{"title": "Days since payday"},
+			"yaxis": map[string]interface{}{"title": "Avg spending per day", "tickprefix": "$"},
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chartData)
+}
@@ -0,0 +1,75 @@
+package insights
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+)
+
+// rhythmData builds eight weeks of Friday paychecks with heavy spending on
+// payday and the day after, and light spending otherwise
+func rhythmData() (*models.TransactionSet, time.Time, time.Time) {
+	start := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC) // a Friday
+	end := start.AddDate(0, 0, 8*7-1)
+
+	var txns []models.Transaction
+	for i := 0; start.AddDate(0, 0, i).Before(end.AddDate(0, 0, 1)); i++ {
+		date := start.AddDate(0, 0, i)
+		if date.Weekday() == time.Friday {
+			txns = append(txns, models.Transaction{Date: date, Description: "ACME PAYROLL", Amount: 1000, TransactionType: models.Income})
+		}
+		amount := -10.0
+		if date.Weekday() == time.Friday || date.Weekday() == time.Saturday {
+			amount = -100
+		}
+		txns = append(txns, models.Transaction{Date: date, Description: fmt.Sprintf("SHOP %d", i), Amount: amount, TransactionType: models.Outflow})
+	}
+	return models.NewTransactionSet(txns), start, end
+}
+
+func TestAnalyzeSpendingRhythm(t *testing.T) {
+	data, start, end := rhythmData()
+	rhythm := analyzeSpendingRhythm(data, start, end)
+
+	week := rhythm.Weekpart
+	if len(week.ByDay) != 7 || week.ByDay[0].Label != "Mon" || week.ByDay[5].Label != "Sat" {
+		t.Fatalf("by-day should run Mon..Sun, got %+v", week.ByDay)
+	}
+	if week.Weekend.Days != 16 || week.Weekday.Days != 40 {
+		t.Errorf("weekend/weekday days = %d/%d, want 16/40", week.Weekend.Days, week.Weekday.Days)
+	}
+	// Weekends: Saturday 100 + Sunday 10; weekdays: Friday 100 + four days of 10
+	if week.Weekend.DailyAverage != 55 || week.Weekday.DailyAverage != 28 {
+		t.Errorf("daily averages = %.2f weekend, %.2f weekday, want 55 and 28", week.Weekend.DailyAverage, week.Weekday.DailyAverage)
+	}
+
+	cycle := rhythm.PayCycle
+	if cycle == nil {
+		t.Fatal("expected a pay cycle from weekly paychecks")
+	}
+	if cycle.CycleDays != 7 || cycle.Paydays != 8 || cycle.Frequency != "weekly" {
+		t.Errorf("cycle = %d days over %d paydays (%s), want 7 over 8 weekly", cycle.CycleDays, cycle.Paydays, cycle.Frequency)
+	}
+	if cycle.ByDay[0].DailyAverage != 100 || cycle.ByDay[1].DailyAverage != 100 || cycle.ByDay[4].DailyAverage != 10 {
+		t.Errorf("by-day averages = %+v", cycle.ByDay)
+	}
+	if !cycle.HasSplurge || cycle.SplurgeRatio < 5 {
+		t.Errorf("expected a post-payday splurge, ratio %.2f", cycle.SplurgeRatio)
+	}
+	if len(cycle.Buckets) != 3 || cycle.Buckets[0].Label != "Days 0–2" {
+		t.Errorf("buckets = %+v", cycle.Buckets)
+	}
+}
+
+func TestPayCycleNeedsRegularIncome(t *testing.T) {
+	d := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	data := models.NewTransactionSet([]models.Transaction{
+		{Date: d, Description: "GIFT", Amount: 200, TransactionType: models.Income},
+		{Date: d.AddDate(0, 0, 3), Description: "SHOP", Amount: -20, TransactionType: models.Outflow},
+	})
+	if cycle := analyzeSpendingRhythm(data, d, d.AddDate(0, 0, 10)).PayCycle; cycle != nil {
+		t.Errorf("one-off income should not produce a pay cycle, got %+v", cycle)
+	}
+}
@@ -0,0 +1,107 @@
+package insights
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/models"
+	"budget2/internal/services/statements"
+)
+
+func handleStatementsPartial(w http.ResponseWriter, r *http.Request) {
+	list, err := cycles.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderStatements(w, list)
+}
+
+func handleSetStatementCycle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	closingDay, err := strconv.Atoi(r.FormValue("closing_day"))
+	if err != nil {
+		http.Error(w, "Closing day must be a number", http.StatusBadRequest)
+		return
+	}
+
+	list, err := cycles.Set(models.StatementCycle{
+		Source:     r.FormValue("source"),
+		Name:       r.FormValue("name"),
+		ClosingDay: closingDay,
+	})
+	if err != nil {
+		http.Error(w, "Failed to save statement cycle: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderStatements(w, list)
+}
+
+func handleRemoveStatementCycle(w http.ResponseWriter, r *http.Request) {
+	source, err := url.PathUnescape(chi.URLParam(r, "source"))
+	if err != nil {
+		http.Error(w, "Invalid source encoding", http.StatusBadRequest)
+		return
+	}
+
+	list, err := cycles.Remove(source)
+	if err != nil {
+		http.Error(w, "Failed to remove statement cycle: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderStatements(w, list)
+}
+
+// renderStatements groups each configured card's transactions into statement
+// periods and renders the panel. Cards use all files, not the enabled set, so
+// turning a file off doesn't hide its statements.
+func renderStatements(w http.ResponseWriter, list []models.StatementCycle) {
+	data, err := loader.LoadData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	files, err := loader.GetFileInfo()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	cards := make([]models.CardStatements, 0, len(list))
+	if len(list) > 0 {
+		sources := make([]string, 0, len(list))
+		for _, c := range list {
+			sources = append(sources, c.Source)
+		}
+		cardData, err := loader.LoadSources(sources)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Periods run up to the newest data overall, so a card that hasn't
+		// been exported lately still shows its current statement
+		for _, c := range list {
+			cards = append(cards, statements.Build(c, cardData, data.MaxDate()))
+		}
+	}
+
+	partialData := map[string]interface{}{
+		"Cards": cards,
+		"Files": files,
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "card-statements", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
@@ -0,0 +1,207 @@
+package insights
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/analytics"
+	"budget2/internal/services/cache"
+)
+
+// trendDefaults are the thresholds used when a request doesn't set its own
+var trendDefaults = models.TrendThresholds{MinSpend: 50, MinShare: 1}
+
+// maxTrends is how many category trends are reported
+const maxTrends = 10
+
+// trendResult is a cached category trend analysis
+type trendResult struct {
+	Trends  []models.CategoryTrend
+	Skipped int
+}
+
+// parseTrendThresholds reads min_spend and min_share from the request,
+// falling back to the configured defaults for missing or negative values
+func parseTrendThresholds(r *http.Request) models.TrendThresholds {
+	th := trendDefaults
+	if v, err := strconv.ParseFloat(r.URL.Query().Get("min_spend"), 64); err == nil && v >= 0 {
+		th.MinSpend = v
+	}
+	if v, err := strconv.ParseFloat(r.URL.Query().Get("min_share"), 64); err == nil && v >= 0 && v <= 100 {
+		th.MinShare = v
+	}
+	return th
+}
+
+// cachedTrends returns the request's category trends for the period
+func cachedTrends(r *http.Request, ts *models.TransactionSet, start, end time.Time, th models.TrendThresholds) trendResult {
+	key := cache.Key("trends", start, end, th.MinSpend, th.MinShare)
+	return cachedInsight(r, key, func() interface{} {
+		trends, skipped := analyzeCategoryTrends(ts, start, end, th)
+		return trendResult{Trends: trends, Skipped: skipped}
+	}).(trendResult)
+}
+
+// analyzeCategoryTrends compares category spending in the period against the
+// equal-length period before it. Categories below the thresholds in both
+// periods are skipped and counted, and the rest are ranked by impact, the
+// change as a share of total spending, so a $12 category tripling never
+// outranks a modest move in rent or groceries.
+func analyzeCategoryTrends(ts *models.TransactionSet, currentStart, currentEnd time.Time, th models.TrendThresholds) ([]models.CategoryTrend, int) {
+	var trends []models.CategoryTrend
+
+	duration := currentEnd.Sub(currentStart)
+	prevStart := currentStart.Add(-duration - 24*time.Hour)
+	prevEnd := currentStart.Add(-24 * time.Hour)
+
+	currentFiltered := ts.FilterByDateRange(currentStart, currentEnd)
+	prevFiltered := ts.FilterByDateRange(prevStart, prevEnd)
+
+	currentOutflows := currentFiltered.FilterByType(models.Outflow)
+	prevOutflows := prevFiltered.FilterByType(models.Outflow)
+
+	currentTotals := currentOutflows.CategoryTotals()
+	prevTotals := prevOutflows.CategoryTotals()
+
+	var currentSpend, prevSpend float64
+	catSet := make(map[string]bool)
+	for cat, amount := range currentTotals {
+		catSet[cat] = true
+		currentSpend += amount
+	}
+	for cat, amount := range prevTotals {
+		catSet[cat] = true
+		prevSpend += amount
+	}
+
+	share := func(amount, total float64) float64 {
+		if total <= 0 {
+			return 0
+		}
+		return amount / total * 100
+	}
+	// Impact is measured against the larger period so a shrinking budget
+	// doesn't inflate it
+	base := math.Max(currentSpend, prevSpend)
+
+	skipped := 0
+	for cat := range catSet {
+		current := currentTotals[cat]
+		previous := prevTotals[cat]
+
+		if math.Max(current, previous) < th.MinSpend ||
+			math.Max(share(current, currentSpend), share(previous, prevSpend)) < th.MinShare {
+			skipped++
+			continue
+		}
+
+		var changePercent float64
+		var direction string
+
+		if previous == 0 {
+			if current == 0 {
+				changePercent = 0
+				direction = "stable"
+			} else {
+				changePercent = 100
+				direction = "up"
+			}
+		} else {
+			changePercent = ((current - previous) / previous) * 100
+			if changePercent > 5 {
+				direction = "up"
+			} else if changePercent < -5 {
+				direction = "down"
+			} else {
+				direction = "stable"
+			}
+		}
+
+		trends = append(trends, models.CategoryTrend{
+			Category:       cat,
+			CurrentAmount:  current,
+			PreviousAmount: previous,
+			ChangePercent:  changePercent,
+			ChangeAmount:   current - previous,
+			Direction:      direction,
+			Share:          share(current, currentSpend),
+			Impact:         share(math.Abs(current-previous), base),
+		})
+	}
+
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Impact != trends[j].Impact {
+			return trends[i].Impact > trends[j].Impact
+		}
+		return trends[i].Category < trends[j].Category
+	})
+
+	if len(trends) > maxTrends {
+		trends = trends[:maxTrends]
+	}
+
+	history := monthlyCategoryHistory(ts, currentEnd)
+	for i := range trends {
+		trends[i].Projection, trends[i].ProjectionRange = analytics.MonthlyProjection(history[trends[i].Category])
+	}
+
+	return trends, skipped
+}
+
+// projectionMonths is how many months of history a category's projection
+// averages
+const projectionMonths = 12
+
+// monthlyCategoryHistory returns each category's spending in the complete
+// months up to end, at most projectionMonths of them, oldest first. Months
+// the data only partly covers are left out so they don't drag the average
+// down; categories without spending in a month get zero for it.
+func monthlyCategoryHistory(ts *models.TransactionSet, end time.Time) map[string][]float64 {
+	first, last := ts.MinDate(), ts.MaxDate()
+	if first.IsZero() {
+		return nil
+	}
+	if end.Before(last) {
+		last = end
+	}
+	firstMonth := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if first.Day() != 1 {
+		firstMonth = firstMonth.AddDate(0, 1, 0)
+	}
+	// The month after the last complete one
+	endMonth := time.Date(last.Year(), last.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if last.AddDate(0, 0, 1).Month() != last.Month() {
+		endMonth = endMonth.AddDate(0, 1, 0)
+	}
+	if start := endMonth.AddDate(0, -projectionMonths, 0); firstMonth.Before(start) {
+		firstMonth = start
+	}
+
+	var months []string
+	for m := firstMonth; m.Before(endMonth); m = m.AddDate(0, 1, 0) {
+		months = append(months, m.Format("2006-01"))
+	}
+	if len(months) == 0 {
+		return nil
+	}
+
+	byMonth := ts.FilterByDateRange(firstMonth, endMonth.AddDate(0, 0, -1)).FilterByType(models.Outflow).GroupByMonth()
+	history := make(map[string][]float64)
+	for i, month := range months {
+		set, ok := byMonth[month]
+		if !ok {
+			continue
+		}
+		for cat, amount := range set.CategoryTotals() {
+			if history[cat] == nil {
+				history[cat] = make([]float64, len(months))
+			}
+			history[cat][i] = amount
+		}
+	}
+	return history
+}
@@ -0,0 +1,102 @@
+package insights
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+)
+
+func TestAnalyzeCategoryTrendsThresholds(t *testing.T) {
+	d := func(s string) time.Time {
+		date, _ := time.Parse("2006-01-02", s)
+		return date
+	}
+	out := func(date, category string, amount float64) models.Transaction {
+		return models.Transaction{Date: d(date), Description: category, Amount: -amount, Category: category, TransactionType: models.Outflow}
+	}
+	data := models.NewTransactionSet([]models.Transaction{
+		// Previous period: Dec 2024
+		out("2024-12-05", "Rent", 2000),
+		out("2024-12-10", "Groceries", 600),
+		out("2024-12-15", "Apps", 3),
+		out("2024-12-20", "Dining", 40),
+		// Current period: Jan 2025
+		out("2025-01-05", "Rent", 2000),
+		out("2025-01-10", "Groceries", 750),
+		out("2025-01-15", "Apps", 12),
+		out("2025-01-20", "Dining", 45),
+	})
+
+	trends, skipped := analyzeCategoryTrends(data, d("2025-01-01"), d("2025-01-31"), models.TrendThresholds{MinSpend: 50, MinShare: 1})
+	if skipped != 2 {
+		t.Errorf("skipped = %d, want Apps and Dining under $50", skipped)
+	}
+	if len(trends) != 2 || trends[0].Category != "Groceries" || trends[1].Category != "Rent" {
+		t.Fatalf("trends = %+v, want Groceries then Rent", trends)
+	}
+	// $150 out of $2,807 spent in January
+	if g := trends[0]; g.Impact < 5.3 || g.Impact > 5.4 || g.Direction != "up" {
+		t.Errorf("Groceries impact = %.2f%%, direction %s; want about 5.3%% up", g.Impact, g.Direction)
+	}
+
+	// With thresholds off the 300% swing on Apps is reported but still ranks
+	// below the groceries increase
+	trends, skipped = analyzeCategoryTrends(data, d("2025-01-01"), d("2025-01-31"), models.TrendThresholds{})
+	if skipped != 0 || len(trends) != 4 {
+		t.Fatalf("with no thresholds got %d trends, %d skipped", len(trends), skipped)
+	}
+	if trends[0].Category != "Groceries" || trends[1].Category != "Apps" || trends[1].ChangePercent != 300 {
+		t.Errorf("trends = %+v, want Groceries first and Apps at +300%% second", trends)
+	}
+
+	// A share threshold alone drops categories that are a sliver of spending
+	// however much was spent
+	_, skipped = analyzeCategoryTrends(data, d("2025-01-01"), d("2025-01-31"), models.TrendThresholds{MinShare: 30})
+	if skipped != 3 {
+		t.Errorf("skipped = %d, want everything but Rent under 30%% of spending", skipped)
+	}
+
+	// December starts on the 5th and January stops on the 20th, so neither
+	// month is complete and there's nothing to project from
+	if g := trends[0]; g.Projection != 0 || g.ProjectionRange != nil {
+		t.Errorf("Groceries projection = %.2f %+v, want none", g.Projection, g.ProjectionRange)
+	}
+}
+
+func TestCategoryTrendProjection(t *testing.T) {
+	d := func(s string) time.Time {
+		date, _ := time.Parse("2006-01-02", s)
+		return date
+	}
+	out := func(date, category string, amount float64) models.Transaction {
+		return models.Transaction{Date: d(date), Description: category, Amount: -amount, Category: category, TransactionType: models.Outflow}
+	}
+	data := models.NewTransactionSet([]models.Transaction{
+		out("2024-10-01", "Groceries", 400),
+		out("2024-11-10", "Groceries", 600),
+		out("2024-12-10", "Groceries", 500),
+		out("2025-01-10", "Groceries", 500),
+		out("2025-01-12", "Travel", 900),
+		// A partial February isn't averaged in
+		out("2025-02-03", "Groceries", 50),
+	})
+
+	trends, _ := analyzeCategoryTrends(data, d("2025-01-01"), d("2025-02-03"), models.TrendThresholds{})
+	byCategory := map[string]models.CategoryTrend{}
+	for _, trend := range trends {
+		byCategory[trend.Category] = trend
+	}
+
+	g := byCategory["Groceries"]
+	if g.Projection != 500 || g.ProjectionRange == nil || g.ProjectionRange.Level != 80 {
+		t.Fatalf("Groceries = %.2f %+v, want the $500 average of October to January", g.Projection, g.ProjectionRange)
+	}
+	if g.ProjectionRange.Low >= 500 || g.ProjectionRange.High <= 500 {
+		t.Errorf("Groceries range = %+v, want it around 500", g.ProjectionRange)
+	}
+	// Travel in one month of four averages $225, and can't go below zero
+	if tr := byCategory["Travel"]; tr.Projection != 225 || tr.ProjectionRange.Low != 0 {
+		t.Errorf("Travel = %.2f %+v, want 225 from zero up", tr.Projection, tr.ProjectionRange)
+	}
+}
@@ -0,0 +1,183 @@
+package networth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/models"
+	"budget2/internal/services/accounts"
+	"budget2/internal/services/dataloader"
+	"budget2/internal/services/networth"
+	"budget2/internal/templates"
+)
+
+var (
+	loader   *dataloader.DataLoader
+	renderer *templates.Renderer
+	manager  *networth.Manager
+	defined  *accounts.Manager
+)
+
+// Initialize sets up the net worth package with required dependencies
+func Initialize(l *dataloader.DataLoader, r *templates.Renderer, m *networth.Manager, a *accounts.Manager) {
+	loader = l
+	renderer = r
+	manager = m
+	defined = a
+}
+
+// RegisterRoutes registers the net worth routes
+func RegisterRoutes(r chi.Router) {
+	r.Get("/networth", handleNetWorthPage)
+	r.Get("/networth/chart", handleNetWorthChart)
+	r.Post("/networth/balances", handleBalanceRecord)
+	r.Delete("/networth/balances/{account}/{date}", handleBalanceRemove)
+	r.Get("/api/networth", handleNetWorthAPI)
+}
+
+func handleNetWorthPage(w http.ResponseWriter, r *http.Request) {
+	data, err := netWorthData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data["Title"] = "Net Worth"
+	data["ActiveTab"] = "networth"
+	renderer.Render(w, "base", data)
+}
+
+func handleNetWorthChart(w http.ResponseWriter, r *http.Request) {
+	balances, err := loadBalances()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(networth.Chart(balances))
+}
+
+// handleBalanceRecord records an account's balance on a date
+func handleBalanceRecord(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	balance, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(r.FormValue("balance")), ",", ""), 64)
+	if err != nil {
+		http.Error(w, "balance must be a number", http.StatusBadRequest)
+		return
+	}
+
+	snapshot := models.BalanceSnapshot{Account: r.FormValue("account"), Date: r.FormValue("date"), Balance: balance}
+	if _, err := manager.Record(snapshot); err != nil {
+		http.Error(w, "Failed to record balance: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderNetWorth(w)
+}
+
+func handleBalanceRemove(w http.ResponseWriter, r *http.Request) {
+	if _, err := manager.Remove(chi.URLParam(r, "account"), chi.URLParam(r, "date")); err != nil {
+		http.Error(w, "Failed to remove balance: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderNetWorth(w)
+}
+
+// handleNetWorthAPI returns net worth as of the date parameter (the newest
+// balance by default) as JSON
+func handleNetWorthAPI(w http.ResponseWriter, r *http.Request) {
+	balances, err := loadBalances()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	nw, _ := networth.Latest(balances)
+	if date := r.URL.Query().Get("date"); date != "" {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			http.Error(w, fmt.Sprintf("date must be YYYY-MM-DD, got %q", date), http.StatusBadRequest)
+			return
+		}
+		nw = networth.At(balances, date)
+	}
+	if nw.Accounts == nil {
+		nw.Accounts = []models.AccountBalance{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(nw)
+}
+
+// loadBalances returns every account's balances, recorded and from the enabled
+// files, oldest first
+func loadBalances() ([]models.AccountBalance, error) {
+	accountList, err := defined.Accounts()
+	if err != nil {
+		return nil, err
+	}
+	data, err := loader.LoadData()
+	if err != nil {
+		return nil, err
+	}
+	return manager.Balances(accountList, data)
+}
+
+// netWorthData gathers the latest net worth, the recorded balances and the
+// known account names and today's date for the form
+func netWorthData() (map[string]interface{}, error) {
+	balances, err := loadBalances()
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := manager.List()
+	if err != nil {
+		return nil, err
+	}
+	data, err := loader.LoadData()
+	if err != nil {
+		return nil, err
+	}
+
+	names := data.Accounts()
+	if accountList, err := defined.Accounts(); err == nil {
+		for _, a := range accountList {
+			if !slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, a.Name) }) {
+				names = append(names, a.Name)
+			}
+		}
+	}
+
+	nw, ok := networth.Latest(balances)
+	return map[string]interface{}{
+		"NetWorth":     nw,
+		"HasBalances":  ok,
+		"Snapshots":    snapshots,
+		"AccountNames": names,
+		"Today":        time.Now().Format("2006-01-02"),
+	}, nil
+}
+
+// renderNetWorth renders the net worth summary, or JSON without a renderer
+func renderNetWorth(w http.ResponseWriter) {
+	data, err := netWorthData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "net-worth-summary", data)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(data)
+	}
+}
@@ -0,0 +1,251 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"budget2/internal/models"
+	"budget2/internal/services/dataloader"
+	"budget2/internal/services/rules"
+	"budget2/internal/templates"
+)
+
+var (
+	loader   *dataloader.DataLoader
+	renderer *templates.Renderer
+	manager  *rules.Manager
+)
+
+// Initialize sets up the rules package with required dependencies
+func Initialize(l *dataloader.DataLoader, r *templates.Renderer, m *rules.Manager) {
+	loader = l
+	renderer = r
+	manager = m
+}
+
+// RegisterRoutes registers the category rule routes
+func RegisterRoutes(r chi.Router) {
+	r.Get("/rules", handleRulesPage)
+	r.Get("/rules/list", handleRulesPartial)
+	r.Post("/rules", handleRuleAdd)
+	r.Get("/rules/{id}/edit", handleRuleEdit)
+	r.Put("/rules/{id}", handleRuleUpdate)
+	r.Delete("/rules/{id}", handleRuleRemove)
+	r.Post("/rules/{id}/move", handleRuleMove)
+	r.Get("/rules/export", handleRulesExport)
+	r.Post("/rules/import", handleRulesImport)
+}
+
+func handleRulesPage(w http.ResponseWriter, r *http.Request) {
+	data, err := rulesData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data["Title"] = "Category Rules"
+	data["ActiveTab"] = "rules"
+	renderer.Render(w, "base", data)
+}
+
+func handleRulesPartial(w http.ResponseWriter, r *http.Request) {
+	renderRules(w)
+}
+
+func handleRuleAdd(w http.ResponseWriter, r *http.Request) {
+	rule, err := parseRule(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rule.ID = uuid.New().String()
+	rule.CreatedAt = time.Now()
+
+	if _, err := manager.Add(rule); err != nil {
+		http.Error(w, "Failed to add rule: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderRules(w)
+}
+
+// handleRuleEdit renders one rule as an inline edit form
+func handleRuleEdit(w http.ResponseWriter, r *http.Request) {
+	list, err := manager.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	for _, rule := range list {
+		if rule.ID == id {
+			renderer.RenderPartial(w, "category-rule-edit", rule)
+			return
+		}
+	}
+	http.Error(w, "Rule not found", http.StatusNotFound)
+}
+
+func handleRuleUpdate(w http.ResponseWriter, r *http.Request) {
+	rule, err := parseRule(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rule.ID = chi.URLParam(r, "id")
+
+	if _, err := manager.Update(rule); err != nil {
+		http.Error(w, "Failed to update rule: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderRules(w)
+}
+
+func handleRuleRemove(w http.ResponseWriter, r *http.Request) {
+	if _, err := manager.Remove(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, "Failed to remove rule: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderRules(w)
+}
+
+func handleRuleMove(w http.ResponseWriter, r *http.Request) {
+	if _, err := manager.Move(chi.URLParam(r, "id"), r.URL.Query().Get("dir") == "up"); err != nil {
+		http.Error(w, "Failed to move rule: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderRules(w)
+}
+
+// handleRulesExport downloads the rules as CSV, or as JSON with format=json,
+// for importing into another install
+func handleRulesExport(w http.ResponseWriter, r *http.Request) {
+	list, err := manager.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", "attachment; filename=\"category_rules.json\"")
+		json.NewEncoder(w).Encode(list)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := rules.WriteCSV(&buf, list); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"category_rules.csv\"")
+	w.Write(buf.Bytes())
+}
+
+// handleRulesImport adds the rules from an exported JSON or CSV file after
+// the existing ones, or replaces them all when replace is checked. Nothing
+// is imported if any rule in the file is invalid.
+func handleRulesImport(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		http.Error(w, "File too large", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Error reading file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	parsed, err := rules.Parse(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	for i := range parsed {
+		parsed[i].ID = uuid.New().String()
+		parsed[i].CreatedAt = now
+	}
+
+	added, err := manager.Import(parsed, r.FormValue("replace") == "on")
+	if err != nil {
+		http.Error(w, "Failed to import rules: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Imported %d of %d category rules from %s", added, len(parsed), header.Filename)
+	renderRules(w)
+}
+
+// parseRule reads a rule's conditions and category from a form. Blank
+// amounts mean no bound.
+func parseRule(r *http.Request) (models.CategoryRule, error) {
+	if err := r.ParseForm(); err != nil {
+		return models.CategoryRule{}, err
+	}
+
+	rule := models.CategoryRule{
+		Match:    r.FormValue("match"),
+		Pattern:  r.FormValue("pattern"),
+		Category: r.FormValue("category"),
+	}
+	for _, field := range []struct {
+		name  string
+		value *float64
+	}{{"min_amount", &rule.MinAmount}, {"max_amount", &rule.MaxAmount}} {
+		s := strings.TrimSpace(r.FormValue(field.name))
+		if s == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return rule, fmt.Errorf("%s must be a number", field.name)
+		}
+		*field.value = v
+	}
+	return rule, nil
+}
+
+// rulesData gathers the rules with their match counts against the loaded
+// data, plus the known categories for the form's suggestions
+func rulesData() (map[string]interface{}, error) {
+	list, err := manager.List()
+	if err != nil {
+		return nil, err
+	}
+	data, err := loader.LoadData()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"Rules":      rules.Evaluate(list, data),
+		"NewRule":    models.CategoryRule{Match: models.RuleContains},
+		"Categories": data.Categories(),
+	}, nil
+}
+
+// renderRules renders the rules list, or JSON without a renderer
+func renderRules(w http.ResponseWriter) {
+	data, err := rulesData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "category-rules", data)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(data)
+	}
+}
@@ -0,0 +1,283 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"budget2/internal/handlers/insights"
+	"budget2/internal/models"
+	"budget2/internal/services/analytics"
+	"budget2/internal/services/watchlist"
+)
+
+const (
+	// upcomingDays is how far ahead the briefing looks for bills
+	upcomingDays = 7
+
+	// spokenItems caps how many bills or alerts are read aloud
+	spokenItems = 3
+
+	// typicalMonths is how many prior months make up typical spending
+	typicalMonths = 12
+)
+
+// handleBriefing serves a short natural-language summary of the month for
+// voice assistant skills: spending against a typical month, bills due in
+// the next week, and alerts. It takes the same token as /api/status.
+// ?format=text returns only the speech as plain text.
+func handleBriefing(w http.ResponseWriter, r *http.Request) {
+	if token == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if !authorized(r) {
+		http.Error(w, "Invalid status token", http.StatusUnauthorized)
+		return
+	}
+
+	data, err := loader.LoadData()
+	if err != nil {
+		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	recurring, err := insights.RecurringPayments()
+	if err != nil {
+		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	briefing := buildBriefing(data, recurring, currentAlerts(data), monthlyBudget, time.Now())
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.URL.Query().Get("format") == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, briefing.Speech)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(briefing)
+}
+
+// currentAlerts gathers the latest month's unusual spending alerts and any
+// watched merchants near or over their limit, as the dashboard shows them
+func currentAlerts(data *models.TransactionSet) []models.SpendingAlert {
+	var alerts []models.SpendingAlert
+	if end := data.MaxDate(); !end.IsZero() {
+		start := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, end.Location())
+		alerts = analytics.DetectAlerts(data.FilterByDateRange(start, end))
+	}
+	if watched != nil {
+		if list, err := watched.List(); err == nil {
+			alerts = append(alerts, watchlist.Alerts(watchlist.Evaluate(list, data))...)
+		}
+	}
+	return alerts
+}
+
+// buildBriefing summarizes data as of its latest transaction, with bills
+// due in the week after now
+func buildBriefing(data *models.TransactionSet, recurring []models.RecurringPayment, alerts []models.SpendingAlert, budget float64, now time.Time) *models.Briefing {
+	status := analytics.CalculateMonthStatus(data, budget)
+	briefing := &models.Briefing{
+		Status:   status,
+		Upcoming: upcomingBills(recurring, now),
+		Alerts:   alertTitles(alerts),
+	}
+	if status.Month == "" {
+		briefing.Speech = "There are no transactions to summarize yet."
+		return briefing
+	}
+
+	asOf := data.MaxDate()
+	briefing.TypicalSpent = typicalSpent(data, asOf)
+
+	var speech []string
+	spent := fmt.Sprintf("So far in %s you've spent %s", asOf.Format("January"), dollars(status.Spent))
+	if briefing.TypicalSpent > 0 {
+		change := (status.Spent - briefing.TypicalSpent) / briefing.TypicalSpent * 100
+		switch {
+		case change >= 5:
+			spent += fmt.Sprintf(", %.0f%% more than usual by this point in the month", change)
+		case change <= -5:
+			spent += fmt.Sprintf(", %.0f%% less than usual by this point in the month", -change)
+		default:
+			spent += ", about what you usually spend by this point in the month"
+		}
+	}
+	speech = append(speech, spent+".")
+
+	// An average budget would only repeat the comparison above
+	if status.BudgetSource == models.BudgetConfigured {
+		if status.Remaining >= 0 {
+			speech = append(speech, fmt.Sprintf("You have %s left of your %s budget.", dollars(status.Remaining), dollars(status.Budget)))
+		} else {
+			speech = append(speech, fmt.Sprintf("You're %s over your %s budget.", dollars(-status.Remaining), dollars(status.Budget)))
+		}
+	}
+
+	speech = append(speech, billsSentence(briefing.Upcoming, now))
+	speech = append(speech, alertsSentence(briefing.Alerts))
+
+	briefing.Speech = strings.Join(speech, " ")
+	return briefing
+}
+
+// typicalSpent averages spending from the 1st through asOf's day of month
+// over the prior months with complete data, so a briefing on the 10th
+// compares against the first ten days of earlier months
+func typicalSpent(data *models.TransactionSet, asOf time.Time) float64 {
+	// The first month only counts if the data starts on the 1st
+	first := data.MinDate()
+	firstFull := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, first.Location())
+	if first.Day() > 1 {
+		firstFull = firstFull.AddDate(0, 1, 0)
+	}
+
+	outflows := data.FilterByType(models.Outflow)
+	var total float64
+	var months int
+	for i := 1; i <= typicalMonths; i++ {
+		start := time.Date(asOf.Year(), asOf.Month()-time.Month(i), 1, 0, 0, 0, 0, asOf.Location())
+		if start.Before(firstFull) {
+			break
+		}
+		end := start.AddDate(0, 0, asOf.Day()-1)
+		if last := start.AddDate(0, 1, -1); end.After(last) {
+			end = last
+		}
+		total += outflows.FilterByDateRange(start, end).SumAbsAmount()
+		months++
+	}
+	if months == 0 {
+		return 0
+	}
+	return total / float64(months)
+}
+
+// upcomingBills lists recurring payments due within upcomingDays of now,
+// soonest first. A payment whose expected date has passed (e.g. the data is
+// a few weeks old) rolls forward by its interval, but one missed more than
+// twice is assumed cancelled.
+func upcomingBills(recurring []models.RecurringPayment, now time.Time) []models.UpcomingBill {
+	today := civilDay(now)
+	horizon := today.AddDate(0, 0, upcomingDays)
+
+	bills := []models.UpcomingBill{}
+	for _, r := range recurring {
+		interval := int(math.Round(r.NextExpected.Sub(r.LastDate).Hours() / 24))
+		if interval <= 0 {
+			continue
+		}
+		due := civilDay(r.NextExpected)
+		for missed := 0; due.Before(today) && missed <= 2; missed++ {
+			due = due.AddDate(0, 0, interval)
+		}
+		if due.Before(today) || due.After(horizon) {
+			continue
+		}
+		bills = append(bills, models.UpcomingBill{
+			Description: r.Description,
+			Amount:      r.Amount,
+			Due:         due.Format("2006-01-02"),
+		})
+	}
+
+	sort.SliceStable(bills, func(i, j int) bool {
+		return bills[i].Due < bills[j].Due
+	})
+	return bills
+}
+
+// alertTitles lists alert titles with errors before warnings
+func alertTitles(alerts []models.SpendingAlert) []string {
+	rank := map[string]int{"error": 0, "warning": 1, "info": 2, "success": 3}
+	sorted := make([]models.SpendingAlert, len(alerts))
+	copy(sorted, alerts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank[sorted[i].Severity] < rank[sorted[j].Severity]
+	})
+
+	titles := make([]string, len(sorted))
+	for i, a := range sorted {
+		titles[i] = a.Title
+	}
+	return titles
+}
+
+// billsSentence reads out the first few upcoming bills
+func billsSentence(bills []models.UpcomingBill, now time.Time) string {
+	if len(bills) == 0 {
+		return "No bills are expected in the next week."
+	}
+
+	today := civilDay(now)
+	var items []string
+	for _, b := range bills[:min(len(bills), spokenItems)] {
+		item := fmt.Sprintf("%s for about %s", b.Description, dollars(b.Amount))
+		due, _ := time.Parse("2006-01-02", b.Due)
+		switch days := int(due.Sub(today).Hours() / 24); days {
+		case 0:
+			item += " today"
+		case 1:
+			item += " tomorrow"
+		default:
+			item += " on " + due.Format("Monday")
+		}
+		items = append(items, item)
+	}
+	if more := len(bills) - len(items); more > 0 {
+		items = append(items, fmt.Sprintf("%d more", more))
+	}
+
+	if len(bills) == 1 {
+		return "One bill is due this week: " + items[0] + "."
+	}
+	return fmt.Sprintf("%d bills are due this week: %s.", len(bills), spokenList(items))
+}
+
+// alertsSentence reads out the first few alert titles
+func alertsSentence(titles []string) string {
+	switch len(titles) {
+	case 0:
+		return "There are no spending alerts."
+	case 1:
+		return "One alert: " + titles[0] + "."
+	}
+
+	items := append([]string{}, titles[:min(len(titles), spokenItems)]...)
+	if more := len(titles) - len(items); more > 0 {
+		items = append(items, fmt.Sprintf("%d more", more))
+	}
+	return fmt.Sprintf("%d alerts: %s.", len(titles), spokenList(items))
+}
+
+// spokenList joins items the way they'd be said: "a, b, and c"
+func spokenList(items []string) string {
+	if len(items) <= 2 {
+		return strings.Join(items, " and ")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + ", and " + items[len(items)-1]
+}
+
+// dollars formats an amount in whole dollars, which reads better aloud
+func dollars(v float64) string {
+	s := fmt.Sprintf("%.0f", math.Abs(v))
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteRune(',')
+		}
+		b.WriteRune(c)
+	}
+	return "$" + b.String()
+}
+
+// civilDay strips the time of day so dates compare as calendar days
+func civilDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,94 @@
+package status
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+)
+
+func date(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+// briefingData has $100 of spending in the first ten days of each month
+// from January through March 2026, and $150 so far in April
+func briefingData() *models.TransactionSet {
+	var txns []models.Transaction
+	for _, month := range []string{"2026-01", "2026-02", "2026-03"} {
+		txns = append(txns,
+			models.Transaction{Date: date(month + "-01"), Description: "Paycheck", Amount: 3000, Category: "Salary", TransactionType: models.Income},
+			models.Transaction{Date: date(month + "-05"), Description: "Groceries", Amount: -100, Category: "Groceries", TransactionType: models.Outflow},
+			models.Transaction{Date: date(month + "-20"), Description: "Dinner", Amount: -80, Category: "Restaurants", TransactionType: models.Outflow},
+		)
+	}
+	txns = append(txns,
+		models.Transaction{Date: date("2026-04-01"), Description: "Paycheck", Amount: 3000, Category: "Salary", TransactionType: models.Income},
+		models.Transaction{Date: date("2026-04-10"), Description: "Groceries", Amount: -150, Category: "Groceries", TransactionType: models.Outflow},
+	)
+	return models.NewTransactionSet(txns)
+}
+
+func TestBuildBriefing(t *testing.T) {
+	recurring := []models.RecurringPayment{
+		{Description: "netflix", Amount: 15.99, LastDate: date("2026-03-14"), NextExpected: date("2026-04-13")},
+		{Description: "rent", Amount: 1500, LastDate: date("2026-03-11"), NextExpected: date("2026-04-11")},
+		{Description: "gym", Amount: 40, LastDate: date("2026-03-25"), NextExpected: date("2026-04-24")},
+		{Description: "old magazine", Amount: 12, LastDate: date("2025-09-01"), NextExpected: date("2025-10-01")},
+	}
+	alerts := []models.SpendingAlert{
+		{Severity: "warning", Title: "Coffee Near Limit"},
+		{Severity: "error", Title: "Amazon Over Limit"},
+	}
+
+	b := buildBriefing(briefingData(), recurring, alerts, 2000, date("2026-04-11"))
+
+	if b.TypicalSpent != 100 {
+		t.Errorf("typical spent = %.2f, want 100 (the first ten days of earlier months)", b.TypicalSpent)
+	}
+	if len(b.Upcoming) != 2 || b.Upcoming[0].Description != "rent" || b.Upcoming[1].Due != "2026-04-13" {
+		t.Errorf("upcoming = %+v, want rent today and netflix on the 13th", b.Upcoming)
+	}
+	if len(b.Alerts) != 2 || b.Alerts[0] != "Amazon Over Limit" {
+		t.Errorf("alerts = %v, want errors first", b.Alerts)
+	}
+
+	for _, want := range []string{
+		"So far in April you've spent $150, 50% more than usual by this point in the month.",
+		"You have $1,850 left of your $2,000 budget.",
+		"2 bills are due this week: rent for about $1,500 today and netflix for about $16 on Monday.",
+		"2 alerts: Amazon Over Limit and Coffee Near Limit.",
+	} {
+		if !strings.Contains(b.Speech, want) {
+			t.Errorf("speech %q should contain %q", b.Speech, want)
+		}
+	}
+}
+
+func TestBuildBriefingQuiet(t *testing.T) {
+	b := buildBriefing(briefingData(), nil, nil, 0, date("2026-04-11"))
+	for _, want := range []string{"No bills are expected", "There are no spending alerts."} {
+		if !strings.Contains(b.Speech, want) {
+			t.Errorf("speech %q should contain %q", b.Speech, want)
+		}
+	}
+	if strings.Contains(b.Speech, "budget") {
+		t.Errorf("speech %q should not repeat the average as a budget", b.Speech)
+	}
+
+	empty := buildBriefing(models.NewTransactionSet(nil), nil, nil, 0, date("2026-04-11"))
+	if empty.Speech != "There are no transactions to summarize yet." {
+		t.Errorf("empty speech = %q", empty.Speech)
+	}
+}
+
+func TestSpokenHelpers(t *testing.T) {
+	if got := dollars(1234567.6); got != "$1,234,568" {
+		t.Errorf("dollars = %q", got)
+	}
+	if got := spokenList([]string{"a", "b", "c"}); got != "a, b, and c" {
+		t.Errorf("spokenList = %q", got)
+	}
+}
@@ -0,0 +1,75 @@
+package status
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/services/analytics"
+	"budget2/internal/services/dataloader"
+	"budget2/internal/services/watchlist"
+)
+
+var (
+	loader  *dataloader.DataLoader
+	watched *watchlist.Manager
+
+	// token guards the status endpoint; empty disables it
+	token string
+
+	// monthlyBudget is the configured spending budget; zero compares against
+	// average spending instead
+	monthlyBudget float64
+)
+
+// Initialize sets up the status package with required dependencies
+func Initialize(l *dataloader.DataLoader, w *watchlist.Manager, statusToken string, budget float64) {
+	loader = l
+	watched = w
+	token = statusToken
+	monthlyBudget = budget
+}
+
+// RegisterRoutes registers the status routes
+func RegisterRoutes(r chi.Router) {
+	r.Get("/api/status", handleStatus)
+	r.Get("/api/v1/briefing", handleBriefing)
+}
+
+// authorized reports whether the request carries the status token, either
+// as a token query parameter or an "Authorization: Bearer" header
+func authorized(r *http.Request) bool {
+	given := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		given = strings.TrimPrefix(auth, "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// handleStatus serves this month's spending against budget and the savings
+// rate as a small JSON document for home dashboards. It is read-only and
+// needs no session, only the configured token.
+func handleStatus(w http.ResponseWriter, r *http.Request) {
+	if token == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if !authorized(r) {
+		http.Error(w, "Invalid status token", http.StatusUnauthorized)
+		return
+	}
+
+	data, err := loader.LoadData()
+	if err != nil {
+		http.Error(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(analytics.CalculateMonthStatus(data, monthlyBudget))
+}
@@ -0,0 +1,83 @@
+package system
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/services/routestats"
+	"budget2/internal/templates"
+)
+
+// chartHours is how far back the route analytics chart goes
+const chartHours = 48
+
+var (
+	renderer *templates.Renderer
+	recorder *routestats.Recorder
+)
+
+// Initialize sets up the system package with required dependencies. A nil
+// recorder means route analytics are off.
+func Initialize(r *templates.Renderer, rec *routestats.Recorder) {
+	renderer = r
+	recorder = rec
+}
+
+// RegisterRoutes registers the system routes
+func RegisterRoutes(r chi.Router) {
+	r.Get("/system/routes", handleRoutesPage)
+	r.Get("/system/routes/chart", handleRoutesChart)
+}
+
+// handleRoutesPage shows request counts and latency per route since the
+// server started, or since the oldest request the recorder still holds
+func handleRoutesPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":     "Route Analytics",
+		"ActiveTab": "routes",
+		"Enabled":   recorder != nil,
+		"Route":     r.URL.Query().Get("route"),
+	}
+	if recorder != nil {
+		data["Stats"] = recorder.Stats(registeredRoutes(r))
+	}
+	renderer.Render(w, "base", data)
+}
+
+// handleRoutesChart plots requests and latency per hour for the route
+// parameter, or for all routes
+func handleRoutesChart(w http.ResponseWriter, r *http.Request) {
+	if recorder == nil {
+		http.Error(w, "Route analytics are off", http.StatusNotFound)
+		return
+	}
+	hours := recorder.Hourly(r.URL.Query().Get("route"), chartHours, time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(routestats.HourlyChart(hours))
+}
+
+// registeredRoutes lists the app's routes as "METHOD /pattern", leaving
+// out static files, profiling and this page's own routes
+func registeredRoutes(r *http.Request) []string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return nil
+	}
+
+	var routes []string
+	chi.Walk(rctx.Routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		for _, prefix := range []string{"/static/", "/debug/", "/system/"} {
+			if strings.HasPrefix(route, prefix) {
+				return nil
+			}
+		}
+		routes = append(routes, method+" "+route)
+		return nil
+	})
+	return routes
+}
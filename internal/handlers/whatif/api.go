@@ -0,0 +1,102 @@
+package whatif
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"budget2/internal/models"
+	"budget2/internal/services/retirement"
+)
+
+// whatIfResponse is the JSON served by /api/v1/whatif
+type whatIfResponse struct {
+	Settings *models.WhatIfSettings `json:"settings"`
+	Analysis *models.WhatIfAnalysis `json:"analysis"`
+}
+
+// handleWhatIfAPI returns the saved what-if settings and their retirement
+// analysis as JSON
+func handleWhatIfAPI(w http.ResponseWriter, r *http.Request) {
+	settings, err := retirementMgr.Load()
+	if err != nil {
+		http.Error(w, "Failed to load settings: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(whatIfResponse{
+		Settings: settings,
+		Analysis: runAnalysisWithCache(settings),
+	})
+}
+
+// Sweep run counts: the default and the most one request may ask for
+const (
+	sweepRuns    = 500
+	maxSweepRuns = 2000
+)
+
+// handleSweepAPI runs the saved plan once per value of one setting and
+// returns each value's success rate and final balance as JSON. Values come
+// from a comma-separated values list or a from/to/step range.
+func handleSweepAPI(w http.ResponseWriter, r *http.Request) {
+	values, err := sweepValues(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	runs, err := parseFormInt(r, "runs")
+	if err != nil || runs < 0 || runs > maxSweepRuns {
+		http.Error(w, fmt.Sprintf("runs must be between 1 and %d", maxSweepRuns), http.StatusBadRequest)
+		return
+	}
+	if runs == 0 {
+		runs = sweepRuns
+	}
+
+	settings, err := retirementMgr.Load()
+	if err != nil {
+		http.Error(w, "Failed to load settings: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result, err := retirement.NewCalculator(settings).RunSweep(r.FormValue("param"), values, runs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// sweepValues reads the values to sweep from the request
+func sweepValues(r *http.Request) ([]float64, error) {
+	if list := r.FormValue("values"); list != "" {
+		var values []float64
+		for _, v := range strings.Split(list, ",") {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q: must be a number", v)
+			}
+			values = append(values, f)
+		}
+		return values, nil
+	}
+
+	from, err := parseRequiredFormFloat(r, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseRequiredFormFloat(r, "to")
+	if err != nil {
+		return nil, err
+	}
+	step, err := parseRequiredFormFloat(r, "step")
+	if err != nil {
+		return nil, err
+	}
+	return retirement.SweepValues(from, to, step)
+}
@@ -0,0 +1,65 @@
+package whatif
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/pdf"
+	"budget2/internal/services/retirement"
+)
+
+// sourceLabels name each assumption source in the PDF report
+var sourceLabels = map[string]string{
+	models.AssumptionUser:    "Entered",
+	models.AssumptionSynced:  "Synced",
+	models.AssumptionDefault: "Default",
+}
+
+// handleAssumptionsReport downloads every input to the projections with
+// where its value came from and when it last changed, as a PDF for
+// sharing with an advisor or as JSON with format=json
+func handleAssumptionsReport(w http.ResponseWriter, r *http.Request) {
+	settings, err := settingsFor(r).Load()
+	if err != nil {
+		http.Error(w, "Failed to load settings: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	report := retirement.Assumptions(settings, time.Now())
+	filename := "whatif_assumptions_" + report.Generated.Format("2006-01-02")
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.json\"", filename))
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	doc := pdf.New()
+	doc.Title("Retirement Projection Assumptions")
+	doc.Text("Generated " + report.Generated.Format("January 2, 2006 3:04 PM") + ". " +
+		"Entered values were set on the what-if page, synced values were derived from transaction data, " +
+		"and defaults are built in and have never been changed.")
+
+	widths := []float64{150, 210, 60, 84}
+	group := ""
+	for _, a := range report.Assumptions {
+		if a.Group != group {
+			group = a.Group
+			doc.Heading(group)
+			doc.Row(true, widths, "Input", "Value", "Source", "Last changed")
+		}
+		changed := ""
+		if a.Changed != nil {
+			changed = a.Changed.Format("2006-01-02")
+		}
+		doc.Row(false, widths, a.Name, a.Value, sourceLabels[a.Source], changed)
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.pdf\"", filename))
+	doc.WriteTo(w)
+}
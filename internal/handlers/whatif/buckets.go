@@ -0,0 +1,163 @@
+package whatif
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"budget2/internal/models"
+)
+
+// parseBucket reads a portfolio bucket's value, return and withdrawal
+// priority from form data. A blank priority is reported as ok == false so
+// the caller can pick one.
+func parseBucket(r *http.Request) (value, annualReturn float64, priority int, ok bool, err error) {
+	value, err = parseRequiredFormFloat(r, "value")
+	if err != nil {
+		return 0, 0, 0, false, err
+	}
+	if value < 0 {
+		return 0, 0, 0, false, fmt.Errorf("value cannot be negative")
+	}
+
+	annualReturn, err = parseRequiredFormFloat(r, "return")
+	if err != nil {
+		return 0, 0, 0, false, err
+	}
+	if annualReturn < -50 || annualReturn > 50 {
+		return 0, 0, 0, false, fmt.Errorf("return must be between -50%% and 50%%")
+	}
+
+	if r.FormValue("priority") == "" {
+		return value, annualReturn, 0, false, nil
+	}
+	priority, err = parseFormInt(r, "priority")
+	if err != nil {
+		return 0, 0, 0, false, fmt.Errorf("invalid priority: must be an integer")
+	}
+	if priority < 0 {
+		return 0, 0, 0, false, fmt.Errorf("priority cannot be negative")
+	}
+	return value, annualReturn, priority, true, nil
+}
+
+func handleWhatIfAddBucket(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		renderError(w, "Invalid form data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name := r.FormValue("name")
+	if name == "" {
+		renderError(w, "Bucket name is required", http.StatusBadRequest)
+		return
+	}
+
+	value, annualReturn, priority, hasPriority, err := parseBucket(r)
+	if err != nil {
+		renderError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !hasPriority {
+		current, err := settingsFor(r).Load()
+		if err != nil {
+			renderError(w, "Failed to load settings: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		priority = current.NextBucketPriority()
+	}
+
+	bucket := models.PortfolioBucket{
+		ID:       uuid.New().String(),
+		Name:     name,
+		Value:    value,
+		Return:   annualReturn,
+		Priority: priority,
+	}
+
+	settings, err := settingsFor(r).AddPortfolioBucket(bucket)
+	if err != nil {
+		renderError(w, "Failed to add bucket: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	analysis := runAnalysisWithCache(settings)
+
+	partialData := map[string]interface{}{
+		"Settings": settings,
+		"Analysis": analysis,
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "whatif-results", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
+
+func handleWhatIfUpdateBucket(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if err := r.ParseForm(); err != nil {
+		renderError(w, "Invalid form data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	value, annualReturn, priority, hasPriority, err := parseBucket(r)
+	if err != nil {
+		renderError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !hasPriority {
+		renderError(w, "missing required field: priority", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := settingsFor(r).UpdatePortfolioBucket(id, value, annualReturn, priority)
+	if err != nil {
+		renderError(w, "Failed to update bucket: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	analysis := runAnalysisWithCache(settings)
+
+	partialData := map[string]interface{}{
+		"Settings": settings,
+		"Analysis": analysis,
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "whatif-results", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
+
+func handleWhatIfDeleteBucket(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	settings, err := settingsFor(r).RemovePortfolioBucket(id)
+	if err != nil {
+		renderError(w, "Failed to remove bucket: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	analysis := runAnalysisWithCache(settings)
+
+	partialData := map[string]interface{}{
+		"Settings": settings,
+		"Analysis": analysis,
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "whatif-results", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
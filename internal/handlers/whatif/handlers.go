@@ -8,7 +8,6 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -16,20 +15,20 @@ import (
 
 	"budget2/internal/handlers/insights"
 	"budget2/internal/models"
+	"budget2/internal/services/cache"
 	"budget2/internal/services/dataloader"
+	"budget2/internal/services/relocation"
 	"budget2/internal/services/retirement"
+	"budget2/internal/services/savings"
 	"budget2/internal/templates"
 )
 
 // analysisCache caches expensive analysis results keyed by settings hash
-type analysisCache struct {
-	mu       sync.RWMutex
-	hash     string
-	analysis *models.WhatIfAnalysis
-	cachedAt time.Time
-}
+var analysisCache = cache.New(5 * time.Minute)
 
-var cache = &analysisCache{}
+// analysisVersion is the cache version for every analysis; the settings hash
+// is the key, so the saved plan and sandbox scenarios are cached side by side
+const analysisVersion = "whatif"
 
 // getSettingsHash generates a hash of the settings for cache key
 func getSettingsHash(settings *models.WhatIfSettings) string {
@@ -41,53 +40,42 @@ func getSettingsHash(settings *models.WhatIfSettings) string {
 	return fmt.Sprintf("%x", hash[:8]) // Use first 8 bytes for shorter key
 }
 
-// getCachedAnalysis returns cached analysis if settings match and cache is fresh
-func getCachedAnalysis(settings *models.WhatIfSettings) *models.WhatIfAnalysis {
+// runAnalysisWithCache runs full analysis, using cache when available
+func runAnalysisWithCache(settings *models.WhatIfSettings) *models.WhatIfAnalysis {
 	hash := getSettingsHash(settings)
 	if hash == "" {
-		return nil
+		return analyze(settings)
 	}
 
-	cache.mu.RLock()
-	defer cache.mu.RUnlock()
-
-	// Return cached result if hash matches and cache is less than 5 minutes old
-	if cache.hash == hash && time.Since(cache.cachedAt) < 5*time.Minute {
-		return cache.analysis
-	}
-	return nil
+	return analysisCache.GetOrCompute(analysisVersion, hash, func() interface{} {
+		return analyze(settings)
+	}).(*models.WhatIfAnalysis)
 }
 
-// setCachedAnalysis stores analysis result in cache
-func setCachedAnalysis(settings *models.WhatIfSettings, analysis *models.WhatIfAnalysis) {
-	hash := getSettingsHash(settings)
-	if hash == "" {
-		return
+// analyze runs the scenario's selected engine, falling back to the standard
+// calculator if that engine isn't registered (e.g. settings from a newer build)
+func analyze(settings *models.WhatIfSettings) *models.WhatIfAnalysis {
+	analysis, err := retirement.Analyze(settings)
+	if err != nil {
+		log.Printf("Warning: %v; using %s engine", err, retirement.DefaultEngine)
+		return retirement.NewCalculator(settings).RunFullAnalysis()
 	}
-
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
-
-	cache.hash = hash
-	cache.analysis = analysis
-	cache.cachedAt = time.Now()
+	return analysis
 }
 
-// runAnalysisWithCache runs full analysis, using cache when available
-func runAnalysisWithCache(settings *models.WhatIfSettings) *models.WhatIfAnalysis {
-	// Check cache first
-	if cached := getCachedAnalysis(settings); cached != nil {
-		return cached
+// Warm precomputes the analysis for the saved settings so the first what-if
+// page load after a restart is served from cache. Settings that haven't been
+// synced from the dashboard yet are skipped, since the first visit changes them.
+func Warm() error {
+	settings, err := retirementMgr.Load()
+	if err != nil {
+		return err
 	}
-
-	// Run full analysis
-	calc := retirement.NewCalculator(settings)
-	analysis := calc.RunFullAnalysis()
-
-	// Cache the result
-	setCachedAnalysis(settings, analysis)
-
-	return analysis
+	if len(settings.IncomeSources) == 0 {
+		return nil
+	}
+	runAnalysisWithCache(settings)
+	return nil
 }
 
 // renderError renders an HTML error fragment for HTMX requests
@@ -115,6 +103,64 @@ func parseFormFloat(r *http.Request, key string) (float64, error) {
 	return strconv.ParseFloat(v, 64)
 }
 
+// parseIncomeTiming reads when an income source starts and ends. With
+// start_by=age the start_age and end_age fields are used; otherwise the
+// start_year and end_year offsets are.
+func parseIncomeTiming(r *http.Request) (models.IncomeTiming, error) {
+	var timing models.IncomeTiming
+
+	if r.FormValue("start_by") == "age" {
+		startAge, err := parseFormInt(r, "start_age")
+		if err != nil {
+			return timing, fmt.Errorf("Invalid start age: %w", err)
+		}
+		if startAge < 1 || startAge > 120 {
+			return timing, fmt.Errorf("Start age must be between 1 and 120")
+		}
+		endAge, err := parseFormInt(r, "end_age")
+		if err != nil {
+			return timing, fmt.Errorf("Invalid end age: %w", err)
+		}
+		if endAge > 0 && endAge < startAge {
+			return timing, fmt.Errorf("End age cannot be before start age")
+		}
+		timing.StartAge, timing.EndAge = startAge, endAge
+		return timing, nil
+	}
+
+	startYear, err := parseFormInt(r, "start_year")
+	if err != nil {
+		return timing, fmt.Errorf("Invalid start year: %w", err)
+	}
+	if startYear < 0 {
+		return timing, fmt.Errorf("Start year cannot be negative")
+	}
+	endYear, err := parseFormInt(r, "end_year")
+	if err != nil {
+		return timing, fmt.Errorf("Invalid end year: %w", err)
+	}
+	if endYear > 0 && endYear < startYear {
+		return timing, fmt.Errorf("End year cannot be before start year")
+	}
+	timing.StartYear, timing.EndYear = startYear, endYear
+	return timing, nil
+}
+
+// parseCOLA reads an income source's COLA from the cola_rate (percent) and
+// cola_inflation (checkbox) fields, returning the rate as a fraction
+func parseCOLA(r *http.Request) (float64, bool, error) {
+	inflationAdjusted := r.FormValue("cola_inflation") == "on" || r.FormValue("cola_inflation") == "true"
+
+	rate, err := parseFormFloat(r, "cola_rate")
+	if err != nil {
+		return 0, false, fmt.Errorf("Invalid COLA rate: %w", err)
+	}
+	if rate < 0 || rate > 10 {
+		return 0, false, fmt.Errorf("COLA rate must be between 0 and 10%%")
+	}
+	return rate / 100, inflationAdjusted, nil
+}
+
 // parseFormInt parses an int from form data, returning an error if invalid
 func parseFormInt(r *http.Request, key string) (int, error) {
 	v := r.FormValue(key)
@@ -154,13 +200,24 @@ var (
 	loader       *dataloader.DataLoader
 	renderer     *templates.Renderer
 	retirementMgr *retirement.SettingsManager
+	savingsMgr    *savings.Manager
+	relocationMgr *relocation.Manager
+	readinessLog  *retirement.ReadinessHistory
+
+	// sandboxes hold session-scoped copies of the settings, edited without
+	// saving until committed
+	sandboxes *retirement.Sandboxes
 )
 
 // Initialize sets up the whatif package with required dependencies
-func Initialize(l *dataloader.DataLoader, r *templates.Renderer, rm *retirement.SettingsManager) {
+func Initialize(l *dataloader.DataLoader, r *templates.Renderer, rm *retirement.SettingsManager, sm *savings.Manager, rl *relocation.Manager, rh *retirement.ReadinessHistory) {
 	loader = l
 	renderer = r
 	retirementMgr = rm
+	savingsMgr = sm
+	relocationMgr = rl
+	readinessLog = rh
+	sandboxes = retirement.NewSandboxes(rm, retirement.SandboxTTL)
 }
 
 // RegisterRoutes registers all whatif routes
@@ -179,13 +236,36 @@ func RegisterRoutes(r chi.Router) {
 	r.Post("/whatif/healthcare", handleWhatIfAddHealthcare)
 	r.Put("/whatif/healthcare/{id}", handleWhatIfUpdateHealthcare)
 	r.Delete("/whatif/healthcare/{id}", handleWhatIfDeleteHealthcare)
+	r.Post("/whatif/bucket", handleWhatIfAddBucket)
+	r.Put("/whatif/bucket/{id}", handleWhatIfUpdateBucket)
+	r.Delete("/whatif/bucket/{id}", handleWhatIfDeleteBucket)
 	r.Get("/whatif/chart/projection", handleWhatIfProjectionChart)
 	r.Post("/whatif/sync", handleWhatIfSync)
+	r.Get("/whatif/sync-windows", handleSyncWindows)
 	r.Post("/whatif/montecarlo", handleWhatIfMonteCarlo)
+	r.Get("/whatif/stress", handleStressTest)
+	r.Get("/whatif/assumptions", handleAssumptionsReport)
+	r.Get("/whatif/readiness", handleReadinessReport)
+	r.Get("/whatif/rmd/withholding", handleWithholdingSchedule)
+	r.Get("/whatif/savings", handleSavingsPartial)
+	r.Post("/whatif/savings", handleSavingsPlan)
+	r.Get("/whatif/relocation", handleRelocationPartial)
+	r.Post("/whatif/relocation", handleSaveRelocation)
+	r.Post("/whatif/relocation/current", handleSetCurrentLocation)
+	r.Delete("/whatif/relocation/{id}", handleDeleteRelocation)
+	r.Post("/whatif/sandbox", handleSandboxOpen)
+	r.Post("/whatif/sandbox/commit", handleSandboxCommit)
+	r.Delete("/whatif/sandbox", handleSandboxDiscard)
+}
+
+// RegisterAPIRoutes registers the what-if JSON API routes
+func RegisterAPIRoutes(r chi.Router) {
+	r.Get("/api/v1/whatif", handleWhatIfAPI)
+	r.Get("/api/v1/whatif/sweep", handleSweepAPI)
 }
 
 func handleWhatIf(w http.ResponseWriter, r *http.Request) {
-	settings, err := retirementMgr.Load()
+	settings, err := settingsFor(r).Load()
 	if err != nil {
 		log.Printf("Error loading what-if settings: %v", err)
 		settings = models.DefaultWhatIfSettings()
@@ -193,18 +273,29 @@ func handleWhatIf(w http.ResponseWriter, r *http.Request) {
 
 	// If no income sources saved yet, auto-sync from dashboard on first load
 	if len(settings.IncomeSources) == 0 {
-		syncSettingsFromDashboard(settings)
-		retirementMgr.Save(settings)
+		synced, err := settingsFor(r).Sync(func(s *models.WhatIfSettings) error {
+			if len(s.IncomeSources) == 0 {
+				syncSettingsFromDashboard(s)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("Error saving synced what-if settings: %v", err)
+		} else {
+			settings = synced
+		}
 	}
 
 	// Run full analysis (with caching)
 	analysis := runAnalysisWithCache(settings)
+	_, sandboxed := sandboxFor(r)
 
 	pageData := map[string]interface{}{
 		"Title":     "What-If Analysis",
 		"ActiveTab": "whatif",
 		"Settings":  settings,
 		"Analysis":  analysis,
+		"Sandbox":   sandboxed,
 	}
 
 	if renderer != nil {
@@ -216,7 +307,7 @@ func handleWhatIf(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleWhatIfCalculate(w http.ResponseWriter, r *http.Request) {
-	settings, err := retirementMgr.Load()
+	settings, err := settingsFor(r).Load()
 	if err != nil {
 		renderError(w, "Failed to load settings: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -250,6 +341,10 @@ func handleWhatIfSettings(w http.ResponseWriter, r *http.Request) {
 		renderError(w, "Invalid portfolio value: "+err.Error(), http.StatusBadRequest)
 		return
 	} else if v != 0 || r.FormValue("portfolio_value") != "" {
+		if v < 0 {
+			renderError(w, "Portfolio value can't be negative", http.StatusBadRequest)
+			return
+		}
 		updates["portfolio_value"] = v
 	}
 
@@ -257,6 +352,10 @@ func handleWhatIfSettings(w http.ResponseWriter, r *http.Request) {
 		renderError(w, "Invalid monthly expenses: "+err.Error(), http.StatusBadRequest)
 		return
 	} else if v != 0 || r.FormValue("monthly_living_expenses") != "" {
+		if v < 0 {
+			renderError(w, "Monthly expenses can't be negative", http.StatusBadRequest)
+			return
+		}
 		updates["monthly_living_expenses"] = v
 	}
 
@@ -264,6 +363,10 @@ func handleWhatIfSettings(w http.ResponseWriter, r *http.Request) {
 		renderError(w, "Invalid healthcare cost: "+err.Error(), http.StatusBadRequest)
 		return
 	} else if v != 0 || r.FormValue("monthly_healthcare") != "" {
+		if v < 0 {
+			renderError(w, "Healthcare cost can't be negative", http.StatusBadRequest)
+			return
+		}
 		updates["monthly_healthcare"] = v
 	}
 
@@ -271,6 +374,10 @@ func handleWhatIfSettings(w http.ResponseWriter, r *http.Request) {
 		renderError(w, "Invalid healthcare start years: "+err.Error(), http.StatusBadRequest)
 		return
 	} else if v != 0 || r.FormValue("healthcare_start_years") != "" {
+		if v < 0 {
+			renderError(w, "Healthcare start years can't be negative", http.StatusBadRequest)
+			return
+		}
 		updates["healthcare_start_years"] = v
 	}
 
@@ -300,6 +407,10 @@ func handleWhatIfSettings(w http.ResponseWriter, r *http.Request) {
 		renderError(w, "Invalid inflation rate: "+err.Error(), http.StatusBadRequest)
 		return
 	} else if v != 0 || r.FormValue("inflation_rate") != "" {
+		if v < -10 || v > 30 {
+			renderError(w, "Inflation must be between -10% and 30%", http.StatusBadRequest)
+			return
+		}
 		updates["inflation_rate"] = v
 	}
 
@@ -307,6 +418,10 @@ func handleWhatIfSettings(w http.ResponseWriter, r *http.Request) {
 		renderError(w, "Invalid healthcare inflation: "+err.Error(), http.StatusBadRequest)
 		return
 	} else if v != 0 || r.FormValue("healthcare_inflation") != "" {
+		if v < -10 || v > 30 {
+			renderError(w, "Healthcare inflation must be between -10% and 30%", http.StatusBadRequest)
+			return
+		}
 		updates["healthcare_inflation"] = v
 	}
 
@@ -314,13 +429,32 @@ func handleWhatIfSettings(w http.ResponseWriter, r *http.Request) {
 		renderError(w, "Invalid spending decline rate: "+err.Error(), http.StatusBadRequest)
 		return
 	} else if v != 0 || r.FormValue("spending_decline_rate") != "" {
+		if v < 0 || v > 20 {
+			renderError(w, "Spending decline must be between 0% and 20%", http.StatusBadRequest)
+			return
+		}
 		updates["spending_decline_rate"] = v
 	}
 
+	if v, err := parseFormFloat(r, "essential_floor"); err != nil {
+		renderError(w, "Invalid essential floor: "+err.Error(), http.StatusBadRequest)
+		return
+	} else if v != 0 || r.FormValue("essential_floor") != "" {
+		if v < 0 {
+			renderError(w, "Essential floor can't be negative", http.StatusBadRequest)
+			return
+		}
+		updates["essential_floor"] = v
+	}
+
 	if v, err := parseFormFloat(r, "investment_return"); err != nil {
 		renderError(w, "Invalid investment return: "+err.Error(), http.StatusBadRequest)
 		return
 	} else if v != 0 || r.FormValue("investment_return") != "" {
+		if v < -50 || v > 50 {
+			renderError(w, "Investment return must be between -50% and 50%", http.StatusBadRequest)
+			return
+		}
 		updates["investment_return"] = v
 	}
 
@@ -328,6 +462,10 @@ func handleWhatIfSettings(w http.ResponseWriter, r *http.Request) {
 		renderError(w, "Invalid discount rate: "+err.Error(), http.StatusBadRequest)
 		return
 	} else if v != 0 || r.FormValue("discount_rate") != "" {
+		if v < -10 || v > 30 {
+			renderError(w, "Discount rate must be between -10% and 30%", http.StatusBadRequest)
+			return
+		}
 		updates["discount_rate"] = v
 	}
 
@@ -349,7 +487,76 @@ func handleWhatIfSettings(w http.ResponseWriter, r *http.Request) {
 		updates["steady_state_override_year"] = v
 	}
 
-	settings, err := retirementMgr.UpdateSettings(updates)
+	if v, err := parseFormFloat(r, "annual_qcd"); err != nil {
+		renderError(w, "Invalid QCD amount: "+err.Error(), http.StatusBadRequest)
+		return
+	} else if v != 0 || r.FormValue("annual_qcd") != "" {
+		if v < 0 {
+			renderError(w, "QCD amount can't be negative", http.StatusBadRequest)
+			return
+		}
+		updates["annual_qcd"] = v
+	}
+
+	if v, err := parseFormFloat(r, "legacy_target"); err != nil {
+		renderError(w, "Invalid legacy target: "+err.Error(), http.StatusBadRequest)
+		return
+	} else if v != 0 || r.FormValue("legacy_target") != "" {
+		if v < 0 {
+			renderError(w, "Legacy target can't be negative", http.StatusBadRequest)
+			return
+		}
+		updates["legacy_target"] = v
+	}
+
+	if v := r.FormValue("crash_recovery"); v != "" {
+		if v != models.RecoveryV && v != models.RecoveryU && v != models.RecoveryL {
+			renderError(w, "Crash recovery must be V, U or L", http.StatusBadRequest)
+			return
+		}
+		updates["crash_recovery"] = v
+	}
+
+	if v, err := parseFormInt(r, "bear_market_years"); err != nil {
+		renderError(w, "Invalid bear market length: "+err.Error(), http.StatusBadRequest)
+		return
+	} else if v != 0 || r.FormValue("bear_market_years") != "" {
+		if v < 1 || v > 5 {
+			renderError(w, "Bear market length must be between 1 and 5 years", http.StatusBadRequest)
+			return
+		}
+		updates["bear_market_years"] = v
+	}
+
+	if v, err := parseFormFloat(r, "inflation_correlation"); err != nil {
+		renderError(w, "Invalid inflation correlation: "+err.Error(), http.StatusBadRequest)
+		return
+	} else if v != 0 || r.FormValue("inflation_correlation") != "" {
+		if v < 0 || v > 1 {
+			renderError(w, "Inflation correlation must be between 0 and 1", http.StatusBadRequest)
+			return
+		}
+		updates["inflation_correlation"] = v
+	}
+
+	if r.Form.Has("sex") {
+		v := r.FormValue("sex")
+		if v != "" && v != models.SexMale && v != models.SexFemale {
+			renderError(w, "Sex must be male, female or blank", http.StatusBadRequest)
+			return
+		}
+		updates["sex"] = v
+	}
+
+	if v := r.FormValue("health"); v != "" {
+		if v != models.HealthExcellent && v != models.HealthGood && v != models.HealthAverage && v != models.HealthPoor {
+			renderError(w, "Health must be excellent, good, average or poor", http.StatusBadRequest)
+			return
+		}
+		updates["health"] = v
+	}
+
+	settings, err := settingsFor(r).UpdateSettings(updates)
 	if err != nil {
 		renderError(w, "Failed to save settings: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -392,47 +599,29 @@ func handleWhatIfAddIncome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	startYear, err := parseFormInt(r, "start_year")
+	timing, err := parseIncomeTiming(r)
 	if err != nil {
-		renderError(w, "Invalid start year: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-	if startYear < 0 {
-		renderError(w, "Start year cannot be negative", http.StatusBadRequest)
+		renderError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	endYear, err := parseFormInt(r, "end_year")
+	colaRate, inflationAdjusted, err := parseCOLA(r)
 	if err != nil {
-		renderError(w, "Invalid end year: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-	if endYear > 0 && endYear < startYear {
-		renderError(w, "End year cannot be before start year", http.StatusBadRequest)
+		renderError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	cola := r.FormValue("cola") == "on" || r.FormValue("cola") == "true"
-
 	source := models.IncomeSource{
-		ID:         uuid.New().String(),
-		Name:       name,
-		Amount:     amount,
-		Type:       models.IncomeFixed,
-		StartMonth: startYear * 12,
-		COLARate:   0,
+		ID:                uuid.New().String(),
+		Name:              name,
+		Amount:            amount,
+		Type:              models.IncomeFixed,
+		COLARate:          colaRate,
+		InflationAdjusted: inflationAdjusted,
 	}
+	timing.Apply(&source)
 
-	if cola {
-		source.COLARate = 0.02 // 2% COLA
-	}
-
-	if endYear > 0 {
-		endMonth := endYear * 12
-		source.EndMonth = &endMonth
-	}
-
-	settings, err := retirementMgr.AddIncomeSource(source)
+	settings, err := settingsFor(r).AddIncomeSource(source)
 	if err != nil {
 		renderError(w, "Failed to add income source: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -461,34 +650,19 @@ func handleWhatIfUpdateIncome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	startYear, err := parseFormInt(r, "start_year")
+	timing, err := parseIncomeTiming(r)
 	if err != nil {
-		renderError(w, "Invalid start year: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-	if startYear < 0 {
-		renderError(w, "Start year cannot be negative", http.StatusBadRequest)
+		renderError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	endYear, err := parseFormInt(r, "end_year")
+	colaRate, inflationAdjusted, err := parseCOLA(r)
 	if err != nil {
-		renderError(w, "Invalid end year: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-	if endYear > 0 && endYear < startYear {
-		renderError(w, "End year cannot be before start year", http.StatusBadRequest)
+		renderError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	cola := r.FormValue("cola") == "on" || r.FormValue("cola") == "true"
-
-	colaRate := 0.0
-	if cola {
-		colaRate = 0.02 // 2% COLA
-	}
-
-	settings, err := retirementMgr.UpdateIncomeSource(id, startYear, endYear, colaRate)
+	settings, err := settingsFor(r).UpdateIncomeSource(id, timing, colaRate, inflationAdjusted)
 	if err != nil {
 		renderError(w, "Failed to update income source: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -512,7 +686,7 @@ func handleWhatIfUpdateIncome(w http.ResponseWriter, r *http.Request) {
 func handleWhatIfDeleteIncome(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	settings, err := retirementMgr.RemoveIncomeSource(id)
+	settings, err := settingsFor(r).RemoveIncomeSource(id)
 	if err != nil {
 		renderError(w, "Failed to remove income source: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -536,7 +710,7 @@ func handleWhatIfDeleteIncome(w http.ResponseWriter, r *http.Request) {
 func handleWhatIfRestoreIncome(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	settings, err := retirementMgr.RestoreIncomeSource(id)
+	settings, err := settingsFor(r).RestoreIncomeSource(id)
 	if err != nil {
 		renderError(w, "Failed to restore income source: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -612,7 +786,7 @@ func handleWhatIfAddExpense(w http.ResponseWriter, r *http.Request) {
 		Discretionary: discretionary,
 	}
 
-	settings, err := retirementMgr.AddExpenseSource(source)
+	settings, err := settingsFor(r).AddExpenseSource(source)
 	if err != nil {
 		renderError(w, "Failed to add expense: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -664,7 +838,7 @@ func handleWhatIfUpdateExpense(w http.ResponseWriter, r *http.Request) {
 	inflation := r.FormValue("inflation") == "on" || r.FormValue("inflation") == "true"
 	discretionary := r.FormValue("discretionary") == "on" || r.FormValue("discretionary") == "true"
 
-	settings, err := retirementMgr.UpdateExpenseSource(id, startYear, endYear, inflation, discretionary)
+	settings, err := settingsFor(r).UpdateExpenseSource(id, startYear, endYear, inflation, discretionary)
 	if err != nil {
 		renderError(w, "Failed to update expense: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -688,7 +862,7 @@ func handleWhatIfUpdateExpense(w http.ResponseWriter, r *http.Request) {
 func handleWhatIfDeleteExpense(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	settings, err := retirementMgr.RemoveExpenseSource(id)
+	settings, err := settingsFor(r).RemoveExpenseSource(id)
 	if err != nil {
 		renderError(w, "Failed to remove expense: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -712,7 +886,7 @@ func handleWhatIfDeleteExpense(w http.ResponseWriter, r *http.Request) {
 func handleWhatIfRestoreExpense(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	settings, err := retirementMgr.RestoreExpenseSource(id)
+	settings, err := settingsFor(r).RestoreExpenseSource(id)
 	if err != nil {
 		renderError(w, "Failed to restore expense: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -733,8 +907,11 @@ func handleWhatIfRestoreExpense(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// bucketColors are the projection chart's colors for portfolio buckets
+var bucketColors = []string{"#6366f1", "#22c55e", "#f59e0b", "#0ea5e9", "#ec4899", "#8b5cf6"}
+
 func handleWhatIfProjectionChart(w http.ResponseWriter, r *http.Request) {
-	settings, err := retirementMgr.Load()
+	settings, err := settingsFor(r).Load()
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -742,8 +919,11 @@ func handleWhatIfProjectionChart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	calc := retirement.NewCalculator(settings)
-	projection := calc.RunProjection()
+	engine, err := retirement.NewEngine(settings)
+	if err != nil {
+		engine = retirement.NewCalculator(settings)
+	}
+	projection := engine.Project()
 
 	// Build chart data
 	var years []float64
@@ -762,22 +942,49 @@ func handleWhatIfProjectionChart(w http.ResponseWriter, r *http.Request) {
 		lineColor = "#ef4444"
 	}
 
-	chartData := map[string]interface{}{
-		"data": []map[string]interface{}{
-			{
-				"type":      "scatter",
-				"mode":      "lines",
-				"name":      "Portfolio Balance",
-				"x":         years,
-				"y":         balances,
-				"fill":      "tozeroy",
-				"fillcolor": fillColor,
-				"line": map[string]interface{}{
-					"color": lineColor,
-					"width": 2,
-				},
+	traces := []map[string]interface{}{
+		{
+			"type":      "scatter",
+			"mode":      "lines",
+			"name":      "Portfolio Balance",
+			"x":         years,
+			"y":         balances,
+			"fill":      "tozeroy",
+			"fillcolor": fillColor,
+			"line": map[string]interface{}{
+				"color": lineColor,
+				"width": 2,
 			},
 		},
+	}
+
+	// Stack each bucket's balance when the portfolio is modeled as buckets
+	showLegend := false
+	if len(projection.Buckets) > 0 && len(projection.Months) > 0 && len(projection.Months[0].BucketBalances) == len(projection.Buckets) {
+		traces = traces[:0]
+		for i, b := range projection.Buckets {
+			var bucketBalances []float64
+			for _, m := range projection.Months {
+				bucketBalances = append(bucketBalances, m.BucketBalances[i])
+			}
+			traces = append(traces, map[string]interface{}{
+				"type":       "scatter",
+				"mode":       "lines",
+				"name":       b.Name,
+				"x":          years,
+				"y":          bucketBalances,
+				"stackgroup": "buckets",
+				"line": map[string]interface{}{
+					"color": bucketColors[i%len(bucketColors)],
+					"width": 1,
+				},
+			})
+		}
+		showLegend = true
+	}
+
+	chartData := map[string]interface{}{
+		"data": traces,
 		"layout": map[string]interface{}{
 			"title": "Portfolio Projection",
 			"xaxis": map[string]interface{}{
@@ -787,7 +994,7 @@ func handleWhatIfProjectionChart(w http.ResponseWriter, r *http.Request) {
 				"title":      "Balance ($)",
 				"tickformat": "$,.0f",
 			},
-			"showlegend": false,
+			"showlegend": showLegend,
 		},
 	}
 
@@ -796,20 +1003,31 @@ func handleWhatIfProjectionChart(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleWhatIfSync(w http.ResponseWriter, r *http.Request) {
-	settings, err := retirementMgr.Load()
-	if err != nil {
-		renderError(w, "Failed to load settings: "+err.Error(), http.StatusInternalServerError)
-		return
+	// A window chosen alongside the sync button is the user's setting, so
+	// it's saved as an entry of its own before the synced values
+	if months, exclude, ok := parseSyncWindow(r); ok {
+		_, err := settingsFor(r).Modify(func(s *models.WhatIfSettings) error {
+			s.SyncWindowMonths = months
+			s.SyncExcludeOneOffs = exclude
+			return nil
+		})
+		if err != nil {
+			renderError(w, "Failed to save settings: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
 	}
 
-	// Sync expenses and income from dashboard
-	if err := syncSettingsFromDashboard(settings); err != nil {
-		renderError(w, "Failed to sync from dashboard: "+err.Error(), http.StatusInternalServerError)
+	// Sync expenses and income from dashboard and save in one locked step
+	var syncErr error
+	settings, err := settingsFor(r).Sync(func(s *models.WhatIfSettings) error {
+		syncErr = syncSettingsFromDashboard(s)
+		return syncErr
+	})
+	if syncErr != nil {
+		renderError(w, "Failed to sync from dashboard: "+syncErr.Error(), http.StatusInternalServerError)
 		return
 	}
-
-	// Save the synced settings
-	if err := retirementMgr.Save(settings); err != nil {
+	if err != nil {
 		renderError(w, "Failed to save settings: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -830,15 +1048,14 @@ func handleWhatIfSync(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleWhatIfMonteCarlo(w http.ResponseWriter, r *http.Request) {
-	settings, err := retirementMgr.Load()
+	settings, err := settingsFor(r).Load()
 	if err != nil {
 		renderError(w, "Failed to load settings: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Re-run the full analysis which includes a fresh Monte Carlo simulation
-	calc := retirement.NewCalculator(settings)
-	analysis := calc.RunFullAnalysis()
+	analysis := analyze(settings)
 
 	partialData := map[string]interface{}{
 		"Settings": settings,
@@ -938,7 +1155,7 @@ func handleWhatIfAddHealthcare(w http.ResponseWriter, r *http.Request) {
 		MedicareEligibleAge:   65,
 	}
 
-	settings, err := retirementMgr.AddHealthcarePerson(person)
+	settings, err := settingsFor(r).AddHealthcarePerson(person)
 	if err != nil {
 		renderError(w, "Failed to add healthcare person: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -1052,7 +1269,7 @@ func handleWhatIfUpdateHealthcare(w http.ResponseWriter, r *http.Request) {
 		updates["aca_cost_after_employer"] = f
 	}
 
-	settings, err := retirementMgr.UpdateHealthcarePerson(id, updates)
+	settings, err := settingsFor(r).UpdateHealthcarePerson(id, updates)
 	if err != nil {
 		renderError(w, "Failed to update healthcare person: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -1076,7 +1293,7 @@ func handleWhatIfUpdateHealthcare(w http.ResponseWriter, r *http.Request) {
 func handleWhatIfDeleteHealthcare(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	settings, err := retirementMgr.RemoveHealthcarePerson(id)
+	settings, err := settingsFor(r).RemoveHealthcarePerson(id)
 	if err != nil {
 		renderError(w, "Failed to remove healthcare person: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -1104,30 +1321,18 @@ func syncSettingsFromDashboard(settings *models.WhatIfSettings) error {
 		return err
 	}
 
-	// Calculate average monthly values from last 12 months
+	// Average monthly expenses over the chosen window
 	now := time.Now()
-	yearAgo := now.AddDate(-1, 0, 0)
-	filtered := data.FilterByDateRange(yearAgo, now)
-	outflows := filtered.FilterByType(models.Outflow)
-
-	months := 12.0
-	if filtered.MinDate().After(yearAgo) {
-		months = now.Sub(filtered.MinDate()).Hours() / 24 / 30
-		if months < 1 {
-			months = 1
-		}
-	}
-
-	// Calculate and set average monthly expenses
-	totalExpenses := outflows.SumAbsAmount()
-	settings.MonthlyLivingExpenses = totalExpenses / months
+	settings.MonthlyLivingExpenses, _ = averageExpenses(data, now, settings.SyncWindow(), settings.SyncExcludeOneOffs)
 
-	// Use insights income pattern detection for individual income sources
+	// Use insights income pattern detection for individual income sources,
+	// over the last 12 months whatever the expense window
+	filtered := data.FilterByDateRange(now.AddDate(-1, 0, 0), now)
 	incomePatterns := insights.AnalyzeIncomePatterns(filtered)
 
 	// Remove old auto-detected sources (prefixed with "insights-" or old "dashboard-income")
 	// Keep user-added sources (no special prefix)
-	// BUT preserve user modifications (EndMonth, StartMonth, COLARate, Type) from existing insights sources
+	// BUT preserve user modifications (EndMonth, StartMonth, ages, COLARate, Type) from existing insights sources
 	userSources := make([]models.IncomeSource, 0)
 	existingMods := make(map[string]models.IncomeSource)
 
@@ -1171,6 +1376,8 @@ func syncSettingsFromDashboard(settings *models.WhatIfSettings) error {
 		if existing, ok := existingMods[id]; ok {
 			newSource.EndMonth = existing.EndMonth
 			newSource.StartMonth = existing.StartMonth
+			newSource.StartAge = existing.StartAge
+			newSource.EndAge = existing.EndAge
 			newSource.COLARate = existing.COLARate
 			newSource.InflationAdjusted = existing.InflationAdjusted
 			// Preserve Type only if user changed it from default
@@ -0,0 +1,136 @@
+package whatif
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/models"
+	"budget2/internal/services/retirement"
+	"budget2/internal/services/storage"
+)
+
+// setupHandlers wires the what-if routes to settings kept in memory, with
+// no renderer so partials come back as JSON
+func setupHandlers(t *testing.T) http.Handler {
+	t.Helper()
+	store, err := storage.NewMemory("/data")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	Initialize(nil, nil, retirement.NewSettingsManager("/data/settings", store), nil, nil, retirement.NewReadinessHistory("/data/settings", store))
+	r := chi.NewRouter()
+	RegisterRoutes(r)
+	return r
+}
+
+// results is the JSON form of the whatif-results partial
+type results struct {
+	Settings *models.WhatIfSettings `json:"Settings"`
+	Analysis *models.WhatIfAnalysis `json:"Analysis"`
+}
+
+// send makes a form request and decodes the results partial
+func send(t *testing.T, h http.Handler, method, path, form string) results {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(form))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s %s = %d: %s", method, path, rec.Code, rec.Body.String())
+	}
+	var res results
+	if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+		t.Fatalf("%s %s: invalid JSON: %v", method, path, err)
+	}
+	return res
+}
+
+// TestSettingsFlow verifies saved settings come back with the analysis and
+// its sanity warnings
+// TestAnalysisCacheKeepsEachSettings verifies switching between settings
+// reuses each one's cached analysis
+func TestAnalysisCacheKeepsEachSettings(t *testing.T) {
+	saved := models.DefaultWhatIfSettings()
+	sandbox := models.DefaultWhatIfSettings()
+	sandbox.MonthlyLivingExpenses = saved.MonthlyLivingExpenses + 1000
+
+	first := runAnalysisWithCache(saved)
+	runAnalysisWithCache(sandbox)
+	if again := runAnalysisWithCache(saved); again != first {
+		t.Error("analysis of the saved settings was recomputed after analysing other settings")
+	}
+}
+
+func TestSettingsFlow(t *testing.T) {
+	h := setupHandlers(t)
+
+	res := send(t, h, http.MethodPost, "/whatif/settings", "investment_return=25&inflation_rate=0&essential_floor=1500")
+	if res.Settings.InvestmentReturn != 25 || res.Settings.EssentialFloor != 1500 {
+		t.Errorf("settings = %.1f%% return, %.0f floor; want 25%% and 1500", res.Settings.InvestmentReturn, res.Settings.EssentialFloor)
+	}
+	fields := make(map[string]bool)
+	for _, w := range res.Analysis.Warnings {
+		fields[w.Field] = true
+	}
+	if !fields["investment_return"] || !fields["inflation_rate"] {
+		t.Errorf("warnings = %v, want the return and inflation flagged", res.Analysis.Warnings)
+	}
+
+	saved, err := retirementMgr.Load()
+	if err != nil || saved.InvestmentReturn != 25 {
+		t.Errorf("saved return = %v (%v), want 25", saved.InvestmentReturn, err)
+	}
+}
+
+// TestIncomeFlow verifies an income source can be added, removed and restored
+func TestIncomeFlow(t *testing.T) {
+	h := setupHandlers(t)
+
+	res := send(t, h, http.MethodPost, "/whatif/income", "name=Pension&amount=1500")
+	if len(res.Settings.IncomeSources) != 1 || res.Settings.IncomeSources[0].Name != "Pension" {
+		t.Fatalf("income sources = %+v, want the pension", res.Settings.IncomeSources)
+	}
+	id := res.Settings.IncomeSources[0].ID
+
+	res = send(t, h, http.MethodDelete, "/whatif/income/"+id, "")
+	if len(res.Settings.IncomeSources) != 0 || len(res.Settings.RemovedIncomeSources) != 1 {
+		t.Errorf("after delete: %d sources, %d removed", len(res.Settings.IncomeSources), len(res.Settings.RemovedIncomeSources))
+	}
+
+	res = send(t, h, http.MethodPost, "/whatif/income/"+id+"/restore", "")
+	if len(res.Settings.IncomeSources) != 1 || len(res.Settings.RemovedIncomeSources) != 0 {
+		t.Errorf("after restore: %d sources, %d removed", len(res.Settings.IncomeSources), len(res.Settings.RemovedIncomeSources))
+	}
+}
+
+// TestBucketFlow verifies buckets set the portfolio as they're added,
+// updated and removed
+func TestBucketFlow(t *testing.T) {
+	h := setupHandlers(t)
+
+	send(t, h, http.MethodPost, "/whatif/bucket", "name=Cash&value=100000&return=2")
+	res := send(t, h, http.MethodPost, "/whatif/bucket", "name=Stocks&value=300000&return=8")
+	if len(res.Settings.PortfolioBuckets) != 2 || res.Settings.PortfolioValue != 400000 {
+		t.Fatalf("buckets = %+v, portfolio %.0f; want two totalling 400000", res.Settings.PortfolioBuckets, res.Settings.PortfolioValue)
+	}
+	if res.Settings.PortfolioBuckets[1].Priority != 2 || len(res.Analysis.Projection.Buckets) != 2 {
+		t.Errorf("stocks priority %d with %d projected buckets; want 2 and 2", res.Settings.PortfolioBuckets[1].Priority, len(res.Analysis.Projection.Buckets))
+	}
+	cash := res.Settings.PortfolioBuckets[0].ID
+
+	res = send(t, h, http.MethodPut, "/whatif/bucket/"+cash, "value=50000&return=3&priority=5")
+	if res.Settings.PortfolioValue != 350000 || res.Settings.BucketWithdrawalOrder()[0] != 1 {
+		t.Errorf("after update portfolio %.0f, order %v", res.Settings.PortfolioValue, res.Settings.BucketWithdrawalOrder())
+	}
+
+	res = send(t, h, http.MethodDelete, "/whatif/bucket/"+cash, "")
+	if len(res.Settings.PortfolioBuckets) != 1 || res.Settings.PortfolioValue != 300000 {
+		t.Errorf("after delete %d buckets, portfolio %.0f", len(res.Settings.PortfolioBuckets), res.Settings.PortfolioValue)
+	}
+}
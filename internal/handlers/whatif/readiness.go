@@ -0,0 +1,98 @@
+package whatif
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"budget2/internal/services/pdf"
+	"budget2/internal/services/retirement"
+)
+
+// handleReadinessReport downloads the quarterly retirement readiness
+// summary: as a one-page PDF, as JSON with format=json, or as plain text
+// with format=text for the body of an email. Each report is remembered as
+// its quarter's snapshot, so the next quarter's shows what changed. A
+// report on a sandbox isn't remembered, since it isn't the plan.
+func handleReadinessReport(w http.ResponseWriter, r *http.Request) {
+	plan, sandboxed := sandboxFor(r)
+	if !sandboxed {
+		plan = retirementMgr
+	}
+	settings, err := plan.Load()
+	if err != nil {
+		http.Error(w, "Failed to load settings: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	previous, err := readinessLog.Previous(retirement.Quarter(now))
+	if err != nil {
+		log.Printf("Warning: failed to load readiness history: %v", err)
+	}
+	report := retirement.NewCalculator(settings).Readiness(now, previous, retirement.ReadinessRuns)
+	if !sandboxed {
+		if err := readinessLog.Record(report.ReadinessSnapshot); err != nil {
+			log.Printf("Warning: failed to save readiness snapshot: %v", err)
+		}
+	}
+	filename := "retirement_readiness_" + report.Quarter
+
+	switch r.URL.Query().Get("format") {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.json\"", filename))
+		json.NewEncoder(w).Encode(report)
+		return
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		retirement.WriteReadinessText(w, report)
+		return
+	}
+
+	doc := pdf.New()
+	doc.Title("Retirement Readiness, " + report.Quarter)
+	source := "the saved what-if plan"
+	if sandboxed {
+		source = "an unsaved what-if sandbox"
+	}
+	doc.Text("Generated " + report.Generated.Format("January 2, 2006 3:04 PM") + " from " + source + ". " +
+		"Success rates come from Monte Carlo simulations on the same random markets, so the risks compare like with like.")
+
+	doc.Heading("Where the plan stands")
+	widths := []float64{200, 304}
+	doc.Row(false, widths, "Sustainability score", fmt.Sprintf("%d (%s)", report.Score, report.Label))
+	change := "First report"
+	if report.Change != nil {
+		change = fmt.Sprintf("%+.1f points since %s", *report.Change, report.Previous.Quarter)
+	}
+	doc.Row(false, widths, "Success rate", fmt.Sprintf("%.1f%% (%s)", report.SuccessRate, change))
+	doc.Row(false, widths, "Median final balance", fmt.Sprintf("$%.0f", report.MedianBalance))
+
+	if len(report.Risks) > 0 {
+		doc.Heading("Biggest assumption risks")
+		widths = []float64{200, 100, 102, 102}
+		doc.Row(true, widths, "If", "Change", "Success rate", "Impact")
+		for _, risk := range report.Risks {
+			doc.Row(false, widths, risk.Name, risk.Change, fmt.Sprintf("%.1f%%", risk.SuccessRate), fmt.Sprintf("%+.1f points", risk.Impact))
+		}
+	}
+
+	doc.Heading("Recommended actions")
+	for _, action := range report.Actions {
+		doc.Text("- " + action)
+	}
+
+	if len(report.Warnings) > 0 {
+		doc.Heading("Flagged inputs")
+		for _, warning := range report.Warnings {
+			doc.Text("- " + warning.Message)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.pdf\"", filename))
+	doc.WriteTo(w)
+}
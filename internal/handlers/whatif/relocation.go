@@ -0,0 +1,118 @@
+package whatif
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"budget2/internal/models"
+	"budget2/internal/services/relocation"
+)
+
+// parseScenario reads a relocation scenario from form data
+func parseScenario(r *http.Request) (models.RelocationScenario, error) {
+	s := models.RelocationScenario{
+		ID:                  r.FormValue("id"),
+		State:               r.FormValue("state"),
+		CostOfLiving:        1,
+		TaxesSocialSecurity: r.FormValue("taxes_social_security") == "true",
+	}
+
+	var err error
+	if r.FormValue("cost_of_living") != "" {
+		if s.CostOfLiving, err = parseFormFloat(r, "cost_of_living"); err != nil {
+			return s, err
+		}
+	}
+	if s.IncomeTaxRate, err = parseFormFloat(r, "income_tax_rate"); err != nil {
+		return s, err
+	}
+	if s.RetirementExclusion, err = parseFormFloat(r, "retirement_exclusion"); err != nil {
+		return s, err
+	}
+	if s.PropertyTax, err = parseFormFloat(r, "property_tax"); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+func handleRelocationPartial(w http.ResponseWriter, r *http.Request) {
+	renderRelocation(w, r)
+}
+
+func handleSaveRelocation(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		renderError(w, "Invalid form data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	scenario, err := parseScenario(r)
+	if err != nil {
+		renderError(w, "Invalid relocation scenario: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := relocationMgr.Save(scenario); err != nil {
+		renderError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderRelocation(w, r)
+}
+
+func handleSetCurrentLocation(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		renderError(w, "Invalid form data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	current, err := parseScenario(r)
+	if err != nil {
+		renderError(w, "Invalid location: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := relocationMgr.SetCurrent(current); err != nil {
+		renderError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderRelocation(w, r)
+}
+
+func handleDeleteRelocation(w http.ResponseWriter, r *http.Request) {
+	if _, err := relocationMgr.Remove(chi.URLParam(r, "id")); err != nil {
+		renderError(w, "Failed to remove scenario: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderRelocation(w, r)
+}
+
+// renderRelocation renders the side-by-side relocation comparison
+func renderRelocation(w http.ResponseWriter, r *http.Request) {
+	plan, err := relocationMgr.Get()
+	if err != nil {
+		renderError(w, "Failed to load relocation scenarios: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	settings, err := settingsFor(r).Load()
+	if err != nil {
+		settings = models.DefaultWhatIfSettings()
+	}
+
+	outcomes, err := relocation.Compare(settings, plan)
+	if err != nil {
+		renderError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	partialData := map[string]interface{}{
+		"Plan":        plan,
+		"Outcomes":    outcomes,
+		"NewScenario": models.RelocationScenario{CostOfLiving: 1},
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "whatif-relocation", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
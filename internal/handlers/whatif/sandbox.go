@@ -0,0 +1,100 @@
+package whatif
+
+import (
+	"errors"
+	"net/http"
+
+	apphttp "budget2/internal/http"
+	"budget2/internal/services/retirement"
+)
+
+// sandboxCookie holds the browser's sandbox ID. It has no expiry, so the
+// sandbox ends with the browser session if it isn't committed first.
+const sandboxCookie = "budget_whatif_sandbox"
+
+// settingsFor returns the settings a request reads and edits: its
+// sandbox's while one is open, and the saved plan's otherwise
+func settingsFor(r *http.Request) *retirement.SettingsManager {
+	if sm, ok := sandboxFor(r); ok {
+		return sm
+	}
+	return retirementMgr
+}
+
+// sandboxFor returns the request's open sandbox
+func sandboxFor(r *http.Request) (*retirement.SettingsManager, bool) {
+	cookie, err := r.Cookie(sandboxCookie)
+	if err != nil || sandboxes == nil {
+		return nil, false
+	}
+	return sandboxes.Get(cookie.Value)
+}
+
+// handleSandboxOpen starts a sandbox from the saved settings. Edits go to
+// it until it's committed or discarded.
+func handleSandboxOpen(w http.ResponseWriter, r *http.Request) {
+	if _, ok := sandboxFor(r); !ok {
+		id, _, err := sandboxes.Open()
+		if err != nil {
+			renderError(w, "Failed to open sandbox: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		setSandboxCookie(w, r, id)
+	}
+	backToWhatIf(w, r)
+}
+
+// handleSandboxCommit saves the sandbox's settings as the plan
+func handleSandboxCommit(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(sandboxCookie)
+	if err != nil {
+		renderError(w, "No sandbox is open", http.StatusBadRequest)
+		return
+	}
+	if _, err := sandboxes.Commit(cookie.Value); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, retirement.ErrNoSandbox) {
+			status = http.StatusConflict
+		}
+		renderError(w, "Failed to commit sandbox: "+err.Error(), status)
+		return
+	}
+	setSandboxCookie(w, r, "")
+	backToWhatIf(w, r)
+}
+
+// handleSandboxDiscard drops the sandbox and its edits
+func handleSandboxDiscard(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sandboxCookie); err == nil {
+		sandboxes.Discard(cookie.Value)
+	}
+	setSandboxCookie(w, r, "")
+	backToWhatIf(w, r)
+}
+
+// setSandboxCookie points the browser at sandbox id, or clears the cookie
+// when id is empty
+func setSandboxCookie(w http.ResponseWriter, r *http.Request, id string) {
+	cookie := &http.Cookie{
+		Name:     sandboxCookie,
+		Value:    id,
+		Path:     "/whatif",
+		HttpOnly: true,
+		Secure:   apphttp.IsHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	}
+	if id == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(w, cookie)
+}
+
+// backToWhatIf reloads the what-if page after entering or leaving a sandbox
+func backToWhatIf(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", "/whatif")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	http.Redirect(w, r, "/whatif", http.StatusSeeOther)
+}
@@ -0,0 +1,97 @@
+package whatif
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"budget2/internal/handlers/insights"
+	"budget2/internal/models"
+	"budget2/internal/services/savings"
+)
+
+// savingsSuggestion suggests a per-payday transfer from the last year of
+// income and spending, projected against the request's portfolio settings
+// and the income growth seen across all the data
+func savingsSuggestion(r *http.Request) (*models.SavingsSuggestion, error) {
+	data, err := loader.LoadData()
+	if err != nil {
+		return nil, err
+	}
+	plan, err := savingsMgr.Get()
+	if err != nil {
+		return nil, err
+	}
+	settings, err := settingsFor(r).Load()
+	if err != nil {
+		settings = models.DefaultWhatIfSettings()
+	}
+
+	end := data.MaxDate()
+	lastYear := data.FilterByDateRange(end.AddDate(-1, 0, 0), end)
+	patterns := insights.AnalyzeIncomePatterns(lastYear)
+	spending := savings.MonthlySpending(data, end)
+	growth := savings.IncomeGrowthRate(insights.AnalyzeIncomePatterns(data))
+
+	return savings.Suggest(patterns, spending, plan, settings.PortfolioValue, settings.InvestmentReturn, growth, time.Now()), nil
+}
+
+func handleSavingsPartial(w http.ResponseWriter, r *http.Request) {
+	renderSavings(w, r)
+}
+
+func handleSavingsPlan(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		renderError(w, "Invalid form data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plan, err := savingsMgr.Get()
+	if err != nil {
+		renderError(w, "Failed to load savings plan: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if v, err := parseFormFloat(r, "emergency_fund"); err != nil {
+		renderError(w, "Invalid emergency fund balance: "+err.Error(), http.StatusBadRequest)
+		return
+	} else if r.FormValue("emergency_fund") != "" {
+		plan.EmergencyFund = v
+	}
+
+	if v, err := parseFormInt(r, "target_months"); err != nil {
+		renderError(w, "Invalid target months: "+err.Error(), http.StatusBadRequest)
+		return
+	} else if r.FormValue("target_months") != "" {
+		plan.TargetMonths = v
+	}
+
+	if v, err := parseFormFloat(r, "withdrawal_rate"); err != nil {
+		renderError(w, "Invalid withdrawal rate: "+err.Error(), http.StatusBadRequest)
+		return
+	} else if r.FormValue("withdrawal_rate") != "" {
+		plan.WithdrawalRate = v
+	}
+
+	if _, err := savingsMgr.Save(plan); err != nil {
+		renderError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderSavings(w, r)
+}
+
+// renderSavings renders the savings transfer suggestion card
+func renderSavings(w http.ResponseWriter, r *http.Request) {
+	suggestion, err := savingsSuggestion(r)
+	if err != nil {
+		renderError(w, "Error loading data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "whatif-savings-transfer", suggestion)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(suggestion)
+	}
+}
@@ -0,0 +1,43 @@
+package whatif
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"budget2/internal/models"
+	"budget2/internal/services/retirement"
+)
+
+// stressTestRuns is the number of simulations per side of a stress test
+const stressTestRuns = 500
+
+// handleStressTest lists the stress presets, running the one named in the
+// scenario query parameter
+func handleStressTest(w http.ResponseWriter, r *http.Request) {
+	var result *models.StressTestResult
+	if name := r.URL.Query().Get("scenario"); name != "" {
+		settings, err := settingsFor(r).Load()
+		if err != nil {
+			renderError(w, "Failed to load settings: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result, err = retirement.NewCalculator(settings).RunStressTest(name, stressTestRuns)
+		if err != nil {
+			renderError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	partialData := map[string]interface{}{
+		"Scenarios": retirement.StressScenarios(),
+		"Result":    result,
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "whatif-stress", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
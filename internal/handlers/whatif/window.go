@@ -0,0 +1,114 @@
+package whatif
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/analytics"
+	"budget2/internal/services/retirement"
+)
+
+// averageExpenses averages monthly spending over the months before now,
+// leaving out one-off purchases if asked, and returns the ones it left out.
+// Data that starts within the window is averaged over the months it covers.
+func averageExpenses(data *models.TransactionSet, now time.Time, months int, excludeOneOffs bool) (float64, []models.Transaction) {
+	start := now.AddDate(0, -months, 0)
+	filtered := data.FilterByDateRange(start, now)
+	outflows := filtered.FilterByType(models.Outflow)
+
+	span := float64(months)
+	if filtered.MinDate().After(start) {
+		span = max(now.Sub(filtered.MinDate()).Hours()/24/30, 1)
+	}
+
+	total := outflows.SumAbsAmount()
+	var oneOffs []models.Transaction
+	if excludeOneOffs {
+		oneOffs = analytics.OneOffPurchases(outflows)
+		for _, t := range oneOffs {
+			total -= math.Abs(t.Amount)
+		}
+	}
+	return total / span, oneOffs
+}
+
+// syncWindows syncs living expenses over each window, with and without
+// one-off purchases, and projects the plan with each result so the user can
+// see how much the choice of window moves it
+func syncWindows(settings *models.WhatIfSettings, data *models.TransactionSet, now time.Time) []models.SyncWindowResult {
+	var results []models.SyncWindowResult
+	for _, months := range models.SyncWindows {
+		for _, exclude := range []bool{false, true} {
+			expenses, oneOffs := averageExpenses(data, now, months, exclude)
+
+			modified := *settings
+			modified.IncomeSources = append([]models.IncomeSource{}, settings.IncomeSources...)
+			modified.ExpenseSources = append([]models.ExpenseSource{}, settings.ExpenseSources...)
+			modified.MonthlyLivingExpenses = expenses
+
+			calc := retirement.NewCalculator(&modified)
+			projection := calc.RunProjection()
+			score := calc.CalculateSustainabilityScore(projection)
+
+			result := models.SyncWindowResult{
+				Months:          months,
+				ExcludeOneOffs:  exclude,
+				Selected:        months == settings.SyncWindow() && exclude == settings.SyncExcludeOneOffs,
+				MonthlyExpenses: expenses,
+				OneOffs:         len(oneOffs),
+				Score:           score.Score,
+				Label:           score.Label,
+				RequiredRate:    calc.CalculateBudgetFit().RequiredRate,
+				Survives:        projection.Survives,
+				LongevityYears:  projection.LongevityYears,
+				FinalBalance:    projection.FinalBalance,
+			}
+			for _, t := range oneOffs {
+				result.OneOffTotal += math.Abs(t.Amount)
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// parseSyncWindow reads the sync options sent with the sync button,
+// reporting false when they're absent or not an offered window
+func parseSyncWindow(r *http.Request) (months int, excludeOneOffs bool, ok bool) {
+	months, err := strconv.Atoi(r.FormValue("sync_window_months"))
+	if err != nil || !slices.Contains(models.SyncWindows, months) {
+		return 0, false, false
+	}
+	return months, r.FormValue("sync_exclude_one_offs") != "", true
+}
+
+// handleSyncWindows compares the plan with living expenses synced over each
+// window
+func handleSyncWindows(w http.ResponseWriter, r *http.Request) {
+	settings, err := settingsFor(r).Load()
+	if err != nil {
+		renderError(w, "Failed to load settings: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err := loader.LoadData()
+	if err != nil {
+		renderError(w, "Failed to load data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	partialData := map[string]interface{}{
+		"Windows": syncWindows(settings, data, time.Now()),
+	}
+
+	if renderer != nil {
+		renderer.RenderPartial(w, "whatif-sync-windows", partialData)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(partialData)
+	}
+}
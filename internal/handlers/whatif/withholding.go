@@ -0,0 +1,42 @@
+package whatif
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"budget2/internal/services/retirement"
+)
+
+// handleWithholdingSchedule downloads each RMD year's suggested federal
+// withholding and quarterly estimated payments as CSV, or as JSON with
+// format=json
+func handleWithholdingSchedule(w http.ResponseWriter, r *http.Request) {
+	settings, err := settingsFor(r).Load()
+	if err != nil {
+		http.Error(w, "Failed to load settings: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rmd := retirement.NewCalculator(settings).CalculateRMDAnalysis()
+	now := time.Now()
+	filename := "rmd_withholding_" + now.Format("2006-01-02")
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.json\"", filename))
+		json.NewEncoder(w).Encode(rmd.Projections)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := retirement.WriteWithholdingCSV(&buf, rmd, now.Year()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.csv\"", filename))
+	w.Write(buf.Bytes())
+}
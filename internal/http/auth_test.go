@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"budget2/internal/config"
+)
+
+func TestRequireAPIKey(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	keys := []config.APIKey{
+		{Token: "reader", Scope: config.APIScopeRead},
+		{Token: "writer", Scope: config.APIScopeWrite},
+	}
+
+	tests := []struct {
+		name   string
+		keys   []config.APIKey
+		method string
+		header string
+		value  string
+		want   int
+	}{
+		{"no keys configured", nil, http.MethodPost, "", "", http.StatusNoContent},
+		{"missing key", keys, http.MethodGet, "", "", http.StatusUnauthorized},
+		{"wrong key", keys, http.MethodGet, "Authorization", "Bearer nope", http.StatusUnauthorized},
+		{"bare authorization", keys, http.MethodGet, "Authorization", "reader", http.StatusUnauthorized},
+		{"read key reads", keys, http.MethodGet, "Authorization", "Bearer reader", http.StatusNoContent},
+		{"read key writes", keys, http.MethodPost, "Authorization", "Bearer reader", http.StatusForbidden},
+		{"write key writes", keys, http.MethodDelete, "X-API-Key", "writer", http.StatusNoContent},
+		{"write key reads", keys, http.MethodHead, "X-API-Key", "writer", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/metrics", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			RequireAPIKey(tt.keys)(ok).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
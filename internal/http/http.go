@@ -3,8 +3,13 @@ package http
 import (
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"budget2/internal/models"
+	"budget2/internal/services/dataloader"
 	"budget2/internal/templates"
 )
 
@@ -59,3 +64,82 @@ func ParseDateRange(startStr, endStr string, minDate, maxDate time.Time) (start,
 
 	return start, end
 }
+
+// ParseCategoryFilter reads the category filter query parameters. category and
+// exclude may be repeated; uncategorized=true limits results to blank categories.
+func ParseCategoryFilter(q url.Values) models.CategoryFilter {
+	return models.CategoryFilter{
+		Include:       nonEmpty(q["category"]),
+		Exclude:       nonEmpty(q["exclude"]),
+		Uncategorized: q.Get("uncategorized") == "true" || q.Get("uncategorized") == "on",
+	}
+}
+
+// nonEmpty drops blank values, such as the "All Categories" option
+func nonEmpty(values []string) []string {
+	var result []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// ParseAccounts reads the account query parameter, which limits a view to
+// the named accounts. It may be repeated.
+func ParseAccounts(q url.Values) []string {
+	return nonEmpty(q["account"])
+}
+
+// ParseSources reads the sources query parameter, which limits a view to
+// specific CSV files. It may be repeated or comma-separated.
+func ParseSources(q url.Values) []string {
+	var sources []string
+	for _, v := range q["sources"] {
+		sources = append(sources, nonEmpty(strings.Split(v, ","))...)
+	}
+	return sources
+}
+
+// LookupTransactions returns data's transactions between start and end in
+// any of categories. Requests over every enabled file are answered by the
+// loader's date and category indexes; ones naming files filter data, which
+// the caller loaded for just those files and the request's accounts.
+func LookupTransactions(loader *dataloader.DataLoader, q url.Values, data *models.TransactionSet, start, end time.Time, categories []string) *models.TransactionSet {
+	if len(ParseSources(q)) == 0 && loader != nil {
+		found, err := loader.Lookup(start, end, categories)
+		if err == nil {
+			if accounts := ParseAccounts(q); len(accounts) > 0 {
+				found = found.FilterByAccounts(accounts)
+			}
+			return found
+		}
+		log.Printf("Warning: transaction lookup failed: %v", err)
+	}
+	found := data.FilterByDateRange(start, end)
+	if len(categories) > 0 {
+		found = found.FilterByCategories(models.CategoryFilter{Include: categories})
+	}
+	return found
+}
+
+// DefaultPerPage and MaxPerPage bound the page size of paginated JSON APIs
+const (
+	DefaultPerPage = 50
+	MaxPerPage     = 500
+)
+
+// ParsePage reads the page and perPage query parameters, defaulting to the
+// first page of DefaultPerPage and capping the size at MaxPerPage
+func ParsePage(q url.Values) (page, perPage int) {
+	page, _ = strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ = strconv.Atoi(q.Get("perPage"))
+	if perPage < 1 {
+		perPage = DefaultPerPage
+	}
+	return page, min(perPage, MaxPerPage)
+}
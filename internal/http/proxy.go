@@ -0,0 +1,101 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustProxies takes a request's client address and scheme from the
+// X-Forwarded-For and X-Forwarded-Proto headers when it comes from one of
+// the trusted proxies. The client is the nearest address in
+// X-Forwarded-For that isn't itself a trusted proxy, so a client can't
+// pose as another by sending the header itself. Requests from anywhere else
+// have the headers removed. With no proxies configured every request
+// passes untouched.
+func TrustProxies(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(trusted) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isTrusted(trusted, remoteAddr(r.RemoteAddr)) {
+				r.Header.Del("X-Forwarded-For")
+				r.Header.Del("X-Forwarded-Proto")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if client, ok := forwardedClient(trusted, r.Header.Values("X-Forwarded-For")); ok {
+				r.RemoteAddr = client.String()
+			}
+			if proto := lastValue(r.Header.Values("X-Forwarded-Proto")); strings.EqualFold(proto, "https") {
+				r.URL.Scheme = "https"
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IsHTTPS reports whether the client reached the server over HTTPS, directly
+// or through a trusted proxy
+func IsHTTPS(r *http.Request) bool {
+	return r.TLS != nil || r.URL.Scheme == "https"
+}
+
+// forwardedClient walks X-Forwarded-For from the nearest hop back, returning
+// the first address that isn't a trusted proxy, or the farthest address
+// when every hop is trusted
+func forwardedClient(trusted []netip.Prefix, headers []string) (netip.Addr, bool) {
+	var hops []string
+	for _, h := range headers {
+		hops = append(hops, strings.Split(h, ",")...)
+	}
+
+	var client netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap()
+		if !isTrusted(trusted, client) {
+			break
+		}
+	}
+	return client, client.IsValid()
+}
+
+// remoteAddr parses the address of a request's peer
+func remoteAddr(hostport string) netip.Addr {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	addr, _ := netip.ParseAddr(host)
+	return addr.Unmap()
+}
+
+// isTrusted reports whether addr is one of the trusted proxies
+func isTrusted(trusted []netip.Prefix, addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastValue returns the last comma-separated value of a header, the one the
+// nearest proxy added
+func lastValue(headers []string) string {
+	if len(headers) == 0 {
+		return ""
+	}
+	values := strings.Split(headers[len(headers)-1], ",")
+	return strings.TrimSpace(values[len(values)-1])
+}
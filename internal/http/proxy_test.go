@@ -0,0 +1,63 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"budget2/internal/config"
+)
+
+func TestTrustProxies(t *testing.T) {
+	trusted := config.ParseTrustedProxies("127.0.0.1, 10.0.0.0/8, bogus")
+	if len(trusted) != 2 {
+		t.Fatalf("parsed %d proxies, want 2", len(trusted))
+	}
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded []string
+		proto     string
+		wantAddr  string
+		wantHTTPS bool
+	}{
+		{"direct client", "192.168.1.5:4000", nil, "", "192.168.1.5:4000", false},
+		{"untrusted peer sends headers", "192.168.1.5:4000", []string{"1.2.3.4"}, "https", "192.168.1.5:4000", false},
+		{"trusted proxy", "127.0.0.1:5000", []string{"192.168.1.5"}, "https", "192.168.1.5", true},
+		{"chain of proxies", "127.0.0.1:5000", []string{"192.168.1.5, 10.1.2.3"}, "http", "192.168.1.5", false},
+		{"spoofed hop before the client", "127.0.0.1:5000", []string{"6.6.6.6, 192.168.1.5"}, "", "192.168.1.5", false},
+		{"repeated headers", "10.0.0.2:5000", []string{"192.168.1.5", "10.0.0.9"}, "HTTPS", "192.168.1.5", true},
+		{"only proxies", "127.0.0.1:5000", []string{"10.0.0.1"}, "", "10.0.0.1", false},
+		{"garbage", "127.0.0.1:5000", []string{"unknown"}, "", "127.0.0.1:5000", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAddr, gotFor string
+			var gotHTTPS bool
+			handler := TrustProxies(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotAddr, gotFor, gotHTTPS = r.RemoteAddr, r.Header.Get("X-Forwarded-For"), IsHTTPS(r)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/dashboard", nil)
+			req.RemoteAddr = tt.remote
+			for _, f := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", f)
+			}
+			if tt.proto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if gotAddr != tt.wantAddr {
+				t.Errorf("RemoteAddr = %q, want %q", gotAddr, tt.wantAddr)
+			}
+			if gotHTTPS != tt.wantHTTPS {
+				t.Errorf("IsHTTPS = %v, want %v", gotHTTPS, tt.wantHTTPS)
+			}
+			if tt.remote == "192.168.1.5:4000" && gotFor != "" {
+				t.Errorf("X-Forwarded-For from an untrusted peer was kept: %q", gotFor)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+// Account types say what kind of account transactions come from, which
+// changes how their credits are classified
+const (
+	// AccountBank credits are income when they look like income
+	AccountBank = "bank"
+	// AccountCreditCard credits are refunds or payments, never income
+	AccountCreditCard = "credit_card"
+	// AccountInvestment credits are income only as dividends or interest;
+	// contributions and sales proceeds aren't
+	AccountInvestment = "investment"
+)
+
+// AccountTypes lists the account types in the order offered to users
+var AccountTypes = []string{AccountBank, AccountCreditCard, AccountInvestment}
+
+// Account is a bank, card or investment account. Its transactions come from
+// the data files mapped to it, or from rows whose account column names it.
+type Account struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"` // One of AccountTypes
+	Institution string   `json:"institution,omitempty"`
+	Files       []string `json:"files,omitempty"` // Data files holding only this account's transactions
+}
+
+// AccountSummary is an account with what the loaded data holds for it
+type AccountSummary struct {
+	Account
+	Defined      bool    `json:"defined"` // Set up by the user rather than named by a file or column
+	Transactions int     `json:"transactions"`
+	Income       float64 `json:"income"`
+	Spending     float64 `json:"spending"`
+	FirstDate    string  `json:"first_date,omitempty"` // YYYY-MM-DD
+	LastDate     string  `json:"last_date,omitempty"`  // YYYY-MM-DD
+}
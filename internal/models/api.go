@@ -0,0 +1,20 @@
+package models
+
+// Pagination describes the page a paginated /api/v1 response holds
+type Pagination struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	Total      int `json:"total"` // Items matching the filters, across all pages
+	TotalPages int `json:"total_pages"`
+}
+
+// TransactionPage is one page of the transactions matching the explorer's
+// filters, served by /api/v1/transactions. The totals cover every page.
+type TransactionPage struct {
+	Start         string        `json:"start"` // YYYY-MM-DD
+	End           string        `json:"end"`   // YYYY-MM-DD
+	Transactions  []Transaction `json:"transactions"`
+	TotalIncome   float64       `json:"total_income"`
+	TotalExpenses float64       `json:"total_expenses"`
+	Pagination    Pagination    `json:"pagination"`
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Where a what-if input's value came from
+const (
+	AssumptionDefault = "default" // Built in, never changed
+	AssumptionUser    = "user"    // Entered on the what-if page
+	AssumptionSynced  = "synced"  // Derived from transaction data by a sync
+)
+
+// SettingChange records the last change to a what-if input
+type SettingChange struct {
+	Source string    `json:"source"` // AssumptionUser or AssumptionSynced
+	At     time.Time `json:"at"`
+}
+
+// Assumption is one input to the what-if projections, for the assumptions
+// report
+type Assumption struct {
+	Group   string     `json:"group"`
+	Name    string     `json:"name"`
+	Key     string     `json:"key"` // Setting key, "" for built-in inputs
+	Value   string     `json:"value"`
+	Source  string     `json:"source"`
+	Changed *time.Time `json:"changed,omitempty"` // nil when unknown or never changed
+}
+
+// AssumptionsReport lists every what-if input with its provenance
+type AssumptionsReport struct {
+	Generated   time.Time    `json:"generated"`
+	Assumptions []Assumption `json:"assumptions"`
+}
+
+// settingLists are the list settings whose entries are tracked one by one,
+// keyed "<list>/<id>"
+var settingLists = map[string]bool{
+	"income_sources":     true,
+	"expense_sources":    true,
+	"healthcare_persons": true,
+	"portfolio_buckets":  true,
+}
+
+// untrackedSettings are stored keys that aren't projection inputs
+var untrackedSettings = map[string]bool{
+	"schema_version":          true,
+	"changes":                 true,
+	"removed_income_sources":  true,
+	"removed_expense_sources": true,
+}
+
+// SettingKeys returns each input's stored value by setting key: the JSON
+// name of a scalar setting, or "<list>/<id>" for an income source, expense
+// source, healthcare person or portfolio bucket
+func (s *WhatIfSettings) SettingKeys() map[string]string {
+	values := make(map[string]string)
+	data, err := json.Marshal(s)
+	if err != nil {
+		return values
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return values
+	}
+	for key, raw := range fields {
+		if untrackedSettings[key] {
+			continue
+		}
+		if !settingLists[key] {
+			values[key] = string(raw)
+			continue
+		}
+		var entries []map[string]json.RawMessage
+		json.Unmarshal(raw, &entries)
+		for _, entry := range entries {
+			var id string
+			json.Unmarshal(entry["id"], &id)
+			encoded, _ := json.Marshal(entry)
+			values[key+"/"+id] = string(encoded)
+		}
+	}
+	return values
+}
+
+// RecordChanges stamps every input that differs from before with source
+// and at, and forgets list entries that were removed
+func (s *WhatIfSettings) RecordChanges(before *WhatIfSettings, source string, at time.Time) {
+	old := before.SettingKeys()
+	current := s.SettingKeys()
+	for key, value := range current {
+		if previous, ok := old[key]; ok && previous == value {
+			continue
+		}
+		if s.Changes == nil {
+			s.Changes = make(map[string]SettingChange)
+		}
+		s.Changes[key] = SettingChange{Source: source, At: at}
+	}
+	for key := range s.Changes {
+		if _, ok := current[key]; !ok {
+			delete(s.Changes, key)
+		}
+	}
+}
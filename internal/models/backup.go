@@ -0,0 +1,32 @@
+package models
+
+// BackupFile is one file in a backup archive
+type BackupFile struct {
+	Name        string `json:"name"`
+	Size        int64  `json:"size"`
+	CurrentSize int64  `json:"current_size,omitempty"` // Size of the file it would replace
+}
+
+// BackupProblem is an archive entry that failed verification
+type BackupProblem struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// BackupReport is what verifying a backup archive found: whether its files
+// read back intact, and what restoring it would change
+type BackupReport struct {
+	Filename  string          `json:"filename"`
+	Entries   int             `json:"entries"`   // Files in the archive
+	Added     []BackupFile    `json:"added"`     // Data files a restore would create
+	Replaced  []BackupFile    `json:"replaced"`  // Data files a restore would overwrite with different content
+	Unchanged []BackupFile    `json:"unchanged"` // Data files identical to the current ones
+	Settings  []BackupFile    `json:"settings"`  // Settings files that passed their checks; a restore leaves settings alone
+	Skipped   []string        `json:"skipped"`   // Other entries a restore ignores
+	Problems  []BackupProblem `json:"problems"`  // Entries that are corrupt or don't match their schema
+}
+
+// OK reports whether every entry passed verification
+func (r BackupReport) OK() bool {
+	return len(r.Problems) == 0
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// BankSyncStatus is how automatic transaction sync last went, by provider
+// and by account
+type BankSyncStatus struct {
+	Providers []BankSyncProvider `json:"providers"`
+	Accounts  []BankSyncAccount  `json:"accounts"`
+}
+
+// BankSyncProvider is the last sync with one aggregator
+type BankSyncProvider struct {
+	Name        string    `json:"name"`
+	LastRun     time.Time `json:"last_run"`
+	LastSuccess time.Time `json:"last_success"`
+	Error       string    `json:"error,omitempty"` // Why the last run failed, or connections that need attention
+}
+
+// BankSyncAccount is an account whose transactions sync into a data file
+type BankSyncAccount struct {
+	ID          string    `json:"id"` // The provider's account ID
+	Provider    string    `json:"provider"`
+	Name        string    `json:"name"`
+	Institution string    `json:"institution,omitempty"`
+	File        string    `json:"file"`              // Data file the transactions are written to
+	Balance     *float64  `json:"balance,omitempty"` // As the provider last reported it
+	LastSync    time.Time `json:"last_sync"`         // Last successful sync
+	Added       int       `json:"added"`             // Transactions the last successful sync added
+	Error       string    `json:"error,omitempty"`   // Why the last sync failed
+}
+
+// Label names the account with its institution
+func (a BankSyncAccount) Label() string {
+	if a.Institution == "" {
+		return a.Name
+	}
+	return a.Institution + " " + a.Name
+}
+
+// Account returns the synced account written to file, or nil
+func (s *BankSyncStatus) Account(file string) *BankSyncAccount {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Accounts {
+		if s.Accounts[i].File == file {
+			return &s.Accounts[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package models
+
+// BillForecast is the recurring payments due in the days after the latest
+// transaction, with the ones that have been missed
+type BillForecast struct {
+	AsOf         string         `json:"as_of"`   // Latest transaction date
+	Through      string         `json:"through"` // Last day forecast
+	Days         int            `json:"days"`
+	Bills        []UpcomingBill `json:"bills"` // By due date, so missed ones come first
+	Total        float64        `json:"total"` // Expected from AsOf through Through
+	OverdueTotal float64        `json:"overdue_total"`
+	Weeks        [][]BillDay    `json:"weeks"` // Calendar of whole weeks covering the forecast
+}
+
+// Overdue returns the bills that have been missed
+func (f *BillForecast) Overdue() []UpcomingBill {
+	var overdue []UpcomingBill
+	for _, b := range f.Bills {
+		if b.Overdue {
+			overdue = append(overdue, b)
+		}
+	}
+	return overdue
+}
+
+// BillDay is one day of a bill calendar
+type BillDay struct {
+	Date    string         `json:"date"`
+	Day     int            `json:"day"`      // Day of the month
+	Weekday string         `json:"weekday"`  // "Mon"
+	InRange bool           `json:"in_range"` // Within the forecast rather than padding out its weeks
+	Today   bool           `json:"today"`    // The forecast's AsOf
+	Bills   []UpcomingBill `json:"bills,omitempty"`
+	Total   float64        `json:"total,omitempty"`
+}
@@ -0,0 +1,50 @@
+package models
+
+// Budget levels, named like the watchlist's
+const (
+	BudgetOK      = WatchOK
+	BudgetWarning = WatchWarning // Approaching the target
+	BudgetOver    = WatchOver    // Target exceeded
+)
+
+// BudgetStatus is a category's month-to-date spending against its budget
+type BudgetStatus struct {
+	CategoryBudget
+	Spent            float64 `json:"spent"`
+	Remaining        float64 `json:"remaining"`
+	Percent          float64 `json:"percent"` // Spent as a share of the limit, 0-100+
+	TransactionCount int     `json:"transaction_count"`
+	Level            string  `json:"level"`
+}
+
+// BudgetSummary is every category budget for one month
+type BudgetSummary struct {
+	Month        string         `json:"month"`         // e.g. "Mar 2025"
+	Start        string         `json:"start"`         // YYYY-MM-DD
+	End          string         `json:"end"`           // Last data date in the month, YYYY-MM-DD
+	MonthPercent float64        `json:"month_percent"` // Share of the month elapsed by End, 0-100
+	TotalBudget  float64        `json:"total_budget"`
+	TotalSpent   float64        `json:"total_spent"`
+	Budgets      []BudgetStatus `json:"budgets"`
+}
+
+// BudgetActual is a category's spending against its budget in one month
+type BudgetActual struct {
+	Month    string  `json:"month"` // YYYY-MM
+	Category string  `json:"category"`
+	Budget   float64 `json:"budget"`
+	Actual   float64 `json:"actual"`
+	Variance float64 `json:"variance"` // Budget less actual, negative when over
+	Percent  float64 `json:"percent"`  // Actual as a share of the budget, 0-100+
+	Partial  bool    `json:"partial"`  // The range or the data covers only part of the month
+}
+
+// BudgetActuals is every category budget against actual spending, month by
+// month, as flat rows for charting tools
+type BudgetActuals struct {
+	Start       string         `json:"start"` // YYYY-MM-DD
+	End         string         `json:"end"`   // YYYY-MM-DD
+	TotalBudget float64        `json:"total_budget"`
+	TotalActual float64        `json:"total_actual"`
+	Rows        []BudgetActual `json:"rows"`
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// CategorySeparator divides a category from its subcategories, as in
+// "Food:Groceries", the form Quicken exports subcategories in
+const CategorySeparator = ":"
+
+// CategoryStyle is the display color and icon for a spending category, so a
+// category looks the same in every chart and table
+type CategoryStyle struct {
+	Category string `json:"category"`
+	Color    string `json:"color"`  // Hex color, e.g. "#22c55e"
+	Icon     string `json:"icon"`   // Short emoji or symbol
+	Custom   bool   `json:"custom"` // Set by the user rather than auto-assigned
+}
+
+// Category rule match types
+const (
+	RuleContains = "contains" // Case-insensitive substring of the description
+	RuleRegex    = "regex"    // Go regular expression against the description
+)
+
+// CategoryRule assigns a category to transactions meeting all of its set
+// conditions. Rules are tried in order and the first match wins.
+type CategoryRule struct {
+	ID        string    `json:"id"`
+	Match     string    `json:"match"`      // RuleContains or RuleRegex
+	Pattern   string    `json:"pattern"`    // Blank matches any description
+	MinAmount float64   `json:"min_amount"` // Smallest absolute amount; zero for no minimum
+	MaxAmount float64   `json:"max_amount"` // Largest absolute amount; zero for no maximum
+	Category  string    `json:"category"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CategoryRuleStatus is a rule with how many loaded transactions it
+// categorized
+type CategoryRuleStatus struct {
+	CategoryRule
+	Matches int `json:"matches"`
+}
+
+// CategoryOverride is a category the user set by hand on one transaction,
+// kept by transaction hash so it survives reloading the files
+type CategoryOverride struct {
+	Category  string    `json:"category"`
+	Original  string    `json:"original"` // Category before the override, for display and reverting
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CategoryNode is one category in the hierarchy, such as Food above
+// Food:Groceries and Food:Restaurants, with its rollup total
+type CategoryNode struct {
+	Category string  `json:"category"` // Full path, e.g. "Food:Groceries"
+	Name     string  `json:"name"`     // Last part of the path, e.g. "Groceries"
+	Parent   string  `json:"parent"`   // Full path of the parent; blank at the top
+	Depth    int     `json:"depth"`    // 0 at the top
+	Own      float64 `json:"own"`      // Total of transactions in exactly this category
+	Total    float64 `json:"total"`    // Own plus every subcategory's total
+	Leaf     bool    `json:"leaf"`     // Has no subcategories
+}
+
+// CategoryPath splits a category into its levels, dropping blank ones, so
+// "Food : Groceries" is Food then Groceries. A blank category is
+// Uncategorized.
+func CategoryPath(category string) []string {
+	var path []string
+	for _, part := range strings.Split(category, CategorySeparator) {
+		if part = strings.TrimSpace(part); part != "" {
+			path = append(path, part)
+		}
+	}
+	if len(path) == 0 {
+		return []string{"Uncategorized"}
+	}
+	return path
+}
+
+// ParentCategory returns the category a subcategory belongs to, or "" for a
+// top-level category
+func ParentCategory(category string) string {
+	path := CategoryPath(category)
+	return strings.Join(path[:len(path)-1], CategorySeparator)
+}
+
+// InCategory reports whether category is parent or one of its
+// subcategories at any depth, ignoring case
+func InCategory(category, parent string) bool {
+	path, within := CategoryPath(category), CategoryPath(parent)
+	if len(within) > len(path) {
+		return false
+	}
+	for i, part := range within {
+		if !strings.EqualFold(path[i], part) {
+			return false
+		}
+	}
+	return true
+}
+
+// BuildCategoryTree arranges category totals into the hierarchy, adding
+// the parents no transaction is filed under directly. Nodes come parents
+// first, then their subcategories, each level sorted by name ignoring case.
+// Paths differing only in case are one node.
+func BuildCategoryTree(totals map[string]float64) []CategoryNode {
+	names := make([]string, 0, len(totals))
+	for name := range totals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	nodes := make(map[string]*CategoryNode)
+	children := make(map[string][]string)
+	for _, name := range names {
+		path := CategoryPath(name)
+		parent := ""
+		for depth := range path {
+			full := strings.Join(path[:depth+1], CategorySeparator)
+			k := strings.ToLower(full)
+			node, ok := nodes[k]
+			if !ok {
+				node = &CategoryNode{Category: full, Name: path[depth], Parent: parent, Depth: depth, Leaf: true}
+				nodes[k] = node
+				children[strings.ToLower(parent)] = append(children[strings.ToLower(parent)], k)
+				if parent != "" {
+					nodes[strings.ToLower(parent)].Leaf = false
+				}
+			}
+			node.Total += totals[name]
+			if depth == len(path)-1 {
+				node.Own += totals[name]
+			}
+			parent = node.Category
+		}
+	}
+
+	tree := make([]CategoryNode, 0, len(nodes))
+	var walk func(parent string)
+	walk = func(parent string) {
+		keys := children[parent]
+		sort.Slice(keys, func(i, j int) bool {
+			return strings.ToLower(nodes[keys[i]].Name) < strings.ToLower(nodes[keys[j]].Name)
+		})
+		for _, k := range keys {
+			tree = append(tree, *nodes[k])
+			walk(k)
+		}
+	}
+	walk("")
+	return tree
+}
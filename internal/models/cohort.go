@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Cohort is a saved filter naming a group of transactions, such as "Kids"
+// for tag:kids OR category:Childcare, that insights can be scoped to
+type Cohort struct {
+	ID        string    `json:"id"` // From the name, e.g. "kids"; the view in /insights?view=kids
+	Name      string    `json:"name"`
+	Filter    string    `json:"filter"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CohortStatus is a cohort with how many loaded transactions it matches
+type CohortStatus struct {
+	Cohort
+	Matches int `json:"matches"`
+}
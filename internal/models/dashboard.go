@@ -17,6 +17,58 @@ type DashboardMetrics struct {
 	ExpensesTrend []float64 `json:"expenses_trend"`
 	SavingsTrend  []float64 `json:"savings_trend"`
 	TrendLabels   []string  `json:"trend_labels"` // Month labels
+	TrendMonths   int       `json:"trend_months"` // Sparkline window
+
+	// Year-over-year overlays: the same months a year earlier, zero where
+	// there is no data
+	IncomeTrendYoY   []float64 `json:"income_trend_yoy,omitempty"`
+	ExpensesTrendYoY []float64 `json:"expenses_trend_yoy,omitempty"`
+	SavingsTrendYoY  []float64 `json:"savings_trend_yoy,omitempty"`
+	HasYoY           bool      `json:"has_yoy"`
+}
+
+// Budget sources for MonthStatus
+const (
+	BudgetConfigured = "configured" // Set with BUDGET_MONTHLY_BUDGET
+	BudgetAverage    = "average"    // Average spending over the prior 12 months
+)
+
+// MonthStatus is a small summary of the latest month's spending, served to
+// home dashboards by /api/status
+type MonthStatus struct {
+	Month             string  `json:"month"` // "2025-12"
+	AsOf              string  `json:"as_of"` // Latest transaction date
+	Spent             float64 `json:"spent"`
+	Income            float64 `json:"income"`
+	SavingsRate       float64 `json:"savings_rate"`
+	Budget            float64 `json:"budget"`
+	BudgetSource      string  `json:"budget_source,omitempty"` // BudgetConfigured or BudgetAverage; empty without a budget
+	BudgetUsedPercent float64 `json:"budget_used_percent"`
+	Remaining         float64 `json:"remaining"`
+	MonthElapsed      float64 `json:"month_elapsed_percent"` // Share of the month's days through AsOf
+	OnTrack           bool    `json:"on_track"`              // Spent no faster than the month has elapsed
+}
+
+// Briefing is a short spoken summary of the current month for voice
+// assistants, served by /api/v1/briefing
+type Briefing struct {
+	Speech       string         `json:"speech"` // The whole briefing as plain sentences
+	Status       *MonthStatus   `json:"status"`
+	TypicalSpent float64        `json:"typical_spent"` // Average spent by the same day of the prior 12 months
+	Upcoming     []UpcomingBill `json:"upcoming_bills"`
+	Alerts       []string       `json:"alerts"` // Alert titles, most severe first
+}
+
+// UpcomingBill is a recurring payment expected soon, or one whose expected
+// date has passed without it
+type UpcomingBill struct {
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+	Due         string  `json:"due"` // "2026-01-05"
+	Merchant    string  `json:"merchant,omitempty"`
+	Frequency   string  `json:"frequency,omitempty"`
+	Overdue     bool    `json:"overdue,omitempty"`      // Missed: no payment since it was due
+	DaysOverdue int     `json:"days_overdue,omitempty"` // Days past due as of the latest transaction
 }
 
 // PeriodComparison holds metrics for two periods for comparison
@@ -25,11 +77,51 @@ type PeriodComparison struct {
 	Previous   *DashboardMetrics `json:"previous"`
 	HasData    bool              `json:"has_data"`
 
-	// Percentage changes
+	// Percentage changes, per day or per paycheck when normalized
 	IncomeChange      float64 `json:"income_change_pct"`
 	ExpensesChange    float64 `json:"expenses_change_pct"`
 	SavingsChange     float64 `json:"savings_change_pct"`
 	SavingsRateChange float64 `json:"savings_rate_change_pp"` // percentage points
+
+	// Normalization is "" (totals), "day" or "paycheck". Units are the days
+	// or paychecks in each period (1 for totals), and the amounts below are
+	// the period totals divided by them.
+	Normalization    string  `json:"normalization"`
+	CurrentUnits     float64 `json:"current_units"`
+	PreviousUnits    float64 `json:"previous_units"`
+	CurrentIncome    float64 `json:"current_income"`
+	PreviousIncome   float64 `json:"previous_income"`
+	CurrentExpenses  float64 `json:"current_expenses"`
+	PreviousExpenses float64 `json:"previous_expenses"`
+}
+
+// SavingsRateDecomposition explains the change in savings rate between two
+// periods: the income effect is the change had spending stayed the same, and
+// each category's effect is its spending change as a share of the current
+// income, so the effects add up to the change. Effects are in percentage
+// points.
+type SavingsRateDecomposition struct {
+	PreviousRate   float64            `json:"previous_rate"`
+	CurrentRate    float64            `json:"current_rate"`
+	PreviousIncome float64            `json:"previous_income"`
+	CurrentIncome  float64            `json:"current_income"`
+	IncomeEffect   float64            `json:"income_effect_pp"`
+	Categories     []RateContribution `json:"categories"`     // largest effects first
+	OtherEffect    float64            `json:"other_effect_pp"` // the remaining categories combined
+	OtherCount     int                `json:"other_count"`
+}
+
+// Change returns the change in savings rate, in percentage points
+func (d *SavingsRateDecomposition) Change() float64 {
+	return d.CurrentRate - d.PreviousRate
+}
+
+// RateContribution is one category's part in a savings rate change
+type RateContribution struct {
+	Category string  `json:"category"`
+	Previous float64 `json:"previous"` // spending in each period
+	Current  float64 `json:"current"`
+	Effect   float64 `json:"effect_pp"`
 }
 
 // SecondaryMetrics contains additional dashboard metrics
@@ -45,14 +137,17 @@ type SecondaryMetrics struct {
 
 // SpendingAlert represents a notification about spending patterns
 type SpendingAlert struct {
-	Type         string        `json:"type"`     // unusual_day, budget_exceeded, budget_warning, large_transaction
-	Severity     string        `json:"severity"` // error, warning, info, success
-	Title        string        `json:"title"`
-	Message      string        `json:"message"`
-	Detail       string        `json:"detail,omitempty"`
-	Date         *time.Time    `json:"date,omitempty"`
-	Amount       float64       `json:"amount,omitempty"`
-	Transactions []Transaction `json:"transactions,omitempty"` // Transactions that triggered this alert
+	Type         string          `json:"type"`     // unusual_day, budget_exceeded, budget_warning, large_transaction, merchant_limit_warning, merchant_limit_exceeded
+	Severity     string          `json:"severity"` // error, warning, info, success
+	Title        string          `json:"title"`
+	Message      string          `json:"message"`
+	Detail       string          `json:"detail,omitempty"`
+	Category     string          `json:"category,omitempty"` // Category the alert is about, for linking to its transactions
+	Date         *time.Time      `json:"date,omitempty"`
+	Amount       float64         `json:"amount,omitempty"`
+	Transactions []Transaction   `json:"transactions,omitempty"` // Transactions that triggered this alert
+	Score        float64         `json:"score,omitempty"`        // Composite severity from 0 to 100; see analytics.ScoreAlert
+	Related      []SpendingAlert `json:"related,omitempty"`      // Alerts from the same day grouped under this one
 }
 
 // ChartData represents data for a Plotly chart
@@ -89,6 +184,14 @@ type CategorySummary struct {
 	Percentage float64 `json:"percentage"`
 }
 
+// CategoryBreakdown is income or spending over a date range by category
+type CategoryBreakdown struct {
+	Start      string            `json:"start"` // YYYY-MM-DD
+	End        string            `json:"end"`   // YYYY-MM-DD
+	Total      float64           `json:"total"`
+	Categories []CategorySummary `json:"categories"` // Largest first
+}
+
 // MonthlySummary represents a month's financial summary
 type MonthlySummary struct {
 	Month       string  `json:"month"`
@@ -97,3 +200,84 @@ type MonthlySummary struct {
 	NetSavings  float64 `json:"net_savings"`
 	SavingsRate float64 `json:"savings_rate"`
 }
+
+// VisitSnapshot records what the dashboard showed at a visit so the next
+// visit can report what changed
+type VisitSnapshot struct {
+	TakenAt           time.Time          `json:"taken_at"`
+	TotalIncome       float64            `json:"total_income"`
+	TotalExpenses     float64            `json:"total_expenses"`
+	NetSavings        float64            `json:"net_savings"`
+	SavingsRate       float64            `json:"savings_rate"`
+	TransactionCount  int                `json:"transaction_count"`
+	CategoryTotals    map[string]float64 `json:"category_totals"` // Spending by category
+	AlertKeys         []string           `json:"alert_keys"`
+	TransactionHashes []string           `json:"transaction_hashes"`
+}
+
+// VisitLog holds the snapshot changes are reported against and the most
+// recent one, which becomes the baseline once a new visit starts
+type VisitLog struct {
+	Previous *VisitSnapshot `json:"previous,omitempty"`
+	Latest   *VisitSnapshot `json:"latest,omitempty"`
+}
+
+// MetricDelta is the change in one value between two snapshots
+type MetricDelta struct {
+	Name     string  `json:"name"`
+	Previous float64 `json:"previous"`
+	Current  float64 `json:"current"`
+	Change   float64 `json:"change"`
+	Percent  bool    `json:"percent,omitempty"` // Value is a percentage, not money
+}
+
+// VisitChanges summarizes what changed since the last visit
+type VisitChanges struct {
+	Since               time.Time       `json:"since"`
+	NewTransactionCount int             `json:"new_transaction_count"`
+	NewTransactions     []Transaction   `json:"new_transactions"` // Most recent first, capped
+	NewIncome           float64         `json:"new_income"`
+	NewSpending         float64         `json:"new_spending"`
+	NewAlerts           []SpendingAlert `json:"new_alerts"`
+	MetricDeltas        []MetricDelta   `json:"metric_deltas"`
+	CategoryDeltas      []MetricDelta   `json:"category_deltas"`
+}
+
+// HasChanges reports whether there is anything worth showing
+func (c *VisitChanges) HasChanges() bool {
+	return c.NewTransactionCount > 0 || len(c.NewAlerts) > 0 || len(c.MetricDeltas) > 0 || len(c.CategoryDeltas) > 0
+}
+
+// WatchedMerchant is a merchant with a monthly spending limit
+type WatchedMerchant struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`  // Display name, e.g. "DoorDash"
+	Match        string    `json:"match"` // Case-insensitive text matched against descriptions
+	MonthlyLimit float64   `json:"monthly_limit"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Watchlist levels
+const (
+	WatchOK      = "ok"
+	WatchWarning = "warning" // Approaching the limit
+	WatchOver    = "over"    // Limit exceeded
+)
+
+// MerchantWatchStatus is a watched merchant's month-to-date spending
+type MerchantWatchStatus struct {
+	WatchedMerchant
+	Spent            float64 `json:"spent"`
+	Remaining        float64 `json:"remaining"`
+	Percent          float64 `json:"percent"` // Spent as a share of the limit, 0-100+
+	TransactionCount int     `json:"transaction_count"`
+	Level            string  `json:"level"`
+}
+
+// MerchantWatchSummary is the watchlist for one month
+type MerchantWatchSummary struct {
+	Month     string                `json:"month"` // e.g. "Mar 2025"
+	Start     string                `json:"start"` // YYYY-MM-DD
+	End       string                `json:"end"`   // Last data date in the month, YYYY-MM-DD
+	Merchants []MerchantWatchStatus `json:"merchants"`
+}
@@ -1,6 +1,9 @@
 package models
 
-import "math"
+import (
+	"math"
+	"strings"
+)
 
 // IncomeType represents the type of income source
 type IncomeType string
@@ -16,16 +19,70 @@ const (
 type IncomeSource struct {
 	ID                string     `json:"id"`
 	Name              string     `json:"name"`
-	Amount            float64    `json:"amount"`       // Monthly amount
+	Amount            float64    `json:"amount"` // Monthly amount
 	Type              IncomeType `json:"income_type"`
-	StartMonth        int        `json:"start_month"`  // 0 = immediate
-	EndMonth          *int       `json:"end_month"`    // nil = perpetual
-	COLARate          float64    `json:"cola_rate"`    // Cost of living adjustment, e.g., 0.02 for 2%
-	InflationAdjusted bool       `json:"inflation_adjusted"`
+	StartMonth        int        `json:"start_month"`         // 0 = immediate
+	EndMonth          *int       `json:"end_month"`           // nil = perpetual
+	COLARate          float64    `json:"cola_rate"`           // Cost of living adjustment, e.g., 0.02 for 2%
+	InflationAdjusted bool       `json:"inflation_adjusted"`  // COLA tracks the plan's inflation rate instead of COLARate
+	StartAge          int        `json:"start_age,omitempty"` // 0 = timed by StartMonth; otherwise StartMonth is derived
+	EndAge            int        `json:"end_age,omitempty"`   // 0 = timed by EndMonth; otherwise EndMonth is derived
+}
+
+// IncomeTiming is when an income source starts and ends, either as year
+// offsets from now or as ages (ages win when StartAge is set)
+type IncomeTiming struct {
+	StartYear int
+	EndYear   int // 0 = never
+	StartAge  int
+	EndAge    int // 0 = never
+}
+
+// Apply sets the source's timing, clearing whichever representation is unused
+func (t IncomeTiming) Apply(is *IncomeSource) {
+	is.StartAge, is.EndAge = 0, 0
+	is.StartMonth = t.StartYear * 12
+	is.EndMonth = nil
+	if t.StartAge > 0 {
+		is.StartAge, is.EndAge = t.StartAge, t.EndAge
+		return
+	}
+	if t.EndYear > 0 {
+		endMonth := t.EndYear * 12
+		is.EndMonth = &endMonth
+	}
+}
+
+// ByAge returns whether the source is timed by age rather than year offsets
+func (is *IncomeSource) ByAge() bool {
+	return is.StartAge > 0
+}
+
+// ResolveAges derives StartMonth and EndMonth from StartAge and EndAge for
+// someone currentAge years old. Ages already passed start immediately.
+func (is *IncomeSource) ResolveAges(currentAge int) {
+	if !is.ByAge() {
+		return
+	}
+	is.StartMonth = max(0, is.StartAge-currentAge) * 12
+	is.EndMonth = nil
+	if is.EndAge > 0 {
+		endMonth := max(0, is.EndAge-currentAge) * 12
+		is.EndMonth = &endMonth
+	}
+}
+
+// EffectiveCOLA returns the annual COLA as a fraction, using the plan's
+// inflation rate (a percentage) for inflation-adjusted sources
+func (is *IncomeSource) EffectiveCOLA(annualInflationRate float64) float64 {
+	if is.InflationAdjusted {
+		return annualInflationRate / 100
+	}
+	return is.COLARate
 }
 
 // GetAdjustedAmount returns income for a specific month with COLA applied
-func (is *IncomeSource) GetAdjustedAmount(month int) float64 {
+func (is *IncomeSource) GetAdjustedAmount(month int, annualInflationRate float64) float64 {
 	if month < is.StartMonth {
 		return 0
 	}
@@ -36,12 +93,28 @@ func (is *IncomeSource) GetAdjustedAmount(month int) float64 {
 	monthsActive := month - is.StartMonth
 	yearsActive := monthsActive / 12
 
-	if is.COLARate > 0 && yearsActive > 0 {
-		return is.Amount * math.Pow(1+is.COLARate, float64(yearsActive))
+	if cola := is.EffectiveCOLA(annualInflationRate); cola > 0 && yearsActive > 0 {
+		return is.Amount * math.Pow(1+cola, float64(yearsActive))
 	}
 	return is.Amount
 }
 
+// SocialSecurityKeywords identify Social Security income sources by name
+// (lowercase)
+var SocialSecurityKeywords = []string{"social security", "ssa", "ssdi"}
+
+// IsSocialSecurity returns whether the income source is Social Security,
+// which is taxed differently from pensions and wages
+func (is *IncomeSource) IsSocialSecurity() bool {
+	name := strings.ToLower(is.Name)
+	for _, kw := range SocialSecurityKeywords {
+		if strings.Contains(name, kw) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsActive returns whether the income source is active in the given month
 func (is *IncomeSource) IsActive(month int) bool {
 	if month < is.StartMonth {
@@ -4,7 +4,9 @@ import "time"
 
 // RecurringPayment represents a detected recurring expense or subscription
 type RecurringPayment struct {
-	Description  string        `json:"description"`
+	Description  string        `json:"description"`        // Latest occurrence's, lowercased
+	Merchant     string        `json:"merchant"`           // Normalized merchant the occurrences share
+	Accounts     []string      `json:"accounts,omitempty"` // Accounts paid from, most recent first
 	Amount       float64       `json:"amount"`
 	Frequency    string        `json:"frequency"` // "weekly", "monthly", "yearly"
 	LastDate     time.Time     `json:"last_date"`
@@ -23,6 +25,22 @@ type CategoryTrend struct {
 	ChangePercent  float64 `json:"change_percent"`
 	ChangeAmount   float64 `json:"change_amount"`
 	Direction      string  `json:"direction"` // "up", "down", "stable"
+	Share          float64 `json:"share"`     // Percent of current-period spending
+	Impact         float64 `json:"impact"`    // Change as a percent of total spending
+
+	// Expected spending in the month after the period: the category's
+	// monthly average over up to 12 months, with the range its month-to-month
+	// variance gives (nil with under two months)
+	Projection      float64          `json:"projection"`
+	ProjectionRange *ConfidenceRange `json:"projection_range,omitempty"`
+}
+
+// TrendThresholds decide which categories are big enough to report trends
+// for. A category must reach both in at least one of the two periods; zero
+// disables a threshold.
+type TrendThresholds struct {
+	MinSpend float64 `json:"min_spend"` // Dollars spent in the period
+	MinShare float64 `json:"min_share"` // Percent of the period's spending
 }
 
 // IncomePattern represents detected income sources and their regularity
@@ -33,24 +51,374 @@ type IncomePattern struct {
 	IsRegular   bool    `json:"is_regular"`
 	Occurrences int     `json:"occurrences"`
 	TotalAmount float64 `json:"total_amount"`
+
+	// First and latest payments, and for regular income the steps in its
+	// amount (raises and cuts, oldest first) and its amount since the last
+	FirstDate     time.Time      `json:"first_date"`
+	LastDate      time.Time      `json:"last_date"`
+	Changes       []IncomeChange `json:"changes,omitempty"`
+	CurrentAmount float64        `json:"current_amount"`
+}
+
+// IncomeChange is a lasting step up (raise) or down in a regular payment
+type IncomeChange struct {
+	Date    time.Time `json:"date"` // First payment at the new amount
+	From    float64   `json:"from"`
+	To      float64   `json:"to"`
+	Percent float64   `json:"percent"` // Negative for a cut
+}
+
+// ConfidenceRange is the likely spread of a projected number, from the
+// historical variance of what it projects
+type ConfidenceRange struct {
+	Low   float64 `json:"low"`
+	High  float64 `json:"high"`
+	Level int     `json:"level"` // Percent of outcomes expected inside, e.g. 80
 }
 
 // SpendingVelocity tracks the burn rate and projections
 type SpendingVelocity struct {
-	DailyAverage    float64 `json:"daily_average"`
-	HistoricalDaily float64 `json:"historical_daily"`
-	MonthProjection float64 `json:"month_projection"`
-	DaysRemaining   int     `json:"days_remaining"`
-	BurnRateChange  float64 `json:"burn_rate_change"` // % vs historical
+	DailyAverage    float64          `json:"daily_average"`
+	HistoricalDaily float64          `json:"historical_daily"`
+	MonthProjection float64          `json:"month_projection"`
+	MonthRange      *ConfidenceRange `json:"month_range,omitempty"` // Nil without enough history
+	DaysRemaining   int              `json:"days_remaining"`
+	BurnRateChange  float64          `json:"burn_rate_change"` // % vs historical
+
+	// On the weekly cycle, the current week's projection
+	Cycle             string           `json:"cycle,omitempty"` // CycleWeekly, or empty
+	WeekProjection    float64          `json:"week_projection,omitempty"`
+	WeekRange         *ConfidenceRange `json:"week_range,omitempty"`
+	WeekDaysRemaining int              `json:"week_days_remaining,omitempty"`
+}
+
+// BurnDown tracks the latest month's cumulative spending by day of month
+// against its budget pace and the month before. On the weekly cycle it
+// follows the latest week instead, against the week before.
+type BurnDown struct {
+	Cycle          string    `json:"cycle,omitempty"` // CycleWeekly for a week; empty for a month
+	Month          string    `json:"month"`           // "2025-12", or a week's first day
+	AsOf           string    `json:"as_of"`           // Latest transaction date
+	Budget         float64   `json:"budget"`
+	BudgetSource   string    `json:"budget_source,omitempty"` // BudgetConfigured or BudgetAverage; empty without a budget
+	Days           []int     `json:"days"`                    // 1 through the month's last day
+	DayLabels      []string  `json:"day_labels,omitempty"`    // Weekday names for a week's days
+	Spent          []float64 `json:"spent"`                   // Through AsOf's day
+	Pace           []float64 `json:"pace,omitempty"`          // Straight line from zero to the budget
+	LastMonth      []float64 `json:"last_month,omitempty"`    // Through the prior month's (or week's) last day
+	DailyRate      float64   `json:"daily_rate"`              // Velocity's daily average, used for the projection
+	Projected      []float64 `json:"projected,omitempty"`     // From AsOf's day to the month's end
+	ProjectedTotal float64   `json:"projected_total"`
+
+	// The projection's confidence band by day, alongside Projected, and at
+	// the month's end; empty without enough history
+	ProjectedLow   []float64        `json:"projected_low,omitempty"`
+	ProjectedHigh  []float64        `json:"projected_high,omitempty"`
+	ProjectedRange *ConfidenceRange `json:"projected_range,omitempty"`
 }
 
 // InsightsData contains all insight metrics for the page
 type InsightsData struct {
 	RecurringPayments  []RecurringPayment `json:"recurring_payments"`
 	CategoryTrends     []CategoryTrend    `json:"category_trends"`
+	TrendThresholds    TrendThresholds    `json:"trend_thresholds"`
+	TrendsSkipped      int                `json:"trends_skipped"` // Categories below the thresholds
 	IncomePatterns     []IncomePattern    `json:"income_patterns"`
 	Velocity           *SpendingVelocity  `json:"velocity"`
 	TotalRecurring     float64            `json:"total_recurring"`      // Annual recurring cost
 	MonthlyRecurring   float64            `json:"monthly_recurring"`    // Monthly recurring cost
 	RegularIncomeTotal float64            `json:"regular_income_total"` // Total from regular income
+	Fees               *FeeSummary        `json:"fees"`
+	Tags               []TagTotal         `json:"tags"` // Totals by tag, most spent first
+}
+
+// SubscriptionCancellation records that a recurring payment was cancelled
+type SubscriptionCancellation struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description"` // The payment's wording when it was cancelled
+	Merchant    string    `json:"merchant"`    // Matches RecurringPayment.Merchant, whichever card charges it
+	Amount      float64   `json:"amount"`
+	Frequency   string    `json:"frequency"`
+	AnnualCost  float64   `json:"annual_cost"`
+	CancelledOn time.Time `json:"cancelled_on"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Cancellation verification states
+const (
+	CancellationPending  = "pending"  // Not enough data since cancelling to confirm
+	CancellationVerified = "verified" // A full billing cycle passed with no charges
+	CancellationCharged  = "charged"  // Charges continued after the cancel date
+)
+
+// CancellationStatus is a cancellation checked against transaction data
+type CancellationStatus struct {
+	SubscriptionCancellation
+	Status          string    `json:"status"`
+	ChargesAfter    int       `json:"charges_after"`
+	LastChargeDate  time.Time `json:"last_charge_date"`
+	RealizedSavings float64   `json:"realized_savings"`
+}
+
+// CancellationSummary totals savings across all cancellations
+type CancellationSummary struct {
+	Items         []CancellationStatus `json:"items"`
+	TotalRealized float64              `json:"total_realized"` // Saved so far
+	TotalAnnual   float64              `json:"total_annual"`   // Annual run-rate of savings
+}
+
+// CancelCandidate is a recurring payment being considered for cancelling
+type CancelCandidate struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description"` // The payment's wording when it was marked
+	Merchant    string    `json:"merchant"`    // Matches RecurringPayment.Merchant
+	Amount      float64   `json:"amount"`
+	Frequency   string    `json:"frequency"`
+	AnnualCost  float64   `json:"annual_cost"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+// CancellationImpact is what cancelling every candidate would save, and
+// its effect on the savings rate over the last year of data
+type CancellationImpact struct {
+	Candidates     []CancelCandidate   `json:"candidates"`
+	AnnualSavings  float64             `json:"annual_savings"`
+	MonthlySavings float64             `json:"monthly_savings"`
+	Start          string              `json:"start"` // YYYY-MM-DD
+	End            string              `json:"end"`   // Latest transaction, YYYY-MM-DD
+	Income         float64             `json:"income"`
+	Spending       float64             `json:"spending"`
+	SavingsRate    float64             `json:"savings_rate"`      // 0-100
+	NewSavingsRate float64             `json:"new_savings_rate"`  // Had the candidates' cost been saved
+	WhatIf         *CancellationWhatIf `json:"what_if,omitempty"` // nil without what-if living expenses
+}
+
+// CancellationWhatIf is the what-if plan run with its living expenses and
+// again with the candidates' monthly cost taken off them
+type CancellationWhatIf struct {
+	LivingExpenses    float64    `json:"living_expenses"`
+	NewLivingExpenses float64    `json:"new_living_expenses"`
+	Runs              int        `json:"runs"` // Monte Carlo runs for each
+	Before            SweepPoint `json:"before"`
+	After             SweepPoint `json:"after"`
+}
+
+// CategoryBenchmark is a reference share of total spending for a category
+type CategoryBenchmark struct {
+	Category string   `json:"category"`
+	Percent  float64  `json:"percent"` // Share of total spending, 0-100
+	Matches  []string `json:"matches"` // Keywords mapping spending categories to this benchmark
+	Source   string   `json:"source"`  // "bls" or "user"
+}
+
+// BenchmarkComparison compares spending in a benchmark category to its baseline
+type BenchmarkComparison struct {
+	Category      string   `json:"category"`
+	Categories    []string `json:"categories"` // Spending categories rolled into this row
+	Amount        float64  `json:"amount"`
+	ActualPercent float64  `json:"actual_percent"`
+	TargetPercent float64  `json:"target_percent"`
+	TargetAmount  float64  `json:"target_amount"` // TargetPercent applied to total spending
+	Difference    float64  `json:"difference"`    // ActualPercent - TargetPercent
+	Source        string   `json:"source"`
+}
+
+// Month close checklist items
+const (
+	CloseItemCategorized    = "categorized"     // No uncategorized transactions (checked automatically)
+	CloseItemReconciled     = "reconciled"      // Balances match the bank statements
+	CloseItemBudgetReviewed = "budget_reviewed" // Spending reviewed against targets
+)
+
+// MonthSnapshot freezes a month's metrics when it is closed. The transaction
+// hashes let later imports be checked against the closed data.
+type MonthSnapshot struct {
+	TotalIncome       float64            `json:"total_income"`
+	TotalExpenses     float64            `json:"total_expenses"`
+	NetSavings        float64            `json:"net_savings"`
+	SavingsRate       float64            `json:"savings_rate"`
+	TransactionCount  int                `json:"transaction_count"`
+	CategoryTotals    map[string]float64 `json:"category_totals"`
+	TransactionHashes []string           `json:"transaction_hashes"`
+}
+
+// MonthClose is the saved close-out state of a calendar month
+type MonthClose struct {
+	Month     string          `json:"month"`     // YYYY-MM
+	Checklist map[string]bool `json:"checklist"` // Manual checklist items marked done
+	Closed    bool            `json:"closed"`
+	ClosedAt  time.Time       `json:"closed_at,omitempty"`
+	Snapshot  *MonthSnapshot  `json:"snapshot,omitempty"`
+}
+
+// CloseChecklistItem is one checklist step with its current state
+type CloseChecklistItem struct {
+	Key    string `json:"key"`
+	Label  string `json:"label"`
+	Done   bool   `json:"done"`
+	Auto   bool   `json:"auto"`   // Derived from the data rather than ticked by hand
+	Detail string `json:"detail"` // Why an automatic item isn't done
+}
+
+// MonthCloseStatus is a month's close state checked against current data
+type MonthCloseStatus struct {
+	Month     string               `json:"month"`
+	Label     string               `json:"label"` // e.g. "Mar 2025"
+	Start     string               `json:"start"` // First day, YYYY-MM-DD
+	End       string               `json:"end"`   // Last day, YYYY-MM-DD
+	Current   MonthSnapshot        `json:"current"`
+	Checklist []CloseChecklistItem `json:"checklist"`
+	Ready     bool                 `json:"ready"` // Every checklist item is done
+	Closed    bool                 `json:"closed"`
+	ClosedAt  time.Time            `json:"closed_at,omitempty"`
+	Snapshot  *MonthSnapshot       `json:"snapshot,omitempty"`
+	Added     int                  `json:"added"`   // Transactions imported since closing
+	Removed   int                  `json:"removed"` // Closed transactions no longer in the data
+}
+
+// Modified reports whether imports changed a closed month
+func (s MonthCloseStatus) Modified() bool {
+	return s.Closed && (s.Added > 0 || s.Removed > 0)
+}
+
+// MonthCloseSummary lists recent months, newest first
+type MonthCloseSummary struct {
+	Months        []MonthCloseStatus `json:"months"`
+	ClosedCount   int                `json:"closed_count"`
+	ModifiedCount int                `json:"modified_count"` // Closed months changed by later imports
+}
+
+// DaySpending is discretionary spending over a group of days
+type DaySpending struct {
+	Label            string  `json:"label"`
+	Days             int     `json:"days"` // Calendar days in the group within the range
+	Total            float64 `json:"total"`
+	DailyAverage     float64 `json:"daily_average"`
+	TransactionCount int     `json:"transaction_count"`
+}
+
+// WeekpartSpending splits spending between weekdays and weekends
+type WeekpartSpending struct {
+	Weekday        DaySpending   `json:"weekday"`
+	Weekend        DaySpending   `json:"weekend"`
+	WeekendPremium float64       `json:"weekend_premium"` // % weekend daily average is above weekdays
+	ByDay          []DaySpending `json:"by_day"`          // Monday through Sunday
+}
+
+// PayCycleSpending shows spending by days since the last paycheck
+type PayCycleSpending struct {
+	Paycheck     string        `json:"paycheck"`  // Description of the income used for paydays
+	Frequency    string        `json:"frequency"` // Pay frequency
+	CycleDays    int           `json:"cycle_days"`
+	Paydays      int           `json:"paydays"`
+	Buckets      []DaySpending `json:"buckets"`       // Cycle split into early, middle and late
+	ByDay        []DaySpending `json:"by_day"`        // Day 0 (payday) through CycleDays-1
+	SplurgeRatio float64       `json:"splurge_ratio"` // First days' daily average over the rest of the cycle
+	HasSplurge   bool          `json:"has_splurge"`
+}
+
+// SpendingRhythm is how discretionary spending falls across the week and pay cycle
+type SpendingRhythm struct {
+	Weekpart *WeekpartSpending `json:"weekpart"`
+	PayCycle *PayCycleSpending `json:"pay_cycle"` // Nil when no regular paycheck is detected
+}
+
+// StatementCycle configures the billing cycle of a card exported to one CSV file
+type StatementCycle struct {
+	Source     string `json:"source"` // CSV file name
+	Name       string `json:"name"`   // Display name, e.g. "Visa"
+	ClosingDay int    `json:"closing_day"`
+}
+
+// StatementPeriod is a card's activity within one statement cycle
+type StatementPeriod struct {
+	Start            time.Time `json:"start"`
+	End              time.Time `json:"end"` // Closing date
+	Charges          float64   `json:"charges"`
+	Credits          float64   `json:"credits"` // Refunds and other credits
+	Balance          float64   `json:"balance"` // Charges less credits
+	TransactionCount int       `json:"transaction_count"`
+	Open             bool      `json:"open"`         // Still accruing charges
+	DaysElapsed      int       `json:"days_elapsed"` // Days through the cycle, for open periods
+	DaysTotal        int       `json:"days_total"`
+	Projected        float64   `json:"projected"` // Balance at closing at the current pace
+
+	// ProjectedRange is the closing balance's likely spread, from the
+	// card's day-to-day variance in earlier periods
+	ProjectedRange *ConfidenceRange `json:"projected_range,omitempty"`
+}
+
+// CardStatements is a card's recent statement periods, newest first
+type CardStatements struct {
+	StatementCycle
+	Periods          []StatementPeriod `json:"periods"`
+	Current          *StatementPeriod  `json:"current,omitempty"` // The open period
+	AverageStatement float64           `json:"average_statement"` // Average closed balance
+}
+
+// Fee kinds
+const (
+	FeeInterest = "interest"
+	FeeLate     = "late_fee"
+	FeeBank     = "bank_fee"
+)
+
+// Fee is a transaction detected as an interest charge or fee
+type Fee struct {
+	Transaction
+	Kind string `json:"kind"` // FeeInterest, FeeLate or FeeBank
+}
+
+// FeeTotal is the money lost to fees in one month or year
+type FeeTotal struct {
+	Period   string  `json:"period"` // "2025-01" or "2025"
+	Label    string  `json:"label"`
+	Interest float64 `json:"interest"`
+	LateFees float64 `json:"late_fees"`
+	BankFees float64 `json:"bank_fees"`
+	Total    float64 `json:"total"`
+	Count    int     `json:"count"`
+}
+
+// FeeSummary is the interest and fees paid across all accounts in a range
+type FeeSummary struct {
+	FeeTotal
+	MonthlyAverage float64    `json:"monthly_average"`
+	ByMonth        []FeeTotal `json:"by_month"` // Newest first
+	ByYear         []FeeTotal `json:"by_year"`  // Newest first
+	Fees           []Fee      `json:"fees"`     // Every fee, newest first
+}
+
+// GivingCategory is a spending category counted as giving
+type GivingCategory struct {
+	Category   string `json:"category"`
+	Deductible bool   `json:"deductible"` // Charitable, so deductible on taxes
+}
+
+// Gift is a donation or gift transaction
+type Gift struct {
+	Transaction
+	Recipient  string `json:"recipient"`
+	Deductible bool   `json:"deductible"`
+}
+
+// GivingRecipient totals a year's giving to one recipient
+type GivingRecipient struct {
+	Recipient  string    `json:"recipient"`
+	Deductible bool      `json:"deductible"`
+	Total      float64   `json:"total"`
+	Count      int       `json:"count"`
+	LastDate   time.Time `json:"last_date"`
+}
+
+// GivingSummary is a year's donations and gifts
+type GivingSummary struct {
+	Year            int               `json:"year"`
+	Years           []int             `json:"years"` // Years with giving, newest first
+	Total           float64           `json:"total"`
+	DeductibleTotal float64           `json:"deductible_total"`
+	GiftTotal       float64           `json:"gift_total"` // Not deductible
+	Count           int               `json:"count"`
+	Recipients      []GivingRecipient `json:"recipients"` // Largest total first
+	Gifts           []Gift            `json:"gifts"`      // Oldest first, for tax records
 }
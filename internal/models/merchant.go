@@ -0,0 +1,45 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// processorPrefixes are payment processors some card exports put ahead of
+// the merchant's name
+var processorPrefixes = []string{"sq *", "sq*", "tst* ", "tst*", "paypal *", "pp*", "pos "}
+
+// merchantNoise are words that vary between exports of the same merchant
+var merchantNoise = []string{"com", "www", "net", "inc", "llc"}
+
+// MerchantKey normalizes a description to the merchant behind it so the same
+// payment matches across accounts whose exports word it differently, e.g.
+// "NETFLIX.COM" and "Netflix.com 866-579-7172 CA" are both "netflix". It
+// drops processor prefixes, punctuation, words with digits (store numbers,
+// phone numbers, references) and a trailing state code, then keeps the first
+// two words.
+func MerchantKey(description string) string {
+	desc := strings.ToLower(strings.TrimSpace(description))
+	for _, prefix := range processorPrefixes {
+		if rest, ok := strings.CutPrefix(desc, prefix); ok {
+			desc = rest
+			break
+		}
+	}
+
+	var words []string
+	for _, w := range strings.FieldsFunc(desc, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if strings.ContainsFunc(w, unicode.IsDigit) || slices.Contains(merchantNoise, w) {
+			continue
+		}
+		words = append(words, w)
+	}
+	if len(words) > 1 && len(words[len(words)-1]) == 2 {
+		words = words[:len(words)-1]
+	}
+	if len(words) == 0 {
+		return strings.ToLower(strings.TrimSpace(description))
+	}
+	return strings.Join(words[:min(2, len(words))], " ")
+}
@@ -0,0 +1,22 @@
+package models
+
+import "testing"
+
+func TestMerchantKey(t *testing.T) {
+	tests := []struct {
+		description string
+		want        string
+	}{
+		{"NETFLIX.COM", "netflix"},
+		{"Netflix.com 866-579-7172 CA", "netflix"},
+		{"SQ *BLUE BOTTLE COFFEE #12", "blue bottle"},
+		{"RENT PAYMENT APT 204", "rent payment"},
+		{"BP GAS STATION", "bp gas"},
+		{"12345", "12345"},
+	}
+	for _, tt := range tests {
+		if got := MerchantKey(tt.description); got != tt.want {
+			t.Errorf("MerchantKey(%q) = %q, want %q", tt.description, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+// BalanceSnapshot is an account's balance on a date, recorded by hand
+type BalanceSnapshot struct {
+	Account string  `json:"account"`
+	Date    string  `json:"date"` // YYYY-MM-DD
+	Balance float64 `json:"balance"`
+}
+
+// Where an account balance came from
+const (
+	BalanceManual = "manual" // A snapshot the user recorded
+	BalanceFile   = "file"   // A data file's balance column
+)
+
+// AccountBalance is an account's balance on a date. Balances are as the
+// account shows them: a card's balance is what's owed, so Value, its
+// contribution to net worth, is its negative.
+type AccountBalance struct {
+	Account string  `json:"account"`
+	Type    string  `json:"type"` // One of AccountTypes
+	Date    string  `json:"date"` // YYYY-MM-DD
+	Balance float64 `json:"balance"`
+	Value   float64 `json:"value"`
+	Source  string  `json:"source"` // BalanceManual or BalanceFile
+}
+
+// NetWorth is the sum of each account's latest balance as of a date
+type NetWorth struct {
+	Date        string           `json:"date"` // YYYY-MM-DD
+	Total       float64          `json:"total"`
+	Assets      float64          `json:"assets"`
+	Liabilities float64          `json:"liabilities"` // Owed, as a positive amount
+	Accounts    []AccountBalance `json:"accounts"`    // Latest balance of each account, by name
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Planned purchase states
+const (
+	PlannedPending = "pending" // Nothing matched yet, and it still may
+	PlannedMissing = "missing" // Nothing matched by the end of its window
+	PlannedUnder   = "under"   // Came in below the plan
+	PlannedOnPlan  = "on-plan" // Within a few percent of the plan
+	PlannedOver    = "over"    // Cost more than planned
+)
+
+// PlannedPurchase is a one-off purchase the user expects to make, such as
+// new flooring or holiday gifts, to compare with what it actually cost
+type PlannedPurchase struct {
+	ID           string    `json:"id"`
+	Item         string    `json:"item"`
+	ExpectedCost float64   `json:"expected_cost"`
+	Month        string    `json:"month"`              // Expected month, YYYY-MM
+	Match        string    `json:"match,omitempty"`    // Description text of the receipts
+	Category     string    `json:"category,omitempty"` // Limits matches to one category
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PlannedPurchaseStatus is a planned purchase with the transactions that
+// paid for it
+type PlannedPurchaseStatus struct {
+	PlannedPurchase
+	Start           string        `json:"start"` // Matching window, YYYY-MM-DD
+	End             string        `json:"end"`
+	Actual          float64       `json:"actual"`
+	Variance        float64       `json:"variance"`         // Actual less expected; positive when over
+	VariancePercent float64       `json:"variance_percent"` // Variance as a share of the expected cost
+	Transactions    []Transaction `json:"transactions"`
+	Status          string        `json:"status"`
+}
+
+// PlannedSummary is every planned purchase against its receipts
+type PlannedSummary struct {
+	TotalPlanned float64 `json:"total_planned"`
+	TotalActual  float64 `json:"total_actual"`
+	// Variance totals only purchases with receipts, so pending ones don't
+	// read as savings
+	TotalVariance float64                 `json:"total_variance"`
+	Purchases     []PlannedPurchaseStatus `json:"purchases"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import "sort"
+
+// PortfolioBucket is a named part of the portfolio, such as cash, bonds,
+// stocks or rental equity, with its own expected return. Withdrawals drain
+// buckets in priority order, lowest first.
+type PortfolioBucket struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Value    float64 `json:"value"`    // Current value
+	Return   float64 `json:"return"`   // Expected annual return (as percentage)
+	Priority int     `json:"priority"` // Withdrawal order, lowest drawn first
+}
+
+// BucketResult summarizes one bucket over a projection
+type BucketResult struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Return       float64  `json:"return"`
+	Priority     int      `json:"priority"`
+	StartBalance float64  `json:"start_balance"`
+	FinalBalance float64  `json:"final_balance"`
+	EmptyYear    *float64 `json:"empty_year"` // nil if the bucket never runs out
+}
+
+// HasPortfolioBuckets returns true if the portfolio is modeled as buckets
+func (s *WhatIfSettings) HasPortfolioBuckets() bool {
+	return len(s.PortfolioBuckets) > 0
+}
+
+// BucketsTotal returns the combined value of the portfolio buckets
+func (s *WhatIfSettings) BucketsTotal() float64 {
+	total := 0.0
+	for _, b := range s.PortfolioBuckets {
+		total += b.Value
+	}
+	return total
+}
+
+// BlendedBucketReturn returns the buckets' value-weighted return, or the
+// plain average when they're all empty
+func (s *WhatIfSettings) BlendedBucketReturn() float64 {
+	if len(s.PortfolioBuckets) == 0 {
+		return 0
+	}
+	total := s.BucketsTotal()
+	blended := 0.0
+	for _, b := range s.PortfolioBuckets {
+		if total > 0 {
+			blended += b.Return * b.Value / total
+		} else {
+			blended += b.Return / float64(len(s.PortfolioBuckets))
+		}
+	}
+	return blended
+}
+
+// SyncPortfolioBuckets sets PortfolioValue and InvestmentReturn to the
+// buckets' total and blended return, so analyses that use a single
+// portfolio see the same one the projection does
+func (s *WhatIfSettings) SyncPortfolioBuckets() {
+	if !s.HasPortfolioBuckets() {
+		return
+	}
+	s.PortfolioValue = s.BucketsTotal()
+	s.InvestmentReturn = s.BlendedBucketReturn()
+}
+
+// BucketWithdrawalOrder returns the indexes of the portfolio buckets in the
+// order withdrawals drain them, keeping list order among equal priorities
+func (s *WhatIfSettings) BucketWithdrawalOrder() []int {
+	order := make([]int, len(s.PortfolioBuckets))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return s.PortfolioBuckets[order[i]].Priority < s.PortfolioBuckets[order[j]].Priority
+	})
+	return order
+}
+
+// NextBucketPriority returns a priority that draws a new bucket after the
+// existing ones
+func (s *WhatIfSettings) NextBucketPriority() int {
+	next := 1
+	for _, b := range s.PortfolioBuckets {
+		next = max(next, b.Priority+1)
+	}
+	return next
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// ReadinessSnapshot is the plan's standing when a readiness report was
+// generated, kept so the next quarter's report can show what changed
+type ReadinessSnapshot struct {
+	Quarter       string    `json:"quarter"` // e.g. "2025-Q3"
+	TakenAt       time.Time `json:"taken_at"`
+	Score         int       `json:"score"`
+	SuccessRate   float64   `json:"success_rate"`
+	MedianBalance float64   `json:"median_balance"`
+}
+
+// ReadinessRisk is how much one adverse shift in an assumption would cost
+// the plan, measured on the same simulated markets as the baseline
+type ReadinessRisk struct {
+	Name        string  `json:"name"`   // e.g. "Lower returns"
+	Param       string  `json:"param"`  // Setting key
+	Change      string  `json:"change"` // e.g. "-2 points"
+	SuccessRate float64 `json:"success_rate"`
+	Impact      float64 `json:"impact"` // Success rate change in points, negative when it hurts
+}
+
+// ReadinessReport is the one-page quarterly retirement readiness summary
+type ReadinessReport struct {
+	ReadinessSnapshot
+	Label     string             `json:"label"`    // Sustainability label, e.g. "Good"
+	Previous  *ReadinessSnapshot `json:"previous"` // Latest report from an earlier quarter, nil if none
+	Change    *float64           `json:"success_rate_change"`
+	Risks     []ReadinessRisk    `json:"risks"`   // Biggest first
+	Actions   []string           `json:"actions"` // Recommended next steps, most important first
+	Warnings  []SanityWarning    `json:"warnings"`
+	Generated time.Time          `json:"generated"`
+}
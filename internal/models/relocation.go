@@ -0,0 +1,35 @@
+package models
+
+// RelocationScenario describes retiring in a state: how expensive it is to
+// live there and how it taxes retirement income and property
+type RelocationScenario struct {
+	ID                  string  `json:"id"`
+	State               string  `json:"state"`
+	CostOfLiving        float64 `json:"cost_of_living"`        // Living expense multiplier vs. where you live now (1.0 = same)
+	IncomeTaxRate       float64 `json:"income_tax_rate"`       // State tax on retirement income (e.g., 5.0 for 5%)
+	TaxesSocialSecurity bool    `json:"taxes_social_security"` // Whether Social Security benefits are taxed
+	RetirementExclusion float64 `json:"retirement_exclusion"`  // Annual retirement income exempt from state tax
+	PropertyTax         float64 `json:"property_tax"`          // Annual property tax
+}
+
+// RelocationPlan is where you live now and the states you're considering
+type RelocationPlan struct {
+	Current   RelocationScenario   `json:"current"`
+	Scenarios []RelocationScenario `json:"scenarios"`
+}
+
+// RelocationOutcome is the retirement plan's result in one location
+type RelocationOutcome struct {
+	Scenario           RelocationScenario   `json:"scenario"`
+	Current            bool                 `json:"current"`
+	MonthlyLiving      float64              `json:"monthly_living"`       // Living expenses after cost of living
+	MonthlyPropertyTax float64              `json:"monthly_property_tax"` // Property tax spread over the year
+	MonthlyStateTax    float64              `json:"monthly_state_tax"`    // Estimated state tax on retirement income
+	MonthlyExpenses    float64              `json:"monthly_expenses"`     // Total first-month expenses, including healthcare
+	FinalBalance       float64              `json:"final_balance"`
+	Survives           bool                 `json:"survives"`
+	LongevityYears     *float64             `json:"longevity_years"` // nil if the portfolio survives
+	Sustainability     *SustainabilityScore `json:"sustainability"`
+	BalanceDifference  float64              `json:"balance_difference"` // Final balance vs. staying put
+	MonthlyDifference  float64              `json:"monthly_difference"` // Monthly expenses vs. staying put
+}
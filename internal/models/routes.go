@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// RouteStat summarizes the recorded requests to one route
+type RouteStat struct {
+	Route    string    `json:"route"` // Method and pattern, e.g. "GET /dashboard/kpi/{kpiType}"
+	Requests int       `json:"requests"`
+	Errors   int       `json:"errors"` // 5xx responses
+	P50Ms    float64   `json:"p50_ms"`
+	P95Ms    float64   `json:"p95_ms"`
+	MaxMs    float64   `json:"max_ms"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// RouteHour is one hour of requests, to one route or all of them
+type RouteHour struct {
+	Hour     time.Time `json:"hour"`
+	Requests int       `json:"requests"`
+	P95Ms    float64   `json:"p95_ms"`
+}
+
+// RouteStats is what the route analytics page shows: the recent requests
+// per route, slowest and busiest, and the routes nobody used
+type RouteStats struct {
+	Since    time.Time   `json:"since"`    // Oldest recorded request
+	Recorded int         `json:"recorded"` // Requests held
+	Capacity int         `json:"capacity"` // Most requests held before the oldest are dropped
+	Routes   []RouteStat `json:"routes"`   // Busiest first
+	Unused   []string    `json:"unused"`   // Registered routes without a recorded request
+}
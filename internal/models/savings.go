@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// SavingsPlan holds the user's emergency fund and FIRE settings used to
+// suggest automatic savings transfers
+type SavingsPlan struct {
+	EmergencyFund  float64 `json:"emergency_fund"`  // Current emergency fund balance
+	TargetMonths   int     `json:"target_months"`   // Months of spending the fund should cover
+	WithdrawalRate float64 `json:"withdrawal_rate"` // Safe withdrawal rate for the FIRE number (e.g., 4.0 for 4%)
+}
+
+// DefaultSavingsPlan returns a six-month emergency fund target and the 4% rule
+func DefaultSavingsPlan() SavingsPlan {
+	return SavingsPlan{
+		TargetMonths:   6,
+		WithdrawalRate: 4.0,
+	}
+}
+
+// SavingsSuggestion is a suggested automatic transfer per payday and its
+// projected effect on the emergency fund and FIRE date
+type SavingsSuggestion struct {
+	Plan SavingsPlan `json:"plan"`
+
+	// Paycheck the transfer is scheduled against
+	Paycheck        string  `json:"paycheck"`
+	Frequency       string  `json:"frequency"`
+	PaydaysPerMonth float64 `json:"paydays_per_month"`
+
+	// Monthly cash flow the suggestion is based on
+	MonthlyIncome   float64 `json:"monthly_income"`   // Regular income only
+	MonthlySpending float64 `json:"monthly_spending"` // Higher of recent and 12-month spending
+	MonthlySurplus  float64 `json:"monthly_surplus"`
+
+	// Suggested transfer
+	PerPayday float64 `json:"per_payday"`
+	Monthly   float64 `json:"monthly"`
+
+	// Emergency fund projection (months are -1 when never reached)
+	EmergencyTarget float64   `json:"emergency_target"`
+	EmergencyMonths int       `json:"emergency_months"`
+	EmergencyDate   time.Time `json:"emergency_date"`
+
+	// FIRE projection with and without the transfer (months are -1 when not
+	// reached within 50 years)
+	PortfolioValue float64   `json:"portfolio_value"`
+	FIRENumber     float64   `json:"fire_number"`
+	FIREMonths     int       `json:"fire_months"`
+	FIREDate       time.Time `json:"fire_date"`
+	BaselineMonths int       `json:"baseline_months"`
+	BaselineDate   time.Time `json:"baseline_date"`
+	MonthsGained   int       `json:"months_gained"`
+
+	// Annual growth of regular income from its raises and cuts, and the FIRE
+	// date with transfers growing at that rate (-1 months when the rate is
+	// 0 or the number isn't reached)
+	IncomeGrowthRate float64   `json:"income_growth_rate"`
+	GrowthFIREMonths int       `json:"growth_fire_months"`
+	GrowthFIREDate   time.Time `json:"growth_fire_date"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// SheetSyncStatus is how the last import of each Google Sheet went
+type SheetSyncStatus struct {
+	Sheets []SheetSync `json:"sheets"`
+}
+
+// SheetSync is a Google Sheet imported into a data file
+type SheetSync struct {
+	ID          string    `json:"id"`              // Spreadsheet ID
+	Title       string    `json:"title,omitempty"` // Spreadsheet title
+	Tab         string    `json:"tab,omitempty"`   // Sheet tab the rows come from
+	File        string    `json:"file,omitempty"`  // Data file the rows are written to
+	Rows        int       `json:"rows"`            // Rows the last successful import wrote
+	LastRun     time.Time `json:"last_run"`
+	LastSuccess time.Time `json:"last_success"`
+	Error       string    `json:"error,omitempty"` // Why the last import failed
+}
+
+// URL links to the spreadsheet in Google Sheets
+func (s SheetSync) URL() string {
+	return "https://docs.google.com/spreadsheets/d/" + s.ID + "/edit"
+}
+
+// File returns the sheet imported into file, or nil
+func (s *SheetSyncStatus) File(file string) *SheetSync {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Sheets {
+		if s.Sheets[i].File == file {
+			return &s.Sheets[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Split divides one transaction among categories, e.g. a Costco run that
+// was part groceries and part household goods. It is kept by transaction
+// hash so it survives reloading the files.
+type Split struct {
+	Parts     []SplitPart `json:"parts"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// SplitPart is one category's share of a split transaction. Amount is the
+// size of the share; it takes the transaction's sign when loaded, and the
+// parts' amounts add up to the transaction's.
+type SplitPart struct {
+	Category string  `json:"category"`
+	Amount   float64 `json:"amount"`
+}
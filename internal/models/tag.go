@@ -0,0 +1,122 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// TransactionTags are the free-form tags the user put on one transaction,
+// e.g. "vacation2024" or "reimbursable". They are kept by transaction hash
+// so they survive reloading the files, and cut across categories.
+type TransactionTags struct {
+	Tags      []string  `json:"tags"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TagTotal is how much the transactions with one tag add up to
+type TagTotal struct {
+	Tag      string  `json:"tag"`
+	Count    int     `json:"count"`
+	Spent    float64 `json:"spent"`    // Outflows, as a positive amount
+	Received float64 `json:"received"` // Income, e.g. reimbursements
+	Net      float64 `json:"net"`      // Received less spent
+}
+
+// NormalizeTag returns tag lowercased and trimmed, without a leading "#"
+// and with inner spaces as dashes, so "#Vacation 2024" and "vacation-2024"
+// are the same tag
+func NormalizeTag(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	return strings.ToLower(strings.Join(strings.Fields(tag), "-"))
+}
+
+// ParseTags splits a comma-separated list into normalized tags, sorted and
+// without blanks or duplicates
+func ParseTags(list string) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, t := range strings.Split(list, ",") {
+		if t = NormalizeTag(t); t != "" && !seen[t] {
+			seen[t] = true
+			tags = append(tags, t)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// HasTag reports whether the transaction carries tag, ignoring case
+func (t *Transaction) HasTag(tag string) bool {
+	tag = NormalizeTag(tag)
+	for _, have := range t.Tags {
+		if have == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterByTags returns transactions carrying any of the tags
+func (ts *TransactionSet) FilterByTags(tags []string) *TransactionSet {
+	result := &TransactionSet{}
+	for _, t := range ts.Transactions {
+		for _, tag := range tags {
+			if t.HasTag(tag) {
+				result.Transactions = append(result.Transactions, t)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Tags returns every tag used on the transactions, sorted
+func (ts *TransactionSet) Tags() []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, t := range ts.Transactions {
+		for _, tag := range t.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// TagTotals totals the transactions by tag, most spent first. A transaction
+// with several tags counts toward each.
+func (ts *TransactionSet) TagTotals() []TagTotal {
+	totals := make(map[string]*TagTotal)
+	for _, t := range ts.Transactions {
+		for _, tag := range t.Tags {
+			total := totals[tag]
+			if total == nil {
+				total = &TagTotal{Tag: tag}
+				totals[tag] = total
+			}
+			total.Count++
+			if t.TransactionType == Income {
+				total.Received += t.AbsAmount()
+			} else {
+				total.Spent += t.AbsAmount()
+			}
+			total.Net = total.Received - total.Spent
+		}
+	}
+
+	result := make([]TagTotal, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Spent != result[j].Spent {
+			return result[i].Spent > result[j].Spent
+		}
+		return result[i].Tag < result[j].Tag
+	})
+	return result
+}
@@ -29,6 +29,28 @@ type Transaction struct {
 	SourceFile      string          `json:"source_file"`
 	Hash            string          `json:"hash"`
 
+	// CategoryEdited is set when the category comes from a manual fix in
+	// the explorer rather than the file or a rule
+	CategoryEdited bool `json:"category_edited,omitempty"`
+
+	// SplitOf is the hash of the transaction this row is one category's
+	// share of, when the user split it; the row's own hash extends it
+	SplitOf string `json:"split_of,omitempty"`
+
+	// Tags are the user's free-form labels, normalized with NormalizeTag;
+	// the shares of a split transaction carry the whole one's tags
+	Tags []string `json:"tags,omitempty"`
+
+	// Account names the account the transaction belongs to, and
+	// AccountType its kind (one of AccountTypes), which decides which
+	// credits count as income
+	Account     string `json:"account,omitempty"`
+	AccountType string `json:"account_type,omitempty"`
+
+	// Balance is the account's running balance after the transaction, when
+	// the file has a balance column
+	Balance *float64 `json:"balance,omitempty"`
+
 	// Derived fields (computed, not stored)
 	Month      string `json:"month,omitempty"`       // "2024-01"
 	Week       string `json:"week,omitempty"`        // "2024-W05"
@@ -117,6 +139,104 @@ func (ts *TransactionSet) FilterByCategory(category string) *TransactionSet {
 	return result
 }
 
+// FilterByAmountRange returns transactions whose absolute amount is within
+// [min, max], so a range of 75-85 finds both an $80 charge and an $80 refund
+func (ts *TransactionSet) FilterByAmountRange(min, max float64) *TransactionSet {
+	result := &TransactionSet{}
+	for _, t := range ts.Transactions {
+		amount := math.Abs(t.Amount)
+		if amount >= min && amount <= max {
+			result.Transactions = append(result.Transactions, t)
+		}
+	}
+	return result
+}
+
+// FilterByWeekdays returns transactions that fall on any of the given days
+func (ts *TransactionSet) FilterByWeekdays(days []time.Weekday) *TransactionSet {
+	result := &TransactionSet{}
+	for _, t := range ts.Transactions {
+		for _, d := range days {
+			if t.Date.Weekday() == d {
+				result.Transactions = append(result.Transactions, t)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// CategoryFilter selects transactions by category. Names match
+// case-insensitively and a blank category counts as "Uncategorized". A
+// parent category also matches its subcategories, so Food takes in
+// Food:Groceries.
+type CategoryFilter struct {
+	Include       []string // Keep only these categories (any of them)
+	Exclude       []string // Drop these categories
+	Uncategorized bool     // Keep only transactions without a category
+}
+
+// IsEmpty reports whether the filter keeps every transaction
+func (f CategoryFilter) IsEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0 && !f.Uncategorized
+}
+
+// FilterByCategories returns transactions matching a CategoryFilter
+func (ts *TransactionSet) FilterByCategories(f CategoryFilter) *TransactionSet {
+	include := make(map[string]bool, len(f.Include))
+	for _, c := range f.Include {
+		include[strings.ToLower(strings.Join(CategoryPath(c), CategorySeparator))] = true
+	}
+	exclude := make(map[string]bool, len(f.Exclude))
+	for _, c := range f.Exclude {
+		exclude[strings.ToLower(strings.Join(CategoryPath(c), CategorySeparator))] = true
+	}
+
+	result := &TransactionSet{}
+	for _, t := range ts.Transactions {
+		path := CategoryPath(t.Category)
+		if f.Uncategorized && !(len(path) == 1 && strings.EqualFold(path[0], "Uncategorized")) {
+			continue
+		}
+		if len(include) > 0 && !withinAny(path, include) {
+			continue
+		}
+		if withinAny(path, exclude) {
+			continue
+		}
+		result.Transactions = append(result.Transactions, t)
+	}
+	return result
+}
+
+// withinAny reports whether the category path, or any category above it,
+// is one of the lowercased categories
+func withinAny(path []string, categories map[string]bool) bool {
+	for depth := range path {
+		if categories[strings.ToLower(strings.Join(path[:depth+1], CategorySeparator))] {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterByAccounts returns transactions in any of the named accounts,
+// ignoring case
+func (ts *TransactionSet) FilterByAccounts(accounts []string) *TransactionSet {
+	wanted := make(map[string]bool, len(accounts))
+	for _, a := range accounts {
+		wanted[strings.ToLower(a)] = true
+	}
+
+	result := &TransactionSet{}
+	for _, t := range ts.Transactions {
+		if wanted[strings.ToLower(t.Account)] {
+			result.Transactions = append(result.Transactions, t)
+		}
+	}
+	return result
+}
+
 // FilterBySearch returns transactions matching the search term in description
 func (ts *TransactionSet) FilterBySearch(search string) *TransactionSet {
 	result := &TransactionSet{}
@@ -160,6 +280,20 @@ func (ts *TransactionSet) GroupByMonth() map[string]*TransactionSet {
 	return result
 }
 
+// GroupByWeek groups transactions by week, keyed by the week's first day
+// ("2006-01-02") for weeks starting on start
+func (ts *TransactionSet) GroupByWeek(start time.Weekday) map[string]*TransactionSet {
+	result := make(map[string]*TransactionSet)
+	for _, t := range ts.Transactions {
+		week := WeekStartOf(t.Date, start).Format("2006-01-02")
+		if result[week] == nil {
+			result[week] = &TransactionSet{}
+		}
+		result[week].Transactions = append(result[week].Transactions, t)
+	}
+	return result
+}
+
 // GroupByCategory groups transactions by category
 func (ts *TransactionSet) GroupByCategory() map[string]*TransactionSet {
 	result := make(map[string]*TransactionSet)
@@ -266,6 +400,23 @@ func (ts *TransactionSet) Categories() []string {
 	return cats
 }
 
+// Accounts returns the names of the accounts transactions belong to,
+// sorted ignoring case
+func (ts *TransactionSet) Accounts() []string {
+	seen := make(map[string]bool)
+	var accounts []string
+	for _, t := range ts.Transactions {
+		if t.Account != "" && !seen[t.Account] {
+			seen[t.Account] = true
+			accounts = append(accounts, t.Account)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return strings.ToLower(accounts[i]) < strings.ToLower(accounts[j])
+	})
+	return accounts
+}
+
 // Paginate returns a slice of transactions for the given page
 func (ts *TransactionSet) Paginate(page, perPage int) *TransactionSet {
 	if page < 1 {
@@ -319,9 +470,37 @@ func (ts *TransactionSet) CategoryTotals() map[string]float64 {
 	return result
 }
 
+// CategoryTree returns the category hierarchy with rollup totals: each
+// parent's total takes in its subcategories'. CategoryTotals stays one
+// entry per category so its totals add up to the set's.
+func (ts *TransactionSet) CategoryTree() []CategoryNode {
+	return BuildCategoryTree(ts.CategoryTotals())
+}
+
 // Copy creates a shallow copy of the TransactionSet
 func (ts *TransactionSet) Copy() *TransactionSet {
 	copied := make([]Transaction, len(ts.Transactions))
 	copy(copied, ts.Transactions)
 	return &TransactionSet{Transactions: copied}
 }
+
+// AmazonOrder is one order from an Amazon order-history export
+type AmazonOrder struct {
+	ID       string    `json:"id"`
+	Date     time.Time `json:"date"`
+	Items    []string  `json:"items"`    // Item titles, most expensive first
+	Category string    `json:"category"` // Amazon category of the most expensive item
+	Total    float64   `json:"total"`    // Amount charged for the order
+}
+
+// TransactionContext is one transaction with what surrounds it, for its
+// permalink page: the other transactions that day and the history of
+// payments to the same merchant
+type TransactionContext struct {
+	Transaction   Transaction   `json:"transaction"`
+	SameDay       []Transaction `json:"same_day"`
+	Merchant      string        `json:"merchant"`       // Normalized merchant the history matches on
+	History       []Transaction `json:"history"`        // Other transactions with the merchant, newest first
+	HistoryCount  int           `json:"history_count"`  // All transactions with the merchant, this one included
+	AverageAmount float64       `json:"average_amount"` // Their mean absolute amount
+}
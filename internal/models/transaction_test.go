@@ -0,0 +1,232 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilterByAmountRange(t *testing.T) {
+	ts := NewTransactionSet([]Transaction{
+		{Description: "small", Amount: -12.50},
+		{Description: "charge", Amount: -79.99},
+		{Description: "refund", Amount: 80.00},
+		{Description: "rent", Amount: -1850},
+	})
+
+	tests := []struct {
+		name     string
+		min, max float64
+		want     int
+	}{
+		{"around 80 matches charge and refund", 75, 85, 2},
+		{"bounds are inclusive", 12.50, 12.50, 1},
+		{"no match", 100, 200, 0},
+		{"open upper bound", 1000, 1e18, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ts.FilterByAmountRange(tt.min, tt.max).Len(); got != tt.want {
+				t.Errorf("FilterByAmountRange(%v, %v) returned %d, want %d", tt.min, tt.max, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterByWeekdays(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	ts := NewTransactionSet([]Transaction{
+		{Date: day("2025-03-07")}, // Friday
+		{Date: day("2025-03-08")}, // Saturday
+		{Date: day("2025-03-09")}, // Sunday
+		{Date: day("2025-03-10")}, // Monday
+	})
+
+	if got := ts.FilterByWeekdays([]time.Weekday{time.Saturday, time.Sunday}).Len(); got != 2 {
+		t.Errorf("weekend filter returned %d, want 2", got)
+	}
+	if got := ts.FilterByWeekdays([]time.Weekday{time.Friday}).Len(); got != 1 {
+		t.Errorf("Friday filter returned %d, want 1", got)
+	}
+	if got := ts.FilterByWeekdays(nil).Len(); got != 0 {
+		t.Errorf("empty day list returned %d, want 0", got)
+	}
+}
+
+func TestGroupByWeek(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	ts := NewTransactionSet([]Transaction{
+		{Date: day("2025-03-08")}, // Saturday
+		{Date: day("2025-03-09")}, // Sunday
+		{Date: day("2025-03-10")}, // Monday
+	})
+
+	monday := ts.GroupByWeek(time.Monday)
+	if len(monday) != 2 || monday["2025-03-03"].Len() != 2 || monday["2025-03-10"].Len() != 1 {
+		t.Errorf("Monday weeks = %v, want the weekend in the week of Mar 3", monday)
+	}
+	sunday := ts.GroupByWeek(time.Sunday)
+	if len(sunday) != 2 || sunday["2025-03-02"].Len() != 1 || sunday["2025-03-09"].Len() != 2 {
+		t.Errorf("Sunday weeks = %v, want Sunday and Monday in the week of Mar 9", sunday)
+	}
+
+	if d, ok := ParseWeekday(" Sun "); !ok || d != time.Sunday {
+		t.Errorf("ParseWeekday(Sun) = %v, %v", d, ok)
+	}
+	if _, ok := ParseWeekday("mo"); ok {
+		t.Error("ParseWeekday should reject abbreviations under three letters")
+	}
+}
+
+func TestFilterByCategories(t *testing.T) {
+	ts := NewTransactionSet([]Transaction{
+		{Category: "Groceries"},
+		{Category: "groceries"},
+		{Category: "Rent"},
+		{Category: "Dining Out"},
+		{Category: ""},
+	})
+
+	tests := []struct {
+		name   string
+		filter CategoryFilter
+		want   int
+	}{
+		{"empty keeps all", CategoryFilter{}, 5},
+		{"include many", CategoryFilter{Include: []string{"Groceries", "Rent"}}, 3},
+		{"exclude", CategoryFilter{Exclude: []string{"rent", "Dining Out"}}, 3},
+		{"include and exclude", CategoryFilter{Include: []string{"Groceries", "Rent"}, Exclude: []string{"Rent"}}, 2},
+		{"uncategorized only", CategoryFilter{Uncategorized: true}, 1},
+		{"uncategorized by name", CategoryFilter{Include: []string{"Uncategorized"}}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ts.FilterByCategories(tt.filter).Len(); got != tt.want {
+				t.Errorf("FilterByCategories(%+v) returned %d, want %d", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterByAccounts(t *testing.T) {
+	ts := NewTransactionSet([]Transaction{
+		{Account: "Checking"},
+		{Account: "checking"},
+		{Account: "Amex"},
+		{Account: ""},
+	})
+
+	if got := ts.Accounts(); len(got) != 3 || got[0] != "Amex" {
+		t.Errorf("Accounts() = %v, want Amex first among 3 names", got)
+	}
+	if got := ts.FilterByAccounts([]string{"CHECKING"}).Len(); got != 2 {
+		t.Errorf("FilterByAccounts(CHECKING) returned %d, want 2", got)
+	}
+	if got := ts.FilterByAccounts([]string{"Checking", "Amex"}).Len(); got != 3 {
+		t.Errorf("FilterByAccounts(Checking, Amex) returned %d, want 3", got)
+	}
+}
+
+func TestTags(t *testing.T) {
+	if got := ParseTags(" #Vacation 2024, reimbursable,,vacation-2024 "); len(got) != 2 || got[0] != "reimbursable" || got[1] != "vacation-2024" {
+		t.Errorf("ParseTags = %q, want reimbursable and vacation-2024", got)
+	}
+
+	ts := NewTransactionSet([]Transaction{
+		{Amount: -1200, TransactionType: Outflow, Tags: []string{"vacation2024"}},
+		{Amount: -300, TransactionType: Outflow, Tags: []string{"reimbursable", "vacation2024"}},
+		{Amount: 300, TransactionType: Income, Tags: []string{"reimbursable"}},
+		{Amount: -50, TransactionType: Outflow},
+	})
+
+	if got := ts.Tags(); len(got) != 2 || got[0] != "reimbursable" {
+		t.Errorf("Tags() = %v, want reimbursable and vacation2024", got)
+	}
+	if got := ts.FilterByTags([]string{"#Reimbursable"}).Len(); got != 2 {
+		t.Errorf("FilterByTags(#Reimbursable) returned %d, want 2", got)
+	}
+	if got := ts.FilterByTags([]string{"reimbursable", "vacation2024"}).Len(); got != 3 {
+		t.Errorf("FilterByTags(either) returned %d, want 3", got)
+	}
+
+	totals := ts.TagTotals()
+	if len(totals) != 2 || totals[0].Tag != "vacation2024" || totals[0].Spent != 1500 || totals[0].Count != 2 {
+		t.Fatalf("TagTotals() = %+v, want vacation2024 first with 1500 spent", totals)
+	}
+	if r := totals[1]; r.Spent != 300 || r.Received != 300 || r.Net != 0 {
+		t.Errorf("reimbursable = %+v, want 300 spent and received", r)
+	}
+}
+
+func TestCategoryHierarchy(t *testing.T) {
+	if got := ParentCategory("Food : Groceries"); got != "Food" {
+		t.Errorf("ParentCategory = %q, want Food", got)
+	}
+	if got := ParentCategory("Rent"); got != "" {
+		t.Errorf("ParentCategory(Rent) = %q, want blank", got)
+	}
+	for _, tt := range []struct {
+		category, parent string
+		want             bool
+	}{
+		{"Food:Groceries", "food", true},
+		{"Food:Groceries", "Food:Groceries", true},
+		{"Food", "Food:Groceries", false},
+		{"Foodstuffs", "Food", false},
+		{"", "Uncategorized", true},
+	} {
+		if got := InCategory(tt.category, tt.parent); got != tt.want {
+			t.Errorf("InCategory(%q, %q) = %v, want %v", tt.category, tt.parent, got, tt.want)
+		}
+	}
+
+	ts := NewTransactionSet([]Transaction{
+		{Category: "Food", Amount: -10},
+		{Category: "Food:Groceries", Amount: -100},
+		{Category: "food : groceries", Amount: -50},
+		{Category: "Food:Restaurants:Takeout", Amount: -30},
+		{Category: "Foodstuffs", Amount: -5},
+		{Category: "Rent", Amount: -1000},
+	})
+
+	filters := []struct {
+		name   string
+		filter CategoryFilter
+		want   int
+	}{
+		{"parent takes in subcategories", CategoryFilter{Include: []string{"Food"}}, 4},
+		{"subcategory alone", CategoryFilter{Include: []string{"Food:Groceries"}}, 2},
+		{"intermediate level", CategoryFilter{Include: []string{"Food:Restaurants"}}, 1},
+		{"excluding a parent", CategoryFilter{Exclude: []string{"food"}}, 2},
+		{"excluding a subcategory", CategoryFilter{Include: []string{"Food"}, Exclude: []string{"Food : Groceries"}}, 2},
+	}
+	for _, tt := range filters {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ts.FilterByCategories(tt.filter).Len(); got != tt.want {
+				t.Errorf("FilterByCategories(%+v) returned %d, want %d", tt.filter, got, tt.want)
+			}
+		})
+	}
+
+	tree := ts.CategoryTree()
+	var got []string
+	for _, n := range tree {
+		got = append(got, fmt.Sprintf("%s=%.0f/%.0f", n.Category, n.Own, n.Total))
+	}
+	want := "Food=10/190 Food:Groceries=150/150 Food:Restaurants=0/30 Food:Restaurants:Takeout=30/30 Foodstuffs=5/5 Rent=1000/1000"
+	if strings.Join(got, " ") != want {
+		t.Errorf("CategoryTree() = %v, want %s", got, want)
+	}
+	if tree[0].Leaf || !tree[1].Leaf || tree[2].Parent != "Food" || tree[3].Depth != 2 || tree[3].Name != "Takeout" {
+		t.Errorf("CategoryTree() nodes = %+v", tree[:4])
+	}
+}
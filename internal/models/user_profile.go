@@ -66,7 +66,7 @@ func DefaultUserSettings() *UserSettings {
 	}
 }
 
-// FileInfo represents metadata about an uploaded CSV file
+// FileInfo represents metadata about an uploaded data file
 type FileInfo struct {
 	Name         string `json:"name"`
 	Path         string `json:"path"`
@@ -75,4 +75,82 @@ type FileInfo struct {
 	Transactions int    `json:"transactions"`
 	MinDate      string `json:"min_date"`
 	MaxDate      string `json:"max_date"`
+	Duplicates   int    `json:"duplicates"` // Rows dropped as duplicates of rows already loaded
+
+	// How the file's amounts are signed, why, and whether the user chose it
+	SignConvention string `json:"sign_convention"`
+	SignReason     string `json:"sign_reason,omitempty"`
+	SignOverridden bool   `json:"sign_overridden,omitempty"`
+
+	// Account the file's transactions belong to, unless an account column
+	// says otherwise, and its kind
+	Account     string `json:"account"`
+	AccountType string `json:"account_type"`
+
+	// Set when strict loading rejected the file
+	LoadError string      `json:"load_error,omitempty"`
+	Issues    []LoadIssue `json:"issues,omitempty"`
+
+	// Fields a CSV file's columns don't supply, so none of its rows load,
+	// and the pattern of the column mapping applied to it, if any
+	MissingColumns []string `json:"missing_columns,omitempty"`
+	ColumnMapping  string   `json:"column_mapping,omitempty"`
+
+	// Set when bank sync writes the file
+	Sync *BankSyncAccount `json:"sync,omitempty"`
+
+	// Set when a Google Sheet is imported into the file
+	Sheet *SheetSync `json:"sheet,omitempty"`
+}
+
+// LoadIssue is one problem strict loading found in a data file
+type LoadIssue struct {
+	Line    int    `json:"line"` // Line in the file; 0 for the file as a whole
+	Column  string `json:"column,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message"`
+}
+
+// Sign conventions say how a data file signs its amounts. The app stores
+// money out as negative and money in as positive.
+const (
+	// SignDebitsNegative files already sign amounts the app's way
+	SignDebitsNegative = "debits_negative"
+	// SignDebitsPositive files list purchases as positive and payments as
+	// negative, as many card issuers do; every amount is negated
+	SignDebitsPositive = "debits_positive"
+	// SignTypeColumn files give each row's direction in a type column
+	// (Debit/Credit, Sale/Payment); amounts are signed to match it
+	SignTypeColumn = "type_column"
+)
+
+// SignConventions lists the sign conventions in the order offered to users
+var SignConventions = []string{SignDebitsNegative, SignDebitsPositive, SignTypeColumn}
+
+// MappableColumns are the fields a column mapping can assign a CSV column to
+var MappableColumns = []string{"Date", "Description", "Amount", "Debit", "Credit"}
+
+// ColumnMapping assigns the columns of CSV files whose headers the loader
+// doesn't recognize to the fields it reads
+type ColumnMapping struct {
+	Pattern string            `json:"pattern"` // File name or glob such as "chase_*.csv"
+	Columns map[string]string `json:"columns"` // Header by field in MappableColumns
+}
+
+// ColumnPreview is a CSV file's header and first rows, with the field each
+// column is read as
+type ColumnPreview struct {
+	File    string            `json:"file"`
+	Header  []string          `json:"header"`
+	Rows    [][]string        `json:"rows"`
+	Columns map[string]string `json:"columns"`           // Header read for each field, by field
+	Missing []string          `json:"missing,omitempty"` // Needed fields no column supplies
+	Mapping *ColumnMapping    `json:"mapping,omitempty"` // The user's mapping in effect
+}
+
+// DedupeReport counts the transactions the last load dropped as duplicates
+type DedupeReport struct {
+	Mode    string         `json:"mode"`
+	Removed int            `json:"removed"`
+	ByFile  map[string]int `json:"by_file"` // Dropped rows per file they came from
 }
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Budget cycles: how spending targets and projections are counted
+const (
+	CycleMonthly = "monthly"
+	CycleWeekly  = "weekly"
+)
+
+// BudgetSmoothedIncome is the budget source for a weekly target that follows
+// smoothed weekly income
+const BudgetSmoothedIncome = "smoothed_income"
+
+// BudgetCycle is the budgeting cycle. The weekly cycle suits irregular
+// incomes such as gig work, where a week's target follows what recent weeks
+// brought in rather than a fixed monthly budget.
+type BudgetCycle struct {
+	Cycle        string       // CycleMonthly or CycleWeekly
+	WeekStart    time.Weekday // First day of a budget week
+	WeeklyBudget float64      // Weekly spending target; zero follows smoothed income
+}
+
+// Weekly reports whether budgets are counted by week
+func (c BudgetCycle) Weekly() bool {
+	return c.Cycle == CycleWeekly
+}
+
+// ParseWeekday reads a day name such as "monday" or "Sun"
+func ParseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) < 3 {
+		return 0, false
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.HasPrefix(strings.ToLower(day.String()), s) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// WeekStartOf returns midnight on the first day of t's week, for weeks
+// starting on start
+func WeekStartOf(t time.Time, start time.Weekday) time.Time {
+	offset := (int(t.Weekday()) - int(start) + 7) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// WeekStatus is one budget week's income and spending against its target
+type WeekStatus struct {
+	Start          string  `json:"start"` // First day, "2025-12-29"
+	End            string  `json:"end"`   // Last day
+	Income         float64 `json:"income"`
+	SmoothedIncome float64 `json:"smoothed_income"` // Average weekly income over the weeks before this one
+	Spent          float64 `json:"spent"`
+	Target         float64 `json:"target"`
+	Remaining      float64 `json:"remaining"`
+	SpentChange    float64 `json:"spent_change"` // Percent change in spending from the week before; zero without one
+}
+
+// WeeklyStatus summarizes the week of the latest transaction against its
+// target, with the weeks before it for week-over-week comparison
+type WeeklyStatus struct {
+	AsOf         string       `json:"as_of"`                   // Latest transaction date
+	WeekStart    string       `json:"week_start"`              // Weekday weeks start on, e.g. "Monday"
+	TargetSource string       `json:"target_source,omitempty"` // BudgetConfigured or BudgetSmoothedIncome; empty without a target
+	WeekElapsed  float64      `json:"week_elapsed_percent"`    // Share of the week's days through AsOf
+	OnTrack      bool         `json:"on_track"`                // Spending is no further through the target than the week
+	Current      WeekStatus   `json:"current"`
+	Weeks        []WeekStatus `json:"weeks"` // Oldest first, ending with Current
+}
+
+// Previous returns the week before the current one, or nil without one
+func (s *WeeklyStatus) Previous() *WeekStatus {
+	if len(s.Weeks) < 2 {
+		return nil
+	}
+	return &s.Weeks[len(s.Weeks)-2]
+}
@@ -1,23 +1,44 @@
 package models
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
 // WhatIfSettings contains all user parameters for retirement planning
 type WhatIfSettings struct {
+	// Schema version of the persisted settings file
+	SchemaVersion int `json:"schema_version"`
+
 	// Portfolio
 	PortfolioValue float64 `json:"portfolio_value"` // Current portfolio value
 
+	// Named buckets with their own returns, drawn down in priority order.
+	// When set, PortfolioValue and InvestmentReturn follow the buckets'
+	// total and blended return (see SyncPortfolioBuckets).
+	PortfolioBuckets []PortfolioBucket `json:"portfolio_buckets,omitempty"`
+
 	// Expenses
 	MonthlyLivingExpenses float64 `json:"monthly_living_expenses"` // Base monthly expenses
 	MonthlyHealthcare     float64 `json:"monthly_healthcare"`      // Monthly healthcare costs (legacy)
 	HealthcareStartYears  int     `json:"healthcare_start_years"`  // Years until healthcare starts (legacy)
 
+	// Part of MonthlyLivingExpenses that keeps pace with inflation and never
+	// declines with SpendingDeclineRate (0 = no floor)
+	EssentialFloor float64 `json:"essential_floor"`
+
+	// How living expenses sync from transactions: the months averaged
+	// (0 = DefaultSyncWindow) and whether one-off purchases are left out
+	SyncWindowMonths   int  `json:"sync_window_months,omitempty"`
+	SyncExcludeOneOffs bool `json:"sync_exclude_one_offs,omitempty"`
+
 	// Multi-person healthcare model
 	HealthcarePersons []HealthcarePerson `json:"healthcare_persons,omitempty"`
 
 	// RMD Settings
 	CurrentAge         int     `json:"current_age"`          // User's current age
 	TaxDeferredPercent float64 `json:"tax_deferred_percent"` // % of portfolio in tax-deferred accounts
+	AnnualQCD          float64 `json:"annual_qcd"`           // Yearly RMD directed to qualified charitable distributions
 
 	// Rates (as percentages, e.g., 4.0 for 4%)
 	InflationRate         float64 `json:"inflation_rate"`          // Annual inflation
@@ -26,10 +47,25 @@ type WhatIfSettings struct {
 	InvestmentReturn      float64 `json:"investment_return"`       // Expected portfolio return
 	DiscountRate          float64 `json:"discount_rate"`           // For PV calculations
 
+	// Analysis engine used for projections ("" = standard calculator)
+	Engine string `json:"engine,omitempty"`
+
+	// Monte Carlo market modeling
+	CrashRecovery        string  `json:"crash_recovery,omitempty"`    // Recovery shape after a crash ("" = RecoveryV)
+	BearMarketYears      int     `json:"bear_market_years,omitempty"` // Longest bear market in years (0 or 1 = single-year crashes)
+	InflationCorrelation float64 `json:"inflation_correlation"`       // 0-1: how strongly high-inflation years drag down returns
+
+	// Longevity: Monte Carlo horizons are sampled from a mortality table
+	Sex    string `json:"sex,omitempty"`    // SexMale, SexFemale or "" to blend both
+	Health string `json:"health,omitempty"` // HealthExcellent, HealthGood, HealthAverage ("") or HealthPoor
+
 	// Projection
 	ProjectionYears         int     `json:"projection_years"`           // Number of years to project
 	SteadyStateOverrideYear float64 `json:"steady_state_override_year"` // User-adjustable projection year (0 = auto)
 
+	// Estate
+	LegacyTarget float64 `json:"legacy_target"` // Amount to leave heirs in today's dollars (0 = none)
+
 	// Income and Expense Sources
 	IncomeSources  []IncomeSource  `json:"income_sources"`
 	ExpenseSources []ExpenseSource `json:"expense_sources"`
@@ -37,6 +73,21 @@ type WhatIfSettings struct {
 	// Recently Removed (for restore functionality)
 	RemovedIncomeSources  []IncomeSource  `json:"removed_income_sources,omitempty"`
 	RemovedExpenseSources []ExpenseSource `json:"removed_expense_sources,omitempty"`
+
+	// Changes records how and when each input was last set, by setting key
+	// (see SettingKeys)
+	Changes map[string]SettingChange `json:"changes,omitempty"`
+}
+
+// HasEssentialFloor returns true if part of living expenses is essential
+func (s *WhatIfSettings) HasEssentialFloor() bool {
+	return s.EssentialFloor > 0
+}
+
+// EssentialLivingExpenses returns today's monthly living expenses under the
+// essential floor, which can't be more than the living expenses themselves
+func (s *WhatIfSettings) EssentialLivingExpenses() float64 {
+	return math.Max(0, math.Min(s.EssentialFloor, s.MonthlyLivingExpenses))
 }
 
 // GetTotalHealthcareCost returns total healthcare cost for a given month
@@ -71,6 +122,90 @@ func (s *WhatIfSettings) HasMultiPersonHealthcare() bool {
 	return len(s.HealthcarePersons) > 0
 }
 
+// Crash recovery shapes for Monte Carlo simulations
+const (
+	RecoveryV = "v" // Sharp rebound the year after a crash
+	RecoveryU = "u" // Two flat years at the bottom, then a rebound
+	RecoveryL = "l" // No rebound: several years of below-average returns
+)
+
+// Sexes for mortality tables
+const (
+	SexMale   = "male"
+	SexFemale = "female"
+)
+
+// Self-reported health ratings that scale mortality rates
+const (
+	HealthExcellent = "excellent"
+	HealthGood      = "good"
+	HealthAverage   = "average"
+	HealthPoor      = "poor"
+)
+
+// RecoveryShape returns the crash recovery shape, defaulting to RecoveryV
+func (s *WhatIfSettings) RecoveryShape() string {
+	switch s.CrashRecovery {
+	case RecoveryU, RecoveryL:
+		return s.CrashRecovery
+	default:
+		return RecoveryV
+	}
+}
+
+// SyncWindows are the averaging windows, in months, offered when syncing
+// living expenses from transactions
+var SyncWindows = []int{6, 12, 24}
+
+// DefaultSyncWindow is the months of spending a sync averages unless the
+// user picks another window
+const DefaultSyncWindow = 12
+
+// SyncWindow returns the months of spending a sync averages
+func (s *WhatIfSettings) SyncWindow() int {
+	if s.SyncWindowMonths <= 0 {
+		return DefaultSyncWindow
+	}
+	return s.SyncWindowMonths
+}
+
+// SyncWindowResult is living expenses synced over one window and how the
+// plan looks with them
+type SyncWindowResult struct {
+	Months          int     `json:"months"`
+	ExcludeOneOffs  bool    `json:"exclude_one_offs"`
+	Selected        bool    `json:"selected"`         // The window the settings sync with
+	MonthlyExpenses float64 `json:"monthly_expenses"` // Average monthly spending over the window
+	OneOffs         int     `json:"one_offs"`         // One-off purchases left out
+	OneOffTotal     float64 `json:"one_off_total"`
+
+	// Plan metrics with living expenses at this window's average
+	Score          int      `json:"score"`
+	Label          string   `json:"label"`
+	RequiredRate   float64  `json:"required_rate"`
+	Survives       bool     `json:"survives"`
+	LongevityYears *float64 `json:"longevity_years"`
+	FinalBalance   float64  `json:"final_balance"`
+}
+
+// HasAgeTimedIncome returns whether any income source is timed by age
+func (s *WhatIfSettings) HasAgeTimedIncome() bool {
+	for i := range s.IncomeSources {
+		if s.IncomeSources[i].ByAge() {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveIncomeAges derives the month offsets of age-timed income sources
+// from CurrentAge
+func (s *WhatIfSettings) ResolveIncomeAges() {
+	for i := range s.IncomeSources {
+		s.IncomeSources[i].ResolveAges(s.CurrentAge)
+	}
+}
+
 // DefaultWhatIfSettings returns sensible defaults for retirement planning
 func DefaultWhatIfSettings() *WhatIfSettings {
 	return &WhatIfSettings{
@@ -85,6 +220,7 @@ func DefaultWhatIfSettings() *WhatIfSettings {
 		SpendingDeclineRate:   1.0,
 		InvestmentReturn:      6.0,
 		DiscountRate:          5.0,
+		InflationCorrelation:  0.5,
 		ProjectionYears:       30,
 		IncomeSources:         []IncomeSource{},
 		ExpenseSources:        []ExpenseSource{},
@@ -108,6 +244,7 @@ type ProjectionMonth struct {
 	RMDWithdrawal      float64 `json:"rmd_withdrawal"` // Forced RMD withdrawal (age 73+)
 	PortfolioGrowth    float64 `json:"portfolio_growth"`
 	Depleted           bool    `json:"depleted"`
+	BucketBalances     []float64 `json:"bucket_balances,omitempty"` // Per bucket, in PortfolioBuckets order
 }
 
 // ProjectionResult contains the complete projection with summary metrics
@@ -117,6 +254,7 @@ type ProjectionResult struct {
 	FinalBalance    float64           `json:"final_balance"`
 	DepletionMonth  *int              `json:"depletion_month"`  // nil if no depletion
 	Survives        bool              `json:"survives"`
+	Buckets         []BucketResult    `json:"buckets,omitempty"` // Set when the portfolio is modeled as buckets
 }
 
 // BudgetFitAnalysis shows monthly gap and required rates
@@ -147,12 +285,23 @@ type BudgetFitAnalysis struct {
 
 // RMDProjection represents RMD estimates for a specific year
 type RMDProjection struct {
-	Age              int     `json:"age"`
-	Year             int     `json:"year"`              // Years from now
-	TaxDeferredBal   float64 `json:"tax_deferred_bal"`  // Estimated balance at start of year
-	LifeExpFactor    float64 `json:"life_exp_factor"`   // IRS Uniform Lifetime factor
-	RMDAmount        float64 `json:"rmd_amount"`        // Required distribution
-	RMDPercent       float64 `json:"rmd_percent"`       // RMD as % of tax-deferred balance
+	Age                int     `json:"age"`
+	Year               int     `json:"year"`                // Years from now
+	TaxDeferredBal     float64 `json:"tax_deferred_bal"`    // Estimated balance at start of year
+	LifeExpFactor      float64 `json:"life_exp_factor"`     // IRS Uniform Lifetime factor
+	RMDAmount          float64 `json:"rmd_amount"`          // Required distribution
+	RMDPercent         float64 `json:"rmd_percent"`         // RMD as % of tax-deferred balance
+	QCD                float64 `json:"qcd"`                 // Part of the RMD given directly to charity
+	TaxableRMD         float64 `json:"taxable_rmd"`         // RMD less QCD
+	TaxableIncome      float64 `json:"taxable_income"`      // Estimated MAGI: taxable RMD plus taxable income sources
+	IRMAATier          int     `json:"irmaa_tier"`          // 0 = no surcharge
+	IRMAASurcharge     float64 `json:"irmaa_surcharge"`     // Annual Medicare premium surcharge
+	IRMAASaved         float64 `json:"irmaa_saved"`         // Surcharge avoided by the QCD
+	FederalTax         float64 `json:"federal_tax"`         // Estimated federal income tax on TaxableIncome
+	MarginalRate       float64 `json:"marginal_rate"`       // Federal bracket the last dollar falls in (%)
+	RMDTax             float64 `json:"rmd_tax"`             // Federal tax the taxable RMD adds
+	WithholdingPercent float64 `json:"withholding_percent"` // Whole percent of the RMD to withhold to cover RMDTax
+	QuarterlyEstimate  float64 `json:"quarterly_estimate"`  // Each estimated payment if paying RMDTax quarterly instead
 }
 
 // RMDAnalysis contains RMD projections and summary
@@ -163,6 +312,9 @@ type RMDAnalysis struct {
 	TaxDeferredValue  float64          `json:"tax_deferred_value"`  // Current tax-deferred balance
 	Projections       []RMDProjection  `json:"projections"`         // Year-by-year projections
 	TotalRMDsOver10Yr float64          `json:"total_rmds_10yr"`     // Sum of first 10 years of RMDs
+	AnnualQCD         float64          `json:"annual_qcd"`          // Configured yearly QCD
+	TotalQCDsOver10Yr float64          `json:"total_qcds_10yr"`     // Taxable income avoided over the first 10 years
+	IRMAASaved10Yr    float64          `json:"irmaa_saved_10yr"`    // Medicare surcharges avoided over the first 10 years
 }
 
 // PresentValueAnalysis shows PV of expenses vs income
@@ -232,6 +384,30 @@ func CalculateSustainabilityScore(requiredRate float64, survives bool) *Sustaina
 	}
 }
 
+// ApplyLegacyTarget lowers a score when the estate left (in today's dollars)
+// falls short of the legacy target, by up to 30 points for leaving nothing
+func (s *SustainabilityScore) ApplyLegacyTarget(estateReal, target float64) {
+	if target <= 0 || estateReal >= target || s.Score == 0 {
+		return
+	}
+
+	funded := math.Max(0, estateReal) / target
+	s.Score = max(0, s.Score-int(math.Round(30*(1-funded))))
+	switch {
+	case s.Score >= 90:
+		s.Label, s.Color = "Good", "green"
+	case s.Score >= 75:
+		s.Label, s.Color = "Fair", "yellow"
+	case s.Score >= 60:
+		s.Label, s.Color = "Caution", "orange"
+	case s.Score >= 40:
+		s.Label, s.Color = "Poor", "orange"
+	default:
+		s.Label, s.Color = "Critical", "red"
+	}
+	s.Description += fmt.Sprintf("; leaves %.0f%% of the legacy target", funded*100)
+}
+
 // SensitivityScenario defines a parameter variation for testing
 type SensitivityScenario struct {
 	Name       string  `json:"name"`
@@ -281,6 +457,7 @@ type MonteCarloResult struct {
 	MidCrashes     int `json:"mid_crashes"`      // Crashes in years 6-15
 	LateCrashes    int `json:"late_crashes"`     // Crashes in years 16+
 	FirstCrashYear int `json:"first_crash_year"` // Year of first crash (0 if none)
+	BearMarkets    int `json:"bear_markets"`     // Crashes that ran on for more than a year
 }
 
 // SequenceRiskBreakdown provides detailed crash timing analysis
@@ -327,6 +504,13 @@ type SequenceRiskBreakdown struct {
 	EarlyCrashSurvivalAdapted float64 `json:"early_crash_survival_adapted"` // Survival with spending adaptation
 	AdaptationBoost           float64 `json:"adaptation_boost"`             // Improvement from adaptation (percentage points)
 	AdaptationRationale       string  `json:"adaptation_rationale"`         // Explanation of adaptation benefit
+
+	// Crash shape analysis
+	RecoveryShape       string  `json:"recovery_shape"`        // RecoveryV, RecoveryU or RecoveryL
+	BearMarketSurvival  float64 `json:"bear_market_survival"`  // Survival rate with at least one multi-year bear market
+	BearMarketCount     int     `json:"bear_market_count"`     // Runs with a multi-year bear market
+	SingleCrashSurvival float64 `json:"single_crash_survival"` // Survival rate when every crash lasted one year
+	SingleCrashCount    int     `json:"single_crash_count"`    // Runs whose crashes all lasted one year
 }
 
 // MonteCarloStats contains aggregated simulation statistics
@@ -343,6 +527,15 @@ type MonteCarloStats struct {
 	BestCase        float64 `json:"best_case"`        // Maximum final balance
 	AvgDepletionYr  float64 `json:"avg_depletion_yr"` // Avg years to depletion (failed runs only)
 
+	// Estate stats, in today's dollars
+	MedianEstateReal  float64 `json:"median_estate_real"`  // Median final balance deflated by each run's length
+	LegacySuccessRate float64 `json:"legacy_success_rate"` // % of scenarios leaving at least the legacy target
+
+	// Longevity, when run lengths come from the mortality table
+	MortalityHorizon bool    `json:"mortality_horizon"` // Runs last a sampled lifetime, so success means the money outlived you
+	MedianDeathAge   int     `json:"median_death_age"`  // Median age at death across runs
+	OutlivePlanRate  float64 `json:"outlive_plan_rate"` // % of runs still alive at the end of the fixed plan horizon
+
 	// Enhanced simulation stats
 	MarketCrashCount   int     `json:"market_crash_count"`   // Runs that experienced crashes
 	SpendingShockCount int     `json:"spending_shock_count"` // Runs with spending shocks
@@ -376,6 +569,7 @@ type MonteCarloAnalysis struct {
 // WhatIfAnalysis is the complete analysis container returned to templates
 type WhatIfAnalysis struct {
 	Settings       *WhatIfSettings       `json:"settings"`
+	Engine         string                `json:"engine"` // Engine that produced the projection
 	Projection     *ProjectionResult     `json:"projection"`
 	BudgetFit      *BudgetFitAnalysis    `json:"budget_fit"`
 	PresentValue   *PresentValueAnalysis `json:"present_value"`
@@ -384,6 +578,98 @@ type WhatIfAnalysis struct {
 	FailurePoints  *FailurePointAnalysis `json:"failure_points"`
 	MonteCarlo     *MonteCarloAnalysis   `json:"monte_carlo"`
 	RMD            *RMDAnalysis          `json:"rmd"`
+	Estate         *EstateProjection     `json:"estate"`
+	Bridge         *BridgeAnalysis       `json:"bridge"`
+	Warnings       []SanityWarning       `json:"warnings"` // Caveats on unrealistic inputs
+}
+
+// SanityWarning flags an input that's allowed but unrealistic, so results
+// built on it carry a caveat
+type SanityWarning struct {
+	Field   string `json:"field"` // Setting key (see SettingKeys)
+	Message string `json:"message"`
+}
+
+// BridgeAnalysis covers the years before delayed income (Social Security, a
+// pension) starts, when the portfolio carries spending on its own
+type BridgeAnalysis struct {
+	HasBridge        bool         `json:"has_bridge"`
+	Months           int          `json:"months"`            // Length of the bridge
+	EndAge           int          `json:"end_age"`           // Age when the last delayed income starts
+	DelayedSources   []string     `json:"delayed_sources"`   // Income the bridge waits for
+	TotalWithdrawals float64      `json:"total_withdrawals"` // Everything drawn from the portfolio during the bridge
+	ExtraWithdrawals float64      `json:"extra_withdrawals"` // The part delayed income would have covered
+	PortfolioShare   float64      `json:"portfolio_share"`   // Extra withdrawals as a % of today's portfolio
+	LadderYield      float64      `json:"ladder_yield"`      // Assumed yield on the ladder (%)
+	LadderCost       float64      `json:"ladder_cost"`       // Cost today of a ladder paying every rung
+	LadderShare      float64      `json:"ladder_share"`      // Ladder cost as a % of today's portfolio
+	Rungs            []BridgeRung `json:"rungs"`
+}
+
+// BridgeRung is one year of a bond/cash ladder sized to the bridge
+type BridgeRung struct {
+	Year       int     `json:"year"` // Years from now
+	Age        int     `json:"age"`
+	Withdrawal float64 `json:"withdrawal"` // Total portfolio withdrawals that year
+	Amount     float64 `json:"amount"`     // Extra withdrawals the rung pays
+	Cost       float64 `json:"cost"`       // Present value of the rung
+}
+
+// EstateProjection is the expected estate left at the end of the plan under
+// the deterministic and median Monte Carlo paths, compared with the legacy
+// target. Real values are in today's dollars.
+type EstateProjection struct {
+	Age                 int     `json:"age"`   // Age at the end of the plan
+	Years               int     `json:"years"` // Years until then
+	Deterministic       float64 `json:"deterministic"`
+	DeterministicReal   float64 `json:"deterministic_real"`
+	MonteCarloMedian    float64 `json:"monte_carlo_median"`
+	MedianReal          float64 `json:"median_real"`
+	LegacyTarget        float64 `json:"legacy_target"`         // Today's dollars
+	LegacyTargetNominal float64 `json:"legacy_target_nominal"` // Inflated to the end of the plan
+	MeetsTarget         bool    `json:"meets_target"`          // Deterministic path
+	MedianMeetsTarget   bool    `json:"median_meets_target"`
+	LegacySuccessRate   float64 `json:"legacy_success_rate"` // % of Monte Carlo runs meeting the target
+}
+
+// StressScenario is a named market regime replayed over the first years of
+// every Monte Carlo run in place of randomly generated returns
+type StressScenario struct {
+	Name        string    `json:"name"`
+	Label       string    `json:"label"`
+	Description string    `json:"description"`
+	Returns     []float64 `json:"returns"`   // Annual returns (%), one per year from the start of the plan
+	Inflation   []float64 `json:"inflation"` // Annual inflation (%) for the same years
+}
+
+// StressTestResult compares a stress scenario's Monte Carlo outcome with the
+// unstressed simulation over the same random seed
+type StressTestResult struct {
+	Scenario            StressScenario   `json:"scenario"`
+	Stressed            *MonteCarloStats `json:"stressed"`
+	Baseline            *MonteCarloStats `json:"baseline"`
+	SuccessRateChange   float64          `json:"success_rate_change"`   // Percentage points versus baseline
+	MedianBalanceChange float64          `json:"median_balance_change"` // Versus baseline
+}
+
+// SweepPoint is the plan's outcome with one setting overridden
+type SweepPoint struct {
+	Value          float64  `json:"value"`
+	SuccessRate    float64  `json:"success_rate"`    // % of Monte Carlo runs that survive
+	MedianBalance  float64  `json:"median_balance"`  // Monte Carlo median final balance
+	FinalBalance   float64  `json:"final_balance"`   // Deterministic projection's final balance
+	Survives       bool     `json:"survives"`
+	LongevityYears *float64 `json:"longevity_years"` // nil if portfolio survives
+	Score          int      `json:"score"`
+}
+
+// SweepResult is a parameter sweep over one setting. Every point's Monte
+// Carlo uses the same random seed, so differences come from the setting.
+type SweepResult struct {
+	Param   string       `json:"param"`   // Setting key
+	Current float64      `json:"current"` // The setting's saved value
+	Runs    int          `json:"runs"`    // Monte Carlo runs per point
+	Points  []SweepPoint `json:"points"`
 }
 
 // WhatIfPageData is the data passed to the whatif template
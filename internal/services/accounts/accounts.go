@@ -0,0 +1,243 @@
+package accounts
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// typesFile holds each account's type
+var typesFile = storage.SettingsFile("account_types.json", func() interface{} { return &map[string]string{} })
+
+// accountsFile holds the accounts
+var accountsFile = storage.SettingsFile("accounts.json", func() interface{} { return &[]models.Account{} })
+
+// Manager persists the accounts the user set up and the account type set
+// for each data file outside them. Files without either are bank accounts.
+type Manager struct {
+	path         string // Account types by file
+	accountsPath string
+	store        *storage.Storage
+	mu           sync.Mutex
+}
+
+// NewManager creates a manager storing accounts and account types in
+// settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:         filepath.Join(settingsDir, typesFile),
+		accountsPath: filepath.Join(settingsDir, accountsFile),
+		store:        store,
+	}
+}
+
+// List returns the account types set by file name
+func (m *Manager) List() (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Set makes file one of models.AccountTypes. A blank or bank type goes
+// back to the default. A file mapped to an account takes the account's
+// type instead.
+func (m *Manager) Set(file, accountType string) error {
+	if file == "" {
+		return fmt.Errorf("no file given")
+	}
+	if accountType != "" && !slices.Contains(models.AccountTypes, accountType) {
+		return fmt.Errorf("unknown account type %q", accountType)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	types, err := m.loadInternal()
+	if err != nil {
+		return err
+	}
+	if accountType == "" || accountType == models.AccountBank {
+		delete(types, file)
+	} else {
+		types[file] = accountType
+	}
+	return m.store.WriteJSON(m.path, types)
+}
+
+// AccountType returns the account type set for file, or models.AccountBank
+func (m *Manager) AccountType(file string) string {
+	types, err := m.List()
+	if err != nil {
+		log.Printf("Warning: failed to load account types: %v", err)
+		return models.AccountBank
+	}
+	if t, ok := types[file]; ok {
+		return t
+	}
+	return models.AccountBank
+}
+
+// Accounts returns the accounts the user set up, sorted by name
+func (m *Manager) Accounts() ([]models.Account, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadAccountsInternal()
+}
+
+// SaveAccount adds a, or replaces the account named previous (or a's name
+// when previous is blank), matching names ignoring case. Each file belongs
+// to one account, so a's files are taken from any other account holding
+// them.
+func (m *Manager) SaveAccount(a models.Account, previous string) ([]models.Account, error) {
+	a.Name = strings.TrimSpace(a.Name)
+	a.Institution = strings.TrimSpace(a.Institution)
+	if a.Name == "" {
+		return nil, fmt.Errorf("account name is required")
+	}
+	if a.Type == "" {
+		a.Type = models.AccountBank
+	}
+	if !slices.Contains(models.AccountTypes, a.Type) {
+		return nil, fmt.Errorf("unknown account type %q", a.Type)
+	}
+	var files []string
+	for _, f := range a.Files {
+		if f = strings.TrimSpace(f); f != "" && !slices.Contains(files, f) {
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	a.Files = files
+	if strings.TrimSpace(previous) == "" {
+		previous = a.Name
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadAccountsInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make([]models.Account, 0, len(list)+1)
+	for _, existing := range list {
+		if strings.EqualFold(existing.Name, previous) {
+			continue
+		}
+		if strings.EqualFold(existing.Name, a.Name) {
+			return nil, fmt.Errorf("an account named %q already exists", existing.Name)
+		}
+		existing.Files = slices.DeleteFunc(existing.Files, func(f string) bool {
+			return slices.Contains(a.Files, f)
+		})
+		kept = append(kept, existing)
+	}
+	kept = append(kept, a)
+	sortByName(kept)
+
+	if err := m.store.WriteJSON(m.accountsPath, kept); err != nil {
+		return nil, err
+	}
+	return kept, nil
+}
+
+// RemoveAccount drops the account named name. Its files go back to being
+// accounts of their own.
+func (m *Manager) RemoveAccount(name string) ([]models.Account, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadAccountsInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	kept := slices.DeleteFunc(list, func(a models.Account) bool {
+		return strings.EqualFold(a.Name, strings.TrimSpace(name))
+	})
+	if err := m.store.WriteJSON(m.accountsPath, kept); err != nil {
+		return nil, err
+	}
+	return kept, nil
+}
+
+// Resolve returns the account a row of file belongs to. name is the row's
+// account column, if the file has one; a set-up account by that name is
+// used, and otherwise the name stands for an account of the file's type.
+// Without a name, the row belongs to the account file is mapped to. The
+// returned name is blank when nothing names the account.
+func (m *Manager) Resolve(file, name string) models.Account {
+	accounts, err := m.Accounts()
+	if err != nil {
+		log.Printf("Warning: failed to load accounts: %v", err)
+	}
+
+	name = strings.TrimSpace(name)
+	for _, a := range accounts {
+		if name != "" && strings.EqualFold(a.Name, name) {
+			return a
+		}
+		if name == "" && slices.Contains(a.Files, file) {
+			return a
+		}
+	}
+	return models.Account{Name: name, Type: m.AccountType(file)}
+}
+
+// Version changes whenever the accounts or account types do, so the loader
+// reloads data
+func (m *Manager) Version() string {
+	var parts []string
+	for _, path := range []string{m.path, m.accountsPath} {
+		if info, err := m.store.Stat(path); err == nil {
+			parts = append(parts, fmt.Sprintf("%d|%d", info.Size(), info.ModTime().UnixNano()))
+		} else {
+			parts = append(parts, "")
+		}
+	}
+	if parts[0] == "" && parts[1] == "" {
+		return ""
+	}
+	return "accounts|" + strings.Join(parts, "|")
+}
+
+// loadInternal reads the account types without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() (map[string]string, error) {
+	types := make(map[string]string)
+	if err := m.store.ReadJSON(m.path, &types); err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]string), nil
+		}
+		return nil, err
+	}
+	return types, nil
+}
+
+// loadAccountsInternal reads the accounts without acquiring lock (caller must hold lock)
+func (m *Manager) loadAccountsInternal() ([]models.Account, error) {
+	var list []models.Account
+	if err := m.store.ReadJSON(m.accountsPath, &list); err != nil {
+		if os.IsNotExist(err) {
+			return []models.Account{}, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+func sortByName(list []models.Account) {
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+}
@@ -0,0 +1,116 @@
+package accounts
+
+import (
+	"testing"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func TestSetAccountType(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	if v := manager.Version(); v != "" {
+		t.Errorf("version without account types = %q, want empty", v)
+	}
+	if a := manager.AccountType("checking.csv"); a != models.AccountBank {
+		t.Errorf("unset file account type = %q, want %q", a, models.AccountBank)
+	}
+
+	if err := manager.Set("amex.csv", models.AccountCreditCard); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if a := manager.AccountType("amex.csv"); a != models.AccountCreditCard {
+		t.Errorf("account type = %q, want %q", a, models.AccountCreditCard)
+	}
+	v1 := manager.Version()
+
+	// Bank is the default, so setting it clears the entry
+	manager.Set("amex.csv", models.AccountBank)
+	if list, _ := manager.List(); len(list) != 0 {
+		t.Errorf("account types = %v, want none", list)
+	}
+	if manager.Version() == v1 {
+		t.Error("version should change when account types do")
+	}
+
+	if err := manager.Set("amex.csv", "brokerage"); err == nil {
+		t.Error("Set should reject an unknown account type")
+	}
+	if err := manager.Set("", models.AccountInvestment); err == nil {
+		t.Error("Set should reject a blank file name")
+	}
+}
+
+func TestSaveAccount(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	if _, err := manager.SaveAccount(models.Account{Name: " "}, ""); err == nil {
+		t.Error("SaveAccount should require a name")
+	}
+	if _, err := manager.SaveAccount(models.Account{Name: "Amex", Type: "brokerage"}, ""); err == nil {
+		t.Error("SaveAccount should reject an unknown account type")
+	}
+
+	manager.SaveAccount(models.Account{Name: "Checking", Files: []string{"chase.csv", "old-chase.csv"}}, "")
+	list, err := manager.SaveAccount(models.Account{Name: "Amex", Type: models.AccountCreditCard, Files: []string{"amex.csv", "old-chase.csv"}}, "")
+	if err != nil {
+		t.Fatalf("SaveAccount: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "Amex" || list[1].Name != "Checking" {
+		t.Fatalf("accounts = %+v, want Amex then Checking", list)
+	}
+	if len(list[1].Files) != 1 || list[1].Files[0] != "chase.csv" {
+		t.Errorf("Checking files = %v, want old-chase.csv moved to Amex", list[1].Files)
+	}
+	if list[0].Type != models.AccountCreditCard || list[1].Type != models.AccountBank {
+		t.Errorf("types = %s, %s, want credit_card and the bank default", list[0].Type, list[1].Type)
+	}
+
+	if _, err := manager.SaveAccount(models.Account{Name: "checking"}, "Amex"); err == nil {
+		t.Error("renaming onto another account's name should fail")
+	}
+	list, _ = manager.SaveAccount(models.Account{Name: "Amex Gold", Type: models.AccountCreditCard, Institution: "American Express"}, "amex")
+	if len(list) != 2 || list[0].Name != "Amex Gold" || list[0].Institution != "American Express" {
+		t.Errorf("rename left %+v", list)
+	}
+
+	list, _ = manager.RemoveAccount("AMEX GOLD")
+	if len(list) != 1 || list[0].Name != "Checking" {
+		t.Errorf("RemoveAccount left %+v, want only Checking", list)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	manager.Set("export.csv", models.AccountInvestment)
+	v1 := manager.Version()
+	manager.SaveAccount(models.Account{Name: "Sapphire", Type: models.AccountCreditCard, Files: []string{"chase.csv"}}, "")
+	if manager.Version() == v1 {
+		t.Error("version should change when accounts do")
+	}
+
+	tests := []struct {
+		file, name string
+		want       models.Account
+	}{
+		{"chase.csv", "", models.Account{Name: "Sapphire", Type: models.AccountCreditCard}},
+		{"export.csv", "sapphire", models.Account{Name: "Sapphire", Type: models.AccountCreditCard}},
+		{"export.csv", "Brokerage", models.Account{Name: "Brokerage", Type: models.AccountInvestment}},
+		{"export.csv", "", models.Account{Name: "", Type: models.AccountInvestment}},
+		{"other.csv", "", models.Account{Name: "", Type: models.AccountBank}},
+	}
+	for _, tt := range tests {
+		got := manager.Resolve(tt.file, tt.name)
+		if got.Name != tt.want.Name || got.Type != tt.want.Type {
+			t.Errorf("Resolve(%q, %q) = %s/%s, want %s/%s", tt.file, tt.name, got.Name, got.Type, tt.want.Name, tt.want.Type)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package amazon
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// ordersFile holds the imported Amazon orders
+var ordersFile = storage.SettingsFile("amazon_orders.json", func() interface{} { return &[]models.AmazonOrder{} })
+
+// MatchWindowDays is how long after the order date Amazon may charge the
+// card; orders are charged when they ship, not when they are placed
+const MatchWindowDays = 7
+
+// DescriptionPrefix starts the description of every enriched charge
+const DescriptionPrefix = "Amazon: "
+
+// maxTitleLength keeps long Amazon product titles readable in tables
+const maxTitleLength = 60
+
+// ChargeKeywords identify Amazon charges on card statements (lowercase)
+var ChargeKeywords = []string{"amzn", "amazon"}
+
+// CategoryMap maps Amazon product categories to budget categories. The first
+// entry whose keyword appears in the lowercase Amazon category wins.
+var CategoryMap = []struct {
+	Keyword  string
+	Category string
+}{
+	{"grocery", "Groceries"},
+	{"gourmet", "Groceries"},
+	{"pet", "Pets"},
+	{"beauty", "Personal Care"},
+	{"personal care", "Personal Care"},
+	{"health", "Health & Fitness"},
+	{"baby", "Kids"},
+	{"toy", "Kids"},
+	{"book", "Books"},
+	{"kindle", "Books"},
+	{"electronic", "Electronics"},
+	{"computer", "Electronics"},
+	{"wireless", "Electronics"},
+	{"apparel", "Clothing"},
+	{"shoes", "Clothing"},
+	{"jewelry", "Clothing"},
+	{"tools", "Home Improvement"},
+	{"home improvement", "Home Improvement"},
+	{"kitchen", "Home"},
+	{"furniture", "Home"},
+	{"home", "Home"},
+	{"office", "Office Supplies"},
+	{"sports", "Health & Fitness"},
+}
+
+// Manager persists imported Amazon orders and enriches matching card charges
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing orders in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, ordersFile),
+		store: store,
+	}
+}
+
+// List returns all imported orders, newest first
+func (m *Manager) List() ([]models.AmazonOrder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Import merges orders into the saved orders, replacing any with the same
+// order ID so re-importing an overlapping export is safe
+func (m *Manager) Import(orders []models.AmazonOrder) ([]models.AmazonOrder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]models.AmazonOrder, len(existing)+len(orders))
+	for _, o := range existing {
+		byID[o.ID] = o
+	}
+	for _, o := range orders {
+		byID[o.ID] = o
+	}
+
+	merged := make([]models.AmazonOrder, 0, len(byID))
+	for _, o := range byID {
+		merged = append(merged, o)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if !merged[i].Date.Equal(merged[j].Date) {
+			return merged[i].Date.After(merged[j].Date)
+		}
+		return merged[i].ID < merged[j].ID
+	})
+
+	if err := m.store.WriteJSON(m.path, merged); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// Clear removes all imported orders
+func (m *Manager) Clear() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.store.Remove(m.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Version fingerprints the saved orders so the data loader's version, and
+// every cache keyed by it, changes when orders are imported or cleared
+func (m *Manager) Version() string {
+	info, err := m.store.Stat(m.path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("amazon|%d|%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// Enrich replaces matched Amazon charges' descriptions and categories with
+// the ordered items. Transactions are returned unchanged if the orders
+// can't be read.
+func (m *Manager) Enrich(transactions []models.Transaction) []models.Transaction {
+	orders, err := m.List()
+	if err != nil {
+		log.Printf("Warning: failed to load Amazon orders: %v", err)
+		return transactions
+	}
+	if n := Match(transactions, orders); n > 0 {
+		log.Printf("Matched %d Amazon charges to orders", n)
+	}
+	return transactions
+}
+
+// loadInternal reads orders without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() ([]models.AmazonOrder, error) {
+	var orders []models.AmazonOrder
+	if err := m.store.ReadJSON(m.path, &orders); err != nil {
+		if os.IsNotExist(err) {
+			return []models.AmazonOrder{}, nil
+		}
+		return nil, err
+	}
+	return orders, nil
+}
+
+// IsCharge reports whether a transaction looks like an Amazon card charge
+func IsCharge(t *models.Transaction) bool {
+	if t.Amount >= 0 || t.TransactionType == models.Income {
+		return false
+	}
+	desc := strings.ToLower(t.Description)
+	for _, kw := range ChargeKeywords {
+		if strings.Contains(desc, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEnriched reports whether a transaction's description came from an order
+func IsEnriched(t *models.Transaction) bool {
+	return strings.HasPrefix(t.Description, DescriptionPrefix)
+}
+
+// Match pairs Amazon charges in transactions with orders of the same amount
+// charged within MatchWindowDays of the order date, closest date first, and
+// rewrites each matched charge in place, keeping categories the user set by
+// hand. Each order matches at most one charge. It returns the number of charges matched.
+func Match(transactions []models.Transaction, orders []models.AmazonOrder) int {
+	if len(orders) == 0 {
+		return 0
+	}
+
+	var charges []int
+	for i := range transactions {
+		if IsCharge(&transactions[i]) && !IsEnriched(&transactions[i]) {
+			charges = append(charges, i)
+		}
+	}
+	sort.SliceStable(charges, func(a, b int) bool {
+		return transactions[charges[a]].Date.Before(transactions[charges[b]].Date)
+	})
+
+	used := make([]bool, len(orders))
+	matched := 0
+	for _, i := range charges {
+		t := &transactions[i]
+		best, bestDays := -1, 0.0
+		for j, o := range orders {
+			if used[j] || math.Abs(o.Total-math.Abs(t.Amount)) >= 0.01 {
+				continue
+			}
+			days := t.Date.Sub(o.Date).Hours() / 24
+			if days < -1 || days > MatchWindowDays {
+				continue
+			}
+			if best < 0 || math.Abs(days) < bestDays {
+				best, bestDays = j, math.Abs(days)
+			}
+		}
+		if best < 0 {
+			continue
+		}
+
+		used[best] = true
+		matched++
+		o := orders[best]
+		t.Description = Describe(o)
+		if category := MapCategory(o.Category); category != "" && !t.CategoryEdited {
+			t.Category = category
+		}
+	}
+	return matched
+}
+
+// Describe summarizes an order's items for a transaction description
+func Describe(o models.AmazonOrder) string {
+	if len(o.Items) == 0 {
+		return DescriptionPrefix + "Order " + o.ID
+	}
+	title := strings.TrimSpace(o.Items[0])
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		title = strings.TrimSpace(string(runes[:maxTitleLength])) + "…"
+	}
+	if len(o.Items) > 1 {
+		title += fmt.Sprintf(" +%d more", len(o.Items)-1)
+	}
+	return DescriptionPrefix + title
+}
+
+// MapCategory returns the budget category for an Amazon category, or "" to
+// keep the charge's existing category
+func MapCategory(amazonCategory string) string {
+	lower := strings.ToLower(strings.TrimSpace(amazonCategory))
+	if lower == "" {
+		return ""
+	}
+	for _, m := range CategoryMap {
+		if strings.Contains(lower, m.Keyword) {
+			return m.Category
+		}
+	}
+	return ""
+}
+
+// orderColumns lists the header names used by Amazon's order exports: the
+// older order-history "Items" report and the newer privacy data export
+var orderColumns = map[string][]string{
+	"id":       {"order id"},
+	"date":     {"order date"},
+	"title":    {"title", "product name"},
+	"category": {"category"},
+	"total":    {"item total", "total owed", "total charged"},
+}
+
+// orderDateFormats covers both exports' order date formats
+var orderDateFormats = []string{
+	time.RFC3339,
+	"2006-01-02",
+	"01/02/2006",
+	"1/2/2006",
+	"01/02/06",
+	"1/2/06",
+}
+
+// ParseOrders reads an Amazon order-history CSV. Each row is one item;
+// items are grouped into orders by order ID.
+func ParseOrders(r io.Reader) ([]models.AmazonOrder, error) {
+	// Amazon's exports start with a byte order mark, which trips up the
+	// quoted header
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(3); err == nil && string(bom) == "\ufeff" {
+		br.Discard(3)
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header: %w", err)
+	}
+
+	colIndex := make(map[string]int)
+	for i, col := range header {
+		name := strings.ToLower(strings.TrimSpace(col))
+		for key, variants := range orderColumns {
+			for _, v := range variants {
+				if _, seen := colIndex[key]; !seen && name == v {
+					colIndex[key] = i
+				}
+			}
+		}
+	}
+	for _, key := range []string{"id", "date", "title", "total"} {
+		if _, ok := colIndex[key]; !ok {
+			return nil, fmt.Errorf("not an Amazon order history export: missing %q column (tried: %v)", key, orderColumns[key])
+		}
+	}
+
+	field := func(record []string, key string) string {
+		if idx, ok := colIndex[key]; ok && idx < len(record) {
+			return strings.TrimSpace(record[idx])
+		}
+		return ""
+	}
+
+	type item struct {
+		title    string
+		category string
+		total    float64
+	}
+	var order []string
+	items := make(map[string][]item)
+	dates := make(map[string]time.Time)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		id := field(record, "id")
+		date := parseOrderDate(field(record, "date"))
+		if id == "" || date.IsZero() {
+			continue
+		}
+		if _, ok := items[id]; !ok {
+			order = append(order, id)
+			dates[id] = date
+		}
+		items[id] = append(items[id], item{
+			title:    field(record, "title"),
+			category: field(record, "category"),
+			total:    parseTotal(field(record, "total")),
+		})
+	}
+
+	orders := make([]models.AmazonOrder, 0, len(order))
+	for _, id := range order {
+		its := items[id]
+		sort.SliceStable(its, func(i, j int) bool {
+			return its[i].total > its[j].total
+		})
+
+		o := models.AmazonOrder{ID: id, Date: dates[id], Category: its[0].category}
+		for _, it := range its {
+			o.Total += it.total
+			if it.title != "" {
+				o.Items = append(o.Items, it.title)
+			}
+		}
+		o.Total = math.Round(o.Total*100) / 100
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+// parseOrderDate tries each export's date format, ignoring the time of day
+func parseOrderDate(s string) time.Time {
+	for _, format := range orderDateFormats {
+		if t, err := time.Parse(format, s); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		}
+	}
+	return time.Time{}
+}
+
+// parseTotal parses an amount such as "$1,234.56"
+func parseTotal(s string) float64 {
+	s = strings.ReplaceAll(s, "$", "")
+	s = strings.ReplaceAll(s, ",", "")
+	amount, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return amount
+}
@@ -0,0 +1,133 @@
+package amazon
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func day(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+func TestParseOrdersItemsReport(t *testing.T) {
+	csv := `Order Date,Order ID,Title,Category,Item Total
+01/15/2025,111-1,USB-C Cable,Electronics,$12.99
+01/15/2025,111-1,Laptop Stand,Office Product,$34.50
+01/20/25,222-2,Dog Food,Pet Products,"$1,020.00"
+`
+	orders, err := ParseOrders(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 2 {
+		t.Fatalf("expected 2 orders, got %+v", orders)
+	}
+	first := orders[0]
+	if first.ID != "111-1" || first.Total != 47.49 || len(first.Items) != 2 {
+		t.Errorf("first order = %+v, want both items totalling 47.49", first)
+	}
+	if first.Items[0] != "Laptop Stand" || first.Category != "Office Product" {
+		t.Errorf("most expensive item should lead, got %v in %q", first.Items, first.Category)
+	}
+	if !orders[1].Date.Equal(day("2025-01-20")) || orders[1].Total != 1020 {
+		t.Errorf("second order = %+v", orders[1])
+	}
+}
+
+func TestParseOrdersDataExport(t *testing.T) {
+	csv := "\ufeff" + `"Order ID","Order Date","Total Owed","Product Name"
+"333-3","2025-02-03T18:22:33Z","19.99","Paperback Novel"
+`
+	orders, err := ParseOrders(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 1 || !orders[0].Date.Equal(day("2025-02-03")) || orders[0].Total != 19.99 {
+		t.Errorf("orders = %+v", orders)
+	}
+
+	if _, err := ParseOrders(strings.NewReader("Date,Description,Amount\n")); err == nil {
+		t.Error("expected an error for a bank export")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	orders := []models.AmazonOrder{
+		{ID: "a", Date: day("2025-03-01"), Items: []string{"Coffee Beans"}, Category: "Grocery & Gourmet Food", Total: 24.99},
+		{ID: "b", Date: day("2025-03-05"), Items: []string{"HDMI Cable", "Mouse"}, Category: "Electronics", Total: 30},
+		{ID: "c", Date: day("2025-03-06"), Items: []string{"Phone Case"}, Total: 30},
+	}
+	txns := []models.Transaction{
+		{Date: day("2025-03-03"), Description: "AMZN Mktp US*2K4", Amount: -24.99, Category: "Shopping", TransactionType: models.Outflow},
+		{Date: day("2025-03-05"), Description: "AMZN Mktp US*7Q1", Amount: -30, Category: "Shopping", TransactionType: models.Outflow},
+		{Date: day("2025-03-07"), Description: "AMAZON.COM*9Z2", Amount: -30, Category: "Shopping", TransactionType: models.Outflow},
+		// Outside the window, not Amazon, and a refund
+		{Date: day("2025-03-20"), Description: "AMZN Mktp US*1A1", Amount: -24.99, TransactionType: models.Outflow},
+		{Date: day("2025-03-03"), Description: "TARGET", Amount: -24.99, TransactionType: models.Outflow},
+		{Date: day("2025-03-08"), Description: "AMZN Mktp refund", Amount: 30, TransactionType: models.Outflow},
+	}
+
+	if n := Match(txns, orders); n != 3 {
+		t.Fatalf("matched %d charges, want 3: %+v", n, txns)
+	}
+	if txns[0].Description != "Amazon: Coffee Beans" || txns[0].Category != "Groceries" {
+		t.Errorf("charge 0 = %q in %q", txns[0].Description, txns[0].Category)
+	}
+	// Same amounts go to the closest order date
+	if txns[1].Description != "Amazon: HDMI Cable +1 more" || txns[1].Category != "Electronics" {
+		t.Errorf("charge 1 = %q in %q", txns[1].Description, txns[1].Category)
+	}
+	if txns[2].Description != "Amazon: Phone Case" || txns[2].Category != "Shopping" {
+		t.Errorf("uncategorized order should keep the category, got %q in %q", txns[2].Description, txns[2].Category)
+	}
+	for _, i := range []int{3, 4, 5} {
+		if IsEnriched(&txns[i]) {
+			t.Errorf("charge %d should not match: %q", i, txns[i].Description)
+		}
+	}
+}
+
+func TestMatchKeepsEditedCategory(t *testing.T) {
+	orders := []models.AmazonOrder{{ID: "a", Date: day("2025-03-01"), Items: []string{"Coffee Beans"}, Category: "Grocery & Gourmet Food", Total: 24.99}}
+	txns := []models.Transaction{
+		{Date: day("2025-03-03"), Description: "AMZN Mktp US*2K4", Amount: -24.99, Category: "Gifts", CategoryEdited: true, TransactionType: models.Outflow},
+	}
+
+	Match(txns, orders)
+	if txns[0].Description != "Amazon: Coffee Beans" || txns[0].Category != "Gifts" {
+		t.Errorf("charge = %q in %q, want the items with the category set by hand", txns[0].Description, txns[0].Category)
+	}
+}
+
+func TestImportMergesByOrderID(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	if v := manager.Version(); v != "" {
+		t.Errorf("version before import = %q, want empty", v)
+	}
+	manager.Import([]models.AmazonOrder{{ID: "a", Date: day("2025-01-01"), Total: 5}})
+	list, err := manager.Import([]models.AmazonOrder{
+		{ID: "a", Date: day("2025-01-01"), Total: 6},
+		{ID: "b", Date: day("2025-02-01"), Total: 7},
+	})
+	if err != nil || len(list) != 2 || list[0].ID != "b" || list[1].Total != 6 {
+		t.Fatalf("Import = %+v, %v; want b then the re-imported a", list, err)
+	}
+	if manager.Version() == "" {
+		t.Error("version should change after import")
+	}
+
+	if err := manager.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := manager.List(); len(list) != 0 {
+		t.Errorf("Clear left %+v", list)
+	}
+}
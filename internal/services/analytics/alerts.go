@@ -0,0 +1,135 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"budget2/internal/models"
+)
+
+// DefaultMaxAlerts is how many alerts the dashboard shows by default
+const DefaultMaxAlerts = 5
+
+// alertWeights rates how much each kind of alert matters on its own. Money
+// already over a limit outranks an unusual day, which outranks one large
+// purchase.
+var alertWeights = map[string]float64{
+	"budget_exceeded":         1.0,
+	"merchant_limit_exceeded": 1.0,
+	"unusual_day":             0.7,
+	"budget_warning":          0.6,
+	"merchant_limit_warning":  0.6,
+	"large_transaction":       0.5,
+}
+
+// severityWeights rate alert types missing from alertWeights
+var severityWeights = map[string]float64{"error": 1.0, "warning": 0.6, "info": 0.4}
+
+// ScoreAlert rates an alert from 0 to 100 by its type (40%), amount (35%)
+// and recency (25%). The amount counts on a log scale, full at $10,000.
+// Recency halves every 30 days before asOf; alerts without a date are about
+// the current month and count as recent.
+func ScoreAlert(a models.SpendingAlert, asOf time.Time) float64 {
+	weight, ok := alertWeights[a.Type]
+	if !ok {
+		weight = severityWeights[a.Severity]
+	}
+
+	amount := math.Min(1, math.Log10(1+math.Abs(a.Amount))/4)
+
+	recency := 1.0
+	if a.Date != nil {
+		days := math.Max(0, asOf.Sub(*a.Date).Hours()/24)
+		recency = math.Pow(0.5, days/30)
+	}
+
+	score := 100 * (0.4*weight + 0.35*amount + 0.25*recency)
+	return math.Round(score*10) / 10
+}
+
+// RankAlerts scores alerts, groups those from the same day into one, and
+// returns them most severe first, at most limit of them (all when limit is
+// zero). Ties keep their original order.
+func RankAlerts(alerts []models.SpendingAlert, asOf time.Time, limit int) []models.SpendingAlert {
+	scored := make([]models.SpendingAlert, len(alerts))
+	for i, a := range alerts {
+		a.Score = ScoreAlert(a, asOf)
+		scored[i] = a
+	}
+
+	ranked := groupAlerts(scored)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// groupAlerts folds alerts sharing a day into the highest-scoring one, with
+// the rest as its Related alerts. Several large purchases on a day become
+// one alert for the day's total. Each alert grouped in adds a point to the
+// group's score. Undated alerts are left alone.
+func groupAlerts(alerts []models.SpendingAlert) []models.SpendingAlert {
+	var result []models.SpendingAlert
+	byDay := make(map[string]int) // Day to its alert's index in result
+	for _, a := range alerts {
+		if a.Date == nil {
+			result = append(result, a)
+			continue
+		}
+		day := a.Date.Format("2006-01-02")
+		i, ok := byDay[day]
+		if !ok {
+			byDay[day] = len(result)
+			result = append(result, a)
+			continue
+		}
+
+		lead := result[i]
+		if a.Score > lead.Score {
+			a.Related, lead.Related = lead.Related, nil
+			a.Related = append(a.Related, lead)
+			lead = a
+		} else {
+			lead.Related = append(lead.Related, a)
+		}
+		result[i] = lead
+	}
+
+	for i, a := range result {
+		if len(a.Related) == 0 {
+			continue
+		}
+		a.Score = math.Min(100, a.Score+float64(len(a.Related)))
+		if purchases := allLargePurchases(a); purchases > 0 {
+			total := a.Amount
+			for _, r := range a.Related {
+				total += r.Amount
+			}
+			a.Title = fmt.Sprintf("%d Large Purchases", purchases)
+			a.Message = fmt.Sprintf("$%.0f across %d purchases on %s", total, purchases, a.Date.Format("Jan 2"))
+			a.Detail = "" // Link to the whole day rather than one purchase
+			a.Amount = total
+		}
+		result[i] = a
+	}
+	return result
+}
+
+// allLargePurchases returns how many purchases a group holds when all its
+// alerts are large transactions, and zero otherwise
+func allLargePurchases(a models.SpendingAlert) int {
+	if a.Type != "large_transaction" {
+		return 0
+	}
+	for _, r := range a.Related {
+		if r.Type != "large_transaction" {
+			return 0
+		}
+	}
+	return len(a.Related) + 1
+}
@@ -0,0 +1,540 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"budget2/internal/models"
+)
+
+// DetectAlerts returns the DefaultMaxAlerts most severe of ts's anomaly
+// alerts, ranked and grouped by RankAlerts
+func DetectAlerts(ts *models.TransactionSet) []models.SpendingAlert {
+	return RankAlerts(AnomalyAlerts(ts), ts.MaxDate(), DefaultMaxAlerts)
+}
+
+// AnomalyAlerts flags unusually expensive days (more than two standard
+// deviations above the daily mean) and the largest individual purchases
+func AnomalyAlerts(ts *models.TransactionSet) []models.SpendingAlert {
+	var alerts []models.SpendingAlert
+
+	outflows := ts.FilterByType(models.Outflow)
+	if outflows.Len() == 0 {
+		return alerts
+	}
+
+	// Group by date to find unusual days
+	daily := outflows.GroupByDate()
+
+	// Calculate mean and std dev of daily spending
+	var dailyTotals []float64
+	var sum, sumSq float64
+
+	for _, dayTxns := range daily {
+		total := dayTxns.SumAbsAmount()
+		dailyTotals = append(dailyTotals, total)
+		sum += total
+		sumSq += total * total
+	}
+
+	n := float64(len(dailyTotals))
+	if n < 7 { // Need at least a week of data
+		return alerts
+	}
+
+	mean := sum / n
+	variance := (sumSq / n) - (mean * mean)
+	stdDev := math.Sqrt(variance)
+	threshold := mean + 2*stdDev
+
+	// Find unusual days (more than 2 standard deviations above mean)
+	for dateStr, dayTxns := range daily {
+		total := dayTxns.SumAbsAmount()
+		if total > threshold && total > mean*1.5 { // Must be 50% above mean too
+			date, _ := time.Parse("2006-01-02", dateStr)
+			// Sort transactions by amount (largest first) for display
+			txnsCopy := make([]models.Transaction, len(dayTxns.Transactions))
+			copy(txnsCopy, dayTxns.Transactions)
+			sort.Slice(txnsCopy, func(i, j int) bool {
+				return math.Abs(txnsCopy[i].Amount) > math.Abs(txnsCopy[j].Amount)
+			})
+			alerts = append(alerts, models.SpendingAlert{
+				Type:         "unusual_day",
+				Severity:     "warning",
+				Title:        "High Spending Day",
+				Message:      fmt.Sprintf("$%.0f spent on %s (%.0f%% above average)", total, date.Format("Jan 2"), ((total-mean)/mean)*100),
+				Date:         &date,
+				Amount:       total,
+				Transactions: txnsCopy,
+			})
+		}
+	}
+
+	// Find large individual transactions (top 5% by amount)
+	sortedTxns := make([]models.Transaction, len(outflows.Transactions))
+	copy(sortedTxns, outflows.Transactions)
+	sort.Slice(sortedTxns, func(i, j int) bool {
+		return math.Abs(sortedTxns[i].Amount) > math.Abs(sortedTxns[j].Amount)
+	})
+
+	// Take top 3 largest transactions if they're significant
+	for i := 0; i < 3 && i < len(sortedTxns); i++ {
+		t := sortedTxns[i]
+		amt := math.Abs(t.Amount)
+		if amt > mean*3 { // Must be 3x average daily spending
+			date := t.Date
+			alerts = append(alerts, models.SpendingAlert{
+				Type:         "large_transaction",
+				Severity:     "info",
+				Title:        "Large Transaction",
+				Message:      fmt.Sprintf("$%.0f at %s", amt, t.Description),
+				Detail:       t.Description,
+				Date:         &date,
+				Amount:       amt,
+				Transactions: []models.Transaction{t},
+			})
+		}
+	}
+
+	// Map iteration leaves the unusual days in random order; sort by date
+	// (most recent first) so equal scores rank the same way on every load
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].Date.After(*alerts[j].Date)
+	})
+
+	return alerts
+}
+
+// DefaultTrendMonths is how many months the KPI sparklines cover by default
+const DefaultTrendMonths = 6
+
+// TrendWindows are the sparkline windows the dashboard offers, in months
+var TrendWindows = []int{6, 12, 24}
+
+// ValidTrendMonths reports whether months is one of the TrendWindows
+func ValidTrendMonths(months int) bool {
+	for _, w := range TrendWindows {
+		if w == months {
+			return true
+		}
+	}
+	return false
+}
+
+// CalculateMetrics computes dashboard KPIs and six-month trends
+func CalculateMetrics(ts *models.TransactionSet) *models.DashboardMetrics {
+	return CalculateMetricsWithTrend(ts, DefaultTrendMonths)
+}
+
+// CalculateMetricsWithTrend computes dashboard KPIs with trends over the
+// last trendMonths months that have data
+func CalculateMetricsWithTrend(ts *models.TransactionSet, trendMonths int) *models.DashboardMetrics {
+	income := ts.FilterByType(models.Income)
+	outflows := ts.FilterByType(models.Outflow)
+
+	totalIncome := income.SumAmount()
+	totalExpenses := outflows.SumAbsAmount()
+	netSavings := totalIncome - totalExpenses
+
+	var savingsRate float64
+	if totalIncome > 0 {
+		savingsRate = (netSavings / totalIncome) * 100
+	}
+
+	// Calculate monthly trends
+	var incomeTrend, expensesTrend, savingsTrend []float64
+	var trendLabels []string
+
+	monthlyIncome := income.GroupByMonth()
+	monthlyOutflows := outflows.GroupByMonth()
+
+	// Get sorted months
+	monthSet := make(map[string]bool)
+	for m := range monthlyIncome {
+		monthSet[m] = true
+	}
+	for m := range monthlyOutflows {
+		monthSet[m] = true
+	}
+
+	var months []string
+	for m := range monthSet {
+		months = append(months, m)
+	}
+	sort.Strings(months)
+
+	if len(months) > trendMonths {
+		months = months[len(months)-trendMonths:]
+	}
+
+	for _, m := range months {
+		incAmt := 0.0
+		if inc, ok := monthlyIncome[m]; ok {
+			incAmt = inc.SumAmount()
+		}
+
+		expAmt := 0.0
+		if exp, ok := monthlyOutflows[m]; ok {
+			expAmt = exp.SumAbsAmount()
+		}
+
+		incomeTrend = append(incomeTrend, incAmt)
+		expensesTrend = append(expensesTrend, expAmt)
+		savingsTrend = append(savingsTrend, incAmt-expAmt)
+		trendLabels = append(trendLabels, m)
+	}
+
+	return &models.DashboardMetrics{
+		TotalIncome:      totalIncome,
+		TotalExpenses:    totalExpenses,
+		NetSavings:       netSavings,
+		SavingsRate:      savingsRate,
+		TransactionCount: ts.Len(),
+		StartDate:        ts.MinDate(),
+		EndDate:          ts.MaxDate(),
+		IncomeTrend:      incomeTrend,
+		ExpensesTrend:    expensesTrend,
+		SavingsTrend:     savingsTrend,
+		TrendLabels:      trendLabels,
+		TrendMonths:      trendMonths,
+	}
+}
+
+// AddYearOverYear fills the metrics' YoY overlays with the same months a
+// year before each trend month. all should be the unfiltered data, since the
+// prior year usually falls outside the dashboard's date range.
+func AddYearOverYear(metrics *models.DashboardMetrics, all *models.TransactionSet) {
+	monthlyIncome := all.FilterByType(models.Income).GroupByMonth()
+	monthlyOutflows := all.FilterByType(models.Outflow).GroupByMonth()
+
+	metrics.IncomeTrendYoY = make([]float64, len(metrics.TrendLabels))
+	metrics.ExpensesTrendYoY = make([]float64, len(metrics.TrendLabels))
+	metrics.SavingsTrendYoY = make([]float64, len(metrics.TrendLabels))
+	metrics.HasYoY = false
+
+	for i, label := range metrics.TrendLabels {
+		month, err := time.Parse("2006-01", label)
+		if err != nil {
+			continue
+		}
+		prior := month.AddDate(-1, 0, 0).Format("2006-01")
+
+		inc, hasInc := monthlyIncome[prior]
+		exp, hasExp := monthlyOutflows[prior]
+		if !hasInc && !hasExp {
+			continue
+		}
+		metrics.HasYoY = true
+
+		incAmt, expAmt := 0.0, 0.0
+		if hasInc {
+			incAmt = inc.SumAmount()
+		}
+		if hasExp {
+			expAmt = exp.SumAbsAmount()
+		}
+		metrics.IncomeTrendYoY[i] = incAmt
+		metrics.ExpensesTrendYoY[i] = expAmt
+		metrics.SavingsTrendYoY[i] = incAmt - expAmt
+	}
+}
+
+// IncomeByCategory totals the income in [start, end] by category, largest
+// first, with each category's share of the total
+func IncomeByCategory(ts *models.TransactionSet, start, end time.Time) models.CategoryBreakdown {
+	return categoryBreakdown(ts.FilterByDateRange(start, end).FilterByType(models.Income), start, end)
+}
+
+// SpendingByCategory totals the outflows in [start, end] by category, as
+// IncomeByCategory does for income
+func SpendingByCategory(ts *models.TransactionSet, start, end time.Time) models.CategoryBreakdown {
+	return categoryBreakdown(ts.FilterByDateRange(start, end).FilterByType(models.Outflow), start, end)
+}
+
+func categoryBreakdown(ts *models.TransactionSet, start, end time.Time) models.CategoryBreakdown {
+	breakdown := models.CategoryBreakdown{
+		Start:      start.Format("2006-01-02"),
+		End:        end.Format("2006-01-02"),
+		Total:      ts.SumAbsAmount(),
+		Categories: []models.CategorySummary{},
+	}
+
+	counts := make(map[string]int)
+	for _, t := range ts.Transactions {
+		cat := t.Category
+		if cat == "" {
+			cat = "Uncategorized"
+		}
+		counts[cat]++
+	}
+	for cat, amount := range ts.CategoryTotals() {
+		summary := models.CategorySummary{Category: cat, Amount: amount, Count: counts[cat]}
+		if breakdown.Total > 0 {
+			summary.Percentage = amount / breakdown.Total * 100
+		}
+		breakdown.Categories = append(breakdown.Categories, summary)
+	}
+	sort.Slice(breakdown.Categories, func(i, j int) bool {
+		a, b := breakdown.Categories[i], breakdown.Categories[j]
+		if a.Amount != b.Amount {
+			return a.Amount > b.Amount
+		}
+		return a.Category < b.Category
+	})
+	return breakdown
+}
+
+// MonthlySavings summarizes each month from the first with data to the
+// last, oldest first. A month without transactions has only its label.
+func MonthlySavings(ts *models.TransactionSet) []models.MonthlySummary {
+	if ts.Len() == 0 {
+		return nil
+	}
+	byMonth := ts.GroupByMonth()
+
+	var months []models.MonthlySummary
+	first := time.Date(ts.MinDate().Year(), ts.MinDate().Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := ts.MaxDate().Format("2006-01")
+	for m := first; m.Format("2006-01") <= last; m = m.AddDate(0, 1, 0) {
+		summary := models.MonthlySummary{Month: m.Format("2006-01")}
+		if month, ok := byMonth[summary.Month]; ok {
+			summary.Income = month.FilterByType(models.Income).SumAmount()
+			summary.Expenses = month.FilterByType(models.Outflow).SumAbsAmount()
+			summary.NetSavings = summary.Income - summary.Expenses
+			if summary.Income > 0 {
+				summary.SavingsRate = summary.NetSavings / summary.Income * 100
+			}
+		}
+		months = append(months, summary)
+	}
+	return months
+}
+
+// AccountSummaries totals each account's transactions. The defined
+// accounts come first in the order given, even without transactions,
+// followed by the accounts only a file or account column names, by name.
+func AccountSummaries(defined []models.Account, ts *models.TransactionSet) []models.AccountSummary {
+	var summaries []models.AccountSummary
+	index := make(map[string]int)
+	for _, a := range defined {
+		index[strings.ToLower(a.Name)] = len(summaries)
+		summaries = append(summaries, models.AccountSummary{Account: a, Defined: true})
+	}
+
+	var named []models.AccountSummary
+	namedIndex := make(map[string]int)
+	for _, t := range ts.Transactions {
+		if t.Account == "" {
+			continue
+		}
+		key := strings.ToLower(t.Account)
+		var s *models.AccountSummary
+		if i, ok := index[key]; ok {
+			s = &summaries[i]
+		} else {
+			i, ok := namedIndex[key]
+			if !ok {
+				i = len(named)
+				namedIndex[key] = i
+				named = append(named, models.AccountSummary{Account: models.Account{Name: t.Account, Type: t.AccountType}})
+			}
+			s = &named[i]
+		}
+
+		s.Transactions++
+		switch t.TransactionType {
+		case models.Income:
+			s.Income += t.Amount
+		case models.Outflow:
+			s.Spending += math.Abs(t.Amount)
+		}
+		date := t.Date.Format("2006-01-02")
+		if s.FirstDate == "" || date < s.FirstDate {
+			s.FirstDate = date
+		}
+		if date > s.LastDate {
+			s.LastDate = date
+		}
+		if !s.Defined && t.SourceFile != "" && !slices.Contains(s.Files, t.SourceFile) {
+			s.Files = append(s.Files, t.SourceFile)
+		}
+	}
+
+	sort.Slice(named, func(i, j int) bool {
+		return strings.ToLower(named[i].Name) < strings.ToLower(named[j].Name)
+	})
+	for i := range named {
+		sort.Strings(named[i].Files)
+	}
+	return append(summaries, named...)
+}
+
+// Comparison normalization modes
+const (
+	NormalizeNone     = ""         // Compare period totals
+	NormalizeDay      = "day"      // Compare per day of data
+	NormalizePaycheck = "paycheck" // Compare per paycheck received
+)
+
+// minPaychecks is how many paydays an income description needs before it
+// is treated as the paycheck
+const minPaychecks = 3
+
+// CalculateComparison compares [start, end] against the previous period, the
+// previous calendar month or the same period last year, depending on
+// compType ("previous", "month" or "year")
+func CalculateComparison(data *models.TransactionSet, start, end time.Time, compType string) *models.PeriodComparison {
+	return CalculateNormalizedComparison(data, start, end, compType, NormalizeNone)
+}
+
+// CalculateNormalizedComparison is CalculateComparison with the changes
+// computed per day or per paycheck, so longer months and three-paycheck
+// months don't show up as changes in behavior. Per-paycheck falls back to
+// per-day when either period has no paychecks.
+func CalculateNormalizedComparison(data *models.TransactionSet, start, end time.Time, compType, normalize string) *models.PeriodComparison {
+	compStart, compEnd, ok := ComparisonPeriod(start, end, compType)
+	if !ok {
+		return nil
+	}
+
+	currentFiltered := data.FilterByDateRange(start, end)
+	compFiltered := data.FilterByDateRange(compStart, compEnd)
+
+	if compFiltered.Len() == 0 {
+		return &models.PeriodComparison{HasData: false}
+	}
+
+	currentMetrics := CalculateMetrics(currentFiltered)
+	compMetrics := CalculateMetrics(compFiltered)
+
+	comparison := &models.PeriodComparison{
+		Current:           currentMetrics,
+		Previous:          compMetrics,
+		HasData:           true,
+		SavingsRateChange: currentMetrics.SavingsRate - compMetrics.SavingsRate,
+		CurrentUnits:      1,
+		PreviousUnits:     1,
+	}
+
+	if normalize == NormalizePaycheck {
+		paycheck := detectPaycheck(data)
+		current := countPaydays(currentFiltered, paycheck)
+		previous := countPaydays(compFiltered, paycheck)
+		if paycheck != "" && current > 0 && previous > 0 {
+			comparison.Normalization = NormalizePaycheck
+			comparison.CurrentUnits = float64(current)
+			comparison.PreviousUnits = float64(previous)
+		} else {
+			normalize = NormalizeDay
+		}
+	}
+	if normalize == NormalizeDay {
+		comparison.Normalization = NormalizeDay
+		comparison.CurrentUnits = coveredDays(data, start, end)
+		comparison.PreviousUnits = coveredDays(data, compStart, compEnd)
+	}
+
+	per := func(total, units float64) float64 {
+		if units <= 0 {
+			return 0
+		}
+		return total / units
+	}
+	comparison.CurrentIncome = per(currentMetrics.TotalIncome, comparison.CurrentUnits)
+	comparison.PreviousIncome = per(compMetrics.TotalIncome, comparison.PreviousUnits)
+	comparison.CurrentExpenses = per(currentMetrics.TotalExpenses, comparison.CurrentUnits)
+	comparison.PreviousExpenses = per(compMetrics.TotalExpenses, comparison.PreviousUnits)
+
+	comparison.IncomeChange = PercentChange(comparison.CurrentIncome, comparison.PreviousIncome)
+	comparison.ExpensesChange = PercentChange(comparison.CurrentExpenses, comparison.PreviousExpenses)
+	comparison.SavingsChange = PercentChange(
+		comparison.CurrentIncome-comparison.CurrentExpenses,
+		comparison.PreviousIncome-comparison.PreviousExpenses,
+	)
+
+	return comparison
+}
+
+// ComparisonPeriod returns the period [start, end] is compared against for
+// compType, or false for an unknown type
+func ComparisonPeriod(start, end time.Time, compType string) (time.Time, time.Time, bool) {
+	switch compType {
+	case "previous":
+		compEnd := start.Add(-24 * time.Hour) // Day before start
+		return compEnd.Add(-end.Sub(start)), compEnd, true
+	case "month":
+		return start.AddDate(0, -1, 0), start.Add(-24 * time.Hour), true
+	case "year":
+		return start.AddDate(-1, 0, 0), end.AddDate(-1, 0, 0), true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// coveredDays returns how many days of [start, end] fall within the data, so
+// a period the data only partly covers isn't diluted
+func coveredDays(data *models.TransactionSet, start, end time.Time) float64 {
+	if first := data.MinDate(); first.After(start) {
+		start = first
+	}
+	if last := data.MaxDate(); last.Before(end) {
+		end = last
+	}
+	days := math.Round(end.Sub(start).Hours()/24) + 1
+	return math.Max(days, 0)
+}
+
+// detectPaycheck returns the description (lowercased) of the income with
+// the largest total among those paid on at least minPaychecks days, or ""
+// if nothing qualifies
+func detectPaycheck(ts *models.TransactionSet) string {
+	totals := make(map[string]float64)
+	days := make(map[string]map[string]bool)
+	for _, t := range ts.FilterByType(models.Income).Transactions {
+		key := strings.ToLower(strings.TrimSpace(t.Description))
+		totals[key] += t.Amount
+		if days[key] == nil {
+			days[key] = make(map[string]bool)
+		}
+		days[key][t.Date.Format("2006-01-02")] = true
+	}
+
+	best := ""
+	for key, total := range totals {
+		if len(days[key]) < minPaychecks {
+			continue
+		}
+		if best == "" || total > totals[best] || (total == totals[best] && key < best) {
+			best = key
+		}
+	}
+	return best
+}
+
+// countPaydays returns how many distinct days the paycheck was paid in ts
+func countPaydays(ts *models.TransactionSet, paycheck string) int {
+	if paycheck == "" {
+		return 0
+	}
+	seen := make(map[string]bool)
+	for _, t := range ts.FilterByType(models.Income).Transactions {
+		if strings.ToLower(strings.TrimSpace(t.Description)) == paycheck {
+			seen[t.Date.Format("2006-01-02")] = true
+		}
+	}
+	return len(seen)
+}
+
+// PercentChange returns the percent change from previous to current
+func PercentChange(current, previous float64) float64 {
+	if previous == 0 {
+		if current == 0 {
+			return 0
+		}
+		return 100
+	}
+	return ((current - previous) / math.Abs(previous)) * 100
+}
@@ -0,0 +1,720 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+)
+
+func txn(date string, amount float64, category string) models.Transaction {
+	d, _ := time.Parse("2006-01-02", date)
+	tt := models.Outflow
+	if amount > 0 {
+		tt = models.Income
+	}
+	return models.Transaction{Date: d, Description: category, Amount: amount, Category: category, TransactionType: tt}
+}
+
+func TestCalculateMetrics(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2025-01-01", 4000, "Paycheck"),
+		txn("2025-01-05", -1500, "Rent"),
+		txn("2025-02-01", 4000, "Paycheck"),
+		txn("2025-02-05", -1500, "Rent"),
+		txn("2025-02-10", -1000, "Groceries"),
+	})
+
+	m := CalculateMetrics(ts)
+	if m.TotalIncome != 8000 || m.TotalExpenses != 4000 || m.NetSavings != 4000 {
+		t.Errorf("totals = %.0f/%.0f/%.0f, want 8000/4000/4000", m.TotalIncome, m.TotalExpenses, m.NetSavings)
+	}
+	if m.SavingsRate != 50 {
+		t.Errorf("SavingsRate = %.1f, want 50", m.SavingsRate)
+	}
+	if len(m.TrendLabels) != 2 || m.SavingsTrend[1] != 1500 {
+		t.Errorf("trend = %v %v, want two months ending with 1500 saved", m.TrendLabels, m.SavingsTrend)
+	}
+}
+
+func TestCalculateMetricsWithTrend(t *testing.T) {
+	var txns []models.Transaction
+	for month := time.January; month <= time.December; month++ {
+		for _, year := range []int{2024, 2025} {
+			date := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+			txns = append(txns, txn(date, 4000, "Paycheck"), txn(date, float64(-1000*(year-2023)), "Rent"))
+		}
+	}
+	all := models.NewTransactionSet(txns)
+
+	for _, months := range TrendWindows {
+		m := CalculateMetricsWithTrend(all, months)
+		if len(m.TrendLabels) != months || m.TrendMonths != months {
+			t.Errorf("window %d: %d labels, TrendMonths %d", months, len(m.TrendLabels), m.TrendMonths)
+		}
+	}
+	if ValidTrendMonths(9) {
+		t.Error("9 months should not be a valid trend window")
+	}
+
+	// The dashboard shows 2025 only; the overlay reaches back into 2024
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	m := CalculateMetricsWithTrend(all.FilterByDateRange(start, end), 12)
+	AddYearOverYear(m, all)
+	if !m.HasYoY || len(m.ExpensesTrendYoY) != 12 {
+		t.Fatalf("HasYoY = %v with %d overlay months, want 12", m.HasYoY, len(m.ExpensesTrendYoY))
+	}
+	if m.ExpensesTrend[0] != 2000 || m.ExpensesTrendYoY[0] != 1000 || m.SavingsTrendYoY[0] != 3000 {
+		t.Errorf("Jan 2025 expenses %.0f vs %.0f a year earlier (saved %.0f); want 2000 vs 1000 (3000)",
+			m.ExpensesTrend[0], m.ExpensesTrendYoY[0], m.SavingsTrendYoY[0])
+	}
+
+	// Without a prior year there is nothing to overlay
+	m = CalculateMetricsWithTrend(all, 6)
+	AddYearOverYear(m, all.FilterByDateRange(start, end))
+	if m.HasYoY {
+		t.Errorf("HasYoY with no prior-year data: %v", m.IncomeTrendYoY)
+	}
+}
+
+func TestPercentChange(t *testing.T) {
+	tests := []struct {
+		current, previous, want float64
+	}{
+		{150, 100, 50},
+		{50, 100, -50},
+		{0, 0, 0},
+		{10, 0, 100},
+		{-50, -100, 50}, // less negative is an improvement
+	}
+	for _, tt := range tests {
+		if got := PercentChange(tt.current, tt.previous); got != tt.want {
+			t.Errorf("PercentChange(%v, %v) = %v, want %v", tt.current, tt.previous, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateComparison(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2024-03-10", -100, "Groceries"),
+		txn("2025-03-10", -150, "Groceries"),
+	})
+	start, _ := time.Parse("2006-01-02", "2025-03-01")
+	end, _ := time.Parse("2006-01-02", "2025-03-31")
+
+	c := CalculateComparison(ts, start, end, "year")
+	if c == nil || !c.HasData || c.ExpensesChange != 50 {
+		t.Errorf("year comparison = %+v, want 50%% expense increase", c)
+	}
+
+	if c := CalculateComparison(ts, start, end, "previous"); c == nil || c.HasData {
+		t.Errorf("previous period has no data, got %+v", c)
+	}
+	if c := CalculateComparison(ts, start, end, "bogus"); c != nil {
+		t.Errorf("unknown comparison should return nil, got %+v", c)
+	}
+}
+
+func TestCalculateNormalizedComparison(t *testing.T) {
+	// $100 of spending every day, paid every other Friday: three paychecks
+	// in January and two in February
+	var txns []models.Transaction
+	first := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for d := first; d.Month() <= time.February; d = d.AddDate(0, 0, 1) {
+		txns = append(txns, txn(d.Format("2006-01-02"), -100, "Groceries"))
+	}
+	for _, payday := range []string{"2025-01-03", "2025-01-17", "2025-01-31", "2025-02-14", "2025-02-28"} {
+		txns = append(txns, txn(payday, 2000, "ACME Payroll"))
+	}
+	txns = append(txns, txn("2025-02-10", 50, "Refund"))
+	ts := models.NewTransactionSet(txns)
+	start := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)
+
+	totals := CalculateComparison(ts, start, end, "month")
+	if totals == nil || !totals.HasData || totals.Normalization != NormalizeNone {
+		t.Fatalf("month comparison = %+v", totals)
+	}
+	if totals.ExpensesChange >= 0 || totals.IncomeChange >= 0 {
+		t.Errorf("totals should fall in the shorter, two-paycheck month: income %.1f%%, expenses %.1f%%",
+			totals.IncomeChange, totals.ExpensesChange)
+	}
+
+	perDay := CalculateNormalizedComparison(ts, start, end, "month", NormalizeDay)
+	if perDay.CurrentUnits != 28 || perDay.PreviousUnits != 31 {
+		t.Errorf("per-day units = %.0f vs %.0f, want 28 vs 31", perDay.CurrentUnits, perDay.PreviousUnits)
+	}
+	if perDay.ExpensesChange != 0 || perDay.CurrentExpenses != 100 {
+		t.Errorf("per-day expenses = %.2f (%.1f%%), want 100 and no change", perDay.CurrentExpenses, perDay.ExpensesChange)
+	}
+
+	perPaycheck := CalculateNormalizedComparison(ts, start, end, "month", NormalizePaycheck)
+	if perPaycheck.Normalization != NormalizePaycheck || perPaycheck.CurrentUnits != 2 || perPaycheck.PreviousUnits != 3 {
+		t.Fatalf("per-paycheck = %q with %.0f vs %.0f paychecks, want 2 vs 3",
+			perPaycheck.Normalization, perPaycheck.CurrentUnits, perPaycheck.PreviousUnits)
+	}
+	if want := PercentChange(4050.0/2, 6000.0/3); math.Abs(perPaycheck.IncomeChange-want) > 1e-9 {
+		t.Errorf("per-paycheck income change = %.2f%%, want %.2f%%", perPaycheck.IncomeChange, want)
+	}
+	if perPaycheck.SavingsRateChange != totals.SavingsRateChange {
+		t.Error("normalizing should not change the savings rate comparison")
+	}
+
+	// Without a regular paycheck, per-paycheck falls back to per-day
+	noPaycheck := models.NewTransactionSet(txns[:len(txns)-6])
+	if c := CalculateNormalizedComparison(noPaycheck, start, end, "month", NormalizePaycheck); c.Normalization != NormalizeDay {
+		t.Errorf("fallback normalization = %q, want %q", c.Normalization, NormalizeDay)
+	}
+}
+
+func TestCalculateMonthStatus(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2025-10-10", -1000, "Rent"),
+		txn("2025-11-01", 4000, "Paycheck"),
+		txn("2025-11-05", -1500, "Rent"),
+		txn("2025-11-20", -500, "Groceries"),
+		txn("2025-12-01", 4000, "Paycheck"),
+		txn("2025-12-05", -1000, "Rent"),
+		txn("2025-12-15", -200, "Groceries"),
+	})
+
+	// Without a budget, December compares against the $1,500 monthly average
+	s := CalculateMonthStatus(ts, 0)
+	if s.Month != "2025-12" || s.AsOf != "2025-12-15" || s.Spent != 1200 || s.SavingsRate != 70 {
+		t.Errorf("status = %+v, want $1,200 spent through Dec 15 at a 70%% savings rate", s)
+	}
+	if s.Budget != 1500 || s.BudgetSource != models.BudgetAverage || s.BudgetUsedPercent != 80 {
+		t.Errorf("budget = %.2f (%s), %.1f%% used; want the 1500 average, 80%% used", s.Budget, s.BudgetSource, s.BudgetUsedPercent)
+	}
+	// 80% of the budget is gone with under half the month elapsed
+	if s.OnTrack || math.Abs(s.MonthElapsed-15.0/31*100) > 0.001 {
+		t.Errorf("elapsed = %.1f%%, on track = %v; want 48.4%% and off track", s.MonthElapsed, s.OnTrack)
+	}
+
+	s = CalculateMonthStatus(ts, 3000)
+	if s.BudgetSource != models.BudgetConfigured || s.Remaining != 1800 || !s.OnTrack {
+		t.Errorf("with a $3,000 budget: %+v, want $1,800 remaining and on track", s)
+	}
+
+	// A first month of data has nothing to average
+	s = CalculateMonthStatus(models.NewTransactionSet([]models.Transaction{txn("2025-12-05", -100, "Rent")}), 0)
+	if s.Budget != 0 || s.BudgetSource != "" || s.Spent != 100 {
+		t.Errorf("first month: %+v, want spending and no budget", s)
+	}
+}
+
+func TestBurnDown(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2025-11-03", -300, "Groceries"),
+		txn("2025-11-30", -900, "Rent"),
+		txn("2025-12-01", 4000, "Paycheck"),
+		txn("2025-12-02", -100, "Groceries"),
+		txn("2025-12-02", -50, "Coffee"),
+		txn("2025-12-05", -250, "Utilities"),
+	})
+
+	b := BurnDown(ts, 3100, 20)
+	if b.Month != "2025-12" || b.AsOf != "2025-12-05" || len(b.Days) != 31 {
+		t.Fatalf("burn-down = %+v, want December through the 5th", b)
+	}
+	if len(b.Spent) != 5 || b.Spent[0] != 0 || b.Spent[1] != 150 || b.Spent[4] != 400 {
+		t.Errorf("spent = %v, want income left out and $400 by the 5th", b.Spent)
+	}
+	if b.Pace[0] != 100 || b.Pace[30] != 3100 {
+		t.Errorf("pace = %v, want $100 a day up to the budget", b.Pace)
+	}
+	if len(b.LastMonth) != 30 || b.LastMonth[2] != 300 || b.LastMonth[29] != 1200 {
+		t.Errorf("last month = %v, want November's 30 days", b.LastMonth)
+	}
+	// The projection starts from the 5th and adds $20 for each of 26 days
+	if len(b.Projected) != 27 || b.Projected[0] != 400 || b.ProjectedTotal != 400+26*20 {
+		t.Errorf("projected = %v (%.2f), want 400 rising to 920", b.Projected, b.ProjectedTotal)
+	}
+
+	// November's 28 days of history band the projection, widening from the 5th
+	sd := DailySpendStdDev(ts, time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC))
+	if len(b.ProjectedLow) != 27 || b.ProjectedLow[0] != 400 || b.ProjectedHigh[0] != 400 {
+		t.Fatalf("band = %v to %v, want it to start at $400", b.ProjectedLow, b.ProjectedHigh)
+	}
+	wantHigh := 920 + 1.2816*sd*math.Sqrt(26)
+	if b.ProjectedRange.Level != 80 || math.Abs(b.ProjectedRange.High-wantHigh) > 0.01 || b.ProjectedRange.Low != 400 {
+		t.Errorf("range = %+v, want 400 to %.2f (the floor is what's spent)", b.ProjectedRange, wantHigh)
+	}
+
+	chart := BurnDownChart(b)
+	if traces := chart["data"].([]map[string]interface{}); len(traces) != 6 || traces[3]["name"] != "80% Range" {
+		t.Errorf("traces = %d, want pace, last month, the band's two edges, projection and spent", len(traces))
+	}
+
+	// A first month has no prior month and, without a budget, no pace
+	b = BurnDown(models.NewTransactionSet([]models.Transaction{txn("2025-12-05", -100, "Rent")}), 0, 10)
+	if b.LastMonth != nil || b.Pace != nil || b.Spent[4] != 100 || b.ProjectedRange != nil {
+		t.Errorf("first month: %+v, want spending only", b)
+	}
+}
+
+func TestProjectionRanges(t *testing.T) {
+	// $100 every other day spreads $50 a day either side of its $50 mean
+	var txns []models.Transaction
+	for day := 1; day <= 28; day += 2 {
+		txns = append(txns, txn(fmt.Sprintf("2025-02-%02d", day), -100, "Groceries"))
+	}
+	ts := models.NewTransactionSet(txns)
+	feb1 := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	if sd := DailySpendStdDev(ts, feb1, feb1.AddDate(0, 0, 27)); sd != 50 {
+		t.Errorf("daily spread = %.2f, want 50", sd)
+	}
+	if sd := DailySpendStdDev(ts, feb1, feb1.AddDate(0, 0, 9)); sd != 0 {
+		t.Errorf("ten days gave a spread of %.2f, want none", sd)
+	}
+
+	// Nine days left spread it by three days' deviation
+	r := ProjectionRange(1000, 50, 9)
+	if r.Level != ConfidenceLevel || math.Abs(r.High-(1000+1.2816*150)) > 1e-9 || math.Abs(r.Low-(1000-1.2816*150)) > 1e-9 {
+		t.Errorf("range = %+v, want 1000 give or take 192", r)
+	}
+	if ProjectionRange(1000, 0, 9) != nil {
+		t.Error("no spread should give no range")
+	}
+
+	mean, r := MonthlyProjection([]float64{100, 300})
+	if mean != 200 || math.Abs(r.Low-(200-128.16)) > 1e-9 || math.Abs(r.High-(200+128.16)) > 1e-9 {
+		t.Errorf("monthly = %.2f %+v, want 200 give or take 128", mean, r)
+	}
+	if _, r := MonthlyProjection([]float64{0, 0, 900}); r.Low != 0 {
+		t.Errorf("low = %.2f, want it floored at zero", r.Low)
+	}
+	if mean, r := MonthlyProjection([]float64{250}); mean != 250 || r != nil {
+		t.Errorf("one month = %.2f %+v, want 250 without a range", mean, r)
+	}
+}
+
+func TestCalculateWeeklyStatus(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2025-11-17", 600, "Deliveries"),
+		txn("2025-11-18", -200, "Groceries"),
+		txn("2025-11-24", 1000, "Deliveries"),
+		txn("2025-11-25", -300, "Groceries"),
+		txn("2025-12-01", 200, "Deliveries"),
+		txn("2025-12-01", -150, "Gas"),
+		txn("2025-12-03", -300, "Groceries"), // Wednesday
+	})
+	cycle := models.BudgetCycle{Cycle: models.CycleWeekly, WeekStart: time.Monday}
+
+	s := CalculateWeeklyStatus(ts, cycle, DefaultWeeks)
+	if len(s.Weeks) != 3 || s.Weeks[0].Start != "2025-11-17" || s.Current.Start != "2025-12-01" || s.Current.End != "2025-12-07" {
+		t.Fatalf("weeks = %+v, want the three weeks since the data begins", s.Weeks)
+	}
+	// The current week's target is the average income of the two weeks before
+	c := s.Current
+	if c.SmoothedIncome != 800 || c.Target != 800 || c.Remaining != 350 || s.TargetSource != models.BudgetSmoothedIncome {
+		t.Errorf("current = %+v (%s), want an $800 smoothed-income target", c, s.TargetSource)
+	}
+	if c.SpentChange != 50 || s.Previous().Spent != 300 {
+		t.Errorf("spent change = %.1f, want 50%% over last week's $300", c.SpentChange)
+	}
+	if s.Weeks[0].SmoothedIncome != 0 || s.Weeks[0].Target != 0 || s.Weeks[1].SmoothedIncome != 600 {
+		t.Errorf("early weeks = %+v, want no target for the first week", s.Weeks[:2])
+	}
+	// $450 is 56% of the target three days (43%) into the week
+	if s.OnTrack {
+		t.Errorf("on track at %.1f%% of the week, want ahead of pace", s.WeekElapsed)
+	}
+
+	cycle.WeeklyBudget = 1200
+	s = CalculateWeeklyStatus(ts, cycle, 2)
+	if len(s.Weeks) != 2 || s.Current.Target != 1200 || s.TargetSource != models.BudgetConfigured || !s.OnTrack {
+		t.Errorf("with a budget: %+v, want two weeks on track for $1,200", s)
+	}
+
+	// Weeks starting Sunday put Sunday's spending in the week it starts
+	cycle = models.BudgetCycle{Cycle: models.CycleWeekly, WeekStart: time.Sunday}
+	s = CalculateWeeklyStatus(ts, cycle, DefaultWeeks)
+	if s.Current.Start != "2025-11-30" || s.WeekStart != "Sunday" {
+		t.Errorf("Sunday weeks: current = %+v", s.Current)
+	}
+
+	if s := CalculateWeeklyStatus(models.NewTransactionSet(nil), cycle, DefaultWeeks); s.AsOf != "" || len(s.Weeks) != 0 {
+		t.Errorf("empty data: %+v, want no weeks", s)
+	}
+}
+
+func TestWeeklyBurnDown(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2025-11-24", 1400, "Deliveries"),
+		txn("2025-11-25", -300, "Groceries"),
+		txn("2025-12-01", -150, "Gas"),
+		txn("2025-12-03", -300, "Groceries"), // Wednesday
+	})
+	cycle := models.BudgetCycle{Cycle: models.CycleWeekly, WeekStart: time.Monday}
+
+	b := WeeklyBurnDown(ts, cycle, 50)
+	if b.Cycle != models.CycleWeekly || b.Month != "2025-12-01" || len(b.Days) != 7 || b.DayLabels[0] != "Mon" || b.DayLabels[6] != "Sun" {
+		t.Fatalf("burn-down = %+v, want the week of Dec 1", b)
+	}
+	if len(b.Spent) != 3 || b.Spent[0] != 150 || b.Spent[2] != 450 {
+		t.Errorf("spent = %v, want $450 by Wednesday", b.Spent)
+	}
+	if b.Budget != 1400 || b.Pace[6] != 1400 || b.BudgetSource != models.BudgetSmoothedIncome {
+		t.Errorf("pace = %v, want last week's income as the target", b.Pace)
+	}
+	if len(b.LastMonth) != 7 || b.LastMonth[0] != 0 || b.LastMonth[6] != 300 {
+		t.Errorf("last week = %v, want the week of Nov 24", b.LastMonth)
+	}
+	if len(b.Projected) != 5 || b.ProjectedTotal != 450+4*50 {
+		t.Errorf("projected = %v, want $50 a day to Sunday", b.Projected)
+	}
+
+	chart := BurnDownChart(b)
+	xaxis := chart["layout"].(map[string]interface{})["xaxis"].(map[string]interface{})
+	if xaxis["title"] != "Week of 2025-12-01" {
+		t.Errorf("x-axis title = %v, want the week", xaxis["title"])
+	}
+}
+
+func TestForecastBills(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	bill := func(description string, amount float64, frequency, last, next string) models.RecurringPayment {
+		return models.RecurringPayment{Description: description, Amount: amount, Frequency: frequency, LastDate: day(last), NextExpected: day(next)}
+	}
+	recurring := []models.RecurringPayment{
+		bill("streaming", 15.99, "monthly", "2025-12-20", "2026-01-19"),
+		bill("gym", 10, "weekly", "2026-01-08", "2026-01-15"),
+		bill("phone", 60, "monthly", "2025-11-25", "2025-12-25"),       // Missed
+		bill("insurance", 100, "monthly", "2025-12-09", "2026-01-08"),  // Two days late, may yet post
+		bill("old magazine", 8, "monthly", "2025-09-01", "2025-10-01"), // Missed for months, so cancelled
+	}
+
+	// Saturday, with weeks starting Monday
+	f := ForecastBills(recurring, day("2026-01-10"), 30, time.Monday)
+	if f.AsOf != "2026-01-10" || f.Through != "2026-02-09" {
+		t.Errorf("forecast covers %s to %s", f.AsOf, f.Through)
+	}
+	var due []string
+	for _, b := range f.Bills {
+		due = append(due, b.Due+" "+b.Description)
+	}
+	want := []string{
+		"2025-12-25 phone", "2026-01-08 insurance", "2026-01-15 gym", "2026-01-19 streaming", "2026-01-22 gym",
+		"2026-01-24 phone", "2026-01-29 gym", "2026-02-05 gym", "2026-02-07 insurance",
+	}
+	if fmt.Sprint(due) != fmt.Sprint(want) {
+		t.Errorf("bills = %v, want %v", due, want)
+	}
+	if !f.Bills[0].Overdue || f.Bills[0].DaysOverdue != 16 || f.Bills[1].Overdue {
+		t.Errorf("overdue flags = %+v, %+v", f.Bills[0], f.Bills[1])
+	}
+	if len(f.Overdue()) != 1 || math.Abs(f.OverdueTotal-60) > 0.001 || math.Abs(f.Total-315.99) > 0.001 {
+		t.Errorf("total = %.2f, overdue %.2f", f.Total, f.OverdueTotal)
+	}
+
+	// Whole weeks from Monday Jan 5 to the week of Feb 9
+	if len(f.Weeks) != 6 || f.Weeks[0][0].Date != "2026-01-05" || f.Weeks[0][0].Weekday != "Mon" {
+		t.Fatalf("calendar = %d weeks from %s", len(f.Weeks), f.Weeks[0][0].Date)
+	}
+	today := f.Weeks[0][5]
+	if !today.Today || !today.InRange || f.Weeks[0][4].InRange {
+		t.Errorf("today = %+v, day before in range %v", today, f.Weeks[0][4].InRange)
+	}
+	if thu := f.Weeks[1][3]; thu.Date != "2026-01-15" || len(thu.Bills) != 1 || thu.Total != 10 {
+		t.Errorf("Jan 15 = %+v", thu)
+	}
+	for _, week := range f.Weeks {
+		for _, d := range week {
+			if d.Date == "2025-12-25" || (len(d.Bills) > 0 && d.Bills[0].Overdue) {
+				t.Errorf("missed bill on the calendar: %+v", d)
+			}
+		}
+	}
+}
+
+func TestDetectAlerts(t *testing.T) {
+	var txns []models.Transaction
+	for day := 1; day <= 14; day++ {
+		txns = append(txns, txn(time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC).Format("2006-01-02"), -20, "Coffee"))
+	}
+	txns = append(txns, txn("2025-01-15", -900, "Electronics"))
+
+	alerts := DetectAlerts(models.NewTransactionSet(txns))
+
+	// The high spending day and its purchase are grouped into one alert
+	if len(alerts) != 1 || len(alerts[0].Related) != 1 {
+		t.Fatalf("expected one alert with one related, got %+v", alerts)
+	}
+	types := map[string]float64{alerts[0].Type: alerts[0].Amount, alerts[0].Related[0].Type: alerts[0].Related[0].Amount}
+	if _, ok := types["unusual_day"]; !ok || types["large_transaction"] != 900 {
+		t.Errorf("expected unusual day and large transaction alerts, got %+v", alerts)
+	}
+
+	if alerts := DetectAlerts(models.NewTransactionSet(txns[:3])); len(alerts) != 0 {
+		t.Errorf("fewer than 7 days of data should not alert, got %d", len(alerts))
+	}
+}
+
+func TestOneOffPurchases(t *testing.T) {
+	var txns []models.Transaction
+	for day := 1; day <= 14; day++ {
+		txns = append(txns, txn(time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC).Format("2006-01-02"), -20, "Coffee"))
+	}
+	txns = append(txns,
+		txn("2025-01-15", -900, "Electronics"),
+		txn("2025-01-03", -700, "Rent"), txn("2025-01-10", -700, "rent "),
+		txn("2025-01-12", 3000, "Payroll"))
+
+	oneOffs := OneOffPurchases(models.NewTransactionSet(txns))
+	if len(oneOffs) != 1 || oneOffs[0].Description != "Electronics" {
+		t.Errorf("one-offs = %+v, want only the electronics purchase, not the recurring rent", oneOffs)
+	}
+	if oneOffs := OneOffPurchases(models.NewTransactionSet(txns[:3])); oneOffs != nil {
+		t.Errorf("fewer than 7 days of spending should flag nothing, got %+v", oneOffs)
+	}
+}
+
+func TestRankAlerts(t *testing.T) {
+	asOf, _ := time.Parse("2006-01-02", "2025-03-31")
+	date := func(s string) *time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return &d
+	}
+	alerts := []models.SpendingAlert{
+		{Type: "large_transaction", Severity: "info", Title: "Large Transaction", Date: date("2025-01-05"), Amount: 2000},
+		{Type: "large_transaction", Severity: "info", Title: "Large Transaction", Date: date("2025-03-30"), Amount: 600, Detail: "TV"},
+		{Type: "large_transaction", Severity: "info", Title: "Large Transaction", Date: date("2025-03-30"), Amount: 400, Detail: "Couch"},
+		{Type: "budget_warning", Severity: "warning", Title: "Dining Near Budget", Amount: 450},
+		{Type: "budget_exceeded", Severity: "error", Title: "Travel Over Budget", Amount: 1200},
+	}
+
+	// Type, then size and recency: an old purchase scores under a recent one
+	if old, recent := ScoreAlert(alerts[0], asOf), ScoreAlert(alerts[1], asOf); old >= recent {
+		t.Errorf("January's $2,000 scored %.1f, March's $600 %.1f; want recency to win", old, recent)
+	}
+
+	ranked := RankAlerts(alerts, asOf, 0)
+	if len(ranked) != 4 || ranked[0].Title != "Travel Over Budget" {
+		t.Fatalf("ranked = %+v, want four alerts led by the exceeded budget", ranked)
+	}
+	var group models.SpendingAlert
+	for _, a := range ranked {
+		if len(a.Related) > 0 {
+			group = a
+		}
+	}
+	if group.Title != "2 Large Purchases" || group.Amount != 1000 || group.Detail != "" || len(group.Related) != 1 {
+		t.Errorf("group = %+v, want March 30's purchases as one $1,000 alert", group)
+	}
+	for i := 1; i < len(ranked); i++ {
+		if ranked[i].Score > ranked[i-1].Score {
+			t.Errorf("alert %d scores %.1f above %.1f before it", i, ranked[i].Score, ranked[i-1].Score)
+		}
+	}
+
+	if limited := RankAlerts(alerts, asOf, 2); len(limited) != 2 {
+		t.Errorf("limit 2 kept %d alerts", len(limited))
+	}
+}
+
+func TestIncomeByCategory(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2024-12-31", 4000, "Paycheck"), // Before the range
+		txn("2025-01-01", 4000, "Paycheck"),
+		txn("2025-01-15", 4000, "Paycheck"),
+		txn("2025-01-20", 1500, "Side Gig"),
+		txn("2025-01-31", 500, "Dividends"),
+		txn("2025-01-05", -1500, "Rent"),
+	})
+	start, _ := time.Parse("2006-01-02", "2025-01-01")
+	end, _ := time.Parse("2006-01-02", "2025-01-31")
+
+	breakdown := IncomeByCategory(ts, start, end)
+	if breakdown.Total != 10000 || breakdown.Start != "2025-01-01" || breakdown.End != "2025-01-31" {
+		t.Errorf("breakdown = %+v, want $10,000 in January", breakdown)
+	}
+	want := []models.CategorySummary{
+		{Category: "Paycheck", Amount: 8000, Count: 2, Percentage: 80},
+		{Category: "Side Gig", Amount: 1500, Count: 1, Percentage: 15},
+		{Category: "Dividends", Amount: 500, Count: 1, Percentage: 5},
+	}
+	if len(breakdown.Categories) != len(want) {
+		t.Fatalf("categories = %+v, want %+v", breakdown.Categories, want)
+	}
+	for i, w := range want {
+		if got := breakdown.Categories[i]; got != w {
+			t.Errorf("category %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	if empty := IncomeByCategory(models.NewTransactionSet(nil), start, end); empty.Total != 0 || empty.Categories == nil {
+		t.Errorf("no income = %+v, want an empty list", empty)
+	}
+}
+
+func TestAccountSummaries(t *testing.T) {
+	inAccount := func(t models.Transaction, account, file string) models.Transaction {
+		t.Account, t.AccountType, t.SourceFile = account, models.AccountBank, file
+		return t
+	}
+	ts := models.NewTransactionSet([]models.Transaction{
+		inAccount(txn("2025-01-01", 4000, "Paycheck"), "Checking", "chase.csv"),
+		inAccount(txn("2025-01-05", -1500, "Rent"), "checking", "chase.csv"),
+		inAccount(txn("2025-02-03", -80, "Dining"), "Savings", "savings.csv"),
+		inAccount(txn("2025-01-20", -40, "Dining"), "Savings", "savings.csv"),
+	})
+	defined := []models.Account{
+		{Name: "Checking", Type: models.AccountBank, Files: []string{"chase.csv"}},
+		{Name: "Amex", Type: models.AccountCreditCard},
+	}
+
+	summaries := AccountSummaries(defined, ts)
+	if len(summaries) != 3 {
+		t.Fatalf("summaries = %+v, want Checking, Amex and Savings", summaries)
+	}
+	checking, amex, savings := summaries[0], summaries[1], summaries[2]
+	if !checking.Defined || checking.Transactions != 2 || checking.Income != 4000 || checking.Spending != 1500 {
+		t.Errorf("Checking = %+v, want both its transactions", checking)
+	}
+	if !amex.Defined || amex.Transactions != 0 || amex.FirstDate != "" {
+		t.Errorf("Amex = %+v, want a defined account without transactions", amex)
+	}
+	if savings.Defined || savings.Spending != 120 || savings.FirstDate != "2025-01-20" || savings.LastDate != "2025-02-03" {
+		t.Errorf("Savings = %+v, want $120 from 2025-01-20 to 2025-02-03", savings)
+	}
+	if len(savings.Files) != 1 || savings.Files[0] != "savings.csv" {
+		t.Errorf("Savings files = %v, want savings.csv", savings.Files)
+	}
+}
+
+func TestSavingsStrip(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2025-01-01", 4000, "Paycheck"),
+		txn("2025-01-05", -3000, "Rent"), // 25% saved
+		txn("2025-02-01", 4000, "Paycheck"),
+		txn("2025-02-05", -3800, "Rent"), // 5% saved
+		// Nothing in March
+		txn("2025-04-05", -500, "Rent"), // No income
+	})
+
+	months := MonthlySavings(ts)
+	if len(months) != 4 || months[0].Month != "2025-01" || months[3].Month != "2025-04" {
+		t.Fatalf("months = %+v, want January through April", months)
+	}
+	if months[0].SavingsRate != 25 || months[1].SavingsRate != 5 || months[2].Income != 0 || months[3].NetSavings != -500 {
+		t.Errorf("months = %+v, want 25%%, 5%%, an empty March and a $500 shortfall", months)
+	}
+
+	data := SavingsStripChart(ts)
+	trace := data["data"].([]map[string]interface{})[0]
+	bands := trace["z"].([][]interface{})[0]
+	want := []interface{}{3, 1, nil, 0}
+	for i := range want {
+		if bands[i] != want[i] {
+			t.Errorf("band for %s = %v, want %v", months[i].Month, bands[i], want[i])
+		}
+	}
+
+	if MonthlySavings(models.NewTransactionSet(nil)) != nil {
+		t.Error("no data should have no months")
+	}
+}
+
+func TestDecomposeSavingsRate(t *testing.T) {
+	previous := models.NewTransactionSet([]models.Transaction{
+		txn("2025-01-01", 4000, "Paycheck"),
+		txn("2025-01-05", -2000, "Rent"),
+		txn("2025-01-10", -600, "Groceries"),
+		txn("2025-01-20", -200, "Travel"),
+	})
+	current := models.NewTransactionSet([]models.Transaction{
+		txn("2025-02-01", 5000, "Paycheck"),
+		txn("2025-02-05", -2000, "Rent"),
+		txn("2025-02-10", -1000, "Groceries"),
+	})
+
+	d := DecomposeSavingsRate(current, previous)
+	if d == nil {
+		t.Fatal("expected a decomposition")
+	}
+	// 30% saved before, 40% now
+	if math.Abs(d.PreviousRate-30) > 1e-9 || math.Abs(d.CurrentRate-40) > 1e-9 {
+		t.Errorf("rates = %.2f -> %.2f, want 30 -> 40", d.PreviousRate, d.CurrentRate)
+	}
+	// $2800 of spending against $5000 instead of $4000
+	if math.Abs(d.IncomeEffect-14) > 1e-9 {
+		t.Errorf("income effect = %.2f, want 14", d.IncomeEffect)
+	}
+	if len(d.Categories) != 2 || d.Categories[0].Category != "Groceries" || math.Abs(d.Categories[0].Effect+8) > 1e-9 ||
+		d.Categories[1].Category != "Travel" || math.Abs(d.Categories[1].Effect-4) > 1e-9 {
+		t.Errorf("categories = %+v, want Groceries -8pp then Travel +4pp, and unchanged Rent left out", d.Categories)
+	}
+
+	sum := d.IncomeEffect + d.OtherEffect
+	for _, c := range d.Categories {
+		sum += c.Effect
+	}
+	if math.Abs(sum-d.Change()) > 1e-9 {
+		t.Errorf("effects add up to %.2f, want the %.2f change", sum, d.Change())
+	}
+
+	chart := SavingsRateWaterfallChart(d)
+	trace := chart["data"].([]map[string]interface{})[0]
+	if x := trace["x"].([]string); len(x) != 5 || x[0] != "Previous rate" || x[4] != "Current rate" {
+		t.Errorf("bars = %v", x)
+	}
+
+	if DecomposeSavingsRate(current, models.NewTransactionSet(nil)) != nil {
+		t.Error("a period without income should have no decomposition")
+	}
+}
+
+func TestCategoryTreemapChart(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2025-01-01", 4000, "Paycheck"),
+		txn("2025-01-05", -80.10, "Food:Groceries"),
+		txn("2025-01-06", -40.20, "Food:Restaurants"),
+		txn("2025-01-07", -1500, "Rent"),
+	})
+
+	trace := CategoryTreemapChart(ts)["data"].([]map[string]interface{})[0]
+	ids := trace["ids"].([]string)
+	if strings.Join(ids, ",") != "Food,Food:Groceries,Food:Restaurants,Rent" {
+		t.Fatalf("ids = %v, want Food, its subcategories and Rent", ids)
+	}
+	if parents := trace["parents"].([]string); parents[0] != "" || parents[1] != "Food" || parents[3] != "" {
+		t.Errorf("parents = %v", parents)
+	}
+	if labels := trace["labels"].([]string); labels[1] != "Groceries" {
+		t.Errorf("labels = %v, want subcategories labelled by their own name", labels)
+	}
+	// Food has no spending of its own; Plotly adds its subcategories
+	if values := trace["values"].([]float64); values[0] != 0 || values[1] != 80.10 || values[3] != 1500 {
+		t.Errorf("values = %v", values)
+	}
+}
+
+func TestChart(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2025-01-01", 4000, "Paycheck"),
+		txn("2025-01-05", -1500, "Rent"),
+	})
+
+	for _, chartType := range ChartTypes {
+		data, ok := Chart(chartType, ts)
+		if !ok {
+			t.Errorf("Chart(%q) not recognized", chartType)
+			continue
+		}
+		if _, ok := data["data"]; !ok {
+			t.Errorf("Chart(%q) missing data traces", chartType)
+		}
+	}
+
+	if _, ok := Chart("unknown", ts); ok {
+		t.Error("unknown chart type should not be recognized")
+	}
+}
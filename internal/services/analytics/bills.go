@@ -0,0 +1,112 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"budget2/internal/models"
+)
+
+// BillForecastDays is how far ahead upcoming bills are forecast
+const BillForecastDays = 30
+
+// BillGraceDays is how long past its expected date a payment may still
+// post before it counts as missed
+const BillGraceDays = 3
+
+// ForecastBills lists the recurring payments due in the days after asOf,
+// each as often as it falls due, and flags the ones missed: expected more
+// than BillGraceDays before asOf with no payment since. A payment missed
+// for more than two of its intervals is taken as cancelled and left out.
+// The calendar's weeks start on weekStart.
+func ForecastBills(recurring []models.RecurringPayment, asOf time.Time, days int, weekStart time.Weekday) *models.BillForecast {
+	today := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	through := today.AddDate(0, 0, days)
+	f := &models.BillForecast{
+		AsOf:    today.Format("2006-01-02"),
+		Through: through.Format("2006-01-02"),
+		Days:    days,
+		Bills:   []models.UpcomingBill{},
+	}
+
+	for _, r := range recurring {
+		interval := int(math.Round(r.NextExpected.Sub(r.LastDate).Hours() / 24))
+		if interval <= 0 {
+			continue
+		}
+		bill := func(due time.Time) models.UpcomingBill {
+			return models.UpcomingBill{
+				Description: r.Description,
+				Merchant:    r.Merchant,
+				Frequency:   r.Frequency,
+				Amount:      r.Amount,
+				Due:         due.Format("2006-01-02"),
+			}
+		}
+
+		due := time.Date(r.NextExpected.Year(), r.NextExpected.Month(), r.NextExpected.Day(), 0, 0, 0, 0, time.UTC)
+		late := int(today.Sub(due).Hours() / 24)
+		switch {
+		case late > BillGraceDays && late > 2*interval:
+			continue
+		case late > BillGraceDays:
+			missed := bill(due)
+			missed.Overdue = true
+			missed.DaysOverdue = late
+			f.Bills = append(f.Bills, missed)
+			f.OverdueTotal += r.Amount
+		case late > 0:
+			// Still within its grace days, so it may yet post
+			f.Bills = append(f.Bills, bill(due))
+			f.Total += r.Amount
+		}
+		for due.Before(today) {
+			due = due.AddDate(0, 0, interval)
+		}
+		for ; !due.After(through); due = due.AddDate(0, 0, interval) {
+			f.Bills = append(f.Bills, bill(due))
+			f.Total += r.Amount
+		}
+	}
+
+	sort.SliceStable(f.Bills, func(i, j int) bool {
+		return f.Bills[i].Due < f.Bills[j].Due
+	})
+	f.Weeks = billCalendar(f.Bills, today, through, weekStart)
+	return f
+}
+
+// billCalendar lays bills out by day over whole weeks from the one holding
+// start to the one holding end. Missed bills stay off it, since their days
+// are past.
+func billCalendar(bills []models.UpcomingBill, start, end time.Time, weekStart time.Weekday) [][]models.BillDay {
+	byDate := make(map[string][]models.UpcomingBill)
+	for _, b := range bills {
+		if !b.Overdue {
+			byDate[b.Due] = append(byDate[b.Due], b)
+		}
+	}
+
+	var weeks [][]models.BillDay
+	for week := models.WeekStartOf(start, weekStart); !week.After(end); week = week.AddDate(0, 0, 7) {
+		days := make([]models.BillDay, 7)
+		for i := range days {
+			date := week.AddDate(0, 0, i)
+			key := date.Format("2006-01-02")
+			days[i] = models.BillDay{
+				Date:    key,
+				Day:     date.Day(),
+				Weekday: date.Format("Mon"),
+				InRange: !date.Before(start) && !date.After(end),
+				Today:   date.Equal(start),
+				Bills:   byDate[key],
+			}
+			for _, b := range days[i].Bills {
+				days[i].Total += b.Amount
+			}
+		}
+		weeks = append(weeks, days)
+	}
+	return weeks
+}
@@ -0,0 +1,617 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"budget2/internal/models"
+	"budget2/internal/services/categories"
+)
+
+// ChartTypes lists the chart names accepted by Chart
+var ChartTypes = []string{"monthly", "category", "treemap", "income", "accounts", "cashflow", "merchants", "weekly", "cumulative"}
+
+// Chart builds Plotly data for a named chart. ok is false for unknown types.
+func Chart(chartType string, ts *models.TransactionSet) (data map[string]interface{}, ok bool) {
+	switch chartType {
+	case "monthly":
+		return MonthlyChart(ts), true
+	case "category":
+		return CategoryChart(ts), true
+	case "treemap":
+		return CategoryTreemapChart(ts), true
+	case "income":
+		return IncomeCategoryChart(ts), true
+	case "accounts":
+		return AccountsChart(ts), true
+	case "cashflow":
+		return CashflowChart(ts), true
+	case "merchants":
+		return MerchantsChart(ts), true
+	case "weekly":
+		return WeeklyPatternChart(ts), true
+	case "cumulative":
+		return CumulativeChart(ts), true
+	default:
+		return nil, false
+	}
+}
+
+// MonthlyChart builds grouped income/expense bars per month
+func MonthlyChart(ts *models.TransactionSet) map[string]interface{} {
+	income := ts.FilterByType(models.Income)
+	outflows := ts.FilterByType(models.Outflow)
+
+	monthlyIncome := income.MonthlyTotals()
+	monthlyOutflows := outflows.MonthlyTotals()
+
+	// Combine and sort months
+	monthSet := make(map[string]bool)
+	for m := range monthlyIncome {
+		monthSet[m] = true
+	}
+	for m := range monthlyOutflows {
+		monthSet[m] = true
+	}
+
+	var months []string
+	for m := range monthSet {
+		months = append(months, m)
+	}
+	sort.Strings(months)
+
+	var incomeValues, expenseValues []float64
+	for _, m := range months {
+		incomeValues = append(incomeValues, monthlyIncome[m])
+		expenseValues = append(expenseValues, math.Abs(monthlyOutflows[m]))
+	}
+
+	return map[string]interface{}{
+		"data": []map[string]interface{}{
+			{
+				"type": "bar",
+				"name": "Income",
+				"x":    months,
+				"y":    incomeValues,
+				"marker": map[string]string{
+					"color": "#22c55e",
+				},
+			},
+			{
+				"type": "bar",
+				"name": "Expenses",
+				"x":    months,
+				"y":    expenseValues,
+				"marker": map[string]string{
+					"color": "#ef4444",
+				},
+			},
+		},
+		"layout": map[string]interface{}{
+			"barmode": "group",
+		},
+	}
+}
+
+// CategoryChart builds a donut of the top 10 spending categories
+func CategoryChart(ts *models.TransactionSet) map[string]interface{} {
+	return categoryPie(ts.FilterByType(models.Outflow).CategoryTotals())
+}
+
+// IncomeCategoryChart builds a donut of income by category (salary,
+// interest, dividends, side gigs)
+func IncomeCategoryChart(ts *models.TransactionSet) map[string]interface{} {
+	return categoryPie(ts.FilterByType(models.Income).CategoryTotals())
+}
+
+// categoryPie builds a donut of the largest ten categoryTotals, with the
+// rest grouped as Other
+func categoryPie(categoryTotals map[string]float64) map[string]interface{} {
+	// Sort by value
+	type catVal struct {
+		cat string
+		val float64
+	}
+	var sorted []catVal
+	for cat, val := range categoryTotals {
+		sorted = append(sorted, catVal{cat, val})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].val > sorted[j].val
+	})
+
+	// Take top 10
+	if len(sorted) > 10 {
+		other := 0.0
+		for _, cv := range sorted[10:] {
+			other += cv.val
+		}
+		sorted = sorted[:10]
+		if other > 0 {
+			sorted = append(sorted, catVal{"Other", other})
+		}
+	}
+
+	var labels []string
+	var values []float64
+	var colors []string
+	for _, cv := range sorted {
+		labels = append(labels, cv.cat)
+		values = append(values, cv.val)
+		colors = append(colors, categories.Color(cv.cat))
+	}
+
+	return map[string]interface{}{
+		"data": []map[string]interface{}{
+			{
+				"type":   "pie",
+				"labels": labels,
+				"values": values,
+				"hole":   0.4,
+				"marker": map[string]interface{}{
+					"colors": colors,
+				},
+			},
+		},
+	}
+}
+
+// CategoryTreemapChart builds a treemap of spending by category that shows
+// the top categories and their subcategories, opening deeper levels when a
+// parent is clicked. Each box's customdata is whether it has no
+// subcategories.
+func CategoryTreemapChart(ts *models.TransactionSet) map[string]interface{} {
+	ids := []string{}
+	labels := []string{}
+	parents := []string{}
+	values := []float64{}
+	colors := []string{}
+	leaves := []bool{}
+	for _, n := range ts.FilterByType(models.Outflow).CategoryTree() {
+		ids = append(ids, n.Category)
+		labels = append(labels, n.Name)
+		parents = append(parents, n.Parent)
+		// Plotly adds the subcategories to each box's own spending, so
+		// totals never fall short of their parts through rounding
+		values = append(values, math.Round(n.Own*100)/100)
+		colors = append(colors, categories.Color(n.Category))
+		leaves = append(leaves, n.Leaf)
+	}
+
+	return map[string]interface{}{
+		"data": []map[string]interface{}{
+			{
+				"type":          "treemap",
+				"ids":           ids,
+				"labels":        labels,
+				"parents":       parents,
+				"values":        values,
+				"customdata":    leaves,
+				"branchvalues":  "remainder",
+				"maxdepth":      2,
+				"textinfo":      "label+value+percent root",
+				"hovertemplate": "%{id}<br>$%{value:,.2f}<extra></extra>",
+				"marker": map[string]interface{}{
+					"colors": colors,
+				},
+			},
+		},
+		"layout": map[string]interface{}{
+			"margin": map[string]int{"t": 10, "r": 10, "b": 10, "l": 10},
+		},
+	}
+}
+
+// AccountsChart builds grouped income/spending bars per account
+func AccountsChart(ts *models.TransactionSet) map[string]interface{} {
+	var names []string
+	var incomeValues, spendingValues []float64
+	for _, s := range AccountSummaries(nil, ts) {
+		names = append(names, s.Name)
+		incomeValues = append(incomeValues, s.Income)
+		spendingValues = append(spendingValues, s.Spending)
+	}
+
+	return map[string]interface{}{
+		"data": []map[string]interface{}{
+			{
+				"type": "bar",
+				"name": "Income",
+				"x":    names,
+				"y":    incomeValues,
+				"marker": map[string]string{
+					"color": "#22c55e",
+				},
+			},
+			{
+				"type": "bar",
+				"name": "Spending",
+				"x":    names,
+				"y":    spendingValues,
+				"marker": map[string]string{
+					"color": "#ef4444",
+				},
+			},
+		},
+		"layout": map[string]interface{}{
+			"barmode": "group",
+		},
+	}
+}
+
+// CashflowChart builds a line of net cash flow per day
+func CashflowChart(ts *models.TransactionSet) map[string]interface{} {
+	sorted := ts.SortByDate()
+	daily := sorted.GroupByDate()
+
+	// Sort dates
+	var dates []string
+	for d := range daily {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+
+	var dateLabels []string
+	var amounts []float64
+
+	for _, d := range dates {
+		dayTotal := daily[d].SumAmount()
+		dateLabels = append(dateLabels, d)
+		amounts = append(amounts, dayTotal)
+	}
+
+	return map[string]interface{}{
+		"data": []map[string]interface{}{
+			{
+				"type": "scatter",
+				"mode": "lines",
+				"name": "Cash Flow",
+				"x":    dateLabels,
+				"y":    amounts,
+				"line": map[string]interface{}{
+					"color": "#6366f1",
+					"width": 2,
+				},
+				"fill":      "tozeroy",
+				"fillcolor": "rgba(99, 102, 241, 0.1)",
+			},
+		},
+	}
+}
+
+// MerchantsChart builds horizontal bars for the top 10 merchants
+func MerchantsChart(ts *models.TransactionSet) map[string]interface{} {
+	outflows := ts.FilterByType(models.Outflow)
+
+	// Group by description (merchant)
+	merchantTotals := make(map[string]float64)
+	for _, t := range outflows.Transactions {
+		merchantTotals[t.Description] += math.Abs(t.Amount)
+	}
+
+	// Sort by value
+	type merchVal struct {
+		name string
+		val  float64
+	}
+	var sorted []merchVal
+	for name, val := range merchantTotals {
+		sorted = append(sorted, merchVal{name, val})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].val > sorted[j].val
+	})
+
+	// Take top 10
+	if len(sorted) > 10 {
+		sorted = sorted[:10]
+	}
+
+	// Reverse for horizontal bar chart
+	var labels []string
+	var values []float64
+	for i := len(sorted) - 1; i >= 0; i-- {
+		labels = append(labels, sorted[i].name)
+		values = append(values, sorted[i].val)
+	}
+
+	return map[string]interface{}{
+		"data": []map[string]interface{}{
+			{
+				"type":        "bar",
+				"orientation": "h",
+				"x":           values,
+				"y":           labels,
+				"marker": map[string]string{
+					"color": "#8b5cf6",
+				},
+			},
+		},
+	}
+}
+
+// WeeklyPatternChart builds average weekly spending per day of week
+func WeeklyPatternChart(ts *models.TransactionSet) map[string]interface{} {
+	outflows := ts.FilterByType(models.Outflow)
+
+	// Group by day of week
+	dayTotals := make(map[int]float64)
+	dayCounts := make(map[int]int)
+	dayNames := []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+	for _, t := range outflows.Transactions {
+		dow := int(t.Date.Weekday())
+		dayTotals[dow] += math.Abs(t.Amount)
+		dayCounts[dow]++
+	}
+
+	// Calculate averages per day
+	var values []float64
+	for i := 0; i < 7; i++ {
+		if dayCounts[i] > 0 {
+			// Get number of weeks in the data
+			minDate := ts.MinDate()
+			maxDate := ts.MaxDate()
+			weeks := maxDate.Sub(minDate).Hours() / 24 / 7
+			if weeks < 1 {
+				weeks = 1
+			}
+			values = append(values, dayTotals[i]/weeks)
+		} else {
+			values = append(values, 0)
+		}
+	}
+
+	return map[string]interface{}{
+		"data": []map[string]interface{}{
+			{
+				"type": "bar",
+				"x":    dayNames,
+				"y":    values,
+				"marker": map[string]interface{}{
+					"color": []string{
+						"#94a3b8", "#3b82f6", "#3b82f6", "#3b82f6",
+						"#3b82f6", "#3b82f6", "#94a3b8",
+					},
+				},
+			},
+		},
+		"layout": map[string]interface{}{
+			"yaxis": map[string]interface{}{
+				"title": "Avg Spending ($)",
+			},
+		},
+	}
+}
+
+// Savings rate bands colored on the savings strip: below zero, under 10%,
+// under 20% and 20% or more
+var savingsBands = []struct {
+	below float64
+	label string
+	color string
+}{
+	{0, "Spent more than earned", "#ef4444"},
+	{10, "Under 10%", "#f59e0b"},
+	{20, "10-20%", "#86efac"},
+	{math.Inf(1), "20% or more", "#16a34a"},
+}
+
+// savingsBand returns the index in savingsBands of a month's savings rate.
+// A month spending more than it earned is in the lowest band even without
+// income to take a rate of.
+func savingsBand(m models.MonthlySummary) int {
+	if m.NetSavings < 0 {
+		return 0
+	}
+	for i, b := range savingsBands {
+		if m.SavingsRate < b.below {
+			return i
+		}
+	}
+	return len(savingsBands) - 1
+}
+
+// SavingsStripChart builds a one-row heatmap of each month's savings rate,
+// colored by band so runs of good and bad months stand out across the
+// whole history. Months without transactions are left blank.
+func SavingsStripChart(ts *models.TransactionSet) map[string]interface{} {
+	var months []string
+	var bands []interface{}
+	var text []string
+	for _, m := range MonthlySavings(ts) {
+		months = append(months, m.Month)
+		if m.Income == 0 && m.Expenses == 0 {
+			bands = append(bands, nil)
+			text = append(text, m.Month+": no data")
+			continue
+		}
+		band := savingsBand(m)
+		bands = append(bands, band)
+		text = append(text, fmt.Sprintf("%s: %.1f%% saved (%s)", m.Month, m.SavingsRate, savingsBands[band].label))
+	}
+
+	// Each band takes an equal slice of the color scale
+	var scale [][]interface{}
+	n := float64(len(savingsBands))
+	for i, b := range savingsBands {
+		scale = append(scale, []interface{}{float64(i) / n, b.color}, []interface{}{float64(i+1) / n, b.color})
+	}
+
+	return map[string]interface{}{
+		"data": []map[string]interface{}{
+			{
+				"type":       "heatmap",
+				"x":          months,
+				"y":          []string{"Savings rate"},
+				"z":          [][]interface{}{bands},
+				"text":       [][]string{text},
+				"hoverinfo":  "text",
+				"zmin":       -0.5,
+				"zmax":       n - 0.5,
+				"colorscale": scale,
+				"showscale":  false,
+				"xgap":       2,
+			},
+		},
+		"layout": map[string]interface{}{
+			"height":     110,
+			"margin":     map[string]int{"t": 10, "r": 10, "b": 30, "l": 10},
+			"showlegend": false,
+			"yaxis": map[string]interface{}{
+				"visible": false,
+			},
+		},
+	}
+}
+
+// CumulativeChart builds the running balance over the period
+func CumulativeChart(ts *models.TransactionSet) map[string]interface{} {
+	sorted := ts.SortByDate()
+	daily := sorted.GroupByDate()
+
+	// Sort dates
+	var dates []string
+	for d := range daily {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+
+	var dateLabels []string
+	var cumulative []float64
+	var runningTotal float64
+
+	for _, d := range dates {
+		dayTotal := daily[d].SumAmount()
+		runningTotal += dayTotal
+		dateLabels = append(dateLabels, d)
+		cumulative = append(cumulative, runningTotal)
+	}
+
+	// Determine line color based on final value
+	lineColor := "#22c55e"
+	fillColor := "rgba(34, 197, 94, 0.1)"
+	if runningTotal < 0 {
+		lineColor = "#ef4444"
+		fillColor = "rgba(239, 68, 68, 0.1)"
+	}
+
+	return map[string]interface{}{
+		"data": []map[string]interface{}{
+			{
+				"type": "scatter",
+				"mode": "lines",
+				"name": "Cumulative Balance",
+				"x":    dateLabels,
+				"y":    cumulative,
+				"line": map[string]interface{}{
+					"color": lineColor,
+					"width": 2,
+				},
+				"fill":      "tozeroy",
+				"fillcolor": fillColor,
+			},
+		},
+		"layout": map[string]interface{}{
+			"yaxis": map[string]interface{}{
+				"title": "Cumulative ($)",
+			},
+		},
+	}
+}
+
+// BurnDownChart plots the month's cumulative spending against the budget
+// pace, last month's spending and the projection to the month's end with its
+// confidence band, or the same for a week on the weekly cycle
+func BurnDownChart(b *models.BurnDown) map[string]interface{} {
+	previous := "Last Month"
+	xaxis := map[string]interface{}{
+		"title": "Day of " + b.Month,
+		"dtick": 5,
+	}
+	if b.Cycle == models.CycleWeekly {
+		previous = "Last Week"
+		xaxis = map[string]interface{}{
+			"title":    "Week of " + b.Month,
+			"tickvals": b.Days,
+			"ticktext": b.DayLabels,
+		}
+	}
+
+	traces := []map[string]interface{}{}
+	if len(b.Pace) > 0 {
+		traces = append(traces, map[string]interface{}{
+			"type": "scatter",
+			"mode": "lines",
+			"name": "Budget Pace",
+			"x":    b.Days,
+			"y":    b.Pace,
+			"line": map[string]interface{}{"color": "#9ca3af", "width": 2, "dash": "dash"},
+		})
+	}
+	if len(b.LastMonth) > 0 {
+		traces = append(traces, map[string]interface{}{
+			"type": "scatter",
+			"mode": "lines",
+			"name": previous,
+			"x":    b.Days[:min(len(b.LastMonth), len(b.Days))],
+			"y":    b.LastMonth[:min(len(b.LastMonth), len(b.Days))],
+			"line": map[string]interface{}{"color": "#94a3b8", "width": 2, "dash": "dot"},
+		})
+	}
+	if len(b.Projected) > 1 && len(b.ProjectedLow) == len(b.Projected) {
+		// The band fills from its low edge up to its high edge
+		x := b.Days[len(b.Spent)-1:]
+		traces = append(traces, map[string]interface{}{
+			"type":       "scatter",
+			"mode":       "lines",
+			"name":       "Projected Low",
+			"x":          x,
+			"y":          b.ProjectedLow,
+			"line":       map[string]interface{}{"width": 0},
+			"showlegend": false,
+			"hoverinfo":  "skip",
+		}, map[string]interface{}{
+			"type":      "scatter",
+			"mode":      "lines",
+			"name":      fmt.Sprintf("%d%% Range", b.ProjectedRange.Level),
+			"x":         x,
+			"y":         b.ProjectedHigh,
+			"fill":      "tonexty",
+			"fillcolor": "rgba(245, 158, 11, 0.15)",
+			"line":      map[string]interface{}{"width": 0},
+			"hoverinfo": "skip",
+		})
+	}
+	if len(b.Projected) > 1 {
+		traces = append(traces, map[string]interface{}{
+			"type": "scatter",
+			"mode": "lines",
+			"name": "Projected",
+			"x":    b.Days[len(b.Spent)-1:],
+			"y":    b.Projected,
+			"line": map[string]interface{}{"color": "#f59e0b", "width": 2, "dash": "dash"},
+		})
+	}
+	if len(b.Spent) > 0 {
+		traces = append(traces, map[string]interface{}{
+			"type": "scatter",
+			"mode": "lines+markers",
+			"name": "Spent",
+			"x":    b.Days[:len(b.Spent)],
+			"y":    b.Spent,
+			"line": map[string]interface{}{"color": "#ef4444", "width": 3},
+		})
+	}
+
+	return map[string]interface{}{
+		"data": traces,
+		"layout": map[string]interface{}{
+			"xaxis": xaxis,
+			"yaxis": map[string]interface{}{
+				"title": "Spent ($)",
+			},
+		},
+	}
+}
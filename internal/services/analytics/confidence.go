@@ -0,0 +1,108 @@
+package analytics
+
+import (
+	"math"
+	"time"
+
+	"budget2/internal/models"
+)
+
+// ConfidenceLevel is the percent of outcomes a projected range should hold
+const ConfidenceLevel = 80
+
+// confidenceZ is the two-sided normal quantile for ConfidenceLevel
+const confidenceZ = 1.2816
+
+// MinHistoryDays is the fewest days of history a daily spread is taken from;
+// with less, projections go without a range
+const MinHistoryDays = 14
+
+// DailySpendStdDev returns the standard deviation of daily outflows in ts
+// from start through end, counting days without spending, or zero when the
+// span covers fewer than MinHistoryDays days
+func DailySpendStdDev(ts *models.TransactionSet, start, end time.Time) float64 {
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(last.Sub(first).Hours()/24) + 1
+	if days < MinHistoryDays {
+		return 0
+	}
+
+	daily := make([]float64, days)
+	for _, t := range ts.FilterByDateRange(start, end).FilterByType(models.Outflow).Transactions {
+		date := time.Date(t.Date.Year(), t.Date.Month(), t.Date.Day(), 0, 0, 0, 0, time.UTC)
+		if day := int(date.Sub(first).Hours() / 24); day >= 0 && day < days {
+			daily[day] += math.Abs(t.Amount)
+		}
+	}
+	_, stdDev := meanStdDev(daily)
+	return stdDev
+}
+
+// ProjectionRange spreads a projection that still has days of spending to
+// come, each varying by dailyStdDev. Days are taken as independent, so the
+// spread grows with the square root of the days left. Returns nil without a
+// deviation to go on.
+func ProjectionRange(projection, dailyStdDev float64, days int) *models.ConfidenceRange {
+	if dailyStdDev <= 0 {
+		return nil
+	}
+	spread := confidenceZ * dailyStdDev * math.Sqrt(float64(max(days, 0)))
+	return &models.ConfidenceRange{
+		Low:   projection - spread,
+		High:  projection + spread,
+		Level: ConfidenceLevel,
+	}
+}
+
+// MonthlyProjection projects the next month from monthly amounts: their
+// average, with the range their month-to-month variance gives. The range is
+// nil with fewer than two months and never goes below zero.
+func MonthlyProjection(amounts []float64) (float64, *models.ConfidenceRange) {
+	mean, stdDev := meanStdDev(amounts)
+	if len(amounts) < 2 {
+		return mean, nil
+	}
+	spread := confidenceZ * stdDev
+	return mean, &models.ConfidenceRange{
+		Low:   math.Max(mean-spread, 0),
+		High:  mean + spread,
+		Level: ConfidenceLevel,
+	}
+}
+
+// meanStdDev returns the mean and population standard deviation of values
+func meanStdDev(values []float64) (mean, stdDev float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	var sumSq float64
+	for _, v := range values {
+		sumSq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sumSq / float64(len(values)))
+}
+
+// addProjectionBand fills a burn-down's confidence band around its
+// projection, from the daily spread of spending before it. The band's
+// bottom never drops below what's already spent.
+func addProjectionBand(b *models.BurnDown, dailyStdDev float64) {
+	if dailyStdDev <= 0 || len(b.Projected) == 0 {
+		return
+	}
+	spent := b.Projected[0]
+	for i, projected := range b.Projected {
+		r := ProjectionRange(projected, dailyStdDev, i)
+		b.ProjectedLow = append(b.ProjectedLow, math.Max(r.Low, spent))
+		b.ProjectedHigh = append(b.ProjectedHigh, r.High)
+	}
+	b.ProjectedRange = &models.ConfidenceRange{
+		Low:   b.ProjectedLow[len(b.ProjectedLow)-1],
+		High:  b.ProjectedHigh[len(b.ProjectedHigh)-1],
+		Level: ConfidenceLevel,
+	}
+}
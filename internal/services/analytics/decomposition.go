@@ -0,0 +1,146 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"budget2/internal/models"
+)
+
+// waterfallCategories is how many categories a savings rate decomposition
+// lists on their own before combining the rest
+const waterfallCategories = 8
+
+// DecomposeSavingsRate attributes the change in savings rate from previous
+// to current to the change in income and to each category's spending
+// change. It returns nil unless both periods have income, since a savings
+// rate needs income to be measured against.
+func DecomposeSavingsRate(current, previous *models.TransactionSet) *models.SavingsRateDecomposition {
+	curIncome := current.FilterByType(models.Income).SumAmount()
+	prevIncome := previous.FilterByType(models.Income).SumAmount()
+	if curIncome <= 0 || prevIncome <= 0 {
+		return nil
+	}
+
+	curSpend := categorySpending(current)
+	prevSpend := categorySpending(previous)
+	var curTotal, prevTotal float64
+	for _, amount := range curSpend {
+		curTotal += amount
+	}
+	for _, amount := range prevSpend {
+		prevTotal += amount
+	}
+
+	d := &models.SavingsRateDecomposition{
+		PreviousRate:   (prevIncome - prevTotal) / prevIncome * 100,
+		CurrentRate:    (curIncome - curTotal) / curIncome * 100,
+		PreviousIncome: prevIncome,
+		CurrentIncome:  curIncome,
+		// Previous spending against the new income, less the old rate
+		IncomeEffect: (prevTotal/prevIncome - prevTotal/curIncome) * 100,
+	}
+
+	// Categories only spent on before contribute too
+	for category := range prevSpend {
+		if _, ok := curSpend[category]; !ok {
+			curSpend[category] = 0
+		}
+	}
+	var contributions []models.RateContribution
+	for category := range curSpend {
+		c := models.RateContribution{
+			Category: category,
+			Previous: prevSpend[category],
+			Current:  curSpend[category],
+		}
+		c.Effect = -(c.Current - c.Previous) / curIncome * 100
+		if c.Effect != 0 {
+			contributions = append(contributions, c)
+		}
+	}
+	sort.Slice(contributions, func(i, j int) bool {
+		a, b := math.Abs(contributions[i].Effect), math.Abs(contributions[j].Effect)
+		if a != b {
+			return a > b
+		}
+		return contributions[i].Category < contributions[j].Category
+	})
+
+	if len(contributions) > waterfallCategories {
+		for _, c := range contributions[waterfallCategories:] {
+			d.OtherEffect += c.Effect
+		}
+		d.OtherCount = len(contributions) - waterfallCategories
+		contributions = contributions[:waterfallCategories]
+	}
+	d.Categories = contributions
+	return d
+}
+
+// categorySpending totals the outflows in ts by category
+func categorySpending(ts *models.TransactionSet) map[string]float64 {
+	totals := make(map[string]float64)
+	for category, txns := range ts.FilterByType(models.Outflow).GroupByCategory() {
+		totals[category] = txns.SumAbsAmount()
+	}
+	return totals
+}
+
+// SavingsRateWaterfallChart builds a waterfall from the previous savings
+// rate, through the income effect and each category's effect, to the
+// current rate
+func SavingsRateWaterfallChart(d *models.SavingsRateDecomposition) map[string]interface{} {
+	x := []string{"Previous rate", "Income"}
+	y := []float64{d.PreviousRate, d.IncomeEffect}
+	measure := []string{"absolute", "relative"}
+	for _, c := range d.Categories {
+		x = append(x, c.Category)
+		y = append(y, c.Effect)
+		measure = append(measure, "relative")
+	}
+	if d.OtherCount > 0 {
+		x = append(x, "Other categories")
+		y = append(y, d.OtherEffect)
+		measure = append(measure, "relative")
+	}
+	x = append(x, "Current rate")
+	y = append(y, d.CurrentRate)
+	measure = append(measure, "total")
+
+	text := make([]string, len(y))
+	for i, v := range y {
+		if measure[i] == "relative" {
+			text[i] = fmt.Sprintf("%+.1fpp", v)
+		} else {
+			text[i] = fmt.Sprintf("%.1f%%", v)
+		}
+	}
+
+	return map[string]interface{}{
+		"data": []map[string]interface{}{{
+			"type":         "waterfall",
+			"x":            x,
+			"y":            y,
+			"measure":      measure,
+			"text":         text,
+			"textposition": "outside",
+			"hoverinfo":    "x+text",
+			"connector":    map[string]interface{}{"line": map[string]interface{}{"color": "#9ca3af", "width": 1}},
+			"increasing":   map[string]interface{}{"marker": map[string]interface{}{"color": "#22c55e"}},
+			"decreasing":   map[string]interface{}{"marker": map[string]interface{}{"color": "#ef4444"}},
+			"totals":       map[string]interface{}{"marker": map[string]interface{}{"color": "#6366f1"}},
+		}},
+		"layout": map[string]interface{}{
+			"showlegend": false,
+			"yaxis": map[string]interface{}{
+				"title":      "Savings rate (%)",
+				"ticksuffix": "%",
+			},
+			"xaxis": map[string]interface{}{
+				"tickangle": -30,
+			},
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package analytics
+
+import (
+	"math"
+	"strings"
+
+	"budget2/internal/models"
+)
+
+// OneOffPurchases flags large purchases that don't recur: outflows at least
+// three times the average day's spending, the bar large purchase alerts
+// use, from a payee that appears only once. Like the alerts, it needs a
+// week of spending days to judge what's large.
+func OneOffPurchases(ts *models.TransactionSet) []models.Transaction {
+	outflows := ts.FilterByType(models.Outflow)
+	daily := outflows.GroupByDate()
+	if len(daily) < 7 {
+		return nil
+	}
+	mean := outflows.SumAbsAmount() / float64(len(daily))
+
+	payees := make(map[string]int)
+	for _, t := range outflows.Transactions {
+		payees[strings.ToLower(strings.TrimSpace(t.Description))]++
+	}
+
+	var oneOffs []models.Transaction
+	for _, t := range outflows.Transactions {
+		if math.Abs(t.Amount) > mean*3 && payees[strings.ToLower(strings.TrimSpace(t.Description))] == 1 {
+			oneOffs = append(oneOffs, t)
+		}
+	}
+	return oneOffs
+}
@@ -0,0 +1,24 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/testutil"
+)
+
+// BenchmarkCharts builds every dashboard chart over three years of data
+func BenchmarkCharts(b *testing.B) {
+	ts := models.NewTransactionSet(testutil.SyntheticTransactions(3 * 365))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, chartType := range ChartTypes {
+			Chart(chartType, ts)
+		}
+	}
+}
+
+func TestChartsBudget(t *testing.T) {
+	testutil.CheckBudget(t, 100*time.Millisecond, BenchmarkCharts)
+}
@@ -0,0 +1,129 @@
+package analytics
+
+import (
+	"time"
+
+	"budget2/internal/models"
+)
+
+// CalculateMonthStatus summarizes the month of the latest transaction against
+// budget. A budget of zero falls back to average monthly spending over the
+// prior 12 months; with no history either, the budget fields stay zero.
+func CalculateMonthStatus(ts *models.TransactionSet, budget float64) *models.MonthStatus {
+	asOf := ts.MaxDate()
+	if asOf.IsZero() {
+		return &models.MonthStatus{}
+	}
+
+	monthStart := time.Date(asOf.Year(), asOf.Month(), 1, 0, 0, 0, 0, asOf.Location())
+	month := CalculateMetrics(ts.FilterByDateRange(monthStart, asOf))
+
+	status := &models.MonthStatus{
+		Month:       monthStart.Format("2006-01"),
+		AsOf:        asOf.Format("2006-01-02"),
+		Spent:       month.TotalExpenses,
+		Income:      month.TotalIncome,
+		SavingsRate: month.SavingsRate,
+	}
+
+	daysInMonth := monthStart.AddDate(0, 1, -1).Day()
+	status.MonthElapsed = float64(asOf.Day()) / float64(daysInMonth) * 100
+
+	if budget > 0 {
+		status.Budget = budget
+		status.BudgetSource = models.BudgetConfigured
+	} else {
+		history := ts.FilterByDateRange(monthStart.AddDate(-1, 0, 0), monthStart.AddDate(0, 0, -1))
+		outflows := history.FilterByType(models.Outflow)
+		if months := len(history.GroupByMonth()); months > 0 {
+			status.Budget = outflows.SumAbsAmount() / float64(months)
+			status.BudgetSource = models.BudgetAverage
+		}
+	}
+
+	if status.Budget > 0 {
+		status.BudgetUsedPercent = status.Spent / status.Budget * 100
+		status.Remaining = status.Budget - status.Spent
+		status.OnTrack = status.BudgetUsedPercent <= status.MonthElapsed
+	}
+
+	return status
+}
+
+// BurnDown builds the cumulative spending curve for the month of the latest
+// transaction, the straight-line pace to its budget (resolved as in
+// CalculateMonthStatus), the prior month's curve, and a projection to the
+// month's end at dailyRate, banded by the daily spread of spending over the
+// 12 months before.
+func BurnDown(ts *models.TransactionSet, budget, dailyRate float64) *models.BurnDown {
+	status := CalculateMonthStatus(ts, budget)
+	if status.Month == "" {
+		return &models.BurnDown{}
+	}
+
+	asOf := ts.MaxDate()
+	monthStart := time.Date(asOf.Year(), asOf.Month(), 1, 0, 0, 0, 0, asOf.Location())
+	daysInMonth := monthStart.AddDate(0, 1, -1).Day()
+
+	b := &models.BurnDown{
+		Month:        status.Month,
+		AsOf:         status.AsOf,
+		Budget:       status.Budget,
+		BudgetSource: status.BudgetSource,
+		DailyRate:    dailyRate,
+	}
+	for day := 1; day <= daysInMonth; day++ {
+		b.Days = append(b.Days, day)
+		if b.Budget > 0 {
+			b.Pace = append(b.Pace, b.Budget*float64(day)/float64(daysInMonth))
+		}
+	}
+	b.Spent = cumulativeSpending(ts, monthStart, asOf.Day())
+
+	prevStart := monthStart.AddDate(0, -1, 0)
+	if !ts.MinDate().After(monthStart.AddDate(0, 0, -1)) {
+		b.LastMonth = cumulativeSpending(ts, prevStart, monthStart.AddDate(0, 0, -1).Day())
+	}
+
+	projected := b.Spent[len(b.Spent)-1]
+	for day := asOf.Day(); day <= daysInMonth; day++ {
+		if day > asOf.Day() {
+			projected += dailyRate
+		}
+		b.Projected = append(b.Projected, projected)
+	}
+	b.ProjectedTotal = projected
+	addProjectionBand(b, historyStdDev(ts, monthStart.AddDate(-1, 0, 0), monthStart))
+
+	return b
+}
+
+// historyStdDev returns the daily spread of spending from from up to the day
+// before until, starting no earlier than the data does
+func historyStdDev(ts *models.TransactionSet, from, until time.Time) float64 {
+	if first := ts.MinDate(); from.Before(first) {
+		from = first
+	}
+	return DailySpendStdDev(ts, from, until.AddDate(0, 0, -1))
+}
+
+// cumulativeSpending returns the running total of outflows for each of the
+// first days of the month or week starting at start
+func cumulativeSpending(ts *models.TransactionSet, start time.Time, days int) []float64 {
+	outflows := ts.FilterByDateRange(start, start.AddDate(0, 0, days-1)).FilterByType(models.Outflow)
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	daily := make([]float64, days)
+	for _, t := range outflows.Transactions {
+		date := time.Date(t.Date.Year(), t.Date.Month(), t.Date.Day(), 0, 0, 0, 0, time.UTC)
+		if day := int(date.Sub(first).Hours() / 24); day >= 0 && day < days {
+			daily[day] += -t.Amount
+		}
+	}
+
+	var total float64
+	for i, amount := range daily {
+		total += amount
+		daily[i] = total
+	}
+	return daily
+}
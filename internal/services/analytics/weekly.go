@@ -0,0 +1,214 @@
+package analytics
+
+import (
+	"time"
+
+	"budget2/internal/models"
+)
+
+// SmoothingWeeks is how many weeks before a week its smoothed income
+// averages, enough to even out a slow week or a late payout
+const SmoothingWeeks = 12
+
+// DefaultWeeks is how many weeks the weekly status covers
+const DefaultWeeks = 8
+
+// weekTotals returns the income and spending of each week in ts, keyed by
+// the week's first day
+func weekTotals(ts *models.TransactionSet, weekStart time.Weekday) (income, spent map[string]float64) {
+	income = make(map[string]float64)
+	spent = make(map[string]float64)
+	for week, set := range ts.GroupByWeek(weekStart) {
+		income[week] = set.FilterByType(models.Income).SumAmount()
+		spent[week] = set.FilterByType(models.Outflow).SumAbsAmount()
+	}
+	return income, spent
+}
+
+// CalculateWeeklyStatus summarizes the week of the latest transaction and
+// the weeks before it, at most weeks of them. Each week's target is the
+// cycle's weekly budget, or else its smoothed income: the average weekly
+// income over up to SmoothingWeeks weeks before it, counting only weeks
+// since the data begins. Without either, targets stay zero.
+func CalculateWeeklyStatus(ts *models.TransactionSet, cycle models.BudgetCycle, weeks int) *models.WeeklyStatus {
+	asOf := ts.MaxDate()
+	status := &models.WeeklyStatus{WeekStart: cycle.WeekStart.String(), Weeks: []models.WeekStatus{}}
+	if asOf.IsZero() {
+		return status
+	}
+	if weeks < 1 {
+		weeks = 1
+	}
+
+	income, spent := weekTotals(ts, cycle.WeekStart)
+	first := models.WeekStartOf(ts.MinDate(), cycle.WeekStart)
+	current := models.WeekStartOf(asOf, cycle.WeekStart)
+
+	for i := weeks - 1; i >= 0; i-- {
+		start := current.AddDate(0, 0, -7*i)
+		if start.Before(first) {
+			continue
+		}
+		key := start.Format("2006-01-02")
+		week := models.WeekStatus{
+			Start:  key,
+			End:    start.AddDate(0, 0, 6).Format("2006-01-02"),
+			Income: income[key],
+			Spent:  spent[key],
+		}
+
+		var total float64
+		n := 0
+		for back := 1; back <= SmoothingWeeks; back++ {
+			prior := start.AddDate(0, 0, -7*back)
+			if prior.Before(first) {
+				break
+			}
+			total += income[prior.Format("2006-01-02")]
+			n++
+		}
+		if n > 0 {
+			week.SmoothedIncome = total / float64(n)
+		}
+
+		week.Target = week.SmoothedIncome
+		if cycle.WeeklyBudget > 0 {
+			week.Target = cycle.WeeklyBudget
+		}
+		if week.Target > 0 {
+			week.Remaining = week.Target - week.Spent
+		}
+		if previous := spent[start.AddDate(0, 0, -7).Format("2006-01-02")]; previous > 0 {
+			week.SpentChange = (week.Spent - previous) / previous * 100
+		}
+		status.Weeks = append(status.Weeks, week)
+	}
+
+	status.Current = status.Weeks[len(status.Weeks)-1]
+	status.AsOf = asOf.Format("2006-01-02")
+	status.WeekElapsed = float64(int(asOf.Weekday()-cycle.WeekStart+7)%7+1) / 7 * 100
+	switch {
+	case cycle.WeeklyBudget > 0:
+		status.TargetSource = models.BudgetConfigured
+	case status.Current.Target > 0:
+		status.TargetSource = models.BudgetSmoothedIncome
+	}
+	if status.Current.Target > 0 {
+		status.OnTrack = status.Current.Spent/status.Current.Target*100 <= status.WeekElapsed
+	}
+	return status
+}
+
+// WeeklyBurnDown builds the burn-down for the week of the latest
+// transaction: its cumulative spending by day against the straight-line
+// pace to its target (as in CalculateWeeklyStatus), the week before, and a
+// projection to the week's end at dailyRate, banded by the daily spread of
+// spending over the SmoothingWeeks weeks before
+func WeeklyBurnDown(ts *models.TransactionSet, cycle models.BudgetCycle, dailyRate float64) *models.BurnDown {
+	status := CalculateWeeklyStatus(ts, cycle, 1)
+	if status.AsOf == "" {
+		return &models.BurnDown{Cycle: models.CycleWeekly}
+	}
+
+	asOf := ts.MaxDate()
+	weekStart := models.WeekStartOf(asOf, cycle.WeekStart)
+	today := int(asOf.Weekday()-cycle.WeekStart+7)%7 + 1
+
+	b := &models.BurnDown{
+		Cycle:        models.CycleWeekly,
+		Month:        status.Current.Start,
+		AsOf:         status.AsOf,
+		Budget:       status.Current.Target,
+		BudgetSource: status.TargetSource,
+		DailyRate:    dailyRate,
+	}
+	for day := 1; day <= 7; day++ {
+		b.Days = append(b.Days, day)
+		b.DayLabels = append(b.DayLabels, weekStart.AddDate(0, 0, day-1).Format("Mon"))
+		if b.Budget > 0 {
+			b.Pace = append(b.Pace, b.Budget*float64(day)/7)
+		}
+	}
+	b.Spent = cumulativeSpending(ts, weekStart, today)
+
+	if !ts.MinDate().After(weekStart.AddDate(0, 0, -1)) {
+		b.LastMonth = cumulativeSpending(ts, weekStart.AddDate(0, 0, -7), 7)
+	}
+
+	projected := b.Spent[len(b.Spent)-1]
+	for day := today; day <= 7; day++ {
+		if day > today {
+			projected += dailyRate
+		}
+		b.Projected = append(b.Projected, projected)
+	}
+	b.ProjectedTotal = projected
+	addProjectionBand(b, historyStdDev(ts, weekStart.AddDate(0, 0, -7*SmoothingWeeks), weekStart))
+
+	return b
+}
+
+// WeeklyBudgetChart charts each week's spending against its target, with
+// actual and smoothed income for comparison
+func WeeklyBudgetChart(s *models.WeeklyStatus) map[string]interface{} {
+	var weeks []string
+	var spent, income, smoothed, target []float64
+	hasTarget := false
+	for _, w := range s.Weeks {
+		weeks = append(weeks, w.Start)
+		spent = append(spent, w.Spent)
+		income = append(income, w.Income)
+		smoothed = append(smoothed, w.SmoothedIncome)
+		target = append(target, w.Target)
+		hasTarget = hasTarget || w.Target > 0
+	}
+
+	traces := []map[string]interface{}{
+		{
+			"type":   "bar",
+			"name":   "Spent",
+			"x":      weeks,
+			"y":      spent,
+			"marker": map[string]interface{}{"color": "#ef4444"},
+		},
+		{
+			"type":   "bar",
+			"name":   "Income",
+			"x":      weeks,
+			"y":      income,
+			"marker": map[string]interface{}{"color": "#86efac"},
+		},
+		{
+			"type": "scatter",
+			"mode": "lines+markers",
+			"name": "Smoothed Income",
+			"x":    weeks,
+			"y":    smoothed,
+			"line": map[string]interface{}{"color": "#16a34a", "width": 2},
+		},
+	}
+	if hasTarget && s.TargetSource == models.BudgetConfigured {
+		traces = append(traces, map[string]interface{}{
+			"type": "scatter",
+			"mode": "lines",
+			"name": "Target",
+			"x":    weeks,
+			"y":    target,
+			"line": map[string]interface{}{"color": "#9ca3af", "width": 2, "dash": "dash"},
+		})
+	}
+
+	return map[string]interface{}{
+		"data": traces,
+		"layout": map[string]interface{}{
+			"barmode": "group",
+			"xaxis": map[string]interface{}{
+				"title": "Week starting",
+				"type":  "category",
+			},
+			"yaxis": map[string]interface{}{
+				"title": "Amount ($)",
+			},
+		},
+	}
+}
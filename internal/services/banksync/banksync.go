@@ -0,0 +1,360 @@
+// Package banksync pulls posted transactions from bank aggregators such as
+// SimpleFIN Bridge and Plaid on a schedule, writing each account's
+// transactions to its own CSV file in the data directory so they load like
+// any uploaded export.
+package banksync
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/dataloader"
+	"budget2/internal/services/storage"
+)
+
+// stateFile holds the bank sync state
+var stateFile = storage.SettingsFile("bank_sync.json", func() interface{} { return &state{} })
+
+const (
+	// initialDays is how far back the first sync with a provider reaches
+	initialDays = 90
+	// lookbackDays of transactions are fetched again on every sync to catch
+	// ones that post late
+	lookbackDays = 10
+)
+
+// Transaction is a posted transaction from a provider, with money out
+// negative
+type Transaction struct {
+	ID          string
+	Date        time.Time
+	Description string
+	Amount      float64
+}
+
+// Account is an account and its transactions as a provider reports them
+type Account struct {
+	ID           string
+	Name         string
+	Institution  string
+	Balance      *float64
+	Transactions []Transaction
+}
+
+// Provider fetches accounts with their posted transactions since a date.
+// A provider may return accounts along with an error when only some of its
+// connections failed.
+type Provider interface {
+	Name() string
+	Fetch(since time.Time) ([]Account, error)
+}
+
+// Config holds where synced data goes and how often to sync
+type Config struct {
+	DataDir     string
+	SettingsDir string
+	Interval    time.Duration
+	Providers   []Provider
+}
+
+// state is the sync status plus the transactions already written
+type state struct {
+	models.BankSyncStatus
+	// Seen maps each account's key to the IDs of transactions written
+	// within the lookback window, and their dates
+	Seen map[string]map[string]string `json:"seen,omitempty"`
+}
+
+// Service syncs transactions from the configured providers
+type Service struct {
+	cfg    Config
+	store  *storage.Storage
+	loader *dataloader.DataLoader
+	path   string
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+// NewService creates a sync service; loader may be nil
+func NewService(cfg Config, store *storage.Storage, loader *dataloader.DataLoader) *Service {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 6 * time.Hour
+	}
+	return &Service{
+		cfg:    cfg,
+		store:  store,
+		loader: loader,
+		path:   filepath.Join(cfg.SettingsDir, stateFile),
+		now:    time.Now,
+	}
+}
+
+// Providers names the configured providers
+func (s *Service) Providers() []string {
+	var names []string
+	for _, p := range s.cfg.Providers {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+// Start syncs in a background goroutine, once at startup and then every
+// interval
+func (s *Service) Start() {
+	go func() {
+		for {
+			if _, err := s.Sync(); err != nil {
+				log.Printf("Warning: bank sync failed: %v", err)
+			}
+			time.Sleep(s.cfg.Interval)
+		}
+	}()
+}
+
+// Status returns how the last sync went
+func (s *Service) Status() (*models.BankSyncStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return &st.BankSyncStatus, nil
+}
+
+// Sync fetches new transactions from every provider and appends them to
+// their accounts' files. A provider that fails keeps its accounts' files
+// as they were and is retried from its last success next time.
+func (s *Service) Sync() (*models.BankSyncStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	var errs []error
+	written := false
+	for _, p := range s.cfg.Providers {
+		run := st.provider(p.Name())
+		run.LastRun = now
+		run.Error = ""
+
+		since := now.AddDate(0, 0, -initialDays)
+		if !run.LastSuccess.IsZero() {
+			since = run.LastSuccess.AddDate(0, 0, -lookbackDays)
+		}
+
+		accounts, err := p.Fetch(since)
+		if err != nil {
+			run.Error = err.Error()
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+		}
+		if err == nil || len(accounts) > 0 {
+			if err == nil {
+				run.LastSuccess = now
+			}
+			for _, a := range accounts {
+				added, err := s.syncAccount(st, p.Name(), a, since, now)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s %s: %w", p.Name(), a.Name, err))
+				}
+				written = written || added > 0
+			}
+		} else {
+			for i := range st.Accounts {
+				if st.Accounts[i].Provider == p.Name() {
+					st.Accounts[i].Error = run.Error
+				}
+			}
+		}
+	}
+
+	if err := s.store.WriteJSON(s.path, st); err != nil {
+		return nil, err
+	}
+	if written && s.loader != nil {
+		s.loader.Invalidate()
+	}
+	return &st.BankSyncStatus, errors.Join(errs...)
+}
+
+// syncAccount records an account's status and appends its new transactions
+// to its file, returning how many were added
+func (s *Service) syncAccount(st *state, provider string, a Account, since, now time.Time) (int, error) {
+	acct := st.account(provider, a.ID)
+	acct.Name = a.Name
+	acct.Institution = a.Institution
+	acct.Balance = a.Balance
+	if acct.File == "" {
+		acct.File = s.fileName(st, *acct)
+	}
+
+	added, err := s.writeTransactions(st, *acct, a.Transactions, since)
+	if err != nil {
+		acct.Error = err.Error()
+		return 0, err
+	}
+	acct.LastSync = now
+	acct.Added = added
+	acct.Error = ""
+	return added, nil
+}
+
+// csvHeader is the layout of synced files, which the loader reads as is
+var csvHeader = []string{"Date", "Description", "Amount", "Account"}
+
+// writeTransactions appends transactions not already written to the
+// account's file, keeping it in date order. If the file was deleted the
+// lookback window is written again.
+func (s *Service) writeTransactions(st *state, acct models.BankSyncAccount, txns []Transaction, since time.Time) (int, error) {
+	key := acct.Provider + "/" + acct.ID
+	path := filepath.Join(s.cfg.DataDir, acct.File)
+
+	var rows [][]string
+	data, err := s.store.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		delete(st.Seen, key)
+	case err != nil:
+		return 0, err
+	default:
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			return 0, fmt.Errorf("reading %s: %w", acct.File, err)
+		}
+		if len(records) > 0 {
+			rows = records[1:]
+		}
+	}
+
+	if st.Seen == nil {
+		st.Seen = make(map[string]map[string]string)
+	}
+	seen := st.Seen[key]
+	if seen == nil {
+		seen = make(map[string]string)
+		st.Seen[key] = seen
+	}
+
+	added := 0
+	for _, t := range txns {
+		if t.ID == "" || seen[t.ID] != "" {
+			continue
+		}
+		date := t.Date.Format("2006-01-02")
+		rows = append(rows, []string{date, t.Description, strconv.FormatFloat(t.Amount, 'f', 2, 64), acct.Label()})
+		seen[t.ID] = date
+		added++
+	}
+
+	// Transactions older than this sync's window won't be fetched again
+	cutoff := since.Format("2006-01-02")
+	for id, date := range seen {
+		if date < cutoff {
+			delete(seen, id)
+		}
+	}
+
+	if added == 0 && data != nil {
+		return 0, nil
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(csvHeader)
+	w.WriteAll(rows)
+	if err := w.Error(); err != nil {
+		return 0, err
+	}
+	if err := s.store.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// fileName picks a data file name for a newly synced account from its
+// label, numbering it if another account or an uploaded file has the name
+func (s *Service) fileName(st *state, acct models.BankSyncAccount) string {
+	base := "bank-" + slug(acct.Label())
+	taken := func(name string) bool {
+		if st.BankSyncStatus.Account(name) != nil {
+			return true
+		}
+		_, err := s.store.Stat(filepath.Join(s.cfg.DataDir, name))
+		return err == nil
+	}
+	name := base + ".csv"
+	for n := 2; taken(name); n++ {
+		name = fmt.Sprintf("%s-%d.csv", base, n)
+	}
+	return name
+}
+
+// slug lowercases s and replaces runs of anything but letters and digits
+// with a dash
+func slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "account"
+	}
+	return b.String()
+}
+
+// provider returns the named provider's status, adding it if new
+func (st *state) provider(name string) *models.BankSyncProvider {
+	for i := range st.Providers {
+		if st.Providers[i].Name == name {
+			return &st.Providers[i]
+		}
+	}
+	st.Providers = append(st.Providers, models.BankSyncProvider{Name: name})
+	return &st.Providers[len(st.Providers)-1]
+}
+
+// account returns a provider's account status, adding it if new
+func (st *state) account(provider, id string) *models.BankSyncAccount {
+	for i := range st.Accounts {
+		if st.Accounts[i].Provider == provider && st.Accounts[i].ID == id {
+			return &st.Accounts[i]
+		}
+	}
+	st.Accounts = append(st.Accounts, models.BankSyncAccount{ID: id, Provider: provider})
+	return &st.Accounts[len(st.Accounts)-1]
+}
+
+// load reads the sync state (caller must hold lock)
+func (s *Service) load() (*state, error) {
+	st := &state{}
+	if err := s.store.ReadJSON(s.path, st); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return st, nil
+}
@@ -0,0 +1,216 @@
+package banksync
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/dataloader"
+	"budget2/internal/services/storage"
+)
+
+// fakeProvider returns the accounts or error set by the test
+type fakeProvider struct {
+	accounts []Account
+	err      error
+	since    time.Time
+}
+
+func (f *fakeProvider) Name() string { return "Fake" }
+
+func (f *fakeProvider) Fetch(since time.Time) ([]Account, error) {
+	f.since = since
+	return f.accounts, f.err
+}
+
+func TestSync(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	loader := dataloader.New(dir, store)
+	provider := &fakeProvider{}
+	svc := NewService(Config{DataDir: dir, SettingsDir: filepath.Join(dir, "settings"), Providers: []Provider{provider}}, store, loader)
+	now := time.Date(2024, 3, 20, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	balance := 1200.0
+	provider.accounts = []Account{{
+		ID: "acct-1", Name: "Checking", Institution: "First Bank", Balance: &balance,
+		Transactions: []Transaction{
+			{ID: "t2", Date: day(12), Description: "GROCERY, INC", Amount: -54.20},
+			{ID: "t1", Date: day(10), Description: "PAYROLL", Amount: 2500},
+		},
+	}}
+
+	status, err := svc.Sync()
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if want := now.AddDate(0, 0, -initialDays); !provider.since.Equal(want) {
+		t.Errorf("first sync since %v, want %v", provider.since, want)
+	}
+	acct := status.Account("bank-first-bank-checking.csv")
+	if acct == nil || acct.Added != 2 || !acct.LastSync.Equal(now) || *acct.Balance != 1200 {
+		t.Fatalf("account status = %+v", status.Accounts)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "bank-first-bank-checking.csv"))
+	want := "Date,Description,Amount,Account\n" +
+		"2024-03-10,PAYROLL,2500.00,First Bank Checking\n" +
+		"2024-03-12,\"GROCERY, INC\",-54.20,First Bank Checking\n"
+	if string(data) != want {
+		t.Errorf("file =\n%s\nwant\n%s", data, want)
+	}
+
+	// The loader reads the file as written
+	loaded, err := loader.LoadData()
+	if err != nil || loaded.Len() != 2 {
+		t.Fatalf("loaded %v transactions, err %v", loaded.Len(), err)
+	}
+
+	// A later sync overlaps the last and adds only new transactions
+	now = now.AddDate(0, 0, 1)
+	provider.accounts[0].Transactions = append(provider.accounts[0].Transactions,
+		Transaction{ID: "t3", Date: day(20), Description: "COFFEE", Amount: -4.50})
+	status, err = svc.Sync()
+	if err != nil {
+		t.Fatalf("second Sync: %v", err)
+	}
+	if want := now.AddDate(0, 0, -1-lookbackDays); !provider.since.Equal(want) {
+		t.Errorf("second sync since %v, want %v", provider.since, want)
+	}
+	if acct := status.Account("bank-first-bank-checking.csv"); acct.Added != 1 {
+		t.Errorf("second sync added %d, want 1", acct.Added)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "bank-first-bank-checking.csv"))
+	if n := strings.Count(string(data), "\n"); n != 4 {
+		t.Errorf("file has %d lines, want header and 3 rows:\n%s", n, data)
+	}
+
+	// A failed sync keeps the files and marks the provider's accounts
+	provider.accounts, provider.err = nil, fmt.Errorf("bridge unavailable")
+	status, err = svc.Sync()
+	if err == nil || !strings.Contains(err.Error(), "bridge unavailable") {
+		t.Fatalf("err = %v, want the provider's error", err)
+	}
+	if acct := status.Account("bank-first-bank-checking.csv"); acct.Error != "bridge unavailable" || !acct.LastSync.Equal(now) {
+		t.Errorf("account after failure = %+v", acct)
+	}
+	if p := status.Providers[0]; p.Error == "" || !p.LastSuccess.Equal(now) {
+		t.Errorf("provider after failure = %+v", p)
+	}
+
+	// Status persists
+	saved, err := svc.Status()
+	if err != nil || len(saved.Accounts) != 1 || saved.Accounts[0].Error == "" {
+		t.Errorf("saved status = %+v, err %v", saved, err)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	os.WriteFile(filepath.Join(dir, "bank-savings.csv"), []byte("Date,Description,Amount\n"), 0644)
+	svc := NewService(Config{DataDir: dir, SettingsDir: dir}, store, nil)
+
+	if name := svc.fileName(&state{}, models.BankSyncAccount{Name: "Savings"}); name != "bank-savings-2.csv" {
+		t.Errorf("name = %q, want an uploaded file's name skipped", name)
+	}
+	if got := slug("  Joint Acct. #1234 "); got != "joint-acct-1234" {
+		t.Errorf("slug = %q", got)
+	}
+	if got := slug("***"); got != "account" {
+		t.Errorf("empty slug = %q", got)
+	}
+}
+
+func TestSimpleFIN(t *testing.T) {
+	var query, user string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/simplefin/accounts" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.RawQuery
+		user, _, _ = r.BasicAuth()
+		w.Write([]byte(`{"errors": ["Connection to First Bank may need attention"], "accounts": [{
+			"id": "ACT-1", "name": "Checking", "org": {"name": "First Bank"}, "balance": "1500.25",
+			"transactions": [
+				{"id": "T1", "posted": 1710028800, "amount": "-12.50", "description": "CAFE", "payee": "Cafe"},
+				{"id": "T2", "posted": 0, "amount": "-8.00", "description": "PENDING", "pending": true},
+				{"id": "T3", "posted": 1710115200, "amount": "100.00", "description": "", "payee": "Refund"}
+			]}]}`))
+	}))
+	defer srv.Close()
+
+	p := &SimpleFIN{AccessURL: strings.Replace(srv.URL, "http://", "http://demo:secret@", 1) + "/simplefin"}
+	accounts, err := p.Fetch(time.Unix(1700000000, 0))
+	if err == nil || !strings.Contains(err.Error(), "need attention") {
+		t.Errorf("err = %v, want the bridge's errors", err)
+	}
+	if user != "demo" || query != "start-date=1700000000" {
+		t.Errorf("user %q query %q", user, query)
+	}
+	if len(accounts) != 1 || accounts[0].Institution != "First Bank" || *accounts[0].Balance != 1500.25 {
+		t.Fatalf("accounts = %+v", accounts)
+	}
+	txns := accounts[0].Transactions
+	if len(txns) != 2 || txns[0].Amount != -12.50 || txns[0].Date.Format("2006-01-02") != "2024-03-10" || txns[1].Description != "Refund" {
+		t.Errorf("transactions = %+v, want posted ones with payee as a fallback description", txns)
+	}
+
+	if _, err := (&SimpleFIN{AccessURL: "not a url"}).Fetch(time.Now()); err == nil {
+		t.Error("an invalid access URL should fail")
+	}
+}
+
+func TestPlaid(t *testing.T) {
+	var offsets []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			AccessToken string `json:"access_token"`
+			StartDate   string `json:"start_date"`
+			Options     struct {
+				Offset int `json:"offset"`
+			} `json:"options"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.AccessToken == "expired" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error_code": "ITEM_LOGIN_REQUIRED", "error_message": "login required", "display_message": ""}`))
+			return
+		}
+		offsets = append(offsets, req.Options.Offset)
+		accounts := `[{"account_id": "A1", "name": "Plaid Checking", "mask": "0000", "balances": {"current": 110}}]`
+		txns := `[{"transaction_id": "X1", "account_id": "A1", "date": "2024-03-10", "name": "Uber", "amount": 6.33}]`
+		if req.Options.Offset > 0 {
+			txns = `[{"transaction_id": "X2", "account_id": "A1", "date": "2024-03-11", "name": "Deposit", "amount": -500, "pending": false},
+				{"transaction_id": "X3", "account_id": "A1", "date": "2024-03-12", "name": "Pending", "amount": 9, "pending": true}]`
+		}
+		fmt.Fprintf(w, `{"accounts": %s, "transactions": %s, "total_transactions": 3}`, accounts, txns)
+	}))
+	defer srv.Close()
+
+	p := &Plaid{ClientID: "id", Secret: "secret", AccessTokens: []string{"good", "expired"}, BaseURL: srv.URL}
+	accounts, err := p.Fetch(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err == nil || !strings.Contains(err.Error(), "ITEM_LOGIN_REQUIRED") {
+		t.Errorf("err = %v, want the failed item's error", err)
+	}
+	if len(offsets) != 2 || offsets[1] != 1 {
+		t.Errorf("offsets = %v, want a second page", offsets)
+	}
+	if len(accounts) != 1 || accounts[0].Name != "Plaid Checking 0000" || *accounts[0].Balance != 110 {
+		t.Fatalf("accounts = %+v", accounts)
+	}
+	txns := accounts[0].Transactions
+	if len(txns) != 2 || txns[0].Amount != -6.33 || txns[1].Amount != 500 {
+		t.Errorf("transactions = %+v, want posted ones with money out negative", txns)
+	}
+}
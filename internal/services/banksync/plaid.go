@@ -0,0 +1,165 @@
+package banksync
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// plaidPageSize is the most transactions Plaid returns per request
+const plaidPageSize = 500
+
+// Plaid fetches transactions for one or more linked Plaid items, each
+// identified by the access token its link flow returned
+type Plaid struct {
+	ClientID     string
+	Secret       string
+	AccessTokens []string
+	Environment  string       // "sandbox" or "production"; empty is production
+	BaseURL      string       // Overrides the environment's API URL
+	Client       *http.Client // nil uses a client with a one-minute timeout
+}
+
+// Name identifies the provider in sync status
+func (p *Plaid) Name() string { return "Plaid" }
+
+type plaidAccount struct {
+	AccountID    string `json:"account_id"`
+	Name         string `json:"name"`
+	OfficialName string `json:"official_name"`
+	Mask         string `json:"mask"`
+	Balances     struct {
+		Current *float64 `json:"current"`
+	} `json:"balances"`
+}
+
+type plaidTransactionsResponse struct {
+	Accounts     []plaidAccount `json:"accounts"`
+	Transactions []struct {
+		TransactionID string  `json:"transaction_id"`
+		AccountID     string  `json:"account_id"`
+		Date          string  `json:"date"`
+		Name          string  `json:"name"`
+		Amount        float64 `json:"amount"`
+		Pending       bool    `json:"pending"`
+	} `json:"transactions"`
+	TotalTransactions int `json:"total_transactions"`
+}
+
+type plaidError struct {
+	ErrorCode      string `json:"error_code"`
+	ErrorMessage   string `json:"error_message"`
+	DisplayMessage string `json:"display_message"`
+}
+
+// Fetch lists each item's accounts with their posted transactions since a
+// date. An item that fails, such as one needing the user to log in again,
+// doesn't stop the others.
+func (p *Plaid) Fetch(since time.Time) ([]Account, error) {
+	var accounts []Account
+	var errs []error
+	for _, token := range p.AccessTokens {
+		item, err := p.fetchItem(token, since)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		accounts = append(accounts, item...)
+	}
+	return accounts, errors.Join(errs...)
+}
+
+// fetchItem pages through one item's transactions
+func (p *Plaid) fetchItem(token string, since time.Time) ([]Account, error) {
+	var accounts []Account
+	index := make(map[string]int)
+	end := time.Now().Format("2006-01-02")
+
+	for offset := 0; ; {
+		var page plaidTransactionsResponse
+		err := p.post("/transactions/get", map[string]interface{}{
+			"client_id":    p.ClientID,
+			"secret":       p.Secret,
+			"access_token": token,
+			"start_date":   since.Format("2006-01-02"),
+			"end_date":     end,
+			"options":      map[string]int{"count": plaidPageSize, "offset": offset},
+		}, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		if offset == 0 {
+			for _, a := range page.Accounts {
+				name := a.OfficialName
+				if name == "" {
+					name = a.Name
+				}
+				if a.Mask != "" {
+					name += " " + a.Mask
+				}
+				index[a.AccountID] = len(accounts)
+				accounts = append(accounts, Account{ID: a.AccountID, Name: name, Balance: a.Balances.Current})
+			}
+		}
+
+		for _, t := range page.Transactions {
+			i, ok := index[t.AccountID]
+			date, err := time.Parse("2006-01-02", t.Date)
+			if !ok || t.Pending || err != nil {
+				continue
+			}
+			// Plaid reports money out as positive
+			accounts[i].Transactions = append(accounts[i].Transactions, Transaction{
+				ID:          t.TransactionID,
+				Date:        date,
+				Description: t.Name,
+				Amount:      -t.Amount,
+			})
+		}
+
+		offset += len(page.Transactions)
+		if len(page.Transactions) == 0 || offset >= page.TotalTransactions {
+			return accounts, nil
+		}
+	}
+}
+
+// post sends a Plaid API request and decodes the response into v, turning
+// Plaid's error responses into errors
+func (p *Plaid) post(path string, body, v interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	base := p.BaseURL
+	if base == "" {
+		env := p.Environment
+		if env == "" {
+			env = "production"
+		}
+		base = "https://" + env + ".plaid.com"
+	}
+
+	resp, err := client(p.Client).Post(base+path, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e plaidError
+		if json.NewDecoder(resp.Body).Decode(&e) != nil || e.ErrorCode == "" {
+			return fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		if e.DisplayMessage != "" {
+			return fmt.Errorf("%s: %s", e.ErrorCode, e.DisplayMessage)
+		}
+		return fmt.Errorf("%s: %s", e.ErrorCode, e.ErrorMessage)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
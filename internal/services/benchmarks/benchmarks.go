@@ -0,0 +1,215 @@
+package benchmarks
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// benchmarksFile holds the category benchmarks
+var benchmarksFile = storage.SettingsFile("benchmarks.json", func() interface{} { return &[]models.CategoryBenchmark{} })
+
+// Benchmark sources
+const (
+	SourceBLS  = "bls"
+	SourceUser = "user"
+)
+
+// OtherCategory is the catch-all benchmark for spending that matches nothing else
+const OtherCategory = "Other"
+
+// DefaultBenchmarks returns baselines derived from the BLS Consumer Expenditure
+// Survey (2023), as a share of average annual household spending
+func DefaultBenchmarks() []models.CategoryBenchmark {
+	return []models.CategoryBenchmark{
+		{Category: "Housing", Percent: 26.4, Matches: []string{"rent", "mortgage", "housing", "home", "furnish", "household"}, Source: SourceBLS},
+		{Category: "Transportation", Percent: 17.0, Matches: []string{"transport", "auto", "gas", "fuel", "vehicle", "parking", "transit", "uber", "lyft"}, Source: SourceBLS},
+		{Category: "Insurance & Pensions", Percent: 12.4, Matches: []string{"insurance", "pension", "retirement", "401k"}, Source: SourceBLS},
+		{Category: "Healthcare", Percent: 8.0, Matches: []string{"health", "medical", "pharmacy", "doctor", "dental"}, Source: SourceBLS},
+		{Category: "Groceries", Percent: 7.8, Matches: []string{"grocer", "supermarket", "food at home"}, Source: SourceBLS},
+		{Category: "Utilities", Percent: 6.5, Matches: []string{"utilit", "electric", "water", "internet", "phone", "mobile"}, Source: SourceBLS},
+		{Category: "Dining Out", Percent: 5.1, Matches: []string{"dining", "restaurant", "coffee", "fast food", "food & drink"}, Source: SourceBLS},
+		{Category: "Entertainment", Percent: 4.7, Matches: []string{"entertainment", "recreation", "streaming", "hobb", "travel"}, Source: SourceBLS},
+		{Category: "Charity & Gifts", Percent: 3.4, Matches: []string{"charit", "donation", "giving", "gift"}, Source: SourceBLS},
+		{Category: "Apparel", Percent: 2.6, Matches: []string{"clothing", "apparel", "shoes"}, Source: SourceBLS},
+		{Category: "Education", Percent: 2.0, Matches: []string{"education", "tuition", "school", "books"}, Source: SourceBLS},
+		{Category: "Personal Care", Percent: 1.3, Matches: []string{"personal care", "hair", "beauty"}, Source: SourceBLS},
+		{Category: OtherCategory, Percent: 2.8, Source: SourceBLS},
+	}
+}
+
+// Manager persists benchmark baselines. Until the user edits a target the
+// defaults are used and nothing is written.
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing benchmarks in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, benchmarksFile),
+		store: store,
+	}
+}
+
+// List returns the active benchmarks
+func (m *Manager) List() ([]models.CategoryBenchmark, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// SetTarget sets the target share for a category, adding a user benchmark
+// if no benchmark with that name exists
+func (m *Manager) SetTarget(category string, percent float64) ([]models.CategoryBenchmark, error) {
+	if percent < 0 || percent > 100 {
+		return nil, fmt.Errorf("target must be between 0 and 100, got %.1f", percent)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	for i := range list {
+		if strings.EqualFold(list[i].Category, category) {
+			list[i].Percent = percent
+			list[i].Source = SourceUser
+			found = true
+			break
+		}
+	}
+	if !found {
+		list = append(list, models.CategoryBenchmark{
+			Category: category,
+			Percent:  percent,
+			Matches:  []string{strings.ToLower(category)},
+			Source:   SourceUser,
+		})
+	}
+
+	if err := m.store.WriteJSON(m.path, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Reset discards user targets and returns to the default benchmarks
+func (m *Manager) Reset() ([]models.CategoryBenchmark, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.store.Remove(m.path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return DefaultBenchmarks(), nil
+}
+
+// loadInternal reads benchmarks without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() ([]models.CategoryBenchmark, error) {
+	var list []models.CategoryBenchmark
+	if err := m.store.ReadJSON(m.path, &list); err != nil {
+		if os.IsNotExist(err) {
+			return DefaultBenchmarks(), nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+// matchBenchmark returns the index of the benchmark a spending category rolls
+// into. Exact name or keyword matches win over substring matches, and anything
+// unmatched falls into the "Other" benchmark when present; -1 means no match.
+func matchBenchmark(benchmarks []models.CategoryBenchmark, category string) int {
+	lower := strings.ToLower(category)
+
+	for i, b := range benchmarks {
+		if strings.EqualFold(b.Category, category) {
+			return i
+		}
+		for _, m := range b.Matches {
+			if m == lower {
+				return i
+			}
+		}
+	}
+
+	for i, b := range benchmarks {
+		for _, m := range b.Matches {
+			if m != "" && strings.Contains(lower, m) {
+				return i
+			}
+		}
+	}
+
+	for i, b := range benchmarks {
+		if strings.EqualFold(b.Category, OtherCategory) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Compare rolls outflows up into benchmark categories and compares each
+// category's share of total spending to its baseline
+func Compare(benchmarks []models.CategoryBenchmark, ts *models.TransactionSet) []models.BenchmarkComparison {
+	totals := ts.FilterByType(models.Outflow).CategoryTotals()
+
+	var total float64
+	for _, amount := range totals {
+		total += amount
+	}
+
+	rows := make([]models.BenchmarkComparison, len(benchmarks))
+	for i, b := range benchmarks {
+		rows[i] = models.BenchmarkComparison{
+			Category:      b.Category,
+			Categories:    []string{},
+			TargetPercent: b.Percent,
+			TargetAmount:  total * b.Percent / 100,
+			Source:        b.Source,
+		}
+	}
+	unmatched := models.BenchmarkComparison{Category: "Not benchmarked", Categories: []string{}}
+
+	for category, amount := range totals {
+		row := &unmatched
+		if idx := matchBenchmark(benchmarks, category); idx >= 0 {
+			row = &rows[idx]
+		}
+		row.Amount += amount
+		row.Categories = append(row.Categories, category)
+	}
+	if unmatched.Amount > 0 {
+		rows = append(rows, unmatched)
+	}
+
+	for i := range rows {
+		if total > 0 {
+			rows[i].ActualPercent = rows[i].Amount / total * 100
+		}
+		rows[i].Difference = rows[i].ActualPercent - rows[i].TargetPercent
+		sort.Strings(rows[i].Categories)
+	}
+
+	// Largest share (actual or target) first
+	sort.SliceStable(rows, func(i, j int) bool {
+		return math.Max(rows[i].ActualPercent, rows[i].TargetPercent) > math.Max(rows[j].ActualPercent, rows[j].TargetPercent)
+	})
+
+	return rows
+}
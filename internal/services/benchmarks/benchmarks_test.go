@@ -0,0 +1,132 @@
+package benchmarks
+
+import (
+	"testing"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func outflow(category string, amount float64) models.Transaction {
+	return models.Transaction{Category: category, Amount: -amount, TransactionType: models.Outflow}
+}
+
+func TestCompare(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		outflow("Rent", 500),
+		outflow("Groceries", 200),
+		outflow("Gas & Fuel", 100),
+		outflow("Shopping", 150),
+		outflow("Pets", 50),
+		{Category: "Paycheck", Amount: 3000, TransactionType: models.Income},
+	})
+
+	benchmarks := []models.CategoryBenchmark{
+		{Category: "Housing", Percent: 40, Matches: []string{"rent"}},
+		{Category: "Food", Percent: 15, Matches: []string{"grocer"}},
+		{Category: "Transportation", Percent: 20, Matches: []string{"gas"}},
+		{Category: "Shopping", Percent: 10, Source: SourceUser},
+		{Category: "Other", Percent: 15},
+	}
+
+	rows := make(map[string]models.BenchmarkComparison)
+	for _, row := range Compare(benchmarks, ts) {
+		rows[row.Category] = row
+	}
+
+	tests := []struct {
+		category   string
+		amount     float64
+		actual     float64
+		difference float64
+	}{
+		{"Housing", 500, 50, 10},
+		{"Food", 200, 20, 5},
+		{"Transportation", 100, 10, -10},
+		{"Shopping", 150, 15, 5}, // exact name match
+		{"Other", 50, 5, -10},    // unmatched "Pets" falls into Other
+	}
+
+	for _, tt := range tests {
+		row, ok := rows[tt.category]
+		if !ok {
+			t.Errorf("missing row for %s", tt.category)
+			continue
+		}
+		if row.Amount != tt.amount || row.ActualPercent != tt.actual || row.Difference != tt.difference {
+			t.Errorf("%s = amount %.0f, actual %.1f%%, diff %.1f; want %.0f, %.1f%%, %.1f",
+				tt.category, row.Amount, row.ActualPercent, row.Difference, tt.amount, tt.actual, tt.difference)
+		}
+	}
+
+	if got := rows["Housing"].TargetAmount; got != 400 {
+		t.Errorf("Housing TargetAmount = %.0f, want 400", got)
+	}
+	if _, ok := rows["Not benchmarked"]; ok {
+		t.Error("spending should fall into Other rather than an unbenchmarked row")
+	}
+}
+
+func TestCompareWithoutOther(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{outflow("Pets", 50)})
+	rows := Compare([]models.CategoryBenchmark{{Category: "Housing", Percent: 30, Matches: []string{"rent"}}}, ts)
+
+	found := false
+	for _, row := range rows {
+		if row.Category == "Not benchmarked" && row.Amount == 50 && row.TargetPercent == 0 {
+			found = true
+		}
+	}
+	if len(rows) != 2 || !found {
+		t.Errorf("expected an unbenchmarked row for Pets, got %+v", rows)
+	}
+}
+
+func TestDefaultBenchmarksSumTo100(t *testing.T) {
+	var total float64
+	for _, b := range DefaultBenchmarks() {
+		total += b.Percent
+	}
+	if total < 99.9 || total > 100.1 {
+		t.Errorf("default benchmarks sum to %.1f%%, want 100%%", total)
+	}
+}
+
+func TestManagerSetTargetAndReset(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	mgr := NewManager(dir, store)
+
+	list, err := mgr.SetTarget("groceries", 10)
+	if err != nil {
+		t.Fatalf("SetTarget failed: %v", err)
+	}
+	if len(list) != len(DefaultBenchmarks()) {
+		t.Errorf("updating an existing benchmark should not add one, have %d", len(list))
+	}
+
+	mgr.SetTarget("Pets", 2)
+	list, _ = mgr.List()
+	var pets *models.CategoryBenchmark
+	for i := range list {
+		if list[i].Category == "Pets" {
+			pets = &list[i]
+		}
+		if list[i].Category == "Groceries" && (list[i].Percent != 10 || list[i].Source != SourceUser) {
+			t.Errorf("Groceries = %+v, want user target of 10", list[i])
+		}
+	}
+	if pets == nil || pets.Percent != 2 {
+		t.Errorf("expected user benchmark for Pets, got %+v", pets)
+	}
+
+	if _, err := mgr.SetTarget("Pets", 120); err == nil {
+		t.Error("expected error for target over 100%")
+	}
+
+	mgr.Reset()
+	list, _ = mgr.List()
+	if len(list) != len(DefaultBenchmarks()) || list[0].Source != SourceBLS {
+		t.Errorf("Reset should restore defaults, got %+v", list)
+	}
+}
@@ -0,0 +1,296 @@
+package budgets
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// budgetsFile holds the category budgets
+var budgetsFile = storage.SettingsFile("budgets.json", func() interface{} { return &[]models.CategoryBudget{} })
+
+// DefaultAlertThreshold is the share of a budget at which a category starts
+// alerting, unless its budget sets another
+const DefaultAlertThreshold = 0.8
+
+// TrailingMonths is how many full months a suggested budget averages
+const TrailingMonths = 3
+
+// Manager persists monthly category budgets
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing budgets in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, budgetsFile),
+		store: store,
+	}
+}
+
+// List returns all budgets sorted by category
+func (m *Manager) List() ([]models.CategoryBudget, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Set saves b, replacing any earlier budget for its category (matched
+// ignoring case). An alert threshold outside (0, 1) uses
+// DefaultAlertThreshold.
+func (m *Manager) Set(b models.CategoryBudget) ([]models.CategoryBudget, error) {
+	b.Category = strings.TrimSpace(b.Category)
+	if b.Category == "" {
+		return nil, fmt.Errorf("category is required")
+	}
+	if b.Limit <= 0 {
+		return nil, fmt.Errorf("monthly budget must be positive, got %.2f", b.Limit)
+	}
+	if b.AlertThreshold <= 0 || b.AlertThreshold >= 1 {
+		b.AlertThreshold = DefaultAlertThreshold
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := append(without(list, b.Category), b)
+	sortByCategory(filtered)
+
+	if err := m.store.WriteJSON(m.path, filtered); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// Remove drops the budget for category
+func (m *Manager) Remove(category string) ([]models.CategoryBudget, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := without(list, category)
+	if err := m.store.WriteJSON(m.path, filtered); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// loadInternal reads the budgets without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() ([]models.CategoryBudget, error) {
+	var list []models.CategoryBudget
+	if err := m.store.ReadJSON(m.path, &list); err != nil {
+		if os.IsNotExist(err) {
+			return []models.CategoryBudget{}, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+// without returns list minus the budget for category
+func without(list []models.CategoryBudget, category string) []models.CategoryBudget {
+	filtered := make([]models.CategoryBudget, 0, len(list)+1)
+	for _, b := range list {
+		if !strings.EqualFold(b.Category, strings.TrimSpace(category)) {
+			filtered = append(filtered, b)
+		}
+	}
+	return filtered
+}
+
+func sortByCategory(list []models.CategoryBudget) {
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Category) < strings.ToLower(list[j].Category)
+	})
+}
+
+// Evaluate totals month-to-date outflows in each budgeted category, as
+// category totals do elsewhere. The month is the one holding the latest
+// transaction in ts, so budgets follow the data rather than the wall clock
+// when imports lag behind.
+func Evaluate(list []models.CategoryBudget, ts *models.TransactionSet) models.BudgetSummary {
+	summary := models.BudgetSummary{Budgets: []models.BudgetStatus{}}
+
+	end := ts.MaxDate()
+	if end.IsZero() {
+		end = time.Now()
+	}
+	start := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, end.Location())
+	summary.Month = start.Format("Jan 2006")
+	summary.Start = start.Format("2006-01-02")
+	summary.End = end.Format("2006-01-02")
+	daysInMonth := start.AddDate(0, 1, -1).Day()
+	summary.MonthPercent = float64(end.Day()) / float64(daysInMonth) * 100
+
+	outflows := ts.FilterByDateRange(start, end).FilterByType(models.Outflow)
+
+	for _, b := range list {
+		spent := outflows.FilterByCategory(b.Category)
+		status := models.BudgetStatus{
+			CategoryBudget:   b,
+			Spent:            spent.SumAbsAmount(),
+			TransactionCount: spent.Len(),
+		}
+		status.Remaining = b.Limit - status.Spent
+		if b.Limit > 0 {
+			status.Percent = status.Spent / b.Limit * 100
+		}
+		threshold := b.AlertThreshold
+		if threshold <= 0 {
+			threshold = DefaultAlertThreshold
+		}
+		switch {
+		case status.Percent > 100:
+			status.Level = models.BudgetOver
+		case status.Percent >= threshold*100:
+			status.Level = models.BudgetWarning
+		default:
+			status.Level = models.BudgetOK
+		}
+		summary.TotalBudget += b.Limit
+		summary.TotalSpent += status.Spent
+		summary.Budgets = append(summary.Budgets, status)
+	}
+
+	// Closest to (or furthest past) the budget first
+	sort.SliceStable(summary.Budgets, func(i, j int) bool {
+		return summary.Budgets[i].Percent > summary.Budgets[j].Percent
+	})
+	return summary
+}
+
+// Actuals compares each budget with the category's outflows in every month
+// from start to end, oldest month first. Months at either end of the range
+// count only the days inside it, and are marked partial along with any
+// month the data in ts doesn't fully cover. Budgets hold one limit, so
+// every month is measured against today's.
+func Actuals(list []models.CategoryBudget, ts *models.TransactionSet, start, end time.Time) models.BudgetActuals {
+	actuals := models.BudgetActuals{
+		Start: start.Format("2006-01-02"),
+		End:   end.Format("2006-01-02"),
+		Rows:  []models.BudgetActual{},
+	}
+	if end.Before(start) || len(list) == 0 {
+		return actuals
+	}
+
+	outflows := ts.FilterByDateRange(start, end).FilterByType(models.Outflow)
+	first, last := ts.MinDate(), ts.MaxDate()
+
+	for month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location()); !month.After(end); month = month.AddDate(0, 1, 0) {
+		monthEnd := month.AddDate(0, 1, -1)
+		from, to := month, monthEnd
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		partial := from.After(month) || to.Before(monthEnd) ||
+			first.IsZero() || first.After(month) || last.Before(monthEnd)
+
+		spent := outflows.FilterByDateRange(from, to)
+		for _, b := range list {
+			actual := math.Round(spent.FilterByCategory(b.Category).SumAbsAmount()*100) / 100
+			row := models.BudgetActual{
+				Month:    month.Format("2006-01"),
+				Category: b.Category,
+				Budget:   b.Limit,
+				Actual:   actual,
+				Variance: math.Round((b.Limit-actual)*100) / 100,
+				Partial:  partial,
+			}
+			if b.Limit > 0 {
+				row.Percent = actual / b.Limit * 100
+			}
+			actuals.TotalBudget += b.Limit
+			actuals.TotalActual += actual
+			actuals.Rows = append(actuals.Rows, row)
+		}
+	}
+	actuals.TotalActual = math.Round(actuals.TotalActual*100) / 100
+	return actuals
+}
+
+// TrailingAverage returns the average monthly outflow in category over the
+// full months before the month of the latest transaction in ts, at most
+// months of them, as a starting point for the category's budget. Months
+// without spending count as zero; months the data doesn't fully cover don't.
+func TrailingAverage(ts *models.TransactionSet, category string, months int) float64 {
+	end := ts.MaxDate()
+	if end.IsZero() || months < 1 {
+		return 0
+	}
+	current := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, end.Location())
+	oldest := ts.MinDate()
+	first := time.Date(oldest.Year(), oldest.Month(), 1, 0, 0, 0, 0, end.Location())
+	if oldest.Day() > 1 {
+		first = first.AddDate(0, 1, 0)
+	}
+
+	start := current
+	counted := 0
+	for counted < months && !start.AddDate(0, -1, 0).Before(first) {
+		start = start.AddDate(0, -1, 0)
+		counted++
+	}
+	if counted == 0 {
+		return 0
+	}
+
+	spent := ts.FilterByDateRange(start, current.AddDate(0, 0, -1)).
+		FilterByType(models.Outflow).
+		FilterByCategory(category).
+		SumAbsAmount()
+	return math.Round(spent/float64(counted)*100) / 100
+}
+
+// Alerts turns categories at or over their alert threshold into spending
+// alerts for the dashboard
+func Alerts(summary models.BudgetSummary) []models.SpendingAlert {
+	var alerts []models.SpendingAlert
+	for _, s := range summary.Budgets {
+		switch s.Level {
+		case models.BudgetOver:
+			alerts = append(alerts, models.SpendingAlert{
+				Type:     "budget_exceeded",
+				Severity: "error",
+				Title:    s.Category + " Over Budget",
+				Message:  fmt.Sprintf("$%.0f of $%.0f spent in %s ($%.0f over)", s.Spent, s.Limit, summary.Month, -s.Remaining),
+				Category: s.Category,
+				Amount:   s.Spent,
+			})
+		case models.BudgetWarning:
+			alerts = append(alerts, models.SpendingAlert{
+				Type:     "budget_warning",
+				Severity: "warning",
+				Title:    s.Category + " Near Budget",
+				Message:  fmt.Sprintf("$%.0f of $%.0f spent in %s (%.0f%%)", s.Spent, s.Limit, summary.Month, s.Percent),
+				Category: s.Category,
+				Amount:   s.Spent,
+			})
+		}
+	}
+	return alerts
+}
@@ -0,0 +1,168 @@
+package budgets
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func txn(date, category string, amount float64) models.Transaction {
+	d, _ := time.Parse("2006-01-02", date)
+	t := models.Transaction{Date: d, Description: category, Amount: amount, Category: category, TransactionType: models.Outflow}
+	if amount > 0 {
+		t.TransactionType = models.Income
+	}
+	return t
+}
+
+func TestSetReplacesAndValidates(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	if _, err := manager.Set(models.CategoryBudget{Category: "Dining"}); err == nil {
+		t.Error("expected error for missing limit")
+	}
+	if _, err := manager.Set(models.CategoryBudget{Limit: 100}); err == nil {
+		t.Error("expected error for missing category")
+	}
+
+	manager.Set(models.CategoryBudget{Category: "Dining", Limit: 300})
+	list, err := manager.Set(models.CategoryBudget{Category: " dining ", Limit: 250, AlertThreshold: 0.9})
+	if err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if len(list) != 1 || list[0].Limit != 250 || list[0].AlertThreshold != 0.9 {
+		t.Errorf("same category should replace the budget, got %+v", list)
+	}
+
+	list, _ = manager.Set(models.CategoryBudget{Category: "Coffee", Limit: 40})
+	if list[0].Category != "Coffee" || list[0].AlertThreshold != DefaultAlertThreshold {
+		t.Errorf("list should be sorted by category with the default threshold, got %+v", list)
+	}
+
+	list, _ = manager.Remove("COFFEE")
+	if len(list) != 1 || list[0].Category != "dining" {
+		t.Errorf("Remove left %+v, want only dining", list)
+	}
+}
+
+func TestEvaluateMonthToDate(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2025-02-20", "Dining", -200), // previous month
+		txn("2025-03-02", "Dining", -180),
+		txn("2025-03-09", "dining", -60),
+		txn("2025-03-10", "Groceries", -300),
+		txn("2025-03-11", "Groceries", -150),
+		txn("2025-03-12", "Groceries", 20), // A refund isn't spending
+		txn("2025-03-15", "Paycheck", 3000),
+	})
+	list := []models.CategoryBudget{
+		{Category: "Dining", Limit: 300, AlertThreshold: 0.8},
+		{Category: "Groceries", Limit: 400, AlertThreshold: 0.8},
+		{Category: "Travel", Limit: 500},
+	}
+
+	summary := Evaluate(list, ts)
+	if summary.Month != "Mar 2025" || summary.Start != "2025-03-01" || summary.End != "2025-03-15" {
+		t.Errorf("month = %s, %s through %s, want Mar 2025 from 2025-03-01 through 2025-03-15", summary.Month, summary.Start, summary.End)
+	}
+	if summary.TotalBudget != 1200 || summary.TotalSpent != 690 {
+		t.Errorf("totals = %.2f of %.2f, want 690 of 1200", summary.TotalSpent, summary.TotalBudget)
+	}
+
+	groceries, dining, travel := summary.Budgets[0], summary.Budgets[1], summary.Budgets[2]
+	if groceries.Category != "Groceries" || groceries.Spent != 450 || groceries.Remaining != -50 || groceries.Level != models.BudgetOver {
+		t.Errorf("Groceries = %+v, want 450 spent and over", groceries)
+	}
+	if dining.Spent != 240 || dining.TransactionCount != 2 || dining.Level != models.BudgetWarning {
+		t.Errorf("Dining = %+v, want 240 across 2 transactions and a warning", dining)
+	}
+	if travel.Spent != 0 || travel.Level != models.BudgetOK {
+		t.Errorf("Travel = %+v, want nothing spent", travel)
+	}
+
+	alerts := Alerts(summary)
+	if len(alerts) != 2 || alerts[0].Severity != "error" || alerts[1].Severity != "warning" {
+		t.Fatalf("alerts = %+v, want an error then a warning", alerts)
+	}
+	if alerts[0].Type != "budget_exceeded" || alerts[0].Category != "Groceries" {
+		t.Errorf("first alert = %+v, want Groceries over budget", alerts[0])
+	}
+}
+
+func TestTrailingAverage(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2025-01-15", "Dining", -900), // January is only partly covered
+		txn("2025-02-03", "Dining", -100),
+		txn("2025-02-20", "dining", -50),
+		txn("2025-04-10", "Dining", -150), // Nothing in March
+		txn("2025-04-11", "Dining", 30),   // A refund isn't spending
+		txn("2025-05-02", "Dining", -500), // The current month isn't averaged
+		txn("2025-05-03", "Groceries", -80),
+	})
+
+	// February through April: (150 + 0 + 150) / 3
+	if got := TrailingAverage(ts, "Dining", 3); got != 100 {
+		t.Errorf("3 months = %.2f, want 100", got)
+	}
+	// Only three full months exist
+	if got := TrailingAverage(ts, "Dining", 12); got != 100 {
+		t.Errorf("12 months = %.2f, want 100 over the three full months", got)
+	}
+	if got := TrailingAverage(ts, "Dining", 1); got != 150 {
+		t.Errorf("1 month = %.2f, want April's 150", got)
+	}
+	if got := TrailingAverage(ts, "Travel", 3); got != 0 {
+		t.Errorf("unspent category = %.2f, want 0", got)
+	}
+	if got := TrailingAverage(models.NewTransactionSet(nil), "Dining", 3); got != 0 {
+		t.Errorf("no data = %.2f, want 0", got)
+	}
+}
+
+func TestActuals(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2025-01-01", "Dining", -120),
+		txn("2025-01-31", "Groceries", -600),
+		txn("2025-02-14", "dining", -260),
+		txn("2025-02-15", "Dining", 10), // A refund isn't spending
+		txn("2025-03-05", "Dining", -40),
+		txn("2025-03-20", "Dining", -75), // After the range
+	})
+	list := []models.CategoryBudget{{Category: "Dining", Limit: 200}, {Category: "Groceries", Limit: 400}}
+	start, _ := time.Parse("2006-01-02", "2025-01-01")
+	end, _ := time.Parse("2006-01-02", "2025-03-10")
+
+	got := Actuals(list, ts, start, end)
+	want := []models.BudgetActual{
+		{Month: "2025-01", Category: "Dining", Budget: 200, Actual: 120, Variance: 80, Percent: 60},
+		{Month: "2025-01", Category: "Groceries", Budget: 400, Actual: 600, Variance: -200, Percent: 150},
+		{Month: "2025-02", Category: "Dining", Budget: 200, Actual: 260, Variance: -60, Percent: 130},
+		{Month: "2025-02", Category: "Groceries", Budget: 400, Variance: 400},
+		{Month: "2025-03", Category: "Dining", Budget: 200, Actual: 40, Variance: 160, Percent: 20, Partial: true},
+		{Month: "2025-03", Category: "Groceries", Budget: 400, Variance: 400, Partial: true},
+	}
+	if len(got.Rows) != len(want) {
+		t.Fatalf("rows = %+v, want %d", got.Rows, len(want))
+	}
+	for i := range want {
+		if got.Rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, got.Rows[i], want[i])
+		}
+	}
+	if got.Start != "2025-01-01" || got.End != "2025-03-10" || got.TotalBudget != 1800 || got.TotalActual != 1020 {
+		t.Errorf("totals = %+v", got)
+	}
+
+	// A month the data only partly covers is partial even inside the range
+	late, _ := time.Parse("2006-01-02", "2025-03-31")
+	if rows := Actuals(list, ts, start, late).Rows; !rows[4].Partial || rows[4].Actual != 115 {
+		t.Errorf("March through its end = %+v, want partial with 115 spent", rows[4])
+	}
+	if rows := Actuals(nil, ts, start, end).Rows; rows == nil || len(rows) != 0 {
+		t.Errorf("no budgets = %#v, want an empty list", rows)
+	}
+}
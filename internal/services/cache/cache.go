@@ -0,0 +1,214 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// entry is a single cached value
+type entry struct {
+	value    interface{}
+	cachedAt time.Time
+	pinned   bool // Stored by Refresh: kept until replaced or the version changes
+}
+
+// call is a computation in progress that concurrent callers wait on
+type call struct {
+	done  chan struct{}
+	value interface{}
+	ok    bool // compute returned; false when it panicked
+}
+
+// maxRetired bounds how many replaced versions a cache remembers
+const maxRetired = 64
+
+// Cache is a TTL cache for expensive analysis results. Entries belong to a
+// version (e.g. the data version or a settings hash); when a caller presents
+// a different version the whole cache is dropped, so results computed from
+// old data are never served. A value computed under a version that has since
+// been replaced is discarded rather than stored, so a slow computation can't
+// wipe the entries of the version that replaced it.
+type Cache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	version  string
+	retired  map[string]bool // Versions replaced since; writes for them are dropped
+	entries  map[string]entry
+	inflight map[string]*call
+	now      func() time.Time
+
+	hits   int64
+	misses int64
+}
+
+// New creates a cache whose entries expire after ttl
+func New(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:      ttl,
+		retired:  make(map[string]bool),
+		entries:  make(map[string]entry),
+		inflight: make(map[string]*call),
+		now:      time.Now,
+	}
+}
+
+// Key joins parts into a cache key, e.g. Key("trends", start, end)
+func Key(parts ...interface{}) string {
+	strs := make([]string, len(parts))
+	for i, p := range parts {
+		switch v := p.(type) {
+		case time.Time:
+			strs[i] = v.Format("2006-01-02")
+		default:
+			strs[i] = fmt.Sprint(v)
+		}
+	}
+	return strings.Join(strs, "|")
+}
+
+// Get returns the value for key if it was cached under version and is fresh
+func (c *Cache) Get(version, key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checkVersion(version)
+	return c.lookup(key)
+}
+
+// Set stores value for key under version. It does nothing if version has
+// been replaced since it was current.
+func (c *Cache) Set(version, key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.acceptVersion(version) {
+		return
+	}
+	c.pruneExpired()
+	c.entries[key] = entry{value: value, cachedAt: c.now()}
+}
+
+// GetOrCompute returns the cached value for key or computes and stores it.
+// compute runs without the lock held so slow analyses don't block readers,
+// and concurrent callers asking for the same key wait for the first one's
+// result instead of repeating the work. If compute panics the panic goes to
+// its caller, nothing is cached, and the waiters compute the value
+// themselves rather than getting nil.
+func (c *Cache) GetOrCompute(version, key string, compute func() interface{}) interface{} {
+	c.mu.Lock()
+	c.checkVersion(version)
+	if v, ok := c.lookup(key); ok {
+		c.mu.Unlock()
+		return v
+	}
+	flightKey := version + "\x00" + key
+	if inProgress, ok := c.inflight[flightKey]; ok {
+		c.mu.Unlock()
+		<-inProgress.done
+		if !inProgress.ok {
+			return c.GetOrCompute(version, key, compute)
+		}
+		return inProgress.value
+	}
+	pending := &call{done: make(chan struct{})}
+	c.inflight[flightKey] = pending
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.inflight, flightKey)
+		c.mu.Unlock()
+		close(pending.done)
+	}()
+
+	pending.value = compute()
+	pending.ok = true
+	c.Set(version, key, pending.value)
+	return pending.value
+}
+
+// Refresh computes key's value and stores it without an expiry, replacing
+// any cached value. It's for background workers that recompute entries on
+// a schedule so requests in between never wait; a version change still
+// drops the entry, and a value computed under a replaced version isn't stored.
+func (c *Cache) Refresh(version, key string, compute func() interface{}) interface{} {
+	value := compute()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.acceptVersion(version) {
+		return value
+	}
+	c.pruneExpired()
+	c.entries[key] = entry{value: value, cachedAt: c.now(), pinned: true}
+	return value
+}
+
+// Invalidate drops every entry
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]entry)
+}
+
+// Stats returns hit/miss counts and the number of live entries
+func (c *Cache) Stats() (hits, misses int64, size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.hits, c.misses, len(c.entries)
+}
+
+// lookup returns a fresh entry and counts the hit or miss, dropping the
+// entry if it has expired (caller must hold lock)
+func (c *Cache) lookup(key string) (interface{}, bool) {
+	e, ok := c.entries[key]
+	if !ok || (!e.pinned && c.now().Sub(e.cachedAt) >= c.ttl) {
+		delete(c.entries, key)
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	return e.value, true
+}
+
+// checkVersion makes version current, clearing the cache when it changes.
+// Reads call it: a caller asking for a version has just computed it, so even
+// a version that was replaced earlier is current again (caller must hold lock).
+func (c *Cache) checkVersion(version string) {
+	if version == c.version {
+		return
+	}
+	if len(c.retired) >= maxRetired {
+		c.retired = make(map[string]bool)
+	}
+	c.retired[c.version] = true
+	delete(c.retired, version)
+	c.entries = make(map[string]entry)
+	c.version = version
+}
+
+// acceptVersion reports whether a value computed under version may be
+// stored, moving the cache to version if it's new. Values for a version that
+// was replaced while they were computed are dropped (caller must hold lock).
+func (c *Cache) acceptVersion(version string) bool {
+	if c.retired[version] {
+		return false
+	}
+	c.checkVersion(version)
+	return true
+}
+
+// pruneExpired removes stale entries (caller must hold lock)
+func (c *Cache) pruneExpired() {
+	now := c.now()
+	for k, e := range c.entries {
+		if !e.pinned && now.Sub(e.cachedAt) >= c.ttl {
+			delete(c.entries, k)
+		}
+	}
+}
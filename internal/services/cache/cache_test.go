@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrComputeCachesWithinVersion(t *testing.T) {
+	c := New(time.Minute)
+	calls := 0
+	compute := func() interface{} {
+		calls++
+		return calls
+	}
+
+	first := c.GetOrCompute("v1", "recurring", compute)
+	second := c.GetOrCompute("v1", "recurring", compute)
+	if first != 1 || second != 1 || calls != 1 {
+		t.Errorf("expected one computation, got calls=%d first=%v second=%v", calls, first, second)
+	}
+
+	hits, misses, size := c.Stats()
+	if hits != 1 || misses != 1 || size != 1 {
+		t.Errorf("Stats() = %d hits, %d misses, %d entries; want 1, 1, 1", hits, misses, size)
+	}
+}
+
+func TestGetOrComputeCoalescesConcurrentCalls(t *testing.T) {
+	c := New(time.Minute)
+	var calls int32
+	release := make(chan struct{})
+	compute := func() interface{} {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "chart"
+	}
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 6)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = c.GetOrCompute("v1", "monthly|2025-01-01|2025-06-30", compute)
+		}(i)
+	}
+
+	// Give every caller time to find the first computation in flight
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("compute ran %d times, want 1", calls)
+	}
+	for i, r := range results {
+		if r != "chart" {
+			t.Errorf("caller %d got %v", i, r)
+		}
+	}
+}
+
+func TestGetOrComputePanicReachesOnlyItsCaller(t *testing.T) {
+	c := New(time.Minute)
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	panicked := make(chan interface{})
+	go func() {
+		defer func() { panicked <- recover() }()
+		c.GetOrCompute("v1", "monthly", func() interface{} {
+			close(started)
+			<-release
+			panic("chart failed")
+		})
+	}()
+	<-started
+
+	waited := make(chan interface{})
+	go func() {
+		waited <- c.GetOrCompute("v1", "monthly", func() interface{} { return "chart" })
+	}()
+
+	// Give the waiter time to find the first computation in flight
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	if p := <-panicked; p != "chart failed" {
+		t.Errorf("computing caller recovered %v, want the panic", p)
+	}
+	if v := <-waited; v != "chart" {
+		t.Errorf("waiter got %v, want its own computation's result", v)
+	}
+	if v, ok := c.Get("v1", "monthly"); !ok || v != "chart" {
+		t.Errorf("cached %v %v, want the waiter's result", v, ok)
+	}
+}
+
+func TestVersionChangeInvalidates(t *testing.T) {
+	c := New(time.Minute)
+	c.Set("v1", "trends", "old")
+	c.Set("v1", "income", "old")
+
+	if _, ok := c.Get("v2", "trends"); ok {
+		t.Error("entry from previous data version should not be returned")
+	}
+	if _, _, size := c.Stats(); size != 0 {
+		t.Errorf("version change should drop all entries, have %d", size)
+	}
+}
+
+func TestStaleSetKeepsCurrentVersion(t *testing.T) {
+	c := New(time.Minute)
+	c.Set("v1", "trends", "old")
+	c.Set("v2", "trends", "new")
+
+	c.Set("v1", "income", "old")
+	if v, ok := c.Get("v2", "trends"); !ok || v != "new" {
+		t.Errorf("write for a replaced version dropped the current entries, got %v %v", v, ok)
+	}
+	if _, _, size := c.Stats(); size != 1 {
+		t.Errorf("write for a replaced version should not be stored, have %d entries", size)
+	}
+
+	// Asking for an earlier version again makes it current
+	if _, ok := c.Get("v1", "trends"); ok {
+		t.Error("entries should not survive returning to an earlier version")
+	}
+	c.Set("v1", "income", "again")
+	if v, ok := c.Get("v1", "income"); !ok || v != "again" {
+		t.Errorf("expected write for the current version to be stored, got %v %v", v, ok)
+	}
+}
+
+func TestSlowComputeAfterVersionChange(t *testing.T) {
+	c := New(time.Minute)
+
+	// The data changes and a newer request caches its result while the
+	// first computation is still running
+	c.GetOrCompute("v1", "recurring", func() interface{} {
+		c.GetOrCompute("v2", "recurring", func() interface{} { return "new" })
+		return "old"
+	})
+
+	if v, ok := c.Get("v2", "recurring"); !ok || v != "new" {
+		t.Errorf("slow computation replaced the newer entry, got %v %v", v, ok)
+	}
+
+	c.Refresh("v1", "trends", func() interface{} { return "old" })
+	if v, ok := c.Get("v2", "recurring"); !ok || v != "new" {
+		t.Errorf("refresh for a replaced version dropped the newer entry, got %v %v", v, ok)
+	}
+}
+
+func TestEntriesExpire(t *testing.T) {
+	c := New(time.Minute)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.Set("v1", "velocity", 42)
+	if v, ok := c.Get("v1", "velocity"); !ok || v != 42 {
+		t.Fatalf("expected fresh entry, got %v %v", v, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get("v1", "velocity"); ok {
+		t.Error("entry should have expired")
+	}
+	if _, _, size := c.Stats(); size != 0 {
+		t.Errorf("expired entry should be dropped, have %d entries", size)
+	}
+}
+
+func TestRefreshedEntriesDontExpire(t *testing.T) {
+	c := New(time.Minute)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.Set("v1", "velocity", 41)
+	if v := c.Refresh("v1", "velocity", func() interface{} { return 42 }); v != 42 {
+		t.Fatalf("Refresh returned %v, want 42", v)
+	}
+
+	now = now.Add(time.Hour)
+	c.Set("v1", "other", 1) // prunes expired entries
+	if v, ok := c.Get("v1", "velocity"); !ok || v != 42 {
+		t.Errorf("refreshed entry = %v %v, want 42 after the TTL", v, ok)
+	}
+	if _, ok := c.Get("v2", "velocity"); ok {
+		t.Error("refreshed entry should be dropped on a version change")
+	}
+}
+
+func TestKey(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	if got := Key("trends", start, end); got != "trends|2025-01-01|2025-06-30" {
+		t.Errorf("Key() = %q", got)
+	}
+}
@@ -0,0 +1,319 @@
+package categories
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// stylesFile holds the category colors and icons
+var stylesFile = storage.SettingsFile("category_styles.json", func() interface{} { return &[]models.CategoryStyle{} })
+
+// Uncategorized is the display name for transactions without a category
+const Uncategorized = "Uncategorized"
+
+// Neutral colors for buckets that aren't real categories
+const (
+	UncategorizedColor = "#9ca3af"
+	OtherColor         = "#d1d5db"
+)
+
+// DefaultIcon is used when no keyword suggests a better one
+const DefaultIcon = "🏷️"
+
+// Palette is the sequence auto-assigned colors are drawn from. The colors are
+// distinct from each other and readable in both light and dark themes.
+var Palette = []string{
+	"#6366f1", "#22c55e", "#f59e0b", "#ef4444", "#06b6d4", "#8b5cf6",
+	"#ec4899", "#14b8a6", "#f97316", "#84cc16", "#3b82f6", "#a855f7",
+	"#eab308", "#10b981", "#e11d48", "#0ea5e9",
+}
+
+// iconKeywords suggests an icon from words in the category name
+var iconKeywords = []struct {
+	icon     string
+	keywords []string
+}{
+	{"🛒", []string{"grocer", "supermarket"}},
+	{"🍽️", []string{"restaurant", "dining", "food"}},
+	{"☕", []string{"coffee"}},
+	{"⛽", []string{"gas", "fuel"}},
+	{"🚗", []string{"auto", "car ", "vehicle", "parking", "rideshare", "transport"}},
+	{"🏠", []string{"rent", "mortgage", "housing", "home"}},
+	{"💡", []string{"utilit", "electric", "water", "internet", "phone"}},
+	{"🎬", []string{"entertainment", "movie", "streaming", "music"}},
+	{"🛍️", []string{"shopping", "clothing", "apparel"}},
+	{"💊", []string{"health", "medical", "pharmacy", "doctor", "dental"}},
+	{"✈️", []string{"travel", "hotel", "airline"}},
+	{"💵", []string{"paycheck", "salary", "income", "payroll", "deposit"}},
+	{"🛡️", []string{"insurance"}},
+	{"📚", []string{"education", "tuition", "books", "school"}},
+	{"🎁", []string{"gift", "charit", "donation"}},
+	{"🐾", []string{"pet"}},
+}
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Registry persists category styles. Colors are assigned when a category
+// first shows up in loaded data (see Assign) and saved, so they stay stable
+// as new categories appear; users can override any of them.
+type Registry struct {
+	path   string
+	store  *storage.Storage
+	mu     sync.Mutex
+	styles map[string]models.CategoryStyle // keyed by lowercased name; nil until loaded
+}
+
+// NewRegistry creates a registry storing styles in settingsDir
+func NewRegistry(settingsDir string, store *storage.Storage) *Registry {
+	return &Registry{
+		path:  filepath.Join(settingsDir, stylesFile),
+		store: store,
+	}
+}
+
+// Style returns the saved style for category, or one derived from its name
+// for a category not assigned yet. It never writes, so charts and templates
+// can call it freely.
+func (r *Registry) Style(category string) models.CategoryStyle {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.loadInternal(); err != nil {
+		log.Printf("Warning: failed to load category styles: %v", err)
+		return autoStyle(category)
+	}
+	if style, ok := r.styles[key(category)]; ok {
+		return style
+	}
+	return autoStyle(category)
+}
+
+// Assign makes sure every category has a style, saving once for the batch
+func (r *Registry) Assign(categories []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.loadInternal(); err != nil {
+		return err
+	}
+	changed := false
+	for _, c := range categories {
+		if _, added := r.ensureInternal(c); added {
+			changed = true
+		}
+	}
+	if changed {
+		return r.saveInternal()
+	}
+	return nil
+}
+
+// List returns every known style sorted by category name
+func (r *Registry) List() ([]models.CategoryStyle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.loadInternal(); err != nil {
+		return nil, err
+	}
+	list := make([]models.CategoryStyle, 0, len(r.styles))
+	for _, s := range r.styles {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Category) < strings.ToLower(list[j].Category)
+	})
+	return list, nil
+}
+
+// Set overrides the color and icon for a category. A blank icon keeps the
+// current one.
+func (r *Registry) Set(category, color, icon string) (models.CategoryStyle, error) {
+	if !hexColor.MatchString(color) {
+		return models.CategoryStyle{}, fmt.Errorf("color must be a hex value like #22c55e, got %q", color)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.loadInternal(); err != nil {
+		return models.CategoryStyle{}, err
+	}
+	style, _ := r.ensureInternal(category)
+	style.Color = strings.ToLower(color)
+	if icon = strings.TrimSpace(icon); icon != "" {
+		style.Icon = icon
+	}
+	style.Custom = true
+	r.styles[key(category)] = style
+
+	if err := r.saveInternal(); err != nil {
+		return models.CategoryStyle{}, err
+	}
+	return style, nil
+}
+
+// Reset drops a user override, giving the category a fresh automatic style
+func (r *Registry) Reset(category string) (models.CategoryStyle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.loadInternal(); err != nil {
+		return models.CategoryStyle{}, err
+	}
+	delete(r.styles, key(category))
+	style, _ := r.ensureInternal(category)
+	if err := r.saveInternal(); err != nil {
+		return models.CategoryStyle{}, err
+	}
+	return style, nil
+}
+
+// ensureInternal returns the style for category, adding an automatic one if
+// missing (caller must hold lock and have loaded styles)
+func (r *Registry) ensureInternal(category string) (models.CategoryStyle, bool) {
+	k := key(category)
+	if style, ok := r.styles[k]; ok {
+		return style, false
+	}
+
+	// Prefer a palette color no other category has yet; once the palette
+	// is used up the hash-derived color stands
+	style := autoStyle(category)
+	if style.Category != Uncategorized {
+		used := make(map[string]bool, len(r.styles))
+		for _, s := range r.styles {
+			used[s.Color] = true
+		}
+		for _, c := range Palette {
+			if !used[c] {
+				style.Color = c
+				break
+			}
+		}
+	}
+	r.styles[k] = style
+	return style, true
+}
+
+// loadInternal reads styles once (caller must hold lock)
+func (r *Registry) loadInternal() error {
+	if r.styles != nil {
+		return nil
+	}
+
+	var list []models.CategoryStyle
+	if err := r.store.ReadJSON(r.path, &list); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	r.styles = make(map[string]models.CategoryStyle, len(list))
+	for _, s := range list {
+		r.styles[key(s.Category)] = s
+	}
+	return nil
+}
+
+// saveInternal writes styles (caller must hold lock)
+func (r *Registry) saveInternal() error {
+	list := make([]models.CategoryStyle, 0, len(r.styles))
+	for _, s := range r.styles {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Category) < strings.ToLower(list[j].Category)
+	})
+	if err := r.store.WriteJSON(r.path, list); err != nil {
+		log.Printf("Warning: failed to save category styles: %v", err)
+		return err
+	}
+	return nil
+}
+
+// key normalizes a category name for lookups
+func key(category string) string {
+	category = strings.TrimSpace(category)
+	if category == "" {
+		category = Uncategorized
+	}
+	return strings.ToLower(category)
+}
+
+// autoStyle derives a style from the name alone: a palette color picked by
+// hash and an icon suggested by keywords
+func autoStyle(category string) models.CategoryStyle {
+	name := strings.TrimSpace(category)
+	if name == "" || strings.EqualFold(name, Uncategorized) {
+		return models.CategoryStyle{Category: Uncategorized, Color: UncategorizedColor, Icon: "❔"}
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(name)))
+	return models.CategoryStyle{
+		Category: name,
+		Color:    Palette[h.Sum32()%uint32(len(Palette))],
+		Icon:     suggestIcon(name),
+	}
+}
+
+// suggestIcon picks an icon from keywords in the category name
+func suggestIcon(category string) string {
+	lower := strings.ToLower(category) + " "
+	for _, k := range iconKeywords {
+		for _, kw := range k.keywords {
+			if strings.Contains(lower, kw) {
+				return k.icon
+			}
+		}
+	}
+	return DefaultIcon
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultRegistry *Registry
+)
+
+// SetDefault installs the registry used by Color and Icon. Chart builders and
+// templates go through these so every view agrees on a category's style.
+func SetDefault(r *Registry) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	defaultRegistry = r
+}
+
+// Lookup returns the style for category from the default registry, or a
+// hash-derived style when no registry is installed (e.g. in tests)
+func Lookup(category string) models.CategoryStyle {
+	defaultMu.RLock()
+	r := defaultRegistry
+	defaultMu.RUnlock()
+
+	if r == nil {
+		return autoStyle(category)
+	}
+	return r.Style(category)
+}
+
+// Color returns the display color for category
+func Color(category string) string {
+	if strings.EqualFold(strings.TrimSpace(category), "Other") {
+		return OtherColor
+	}
+	return Lookup(category).Color
+}
+
+// Icon returns the display icon for category
+func Icon(category string) string {
+	return Lookup(category).Icon
+}
@@ -0,0 +1,101 @@
+package categories
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"budget2/internal/services/storage"
+)
+
+func newTestRegistry(t *testing.T) (*Registry, string, *storage.Storage) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	return NewRegistry(dir, store), dir, store
+}
+
+func TestStyleIsStableAndPersisted(t *testing.T) {
+	registry, dir, store := newTestRegistry(t)
+
+	// Looking a style up doesn't save it; assigning does
+	registry.Style("Groceries")
+	if _, err := os.Stat(filepath.Join(dir, "category_styles.json")); !os.IsNotExist(err) {
+		t.Errorf("Style should not write the styles file, stat = %v", err)
+	}
+	if err := registry.Assign([]string{"Groceries", "Dining"}); err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+
+	groceries := registry.Style("Groceries")
+	dining := registry.Style("Dining")
+	if groceries.Color == dining.Color {
+		t.Errorf("first categories should get distinct palette colors, both got %s", groceries.Color)
+	}
+	if groceries.Icon != "🛒" {
+		t.Errorf("Groceries icon = %q, want 🛒", groceries.Icon)
+	}
+	if again := registry.Style("groceries"); again.Color != groceries.Color {
+		t.Errorf("lookup should ignore case, got %s then %s", groceries.Color, again.Color)
+	}
+
+	// A fresh registry over the same file sees the saved assignments
+	reloaded := NewRegistry(dir, store)
+	if got := reloaded.Style("Dining").Color; got != dining.Color {
+		t.Errorf("Dining color after reload = %s, want %s", got, dining.Color)
+	}
+}
+
+func TestUncategorizedIsNeutral(t *testing.T) {
+	registry, _, _ := newTestRegistry(t)
+
+	if got := registry.Style("").Color; got != UncategorizedColor {
+		t.Errorf("blank category color = %s, want %s", got, UncategorizedColor)
+	}
+	if got := Color("Other"); got != OtherColor {
+		t.Errorf("Other color = %s, want %s", got, OtherColor)
+	}
+}
+
+func TestSetAndReset(t *testing.T) {
+	registry, _, _ := newTestRegistry(t)
+	auto := registry.Style("Coffee Shops")
+
+	if _, err := registry.Set("Coffee Shops", "brown", ""); err == nil {
+		t.Error("expected error for non-hex color")
+	}
+
+	style, err := registry.Set("coffee shops", "#7C2D12", "🥐")
+	if err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if style.Color != "#7c2d12" || style.Icon != "🥐" || !style.Custom {
+		t.Errorf("Set returned %+v", style)
+	}
+	if got := registry.Style("Coffee Shops"); got.Color != "#7c2d12" {
+		t.Errorf("override not applied, got %s", got.Color)
+	}
+
+	style, err = registry.Reset("Coffee Shops")
+	if err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if style.Custom || style.Icon != auto.Icon {
+		t.Errorf("Reset should restore an automatic style, got %+v", style)
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	SetDefault(nil)
+	if Color("Groceries") != autoStyle("Groceries").Color {
+		t.Error("without a registry colors should come from the name hash")
+	}
+
+	registry, _, _ := newTestRegistry(t)
+	custom, _ := registry.Set("Groceries", "#123456", "")
+	SetDefault(registry)
+	defer SetDefault(nil)
+
+	if got := Color("Groceries"); got != custom.Color {
+		t.Errorf("Color() = %s, want the registry's %s", got, custom.Color)
+	}
+}
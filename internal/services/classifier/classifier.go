@@ -42,6 +42,12 @@ var NeverIncomeKeywords = []string{
 	"usaa credit card payment", "recurring scheduled payment",
 }
 
+// Investment income keywords (lowercase); other credits to an investment
+// account are contributions or sales proceeds
+var InvestmentIncomeKeywords = []string{
+	"dividend", "interest", "capital gain", "cap gain",
+}
+
 // Internal transfer patterns to filter (lowercase)
 var InternalTransferPatterns = []string{
 	"usaa funds transfer",
@@ -53,6 +59,25 @@ var InternalTransferPatterns = []string{
 	"recurring scheduled payment",
 }
 
+// Interest charged on balances (lowercase)
+var InterestChargeKeywords = []string{
+	"interest charge", "finance charge", "purchase interest",
+	"cash advance interest", "interest charged", "interest on purchases",
+}
+
+// Late and returned payment fees (lowercase)
+var LateFeeKeywords = []string{
+	"late fee", "late payment fee", "late charge", "returned payment fee",
+}
+
+// Bank and card fees (lowercase)
+var BankFeeKeywords = []string{
+	"overdraft", "nsf fee", "insufficient funds", "atm fee",
+	"service fee", "service charge", "maintenance fee", "monthly fee",
+	"annual fee", "foreign transaction fee", "conversion fee",
+	"wire fee", "cash advance fee", "balance transfer fee", "bank fee",
+}
+
 // ClassifyTransactions classifies each transaction as Income or Outflow
 func ClassifyTransactions(transactions []models.Transaction) []models.Transaction {
 	for i := range transactions {
@@ -84,6 +109,17 @@ func classifyTransaction(t *models.Transaction) models.TransactionType {
 		}
 	}
 
+	switch t.AccountType {
+	case models.AccountCreditCard:
+		// Card credits are refunds or rewards, never income
+		return models.Outflow
+	case models.AccountInvestment:
+		if t.Amount > 0 && (containsAny(descLower, InvestmentIncomeKeywords) || containsAny(catLower, InvestmentIncomeKeywords)) {
+			return models.Income
+		}
+		return models.Outflow
+	}
+
 	// For positive amounts, check if it looks like income
 	if t.Amount > 0 {
 		// Check income categories (exact match or contains)
@@ -148,6 +184,28 @@ func IsPotentialIncome(t *models.Transaction) bool {
 	return containsAny(descLower, IncomeKeywords)
 }
 
+// FeeKind reports whether an outflow is an interest charge, late fee or bank
+// fee, returning one of the models.Fee* kinds or "" for anything else.
+// Credits such as interest earned or fee reversals are never fees.
+func FeeKind(t *models.Transaction) string {
+	if t.TransactionType == models.Income || t.Amount >= 0 {
+		return ""
+	}
+
+	descLower := strings.ToLower(strings.TrimSpace(t.Description))
+	catLower := strings.ToLower(strings.TrimSpace(t.Category))
+
+	switch {
+	case containsAny(descLower, LateFeeKeywords):
+		return models.FeeLate
+	case containsAny(descLower, InterestChargeKeywords), strings.Contains(catLower, "interest"):
+		return models.FeeInterest
+	case containsAny(descLower, BankFeeKeywords), strings.Contains(catLower, "fee"):
+		return models.FeeBank
+	}
+	return ""
+}
+
 // containsAny checks if text contains any of the keywords
 func containsAny(text string, keywords []string) bool {
 	for _, kw := range keywords {
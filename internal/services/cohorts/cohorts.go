@@ -0,0 +1,166 @@
+package cohorts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// cohortsFile holds the saved cohorts
+var cohortsFile = storage.SettingsFile("cohorts.json", func() interface{} { return &[]models.Cohort{} })
+
+// Manager persists the user's cohorts: saved filters that insights can be
+// scoped to
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing cohorts in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, cohortsFile),
+		store: store,
+	}
+}
+
+// List returns all cohorts sorted by name
+func (m *Manager) List() ([]models.Cohort, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Get returns the cohort with the given ID, or nil if there is none
+func (m *Manager) Get(id string) (*models.Cohort, error) {
+	list, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].ID == id {
+			return &list[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Add saves a cohort, replacing any earlier one with the same name. Its ID
+// comes from the name, and its filter must parse.
+func (m *Manager) Add(c models.Cohort) ([]models.Cohort, error) {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Filter = strings.TrimSpace(c.Filter)
+	c.ID = slug(c.Name)
+	if c.ID == "" {
+		return nil, fmt.Errorf("cohort name needs a letter or digit")
+	}
+	if _, err := Parse(c.Filter); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.Cohort, 0, len(list)+1)
+	for _, existing := range list {
+		if existing.ID != c.ID {
+			filtered = append(filtered, existing)
+		}
+	}
+	filtered = append(filtered, c)
+	sort.Slice(filtered, func(i, j int) bool {
+		return strings.ToLower(filtered[i].Name) < strings.ToLower(filtered[j].Name)
+	})
+
+	if err := m.store.WriteJSON(m.path, filtered); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// Remove deletes a cohort by ID
+func (m *Manager) Remove(id string) ([]models.Cohort, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.Cohort, 0, len(list))
+	for _, c := range list {
+		if c.ID != id {
+			filtered = append(filtered, c)
+		}
+	}
+
+	if err := m.store.WriteJSON(m.path, filtered); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// Version changes whenever the cohorts do, so results cached for a cohort
+// are recomputed after its filter changes
+func (m *Manager) Version() string {
+	info, err := m.store.Stat(m.path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("cohorts|%d|%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// Statuses counts the transactions in ts each cohort matches
+func Statuses(list []models.Cohort, ts *models.TransactionSet) []models.CohortStatus {
+	statuses := make([]models.CohortStatus, 0, len(list))
+	for _, c := range list {
+		status := models.CohortStatus{Cohort: c}
+		if f, err := Parse(c.Filter); err == nil {
+			status.Matches = f.Apply(ts).Len()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// slug turns a name into an ID for URLs, e.g. "Kids & School" into
+// "kids-school"
+func slug(name string) string {
+	s := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, name)
+	for strings.Contains(s, "--") {
+		s = strings.ReplaceAll(s, "--", "-")
+	}
+	return strings.Trim(s, "-")
+}
+
+// loadInternal reads the cohorts without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() ([]models.Cohort, error) {
+	var list []models.Cohort
+	if err := m.store.ReadJSON(m.path, &list); err != nil {
+		if os.IsNotExist(err) {
+			return []models.Cohort{}, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,95 @@
+package cohorts
+
+import (
+	"strings"
+	"testing"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func TestParseAndMatch(t *testing.T) {
+	txns := []models.Transaction{
+		{Description: "KINDERCARE", Category: "Childcare", TransactionType: models.Outflow, Account: "Checking"},
+		{Description: "TARGET", Category: "Shopping", TransactionType: models.Outflow, Tags: []string{"kids"}},
+		{Description: "TARGET REFUND", Category: "Shopping", TransactionType: models.Income, Tags: []string{"kids"}},
+		{Description: "CHIPOTLE", Category: "Eating Out", TransactionType: models.Outflow, Account: "Visa"},
+	}
+
+	for _, tc := range []struct {
+		expr string
+		want []string
+	}{
+		{"tag:kids OR category:Childcare", []string{"KINDERCARE", "TARGET", "TARGET REFUND"}},
+		{"tag:kids -text:refund", []string{"TARGET"}},
+		{"tag:kids AND type:income", []string{"TARGET REFUND"}},
+		{`category:"eating out"`, []string{"CHIPOTLE"}},
+		{"target", []string{"TARGET", "TARGET REFUND"}},
+		{"account:visa OR account:checking", []string{"KINDERCARE", "CHIPOTLE"}},
+		{"category:Child", nil}, // Whole values only
+	} {
+		f, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.expr, err)
+		}
+		var got []string
+		for i := range txns {
+			if f.Match(&txns[i]) {
+				got = append(got, txns[i].Description)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%q matched %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+
+	for _, expr := range []string{"", "OR tag:kids", "tag:kids OR", "color:red", "tag:", "type:transfer"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) should fail", expr)
+		}
+	}
+}
+
+func TestManager(t *testing.T) {
+	store, _ := storage.NewMemory("/data")
+	manager := NewManager("/data/settings", store)
+
+	if _, err := manager.Add(models.Cohort{Name: "Kids", Filter: "color:red"}); err == nil {
+		t.Error("expected error for an invalid filter")
+	}
+	if _, err := manager.Add(models.Cohort{Name: "!!", Filter: "tag:kids"}); err == nil {
+		t.Error("expected error for a name without letters")
+	}
+
+	manager.Add(models.Cohort{Name: "Kids & School", Filter: "tag:kids"})
+	manager.Add(models.Cohort{Name: "Dining", Filter: `category:"Eating Out"`})
+	list, err := manager.Add(models.Cohort{Name: "kids & school", Filter: "tag:kids OR category:Childcare"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "Dining" || list[1].ID != "kids-school" || list[1].Filter != "tag:kids OR category:Childcare" {
+		t.Errorf("same name should replace the cohort and the list be sorted, got %+v", list)
+	}
+
+	if c, _ := manager.Get("kids-school"); c == nil || c.Name != "kids & school" {
+		t.Errorf("Get(kids-school) = %+v", c)
+	}
+	if c, _ := manager.Get("nope"); c != nil {
+		t.Errorf("Get(nope) = %+v, want nil", c)
+	}
+
+	ts := models.NewTransactionSet([]models.Transaction{
+		{Description: "KINDERCARE", Category: "Childcare"},
+		{Description: "CHIPOTLE", Category: "Eating Out"},
+		{Description: "PANERA", Category: "Eating Out"},
+	})
+	statuses := Statuses(list, ts)
+	if statuses[0].Matches != 2 || statuses[1].Matches != 1 {
+		t.Errorf("statuses = %+v, want 2 dining and 1 kids", statuses)
+	}
+
+	list, _ = manager.Remove("dining")
+	if len(list) != 1 {
+		t.Errorf("Remove left %d cohorts, want 1", len(list))
+	}
+}
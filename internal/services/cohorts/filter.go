@@ -0,0 +1,164 @@
+package cohorts
+
+import (
+	"fmt"
+	"strings"
+
+	"budget2/internal/models"
+)
+
+// FilterFields are the fields a filter term can name, e.g. tag:kids
+var FilterFields = []string{"tag", "category", "account", "file", "type", "text"}
+
+// term is one condition of a filter: a field's value, or its opposite
+type term struct {
+	field  string
+	value  string // Lowercased
+	negate bool
+}
+
+// Filter is a parsed cohort filter. Terms side by side must all match and
+// OR separates alternatives, so "tag:kids OR category:Childcare -text:refund"
+// matches transactions tagged kids, or Childcare transactions that aren't
+// refunds.
+type Filter struct {
+	groups [][]term
+}
+
+// Parse reads a cohort filter. Each term is field:value for one of
+// FilterFields, or a bare word matching the description; a leading "-"
+// negates it. Values with spaces go in quotes, as in category:"Eating Out".
+// Fields other than text match the whole value, ignoring case.
+func Parse(expr string) (*Filter, error) {
+	f := &Filter{}
+	var group []term
+	for _, token := range tokenize(expr) {
+		switch token {
+		case "OR":
+			if len(group) == 0 {
+				return nil, fmt.Errorf("OR needs a term on each side")
+			}
+			f.groups = append(f.groups, group)
+			group = nil
+			continue
+		case "AND":
+			continue
+		}
+
+		t, err := parseTerm(token)
+		if err != nil {
+			return nil, err
+		}
+		group = append(group, t)
+	}
+	if len(group) == 0 {
+		if len(f.groups) > 0 {
+			return nil, fmt.Errorf("OR needs a term on each side")
+		}
+		return nil, fmt.Errorf("filter is empty")
+	}
+	f.groups = append(f.groups, group)
+	return f, nil
+}
+
+// parseTerm reads one term, e.g. -category:"Eating Out"
+func parseTerm(token string) (term, error) {
+	t := term{field: "text"}
+	if strings.HasPrefix(token, "-") && len(token) > 1 {
+		t.negate = true
+		token = token[1:]
+	}
+
+	value := token
+	if field, rest, ok := strings.Cut(token, ":"); ok && !strings.HasPrefix(field, `"`) {
+		t.field = strings.ToLower(field)
+		value = rest
+		known := false
+		for _, f := range FilterFields {
+			known = known || f == t.field
+		}
+		if !known {
+			return term{}, fmt.Errorf("unknown field %q; use one of %s", field, strings.Join(FilterFields, ", "))
+		}
+	}
+	t.value = strings.ToLower(strings.Trim(value, `"`))
+	if t.value == "" {
+		return term{}, fmt.Errorf("%s: needs a value", t.field)
+	}
+	if t.field == "type" && t.value != "income" && t.value != "outflow" {
+		return term{}, fmt.Errorf("type must be income or outflow, got %q", value)
+	}
+	return t, nil
+}
+
+// tokenize splits expr on spaces outside double quotes
+func tokenize(expr string) []string {
+	var tokens []string
+	var b strings.Builder
+	quoted := false
+	for _, r := range expr {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			b.WriteRune(r)
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if b.Len() > 0 {
+				tokens = append(tokens, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() > 0 {
+		tokens = append(tokens, b.String())
+	}
+	return tokens
+}
+
+// Match reports whether the transaction belongs to the cohort
+func (f *Filter) Match(t *models.Transaction) bool {
+	for _, group := range f.groups {
+		all := true
+		for _, term := range group {
+			if term.match(t) == term.negate {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+// match reports whether the transaction has the term's value, ignoring
+// negation
+func (term term) match(t *models.Transaction) bool {
+	switch term.field {
+	case "tag":
+		return t.HasTag(term.value)
+	case "category":
+		return strings.ToLower(t.Category) == term.value
+	case "account":
+		return strings.ToLower(t.Account) == term.value
+	case "file":
+		return strings.ToLower(t.SourceFile) == term.value
+	case "type":
+		return strings.ToLower(string(t.TransactionType)) == term.value
+	default:
+		return strings.Contains(strings.ToLower(t.Description), term.value)
+	}
+}
+
+// Apply returns the transactions in ts that belong to the cohort
+func (f *Filter) Apply(ts *models.TransactionSet) *models.TransactionSet {
+	result := &models.TransactionSet{}
+	for i := range ts.Transactions {
+		if f.Match(&ts.Transactions[i]) {
+			result.Transactions = append(result.Transactions, ts.Transactions[i])
+		}
+	}
+	return result
+}
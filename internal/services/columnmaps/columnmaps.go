@@ -0,0 +1,184 @@
+package columnmaps
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// mappingsFile holds the CSV column mappings
+var mappingsFile = storage.SettingsFile("column_mappings.json", func() interface{} { return &[]models.ColumnMapping{} })
+
+// Manager persists the column mappings the user set up for CSV layouts the
+// loader doesn't recognize
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing column mappings in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, mappingsFile),
+		store: store,
+	}
+}
+
+// List returns all mappings sorted by pattern
+func (m *Manager) List() ([]models.ColumnMapping, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Set saves mapping, replacing any earlier one with the same pattern. It
+// needs a Date and a Description column, and either an Amount column or
+// Debit/Credit columns, each a different column.
+func (m *Manager) Set(mapping models.ColumnMapping) error {
+	mapping.Pattern = strings.TrimSpace(mapping.Pattern)
+	if mapping.Pattern == "" {
+		return fmt.Errorf("file pattern is required")
+	}
+	if _, err := filepath.Match(mapping.Pattern, ""); err != nil {
+		return fmt.Errorf("invalid file pattern %q", mapping.Pattern)
+	}
+
+	columns := make(map[string]string)
+	used := make(map[string]string)
+	for field, header := range mapping.Columns {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+		if !slices.Contains(models.MappableColumns, field) {
+			return fmt.Errorf("unknown field %q", field)
+		}
+		if other, ok := used[header]; ok {
+			return fmt.Errorf("column %q can't be both %s and %s", header, other, field)
+		}
+		used[header] = field
+		columns[field] = header
+	}
+	if err := validate(columns); err != nil {
+		return err
+	}
+	mapping.Columns = columns
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return err
+	}
+	list = slices.DeleteFunc(list, func(c models.ColumnMapping) bool {
+		return c.Pattern == mapping.Pattern
+	})
+	list = append(list, mapping)
+	sort.Slice(list, func(i, j int) bool { return list[i].Pattern < list[j].Pattern })
+	return m.store.WriteJSON(m.path, list)
+}
+
+// validate checks a mapping assigns every field the loader needs
+func validate(columns map[string]string) error {
+	for _, field := range []string{"Date", "Description"} {
+		if columns[field] == "" {
+			return fmt.Errorf("choose the %s column", field)
+		}
+	}
+	hasDebitCredit := columns["Debit"] != "" || columns["Credit"] != ""
+	switch {
+	case columns["Amount"] == "" && !hasDebitCredit:
+		return fmt.Errorf("choose the Amount column, or Debit and Credit columns")
+	case columns["Amount"] != "" && hasDebitCredit:
+		return fmt.Errorf("choose either an Amount column or Debit and Credit columns, not both")
+	}
+	return nil
+}
+
+// Remove deletes the mapping with pattern
+func (m *Manager) Remove(pattern string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return err
+	}
+	filtered := slices.DeleteFunc(list, func(c models.ColumnMapping) bool {
+		return c.Pattern == pattern
+	})
+	if len(filtered) == len(list) {
+		return nil
+	}
+	return m.store.WriteJSON(m.path, filtered)
+}
+
+// Mapping returns the mapping for file, or nil. A pattern naming the file
+// exactly wins over globs, and otherwise the first matching glob does.
+func (m *Manager) Mapping(file string) *models.ColumnMapping {
+	list, err := m.List()
+	if err != nil {
+		log.Printf("Warning: failed to load column mappings: %v", err)
+		return nil
+	}
+	return Match(list, file)
+}
+
+// Match returns the mapping in list for file, as Mapping chooses it
+func Match(list []models.ColumnMapping, file string) *models.ColumnMapping {
+	var found *models.ColumnMapping
+	for i := range list {
+		if list[i].Pattern == file {
+			return &list[i]
+		}
+		if ok, _ := filepath.Match(list[i].Pattern, file); ok && found == nil {
+			found = &list[i]
+		}
+	}
+	return found
+}
+
+// Version changes whenever the mappings do, so the loader reloads data
+func (m *Manager) Version() string {
+	info, err := m.store.Stat(m.path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("columns|%d|%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// datePart matches the digits exports add to file names, such as dates and
+// statement numbers
+var datePart = regexp.MustCompile(`[0-9]+([-_. ][0-9]+)*`)
+
+// SuggestPattern proposes a pattern matching file and the later exports
+// named like it, replacing its numbers with wildcards: "chase_2024-01.csv"
+// becomes "chase_*.csv"
+func SuggestPattern(file string) string {
+	ext := filepath.Ext(file)
+	return datePart.ReplaceAllString(strings.TrimSuffix(file, ext), "*") + ext
+}
+
+// loadInternal reads the mappings without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() ([]models.ColumnMapping, error) {
+	var list []models.ColumnMapping
+	if err := m.store.ReadJSON(m.path, &list); err != nil {
+		if os.IsNotExist(err) {
+			return []models.ColumnMapping{}, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,81 @@
+package columnmaps
+
+import (
+	"testing"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func TestSetMapping(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	if v := manager.Version(); v != "" {
+		t.Errorf("version without mappings = %q, want empty", v)
+	}
+
+	err := manager.Set(models.ColumnMapping{Pattern: "chase_*.csv", Columns: map[string]string{
+		"Date": "Posting Dt", "Description": "Memo Line", "Debit": "Out", "Credit": "In", "Amount": " ",
+	}})
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	mapping := manager.Mapping("chase_2024-01.csv")
+	if mapping == nil || mapping.Columns["Date"] != "Posting Dt" || mapping.Columns["Credit"] != "In" {
+		t.Fatalf("mapping = %+v, want the chase mapping", mapping)
+	}
+	if _, ok := mapping.Columns["Amount"]; ok {
+		t.Error("blank columns should be dropped")
+	}
+	if manager.Mapping("checking.csv") != nil {
+		t.Error("unmatched file should have no mapping")
+	}
+
+	// A pattern naming the file wins over a glob
+	manager.Set(models.ColumnMapping{Pattern: "chase_old.csv", Columns: map[string]string{
+		"Date": "When", "Description": "What", "Amount": "How Much",
+	}})
+	if mapping := manager.Mapping("chase_old.csv"); mapping == nil || mapping.Pattern != "chase_old.csv" {
+		t.Errorf("mapping = %+v, want the exact one", mapping)
+	}
+	v1 := manager.Version()
+
+	manager.Remove("chase_old.csv")
+	if mapping := manager.Mapping("chase_old.csv"); mapping == nil || mapping.Pattern != "chase_*.csv" {
+		t.Errorf("after removing, mapping = %+v, want the glob", mapping)
+	}
+	if manager.Version() == v1 {
+		t.Error("version should change when mappings do")
+	}
+
+	for name, bad := range map[string]models.ColumnMapping{
+		"no pattern":     {Columns: map[string]string{"Date": "a", "Description": "b", "Amount": "c"}},
+		"bad pattern":    {Pattern: "[x.csv", Columns: map[string]string{"Date": "a", "Description": "b", "Amount": "c"}},
+		"no date":        {Pattern: "x.csv", Columns: map[string]string{"Description": "b", "Amount": "c"}},
+		"no amount":      {Pattern: "x.csv", Columns: map[string]string{"Date": "a", "Description": "b"}},
+		"amount and out": {Pattern: "x.csv", Columns: map[string]string{"Date": "a", "Description": "b", "Amount": "c", "Debit": "d"}},
+		"reused column":  {Pattern: "x.csv", Columns: map[string]string{"Date": "a", "Description": "a", "Amount": "c"}},
+		"unknown field":  {Pattern: "x.csv", Columns: map[string]string{"Date": "a", "Description": "b", "Amount": "c", "Memo": "d"}},
+	} {
+		if err := manager.Set(bad); err == nil {
+			t.Errorf("%s: Set should fail", name)
+		}
+	}
+}
+
+func TestSuggestPattern(t *testing.T) {
+	tests := map[string]string{
+		"chase_2024-01.csv":      "chase_*.csv",
+		"Statement 12 2024.csv":  "Statement *.csv",
+		"export.csv":             "export.csv",
+		"acct4411_20240131.csv":  "acct*.csv",
+		"2024-01-31_savings.csv": "*_savings.csv",
+	}
+	for file, want := range tests {
+		if got := SuggestPattern(file); got != want {
+			t.Errorf("SuggestPattern(%q) = %q, want %q", file, got, want)
+		}
+	}
+}
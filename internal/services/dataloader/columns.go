@@ -0,0 +1,163 @@
+package dataloader
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"budget2/internal/models"
+)
+
+// ColumnMappings supplies the column mapping the user set up for a CSV data
+// file whose headers the loader doesn't recognize, or nil to recognize its
+// columns by name. Version works as for Enricher.
+type ColumnMappings interface {
+	Version() string
+	Mapping(file string) *models.ColumnMapping
+}
+
+// SetColumnMappings lets the user's mappings decide which columns files
+// are read from
+func (dl *DataLoader) SetColumnMappings(c ColumnMappings) {
+	dl.columns = c
+}
+
+// columnMapping returns the mapping the user set up for file, if any
+func (dl *DataLoader) columnMapping(file string) *models.ColumnMapping {
+	if dl.columns == nil {
+		return nil
+	}
+	return dl.columns.Mapping(file)
+}
+
+// columnIndex indexes a CSV file's header by field as buildColumnIndex
+// does. A mapping for the file decides all of models.MappableColumns, so
+// one reading Debit/Credit drops a recognized Amount column, while the
+// other fields are still recognized by name.
+func (dl *DataLoader) columnIndex(file string, header []string) (map[string]int, *models.ColumnMapping) {
+	colIndex := buildColumnIndex(header)
+	mapping := dl.columnMapping(file)
+	if mapping == nil {
+		return colIndex, nil
+	}
+	for _, field := range models.MappableColumns {
+		delete(colIndex, field)
+		name, ok := mapping.Columns[field]
+		if !ok {
+			continue
+		}
+		for i, col := range header {
+			if strings.TrimSpace(col) == name {
+				colIndex[field] = i
+				break
+			}
+		}
+	}
+	return colIndex, mapping
+}
+
+// mappingKey describes the columns a mapping reads, for fingerprints
+func mappingKey(mapping *models.ColumnMapping) string {
+	if mapping == nil {
+		return ""
+	}
+	var parts []string
+	for _, field := range models.MappableColumns {
+		if name, ok := mapping.Columns[field]; ok {
+			parts = append(parts, field+"="+name)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// missingColumns lists the fields the loader needs that colIndex lacks
+func missingColumns(colIndex map[string]int) []string {
+	var missing []string
+	for _, field := range []string{"Date", "Description"} {
+		if _, ok := colIndex[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	_, hasAmount := colIndex["Amount"]
+	_, hasDebit := colIndex["Debit"]
+	_, hasCredit := colIndex["Credit"]
+	if !hasAmount && !hasDebit && !hasCredit {
+		missing = append(missing, "Amount or Debit/Credit")
+	}
+	return missing
+}
+
+// fieldAt returns the field read from column i, or ""
+func fieldAt(colIndex map[string]int, i int) string {
+	for field, idx := range colIndex {
+		if idx == i && columnMappings[field] != nil {
+			return field
+		}
+	}
+	return ""
+}
+
+// PreviewColumns reads a CSV data file's header and up to rows of its
+// records, with the column each field is read from, so columns the loader
+// doesn't recognize can be mapped by hand
+func (dl *DataLoader) PreviewColumns(filename string, rows int) (models.ColumnPreview, error) {
+	filename = filepath.Base(filename)
+	if !strings.EqualFold(filepath.Ext(filename), ".csv") {
+		return models.ColumnPreview{}, fmt.Errorf("%s is not a CSV file", filename)
+	}
+	return dl.previewCSV(filepath.Join(dl.CSVDirectory, filename), rows)
+}
+
+// previewCSV reads a CSV file's header and first rows
+func (dl *DataLoader) previewCSV(filePath string, rows int) (models.ColumnPreview, error) {
+	file, err := dl.store.OpenFile(filePath)
+	if err != nil {
+		return models.ColumnPreview{}, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return models.ColumnPreview{}, fmt.Errorf("error reading header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	name := filepath.Base(filePath)
+	colIndex, mapping := dl.columnIndex(name, header)
+	preview := models.ColumnPreview{
+		File:    name,
+		Header:  header,
+		Rows:    [][]string{},
+		Columns: make(map[string]string),
+		Missing: missingColumns(colIndex),
+		Mapping: mapping,
+	}
+	for field, idx := range colIndex {
+		if columnMappings[field] != nil {
+			preview.Columns[field] = header[idx]
+		}
+	}
+
+	for len(preview.Rows) < rows {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+		// Pad or trim rows to the header so they line up in a table
+		line := make([]string, len(header))
+		copy(line, record)
+		preview.Rows = append(preview.Rows, line)
+	}
+	return preview, nil
+}
@@ -0,0 +1,92 @@
+package dataloader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// fixedColumns is a ColumnMappings with mappings set by the test, by file
+type fixedColumns map[string]*models.ColumnMapping
+
+func (f fixedColumns) Version() string {
+	return fmt.Sprint(len(f), mappingKey(f["bank.csv"]))
+}
+
+func (f fixedColumns) Mapping(file string) *models.ColumnMapping { return f[file] }
+
+func TestColumnMapping(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := "Posting Dt,Memo Line,Out,In,Amount\n" +
+		"01/15/2024,GROCERY STORE,42.10,,999\n" +
+		"01/16/2024,PAYROLL,,1500.00,999\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "bank.csv"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+	columns := fixedColumns{}
+	loader.SetColumnMappings(columns)
+
+	// Without a mapping the file lacks a date and description
+	preview, err := loader.PreviewColumns("bank.csv", 5)
+	if err != nil {
+		t.Fatalf("PreviewColumns: %v", err)
+	}
+	if !slices.Equal(preview.Missing, []string{"Date", "Description"}) || preview.Columns["Amount"] != "Amount" {
+		t.Errorf("preview missing %v columns %v", preview.Missing, preview.Columns)
+	}
+	if len(preview.Rows) != 2 || preview.Rows[1][3] != "1500.00" {
+		t.Errorf("preview rows = %v", preview.Rows)
+	}
+	if data, _ := loader.LoadData(); data.Len() != 0 {
+		t.Fatalf("loaded %d transactions without a mapping, want none", data.Len())
+	}
+	infos, _ := loader.GetFileInfo()
+	if len(infos) != 1 || len(infos[0].MissingColumns) != 2 {
+		t.Fatalf("file info = %+v, want missing columns", infos)
+	}
+	v1, _ := loader.DataVersion()
+	fp1, _ := loader.fingerprint(filepath.Join(tmpDir, "bank.csv"))
+
+	// A Debit/Credit mapping passes over the Amount column
+	columns["bank.csv"] = &models.ColumnMapping{Pattern: "bank*.csv", Columns: map[string]string{
+		"Date": "Posting Dt", "Description": "Memo Line", "Debit": "Out", "Credit": "In",
+	}}
+	if v2, _ := loader.DataVersion(); v2 == v1 {
+		t.Error("data version should change with the mappings")
+	}
+	if fp2, _ := loader.fingerprint(filepath.Join(tmpDir, "bank.csv")); fp2 == fp1 {
+		t.Error("stored rows should be parsed again when a file's mapping changes")
+	}
+
+	loader.SetStrict(true)
+	data, err := loader.LoadData()
+	if err != nil {
+		t.Fatalf("LoadData failed: %v", err)
+	}
+	if data.Len() != 2 {
+		t.Fatalf("loaded %d transactions, want 2", data.Len())
+	}
+	amounts := map[string]float64{}
+	for _, txn := range data.Transactions {
+		amounts[txn.Description] = txn.Amount
+	}
+	if amounts["GROCERY STORE"] != -42.10 || amounts["PAYROLL"] != 1500 {
+		t.Errorf("amounts = %v", amounts)
+	}
+
+	infos, _ = loader.GetFileInfo()
+	if info := infos[0]; len(info.MissingColumns) != 0 || info.ColumnMapping != "bank*.csv" || info.MinDate != "2024-01-15" || info.LoadError != "" {
+		t.Errorf("file info = %+v, want the mapping applied", info)
+	}
+
+	if _, err := loader.PreviewColumns("check.qif", 5); err == nil {
+		t.Error("previewing a QIF file should fail")
+	}
+}
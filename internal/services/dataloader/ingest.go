@@ -0,0 +1,198 @@
+package dataloader
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/txstore"
+)
+
+// parseVersion is part of every stored file's fingerprint. Bump it when
+// parsing changes so files ingested by older code are parsed again.
+const parseVersion = 4
+
+// IngestResult reports what Ingest did with one data file
+type IngestResult struct {
+	File         string
+	Transactions int
+	Parsed       bool // False when the stored copy was already current
+	Err          error
+}
+
+// Ingest brings the transaction store up to date with every data file,
+// enabled or not, parsing only new and changed files and dropping deleted
+// ones. It returns the per-file results and how many files were dropped.
+func (dl *DataLoader) Ingest() ([]IngestResult, int, error) {
+	db := dl.transactionStore()
+	if db == nil {
+		return nil, 0, errors.New("no transaction database configured")
+	}
+
+	files, err := dl.dataFiles()
+	if err != nil {
+		return nil, 0, fmt.Errorf("error finding data files: %w", err)
+	}
+
+	results := make([]IngestResult, 0, len(files))
+	for _, file := range files {
+		result := IngestResult{File: filepath.Base(file)}
+		fingerprint, err := dl.fingerprint(file)
+		if err != nil {
+			result.Err = err
+			results = append(results, result)
+			continue
+		}
+
+		transactions, ok, err := db.Get(result.File, fingerprint)
+		if err != nil || !ok {
+			result.Parsed = true
+			transactions, err = dl.loadFile(file)
+			if err == nil {
+				err = db.Put(result.File, fingerprint, transactions)
+			}
+		}
+		result.Transactions = len(transactions)
+		result.Err = err
+		results = append(results, result)
+	}
+
+	removed, err := db.Prune(fileNames(files))
+	return results, removed, err
+}
+
+// readFile returns a data file's parsed rows, from the transaction store
+// when the file hasn't changed since it was stored
+func (dl *DataLoader) readFile(filePath string) ([]models.Transaction, error) {
+	db := dl.transactionStore()
+	if db == nil {
+		return dl.loadFile(filePath)
+	}
+
+	name := filepath.Base(filePath)
+	fingerprint, err := dl.fingerprint(filePath)
+	if err != nil {
+		return dl.loadFile(filePath)
+	}
+	transactions, ok, err := db.Get(name, fingerprint)
+	if err != nil {
+		log.Printf("Warning: could not read %s from transaction database: %v", name, err)
+	}
+	if ok {
+		return transactions, nil
+	}
+
+	transactions, err = dl.loadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Put(name, fingerprint, transactions); err != nil {
+		log.Printf("Warning: could not store %s in transaction database: %v", name, err)
+	}
+	return transactions, nil
+}
+
+// Lookup returns the enabled files' transactions dated start through end,
+// oldest first, limited to categories and their subcategories when any are
+// given. When the transaction database's date and category indexes hold the
+// current data version it's served from them without loading the files;
+// otherwise it loads the data, which indexes it again, and filters that.
+func (dl *DataLoader) Lookup(start, end time.Time, categories []string) (*models.TransactionSet, error) {
+	if db := dl.transactionStore(); db != nil {
+		if version, err := dl.DataVersion(); err == nil {
+			if indexed, err := db.IndexedVersion(); err == nil && indexed == version {
+				if found, ok := dl.lookupIndex(db, version, start, end, categories); ok {
+					return found, nil
+				}
+			}
+		}
+	}
+
+	data, err := dl.LoadData()
+	if err != nil {
+		return nil, err
+	}
+	found := data.FilterByDateRange(start, end)
+	if len(categories) > 0 {
+		found = found.FilterByCategories(models.CategoryFilter{Include: categories})
+	}
+	return found.SortByDate(), nil
+}
+
+// lookupIndex answers Lookup from the indexes; ok is false when they hold
+// another data version or can't be read
+func (dl *DataLoader) lookupIndex(db *txstore.Store, version string, start, end time.Time, categories []string) (*models.TransactionSet, bool) {
+	var transactions []models.Transaction
+	var ok bool
+	var err error
+	if len(categories) == 0 {
+		transactions, ok, err = db.Range(version, start, end)
+	} else {
+		transactions, ok, err = db.Categories(version, categories, start, end)
+	}
+	if err != nil {
+		log.Printf("Warning: could not read transaction index: %v", err)
+		return nil, false
+	}
+	return &models.TransactionSet{Transactions: transactions}, ok
+}
+
+// fingerprint identifies a data file's contents by size and modification
+// time, as DataVersion does, plus the parser version, whether loading is
+// strict, so rows stored by a lenient load aren't reused by a strict one,
+// and any sign convention or column mapping the user chose for it
+func (dl *DataLoader) fingerprint(filePath string) (string, error) {
+	info, err := dl.store.Stat(filePath)
+	if err != nil {
+		return "", err
+	}
+	mode := ""
+	if dl.strict {
+		mode = "strict|"
+	}
+	if convention := dl.signOverride(filepath.Base(filePath)); convention != "" {
+		mode += "sign=" + convention + "|"
+	}
+	if columns := mappingKey(dl.columnMapping(filepath.Base(filePath))); columns != "" {
+		mode += "columns=" + columns + "|"
+	}
+	return fmt.Sprintf("v%d|%s%d|%d", parseVersion, mode, info.Size(), info.ModTime().UnixNano()), nil
+}
+
+// transactionStore returns the store in use, if any. Once storage is
+// encrypted the store is deleted rather than leave plain copies of the data
+// on disk.
+func (dl *DataLoader) transactionStore() *txstore.Store {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	if dl.txStore != nil && dl.store.IsEncrypted() {
+		log.Printf("Storage is encrypted, removing transaction database")
+		if err := dl.txStore.Destroy(); err != nil {
+			log.Printf("Warning: could not remove transaction database: %v", err)
+		}
+		dl.txStore = nil
+	}
+	return dl.txStore
+}
+
+// pruneTransactionStore drops stored files that no longer exist
+func (dl *DataLoader) pruneTransactionStore(db *txstore.Store) error {
+	files, err := dl.dataFiles()
+	if err != nil {
+		return err
+	}
+	_, err = db.Prune(fileNames(files))
+	return err
+}
+
+// fileNames returns the base names of paths
+func fileNames(paths []string) []string {
+	names := make([]string, len(paths))
+	for i, p := range paths {
+		names[i] = filepath.Base(p)
+	}
+	return names
+}
@@ -0,0 +1,145 @@
+package dataloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+	"budget2/internal/services/txstore"
+)
+
+func TestIngestParsesOnlyChangedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+	db, err := txstore.Open(filepath.Join(tmpDir, "cache", "transactions.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+	loader.SetTransactionStore(db)
+
+	checking := filepath.Join(tmpDir, "checking.csv")
+	os.WriteFile(checking, []byte("Date,Description,Amount\n2024-01-15,Store,-5.00\n2024-01-16,Cafe,-3.00\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "card.csv"), []byte("Date,Description,Amount\n2024-01-20,Gas,-40.00\n"), 0644)
+
+	results, _, err := loader.Ingest()
+	if err != nil {
+		t.Fatalf("Ingest failed: %v", err)
+	}
+	if len(results) != 2 || !results[0].Parsed || !results[1].Parsed || results[1].Transactions != 2 {
+		t.Fatalf("first ingest should parse both files, got %+v", results)
+	}
+
+	results, _, _ = loader.Ingest()
+	if results[0].Parsed || results[1].Parsed {
+		t.Errorf("unchanged files should not be parsed again, got %+v", results)
+	}
+
+	// Stored rows load the same as parsing
+	ts, err := loader.LoadData()
+	if err != nil || ts.Len() != 3 {
+		t.Fatalf("LoadData = %v rows, err %v; want 3", ts.Len(), err)
+	}
+
+	// Changed files are parsed again, deleted ones dropped
+	os.WriteFile(checking, []byte("Date,Description,Amount\n2024-01-15,Store,-5.00\n"), 0644)
+	os.Remove(filepath.Join(tmpDir, "card.csv"))
+	results, removed, _ := loader.Ingest()
+	if len(results) != 1 || !results[0].Parsed || results[0].Transactions != 1 || removed != 1 {
+		t.Errorf("expected checking.csv re-parsed and card.csv removed, got %+v, %d removed", results, removed)
+	}
+}
+
+func TestTransactionStoreDroppedWhenEncrypted(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+	dbPath := filepath.Join(tmpDir, "cache", "transactions.db")
+	db, err := txstore.Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	loader.SetTransactionStore(db)
+
+	os.WriteFile(filepath.Join(tmpDir, "checking.csv"), []byte("Date,Description,Amount\n2024-01-15,Store,-5.00\n"), 0644)
+	if _, err := loader.LoadData(); err != nil {
+		t.Fatalf("LoadData failed: %v", err)
+	}
+
+	if err := store.EnableEncryption("correcthorse"); err != nil {
+		t.Fatalf("EnableEncryption failed: %v", err)
+	}
+	ts, err := loader.LoadData()
+	if err != nil || ts.Len() != 1 {
+		t.Fatalf("LoadData after encryption = %v rows, err %v; want 1", ts.Len(), err)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Errorf("transaction database should be deleted once storage is encrypted, stat err = %v", err)
+	}
+}
+
+func TestLookupUsesIndexes(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+	db, err := txstore.Open(filepath.Join(tmpDir, "cache", "transactions.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+	loader.SetTransactionStore(db)
+
+	checking := filepath.Join(tmpDir, "checking.csv")
+	os.WriteFile(checking, []byte("Date,Description,Amount,Category\n"+
+		"2024-01-20,Kroger,-50.00,Food:Groceries\n"+
+		"2024-01-05,Diner,-20.00,Food:Restaurants\n"+
+		"2024-01-10,Landlord,-900.00,Rent\n"+
+		"2024-02-01,Kroger,-60.00,Food:Groceries\n"), 0644)
+
+	jan1, jan31 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	if _, err := loader.Lookup(jan1, jan31, nil); err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	version, _ := loader.DataVersion()
+	if indexed, _ := db.IndexedVersion(); indexed != version {
+		t.Fatalf("loading should index the current data, indexed %q want %q", indexed, version)
+	}
+
+	amounts := func(ts *models.TransactionSet) []float64 {
+		var list []float64
+		for _, t := range ts.Transactions {
+			list = append(list, t.Amount)
+		}
+		return list
+	}
+	january, _ := loader.Lookup(jan1, jan31, nil)
+	if got := amounts(january); len(got) != 3 || got[0] != -20 || got[2] != -50 {
+		t.Errorf("Lookup(January) = %v, want three rows oldest first", got)
+	}
+	food, _ := loader.Lookup(jan1, jan31, []string{"food"})
+	if got := amounts(food); len(got) != 2 || got[0] != -20 || got[1] != -50 {
+		t.Errorf("Lookup(January, food) = %v, want both Food subcategories", got)
+	}
+
+	// Lookups are answered by the indexes, not the loaded set
+	db.Index(version, []models.Transaction{{Date: jan1.AddDate(0, 0, 14), Amount: -1, Category: "Food"}})
+	if indexed, _ := loader.Lookup(jan1, jan31, []string{"Food"}); len(indexed.Transactions) != 1 || indexed.Transactions[0].Amount != -1 {
+		t.Errorf("Lookup = %v, want the indexed row", amounts(indexed))
+	}
+
+	// A changed file is loaded and indexed again before lookups
+	os.WriteFile(checking, []byte("Date,Description,Amount,Category\n2024-01-20,Kroger,-55.00,Food:Groceries\n"), 0644)
+	loader.Invalidate()
+	food, _ = loader.Lookup(jan1, jan31, []string{"Food:Groceries"})
+	if got := amounts(food); len(got) != 1 || got[0] != -55 {
+		t.Errorf("Lookup after a change = %v, want the new row", got)
+	}
+	version, _ = loader.DataVersion()
+	if indexed, _ := db.IndexedVersion(); indexed != version {
+		t.Errorf("a stale index should be rebuilt by the lookup, indexed %q want %q", indexed, version)
+	}
+}
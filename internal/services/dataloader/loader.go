@@ -1,27 +1,93 @@
 package dataloader
 
 import (
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"budget2/internal/models"
 	"budget2/internal/services/classifier"
 	"budget2/internal/services/storage"
+	"budget2/internal/services/txstore"
 )
 
-// DataLoader handles loading and preprocessing of financial data from CSV files
+// DataLoader handles loading and preprocessing of financial data from CSV
+// and QIF files
 type DataLoader struct {
 	CSVDirectory          string
 	FilteredTransferCount int
 	enabledFiles          map[string]bool
 	store                 *storage.Storage
+	enrichers             []Enricher
+	categorizers          []Categorizer
+	dedupeMode            string
+	strict                bool
+	signs                 SignOverrides
+	columns               ColumnMappings
+	accounts              Accounts
+	txStore               *txstore.Store
+	loadHooks             []func(*models.TransactionSet)
+
+	// Last LoadData result, reused while the data version is unchanged
+	mu            sync.Mutex
+	cached        *models.TransactionSet
+	cachedVersion string
+	cachedDedupe  models.DedupeReport
+	cachedFailed  map[string]*StrictError
+
+	// While a watcher reports file changes (see Watch), the data files'
+	// fingerprint is reused until it invalidates it
+	watching     bool
+	filesVersion string
+	filesGen     uint64
+}
+
+// Dedupe modes decide which transactions count as the same one loaded twice
+const (
+	// DedupeNormalized matches date, amount and description ignoring case,
+	// punctuation and spacing, so overlapping exports of one account whose
+	// descriptions are formatted slightly differently still collapse
+	DedupeNormalized = "normalized"
+	// DedupeExact matches date, amount and trimmed, lowercased description
+	DedupeExact = "exact"
+	// DedupeOff keeps every row
+	DedupeOff = "off"
+)
+
+// Enricher rewrites transactions after loading, e.g. filling in details from
+// another export. Version must change whenever Enrich's output would, since
+// it is part of the data version.
+type Enricher interface {
+	Version() string
+	Enrich(transactions []models.Transaction) []models.Transaction
+}
+
+// Accounts supplies the account a row of a data file belongs to, given the
+// row's account column (blank without one). A blank name leaves the
+// account named after the file. Version works as for Enricher.
+type Accounts interface {
+	Version() string
+	Resolve(file, name string) models.Account
+}
+
+// Categorizer reassigns categories after loading, before transfers are
+// filtered and transactions classified, so a category it sets can mark a
+// transfer or income. Version works as for Enricher.
+type Categorizer interface {
+	Version() string
+	Categorize(transactions []models.Transaction) []models.Transaction
 }
 
 // columnMappings maps common bank export column names to our standard names
@@ -33,6 +99,7 @@ var columnMappings = map[string][]string{
 		"post date", "Post Date", "POST DATE",
 		"trans date", "Trans Date", "TRANS DATE",
 		"posting date", "Posting Date", "POSTING DATE",
+		"run date", "Run Date", "RUN DATE",
 	},
 	"Description": {
 		"description", "Description", "DESCRIPTION",
@@ -43,12 +110,14 @@ var columnMappings = map[string][]string{
 		"transaction description", "Transaction Description",
 		"merchant", "Merchant", "MERCHANT",
 		"narrative", "Narrative", "NARRATIVE",
+		"action", "Action", "ACTION",
 	},
 	"Amount": {
 		"amount", "Amount", "AMOUNT",
 		"value", "Value", "VALUE",
 		"transaction amount", "Transaction Amount", "TRANSACTION AMOUNT",
 		"sum", "Sum", "SUM",
+		"amount ($)", "Amount ($)", "AMOUNT ($)",
 	},
 	"Category": {
 		"category", "Category", "CATEGORY",
@@ -69,6 +138,31 @@ var columnMappings = map[string][]string{
 		"money in", "Money In", "MONEY IN",
 		"income", "Income", "INCOME",
 	},
+	"Account": {
+		"account", "Account", "ACCOUNT",
+		"account name", "Account Name", "ACCOUNT NAME",
+		"account #", "Account #", "ACCOUNT #",
+		"account number", "Account Number", "ACCOUNT NUMBER",
+	},
+	"Balance": {
+		"balance", "Balance", "BALANCE",
+		"running balance", "Running Balance", "RUNNING BALANCE",
+		"running bal.", "Running Bal.", "RUNNING BAL.",
+		"available balance", "Available Balance", "AVAILABLE BALANCE",
+		"ledger balance", "Ledger Balance", "LEDGER BALANCE",
+	},
+}
+
+// dataFilePatterns match the transaction files the loader reads
+var dataFilePatterns = []string{"*.csv", "*.qif"}
+
+// IsDataFile reports whether name is a transaction file the loader reads
+func IsDataFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv", ".qif":
+		return true
+	}
+	return false
 }
 
 // New creates a new DataLoader
@@ -77,6 +171,7 @@ func New(csvDirectory string, store *storage.Storage) *DataLoader {
 		CSVDirectory: csvDirectory,
 		enabledFiles: make(map[string]bool),
 		store:        store,
+		dedupeMode:   DedupeNormalized,
 	}
 }
 
@@ -114,51 +209,332 @@ func (dl *DataLoader) SetEnabledFiles(files []string) {
 	}
 }
 
-// LoadData loads and combines data from all CSV files in the directory
+// SetDedupeMode chooses how duplicate transactions are detected, falling
+// back to DedupeNormalized for unknown modes
+func (dl *DataLoader) SetDedupeMode(mode string) {
+	switch mode {
+	case DedupeNormalized, DedupeExact, DedupeOff:
+		dl.dedupeMode = mode
+	default:
+		if mode != "" {
+			log.Printf("Warning: unknown dedupe mode %q, using %s", mode, DedupeNormalized)
+		}
+		dl.dedupeMode = DedupeNormalized
+	}
+}
+
+// SetStrict turns strict loading on or off. A strict load rejects any file
+// with a column, row or sign convention it can't interpret, reporting every
+// problem, where a lenient load skips what it can't read.
+func (dl *DataLoader) SetStrict(strict bool) {
+	dl.strict = strict
+}
+
+// AddCategorizer runs c on every load, before classification
+func (dl *DataLoader) AddCategorizer(c Categorizer) {
+	dl.categorizers = append(dl.categorizers, c)
+}
+
+// SetTransactionStore keeps parsed rows in db, so unchanged files aren't
+// parsed again. The loader drops and deletes db once storage is encrypted.
+func (dl *DataLoader) SetTransactionStore(db *txstore.Store) {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	dl.txStore = db
+}
+
+// OnLoad calls fn with the combined transactions each time the data is
+// loaded afresh, e.g. to assign styles to categories it hasn't seen.
+// fn must treat the set as read-only.
+func (dl *DataLoader) OnLoad(fn func(*models.TransactionSet)) {
+	dl.loadHooks = append(dl.loadHooks, fn)
+}
+
+// AddEnricher runs e on every load, after deduplication
+func (dl *DataLoader) AddEnricher(e Enricher) {
+	dl.enrichers = append(dl.enrichers, e)
+}
+
+// SetAccounts decides which account each row belongs to; without it each
+// file is a bank account named after the file
+func (dl *DataLoader) SetAccounts(a Accounts) {
+	dl.accounts = a
+}
+
+// account returns the account a row of file belongs to, given the row's
+// account column
+func (dl *DataLoader) account(file, name string) models.Account {
+	var account models.Account
+	if dl.accounts != nil {
+		account = dl.accounts.Resolve(file, name)
+	}
+	if account.Name == "" {
+		account.Name = strings.TrimSuffix(file, filepath.Ext(file))
+	}
+	if account.Type == "" {
+		account.Type = models.AccountBank
+	}
+	return account
+}
+
+// DataVersion returns a short fingerprint of the data files (name, size,
+// modification time), the enabled file selection, the categorizers' and
+// enrichers' versions, the chosen sign conventions, the column mappings and
+// the account types. It changes whenever data is uploaded, deleted, edited,
+// toggled, recategorized, enriched, re-signed, re-mapped or re-typed
+// differently, so it can key caches.
+func (dl *DataLoader) DataVersion() (string, error) {
+	files, err := dl.filesFingerprint()
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(files))
+
+	enabled := make([]string, 0, len(dl.enabledFiles))
+	for name, on := range dl.enabledFiles {
+		if on {
+			enabled = append(enabled, name)
+		}
+	}
+	sort.Strings(enabled)
+	fmt.Fprintf(h, "enabled:%s\ndedupe:%s", strings.Join(enabled, ","), dl.dedupeMode)
+	for _, c := range dl.categorizers {
+		fmt.Fprintf(h, "\ncategorizer:%s", c.Version())
+	}
+	for _, e := range dl.enrichers {
+		fmt.Fprintf(h, "\nenricher:%s", e.Version())
+	}
+	if dl.signs != nil {
+		fmt.Fprintf(h, "\nsigns:%s", dl.signs.Version())
+	}
+	if dl.columns != nil {
+		fmt.Fprintf(h, "\ncolumns:%s", dl.columns.Version())
+	}
+	if dl.accounts != nil {
+		fmt.Fprintf(h, "\naccounts:%s", dl.accounts.Version())
+	}
+
+	return hex.EncodeToString(h.Sum(nil)[:8]), nil
+}
+
+// filesFingerprint lists each data file's name, size and modification time.
+// While watching, the last listing is reused until a change invalidates it.
+func (dl *DataLoader) filesFingerprint() (string, error) {
+	dl.mu.Lock()
+	if dl.watching && dl.filesVersion != "" {
+		v := dl.filesVersion
+		dl.mu.Unlock()
+		return v, nil
+	}
+	gen := dl.filesGen
+	dl.mu.Unlock()
+
+	files, err := dl.dataFiles()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, file := range files {
+		info, err := dl.store.Stat(file)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "%s|%d|%d\n", filepath.Base(file), info.Size(), info.ModTime().UnixNano())
+	}
+	v := b.String()
+
+	// Only keep the listing if nothing changed while it was being made
+	dl.mu.Lock()
+	if dl.watching && dl.filesGen == gen {
+		dl.filesVersion = v
+	}
+	dl.mu.Unlock()
+	return v, nil
+}
+
+// Invalidate forgets the data files' fingerprint, so the next DataVersion or
+// LoadData call checks the files again. Handlers that write data files call
+// it so a reload never races the watcher's notification.
+func (dl *DataLoader) Invalidate() {
+	dl.mu.Lock()
+	dl.filesVersion = ""
+	dl.filesGen++
+	dl.mu.Unlock()
+}
+
+// LoadData loads and combines data from all CSV and QIF files in the directory.
+// The result is kept until the data version changes, so callers must treat
+// the returned set as read-only (filters and Copy return new sets).
 func (dl *DataLoader) LoadData() (*models.TransactionSet, error) {
-	pattern := filepath.Join(dl.CSVDirectory, "*.csv")
-	files, err := filepath.Glob(pattern)
+	version, verr := dl.DataVersion()
+
+	dl.mu.Lock()
+	if verr == nil && dl.cached != nil && dl.cachedVersion == version {
+		ts := dl.cached
+		dl.mu.Unlock()
+		return ts, nil
+	}
+	dl.mu.Unlock()
+
+	if db := dl.transactionStore(); db != nil {
+		if err := dl.pruneTransactionStore(db); err != nil {
+			log.Printf("Warning: could not prune transaction database: %v", err)
+		}
+	}
+
+	ts, report, err := dl.loadFiles(func(filename string) bool {
+		// Skip if file list is set and this file is not enabled
+		if len(dl.enabledFiles) > 0 && !dl.enabledFiles[filename] {
+			log.Printf("Skipping disabled file: %s", filename)
+			return false
+		}
+		return true
+	})
+	if err != nil || verr != nil {
+		return ts, err
+	}
+
+	dl.mu.Lock()
+	dl.cached = ts
+	dl.cachedVersion = version
+	dl.cachedDedupe = report.dedupe
+	dl.cachedFailed = report.failed
+	dl.mu.Unlock()
+
+	if db := dl.transactionStore(); db != nil {
+		if err := db.Index(version, ts.Transactions); err != nil {
+			log.Printf("Warning: could not index transactions: %v", err)
+		}
+	}
+	for _, fn := range dl.loadHooks {
+		fn(ts)
+	}
+	return ts, nil
+}
+
+// DedupeReport returns the duplicates dropped while loading the enabled
+// files, loading them first if the data has changed
+func (dl *DataLoader) DedupeReport() (models.DedupeReport, error) {
+	if _, err := dl.LoadData(); err != nil {
+		return models.DedupeReport{}, err
+	}
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	return dl.cachedDedupe, nil
+}
+
+// LoadSources loads only the named data files, regardless of which files are
+// enabled, so a view can be narrowed to one account without changing the
+// global selection. An empty list behaves like LoadData.
+func (dl *DataLoader) LoadSources(sources []string) (*models.TransactionSet, error) {
+	if len(sources) == 0 {
+		return dl.LoadData()
+	}
+
+	wanted := make(map[string]bool, len(sources))
+	for _, s := range sources {
+		wanted[filepath.Base(s)] = true
+	}
+	ts, _, err := dl.loadFiles(func(filename string) bool {
+		return wanted[filename]
+	})
+	return ts, err
+}
+
+// dataFiles lists the directory's CSV and QIF files in name order
+func (dl *DataLoader) dataFiles() ([]string, error) {
+	var files []string
+	for _, pattern := range dataFilePatterns {
+		matches, err := dl.store.Glob(filepath.Join(dl.CSVDirectory, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// loadFile loads transactions from one data file according to its format
+func (dl *DataLoader) loadFile(filePath string) ([]models.Transaction, error) {
+	if strings.EqualFold(filepath.Ext(filePath), ".qif") {
+		return dl.loadQIFFile(filePath)
+	}
+	return dl.loadCSVFile(filePath)
+}
+
+// loadReport is what loading found besides the transactions
+type loadReport struct {
+	dedupe models.DedupeReport
+	failed map[string]*StrictError // Files strict loading rejected
+}
+
+// loadFiles loads, preprocesses and combines the data files accepted by
+// include, reporting the duplicates it dropped and the files it rejected
+func (dl *DataLoader) loadFiles(include func(filename string) bool) (*models.TransactionSet, loadReport, error) {
+	report := loadReport{
+		dedupe: models.DedupeReport{Mode: dl.dedupeMode, ByFile: make(map[string]int)},
+		failed: make(map[string]*StrictError),
+	}
+
+	files, err := dl.dataFiles()
 	if err != nil {
-		return nil, fmt.Errorf("error finding CSV files: %w", err)
+		return nil, report, fmt.Errorf("error finding data files: %w", err)
 	}
 
 	if len(files) == 0 {
-		log.Printf("No CSV files found in %s - returning empty dataset", dl.CSVDirectory)
-		return models.NewTransactionSet(nil), nil
+		log.Printf("No data files found in %s - returning empty dataset", dl.CSVDirectory)
+		return models.NewTransactionSet(nil), report, nil
 	}
 
-	log.Printf("Found %d CSV files in %s", len(files), dl.CSVDirectory)
+	log.Printf("Found %d data files in %s", len(files), dl.CSVDirectory)
 
 	var allTransactions []models.Transaction
 
 	for _, file := range files {
 		filename := filepath.Base(file)
 
-		// Skip if file list is set and this file is not enabled
-		if len(dl.enabledFiles) > 0 && !dl.enabledFiles[filename] {
-			log.Printf("Skipping disabled file: %s", filename)
+		if !include(filename) {
 			continue
 		}
 
-		transactions, err := dl.loadCSVFile(file)
+		transactions, err := dl.readFile(file)
 		if err != nil {
 			log.Printf("Warning: failed to load %s: %v", filename, err)
+			var strictErr *StrictError
+			if errors.As(err, &strictErr) {
+				report.failed[filename] = strictErr
+			}
 			continue
 		}
 
+		for i := range transactions {
+			account := dl.account(filename, transactions[i].Account)
+			transactions[i].Account = account.Name
+			transactions[i].AccountType = account.Type
+		}
+
 		log.Printf("Loaded %d transactions from %s", len(transactions), filename)
 		allTransactions = append(allTransactions, transactions...)
 	}
 
 	if len(allTransactions) == 0 {
-		log.Printf("No transactions loaded from CSV files - returning empty dataset")
-		return models.NewTransactionSet(nil), nil
+		log.Printf("No transactions loaded from data files - returning empty dataset")
+		return models.NewTransactionSet(nil), report, nil
 	}
 
-	// Preprocess: filter transfers, classify, deduplicate
+	// Preprocess: categorize, filter transfers, classify, deduplicate, enrich
+	for _, c := range dl.categorizers {
+		allTransactions = c.Categorize(allTransactions)
+	}
 	allTransactions = dl.filterInternalTransfers(allTransactions)
 	allTransactions = classifier.ClassifyTransactions(allTransactions)
-	allTransactions = dl.deduplicateTransactions(allTransactions)
+	allTransactions = dl.deduplicateTransactions(allTransactions, &report.dedupe)
+	for _, e := range dl.enrichers {
+		allTransactions = e.Enrich(allTransactions)
+	}
 
 	// Compute derived fields
 	for i := range allTransactions {
@@ -167,15 +543,32 @@ func (dl *DataLoader) LoadData() (*models.TransactionSet, error) {
 
 	log.Printf("Total transactions after processing: %d", len(allTransactions))
 
-	return models.NewTransactionSet(allTransactions), nil
+	return models.NewTransactionSet(allTransactions), report, nil
 }
 
-// loadCSVFile loads transactions from a single CSV file
+// loadCSVFile loads transactions from a single CSV file. In strict mode
+// any column, row or sign convention it can't interpret rejects the file
+// with a StrictError listing them all.
 func (dl *DataLoader) loadCSVFile(filePath string) ([]models.Transaction, error) {
-	file, err := dl.store.OpenFile(filePath)
+	issues := dl.newIssueLog()
+	transactions, _, err := dl.parseCSVFile(filePath, issues)
 	if err != nil {
 		return nil, err
 	}
+	if err := issues.err(filepath.Base(filePath)); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// parseCSVFile reads a CSV file's transactions, signing their amounts by
+// the file's sign convention, which it also returns. Problems strict loading
+// rejects a file for are logged to issues.
+func (dl *DataLoader) parseCSVFile(filePath string, issues *issueLog) ([]models.Transaction, signChoice, error) {
+	file, err := dl.store.OpenFile(filePath)
+	if err != nil {
+		return nil, signChoice{}, err
+	}
 	defer file.Close()
 
 	reader := csv.NewReader(file)
@@ -185,11 +578,15 @@ func (dl *DataLoader) loadCSVFile(filePath string) ([]models.Transaction, error)
 	// Read header
 	header, err := reader.Read()
 	if err != nil {
-		return nil, fmt.Errorf("error reading header: %w", err)
+		return nil, signChoice{}, fmt.Errorf("error reading header: %w", err)
 	}
 
-	// Build normalized column index map
-	colIndex := buildColumnIndex(header)
+	// Build normalized column index map, following any mapping the user set
+	// up for the file
+	sourceFile := filepath.Base(filePath)
+	colIndex, mapping := dl.columnIndex(sourceFile, header)
+	checkHeader(header, colIndex, mapping != nil, issues)
+	typeIdx := typeColumn(header, colIndex)
 
 	// Check for Debit/Credit columns as alternative to Amount
 	_, hasAmount := colIndex["Amount"]
@@ -199,13 +596,16 @@ func (dl *DataLoader) loadCSVFile(filePath string) ([]models.Transaction, error)
 
 	// Validate required columns
 	if _, ok := colIndex["Date"]; !ok {
-		return nil, fmt.Errorf("missing required column: Date (tried: %v)", columnMappings["Date"])
+		return nil, signChoice{}, fmt.Errorf("missing required column: Date (tried: %v)", columnMappings["Date"])
 	}
 	if _, ok := colIndex["Description"]; !ok {
-		return nil, fmt.Errorf("missing required column: Description (tried: %v)", columnMappings["Description"])
+		return nil, signChoice{}, fmt.Errorf("missing required column: Description (tried: %v)", columnMappings["Description"])
 	}
 	if !hasAmount && !useDebitCredit {
-		return nil, fmt.Errorf("missing required column: Amount or Debit/Credit (tried: %v)", columnMappings["Amount"])
+		return nil, signChoice{}, fmt.Errorf("missing required column: Amount or Debit/Credit (tried: %v)", columnMappings["Amount"])
+	}
+	if hasAmount && (hasDebit || hasCredit) {
+		issues.add(1, "", "", "both an Amount column and Debit/Credit columns, so the sign of each row is ambiguous; only Amount is used")
 	}
 
 	if useDebitCredit {
@@ -213,7 +613,7 @@ func (dl *DataLoader) loadCSVFile(filePath string) ([]models.Transaction, error)
 	}
 
 	var transactions []models.Transaction
-	sourceFile := filepath.Base(filePath)
+	var rows []signRow
 	lineNum := 1
 
 	for {
@@ -223,6 +623,7 @@ func (dl *DataLoader) loadCSVFile(filePath string) ([]models.Transaction, error)
 		}
 		if err != nil {
 			log.Printf("Warning: error reading line %d: %v", lineNum+1, err)
+			issues.add(lineNum+1, "", "", "unreadable row: %v", err)
 			lineNum++
 			continue
 		}
@@ -238,33 +639,144 @@ func (dl *DataLoader) loadCSVFile(filePath string) ([]models.Transaction, error)
 			t.Date = parseDate(dateStr)
 			if t.Date.IsZero() {
 				log.Printf("Warning: could not parse date '%s' on line %d", dateStr, lineNum)
+				issues.add(lineNum, header[idx], dateStr, "unrecognized date")
 				continue
 			}
+		} else {
+			issues.add(lineNum, "", "", "row has no date")
 		}
 
 		// Parse Amount (either from Amount column or Debit/Credit columns)
 		if useDebitCredit {
+			checkDebitCredit(record, header, colIndex, lineNum, issues)
 			t.Amount = parseDebitCredit(record, colIndex)
 		} else if idx, ok := colIndex["Amount"]; ok && idx < len(record) {
 			amountStr := strings.TrimSpace(record[idx])
-			t.Amount = parseAmount(amountStr)
+			amount, err := parseAmountErr(amountStr)
+			if amountStr == "" {
+				issues.add(lineNum, header[idx], "", "row has no amount")
+			} else if err != nil {
+				issues.add(lineNum, header[idx], amountStr, "unrecognized amount")
+			}
+			t.Amount = amount
+		} else {
+			issues.add(lineNum, "", "", "row has no amount")
 		}
 
 		// Parse Description
 		if idx, ok := colIndex["Description"]; ok && idx < len(record) {
 			t.Description = strings.TrimSpace(record[idx])
 		}
+		if t.Description == "" {
+			issues.add(lineNum, "", "", "row has no description")
+		}
 
 		// Parse Category (optional)
 		if idx, ok := colIndex["Category"]; ok && idx < len(record) {
 			t.Category = strings.TrimSpace(record[idx])
 		}
 
-		t.Hash = t.ComputeHash()
+		// Parse Account (optional), for files holding several accounts
+		if idx, ok := colIndex["Account"]; ok && idx < len(record) {
+			t.Account = strings.TrimSpace(record[idx])
+		}
+
+		// Parse Balance (optional), the running balance after the row
+		if idx, ok := colIndex["Balance"]; ok && idx < len(record) {
+			if balanceStr := strings.TrimSpace(record[idx]); balanceStr != "" {
+				if balance, err := parseAmountErr(balanceStr); err != nil {
+					issues.add(lineNum, header[idx], balanceStr, "unrecognized balance")
+				} else {
+					t.Balance = &balance
+				}
+			}
+		}
+
+		row := signRow{line: lineNum}
+		if typeIdx >= 0 && typeIdx < len(record) {
+			row.kind = strings.TrimSpace(record[typeIdx])
+		}
+
 		transactions = append(transactions, t)
+		rows = append(rows, row)
 	}
 
-	return transactions, nil
+	choice := dl.chooseSign(sourceFile, transactions, rows, typeIdx >= 0, useDebitCredit)
+
+	// A card export listing purchases as positive numbers looks like all
+	// income; with an Amount column there's no telling it apart
+	if choice.ambiguous {
+		issues.add(0, header[colIndex["Amount"]], "", "every amount is positive, so it's unclear whether they are money in or purchases listed as positive (as some card issuers do); choose the file's sign convention to load it")
+	}
+
+	typeCol := ""
+	if typeIdx >= 0 {
+		typeCol = strings.TrimSpace(header[typeIdx])
+	}
+	applySign(transactions, rows, choice, typeCol, issues)
+
+	// A type column read as the category holds directions, not categories
+	if idx, ok := colIndex["Category"]; ok && idx == typeIdx && choice.convention == models.SignTypeColumn {
+		for i := range transactions {
+			transactions[i].Category = ""
+		}
+	}
+
+	return transactions, choice, nil
+}
+
+// checkHeader logs columns strict loading can't account for: columns that
+// map to nothing, and extra columns mapping to a field already taken. With
+// a column mapping (mapped), columns it passed over for a field are fine.
+func checkHeader(header []string, colIndex map[string]int, mapped bool, issues *issueLog) {
+	for i, col := range header {
+		name := strings.TrimSpace(col)
+		normalized := fieldAt(colIndex, i)
+		if normalized == "" {
+			normalized = normalizeColumnName(col)
+		}
+		switch {
+		case name == "":
+			issues.add(1, fmt.Sprintf("#%d", i+1), "", "unnamed column")
+		case mapped && slices.Contains(models.MappableColumns, normalized):
+		case columnMappings[normalized] == nil:
+			issues.add(1, name, "", "column doesn't map to Date, Description, Amount, Category, Debit, Credit, Account or Balance")
+		case colIndex[normalized] != i:
+			issues.add(1, name, "", "column also maps to %s, which is read from column %q", normalized, strings.TrimSpace(header[colIndex[normalized]]))
+		}
+	}
+}
+
+// checkDebitCredit logs Debit/Credit rows whose direction is ambiguous: no
+// amount, both columns filled, negative values or unreadable numbers
+func checkDebitCredit(record, header []string, colIndex map[string]int, line int, issues *issueLog) {
+	filled := 0
+	for _, name := range []string{"Debit", "Credit"} {
+		idx, ok := colIndex[name]
+		if !ok || idx >= len(record) {
+			continue
+		}
+		value := strings.TrimSpace(record[idx])
+		if value == "" {
+			continue
+		}
+		amount, err := parseAmountErr(value)
+		switch {
+		case err != nil:
+			issues.add(line, header[idx], value, "unrecognized amount")
+		case amount < 0:
+			issues.add(line, header[idx], value, "negative %s, so the direction of the money is ambiguous", strings.ToLower(name))
+		}
+		if amount != 0 {
+			filled++
+		}
+	}
+	switch filled {
+	case 0:
+		issues.add(line, "", "", "row has neither a debit nor a credit amount")
+	case 2:
+		issues.add(line, "", "", "row has both a debit and a credit amount")
+	}
 }
 
 // parseDebitCredit combines Debit and Credit columns into a single amount
@@ -327,8 +839,15 @@ func parseDate(s string) time.Time {
 	return time.Time{}
 }
 
-// parseAmount parses an amount string, handling currency symbols and parentheses
+// parseAmount parses an amount string, handling currency symbols and
+// parentheses. Amounts it can't read are zero.
 func parseAmount(s string) float64 {
+	amount, _ := parseAmountErr(s)
+	return amount
+}
+
+// parseAmountErr is parseAmount reporting amounts it can't read
+func parseAmountErr(s string) (float64, error) {
 	// Remove currency symbols and spaces
 	s = strings.ReplaceAll(s, "$", "")
 	s = strings.ReplaceAll(s, ",", "")
@@ -339,8 +858,7 @@ func parseAmount(s string) float64 {
 		s = "-" + s[1:len(s)-1]
 	}
 
-	amount, _ := strconv.ParseFloat(s, 64)
-	return amount
+	return strconv.ParseFloat(s, 64)
 }
 
 // filterInternalTransfers removes internal transfers to avoid double-counting
@@ -362,38 +880,67 @@ func (dl *DataLoader) filterInternalTransfers(transactions []models.Transaction)
 	return filtered
 }
 
-// deduplicateTransactions removes duplicate transactions based on hash
-func (dl *DataLoader) deduplicateTransactions(transactions []models.Transaction) []models.Transaction {
+// deduplicateTransactions removes transactions already seen under the
+// loader's dedupe mode, keeping the first (files load in name order) and
+// counting the rest against their files in report
+func (dl *DataLoader) deduplicateTransactions(transactions []models.Transaction, report *models.DedupeReport) []models.Transaction {
+	if dl.dedupeMode == DedupeOff {
+		return transactions
+	}
+
 	seen := make(map[string]bool)
 	var unique []models.Transaction
 
 	for _, t := range transactions {
-		if !seen[t.Hash] {
-			seen[t.Hash] = true
-			unique = append(unique, t)
+		key := t.Hash
+		if dl.dedupeMode == DedupeNormalized {
+			key = dedupeKey(t)
 		}
+		if seen[key] {
+			report.ByFile[t.SourceFile]++
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, t)
 	}
 
-	duplicatesRemoved := len(transactions) - len(unique)
-	if duplicatesRemoved > 0 {
-		log.Printf("Removed %d duplicate transactions", duplicatesRemoved)
+	report.Removed = len(transactions) - len(unique)
+	if report.Removed > 0 {
+		log.Printf("Removed %d duplicate transactions", report.Removed)
 	}
 
 	return unique
 }
 
-// GetFileInfo returns information about available CSV files
+// dedupeKey identifies a transaction by date, amount and its description's
+// letters and digits, lowercased
+func dedupeKey(t models.Transaction) string {
+	desc := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, t.Description)
+	return fmt.Sprintf("%s|%.2f|%s", t.Date.Format("2006-01-02"), t.Amount, strings.Join(strings.Fields(desc), " "))
+}
+
+// GetFileInfo returns information about available data files, including how
+// many of each file's rows the last load dropped as duplicates and why
+// strict loading rejected any
 func (dl *DataLoader) GetFileInfo() ([]models.FileInfo, error) {
-	pattern := filepath.Join(dl.CSVDirectory, "*.csv")
-	files, err := filepath.Glob(pattern)
+	files, err := dl.dataFiles()
 	if err != nil {
 		return nil, err
 	}
 
+	// A load failure only costs the duplicate counts and strict problems
+	dedupe, _ := dl.DedupeReport()
+	failed, _ := dl.LoadFailures()
+
 	var infos []models.FileInfo
 
 	for _, file := range files {
-		info, err := os.Stat(file)
+		info, err := dl.store.Stat(file)
 		if err != nil {
 			continue
 		}
@@ -401,7 +948,11 @@ func (dl *DataLoader) GetFileInfo() ([]models.FileInfo, error) {
 		filename := filepath.Base(file)
 
 		// Quick scan to get transaction count and date range
-		transCount, minD, maxD, err := dl.scanCSVMetadata(file)
+		scan := dl.scanCSVMetadata
+		if strings.EqualFold(filepath.Ext(file), ".qif") {
+			scan = dl.scanQIFMetadata
+		}
+		transCount, minD, maxD, err := scan(file)
 		minDate := ""
 		maxDate := ""
 
@@ -419,7 +970,7 @@ func (dl *DataLoader) GetFileInfo() ([]models.FileInfo, error) {
 			enabled = dl.enabledFiles[filename]
 		}
 
-		infos = append(infos, models.FileInfo{
+		fileInfo := models.FileInfo{
 			Name:         filename,
 			Path:         file,
 			Size:         info.Size(),
@@ -427,7 +978,29 @@ func (dl *DataLoader) GetFileInfo() ([]models.FileInfo, error) {
 			Transactions: transCount,
 			MinDate:      minDate,
 			MaxDate:      maxDate,
-		})
+			Duplicates:   dedupe.ByFile[filename],
+		}
+		account := dl.account(filename, "")
+		fileInfo.Account = account.Name
+		fileInfo.AccountType = account.Type
+		if choice, err := dl.fileSign(file); err == nil {
+			fileInfo.SignConvention = choice.convention
+			fileInfo.SignReason = choice.reason
+			fileInfo.SignOverridden = choice.overridden
+		}
+		if strictErr := failed[filename]; strictErr != nil {
+			fileInfo.LoadError = strictErr.Error()
+			fileInfo.Issues = strictErr.Issues
+		}
+		if !strings.EqualFold(filepath.Ext(file), ".qif") {
+			if preview, err := dl.previewCSV(file, 0); err == nil {
+				fileInfo.MissingColumns = preview.Missing
+				if preview.Mapping != nil {
+					fileInfo.ColumnMapping = preview.Mapping.Pattern
+				}
+			}
+		}
+		infos = append(infos, fileInfo)
 	}
 
 	return infos, nil
@@ -456,11 +1029,9 @@ func (dl *DataLoader) scanCSVMetadata(filePath string) (int, time.Time, time.Tim
 	}
 
 	dateIdx := -1
-	for i, col := range header {
-		if strings.TrimSpace(col) == "Date" {
-			dateIdx = i
-			break
-		}
+	colIndex, _ := dl.columnIndex(filepath.Base(filePath), header)
+	if idx, ok := colIndex["Date"]; ok {
+		dateIdx = idx
 	}
 
 	// Count transactions (lines - 1 for header)
@@ -1,11 +1,17 @@
 package dataloader
 
 import (
+	"cmp"
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"budget2/internal/models"
 	"budget2/internal/services/storage"
+	"budget2/internal/testutil"
 )
 
 func TestNormalizeColumnName(t *testing.T) {
@@ -58,8 +64,17 @@ func TestNormalizeColumnName(t *testing.T) {
 
 		// Unknown columns should pass through unchanged
 		{"Unknown Column", "Unknown Column"},
-		{"Balance", "Balance"},
+		{"Reference", "Reference"},
+
+		// Account variations
 		{"Account", "Account"},
+		{"Account Number", "Account"},
+		{"Account #", "Account"},
+
+		// Balance variations
+		{"Balance", "Balance"},
+		{"Running Balance", "Balance"},
+		{"Available Balance", "Balance"},
 	}
 
 	for _, tt := range tests {
@@ -301,6 +316,45 @@ Grocery Store,-50.00`,
 	}
 }
 
+// TestLoadBankExports loads synthetic downloads in each major bank's layout
+// (the files cmd/genfixtures writes) and checks every row maps back to the
+// transaction it came from
+func TestLoadBankExports(t *testing.T) {
+	want := testutil.SyntheticTransactions(45)
+
+	for _, bank := range testutil.BankExports {
+		t.Run(bank.Name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			csvPath := filepath.Join(tmpDir, bank.Name+".csv")
+			if err := os.WriteFile(csvPath, []byte(bank.CSV(want)), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			store, _ := storage.New(tmpDir)
+			transactions, err := New(tmpDir, store).loadCSVFile(csvPath)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(transactions) != len(want) {
+				t.Fatalf("got %d transactions, want %d", len(transactions), len(want))
+			}
+
+			for i, got := range transactions {
+				w := want[i]
+				amount := w.Amount // Charges-positive exports are detected and negated
+				if !got.Date.Equal(w.Date) || math.Abs(got.Amount-amount) > 0.005 || !strings.Contains(got.Description, w.Description) {
+					t.Fatalf("row %d = %s %q %.2f, want %s %q %.2f", i+1,
+						got.Date.Format("2006-01-02"), got.Description, got.Amount,
+						w.Date.Format("2006-01-02"), w.Description, amount)
+				}
+				if bank.HasCategory && got.Category != w.Category {
+					t.Fatalf("row %d category = %q, want %q", i+1, got.Category, w.Category)
+				}
+			}
+		})
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
 		(len(s) > 0 && len(substr) > 0 && findSubstring(s, substr)))
@@ -314,3 +368,301 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestDataVersionChangesWithData(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+
+	csvPath := filepath.Join(tmpDir, "checking.csv")
+	if err := os.WriteFile(csvPath, []byte("Date,Description,Amount\n2024-01-15,Store,-5.00\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	v1, err := loader.DataVersion()
+	if err != nil {
+		t.Fatalf("DataVersion failed: %v", err)
+	}
+	if again, _ := loader.DataVersion(); again != v1 {
+		t.Errorf("DataVersion not stable: %q then %q", v1, again)
+	}
+
+	// Appending a row changes size and so the version
+	if err := os.WriteFile(csvPath, []byte("Date,Description,Amount\n2024-01-15,Store,-5.00\n2024-01-16,Cafe,-3.00\n"), 0644); err != nil {
+		t.Fatalf("failed to rewrite test file: %v", err)
+	}
+	v2, _ := loader.DataVersion()
+	if v2 == v1 {
+		t.Error("DataVersion should change when a file changes")
+	}
+
+	// Toggling files changes the version too
+	loader.SetEnabledFiles([]string{"checking.csv"})
+	if v3, _ := loader.DataVersion(); v3 == v2 {
+		t.Error("DataVersion should change when enabled files change")
+	}
+}
+
+func TestLoadDataReusesUntilDataChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+	loads := 0
+	loader.OnLoad(func(*models.TransactionSet) { loads++ })
+
+	csvPath := filepath.Join(tmpDir, "checking.csv")
+	if err := os.WriteFile(csvPath, []byte("Date,Description,Amount\n2024-01-15,Store,-5.00\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	first, err := loader.LoadData()
+	if err != nil {
+		t.Fatalf("LoadData failed: %v", err)
+	}
+	if again, _ := loader.LoadData(); again != first {
+		t.Error("LoadData should reuse the parsed set while data is unchanged")
+	}
+	if loads != 1 {
+		t.Errorf("load hooks ran %d times, want once for the one fresh load", loads)
+	}
+
+	if err := os.WriteFile(csvPath, []byte("Date,Description,Amount\n2024-01-15,Store,-5.00\n2024-01-16,Cafe,-3.00\n"), 0644); err != nil {
+		t.Fatalf("failed to rewrite test file: %v", err)
+	}
+	reloaded, _ := loader.LoadData()
+	if reloaded == first || reloaded.Len() != 2 {
+		t.Errorf("LoadData should reparse after a file changes, got %d transactions", reloaded.Len())
+	}
+	if loads != 2 {
+		t.Errorf("load hooks ran %d times, want again after the change", loads)
+	}
+}
+
+func TestLoadSourcesIgnoresEnabledFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+
+	files := map[string]string{
+		"checking.csv": "Date,Description,Amount\n2024-01-15,Store,-5.00\n",
+		"credit.csv":   "Date,Description,Amount\n2024-01-16,Cafe,-3.00\n2024-01-17,Books,-12.00\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	loader.SetEnabledFiles([]string{"checking.csv"})
+
+	ts, err := loader.LoadSources([]string{"credit.csv"})
+	if err != nil {
+		t.Fatalf("LoadSources failed: %v", err)
+	}
+	if ts.Len() != 2 {
+		t.Errorf("LoadSources(credit.csv) loaded %d transactions, want 2 even though the file is disabled", ts.Len())
+	}
+
+	ts, _ = loader.LoadSources(nil)
+	if ts.Len() != 1 {
+		t.Errorf("LoadSources(nil) loaded %d transactions, want the 1 from enabled files", ts.Len())
+	}
+
+	if !loader.enabledFiles["checking.csv"] || loader.enabledFiles["credit.csv"] {
+		t.Errorf("LoadSources must not change the enabled-file selection, got %v", loader.enabledFiles)
+	}
+}
+
+func TestDedupeAcrossFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := storage.New(tmpDir)
+
+	// The same statement exported twice, the second time with different
+	// description formatting and one extra month
+	files := map[string]string{
+		"checking_jan.csv": "Date,Description,Amount\n2024-01-15,AMAZON.COM*AB12,-25.00\n2024-01-16,Cafe,-3.00\n",
+		"checking_all.csv": "Date,Description,Amount\n2024-01-15,Amazon.com  AB12,-25.00\n2024-01-16,cafe,-3.00\n2024-02-01,Books,-12.00\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		mode    string
+		want    int
+		removed int
+	}{
+		{DedupeNormalized, 3, 2},
+		{DedupeExact, 4, 1}, // Only the cafe rows match once lowercased
+		{DedupeOff, 5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			loader := New(tmpDir, store)
+			loader.SetDedupeMode(tt.mode)
+
+			ts, err := loader.LoadData()
+			if err != nil {
+				t.Fatalf("LoadData failed: %v", err)
+			}
+			if ts.Len() != tt.want {
+				t.Errorf("loaded %d transactions, want %d", ts.Len(), tt.want)
+			}
+
+			report, _ := loader.DedupeReport()
+			if report.Mode != tt.mode || report.Removed != tt.removed {
+				t.Errorf("report = %+v, want %d removed in %s mode", report, tt.removed, tt.mode)
+			}
+			// Files load in name order, so checking_all.csv keeps its rows and
+			// checking_jan.csv's copies are the ones dropped
+			infos, _ := loader.GetFileInfo()
+			for _, info := range infos {
+				want := 0
+				if info.Name == "checking_jan.csv" {
+					want = tt.removed
+				}
+				if info.Duplicates != want {
+					t.Errorf("%s: %d duplicates, want %d", info.Name, info.Duplicates, want)
+				}
+			}
+		})
+	}
+}
+
+// fixedAccounts is an Accounts with account types by file set by the test;
+// rows keep the account their column names
+type fixedAccounts map[string]string
+
+func (f fixedAccounts) Version() string {
+	return fmt.Sprint(map[string]string(f))
+}
+
+func (f fixedAccounts) Resolve(file, name string) models.Account {
+	return models.Account{Name: name, Type: f[file]}
+}
+
+func TestAccountTypeClassification(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"brokerage.csv": "Date,Description,Amount\n2024-01-05,DIVIDEND VTSAX,42.10\n2024-01-06,CONTRIBUTION FROM CHECKING,1000.00\n2024-01-07,SOLD 10 SH VTI,2300.00\n",
+		"card.csv":      "Date,Description,Amount\n2024-01-05,AMAZON REFUND,-25.00\n2024-01-06,CASHBACK REWARD,-10.00\n2024-01-07,SAFEWAY,60.00\n",
+		"checking.csv":  "Date,Description,Amount\n2024-01-05,TAX REFUND,250.00\n2024-01-06,STARBUCKS,-6.45\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+	accounts := fixedAccounts{}
+	loader.SetAccounts(accounts)
+	loader.SetSignOverrides(fixedSigns{"card.csv": models.SignDebitsPositive})
+
+	v1, _ := loader.DataVersion()
+	accounts["card.csv"] = models.AccountCreditCard
+	accounts["brokerage.csv"] = models.AccountInvestment
+	if v2, _ := loader.DataVersion(); v2 == v1 {
+		t.Error("data version should change with the account types")
+	}
+
+	data, err := loader.LoadData()
+	if err != nil {
+		t.Fatalf("LoadData failed: %v", err)
+	}
+	income := map[string]bool{}
+	for _, txn := range data.Transactions {
+		if txn.AccountType != cmp.Or(accounts[txn.SourceFile], models.AccountBank) {
+			t.Errorf("%s account type = %q, want its file's", txn.Description, txn.AccountType)
+		}
+		if txn.TransactionType == models.Income {
+			income[txn.Description] = true
+		}
+	}
+	// Card refunds and rewards stay credits against spending, and only the
+	// brokerage's dividend is income
+	want := map[string]bool{"TAX REFUND": true, "DIVIDEND VTSAX": true}
+	if len(income) != len(want) || !income["TAX REFUND"] || !income["DIVIDEND VTSAX"] {
+		t.Errorf("income = %v, want %v", income, want)
+	}
+	for _, txn := range data.FilterByType(models.Outflow).Transactions {
+		if txn.Description == "AMAZON REFUND" && txn.Amount != 25 {
+			t.Errorf("card refund = %.2f, want a 25.00 credit", txn.Amount)
+		}
+	}
+
+	infos, _ := loader.GetFileInfo()
+	for _, info := range infos {
+		if info.AccountType != cmp.Or(accounts[info.Name], models.AccountBank) {
+			t.Errorf("%s: account type %q", info.Name, info.AccountType)
+		}
+	}
+}
+
+func TestAccountColumn(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"household.csv": "Date,Description,Amount,Account Name\n2024-01-05,SAFEWAY,-60.00,Joint Checking\n2024-01-06,STARBUCKS,-6.45,Visa\n2024-01-07,PAYROLL,2000.00,\n",
+		"savings.qif":   "!Type:Bank\nD01/15/2024\nT12.50\nPInterest\n^\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+	loader.SetAccounts(fixedAccounts{"household.csv": models.AccountCreditCard})
+
+	data, err := loader.LoadData()
+	if err != nil {
+		t.Fatalf("LoadData failed: %v", err)
+	}
+	// Rows without an account column value belong to an account named
+	// after their file
+	want := map[string]string{
+		"SAFEWAY":   "Joint Checking",
+		"STARBUCKS": "Visa",
+		"PAYROLL":   "household",
+		"Interest":  "savings",
+	}
+	for _, txn := range data.Transactions {
+		if txn.Account != want[txn.Description] {
+			t.Errorf("%s account = %q, want %q", txn.Description, txn.Account, want[txn.Description])
+		}
+	}
+	if got := data.Accounts(); strings.Join(got, ",") != "household,Joint Checking,savings,Visa" {
+		t.Errorf("accounts = %v", got)
+	}
+	if visa := data.FilterByAccounts([]string{"visa"}); visa.Len() != 1 || visa.Transactions[0].AccountType != models.AccountCreditCard {
+		t.Errorf("Visa transactions = %+v", visa.Transactions)
+	}
+}
+
+func TestBalanceColumn(t *testing.T) {
+	tmpDir := t.TempDir()
+	csv := "Date,Description,Amount,Running Balance\n2024-01-05,SAFEWAY,-60.00,\"1,940.00\"\n2024-01-06,STARBUCKS,-6.45,\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "checking.csv"), []byte(csv), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+
+	data, err := loader.LoadData()
+	if err != nil {
+		t.Fatalf("LoadData failed: %v", err)
+	}
+	for _, txn := range data.Transactions {
+		switch txn.Description {
+		case "SAFEWAY":
+			if txn.Balance == nil || *txn.Balance != 1940 {
+				t.Errorf("SAFEWAY balance = %v, want 1940", txn.Balance)
+			}
+		case "STARBUCKS":
+			if txn.Balance != nil {
+				t.Errorf("STARBUCKS balance = %v, want none for a blank cell", *txn.Balance)
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package dataloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"budget2/internal/services/storage"
+	"budget2/internal/testutil"
+)
+
+// benchmarkLoader returns a loader over three years of synthetic data
+func benchmarkLoader(tb testing.TB) *DataLoader {
+	tmpDir := tb.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "synthetic.csv"), []byte(testutil.SyntheticCSV(3*365)), 0644); err != nil {
+		tb.Fatalf("failed to write synthetic data: %v", err)
+	}
+	store, _ := storage.New(tmpDir)
+	return New(tmpDir, store)
+}
+
+// BenchmarkLoadData measures a cold parse; LoadData's own cache is bypassed
+func BenchmarkLoadData(b *testing.B) {
+	loader := benchmarkLoader(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := loader.loadFiles(func(string) bool { return true }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestLoadDataBudget(t *testing.T) {
+	testutil.CheckBudget(t, 150*time.Millisecond, BenchmarkLoadData)
+}
@@ -0,0 +1,188 @@
+package dataloader
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"budget2/internal/models"
+)
+
+// qifAccountTypes are the QIF sections that hold cash transactions. Others,
+// such as investment, category and memorized-transaction lists, are skipped.
+var qifAccountTypes = map[string]bool{
+	"bank":  true,
+	"ccard": true,
+	"cash":  true,
+	"oth a": true,
+	"oth l": true,
+}
+
+// loadQIFFile loads transactions from a single Quicken Interchange Format file
+func (dl *DataLoader) loadQIFFile(filePath string) ([]models.Transaction, error) {
+	data, err := dl.store.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	issues := dl.newIssueLog()
+	transactions, err := parseQIF(bytes.NewReader(data), filepath.Base(filePath), issues)
+	if err != nil {
+		return nil, err
+	}
+	applySign(transactions, nil, dl.qifSign(filepath.Base(filePath)), "", issues)
+	if err := issues.err(filepath.Base(filePath)); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// parseQIF reads the transactions in a QIF export. Each record is a run of
+// lines keyed by their first letter (D date, T amount, P payee, M memo,
+// L category) ending with "^". Payees become descriptions, falling back to
+// the memo, and transfers ("[Savings]") are categorized as Transfer.
+// Unreadable dates and amounts are logged to issues.
+func parseQIF(r io.Reader, sourceFile string, issues *issueLog) ([]models.Transaction, error) {
+	scanner := bufio.NewScanner(r)
+
+	var transactions []models.Transaction
+	var t models.Transaction
+	var memo, splitCategory string
+	inAccount := false
+	sawHeader := false
+	lineNum := 0
+
+	finish := func() {
+		defer func() {
+			t = models.Transaction{}
+			memo, splitCategory = "", ""
+		}()
+		if !inAccount || t.Date.IsZero() {
+			return
+		}
+		if t.Description == "" {
+			t.Description = memo
+		}
+		if t.Category == "" {
+			t.Category = splitCategory
+		}
+		t.SourceFile = sourceFile
+		t.Hash = t.ComputeHash()
+		transactions = append(transactions, t)
+	}
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "!") {
+			header := strings.ToLower(strings.TrimSpace(line))
+			if strings.HasPrefix(header, "!type:") {
+				sawHeader = true
+				inAccount = qifAccountTypes[strings.TrimSpace(strings.TrimPrefix(header, "!type:"))]
+			} else if header == "!account" {
+				// Account list entries until the next !Type header
+				inAccount = false
+			}
+			continue
+		}
+
+		code, value := line[0], strings.TrimSpace(line[1:])
+		switch code {
+		case '^':
+			finish()
+		case 'D':
+			t.Date = parseQIFDate(value)
+			if t.Date.IsZero() && inAccount {
+				log.Printf("Warning: could not parse date '%s' on line %d of %s", value, lineNum, sourceFile)
+				issues.add(lineNum, "D", value, "unrecognized date")
+			}
+		case 'T', 'U':
+			amount, err := parseAmountErr(value)
+			if err != nil && inAccount {
+				issues.add(lineNum, string(code), value, "unrecognized amount")
+			}
+			t.Amount = amount
+		case 'P':
+			t.Description = value
+		case 'M':
+			memo = value
+		case 'L':
+			t.Category = qifCategory(value)
+		case 'S':
+			if splitCategory == "" {
+				splitCategory = qifCategory(value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading QIF: %w", err)
+	}
+	if !sawHeader {
+		return nil, fmt.Errorf("missing !Type header, not a QIF file")
+	}
+	// Tolerate a final record without its "^"
+	finish()
+
+	return transactions, nil
+}
+
+// qifCategory converts a QIF category field, dropping the "/class" suffix
+// and mapping transfers to other accounts to Transfer. Subcategories keep
+// Quicken's "Parent:Child" form.
+func qifCategory(value string) string {
+	if i := strings.Index(value, "/"); i >= 0 {
+		value = value[:i]
+	}
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		return "Transfer"
+	}
+	return value
+}
+
+// parseQIFDate parses QIF dates, which Quicken writes as 1/15/2025,
+// 1/15/25 or 1/15'25 (the apostrophe marking 2000s years), sometimes
+// padded with spaces
+func parseQIFDate(s string) time.Time {
+	s = strings.ReplaceAll(s, " ", "")
+	s = strings.ReplaceAll(s, "'", "/")
+	if t := parseDate(s); !t.IsZero() {
+		return t
+	}
+	for _, format := range []string{"1/2/06", "1-2-06"} {
+		if t, err := time.Parse(format, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// scanQIFMetadata counts a QIF file's transactions and finds its date range
+func (dl *DataLoader) scanQIFMetadata(filePath string) (int, time.Time, time.Time, error) {
+	transactions, err := dl.loadQIFFile(filePath)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, err
+	}
+
+	var minDate, maxDate time.Time
+	for _, t := range transactions {
+		if minDate.IsZero() || t.Date.Before(minDate) {
+			minDate = t.Date
+		}
+		if t.Date.After(maxDate) {
+			maxDate = t.Date
+		}
+	}
+	return len(transactions), minDate, maxDate, nil
+}
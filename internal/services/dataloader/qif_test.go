@@ -0,0 +1,126 @@
+package dataloader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"budget2/internal/services/storage"
+)
+
+const sampleQIF = `!Type:Bank
+D01/15/2025
+T-1,234.56
+PLandlord LLC
+LHousing:Rent
+^
+D1/20'25
+T3,000.00
+PACME PAYROLL
+LSalary
+N1042
+^
+D 1/22/25
+T-45.00
+MCash at farmers market
+^
+D1/25/2025
+T-500.00
+PTransfer to savings
+L[Savings]
+^
+D1/28/2025
+T-120.00
+PCostco
+SGroceries
+$-80.00
+SHousehold
+$-40.00
+^
+!Type:Invst
+D1/29/2025
+NBuy
+YVTSAX
+T1000.00
+^
+`
+
+func TestParseQIF(t *testing.T) {
+	transactions, err := parseQIF(strings.NewReader(sampleQIF), "checking.qif", nil)
+	if err != nil {
+		t.Fatalf("parseQIF failed: %v", err)
+	}
+	if len(transactions) != 5 {
+		t.Fatalf("got %d transactions, want the 5 bank ones: %+v", len(transactions), transactions)
+	}
+
+	tests := []struct {
+		date, description, category string
+		amount                      float64
+	}{
+		{"2025-01-15", "Landlord LLC", "Housing:Rent", -1234.56},
+		{"2025-01-20", "ACME PAYROLL", "Salary", 3000},
+		{"2025-01-22", "Cash at farmers market", "", -45},
+		{"2025-01-25", "Transfer to savings", "Transfer", -500},
+		{"2025-01-28", "Costco", "Groceries", -120},
+	}
+	for i, tt := range tests {
+		got := transactions[i]
+		if got.Date.Format("2006-01-02") != tt.date || got.Description != tt.description ||
+			got.Category != tt.category || got.Amount != tt.amount {
+			t.Errorf("transaction %d = %s %q %q %.2f, want %s %q %q %.2f", i,
+				got.Date.Format("2006-01-02"), got.Description, got.Category, got.Amount,
+				tt.date, tt.description, tt.category, tt.amount)
+		}
+		if got.SourceFile != "checking.qif" || got.Hash == "" {
+			t.Errorf("transaction %d: source %q, hash %q", i, got.SourceFile, got.Hash)
+		}
+	}
+
+	if _, err := parseQIF(strings.NewReader("Date,Description,Amount\n"), "not.qif", nil); err == nil {
+		t.Error("expected an error for a file without a !Type header")
+	}
+}
+
+func TestLoadDataIncludesQIF(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+
+	files := map[string]string{
+		"checking.qif": sampleQIF,
+		"credit.csv":   "Date,Description,Amount\n2025-01-16,Cafe,-3.00\n",
+		"notes.txt":    "not transactions",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	ts, err := loader.LoadData()
+	if err != nil {
+		t.Fatalf("LoadData failed: %v", err)
+	}
+	// The transfer to savings isn't an internal transfer pattern, so all 5
+	// QIF transactions load alongside the CSV row
+	if ts.Len() != 6 {
+		t.Errorf("LoadData loaded %d transactions, want 6", ts.Len())
+	}
+
+	infos, err := loader.GetFileInfo()
+	if err != nil {
+		t.Fatalf("GetFileInfo failed: %v", err)
+	}
+	if len(infos) != 2 || infos[0].Name != "checking.qif" {
+		t.Fatalf("GetFileInfo = %+v, want checking.qif and credit.csv", infos)
+	}
+	if qif := infos[0]; qif.Transactions != 5 || qif.MinDate != "2025-01-15" || qif.MaxDate != "2025-01-28" {
+		t.Errorf("checking.qif info = %d transactions from %s to %s", qif.Transactions, qif.MinDate, qif.MaxDate)
+	}
+
+	if !IsDataFile("Checking.QIF") || IsDataFile("notes.txt") {
+		t.Error("IsDataFile should accept QIF in any case and reject other files")
+	}
+}
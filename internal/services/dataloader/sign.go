@@ -0,0 +1,220 @@
+package dataloader
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"budget2/internal/models"
+	"budget2/internal/services/classifier"
+)
+
+// SignOverrides supplies the sign convention (one of
+// models.SignConventions) the user chose for a data file, or "" to detect
+// it. Version works as for Enricher.
+type SignOverrides interface {
+	Version() string
+	Convention(file string) string
+}
+
+// SetSignOverrides lets the user's choices replace detected sign conventions
+func (dl *DataLoader) SetSignOverrides(s SignOverrides) {
+	dl.signs = s
+}
+
+// signOverride returns the convention the user chose for file, if any
+func (dl *DataLoader) signOverride(file string) string {
+	if dl.signs == nil {
+		return ""
+	}
+	return dl.signs.Convention(file)
+}
+
+// signChoice is the sign convention in effect for one file and why
+type signChoice struct {
+	convention string
+	reason     string
+	overridden bool
+	ambiguous  bool // Every amount is positive and nothing says which way they go
+}
+
+// signRow is what sign handling needs from a CSV row besides its amount
+type signRow struct {
+	line int
+	kind string // Type column value
+}
+
+// typeColumnNames are headers of columns saying which way a row's money went
+var typeColumnNames = []string{
+	"type", "transaction type", "details",
+	"debit/credit", "credit/debit", "dr/cr", "cr/dr", "credit debit indicator",
+}
+
+// Words in type column values marking money out and money in (lowercase)
+var (
+	debitTypeWords  = []string{"debit", "dr", "sale", "purchase", "withdrawal", "fee", "charge"}
+	creditTypeWords = []string{"credit", "cr", "payment", "return", "refund", "deposit"}
+)
+
+// typeColumn returns the index of the first column that may say which way
+// each row's money went, or -1. Columns read as the date, description or
+// amount don't count.
+func typeColumn(header []string, colIndex map[string]int) int {
+	for i, col := range header {
+		if !slices.Contains(typeColumnNames, strings.ToLower(strings.TrimSpace(col))) {
+			continue
+		}
+		taken := false
+		for _, field := range []string{"Date", "Description", "Amount"} {
+			if idx, ok := colIndex[field]; ok && idx == i {
+				taken = true
+			}
+		}
+		if !taken {
+			return i
+		}
+	}
+	return -1
+}
+
+// typeDirection reads a type column value such as "Sale", "DEBIT" or
+// "ACH_CREDIT": -1 for money out, 1 for money in, 0 if it doesn't say
+func typeDirection(value string) int {
+	words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, w := range words {
+		switch {
+		case slices.Contains(debitTypeWords, w):
+			return -1
+		case slices.Contains(creditTypeWords, w):
+			return 1
+		}
+	}
+	return 0
+}
+
+// chooseSign picks the sign convention for a CSV file: the user's choice if
+// there is one, otherwise what its columns and amounts suggest
+func (dl *DataLoader) chooseSign(file string, transactions []models.Transaction, rows []signRow, hasTypeColumn, useDebitCredit bool) signChoice {
+	if convention := dl.signOverride(file); convention != "" {
+		choice := signChoice{convention: convention, reason: "chosen by hand", overridden: true}
+		if convention == models.SignTypeColumn && !hasTypeColumn {
+			choice.reason = "chosen by hand, but the file has no type column, so amounts are used as written"
+		}
+		return choice
+	}
+	if useDebitCredit {
+		return signChoice{convention: models.SignDebitsNegative, reason: "separate debit and credit columns give each row's direction"}
+	}
+	return detectSign(transactions, rows, hasTypeColumn)
+}
+
+// detectSign works out how a file signs its amounts. A type column whose
+// values mostly say debit or credit wins. Otherwise a file whose amounts are
+// mostly positive, with none looking like income, and whose negative rows
+// look like payments or income, is a card export listing purchases as
+// positive. Anything else is taken as already signed the app's way.
+func detectSign(transactions []models.Transaction, rows []signRow, hasTypeColumn bool) signChoice {
+	if hasTypeColumn {
+		filled, known := 0, 0
+		for _, row := range rows {
+			if strings.TrimSpace(row.kind) == "" {
+				continue
+			}
+			filled++
+			if typeDirection(row.kind) != 0 {
+				known++
+			}
+		}
+		if known > 0 && known*2 >= filled {
+			return signChoice{convention: models.SignTypeColumn, reason: "a type column marks each row as money in or out"}
+		}
+	}
+
+	positive, negative := 0, 0
+	positiveIncome, negativePayments := false, false
+	for _, t := range transactions {
+		switch {
+		case t.Amount > 0:
+			positive++
+			if classifier.IsPotentialIncome(&t) {
+				positiveIncome = true
+			}
+		case t.Amount < 0:
+			negative++
+			flipped := t
+			flipped.Amount = -t.Amount
+			if classifier.IsPotentialIncome(&flipped) || strings.Contains(strings.ToLower(t.Description), "payment") {
+				negativePayments = true
+			}
+		}
+	}
+
+	switch {
+	case positive > 1 && negative == 0:
+		return signChoice{
+			convention: models.SignDebitsNegative,
+			reason:     "every amount is positive, so they are taken as money in; choose \"money out positive\" if they are purchases",
+			ambiguous:  true,
+		}
+	case negative > 0 && positive >= 2*negative && !positiveIncome && negativePayments:
+		return signChoice{convention: models.SignDebitsPositive, reason: "most amounts are positive and the negative ones look like payments or income, as in card exports listing purchases as positive"}
+	}
+	return signChoice{convention: models.SignDebitsNegative, reason: "amounts are negative for money out"}
+}
+
+// applySign rewrites amounts to the app's convention (money out negative)
+// and rehashes the rows, since the amount is part of the hash. Rows whose
+// type column doesn't say which way the money went keep their amount and,
+// like a missing type column, are logged to issues.
+func applySign(transactions []models.Transaction, rows []signRow, choice signChoice, typeCol string, issues *issueLog) {
+	switch choice.convention {
+	case models.SignDebitsPositive:
+		for i := range transactions {
+			transactions[i].Amount = -transactions[i].Amount
+		}
+	case models.SignTypeColumn:
+		if typeCol == "" {
+			issues.add(0, "", "", "no type column to sign amounts by")
+			break
+		}
+		for i := range transactions {
+			switch typeDirection(rows[i].kind) {
+			case -1:
+				transactions[i].Amount = -abs(transactions[i].Amount)
+			case 1:
+				transactions[i].Amount = abs(transactions[i].Amount)
+			default:
+				issues.add(rows[i].line, typeCol, rows[i].kind, "type doesn't say whether money went in or out")
+			}
+		}
+	}
+
+	for i := range transactions {
+		transactions[i].Hash = transactions[i].ComputeHash()
+	}
+}
+
+// qifSign is the sign convention for a QIF file, whose amounts are signed
+// the app's way unless the user says otherwise
+func (dl *DataLoader) qifSign(file string) signChoice {
+	switch convention := dl.signOverride(file); convention {
+	case "":
+		return signChoice{convention: models.SignDebitsNegative, reason: "QIF amounts are negative for money out"}
+	case models.SignTypeColumn:
+		return signChoice{convention: convention, reason: "chosen by hand, but QIF files have no type column, so amounts are used as written", overridden: true}
+	default:
+		return signChoice{convention: convention, reason: "chosen by hand", overridden: true}
+	}
+}
+
+// fileSign returns the sign convention in effect for a data file
+func (dl *DataLoader) fileSign(filePath string) (signChoice, error) {
+	if strings.EqualFold(filepath.Ext(filePath), ".qif") {
+		return dl.qifSign(filepath.Base(filePath)), nil
+	}
+	_, choice, err := dl.parseCSVFile(filePath, nil)
+	return choice, err
+}
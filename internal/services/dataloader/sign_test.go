@@ -0,0 +1,180 @@
+package dataloader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// fixedSigns is a SignOverrides with conventions set by the test
+type fixedSigns map[string]string
+
+func (f fixedSigns) Version() string {
+	return fmt.Sprint(map[string]string(f)) // Printed with sorted keys
+}
+
+func (f fixedSigns) Convention(file string) string { return f[file] }
+
+func TestDetectSignConvention(t *testing.T) {
+	tests := []struct {
+		name       string
+		csv        string
+		convention string
+		amounts    []float64
+		categories []string // Checked when set
+	}{
+		{
+			name: "signed amounts",
+			csv: `Date,Description,Amount
+2024-01-15,Grocery Store,-50.00
+2024-01-16,Paycheck,3000.00`,
+			convention: models.SignDebitsNegative,
+			amounts:    []float64{-50, 3000},
+		},
+		{
+			name: "card listing charges as positive",
+			csv: `Date,Description,Card Member,Amount
+01/15/2024,SAFEWAY,J DOE,82.10
+01/16/2024,STARBUCKS,J DOE,6.45
+01/17/2024,SHELL OIL,J DOE,40.00
+01/20/2024,AUTOPAY PAYMENT - THANK YOU,J DOE,-500.00`,
+			convention: models.SignDebitsPositive,
+			amounts:    []float64{-82.10, -6.45, -40, 500},
+		},
+		{
+			name: "mostly positive savings account",
+			csv: `Date,Description,Amount
+2024-01-15,Interest Earned,1.20
+2024-01-16,Transfer from checking,500.00
+2024-01-17,Transfer from checking,500.00
+2024-01-20,Withdrawal to checking,-200.00`,
+			convention: models.SignDebitsNegative,
+			amounts:    []float64{1.20, 500, 500, -200},
+		},
+		{
+			name: "type column with unsigned amounts",
+			csv: `Date,Description,Amount,Transaction Type
+2024-01-15,Grocery Store,50.00,DEBIT
+2024-01-16,Paycheck,3000.00,CREDIT
+2024-01-17,Coffee,4.50,ACH_DEBIT`,
+			convention: models.SignTypeColumn,
+			amounts:    []float64{-50, 3000, -4.50},
+		},
+		{
+			name: "type column read as the category",
+			csv: `Date,Description,Amount,Type
+2024-01-15,Grocery Store,50.00,Sale
+2024-01-16,Card payment,200.00,Payment`,
+			convention: models.SignTypeColumn,
+			amounts:    []float64{-50, 200},
+			categories: []string{"", ""},
+		},
+		{
+			name: "type column holding categories",
+			csv: `Date,Description,Amount,Type
+2024-01-15,Grocery Store,-50.00,Groceries
+2024-01-16,Paycheck,3000.00,Income`,
+			convention: models.SignDebitsNegative,
+			amounts:    []float64{-50, 3000},
+			categories: []string{"Groceries", "Income"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			csvPath := filepath.Join(tmpDir, "test.csv")
+			if err := os.WriteFile(csvPath, []byte(tt.csv), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+			store, _ := storage.New(tmpDir)
+
+			transactions, choice, err := New(tmpDir, store).parseCSVFile(csvPath, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if choice.convention != tt.convention || choice.overridden {
+				t.Errorf("convention = %q (%s), want detected %q", choice.convention, choice.reason, tt.convention)
+			}
+			if len(transactions) != len(tt.amounts) {
+				t.Fatalf("got %d transactions, want %d", len(transactions), len(tt.amounts))
+			}
+			for i, want := range tt.amounts {
+				if transactions[i].Amount != want {
+					t.Errorf("row %d amount = %.2f, want %.2f", i+1, transactions[i].Amount, want)
+				}
+				if transactions[i].Hash != transactions[i].ComputeHash() {
+					t.Errorf("row %d hash doesn't match its signed amount", i+1)
+				}
+				if tt.categories != nil && transactions[i].Category != tt.categories[i] {
+					t.Errorf("row %d category = %q, want %q", i+1, transactions[i].Category, tt.categories[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSignOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"card.csv":  "Date,Description,Amount\n01/15/2024,AMAZON MKTPLACE,50.00\n01/16/2024,STARBUCKS,6.45\n",
+		"check.qif": "!Type:Bank\nD01/15/2024\nT12.50\nPCoffee\n^\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+	signs := fixedSigns{}
+	loader.SetSignOverrides(signs)
+
+	// Every amount positive is ambiguous: lenient loads keep them as income,
+	// strict loads reject the file until the user picks a convention
+	loader.SetStrict(true)
+	if _, err := loader.loadCSVFile(filepath.Join(tmpDir, "card.csv")); err == nil {
+		t.Fatal("strict load of an all-positive file should fail without a chosen convention")
+	}
+	v1, _ := loader.DataVersion()
+	fp1, _ := loader.fingerprint(filepath.Join(tmpDir, "card.csv"))
+
+	signs["card.csv"] = models.SignDebitsPositive
+	signs["check.qif"] = models.SignDebitsPositive
+	if v2, _ := loader.DataVersion(); v2 == v1 {
+		t.Error("data version should change with the chosen conventions")
+	}
+	if fp2, _ := loader.fingerprint(filepath.Join(tmpDir, "card.csv")); fp2 == fp1 {
+		t.Error("stored rows should be parsed again when a file's convention changes")
+	}
+
+	data, err := loader.LoadData()
+	if err != nil {
+		t.Fatalf("LoadData failed: %v", err)
+	}
+	if data.Len() != 3 {
+		t.Fatalf("loaded %d transactions, want 3", data.Len())
+	}
+	for _, txn := range data.Transactions {
+		if txn.Amount >= 0 || txn.TransactionType != models.Outflow {
+			t.Errorf("%s %s = %.2f (%s), want an outflow", txn.SourceFile, txn.Description, txn.Amount, txn.TransactionType)
+		}
+	}
+
+	infos, _ := loader.GetFileInfo()
+	for _, info := range infos {
+		if info.SignConvention != models.SignDebitsPositive || !info.SignOverridden {
+			t.Errorf("%s: convention %q overridden %v", info.Name, info.SignConvention, info.SignOverridden)
+		}
+	}
+
+	// A type column convention on a file without one is reported
+	signs["card.csv"] = models.SignTypeColumn
+	if _, err := loader.loadCSVFile(filepath.Join(tmpDir, "card.csv")); err == nil {
+		t.Error("strict load should reject a type column convention without a type column")
+	}
+}
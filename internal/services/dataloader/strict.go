@@ -0,0 +1,96 @@
+package dataloader
+
+import (
+	"fmt"
+	"strings"
+
+	"budget2/internal/models"
+)
+
+// maxIssuesInError caps how many problems a StrictError's message lists;
+// Issues keeps them all
+const maxIssuesInError = 5
+
+// StrictError rejects a data file in strict mode, listing every problem
+// found in it
+type StrictError struct {
+	File   string
+	Issues []models.LoadIssue
+}
+
+func (e *StrictError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "strict loading rejected %s: %d problem", e.File, len(e.Issues))
+	if len(e.Issues) != 1 {
+		sb.WriteString("s")
+	}
+	for i, issue := range e.Issues {
+		if i == maxIssuesInError {
+			fmt.Fprintf(&sb, "; and %d more", len(e.Issues)-i)
+			break
+		}
+		sb.WriteString("; ")
+		sb.WriteString(describeIssue(issue))
+	}
+	return sb.String()
+}
+
+// describeIssue formats an issue as "line 4, column Amount: message"
+func describeIssue(issue models.LoadIssue) string {
+	var where []string
+	if issue.Line > 0 {
+		where = append(where, fmt.Sprintf("line %d", issue.Line))
+	}
+	if issue.Column != "" {
+		where = append(where, "column "+issue.Column)
+	}
+	if len(where) == 0 {
+		return issue.Message
+	}
+	return strings.Join(where, ", ") + ": " + issue.Message
+}
+
+// issueLog collects the problems strict loading reports. Lenient loads use
+// a nil log, which ignores them.
+type issueLog struct {
+	issues []models.LoadIssue
+}
+
+func (l *issueLog) add(line int, column, value, format string, args ...interface{}) {
+	if l == nil {
+		return
+	}
+	l.issues = append(l.issues, models.LoadIssue{
+		Line:    line,
+		Column:  column,
+		Value:   value,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// err returns a StrictError for file if any problems were logged
+func (l *issueLog) err(file string) error {
+	if l == nil || len(l.issues) == 0 {
+		return nil
+	}
+	return &StrictError{File: file, Issues: l.issues}
+}
+
+// newIssueLog returns a log for one file, or nil when loading leniently
+func (dl *DataLoader) newIssueLog() *issueLog {
+	if !dl.strict {
+		return nil
+	}
+	return &issueLog{}
+}
+
+// LoadFailures returns the enabled files strict loading rejected, by name,
+// loading them first if the data has changed
+func (dl *DataLoader) LoadFailures() (map[string]*StrictError, error) {
+	if _, err := dl.LoadData(); err != nil {
+		return nil, err
+	}
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	return dl.cachedFailed, nil
+}
@@ -0,0 +1,161 @@
+package dataloader
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"budget2/internal/services/storage"
+)
+
+// strictLoader returns a strict loader over files written to a temp dir
+func strictLoader(t *testing.T, files map[string]string) (*DataLoader, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+	loader.SetStrict(true)
+	return loader, tmpDir
+}
+
+func TestStrictLoadingIssues(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []string // Issue messages, in order
+		lenient int      // Rows a lenient load keeps
+	}{
+		{
+			name: "clean file",
+			csv: `Date,Description,Amount,Category
+2024-01-15,Grocery Store,-50.00,Groceries
+2024-01-16,Paycheck,3000.00,Income`,
+			lenient: 2,
+		},
+		{
+			name: "unmapped and duplicate columns",
+			csv: `Transaction Date,Post Date,Description,Amount,Reference
+01/15/2024,01/16/2024,Grocery Store,-50.00,A1
+01/16/2024,01/17/2024,Paycheck,3000.00,A2`,
+			want: []string{
+				`column also maps to Date, which is read from column "Transaction Date"`,
+				"column doesn't map to Date, Description, Amount, Category, Debit, Credit, Account or Balance",
+			},
+			lenient: 2,
+		},
+		{
+			name: "unparseable rows",
+			csv: `Date,Description,Amount
+2024-01-15,Grocery Store,-50.00
+2024-13-45,Bad Date,-10.00
+2024-01-17,Bad Amount,twelve
+2024-01-18,,-5.00
+2024-01-19,Paycheck,3000.00`,
+			want:    []string{"unrecognized date", "unrecognized amount", "row has no description"},
+			lenient: 4,
+		},
+		{
+			name: "all amounts positive",
+			csv: `Date,Description,Amount
+01/15/2024,AMAZON MKTPLACE,50.00
+01/16/2024,STARBUCKS,6.45`,
+			want:    []string{"every amount is positive"},
+			lenient: 2,
+		},
+		{
+			name: "ambiguous debit and credit",
+			csv: `Posted Date,Details,Debit,Credit
+2024-01-15,Grocery Store,50.00,
+2024-01-16,Refund,-20.00,
+2024-01-17,Transfer,10.00,10.00
+2024-01-18,Nothing,,`,
+			want: []string{
+				"negative debit, so the direction of the money is ambiguous",
+				"row has both a debit and a credit amount",
+				"row has neither a debit nor a credit amount",
+			},
+			lenient: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader, tmpDir := strictLoader(t, map[string]string{"test.csv": tt.csv})
+			csvPath := filepath.Join(tmpDir, "test.csv")
+
+			transactions, err := loader.loadCSVFile(csvPath)
+			var strictErr *StrictError
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			} else {
+				if !errors.As(err, &strictErr) {
+					t.Fatalf("expected a StrictError, got %v (%d transactions)", err, len(transactions))
+				}
+				if len(strictErr.Issues) != len(tt.want) {
+					t.Fatalf("got %d issues, want %d: %v", len(strictErr.Issues), len(tt.want), strictErr)
+				}
+				for i, want := range tt.want {
+					if !strings.Contains(strictErr.Issues[i].Message, want) {
+						t.Errorf("issue %d = %q, want %q", i, strictErr.Issues[i].Message, want)
+					}
+				}
+			}
+
+			loader.SetStrict(false)
+			transactions, err = loader.loadCSVFile(csvPath)
+			if err != nil || len(transactions) != tt.lenient {
+				t.Errorf("lenient load = %d transactions, %v; want %d", len(transactions), err, tt.lenient)
+			}
+		})
+	}
+}
+
+func TestStrictErrorMessage(t *testing.T) {
+	loader, tmpDir := strictLoader(t, map[string]string{"test.csv": `Date,Description,Amount
+2024-13-45,Bad Date,-10.00
+2024-01-17,Bad Amount,twelve`})
+
+	_, err := loader.loadCSVFile(filepath.Join(tmpDir, "test.csv"))
+	want := `strict loading rejected test.csv: 2 problems; line 2, column Date: unrecognized date; line 3, column Amount: unrecognized amount`
+	if err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %q", err, want)
+	}
+}
+
+func TestStrictLoadingRejectsWholeFile(t *testing.T) {
+	loader, _ := strictLoader(t, map[string]string{
+		"good.csv": "Date,Description,Amount\n2024-01-15,Grocery Store,-50.00\n2024-01-16,Paycheck,3000.00\n",
+		"bad.csv":  "Date,Description,Amount\n2024-01-15,Coffee,-5.00\n2024-01-16,Lunch,twelve\n",
+		"bad.qif":  "!Type:Bank\nD01/15/2024\nT-12.x\nPCoffee\n^\n",
+	})
+
+	data, err := loader.LoadData()
+	if err != nil {
+		t.Fatalf("LoadData failed: %v", err)
+	}
+	if data.Len() != 2 {
+		t.Errorf("loaded %d transactions, want only good.csv's 2", data.Len())
+	}
+
+	failed, _ := loader.LoadFailures()
+	if len(failed) != 2 || failed["bad.csv"] == nil || failed["bad.qif"] == nil {
+		t.Fatalf("failures = %v, want bad.csv and bad.qif", failed)
+	}
+
+	infos, _ := loader.GetFileInfo()
+	for _, info := range infos {
+		rejected := info.Name != "good.csv"
+		if rejected != (len(info.Issues) > 0) || rejected != (info.LoadError != "") {
+			t.Errorf("%s: issues %v, error %q", info.Name, info.Issues, info.LoadError)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package dataloader
+
+import (
+	"log"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch uses filesystem notifications on CSVDirectory to tell when data
+// files change, so DataVersion and LoadData stop statting every file on each
+// call and only look again after a change. It only sees local files; remote
+// storage backends keep checking on every call. Call stop to end watching.
+func (dl *DataLoader) Watch() (stop func(), err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(dl.CSVDirectory); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	dl.mu.Lock()
+	dl.watching = true
+	dl.mu.Unlock()
+	dl.Invalidate()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if IsDataFile(event.Name) {
+					dl.Invalidate()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				// Events may have been dropped, so don't trust the fingerprint
+				log.Printf("Warning: data directory watcher: %v", err)
+				dl.Invalidate()
+			}
+		}
+	}()
+
+	return func() {
+		dl.mu.Lock()
+		dl.watching = false
+		dl.mu.Unlock()
+		dl.Invalidate()
+		watcher.Close()
+		<-done
+	}, nil
+}
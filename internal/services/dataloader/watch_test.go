@@ -0,0 +1,70 @@
+package dataloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"budget2/internal/services/storage"
+)
+
+func TestWatchInvalidatesOnFileChange(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+
+	os.WriteFile(filepath.Join(tmpDir, "checking.csv"), []byte("Date,Description,Amount\n2024-01-15,Store,-5.00\n"), 0644)
+
+	stop, err := loader.Watch()
+	if err != nil {
+		t.Skipf("filesystem notifications unavailable: %v", err)
+	}
+	defer stop()
+
+	ts, err := loader.LoadData()
+	if err != nil || ts.Len() != 1 {
+		t.Fatalf("LoadData = %v rows, err %v; want 1", ts.Len(), err)
+	}
+	v1, _ := loader.DataVersion()
+
+	// Non-data files don't invalidate
+	os.WriteFile(filepath.Join(tmpDir, "notes.txt"), []byte("hello"), 0644)
+	time.Sleep(50 * time.Millisecond)
+	if v, _ := loader.DataVersion(); v != v1 {
+		t.Errorf("version changed for a non-data file: %q then %q", v1, v)
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, "card.csv"), []byte("Date,Description,Amount\n2024-01-20,Gas,-40.00\n"), 0644)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if v, _ := loader.DataVersion(); v != v1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("version did not change after a data file was added")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if ts, _ := loader.LoadData(); ts.Len() != 2 {
+		t.Errorf("LoadData after change = %d rows, want 2", ts.Len())
+	}
+}
+
+func TestInvalidateWithoutWatcher(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := storage.New(tmpDir)
+	loader := New(tmpDir, store)
+
+	// Without a watcher every call checks the files, so Invalidate is a no-op
+	v1, _ := loader.DataVersion()
+	os.WriteFile(filepath.Join(tmpDir, "checking.csv"), []byte("Date,Description,Amount\n2024-01-15,Store,-5.00\n"), 0644)
+	if v2, _ := loader.DataVersion(); v2 == v1 {
+		t.Error("version should change without a watcher")
+	}
+	loader.Invalidate()
+	if v3, _ := loader.DataVersion(); v3 == v1 {
+		t.Error("version should still reflect the new file after Invalidate")
+	}
+}
@@ -0,0 +1,232 @@
+package giving
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// givingFile holds the giving categories
+var givingFile = storage.SettingsFile("giving.json", func() interface{} { return &[]models.GivingCategory{} })
+
+// DeductibleKeywords mark charitable donations in a description or category
+// (lowercase)
+var DeductibleKeywords = []string{
+	"charit", "donation", "donate", "church", "tithe", "nonprofit",
+	"red cross", "united way", "salvation army",
+}
+
+// GiftKeywords mark personal gifts, which are not deductible (lowercase)
+var GiftKeywords = []string{"gift"}
+
+// NotGiftKeywords are purchases that mention gifts but aren't giving (lowercase)
+var NotGiftKeywords = []string{"gift card", "giftcard", "gift shop"}
+
+// Manager persists which categories count as giving
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing giving categories in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, givingFile),
+		store: store,
+	}
+}
+
+// List returns the giving categories sorted by name
+func (m *Manager) List() ([]models.GivingCategory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Set counts a category as giving, replacing any earlier setting for it
+func (m *Manager) Set(c models.GivingCategory) ([]models.GivingCategory, error) {
+	c.Category = strings.TrimSpace(c.Category)
+	if c.Category == "" {
+		return nil, fmt.Errorf("category is required")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.GivingCategory, 0, len(list)+1)
+	for _, existing := range list {
+		if !strings.EqualFold(existing.Category, c.Category) {
+			filtered = append(filtered, existing)
+		}
+	}
+	filtered = append(filtered, c)
+	sort.Slice(filtered, func(i, j int) bool {
+		return strings.ToLower(filtered[i].Category) < strings.ToLower(filtered[j].Category)
+	})
+
+	if err := m.store.WriteJSON(m.path, filtered); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// Remove stops counting a category as giving
+func (m *Manager) Remove(category string) ([]models.GivingCategory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.GivingCategory, 0, len(list))
+	for _, c := range list {
+		if !strings.EqualFold(c.Category, category) {
+			filtered = append(filtered, c)
+		}
+	}
+
+	if err := m.store.WriteJSON(m.path, filtered); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// loadInternal reads giving categories without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() ([]models.GivingCategory, error) {
+	var list []models.GivingCategory
+	if err := m.store.ReadJSON(m.path, &list); err != nil {
+		if os.IsNotExist(err) {
+			return []models.GivingCategory{}, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+// containsAny checks if text contains any of the keywords
+func containsAny(text string, keywords []string) bool {
+	for _, kw := range keywords {
+		if strings.Contains(text, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// Classify reports whether an outflow is giving and whether it is tax
+// deductible. Configured categories decide first; otherwise the description
+// and category are checked for donation and gift keywords.
+func Classify(t *models.Transaction, categories []models.GivingCategory) (isGiving, deductible bool) {
+	if t.Amount >= 0 || t.TransactionType == models.Income {
+		return false, false
+	}
+
+	for _, c := range categories {
+		if strings.EqualFold(strings.TrimSpace(t.Category), c.Category) {
+			return true, c.Deductible
+		}
+	}
+
+	text := strings.ToLower(t.Description + " | " + t.Category)
+	if containsAny(text, DeductibleKeywords) {
+		return true, true
+	}
+	if containsAny(text, GiftKeywords) && !containsAny(text, NotGiftKeywords) {
+		return true, false
+	}
+	return false, false
+}
+
+// Summarize totals giving in ts for a year, per recipient and split into the
+// tax-deductible subtotal and personal gifts. Year 0 means the latest year
+// with any giving.
+func Summarize(ts *models.TransactionSet, categories []models.GivingCategory, year int) *models.GivingSummary {
+	summary := &models.GivingSummary{
+		Years:      []int{},
+		Recipients: []models.GivingRecipient{},
+		Gifts:      []models.Gift{},
+	}
+
+	var all []models.Gift
+	seenYear := make(map[int]bool)
+	for _, t := range ts.Transactions {
+		isGiving, deductible := Classify(&t, categories)
+		if !isGiving {
+			continue
+		}
+		all = append(all, models.Gift{
+			Transaction: t,
+			Recipient:   strings.TrimSpace(t.Description),
+			Deductible:  deductible,
+		})
+		if !seenYear[t.Date.Year()] {
+			seenYear[t.Date.Year()] = true
+			summary.Years = append(summary.Years, t.Date.Year())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(summary.Years)))
+
+	if year == 0 && len(summary.Years) > 0 {
+		year = summary.Years[0]
+	}
+	summary.Year = year
+
+	recipients := make(map[string]*models.GivingRecipient)
+	var order []string
+	for _, g := range all {
+		if g.Date.Year() != year {
+			continue
+		}
+		amount := math.Abs(g.Amount)
+		summary.Gifts = append(summary.Gifts, g)
+		summary.Total += amount
+		summary.Count++
+		if g.Deductible {
+			summary.DeductibleTotal += amount
+		} else {
+			summary.GiftTotal += amount
+		}
+
+		// Deductible and non-deductible giving to the same recipient stay
+		// apart so each row's subtotal is unambiguous
+		key := fmt.Sprintf("%s|%t", strings.ToLower(g.Recipient), g.Deductible)
+		r, ok := recipients[key]
+		if !ok {
+			r = &models.GivingRecipient{Recipient: g.Recipient, Deductible: g.Deductible}
+			recipients[key] = r
+			order = append(order, key)
+		}
+		r.Total += amount
+		r.Count++
+		if g.Date.After(r.LastDate) {
+			r.LastDate = g.Date
+		}
+	}
+
+	for _, key := range order {
+		summary.Recipients = append(summary.Recipients, *recipients[key])
+	}
+	sort.SliceStable(summary.Recipients, func(i, j int) bool {
+		return summary.Recipients[i].Total > summary.Recipients[j].Total
+	})
+	sort.SliceStable(summary.Gifts, func(i, j int) bool {
+		return summary.Gifts[i].Date.Before(summary.Gifts[j].Date)
+	})
+	return summary
+}
@@ -0,0 +1,78 @@
+package giving
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func day(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+func givingData() *models.TransactionSet {
+	return models.NewTransactionSet([]models.Transaction{
+		{Date: day("2024-12-20"), Description: "RED CROSS", Amount: -100, Category: "Other", TransactionType: models.Outflow},
+		{Date: day("2025-03-01"), Description: "ST MARYS", Amount: -50, Category: "Tithe", TransactionType: models.Outflow},
+		{Date: day("2025-04-01"), Description: "St Marys", Amount: -50, Category: "Tithe", TransactionType: models.Outflow},
+		{Date: day("2025-05-10"), Description: "MOTHERS DAY GIFT", Amount: -75, Category: "Shopping", TransactionType: models.Outflow},
+		{Date: day("2025-06-01"), Description: "SCHOOL FUNDRAISER", Amount: -40, Category: "Kids", TransactionType: models.Outflow},
+		// Not giving: a gift card purchase and a gift received
+		{Date: day("2025-07-01"), Description: "AMAZON GIFT CARD", Amount: -25, Category: "Shopping", TransactionType: models.Outflow},
+		{Date: day("2025-08-01"), Description: "BIRTHDAY GIFT", Amount: 100, Category: "Gift", TransactionType: models.Income},
+	})
+}
+
+func TestSummarize(t *testing.T) {
+	cats := []models.GivingCategory{{Category: "kids", Deductible: true}}
+	summary := Summarize(givingData(), cats, 0)
+
+	if summary.Year != 2025 || len(summary.Years) != 2 || summary.Years[0] != 2025 {
+		t.Fatalf("year = %d of %v, want latest 2025", summary.Year, summary.Years)
+	}
+	if summary.Count != 4 || summary.Total != 215 || summary.DeductibleTotal != 140 || summary.GiftTotal != 75 {
+		t.Errorf("totals = %d gifts, %.2f total, %.2f deductible, %.2f gifts", summary.Count, summary.Total, summary.DeductibleTotal, summary.GiftTotal)
+	}
+	if len(summary.Recipients) != 3 || summary.Recipients[0].Recipient != "ST MARYS" || summary.Recipients[0].Count != 2 {
+		t.Errorf("recipients = %+v, want the church first with 2 gifts", summary.Recipients)
+	}
+	if summary.Gifts[0].Date.After(summary.Gifts[1].Date) {
+		t.Error("gifts should be listed oldest first")
+	}
+
+	if last := Summarize(givingData(), nil, 2024); last.Total != 100 || !last.Recipients[0].Deductible {
+		t.Errorf("2024 summary = %+v", last)
+	}
+}
+
+func TestConfiguredCategoryOverridesKeywords(t *testing.T) {
+	tx := models.Transaction{Description: "CHARITY AUCTION DINNER", Amount: -80, Category: "Dining Out", TransactionType: models.Outflow}
+	if isGiving, deductible := Classify(&tx, nil); !isGiving || !deductible {
+		t.Errorf("keyword match = %v, %v; want deductible giving", isGiving, deductible)
+	}
+	cats := []models.GivingCategory{{Category: "Dining Out", Deductible: false}}
+	if isGiving, deductible := Classify(&tx, cats); !isGiving || deductible {
+		t.Errorf("configured category = %v, %v; want non-deductible giving", isGiving, deductible)
+	}
+}
+
+func TestSetAndRemove(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	if _, err := manager.Set(models.GivingCategory{Category: " "}); err == nil {
+		t.Error("expected error for a blank category")
+	}
+	manager.Set(models.GivingCategory{Category: "Charity", Deductible: false})
+	list, err := manager.Set(models.GivingCategory{Category: "charity", Deductible: true})
+	if err != nil || len(list) != 1 || !list[0].Deductible {
+		t.Fatalf("Set = %+v, %v; want one deductible entry", list, err)
+	}
+	if list, _ = manager.Remove("CHARITY"); len(list) != 0 {
+		t.Errorf("Remove left %+v", list)
+	}
+}
@@ -0,0 +1,218 @@
+// Package journal writes transactions as plain-text accounting journals
+// for Beancount and Ledger. Each transaction becomes an entry with two
+// postings: one to the account it came from, under Assets or Liabilities by
+// account type, and one to its category under Expenses or Income. Both
+// formats use the same account names, so a journal from one tool can be
+// checked against the other's.
+package journal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"budget2/internal/models"
+)
+
+// Format is a plain-text accounting journal format
+type Format string
+
+const (
+	Beancount Format = "beancount"
+	Ledger    Format = "ledger"
+)
+
+// Currency is the commodity every amount is written in
+const Currency = "USD"
+
+// ParseFormat returns the format named by s
+func ParseFormat(s string) (Format, bool) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case Beancount, Ledger:
+		return f, true
+	}
+	return "", false
+}
+
+// Extension is the file extension journals in the format use
+func (f Format) Extension() string {
+	if f == Ledger {
+		return ".ledger"
+	}
+	return ".beancount"
+}
+
+// posting is one leg of an entry
+type posting struct {
+	account string
+	amount  float64
+}
+
+// entry is a transaction as its two postings
+type entry struct {
+	date        time.Time
+	description string
+	tags        []string
+	postings    [2]posting
+}
+
+// Write writes txns as a journal in format f, oldest first, opening (or
+// declaring, for Ledger) each account before its first use
+func Write(w io.Writer, f Format, txns []models.Transaction) error {
+	entries := make([]entry, len(txns))
+	opened := make(map[string]time.Time)
+	width := 0
+	for i, t := range txns {
+		e := entry{
+			date:        t.Date,
+			description: strings.Join(strings.Fields(t.Description), " "),
+			tags:        t.Tags,
+			postings: [2]posting{
+				{categoryAccount(t), 0 - t.Amount}, // Not -t.Amount, which writes a zero as -0.00
+				{fundingAccount(t), t.Amount},
+			},
+		}
+		for _, p := range e.postings {
+			if first, ok := opened[p.account]; !ok || t.Date.Before(first) {
+				opened[p.account] = t.Date
+			}
+			width = max(width, len(p.account))
+		}
+		entries[i] = e
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].date.Before(entries[j].date)
+	})
+
+	accounts := make([]string, 0, len(opened))
+	for a := range opened {
+		accounts = append(accounts, a)
+	}
+	sort.Strings(accounts)
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "; Exported from SimpleBudget: %d transactions", len(entries))
+	if len(entries) > 0 {
+		fmt.Fprintf(b, ", %s to %s", entries[0].date.Format("2006-01-02"), entries[len(entries)-1].date.Format("2006-01-02"))
+	}
+	b.WriteString("\n")
+
+	if f == Ledger {
+		writeLedger(b, entries, accounts, width)
+	} else {
+		writeBeancount(b, entries, accounts, opened, width)
+	}
+	return b.Flush()
+}
+
+func writeBeancount(b *bufio.Writer, entries []entry, accounts []string, opened map[string]time.Time, width int) {
+	fmt.Fprintf(b, "option \"operating_currency\" %q\n\n", Currency)
+	for _, a := range accounts {
+		fmt.Fprintf(b, "%s open %s %s\n", opened[a].Format("2006-01-02"), a, Currency)
+	}
+	for _, e := range entries {
+		fmt.Fprintf(b, "\n%s * \"%s\"", e.date.Format("2006-01-02"), beancountString(e.description))
+		for _, tag := range e.tags {
+			if tag = tagName(tag); tag != "" {
+				b.WriteString(" #" + tag)
+			}
+		}
+		b.WriteString("\n")
+		for _, p := range e.postings {
+			fmt.Fprintf(b, "  %-*s  %12.2f %s\n", width, p.account, p.amount, Currency)
+		}
+	}
+}
+
+func writeLedger(b *bufio.Writer, entries []entry, accounts []string, width int) {
+	for _, a := range accounts {
+		fmt.Fprintf(b, "account %s\n", a)
+	}
+	for _, e := range entries {
+		fmt.Fprintf(b, "\n%s * %s\n", e.date.Format("2006/01/02"), e.description)
+		var tags []string
+		for _, tag := range e.tags {
+			if tag = tagName(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) > 0 {
+			fmt.Fprintf(b, "    ; :%s:\n", strings.Join(tags, ":"))
+		}
+		for _, p := range e.postings {
+			fmt.Fprintf(b, "    %-*s  %12.2f %s\n", width, p.account, p.amount, Currency)
+		}
+	}
+}
+
+// fundingAccount names the account a transaction was paid from or into:
+// its account, or its file when it has none, under Liabilities for credit
+// cards and Assets otherwise
+func fundingAccount(t models.Transaction) string {
+	name := t.Account
+	if name == "" {
+		name = strings.TrimSuffix(t.SourceFile, filepath.Ext(t.SourceFile))
+	}
+	root := "Assets"
+	if t.AccountType == models.AccountCreditCard {
+		root = "Liabilities"
+	}
+	return root + ":" + component(name, "Unknown")
+}
+
+// categoryAccount names the account a transaction's category maps to:
+// under Income for income and Expenses for everything else
+func categoryAccount(t models.Transaction) string {
+	root := "Expenses"
+	if t.TransactionType == models.Income {
+		root = "Income"
+	}
+	return root + ":" + component(t.Category, "Uncategorized")
+}
+
+// component turns a name into an account name component both tools
+// accept: letters and digits, with runs of anything else as one dash, and
+// a capital first letter. Names without letters or digits get fallback.
+func component(name, fallback string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			if b.Len() == 0 {
+				r = unicode.ToUpper(r)
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return fallback
+	}
+	return b.String()
+}
+
+// tagName keeps the characters Beancount allows in a tag, replacing others
+// with a dash
+func tagName(tag string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_/.", r)) {
+			return r
+		}
+		return '-'
+	}, tag), "-")
+}
+
+// beancountString escapes s for a double-quoted Beancount string
+func beancountString(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,94 @@
+package journal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+)
+
+func testTransactions() []models.Transaction {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	return []models.Transaction{
+		{Date: date("2025-03-04"), Amount: -54.20, Description: `Corner  "Market"`, Category: "Food & Dining",
+			TransactionType: models.Outflow, SourceFile: "visa.csv", AccountType: models.AccountCreditCard, Tags: []string{"kids", "trip:2025"}},
+		{Date: date("2025-03-01"), Amount: 2500, Description: "Payroll", Category: "salary",
+			TransactionType: models.Income, SourceFile: "checking.csv", Account: "Joint Checking"},
+		{Date: date("2025-03-05"), Amount: 12.5, Description: "Refund", TransactionType: models.Outflow,
+			SourceFile: "visa.csv", AccountType: models.AccountCreditCard},
+	}
+}
+
+func TestWriteBeancount(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Beancount, testTransactions()); err != nil {
+		t.Fatal(err)
+	}
+	want := `; Exported from SimpleBudget: 3 transactions, 2025-03-01 to 2025-03-05
+option "operating_currency" "USD"
+
+2025-03-01 open Assets:Joint-Checking USD
+2025-03-04 open Expenses:Food-Dining USD
+2025-03-05 open Expenses:Uncategorized USD
+2025-03-01 open Income:Salary USD
+2025-03-04 open Liabilities:Visa USD
+
+2025-03-01 * "Payroll"
+  Income:Salary               -2500.00 USD
+  Assets:Joint-Checking        2500.00 USD
+
+2025-03-04 * "Corner \"Market\"" #kids #trip-2025
+  Expenses:Food-Dining           54.20 USD
+  Liabilities:Visa              -54.20 USD
+
+2025-03-05 * "Refund"
+  Expenses:Uncategorized        -12.50 USD
+  Liabilities:Visa               12.50 USD
+`
+	if buf.String() != want {
+		t.Errorf("journal =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteLedger(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Ledger, testTransactions()); err != nil {
+		t.Fatal(err)
+	}
+	want := `; Exported from SimpleBudget: 3 transactions, 2025-03-01 to 2025-03-05
+account Assets:Joint-Checking
+account Expenses:Food-Dining
+account Expenses:Uncategorized
+account Income:Salary
+account Liabilities:Visa
+
+2025/03/01 * Payroll
+    Income:Salary               -2500.00 USD
+    Assets:Joint-Checking        2500.00 USD
+
+2025/03/04 * Corner "Market"
+    ; :kids:trip-2025:
+    Expenses:Food-Dining           54.20 USD
+    Liabilities:Visa              -54.20 USD
+
+2025/03/05 * Refund
+    Expenses:Uncategorized        -12.50 USD
+    Liabilities:Visa               12.50 USD
+`
+	if buf.String() != want {
+		t.Errorf("journal =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, ok := ParseFormat(" Ledger "); !ok || f != Ledger || f.Extension() != ".ledger" {
+		t.Errorf("ParseFormat(Ledger) = %q, %v", f, ok)
+	}
+	if _, ok := ParseFormat("qif"); ok {
+		t.Error("qif isn't a journal format")
+	}
+}
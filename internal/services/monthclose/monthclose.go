@@ -0,0 +1,334 @@
+package monthclose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/analytics"
+	"budget2/internal/services/storage"
+)
+
+// closesFile holds the closed months
+var closesFile = storage.SettingsFile("month_closes.json", func() interface{} { return &[]models.MonthClose{} })
+
+// MaxMonths is how many recent months the close view lists. Closed months
+// are always listed so changes to them stay visible.
+const MaxMonths = 12
+
+// manualItems are the checklist steps ticked by hand, in display order
+var manualItems = []struct {
+	key   string
+	label string
+}{
+	{models.CloseItemReconciled, "Reconciled with statements"},
+	{models.CloseItemBudgetReviewed, "Budget reviewed"},
+}
+
+// Manager persists month close state
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing month closes in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, closesFile),
+		store: store,
+	}
+}
+
+// ParseMonth validates a YYYY-MM month and returns its first day
+func ParseMonth(month string) (time.Time, error) {
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("month must be YYYY-MM, got %q", month)
+	}
+	return start, nil
+}
+
+// List returns the saved close state of every month that has any
+func (m *Manager) List() ([]models.MonthClose, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// SetItem ticks or clears a manual checklist item. Closed months are locked
+// and must be reopened first.
+func (m *Manager) SetItem(month, item string, done bool) error {
+	if _, err := ParseMonth(month); err != nil {
+		return err
+	}
+	if !isManualItem(item) {
+		return fmt.Errorf("unknown checklist item %q", item)
+	}
+
+	return m.update(month, func(c *models.MonthClose) error {
+		if c.Closed {
+			return fmt.Errorf("%s is closed; reopen it to change the checklist", month)
+		}
+		if c.Checklist == nil {
+			c.Checklist = make(map[string]bool)
+		}
+		c.Checklist[item] = done
+		return nil
+	})
+}
+
+// Close locks a month, keeping snapshot as its closing figures
+func (m *Manager) Close(month string, snapshot models.MonthSnapshot, now time.Time) error {
+	if _, err := ParseMonth(month); err != nil {
+		return err
+	}
+
+	return m.update(month, func(c *models.MonthClose) error {
+		if c.Closed {
+			return fmt.Errorf("%s is already closed", month)
+		}
+		c.Closed = true
+		c.ClosedAt = now
+		c.Snapshot = &snapshot
+		return nil
+	})
+}
+
+// Reopen unlocks a closed month, discarding its snapshot. The checklist is
+// kept so a quick fix doesn't mean starting over.
+func (m *Manager) Reopen(month string) error {
+	if _, err := ParseMonth(month); err != nil {
+		return err
+	}
+
+	return m.update(month, func(c *models.MonthClose) error {
+		c.Closed = false
+		c.ClosedAt = time.Time{}
+		c.Snapshot = nil
+		return nil
+	})
+}
+
+// update applies fn to a month's entry, creating it if needed, and saves
+func (m *Manager) update(month string, fn func(c *models.MonthClose) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return err
+	}
+
+	idx := -1
+	for i := range list {
+		if list[i].Month == month {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		list = append(list, models.MonthClose{Month: month, Checklist: map[string]bool{}})
+		idx = len(list) - 1
+	}
+	if err := fn(&list[idx]); err != nil {
+		return err
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Month < list[j].Month
+	})
+	return m.store.WriteJSON(m.path, list)
+}
+
+// loadInternal reads month closes without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() ([]models.MonthClose, error) {
+	var list []models.MonthClose
+	if err := m.store.ReadJSON(m.path, &list); err != nil {
+		if os.IsNotExist(err) {
+			return []models.MonthClose{}, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+func isManualItem(item string) bool {
+	for _, mi := range manualItems {
+		if mi.key == item {
+			return true
+		}
+	}
+	return false
+}
+
+// TakeSnapshot captures the metrics and transaction hashes of one month's data
+func TakeSnapshot(ts *models.TransactionSet) models.MonthSnapshot {
+	metrics := analytics.CalculateMetrics(ts)
+	snap := models.MonthSnapshot{
+		TotalIncome:       metrics.TotalIncome,
+		TotalExpenses:     metrics.TotalExpenses,
+		NetSavings:        metrics.NetSavings,
+		SavingsRate:       metrics.SavingsRate,
+		TransactionCount:  metrics.TransactionCount,
+		CategoryTotals:    ts.FilterByType(models.Outflow).CategoryTotals(),
+		TransactionHashes: []string{},
+	}
+	for _, t := range ts.Transactions {
+		snap.TransactionHashes = append(snap.TransactionHashes, transactionHash(t))
+	}
+	return snap
+}
+
+// transactionHash returns the dedupe hash, computing it if the loader didn't
+func transactionHash(t models.Transaction) string {
+	if t.Hash != "" {
+		return t.Hash
+	}
+	return t.ComputeHash()
+}
+
+// MonthData returns the transactions dated within month
+func MonthData(ts *models.TransactionSet, month string) (*models.TransactionSet, error) {
+	start, err := ParseMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	return ts.FilterByDateRange(start, start.AddDate(0, 1, -1)), nil
+}
+
+// Evaluate checks the most recent months in ts against their saved close
+// state: the categorization step is derived from the data, and closed months
+// are compared with their snapshot to catch imports that changed them.
+func Evaluate(closes []models.MonthClose, ts *models.TransactionSet) models.MonthCloseSummary {
+	summary := models.MonthCloseSummary{Months: []models.MonthCloseStatus{}}
+
+	byMonth := ts.GroupByMonth()
+	saved := make(map[string]models.MonthClose, len(closes))
+	for _, c := range closes {
+		saved[c.Month] = c
+	}
+
+	var months []string
+	for month := range byMonth {
+		months = append(months, month)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(months)))
+	if len(months) > MaxMonths {
+		months = months[:MaxMonths]
+	}
+	listed := make(map[string]bool, len(months))
+	for _, month := range months {
+		listed[month] = true
+	}
+	for _, c := range closes {
+		if c.Closed && !listed[c.Month] {
+			months = append(months, c.Month)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(months)))
+
+	for _, month := range months {
+		start, err := ParseMonth(month)
+		if err != nil {
+			continue
+		}
+		data := byMonth[month]
+		if data == nil {
+			data = &models.TransactionSet{}
+		}
+
+		c := saved[month]
+		status := models.MonthCloseStatus{
+			Month:    month,
+			Label:    start.Format("Jan 2006"),
+			Start:    start.Format("2006-01-02"),
+			End:      start.AddDate(0, 1, -1).Format("2006-01-02"),
+			Current:  TakeSnapshot(data),
+			Closed:   c.Closed,
+			ClosedAt: c.ClosedAt,
+			Snapshot: c.Snapshot,
+		}
+
+		uncategorized := 0
+		for _, t := range data.Transactions {
+			if strings.TrimSpace(t.Category) == "" {
+				uncategorized++
+			}
+		}
+		item := models.CloseChecklistItem{
+			Key:   models.CloseItemCategorized,
+			Label: "Uncategorized cleared",
+			Done:  uncategorized == 0,
+			Auto:  true,
+		}
+		if uncategorized > 0 {
+			item.Detail = fmt.Sprintf("%d uncategorized", uncategorized)
+		}
+		status.Checklist = append(status.Checklist, item)
+		for _, mi := range manualItems {
+			status.Checklist = append(status.Checklist, models.CloseChecklistItem{
+				Key:   mi.key,
+				Label: mi.label,
+				Done:  c.Checklist[mi.key],
+			})
+		}
+
+		status.Ready = true
+		for _, item := range status.Checklist {
+			if !item.Done {
+				status.Ready = false
+			}
+		}
+
+		if c.Closed && c.Snapshot != nil {
+			status.Added, status.Removed = compareHashes(c.Snapshot.TransactionHashes, status.Current.TransactionHashes)
+			summary.ClosedCount++
+			if status.Modified() {
+				summary.ModifiedCount++
+			}
+		}
+
+		summary.Months = append(summary.Months, status)
+	}
+
+	return summary
+}
+
+// compareHashes counts hashes present only in current (added) and only in
+// closed (removed). Duplicates are counted, so a re-imported copy of an
+// existing transaction shows up as added.
+func compareHashes(closed, current []string) (added, removed int) {
+	counts := make(map[string]int, len(closed))
+	for _, h := range closed {
+		counts[h]++
+	}
+	for _, h := range current {
+		if counts[h] > 0 {
+			counts[h]--
+		} else {
+			added++
+		}
+	}
+	for _, n := range counts {
+		removed += n
+	}
+	return added, removed
+}
+
+// Modified returns the closed months whose transactions changed since closing
+func Modified(closes []models.MonthClose, ts *models.TransactionSet) []models.MonthCloseStatus {
+	var modified []models.MonthCloseStatus
+	for _, status := range Evaluate(closes, ts).Months {
+		if status.Modified() {
+			modified = append(modified, status)
+		}
+	}
+	return modified
+}
@@ -0,0 +1,107 @@
+package monthclose
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func txn(date, desc, category string, amount float64) models.Transaction {
+	d, _ := time.Parse("2006-01-02", date)
+	t := models.Transaction{Date: d, Description: desc, Category: category, Amount: amount, TransactionType: models.Outflow}
+	if amount > 0 {
+		t.TransactionType = models.Income
+	}
+	t.Hash = t.ComputeHash()
+	return t
+}
+
+func TestChecklistAndClose(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	data := models.NewTransactionSet([]models.Transaction{
+		txn("2025-03-01", "PAYROLL", "Paycheck", 3000),
+		txn("2025-03-02", "GROCERY", "", -100),
+	})
+
+	if err := manager.SetItem("2025-03", "nonsense", true); err == nil {
+		t.Error("expected error for unknown checklist item")
+	}
+	if err := manager.SetItem("March", models.CloseItemReconciled, true); err == nil {
+		t.Error("expected error for invalid month")
+	}
+	manager.SetItem("2025-03", models.CloseItemReconciled, true)
+	manager.SetItem("2025-03", models.CloseItemBudgetReviewed, true)
+
+	closes, _ := manager.List()
+	status := Evaluate(closes, data).Months[0]
+	if status.Ready {
+		t.Error("month with uncategorized transactions should not be ready")
+	}
+	if status.Checklist[0].Detail != "1 uncategorized" {
+		t.Errorf("categorized detail = %q", status.Checklist[0].Detail)
+	}
+
+	// Categorizing the grocery run completes the checklist
+	data.Transactions[1].Category = "Groceries"
+	status = Evaluate(closes, data).Months[0]
+	if !status.Ready {
+		t.Fatalf("checklist should be complete, got %+v", status.Checklist)
+	}
+
+	month, _ := MonthData(data, "2025-03")
+	if err := manager.Close("2025-03", TakeSnapshot(month), time.Now()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := manager.Close("2025-03", TakeSnapshot(month), time.Now()); err == nil {
+		t.Error("closing twice should fail")
+	}
+	if err := manager.SetItem("2025-03", models.CloseItemReconciled, false); err == nil {
+		t.Error("closed months should reject checklist changes")
+	}
+
+	if err := manager.Reopen("2025-03"); err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	closes, _ = manager.List()
+	if closes[0].Closed || closes[0].Snapshot != nil || !closes[0].Checklist[models.CloseItemReconciled] {
+		t.Errorf("reopen should unlock but keep the checklist, got %+v", closes[0])
+	}
+}
+
+func TestEvaluateDetectsLaterImports(t *testing.T) {
+	before := models.NewTransactionSet([]models.Transaction{
+		txn("2025-03-01", "PAYROLL", "Paycheck", 3000),
+		txn("2025-03-02", "GROCERY", "Groceries", -100),
+		txn("2025-04-02", "GROCERY", "Groceries", -90),
+	})
+	march, _ := MonthData(before, "2025-03")
+	closes := []models.MonthClose{{
+		Month:    "2025-03",
+		Closed:   true,
+		Snapshot: &models.MonthSnapshot{TransactionHashes: TakeSnapshot(march).TransactionHashes},
+	}}
+
+	summary := Evaluate(closes, before)
+	if len(summary.Months) != 2 || summary.Months[0].Month != "2025-04" {
+		t.Fatalf("months should be listed newest first, got %+v", summary.Months)
+	}
+	if summary.ModifiedCount != 0 {
+		t.Errorf("unchanged data reported as modified")
+	}
+
+	// A late import adds a March charge and drops the grocery run
+	after := models.NewTransactionSet([]models.Transaction{
+		txn("2025-03-01", "PAYROLL", "Paycheck", 3000),
+		txn("2025-03-28", "LATE FEE", "Fees", -25),
+		txn("2025-04-02", "GROCERY", "Groceries", -90),
+	})
+	status := Evaluate(closes, after).Months[1]
+	if !status.Modified() || status.Added != 1 || status.Removed != 1 {
+		t.Errorf("expected 1 added and 1 removed, got %+v", status)
+	}
+}
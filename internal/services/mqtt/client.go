@@ -0,0 +1,193 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// MQTT 3.1.1 control packet types, shifted into the fixed header's high nibble
+const (
+	packetConnect    = 1 << 4
+	packetConnAck    = 2 << 4
+	packetPublish    = 3 << 4
+	packetDisconnect = 14 << 4
+)
+
+// connectTimeout bounds dialing the broker and waiting for CONNACK
+const connectTimeout = 10 * time.Second
+
+// connAckErrors describe the CONNACK return codes that refuse a connection
+var connAckErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// Message is one retained publish
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+// BrokerConfig says where and as whom to connect
+type BrokerConfig struct {
+	Address  string // host:port, optionally prefixed with tcp:// or mqtt://
+	ClientID string
+	Username string
+	Password string
+}
+
+// address strips a URL scheme and adds the default MQTT port when missing
+func (c BrokerConfig) address() string {
+	addr := c.Address
+	for _, scheme := range []string{"tcp://", "mqtt://"} {
+		addr = strings.TrimPrefix(addr, scheme)
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "1883")
+	}
+	return addr
+}
+
+// Publish connects to the broker, sends each message as a retained QoS 0
+// publish and disconnects. Metrics change rarely, so a short-lived
+// connection per refresh avoids keep-alives and reconnect handling.
+func Publish(cfg BrokerConfig, messages []Message) error {
+	conn, err := net.DialTimeout("tcp", cfg.address(), connectTimeout)
+	if err != nil {
+		return fmt.Errorf("connecting to MQTT broker: %w", err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(connectTimeout))
+	if _, err := conn.Write(connectPacket(cfg)); err != nil {
+		return fmt.Errorf("sending CONNECT: %w", err)
+	}
+
+	packetType, body, err := readPacket(bufio.NewReader(conn))
+	if err != nil {
+		return fmt.Errorf("reading CONNACK: %w", err)
+	}
+	if packetType != packetConnAck || len(body) != 2 {
+		return fmt.Errorf("expected CONNACK, got packet type %d", packetType>>4)
+	}
+	if code := body[1]; code != 0 {
+		if reason, ok := connAckErrors[code]; ok {
+			return fmt.Errorf("MQTT broker refused connection: %s", reason)
+		}
+		return fmt.Errorf("MQTT broker refused connection: code %d", code)
+	}
+
+	var buf bytes.Buffer
+	for _, m := range messages {
+		buf.Write(publishPacket(m))
+	}
+	buf.Write([]byte{packetDisconnect, 0})
+	if _, err := conn.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("publishing: %w", err)
+	}
+	return nil
+}
+
+// connectPacket builds a clean-session CONNECT with optional credentials
+func connectPacket(cfg BrokerConfig) []byte {
+	var body bytes.Buffer
+	writeString(&body, "MQTT")
+	body.WriteByte(4) // Protocol level 3.1.1
+
+	flags := byte(0x02) // Clean session
+	if cfg.Username != "" {
+		flags |= 0x80
+		if cfg.Password != "" {
+			flags |= 0x40
+		}
+	}
+	body.WriteByte(flags)
+	binary.Write(&body, binary.BigEndian, uint16(60)) // Keep-alive seconds
+
+	writeString(&body, cfg.ClientID)
+	if cfg.Username != "" {
+		writeString(&body, cfg.Username)
+		if cfg.Password != "" {
+			writeString(&body, cfg.Password)
+		}
+	}
+	return packet(packetConnect, body.Bytes())
+}
+
+// publishPacket builds a retained QoS 0 PUBLISH
+func publishPacket(m Message) []byte {
+	var body bytes.Buffer
+	writeString(&body, m.Topic)
+	body.Write(m.Payload)
+	return packet(packetPublish|0x01, body.Bytes())
+}
+
+// packet prefixes body with the fixed header for header
+func packet(header byte, body []byte) []byte {
+	out := []byte{header}
+	out = append(out, encodeLength(len(body))...)
+	return append(out, body...)
+}
+
+// encodeLength writes MQTT's variable-length remaining length: seven bits
+// per byte, high bit set while more bytes follow
+func encodeLength(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			return out
+		}
+	}
+}
+
+// writeString writes a length-prefixed UTF-8 string
+func writeString(buf *bytes.Buffer, s string) {
+	binary.Write(buf, binary.BigEndian, uint16(len(s)))
+	buf.WriteString(s)
+}
+
+// readPacket reads one control packet, returning its fixed header byte and body
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
@@ -0,0 +1,199 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"budget2/internal/services/dataloader"
+	"budget2/internal/services/storage"
+)
+
+// session is what a fake broker saw on one connection
+type session struct {
+	clientID, username string
+	published          map[string]string
+	retained           bool
+}
+
+// fakeBroker accepts connections, answering CONNECT with returnCode and
+// recording publishes until DISCONNECT
+func fakeBroker(t *testing.T, returnCode byte) (string, chan session) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	sessions := make(chan session, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				s := session{published: map[string]string{}, retained: true}
+
+				header, body, err := readPacket(r)
+				if err != nil || header != packetConnect {
+					return
+				}
+				s.clientID, s.username = parseConnect(body)
+				conn.Write([]byte{packetConnAck, 2, 0, returnCode})
+				if returnCode != 0 {
+					sessions <- s
+					return
+				}
+
+				for {
+					header, body, err := readPacket(r)
+					if err != nil || header == packetDisconnect {
+						break
+					}
+					if header&0xf0 == packetPublish {
+						s.retained = s.retained && header&0x01 != 0
+						n := binary.BigEndian.Uint16(body)
+						s.published[string(body[2:2+n])] = string(body[2+n:])
+					}
+				}
+				sessions <- s
+			}()
+		}
+	}()
+	return ln.Addr().String(), sessions
+}
+
+// parseConnect pulls the client ID and user name out of a CONNECT body
+func parseConnect(body []byte) (string, string) {
+	flags := body[7]
+	rest := body[10:]
+	next := func() string {
+		n := binary.BigEndian.Uint16(rest)
+		s := string(rest[2 : 2+n])
+		rest = rest[2+n:]
+		return s
+	}
+	clientID := next()
+	username := ""
+	if flags&0x80 != 0 {
+		username = next()
+	}
+	return clientID, username
+}
+
+func TestPublish(t *testing.T) {
+	addr, sessions := fakeBroker(t, 0)
+
+	err := Publish(BrokerConfig{Address: "tcp://" + addr, ClientID: "budget", Username: "ha", Password: "secret"}, []Message{
+		{Topic: "simplebudget/alert_count", Payload: []byte("2")},
+		{Topic: "simplebudget/state", Payload: []byte(strings.Repeat("x", 300))},
+	})
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	s := <-sessions
+	if s.clientID != "budget" || s.username != "ha" {
+		t.Errorf("CONNECT = client %q user %q, want budget/ha", s.clientID, s.username)
+	}
+	if s.published["simplebudget/alert_count"] != "2" {
+		t.Errorf("alert_count = %q, want 2", s.published["simplebudget/alert_count"])
+	}
+	// Payloads over 127 bytes need a two-byte remaining length
+	if len(s.published["simplebudget/state"]) != 300 {
+		t.Errorf("state payload length = %d, want 300", len(s.published["simplebudget/state"]))
+	}
+	if !s.retained {
+		t.Error("publishes should be retained")
+	}
+}
+
+func TestPublishRefused(t *testing.T) {
+	addr, _ := fakeBroker(t, 4)
+
+	err := Publish(BrokerConfig{Address: addr, ClientID: "budget"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "bad user name or password") {
+		t.Errorf("expected refused credentials error, got %v", err)
+	}
+}
+
+func TestBrokerAddress(t *testing.T) {
+	tests := map[string]string{
+		"broker.local":           "broker.local:1883",
+		"mqtt://broker.local":    "broker.local:1883",
+		"tcp://10.0.0.5:1884":    "10.0.0.5:1884",
+		"homeassistant.lan:1883": "homeassistant.lan:1883",
+	}
+	for in, want := range tests {
+		if got := (BrokerConfig{Address: in}).address(); got != want {
+			t.Errorf("address(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRefreshPublishesOnDataChange(t *testing.T) {
+	addr, sessions := fakeBroker(t, 0)
+
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	loader := dataloader.New(dir, store)
+	csvPath := filepath.Join(dir, "checking.csv")
+	csv := "Date,Description,Amount\n2024-03-01,Paycheck,3000.00\n2024-03-05,Grocery Store,-400.00\n2024-03-10,Electric,-100.00\n"
+	if err := os.WriteFile(csvPath, []byte(csv), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	p := NewPublisher(Config{
+		Broker:          BrokerConfig{Address: addr},
+		DiscoveryPrefix: "homeassistant",
+		MonthlyBudget:   2000,
+	}, loader, nil)
+
+	if err := p.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	s := <-sessions
+	if s.published["simplebudget/month_to_date_spend"] != "500.00" {
+		t.Errorf("month_to_date_spend = %q, want 500.00", s.published["simplebudget/month_to_date_spend"])
+	}
+	if s.published["simplebudget/budget_remaining"] != "1500.00" {
+		t.Errorf("budget_remaining = %q, want 1500.00", s.published["simplebudget/budget_remaining"])
+	}
+	if _, ok := s.published["simplebudget/alert_count"]; !ok {
+		t.Error("alert_count should be published")
+	}
+	config := s.published["homeassistant/sensor/simplebudget_budget_remaining/config"]
+	if !strings.Contains(config, `"state_topic":"simplebudget/budget_remaining"`) {
+		t.Errorf("discovery config missing state topic: %s", config)
+	}
+
+	// Unchanged data publishes nothing
+	if err := p.Refresh(); err != nil {
+		t.Fatalf("second Refresh failed: %v", err)
+	}
+	select {
+	case <-sessions:
+		t.Error("Refresh should not publish when data is unchanged")
+	default:
+	}
+
+	// New data publishes again
+	csv += "2024-03-12,Restaurant,-50.00\n"
+	if err := os.WriteFile(csvPath, []byte(csv), 0644); err != nil {
+		t.Fatalf("failed to rewrite test file: %v", err)
+	}
+	if err := p.Refresh(); err != nil {
+		t.Fatalf("third Refresh failed: %v", err)
+	}
+	if s := <-sessions; s.published["simplebudget/month_to_date_spend"] != "550.00" {
+		t.Errorf("month_to_date_spend after change = %q, want 550.00", s.published["simplebudget/month_to_date_spend"])
+	}
+}
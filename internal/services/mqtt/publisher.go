@@ -0,0 +1,171 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/analytics"
+	"budget2/internal/services/dataloader"
+	"budget2/internal/services/watchlist"
+)
+
+// Config holds the broker settings and what to publish
+type Config struct {
+	Broker          BrokerConfig
+	Topic           string        // Prefix for metric topics, e.g. "simplebudget"
+	DiscoveryPrefix string        // Home Assistant discovery prefix; empty skips discovery
+	Interval        time.Duration // How often to check for new data
+	MonthlyBudget   float64       // Zero budgets against average spending
+}
+
+// Metrics are the values published on each refresh
+type Metrics struct {
+	Status     *models.MonthStatus `json:"status"`
+	AlertCount int                 `json:"alert_count"`
+}
+
+// sensor describes one metric topic and its Home Assistant entity
+type sensor struct {
+	key, name, unit, icon string
+	value                 func(Metrics) float64
+}
+
+var sensors = []sensor{
+	{"month_to_date_spend", "Month-to-date spend", "USD", "mdi:cash-minus", func(m Metrics) float64 { return m.Status.Spent }},
+	{"budget_remaining", "Budget remaining", "USD", "mdi:wallet", func(m Metrics) float64 { return m.Status.Remaining }},
+	{"alert_count", "Spending alerts", "", "mdi:alert", func(m Metrics) float64 { return float64(m.AlertCount) }},
+	{"savings_rate", "Savings rate", "%", "mdi:piggy-bank", func(m Metrics) float64 { return m.Status.SavingsRate }},
+}
+
+// Publisher sends metrics to an MQTT broker whenever the data changes
+type Publisher struct {
+	cfg     Config
+	loader  *dataloader.DataLoader
+	watched *watchlist.Manager
+
+	lastVersion string
+}
+
+// NewPublisher creates a publisher; watched may be nil
+func NewPublisher(cfg Config, loader *dataloader.DataLoader, watched *watchlist.Manager) *Publisher {
+	if cfg.Topic == "" {
+		cfg.Topic = "simplebudget"
+	}
+	cfg.Topic = strings.Trim(cfg.Topic, "/")
+	if cfg.Broker.ClientID == "" {
+		cfg.Broker.ClientID = "simplebudget"
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	return &Publisher{cfg: cfg, loader: loader, watched: watched}
+}
+
+// Start checks for new data in a background goroutine, publishing once at
+// startup and again after every upload, edit or file change
+func (p *Publisher) Start() {
+	go func() {
+		for {
+			if err := p.Refresh(); err != nil {
+				log.Printf("Warning: MQTT publish failed: %v", err)
+			}
+			time.Sleep(p.cfg.Interval)
+		}
+	}()
+}
+
+// Refresh publishes the metrics if the data version has changed since the
+// last successful publish. Failures leave the version unrecorded so the
+// next check retries.
+func (p *Publisher) Refresh() error {
+	version, err := p.loader.DataVersion()
+	if err != nil {
+		return err
+	}
+	if version == p.lastVersion {
+		return nil
+	}
+
+	metrics, err := p.Collect()
+	if err != nil {
+		return err
+	}
+	if err := Publish(p.cfg.Broker, p.Messages(metrics)); err != nil {
+		return err
+	}
+
+	p.lastVersion = version
+	log.Printf("Published budget metrics to MQTT topic %s", p.cfg.Topic)
+	return nil
+}
+
+// Collect computes the latest month's metrics. Alerts are the dashboard's
+// anomaly alerts for the month plus any merchant watchlist alerts.
+func (p *Publisher) Collect() (Metrics, error) {
+	data, err := p.loader.LoadData()
+	if err != nil {
+		return Metrics{}, err
+	}
+
+	metrics := Metrics{Status: analytics.CalculateMonthStatus(data, p.cfg.MonthlyBudget)}
+	if end := data.MaxDate(); !end.IsZero() {
+		start := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, end.Location())
+		metrics.AlertCount = len(analytics.DetectAlerts(data.FilterByDateRange(start, end)))
+	}
+	if p.watched != nil {
+		if list, err := p.watched.List(); err == nil {
+			metrics.AlertCount += len(watchlist.Alerts(watchlist.Evaluate(list, data)))
+		}
+	}
+	return metrics, nil
+}
+
+// Messages lays out metrics as one topic per value plus a JSON state topic,
+// preceded by Home Assistant discovery configs when enabled
+func (p *Publisher) Messages(m Metrics) []Message {
+	var messages []Message
+
+	if p.cfg.DiscoveryPrefix != "" {
+		device := map[string]interface{}{
+			"identifiers": []string{p.cfg.Broker.ClientID},
+			"name":        "SimpleBudget",
+		}
+		for _, s := range sensors {
+			config := map[string]interface{}{
+				"name":        s.name,
+				"unique_id":   p.cfg.Broker.ClientID + "_" + s.key,
+				"state_topic": p.topic(s.key),
+				"icon":        s.icon,
+				"device":      device,
+			}
+			if s.unit != "" {
+				config["unit_of_measurement"] = s.unit
+			}
+			payload, _ := json.Marshal(config)
+			messages = append(messages, Message{
+				Topic:   fmt.Sprintf("%s/sensor/%s_%s/config", strings.Trim(p.cfg.DiscoveryPrefix, "/"), p.cfg.Broker.ClientID, s.key),
+				Payload: payload,
+			})
+		}
+	}
+
+	for _, s := range sensors {
+		messages = append(messages, Message{
+			Topic:   p.topic(s.key),
+			Payload: []byte(strconv.FormatFloat(s.value(m), 'f', 2, 64)),
+		})
+	}
+
+	state, _ := json.Marshal(m)
+	return append(messages, Message{Topic: p.topic("state"), Payload: state})
+}
+
+// topic returns the full topic for a metric
+func (p *Publisher) topic(key string) string {
+	return p.cfg.Topic + "/" + key
+}
@@ -0,0 +1,348 @@
+package networth
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// balancesFile holds the account balance snapshots
+var balancesFile = storage.SettingsFile("balances.json", func() interface{} { return &[]models.BalanceSnapshot{} })
+
+// Manager persists the account balances recorded by hand
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing balances in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, balancesFile),
+		store: store,
+	}
+}
+
+// List returns the recorded balances, newest first
+func (m *Manager) List() ([]models.BalanceSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Record saves an account's balance on a date, replacing any balance
+// recorded for the same account and date
+func (m *Manager) Record(s models.BalanceSnapshot) ([]models.BalanceSnapshot, error) {
+	s.Account = strings.TrimSpace(s.Account)
+	if s.Account == "" {
+		return nil, fmt.Errorf("account is required")
+	}
+	if _, err := time.Parse("2006-01-02", s.Date); err != nil {
+		return nil, fmt.Errorf("date must be YYYY-MM-DD, got %q", s.Date)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make([]models.BalanceSnapshot, 0, len(list)+1)
+	for _, existing := range list {
+		if !sameSnapshot(existing, s.Account, s.Date) {
+			kept = append(kept, existing)
+		}
+	}
+	kept = append(kept, s)
+	sortSnapshots(kept)
+
+	if err := m.store.WriteJSON(m.path, kept); err != nil {
+		return nil, err
+	}
+	return kept, nil
+}
+
+// Remove deletes the balance recorded for account on date
+func (m *Manager) Remove(account, date string) ([]models.BalanceSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make([]models.BalanceSnapshot, 0, len(list))
+	for _, existing := range list {
+		if !sameSnapshot(existing, strings.TrimSpace(account), date) {
+			kept = append(kept, existing)
+		}
+	}
+	if err := m.store.WriteJSON(m.path, kept); err != nil {
+		return nil, err
+	}
+	return kept, nil
+}
+
+// Balances returns the recorded balances merged with those in ts, oldest
+// first; see Balances
+func (m *Manager) Balances(defined []models.Account, ts *models.TransactionSet) ([]models.AccountBalance, error) {
+	snapshots, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	return Balances(snapshots, defined, ts), nil
+}
+
+// loadInternal reads the balances without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() ([]models.BalanceSnapshot, error) {
+	var list []models.BalanceSnapshot
+	if err := m.store.ReadJSON(m.path, &list); err != nil {
+		if os.IsNotExist(err) {
+			return []models.BalanceSnapshot{}, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+func sameSnapshot(s models.BalanceSnapshot, account, date string) bool {
+	return strings.EqualFold(s.Account, account) && s.Date == date
+}
+
+func sortSnapshots(list []models.BalanceSnapshot) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Date != list[j].Date {
+			return list[i].Date > list[j].Date
+		}
+		return strings.ToLower(list[i].Account) < strings.ToLower(list[j].Account)
+	})
+}
+
+// Balances merges the recorded balances with those read from the data's
+// balance columns into one balance per account and day, oldest first. A
+// recorded balance wins over the file's on the same day. Account types
+// come from the defined accounts, then from the account's transactions.
+func Balances(snapshots []models.BalanceSnapshot, defined []models.Account, ts *models.TransactionSet) []models.AccountBalance {
+	types := make(map[string]string)
+	for _, t := range ts.Transactions {
+		if t.Account != "" && t.AccountType != "" {
+			types[strings.ToLower(t.Account)] = t.AccountType
+		}
+	}
+	for _, a := range defined {
+		types[strings.ToLower(a.Name)] = a.Type
+	}
+
+	byDay := make(map[string]models.AccountBalance)
+	key := func(account, date string) string { return strings.ToLower(account) + "|" + date }
+	for account, days := range fileBalances(ts) {
+		for date, balance := range days {
+			byDay[key(account, date)] = models.AccountBalance{Account: account, Date: date, Balance: balance, Source: models.BalanceFile}
+		}
+	}
+	for _, s := range snapshots {
+		byDay[key(s.Account, s.Date)] = models.AccountBalance{Account: s.Account, Date: s.Date, Balance: s.Balance, Source: models.BalanceManual}
+	}
+
+	balances := make([]models.AccountBalance, 0, len(byDay))
+	for _, b := range byDay {
+		b.Type = types[strings.ToLower(b.Account)]
+		if b.Type == "" {
+			b.Type = models.AccountBank
+		}
+		b.Value = value(b.Type, b.Balance)
+		balances = append(balances, b)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		if balances[i].Date != balances[j].Date {
+			return balances[i].Date < balances[j].Date
+		}
+		return strings.ToLower(balances[i].Account) < strings.ToLower(balances[j].Account)
+	})
+	return balances
+}
+
+// value is what a balance adds to net worth. Card exports differ on
+// whether what's owed is positive or negative, so it's always a debt.
+func value(accountType string, balance float64) float64 {
+	if accountType == models.AccountCreditCard {
+		return -math.Abs(balance)
+	}
+	return balance
+}
+
+// fileBalances returns each account's end-of-day balance from the rows
+// with a running balance. Of a day's rows, the last is the one no other
+// row's balance follows from; when that doesn't single one out, the last
+// row loaded wins.
+func fileBalances(ts *models.TransactionSet) map[string]map[string]float64 {
+	type row struct{ amount, balance float64 }
+	days := make(map[string]map[string][]row)
+	names := make(map[string]string)
+	for _, t := range ts.Transactions {
+		if t.Balance == nil || t.Account == "" {
+			continue
+		}
+		account := strings.ToLower(t.Account)
+		names[account] = t.Account
+		if days[account] == nil {
+			days[account] = make(map[string][]row)
+		}
+		date := t.Date.Format("2006-01-02")
+		days[account][date] = append(days[account][date], row{t.Amount, *t.Balance})
+	}
+
+	result := make(map[string]map[string]float64)
+	for account, byDate := range days {
+		result[names[account]] = make(map[string]float64)
+		for date, rows := range byDate {
+			end := rows[len(rows)-1].balance
+			var last []float64
+			for i, r := range rows {
+				followed := false
+				for j, next := range rows {
+					if i != j && next.balance != r.balance &&
+						(cents(next.balance-next.amount) == cents(r.balance) || cents(next.balance+next.amount) == cents(r.balance)) {
+						followed = true
+						break
+					}
+				}
+				if !followed {
+					last = append(last, r.balance)
+				}
+			}
+			if len(last) == 1 {
+				end = last[0]
+			}
+			result[names[account]][date] = end
+		}
+	}
+	return result
+}
+
+func cents(v float64) int64 {
+	return int64(math.Round(v * 100))
+}
+
+// At returns net worth as of date: each account's latest balance on or
+// before it. balances must be oldest first, as Balances returns them.
+func At(balances []models.AccountBalance, date string) models.NetWorth {
+	latest := make(map[string]models.AccountBalance)
+	for _, b := range balances {
+		if b.Date <= date {
+			latest[strings.ToLower(b.Account)] = b
+		}
+	}
+
+	nw := models.NetWorth{Date: date, Accounts: []models.AccountBalance{}}
+	for _, b := range latest {
+		nw.Accounts = append(nw.Accounts, b)
+		nw.Total += b.Value
+		if b.Value >= 0 {
+			nw.Assets += b.Value
+		} else {
+			nw.Liabilities -= b.Value
+		}
+	}
+	sort.Slice(nw.Accounts, func(i, j int) bool {
+		return strings.ToLower(nw.Accounts[i].Account) < strings.ToLower(nw.Accounts[j].Account)
+	})
+	return nw
+}
+
+// Latest returns net worth as of the newest balance, and false without any
+func Latest(balances []models.AccountBalance) (models.NetWorth, bool) {
+	if len(balances) == 0 {
+		return models.NetWorth{}, false
+	}
+	return At(balances, balances[len(balances)-1].Date), true
+}
+
+// Chart builds stacked areas of each account's balance at the end of
+// every month, debts stacked below zero, with a line for the total. Accounts carry their last
+// balance forward until a new one is known.
+func Chart(balances []models.AccountBalance) map[string]interface{} {
+	var months, ends []string
+	if len(balances) > 0 {
+		first, _ := time.Parse("2006-01-02", balances[0].Date)
+		last := balances[len(balances)-1].Date[:7]
+		for m := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, time.UTC); m.Format("2006-01") <= last; m = m.AddDate(0, 1, 0) {
+			months = append(months, m.Format("2006-01"))
+			ends = append(ends, m.AddDate(0, 1, -1).Format("2006-01-02"))
+		}
+	}
+
+	var accounts []string
+	types := make(map[string]string)
+	series := make(map[string][]interface{})
+	totals := make([]float64, len(ends))
+	for i, end := range ends {
+		nw := At(balances, end)
+		totals[i] = nw.Total
+		for _, b := range nw.Accounts {
+			if series[b.Account] == nil {
+				accounts = append(accounts, b.Account)
+				series[b.Account] = make([]interface{}, len(ends))
+			}
+			types[b.Account] = b.Type
+			series[b.Account][i] = b.Value
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return strings.ToLower(accounts[i]) < strings.ToLower(accounts[j])
+	})
+
+	traces := []map[string]interface{}{}
+	for _, a := range accounts {
+		traces = append(traces, map[string]interface{}{
+			"type":       "scatter",
+			"mode":       "lines",
+			"name":       a,
+			"x":          months,
+			"y":          series[a],
+			"stackgroup": stackGroup(types[a]),
+		})
+	}
+	traces = append(traces, map[string]interface{}{
+		"type": "scatter",
+		"mode": "lines+markers",
+		"name": "Net Worth",
+		"x":    months,
+		"y":    totals,
+		"line": map[string]interface{}{
+			"color": "#6366f1",
+			"width": 3,
+		},
+	})
+
+	return map[string]interface{}{
+		"data": traces,
+		"layout": map[string]interface{}{
+			"yaxis": map[string]interface{}{
+				"title": "Balance ($)",
+			},
+		},
+	}
+}
+
+// stackGroup keeps debts in their own stack so they don't eat into the
+// assets' area
+func stackGroup(accountType string) string {
+	if accountType == models.AccountCreditCard {
+		return "liabilities"
+	}
+	return "assets"
+}
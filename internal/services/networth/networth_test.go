@@ -0,0 +1,95 @@
+package networth
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func txn(date, account string, amount, balance float64) models.Transaction {
+	d, _ := time.Parse("2006-01-02", date)
+	return models.Transaction{Date: d, Description: account, Amount: amount, Account: account, AccountType: models.AccountBank, Balance: &balance}
+}
+
+func TestRecordReplacesAndValidates(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	if _, err := manager.Record(models.BalanceSnapshot{Date: "2025-01-31", Balance: 100}); err == nil {
+		t.Error("expected error for missing account")
+	}
+	if _, err := manager.Record(models.BalanceSnapshot{Account: "Brokerage", Date: "01/31/2025", Balance: 100}); err == nil {
+		t.Error("expected error for a malformed date")
+	}
+
+	manager.Record(models.BalanceSnapshot{Account: "Brokerage", Date: "2025-01-31", Balance: 50000})
+	manager.Record(models.BalanceSnapshot{Account: "Brokerage", Date: "2025-02-28", Balance: 51000})
+	list, err := manager.Record(models.BalanceSnapshot{Account: "brokerage", Date: "2025-01-31", Balance: 49000})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if len(list) != 2 || list[0].Date != "2025-02-28" || list[1].Balance != 49000 {
+		t.Errorf("list = %+v, want February first and January replaced", list)
+	}
+
+	list, _ = manager.Remove("BROKERAGE", "2025-02-28")
+	if len(list) != 1 || list[0].Date != "2025-01-31" {
+		t.Errorf("Remove left %+v, want only January", list)
+	}
+}
+
+func TestBalancesAndNetWorth(t *testing.T) {
+	// Checking lists a day's rows newest first; the end-of-day balance is
+	// the one no other row follows from, whatever the order
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2025-01-10", "Checking", -200, 1800),
+		txn("2025-01-10", "Checking", 1000, 2000),
+		txn("2025-01-10", "Checking", -100, 1000),
+		txn("2025-02-03", "Checking", -300, 1500),
+		txn("2025-01-15", "Visa", -45, 345),
+	})
+	defined := []models.Account{{Name: "Visa", Type: models.AccountCreditCard}}
+	snapshots := []models.BalanceSnapshot{
+		{Account: "Brokerage", Date: "2025-01-31", Balance: 10000},
+		{Account: "Checking", Date: "2025-02-03", Balance: 1550}, // Wins over the file
+	}
+
+	balances := Balances(snapshots, defined, ts)
+	if len(balances) != 4 {
+		t.Fatalf("balances = %+v, want one per account and day", balances)
+	}
+	if b := balances[0]; b.Account != "Checking" || b.Balance != 1800 || b.Source != models.BalanceFile {
+		t.Errorf("first balance = %+v, want Checking's 1800 end of day", b)
+	}
+	if b := balances[1]; b.Account != "Visa" || b.Type != models.AccountCreditCard || b.Value != -345 {
+		t.Errorf("Visa = %+v, want a $345 debt", b)
+	}
+	if b := balances[3]; b.Balance != 1550 || b.Source != models.BalanceManual {
+		t.Errorf("last balance = %+v, want the recorded 1550", b)
+	}
+
+	jan := At(balances, "2025-01-31")
+	if jan.Total != 1800-345+10000 || jan.Assets != 11800 || jan.Liabilities != 345 || len(jan.Accounts) != 3 {
+		t.Errorf("January net worth = %+v", jan)
+	}
+	latest, ok := Latest(balances)
+	if !ok || latest.Date != "2025-02-03" || latest.Total != 1550-345+10000 {
+		t.Errorf("latest net worth = %+v", latest)
+	}
+	if _, ok := Latest(nil); ok {
+		t.Error("no balances should have no net worth")
+	}
+
+	chart := Chart(balances)
+	traces := chart["data"].([]map[string]interface{})
+	if len(traces) != 4 {
+		t.Fatalf("traces = %d, want three accounts and the total", len(traces))
+	}
+	total := traces[3]["y"].([]float64)
+	if len(total) != 2 || total[0] != jan.Total || total[1] != latest.Total {
+		t.Errorf("monthly totals = %v, want %v and %v", total, jan.Total, latest.Total)
+	}
+}
@@ -0,0 +1,273 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/analytics"
+	"budget2/internal/services/dataloader"
+	"budget2/internal/services/storage"
+)
+
+// settingsFile holds the notification settings
+var settingsFile = storage.SettingsFile("notifications.json", func() interface{} { return &Settings{} })
+
+// sentFile records which alerts were sent, and when
+var sentFile = storage.SettingsFile("notifications_sent.json", func() interface{} { return &map[string]time.Time{} })
+
+// Config holds the webhook to notify and how often to check for new data
+type Config struct {
+	URL      string
+	Format   string        // ntfy, slack or discord; empty guesses from the URL
+	Interval time.Duration // How often to check for new data
+}
+
+// AlertTypes are the alert types a threshold can be set for
+var AlertTypes = []string{"unusual_day", "large_transaction"}
+
+// Settings are the user's notification preferences
+type Settings struct {
+	// Thresholds are the smallest alert amount worth a notification, by
+	// alert type (unusual_day, large_transaction). Types not listed are
+	// always sent; a negative threshold turns a type off.
+	Thresholds map[string]float64 `json:"thresholds"`
+}
+
+// badThresholds lists the thresholds set for alert types that don't exist,
+// most likely misspellings, which would otherwise be silently ignored
+func (s Settings) badThresholds() []string {
+	var bad []string
+	for alertType := range s.Thresholds {
+		if !slices.Contains(AlertTypes, alertType) {
+			bad = append(bad, alertType)
+		}
+	}
+	sort.Strings(bad)
+	return bad
+}
+
+// CheckSettings refuses thresholds for unknown alert types
+func (s *Settings) CheckSettings() error {
+	if bad := s.badThresholds(); len(bad) > 0 {
+		return fmt.Errorf("thresholds for unknown alert types %s (known: %s)",
+			strings.Join(bad, ", "), strings.Join(AlertTypes, ", "))
+	}
+	return nil
+}
+
+// passes reports whether an alert is large enough to send on its own
+func (s Settings) passes(a models.SpendingAlert) bool {
+	threshold, ok := s.Thresholds[a.Type]
+	if !ok {
+		return true
+	}
+	return threshold >= 0 && a.Amount >= threshold
+}
+
+// Wants reports whether an alert, or one grouped under it, clears its
+// type's threshold
+func (s Settings) Wants(a models.SpendingAlert) bool {
+	if s.passes(a) {
+		return true
+	}
+	for _, r := range a.Related {
+		if s.passes(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Notifier posts new spending alerts to a webhook whenever the data changes
+type Notifier struct {
+	cfg          Config
+	loader       *dataloader.DataLoader
+	store        *storage.Storage
+	settingsPath string
+	sentPath     string
+	client       *http.Client
+
+	lastVersion string
+}
+
+// NewNotifier creates a notifier reading its settings from settingsDir
+func NewNotifier(cfg Config, loader *dataloader.DataLoader, settingsDir string, store *storage.Storage) *Notifier {
+	cfg.Format = strings.ToLower(strings.TrimSpace(cfg.Format))
+	if cfg.Format == "" {
+		cfg.Format = GuessFormat(cfg.URL)
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	return &Notifier{
+		cfg:          cfg,
+		loader:       loader,
+		store:        store,
+		settingsPath: filepath.Join(settingsDir, settingsFile),
+		sentPath:     filepath.Join(settingsDir, sentFile),
+		client:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Format returns the payload format the notifier sends
+func (n *Notifier) Format() string {
+	return n.cfg.Format
+}
+
+// Start checks for new data in a background goroutine, once at startup and
+// again after every upload, edit or file change
+func (n *Notifier) Start() {
+	go func() {
+		for {
+			if err := n.Refresh(); err != nil {
+				log.Printf("Warning: alert webhook failed: %v", err)
+			}
+			time.Sleep(n.cfg.Interval)
+		}
+	}()
+}
+
+// Settings returns the saved notification settings, or the defaults when
+// there are none
+func (n *Notifier) Settings() (Settings, error) {
+	var s Settings
+	if err := n.store.ReadJSON(n.settingsPath, &s); err != nil && !os.IsNotExist(err) {
+		return Settings{}, err
+	}
+	for _, alertType := range s.badThresholds() {
+		log.Printf("Warning: ignoring the %s threshold in %s: not an alert type (known: %s)",
+			alertType, settingsFile, strings.Join(AlertTypes, ", "))
+		delete(s.Thresholds, alertType)
+	}
+	return s, nil
+}
+
+// Alerts returns the dashboard's anomaly alerts for the latest month of ts
+func Alerts(ts *models.TransactionSet) []models.SpendingAlert {
+	end := ts.MaxDate()
+	if end.IsZero() {
+		return nil
+	}
+	start := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, end.Location())
+	return analytics.DetectAlerts(ts.FilterByDateRange(start, end))
+}
+
+// alertKey identifies an alert across data changes by its day. New data
+// moves the month's average, so a day can turn from an unusual day into a
+// large purchase or gain purchases grouped under it; keying by the day sends
+// it once either way.
+func alertKey(a models.SpendingAlert) string {
+	if a.Date == nil {
+		return a.Type
+	}
+	return a.Date.Format("2006-01-02")
+}
+
+// Refresh sends alerts not sent before if the data version has changed
+// since the last check. The first check with nothing sent yet only records
+// the existing alerts, so only ones that arrive with new data are sent.
+// Failures leave the version unrecorded so the next check retries.
+func (n *Notifier) Refresh() error {
+	version, err := n.loader.DataVersion()
+	if err != nil {
+		return err
+	}
+	if version == n.lastVersion {
+		return nil
+	}
+
+	data, err := n.loader.LoadData()
+	if err != nil {
+		return err
+	}
+	settings, err := n.Settings()
+	if err != nil {
+		return err
+	}
+
+	// Sent alerts by key, with the alert's date
+	sent := map[string]time.Time{}
+	seeded := true
+	if err := n.store.ReadJSON(n.sentPath, &sent); err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		seeded = false
+	}
+
+	alerts := Alerts(data)
+	var sendErr error
+	count := 0
+	for _, a := range alerts {
+		key := alertKey(a)
+		if _, ok := sent[key]; ok {
+			continue
+		}
+		if seeded {
+			if !settings.Wants(a) {
+				continue
+			}
+			if sendErr = n.Send(a); sendErr != nil {
+				break
+			}
+			count++
+		}
+		if a.Date != nil {
+			sent[key] = *a.Date
+		} else {
+			sent[key] = time.Time{}
+		}
+	}
+
+	// Alerts before the latest month can't come back
+	if end := data.MaxDate(); !end.IsZero() {
+		start := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, end.Location())
+		for key, date := range sent {
+			if !date.IsZero() && date.Before(start) {
+				delete(sent, key)
+			}
+		}
+	}
+	if err := n.store.WriteJSON(n.sentPath, sent); err != nil {
+		return err
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+
+	n.lastVersion = version
+	switch {
+	case !seeded:
+		log.Printf("Alert webhook: recorded %d existing alerts; only new ones will be sent", len(alerts))
+	case count > 0:
+		log.Printf("Alert webhook: sent %d new alerts", count)
+	}
+	return nil
+}
+
+// Send posts one alert to the webhook
+func (n *Notifier) Send(a models.SpendingAlert) error {
+	req, err := Request(n.cfg.URL, n.cfg.Format, a)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
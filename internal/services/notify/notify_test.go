@@ -0,0 +1,249 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/dataloader"
+	"budget2/internal/services/storage"
+)
+
+func TestGuessFormat(t *testing.T) {
+	tests := map[string]string{
+		"https://hooks.slack.com/services/T0/B0/xyz":     FormatSlack,
+		"https://discord.com/api/webhooks/1/abc":         FormatDiscord,
+		"https://ptb.discord.com/api/webhooks/1/abc":     FormatDiscord,
+		"https://ntfy.sh/my-budget":                      FormatNtfy,
+		"http://homeassistant.local:8123/api/webhook/ab": FormatNtfy,
+	}
+	for in, want := range tests {
+		if got := GuessFormat(in); got != want {
+			t.Errorf("GuessFormat(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRequest(t *testing.T) {
+	date := time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC)
+	alert := models.SpendingAlert{
+		Type:     "unusual_day",
+		Severity: "warning",
+		Title:    "High Spending Day",
+		Message:  "$1020 spent on Mar 12 (835% above average)",
+		Date:     &date,
+		Amount:   1020,
+		Transactions: []models.Transaction{
+			{Description: "FURNITURE STORE", Amount: -1000},
+			{Description: "COFFEE", Amount: -20},
+		},
+	}
+
+	req, err := Request("https://ntfy.sh/budget", FormatNtfy, alert)
+	if err != nil {
+		t.Fatalf("ntfy: %v", err)
+	}
+	body, _ := io.ReadAll(req.Body)
+	if req.Header.Get("Title") != "SimpleBudget: High Spending Day" || req.Header.Get("Priority") != "high" {
+		t.Errorf("ntfy headers = %v", req.Header)
+	}
+	if !strings.Contains(string(body), "$1000.00 FURNITURE STORE") {
+		t.Errorf("ntfy body = %q, want the day's transactions", body)
+	}
+
+	req, _ = Request("https://hooks.slack.com/services/x", FormatSlack, alert)
+	var slack struct{ Text string }
+	json.NewDecoder(req.Body).Decode(&slack)
+	if !strings.HasPrefix(slack.Text, "*SimpleBudget: High Spending Day*\n$1020 spent") {
+		t.Errorf("slack text = %q", slack.Text)
+	}
+
+	req, _ = Request("https://discord.com/api/webhooks/x", FormatDiscord, alert)
+	var discord struct {
+		Embeds []struct {
+			Title     string
+			Timestamp string
+		}
+	}
+	json.NewDecoder(req.Body).Decode(&discord)
+	if len(discord.Embeds) != 1 || discord.Embeds[0].Title != "SimpleBudget: High Spending Day" || discord.Embeds[0].Timestamp != "2024-03-12T00:00:00Z" {
+		t.Errorf("discord embeds = %+v", discord.Embeds)
+	}
+
+	if _, err := Request("https://example.com", "teams", alert); err == nil {
+		t.Error("expected error for an unknown format")
+	}
+}
+
+func TestWants(t *testing.T) {
+	large := models.SpendingAlert{Type: "large_transaction", Amount: 800}
+	day := models.SpendingAlert{Type: "unusual_day", Amount: 900, Related: []models.SpendingAlert{large}}
+
+	s := Settings{Thresholds: map[string]float64{"unusual_day": 1000, "large_transaction": 500}}
+	if !s.Wants(large) {
+		t.Error("a $800 purchase should clear a $500 threshold")
+	}
+	if !s.Wants(day) {
+		t.Error("a day under its threshold should be sent for a purchase grouped under it")
+	}
+
+	s.Thresholds["large_transaction"] = -1
+	if s.Wants(large) || s.Wants(day) {
+		t.Error("a negative threshold should turn the type off")
+	}
+	if !(Settings{}).Wants(day) {
+		t.Error("with no thresholds every alert should be sent")
+	}
+}
+
+func TestSettingsIgnoreUnknownAlertTypes(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	n := NewNotifier(Config{URL: "https://ntfy.sh/test"}, dataloader.New(dir, store), dir, store)
+
+	data := `{"thresholds":{"unusual_day":250,"large_purchase":100}}`
+	if err := os.WriteFile(filepath.Join(dir, "notifications.json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := n.Settings()
+	if err != nil {
+		t.Fatalf("Settings failed: %v", err)
+	}
+	if len(s.Thresholds) != 1 || s.Thresholds["unusual_day"] != 250 {
+		t.Errorf("Thresholds = %v, want only unusual_day: 250", s.Thresholds)
+	}
+
+	err = storage.CheckSettings("notifications.json", []byte(data))
+	if err == nil || !strings.Contains(err.Error(), "unknown alert types large_purchase") {
+		t.Errorf("CheckSettings() = %v, want the misspelled type refused", err)
+	}
+	if err := storage.CheckSettings("notifications.json", []byte(`{"thresholds":{"large_transaction":-1}}`)); err != nil {
+		t.Errorf("CheckSettings() = %v, want nil for known types", err)
+	}
+}
+
+func TestRefreshSendsNewAlerts(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("Title"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	loader := dataloader.New(dir, store)
+	settingsDir := filepath.Join(dir, "settings")
+	os.MkdirAll(settingsDir, 0755)
+
+	// Ten quiet days, then a $1,000 purchase on the 11th
+	csvPath := filepath.Join(dir, "checking.csv")
+	csv := "Date,Description,Amount\n"
+	for day := 1; day <= 10; day++ {
+		csv += fmt.Sprintf("2024-03-%02d,COFFEE,-20.00\n", day)
+	}
+	write := func(s string) {
+		if err := os.WriteFile(csvPath, []byte(s), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+	write(csv + "2024-03-11,FURNITURE STORE,-1000.00\n")
+
+	n := NewNotifier(Config{URL: server.URL}, loader, settingsDir, store)
+
+	// Alerts already there when notifications start aren't sent
+	if err := n.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if len(received) != 0 {
+		t.Fatalf("first Refresh sent %v, want nothing", received)
+	}
+
+	// A new large purchase is sent once
+	csv += "2024-03-11,FURNITURE STORE,-1000.00\n2024-03-14,TV STORE,-1500.00\n"
+	write(csv)
+	if err := n.Refresh(); err != nil {
+		t.Fatalf("second Refresh failed: %v", err)
+	}
+	if len(received) != 1 || received[0] != "SimpleBudget: High Spending Day" {
+		t.Fatalf("second Refresh sent %v, want the Mar 14 alert", received)
+	}
+
+	write(csv + "2024-03-15,COFFEE,-20.00\n")
+	if err := n.Refresh(); err != nil {
+		t.Fatalf("third Refresh failed: %v", err)
+	}
+	if len(received) != 1 {
+		t.Errorf("alerts already sent were sent again: %v", received)
+	}
+
+	// Purchases under the threshold aren't sent
+	store.WriteJSON(filepath.Join(settingsDir, "notifications.json"), Settings{
+		Thresholds: map[string]float64{"unusual_day": -1, "large_transaction": 5000},
+	})
+	csv += "2024-03-15,COFFEE,-20.00\n2024-03-16,AIRLINE,-2000.00\n"
+	write(csv)
+	if err := n.Refresh(); err != nil {
+		t.Fatalf("fourth Refresh failed: %v", err)
+	}
+	if len(received) != 1 {
+		t.Errorf("alerts under their thresholds were sent: %v", received)
+	}
+
+	// Lowering the threshold sends it with the next new data
+	store.WriteJSON(filepath.Join(settingsDir, "notifications.json"), Settings{
+		Thresholds: map[string]float64{"unusual_day": -1, "large_transaction": 1000},
+	})
+	write(csv + "2024-03-17,COFFEE,-20.00\n")
+	if err := n.Refresh(); err != nil {
+		t.Fatalf("fifth Refresh failed: %v", err)
+	}
+	if len(received) != 2 {
+		t.Errorf("after lowering the threshold got %v, want the Mar 16 purchase", received)
+	}
+}
+
+func TestRefreshRetriesFailedSend(t *testing.T) {
+	fail := true
+	sent := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			http.Error(w, "topic is rate limited", http.StatusTooManyRequests)
+			return
+		}
+		sent++
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	loader := dataloader.New(dir, store)
+	store.WriteJSON(filepath.Join(dir, "notifications_sent.json"), map[string]time.Time{})
+
+	csv := "Date,Description,Amount\n"
+	for day := 1; day <= 9; day++ {
+		csv += fmt.Sprintf("2024-03-%02d,COFFEE,-20.00\n", day)
+	}
+	csv += "2024-03-10,TV STORE,-1500.00\n"
+	os.WriteFile(filepath.Join(dir, "checking.csv"), []byte(csv), 0644)
+
+	n := NewNotifier(Config{URL: server.URL, Format: "Slack"}, loader, dir, store)
+	if err := n.Refresh(); err == nil || !strings.Contains(err.Error(), "rate limited") {
+		t.Fatalf("expected the webhook's error, got %v", err)
+	}
+
+	fail = false
+	if err := n.Refresh(); err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	if sent != 1 {
+		t.Errorf("retry sent %d alerts, want 1", sent)
+	}
+}
@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"budget2/internal/models"
+)
+
+// Webhook payload formats
+const (
+	FormatNtfy    = "ntfy"
+	FormatSlack   = "slack"
+	FormatDiscord = "discord"
+)
+
+// Formats are the payload formats a webhook can take
+var Formats = []string{FormatNtfy, FormatSlack, FormatDiscord}
+
+// GuessFormat picks a payload format from a webhook URL: Slack and Discord
+// by their hosts, and ntfy for anything else, since a plain text body with
+// a title header suits most simple receivers
+func GuessFormat(webhookURL string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return FormatNtfy
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "hooks.slack.com":
+		return FormatSlack
+	case host == "discord.com" || host == "discordapp.com" || strings.HasSuffix(host, ".discord.com"):
+		return FormatDiscord
+	default:
+		return FormatNtfy
+	}
+}
+
+// text describes an alert in a few lines: its message and the largest
+// transactions behind it
+func text(a models.SpendingAlert) string {
+	lines := []string{a.Message}
+	for i, t := range a.Transactions {
+		if i == 3 {
+			lines = append(lines, fmt.Sprintf("and %d more", len(a.Transactions)-i))
+			break
+		}
+		if len(a.Transactions) > 1 {
+			lines = append(lines, fmt.Sprintf("- $%.2f %s", t.AbsAmount(), t.Description))
+		}
+	}
+	for _, r := range a.Related {
+		lines = append(lines, r.Title+": "+r.Message)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Request builds the POST sending an alert to webhookURL in format
+func Request(webhookURL, format string, a models.SpendingAlert) (*http.Request, error) {
+	title := "SimpleBudget: " + a.Title
+
+	var body []byte
+	header := http.Header{}
+	switch format {
+	case FormatSlack:
+		body, _ = json.Marshal(map[string]string{"text": "*" + title + "*\n" + text(a)})
+		header.Set("Content-Type", "application/json")
+	case FormatDiscord:
+		color := 0x3b82f6 // Blue for information
+		if a.Severity == "warning" || a.Severity == "error" {
+			color = 0xf59e0b // Amber
+		}
+		embed := map[string]interface{}{
+			"title":       title,
+			"description": text(a),
+			"color":       color,
+		}
+		if a.Date != nil {
+			embed["timestamp"] = a.Date.Format("2006-01-02T15:04:05Z07:00")
+		}
+		body, _ = json.Marshal(map[string]interface{}{
+			"username": "SimpleBudget",
+			"embeds":   []interface{}{embed},
+		})
+		header.Set("Content-Type", "application/json")
+	case FormatNtfy:
+		body = []byte(text(a))
+		header.Set("Title", title)
+		header.Set("Tags", "moneybag")
+		if a.Severity == "warning" || a.Severity == "error" {
+			header.Set("Tags", "warning")
+			header.Set("Priority", "high")
+		}
+	default:
+		return nil, fmt.Errorf("unknown webhook format %q; use one of %s", format, strings.Join(Formats, ", "))
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	return req, nil
+}
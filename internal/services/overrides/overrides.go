@@ -0,0 +1,117 @@
+package overrides
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// overridesFile holds the category overrides by transaction
+var overridesFile = storage.SettingsFile("category_overrides.json", func() interface{} { return &map[string]models.CategoryOverride{} })
+
+// Manager persists categories the user set by hand on single transactions,
+// keyed by transaction hash, and reapplies them while data loads
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing overrides in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, overridesFile),
+		store: store,
+	}
+}
+
+// List returns the overrides by transaction hash
+func (m *Manager) List() (map[string]models.CategoryOverride, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Set overrides the category of the transaction t. t's current category is
+// remembered as the original unless it is already overridden. Setting the
+// original category again, or a blank one, removes the override.
+func (m *Manager) Set(t models.Transaction, category string) error {
+	if t.Hash == "" {
+		return fmt.Errorf("transaction has no hash")
+	}
+	category = strings.TrimSpace(category)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	overrides, err := m.loadInternal()
+	if err != nil {
+		return err
+	}
+
+	original := t.Category
+	if o, ok := overrides[t.Hash]; ok {
+		original = o.Original
+	}
+	if category == "" || category == original {
+		delete(overrides, t.Hash)
+	} else {
+		overrides[t.Hash] = models.CategoryOverride{
+			Category:  category,
+			Original:  original,
+			UpdatedAt: time.Now(),
+		}
+	}
+	return m.store.WriteJSON(m.path, overrides)
+}
+
+// Version changes whenever the overrides do, so the loader reloads data
+func (m *Manager) Version() string {
+	info, err := m.store.Stat(m.path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("overrides|%d|%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// Categorize applies the overrides to freshly loaded transactions, after
+// category rules so a manual fix always wins. Transactions are returned
+// unchanged if the overrides can't be read.
+func (m *Manager) Categorize(transactions []models.Transaction) []models.Transaction {
+	overrides, err := m.List()
+	if err != nil {
+		log.Printf("Warning: failed to load category overrides: %v", err)
+		return transactions
+	}
+	if len(overrides) == 0 {
+		return transactions
+	}
+
+	for i := range transactions {
+		if o, ok := overrides[transactions[i].Hash]; ok {
+			transactions[i].Category = o.Category
+			transactions[i].CategoryEdited = true
+		}
+	}
+	return transactions
+}
+
+// loadInternal reads the overrides without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() (map[string]models.CategoryOverride, error) {
+	overrides := make(map[string]models.CategoryOverride)
+	if err := m.store.ReadJSON(m.path, &overrides); err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]models.CategoryOverride), nil
+		}
+		return nil, err
+	}
+	return overrides, nil
+}
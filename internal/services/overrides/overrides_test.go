@@ -0,0 +1,63 @@
+package overrides
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func txn(desc string, amount float64, category string) models.Transaction {
+	d, _ := time.Parse("2006-01-02", "2024-03-01")
+	t := models.Transaction{Date: d, Description: desc, Amount: amount, Category: category}
+	t.Hash = t.ComputeHash()
+	return t
+}
+
+func TestSetAndCategorize(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	if v := manager.Version(); v != "" {
+		t.Errorf("version without overrides = %q, want empty", v)
+	}
+
+	coffee := txn("SQ *BLUE BOTTLE", -6.5, "Restaurants")
+	if err := manager.Set(coffee, " Coffee "); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	v1 := manager.Version()
+
+	// Setting it again keeps the category from the file as the original
+	edited := coffee
+	edited.Category = "Coffee"
+	manager.Set(edited, "Treats")
+	list, _ := manager.List()
+	if o := list[coffee.Hash]; o.Category != "Treats" || o.Original != "Restaurants" {
+		t.Errorf("override = %+v, want Treats over Restaurants", o)
+	}
+	if manager.Version() == v1 {
+		t.Error("version should change when overrides change")
+	}
+
+	// A reloaded file gets the override; other rows are untouched
+	loaded := manager.Categorize([]models.Transaction{coffee, txn("SAFEWAY", -80, "Groceries")})
+	if loaded[0].Category != "Treats" || !loaded[0].CategoryEdited {
+		t.Errorf("overridden row = %+v", loaded[0])
+	}
+	if loaded[1].Category != "Groceries" || loaded[1].CategoryEdited {
+		t.Errorf("other row = %+v", loaded[1])
+	}
+
+	// Setting the original category again reverts
+	manager.Set(loaded[0], "Restaurants")
+	if list, _ := manager.List(); len(list) != 0 {
+		t.Errorf("setting the original category should remove the override, got %+v", list)
+	}
+
+	if err := manager.Set(models.Transaction{Description: "no hash"}, "Coffee"); err == nil {
+		t.Error("Set should reject a transaction without a hash")
+	}
+}
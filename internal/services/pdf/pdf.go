@@ -0,0 +1,222 @@
+// Package pdf writes simple text reports as PDF files: headings, wrapped
+// paragraphs and table rows in the standard Helvetica fonts, on US Letter
+// pages. It needs no fonts or images, so the output is small and opens in
+// any viewer.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Page geometry in points
+const (
+	pageWidth  = 612.0
+	pageHeight = 792.0
+	margin     = 54.0
+
+	// Width of the text area
+	Width = pageWidth - 2*margin
+)
+
+// Font sizes and their line heights
+const (
+	titleSize   = 16.0
+	headingSize = 12.0
+	textSize    = 9.5
+	leading     = 1.35
+)
+
+// Document is a report being laid out, one page after another
+type Document struct {
+	pages []*bytes.Buffer
+	y     float64 // baseline of the next line on the current page
+}
+
+// New starts an empty document
+func New() *Document {
+	d := &Document{}
+	d.newPage()
+	return d
+}
+
+func (d *Document) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - margin
+}
+
+// need starts a new page unless height more points fit on this one
+func (d *Document) need(height float64) {
+	if d.y-height < margin {
+		d.newPage()
+	}
+}
+
+// show writes text with its baseline at x, d.y
+func (d *Document) show(x float64, bold bool, size float64, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.pages[len(d.pages)-1], "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, d.y, encode(text))
+}
+
+// Title writes the document title
+func (d *Document) Title(text string) {
+	d.need(titleSize * leading)
+	d.y -= titleSize
+	d.show(margin, true, titleSize, text)
+	d.y -= titleSize * (leading - 1)
+}
+
+// Heading writes a section heading, with space above it, keeping it on the
+// same page as at least one line that follows
+func (d *Document) Heading(text string) {
+	d.need(headingSize*leading + textSize*leading*2)
+	d.y -= headingSize * leading
+	d.show(margin, true, headingSize, text)
+	d.y -= headingSize * (leading - 1)
+}
+
+// Text writes a paragraph wrapped to the page width
+func (d *Document) Text(text string) {
+	for _, line := range wrap(text, Width, textSize) {
+		d.need(textSize * leading)
+		d.y -= textSize * leading
+		d.show(margin, false, textSize, line)
+	}
+}
+
+// Row writes a table row, each cell wrapped to its column's width in
+// points. Bold rows suit header rows.
+func (d *Document) Row(bold bool, widths []float64, cells ...string) {
+	var wrapped [][]string
+	lines := 1
+	for i, cell := range cells {
+		w := Width
+		if i < len(widths) {
+			w = widths[i] - 6 // gutter
+		}
+		wrapped = append(wrapped, wrap(cell, w, textSize))
+		lines = max(lines, len(wrapped[i]))
+	}
+
+	d.need(float64(lines) * textSize * leading)
+	for line := 0; line < lines; line++ {
+		d.y -= textSize * leading
+		x := margin
+		for i, cellLines := range wrapped {
+			if line < len(cellLines) {
+				d.show(x, bold, textSize, cellLines[line])
+			}
+			if i < len(widths) {
+				x += widths[i]
+			}
+		}
+	}
+}
+
+// WriteTo writes the document as a PDF file
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objects 1-4 are the catalog, page tree and fonts; each page then
+	// takes a page object followed by its content stream
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+2*i))
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", page.Len(), page.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// winAnsi maps the punctuation outside Latin-1 that reports commonly use
+// to its WinAnsiEncoding byte
+var winAnsi = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, '→': '>',
+}
+
+// encode converts text to WinAnsiEncoding and escapes it for a PDF string,
+// replacing characters the standard fonts can't show with "?"
+func encode(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case winAnsi[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", winAnsi[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// charWidth approximates Helvetica's average character width as a share of
+// the font size, enough to wrap text without measuring each glyph
+const charWidth = 0.5
+
+// wrap breaks text into lines no wider than width points at size, breaking
+// between words where it can
+func wrap(text string, width, size float64) []string {
+	limit := max(int(width/(size*charWidth)), 1)
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for len([]rune(word)) > limit {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				runes := []rune(word)
+				lines = append(lines, string(runes[:limit]))
+				word = string(runes[limit:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case len([]rune(line))+1+len([]rune(word)) <= limit:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,69 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	doc := New()
+	doc.Title("Report (draft)")
+	doc.Heading("Rates")
+	doc.Row(true, []float64{200, 304}, "Name", "Value")
+	for i := 0; i < 80; i++ {
+		doc.Row(false, []float64{200, 304}, "Inflation", "3.0% – a long value that wraps onto a second line within its narrow column")
+	}
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "%PDF-1.4\n") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatal("output isn't framed as a PDF file")
+	}
+	if !strings.Contains(out, `(Report \(draft\)) Tj`) {
+		t.Error("parentheses in text should be escaped")
+	}
+	if !strings.Contains(out, `3.0% \226 a long`) {
+		t.Error("en dash should be written in WinAnsiEncoding")
+	}
+
+	count := regexp.MustCompile(`/Count (\d+)`).FindStringSubmatch(out)
+	if count == nil {
+		t.Fatal("page tree missing")
+	}
+	if n, _ := strconv.Atoi(count[1]); n < 2 {
+		t.Errorf("pages = %d, want the rows to overflow onto more pages", n)
+	}
+
+	// The cross-reference table must point at each object
+	xref := regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(out)
+	start, _ := strconv.Atoi(xref[1])
+	if !strings.HasPrefix(out[start:], "xref\n") {
+		t.Fatalf("startxref %d doesn't point at the xref table", start)
+	}
+	for i, m := range regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(out[start:], -1) {
+		offset, _ := strconv.Atoi(m[1])
+		if want := strconv.Itoa(i+1) + " 0 obj"; !strings.HasPrefix(out[offset:], want) {
+			t.Errorf("xref entry %d points at %q", i+1, out[offset:offset+10])
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	lines := wrap("one two three four", 10*textSize*charWidth, textSize)
+	if len(lines) != 2 || lines[0] != "one two" || lines[1] != "three four" {
+		t.Errorf("lines = %q", lines)
+	}
+	if lines := wrap("abcdefghijkl", 5*textSize*charWidth, textSize); len(lines) != 3 || lines[2] != "kl" {
+		t.Errorf("long word lines = %q, want it split", lines)
+	}
+	if lines := wrap("", 100, textSize); len(lines) != 1 || lines[0] != "" {
+		t.Errorf("empty text lines = %q", lines)
+	}
+}
@@ -0,0 +1,207 @@
+package planned
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// plannedFile holds the planned purchases
+var plannedFile = storage.SettingsFile("planned.json", func() interface{} { return &[]models.PlannedPurchase{} })
+
+// WindowMonths is how many months either side of its expected month a
+// purchase's receipts may fall, for deposits paid early and deliveries
+// that slip
+const WindowMonths = 1
+
+// OnPlanPercent is how far from the plan, as a percent of it, a purchase
+// still counts as on plan
+const OnPlanPercent = 5.0
+
+// Manager persists planned purchases
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing planned purchases in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, plannedFile),
+		store: store,
+	}
+}
+
+// List returns all planned purchases by month, then item
+func (m *Manager) List() ([]models.PlannedPurchase, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Add saves a planned purchase. Without match text or a category, receipts
+// are matched by the item's name.
+func (m *Manager) Add(p models.PlannedPurchase) ([]models.PlannedPurchase, error) {
+	p.Item = strings.TrimSpace(p.Item)
+	p.Match = strings.TrimSpace(p.Match)
+	p.Category = strings.TrimSpace(p.Category)
+	if p.Item == "" {
+		return nil, fmt.Errorf("item is required")
+	}
+	if p.ExpectedCost <= 0 {
+		return nil, fmt.Errorf("expected cost must be positive, got %.2f", p.ExpectedCost)
+	}
+	if _, err := time.Parse("2006-01", p.Month); err != nil {
+		return nil, fmt.Errorf("expected month must be YYYY-MM, got %q", p.Month)
+	}
+	if p.Match == "" && p.Category == "" {
+		p.Match = p.Item
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	list = append(list, p)
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Month != list[j].Month {
+			return list[i].Month < list[j].Month
+		}
+		return strings.ToLower(list[i].Item) < strings.ToLower(list[j].Item)
+	})
+
+	if err := m.store.WriteJSON(m.path, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Remove deletes a planned purchase by ID
+func (m *Manager) Remove(id string) ([]models.PlannedPurchase, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.PlannedPurchase, 0, len(list))
+	for _, p := range list {
+		if p.ID != id {
+			filtered = append(filtered, p)
+		}
+	}
+
+	if err := m.store.WriteJSON(m.path, filtered); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// loadInternal reads the planned purchases without acquiring lock (caller
+// must hold lock)
+func (m *Manager) loadInternal() ([]models.PlannedPurchase, error) {
+	var list []models.PlannedPurchase
+	if err := m.store.ReadJSON(m.path, &list); err != nil {
+		if os.IsNotExist(err) {
+			return []models.PlannedPurchase{}, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+// matches reports whether an outflow could be a receipt for p
+func matches(p models.PlannedPurchase, t *models.Transaction) bool {
+	if p.Category != "" && !strings.EqualFold(t.Category, p.Category) {
+		return false
+	}
+	return p.Match == "" || strings.Contains(strings.ToLower(t.Description), strings.ToLower(p.Match))
+}
+
+// window returns the first and last day receipts for a purchase expected
+// in month may fall on
+func window(month time.Time) (start, end time.Time) {
+	return month.AddDate(0, -WindowMonths, 0), month.AddDate(0, WindowMonths+1, -1)
+}
+
+// Evaluate matches outflows in ts to each planned purchase and compares
+// what they cost with the plan. An outflow counts toward one purchase only:
+// of those it matches, the one expected closest to its date.
+func Evaluate(list []models.PlannedPurchase, ts *models.TransactionSet) models.PlannedSummary {
+	summary := models.PlannedSummary{Purchases: make([]models.PlannedPurchaseStatus, len(list))}
+	months := make([]time.Time, len(list))
+	for i, p := range list {
+		months[i], _ = time.Parse("2006-01", p.Month)
+		start, end := window(months[i])
+		summary.Purchases[i] = models.PlannedPurchaseStatus{
+			PlannedPurchase: p,
+			Start:           start.Format("2006-01-02"),
+			End:             end.Format("2006-01-02"),
+			Transactions:    []models.Transaction{},
+		}
+	}
+
+	for _, t := range ts.FilterByType(models.Outflow).SortByDate().Transactions {
+		day := time.Date(t.Date.Year(), t.Date.Month(), t.Date.Day(), 0, 0, 0, 0, time.UTC)
+		best, bestDistance := -1, math.MaxFloat64
+		for i, p := range list {
+			start, end := window(months[i])
+			if day.Before(start) || day.After(end) || !matches(p, &t) {
+				continue
+			}
+			// Distance from the middle of the expected month
+			if distance := math.Abs(day.Sub(months[i].AddDate(0, 0, 14)).Hours()); distance < bestDistance {
+				best, bestDistance = i, distance
+			}
+		}
+		if best >= 0 {
+			status := &summary.Purchases[best]
+			status.Transactions = append(status.Transactions, t)
+			status.Actual += t.AbsAmount()
+		}
+	}
+
+	latest := ts.MaxDate()
+	for i := range summary.Purchases {
+		status := &summary.Purchases[i]
+		summary.TotalPlanned += status.ExpectedCost
+		summary.TotalActual += status.Actual
+
+		if len(status.Transactions) == 0 {
+			status.Status = models.PlannedPending
+			if _, end := window(months[i]); !latest.IsZero() && latest.After(end) {
+				status.Status = models.PlannedMissing
+			}
+			continue
+		}
+
+		status.Variance = status.Actual - status.ExpectedCost
+		status.VariancePercent = status.Variance / status.ExpectedCost * 100
+		summary.TotalVariance += status.Variance
+		switch {
+		case status.VariancePercent > OnPlanPercent:
+			status.Status = models.PlannedOver
+		case status.VariancePercent < -OnPlanPercent:
+			status.Status = models.PlannedUnder
+		default:
+			status.Status = models.PlannedOnPlan
+		}
+	}
+	return summary
+}
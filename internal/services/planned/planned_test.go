@@ -0,0 +1,97 @@
+package planned
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func txn(date, desc, category string, amount float64) models.Transaction {
+	d, _ := time.Parse("2006-01-02", date)
+	t := models.Transaction{Date: d, Description: desc, Category: category, Amount: amount, TransactionType: models.Outflow}
+	if amount > 0 {
+		t.TransactionType = models.Income
+	}
+	return t
+}
+
+func TestAddValidates(t *testing.T) {
+	store, _ := storage.NewMemory("/data")
+	manager := NewManager("/data/settings", store)
+
+	for _, p := range []models.PlannedPurchase{
+		{Item: "", ExpectedCost: 100, Month: "2025-11"},
+		{Item: "Tile", ExpectedCost: 0, Month: "2025-11"},
+		{Item: "Tile", ExpectedCost: 100, Month: "November"},
+	} {
+		if _, err := manager.Add(p); err == nil {
+			t.Errorf("Add(%+v) should fail", p)
+		}
+	}
+
+	manager.Add(models.PlannedPurchase{ID: "1", Item: "Gifts", ExpectedCost: 800, Month: "2025-12", Category: "Gifts"})
+	list, err := manager.Add(models.PlannedPurchase{ID: "2", Item: "Tile", ExpectedCost: 1200, Month: "2025-11"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if len(list) != 2 || list[0].Item != "Tile" || list[0].Match != "Tile" || list[1].Match != "" {
+		t.Errorf("list = %+v, want sorted by month with the item as Tile's match", list)
+	}
+
+	list, _ = manager.Remove("2")
+	if len(list) != 1 || list[0].ID != "1" {
+		t.Errorf("Remove left %+v", list)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	list := []models.PlannedPurchase{
+		{ID: "tile", Item: "Kitchen tile", ExpectedCost: 1200, Month: "2025-10", Match: "floor & decor"},
+		{ID: "gifts", Item: "Holiday gifts", ExpectedCost: 800, Month: "2025-12", Category: "Gifts"},
+		{ID: "deck", Item: "Deck stain", ExpectedCost: 150, Month: "2025-08", Match: "sherwin"},
+		{ID: "sofa", Item: "Sofa", ExpectedCost: 2000, Month: "2026-01", Match: "sofa"},
+		{ID: "tile2", Item: "Bath tile", ExpectedCost: 500, Month: "2026-01", Match: "floor & decor"},
+	}
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2025-09-20", "FLOOR & DECOR #112", "Home", -400), // Deposit a month early
+		txn("2025-10-05", "FLOOR & DECOR #112", "Home", -1000),
+		txn("2025-10-08", "FLOOR & DECOR REFUND", "Home", 50), // Not an outflow
+		txn("2025-12-28", "FLOOR & DECOR #112", "Home", -480), // Closer to January's bath tile
+		txn("2025-12-03", "AMAZON", "Gifts", -300),
+		txn("2025-12-15", "TARGET", "Gifts", -250),
+		txn("2025-12-15", "TARGET", "Groceries", -90),
+		txn("2026-01-02", "KROGER", "Groceries", -60),
+	})
+
+	summary := Evaluate(list, ts)
+	byID := make(map[string]models.PlannedPurchaseStatus)
+	for _, p := range summary.Purchases {
+		byID[p.ID] = p
+	}
+
+	tile := byID["tile"]
+	if tile.Actual != 1400 || tile.Variance != 200 || tile.Status != models.PlannedOver || len(tile.Transactions) != 2 {
+		t.Errorf("tile = %.2f, %+.2f, %s, %d receipts; want 1400, +200, over, 2", tile.Actual, tile.Variance, tile.Status, len(tile.Transactions))
+	}
+	if tile.Start != "2025-09-01" || tile.End != "2025-11-30" {
+		t.Errorf("tile window = %s to %s", tile.Start, tile.End)
+	}
+	if gifts := byID["gifts"]; gifts.Actual != 550 || gifts.Status != models.PlannedUnder {
+		t.Errorf("gifts = %.2f %s, want 550 under", gifts.Actual, gifts.Status)
+	}
+	if bath := byID["tile2"]; bath.Actual != 480 || bath.Status != models.PlannedOnPlan {
+		t.Errorf("bath tile = %.2f %s, want 480 on plan", bath.Actual, bath.Status)
+	}
+	if deck := byID["deck"]; deck.Status != models.PlannedMissing {
+		t.Errorf("deck = %s, want missing once its window has passed", deck.Status)
+	}
+	if sofa := byID["sofa"]; sofa.Status != models.PlannedPending || sofa.Variance != 0 {
+		t.Errorf("sofa = %s %+.2f, want pending with no variance", sofa.Status, sofa.Variance)
+	}
+
+	if summary.TotalPlanned != 4650 || summary.TotalActual != 2430 || summary.TotalVariance != 200-250-20 {
+		t.Errorf("totals = %.2f planned, %.2f actual, %+.2f variance", summary.TotalPlanned, summary.TotalActual, summary.TotalVariance)
+	}
+}
@@ -0,0 +1,263 @@
+package relocation
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"budget2/internal/models"
+	"budget2/internal/services/retirement"
+	"budget2/internal/services/storage"
+)
+
+// plansFile holds the relocation plan
+var plansFile = storage.SettingsFile("relocations.json", func() interface{} { return &models.RelocationPlan{} })
+
+// Expense source IDs added to each location's plan
+const (
+	propertyTaxID = "relocation-property-tax"
+	stateTaxID    = "relocation-state-tax"
+)
+
+// Manager persists the current location and the states being considered
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing relocation scenarios in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, plansFile),
+		store: store,
+	}
+}
+
+// Get returns the saved relocation plan
+func (m *Manager) Get() (models.RelocationPlan, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// SetCurrent saves where you live now. Its cost of living is the baseline
+// every scenario is measured against, so it is always 1.0.
+func (m *Manager) SetCurrent(current models.RelocationScenario) (models.RelocationPlan, error) {
+	current.ID = "current"
+	current.CostOfLiving = 1
+	if err := validate(&current); err != nil {
+		return models.RelocationPlan{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	plan, err := m.loadInternal()
+	if err != nil {
+		return plan, err
+	}
+	plan.Current = current
+	if err := m.store.WriteJSON(m.path, plan); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// Save adds a scenario, or replaces the scenario with the same ID
+func (m *Manager) Save(scenario models.RelocationScenario) (models.RelocationPlan, error) {
+	if err := validate(&scenario); err != nil {
+		return models.RelocationPlan{}, err
+	}
+	if scenario.ID == "" {
+		scenario.ID = uuid.New().String()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	plan, err := m.loadInternal()
+	if err != nil {
+		return plan, err
+	}
+
+	replaced := false
+	for i, s := range plan.Scenarios {
+		if s.ID == scenario.ID {
+			plan.Scenarios[i] = scenario
+			replaced = true
+		}
+	}
+	if !replaced {
+		plan.Scenarios = append(plan.Scenarios, scenario)
+	}
+	if err := m.store.WriteJSON(m.path, plan); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// Remove deletes a scenario
+func (m *Manager) Remove(id string) (models.RelocationPlan, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	plan, err := m.loadInternal()
+	if err != nil {
+		return plan, err
+	}
+
+	filtered := make([]models.RelocationScenario, 0, len(plan.Scenarios))
+	for _, s := range plan.Scenarios {
+		if s.ID != id {
+			filtered = append(filtered, s)
+		}
+	}
+	plan.Scenarios = filtered
+	if err := m.store.WriteJSON(m.path, plan); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// loadInternal reads the plan without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() (models.RelocationPlan, error) {
+	plan := models.RelocationPlan{
+		Current:   models.RelocationScenario{ID: "current", CostOfLiving: 1},
+		Scenarios: []models.RelocationScenario{},
+	}
+	if err := m.store.ReadJSON(m.path, &plan); err != nil && !os.IsNotExist(err) {
+		return plan, err
+	}
+	if plan.Scenarios == nil {
+		plan.Scenarios = []models.RelocationScenario{}
+	}
+	return plan, nil
+}
+
+// validate trims and checks a scenario's fields
+func validate(s *models.RelocationScenario) error {
+	s.State = strings.TrimSpace(s.State)
+	switch {
+	case s.CostOfLiving <= 0 || s.CostOfLiving > 5:
+		return fmt.Errorf("cost of living multiplier must be between 0 and 5")
+	case s.IncomeTaxRate < 0 || s.IncomeTaxRate > 20:
+		return fmt.Errorf("state income tax rate must be between 0 and 20%%")
+	case s.RetirementExclusion < 0:
+		return fmt.Errorf("retirement income exclusion can't be negative")
+	case s.PropertyTax < 0:
+		return fmt.Errorf("property tax can't be negative")
+	}
+	if s.State == "" && s.ID != "current" {
+		return fmt.Errorf("state is required")
+	}
+	return nil
+}
+
+// MonthlyStateTax estimates state tax on retirement income once every income
+// source has started: taxable income, plus the share of portfolio
+// withdrawals that comes from tax-deferred accounts, less the state's
+// retirement income exclusion
+func MonthlyStateTax(settings *models.WhatIfSettings, monthlyExpenses float64, s models.RelocationScenario) float64 {
+	if s.IncomeTaxRate <= 0 {
+		return 0
+	}
+
+	var income, taxable float64
+	for _, source := range settings.IncomeSources {
+		income += source.Amount
+		if s.TaxesSocialSecurity || !source.IsSocialSecurity() {
+			taxable += source.Amount
+		}
+	}
+	if withdrawal := monthlyExpenses - income; withdrawal > 0 {
+		taxable += withdrawal * settings.TaxDeferredPercent / 100
+	}
+
+	taxable -= s.RetirementExclusion / 12
+	if taxable <= 0 {
+		return 0
+	}
+	return taxable * s.IncomeTaxRate / 100
+}
+
+// Apply returns a copy of settings for retiring in scenario instead of
+// current. Living expenses are assumed to include current's property tax;
+// the rest is scaled by the scenario's cost of living, and the scenario's
+// property tax and estimated state tax are added as inflation-adjusted
+// expenses.
+func Apply(settings *models.WhatIfSettings, current, scenario models.RelocationScenario) (*models.WhatIfSettings, *models.RelocationOutcome) {
+	adjusted := *settings
+	adjusted.ExpenseSources = make([]models.ExpenseSource, 0, len(settings.ExpenseSources)+2)
+	for _, e := range settings.ExpenseSources {
+		if e.ID != propertyTaxID && e.ID != stateTaxID {
+			adjusted.ExpenseSources = append(adjusted.ExpenseSources, e)
+		}
+	}
+
+	base := math.Max(0, settings.MonthlyLivingExpenses-current.PropertyTax/12)
+	adjusted.MonthlyLivingExpenses = base * scenario.CostOfLiving
+	adjusted.EssentialFloor = settings.EssentialFloor * scenario.CostOfLiving
+
+	outcome := &models.RelocationOutcome{
+		Scenario:           scenario,
+		MonthlyLiving:      adjusted.MonthlyLivingExpenses,
+		MonthlyPropertyTax: scenario.PropertyTax / 12,
+	}
+	if outcome.MonthlyPropertyTax > 0 {
+		adjusted.ExpenseSources = append(adjusted.ExpenseSources, models.ExpenseSource{
+			ID:        propertyTaxID,
+			Name:      "Property tax (" + scenario.State + ")",
+			Amount:    outcome.MonthlyPropertyTax,
+			Inflation: true,
+		})
+	}
+
+	expenses := retirement.NewCalculator(&adjusted).CalculateTotalExpenses(0)
+	outcome.MonthlyStateTax = MonthlyStateTax(&adjusted, expenses, scenario)
+	if outcome.MonthlyStateTax > 0 {
+		adjusted.ExpenseSources = append(adjusted.ExpenseSources, models.ExpenseSource{
+			ID:        stateTaxID,
+			Name:      "State income tax (" + scenario.State + ")",
+			Amount:    outcome.MonthlyStateTax,
+			Inflation: true,
+		})
+	}
+	return &adjusted, outcome
+}
+
+// Compare runs the plan where you live now and in each scenario, side by
+// side. Differences are measured against staying put, the first outcome.
+func Compare(settings *models.WhatIfSettings, plan models.RelocationPlan) ([]models.RelocationOutcome, error) {
+	locations := append([]models.RelocationScenario{plan.Current}, plan.Scenarios...)
+	outcomes := make([]models.RelocationOutcome, 0, len(locations))
+
+	for i, location := range locations {
+		adjusted, outcome := Apply(settings, plan.Current, location)
+		engine, err := retirement.NewEngine(adjusted)
+		if err != nil {
+			return nil, err
+		}
+
+		calc := retirement.NewCalculator(adjusted)
+		projection := engine.Project()
+		outcome.Current = i == 0
+		outcome.MonthlyExpenses = calc.CalculateTotalExpenses(0)
+		outcome.FinalBalance = projection.FinalBalance
+		outcome.Survives = projection.Survives
+		outcome.LongevityYears = projection.LongevityYears
+		outcome.Sustainability = calc.CalculateSustainabilityScore(projection)
+		if i > 0 {
+			outcome.BalanceDifference = outcome.FinalBalance - outcomes[0].FinalBalance
+			outcome.MonthlyDifference = outcome.MonthlyExpenses - outcomes[0].MonthlyExpenses
+		}
+		outcomes = append(outcomes, *outcome)
+	}
+	return outcomes, nil
+}
@@ -0,0 +1,133 @@
+package relocation
+
+import (
+	"math"
+	"testing"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func testSettings() *models.WhatIfSettings {
+	s := models.DefaultWhatIfSettings()
+	s.PortfolioValue = 800000
+	s.MonthlyLivingExpenses = 5000
+	s.MonthlyHealthcare = 0
+	s.TaxDeferredPercent = 50
+	s.IncomeSources = []models.IncomeSource{
+		{ID: "ss", Name: "Social Security", Amount: 2000, Type: models.IncomeFixed},
+		{ID: "pension", Name: "Pension", Amount: 1000, Type: models.IncomeFixed},
+	}
+	return s
+}
+
+func TestMonthlyStateTax(t *testing.T) {
+	s := testSettings()
+
+	// Pension plus half of the 2000 withdrawal, at 5%
+	exempt := models.RelocationScenario{IncomeTaxRate: 5}
+	if got := MonthlyStateTax(s, 5000, exempt); math.Abs(got-100) > 0.01 {
+		t.Errorf("tax with Social Security exempt = %.2f, want 100", got)
+	}
+
+	taxed := models.RelocationScenario{IncomeTaxRate: 5, TaxesSocialSecurity: true}
+	if got := MonthlyStateTax(s, 5000, taxed); math.Abs(got-200) > 0.01 {
+		t.Errorf("tax with Social Security taxed = %.2f, want 200", got)
+	}
+
+	// A 24000/yr exclusion covers the taxable 2000/mo entirely
+	excluded := models.RelocationScenario{IncomeTaxRate: 5, RetirementExclusion: 24000}
+	if got := MonthlyStateTax(s, 5000, excluded); got != 0 {
+		t.Errorf("tax with exclusion = %.2f, want 0", got)
+	}
+}
+
+func TestApply(t *testing.T) {
+	s := testSettings()
+	current := models.RelocationScenario{ID: "current", CostOfLiving: 1, PropertyTax: 6000}
+	cheaper := models.RelocationScenario{State: "Tennessee", CostOfLiving: 0.8, PropertyTax: 2400}
+
+	adjusted, outcome := Apply(s, current, cheaper)
+
+	// 5000 less 500 of current property tax, scaled by 0.8
+	if adjusted.MonthlyLivingExpenses != 3600 || outcome.MonthlyLiving != 3600 {
+		t.Errorf("living = %.2f, want 3600", adjusted.MonthlyLivingExpenses)
+	}
+	if outcome.MonthlyPropertyTax != 200 || outcome.MonthlyStateTax != 0 {
+		t.Errorf("property tax = %.2f, state tax = %.2f, want 200 and 0", outcome.MonthlyPropertyTax, outcome.MonthlyStateTax)
+	}
+	if len(adjusted.ExpenseSources) != 1 || len(s.ExpenseSources) != 0 {
+		t.Errorf("expense sources = %d (original %d), want 1 added to a copy", len(adjusted.ExpenseSources), len(s.ExpenseSources))
+	}
+	if s.MonthlyLivingExpenses != 5000 {
+		t.Errorf("original settings modified: living = %.2f", s.MonthlyLivingExpenses)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	plan := models.RelocationPlan{
+		Current: models.RelocationScenario{ID: "current", State: "California", CostOfLiving: 1, IncomeTaxRate: 9.3, PropertyTax: 9000},
+		Scenarios: []models.RelocationScenario{
+			{ID: "fl", State: "Florida", CostOfLiving: 0.85, PropertyTax: 5000},
+		},
+	}
+
+	outcomes, err := Compare(testSettings(), plan)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if len(outcomes) != 2 || !outcomes[0].Current || outcomes[1].Current {
+		t.Fatalf("outcomes = %+v, want current then Florida", outcomes)
+	}
+
+	fl := outcomes[1]
+	if fl.MonthlyDifference >= 0 {
+		t.Errorf("Florida monthly difference = %.2f, want cheaper than California", fl.MonthlyDifference)
+	}
+	if fl.BalanceDifference <= 0 {
+		t.Errorf("Florida balance difference = %.2f, want a larger final balance", fl.BalanceDifference)
+	}
+	if fl.Sustainability == nil {
+		t.Error("Florida outcome missing sustainability score")
+	}
+}
+
+func TestManager(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	m := NewManager(dir, store)
+
+	plan, err := m.Get()
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if plan.Current.CostOfLiving != 1 || len(plan.Scenarios) != 0 {
+		t.Errorf("empty plan = %+v", plan)
+	}
+
+	if _, err := m.SetCurrent(models.RelocationScenario{State: "Ohio", CostOfLiving: 2, IncomeTaxRate: 3.5}); err != nil {
+		t.Fatalf("SetCurrent: %v", err)
+	}
+	plan, err = m.Save(models.RelocationScenario{State: " Texas ", CostOfLiving: 1.1})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if plan.Current.State != "Ohio" || plan.Current.CostOfLiving != 1 {
+		t.Errorf("current = %+v, want Ohio at cost of living 1", plan.Current)
+	}
+	if len(plan.Scenarios) != 1 || plan.Scenarios[0].State != "Texas" || plan.Scenarios[0].ID == "" {
+		t.Fatalf("scenarios = %+v, want Texas with an ID", plan.Scenarios)
+	}
+
+	if _, err := m.Save(models.RelocationScenario{CostOfLiving: 1}); err == nil {
+		t.Error("Save without a state should fail")
+	}
+
+	plan, err = m.Remove(plan.Scenarios[0].ID)
+	if err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if len(plan.Scenarios) != 0 {
+		t.Errorf("scenarios after Remove = %+v", plan.Scenarios)
+	}
+}
@@ -0,0 +1,209 @@
+package retirement
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"budget2/internal/models"
+)
+
+// settingField is a scalar what-if setting listed in the assumptions report
+type settingField struct {
+	key   string // JSON name, as in WhatIfSettings.SettingKeys
+	group string
+	name  string
+	value func(s *models.WhatIfSettings) string
+}
+
+// settingFields are the scalar inputs in report order. The legacy
+// single-person healthcare fields are listed only when no healthcare
+// persons are set up.
+var settingFields = []settingField{
+	{"portfolio_value", "Portfolio", "Portfolio value", func(s *models.WhatIfSettings) string { return money(s.PortfolioValue) }},
+	{"tax_deferred_percent", "Portfolio", "Tax-deferred share", func(s *models.WhatIfSettings) string { return percent(s.TaxDeferredPercent) }},
+	{"annual_qcd", "Portfolio", "Qualified charitable distributions", func(s *models.WhatIfSettings) string { return money(s.AnnualQCD) + "/yr" }},
+	{"monthly_living_expenses", "Spending", "Monthly living expenses", func(s *models.WhatIfSettings) string { return money(s.MonthlyLivingExpenses) + "/mo" }},
+	{"spending_decline_rate", "Spending", "Annual spending decline", func(s *models.WhatIfSettings) string { return percent(s.SpendingDeclineRate) }},
+	{"essential_floor", "Spending", "Essential floor (no decline)", func(s *models.WhatIfSettings) string { return money(s.EssentialFloor) + "/mo" }},
+	{"sync_window_months", "Spending", "Expenses synced over", func(s *models.WhatIfSettings) string { return fmt.Sprintf("Last %d months", s.SyncWindow()) }},
+	{"sync_exclude_one_offs", "Spending", "One-off purchases in sync", func(s *models.WhatIfSettings) string {
+		if s.SyncExcludeOneOffs {
+			return "Excluded"
+		}
+		return "Included"
+	}},
+	{"monthly_healthcare", "Healthcare", "Monthly healthcare", func(s *models.WhatIfSettings) string { return money(s.MonthlyHealthcare) + "/mo" }},
+	{"healthcare_start_years", "Healthcare", "Healthcare starts in", func(s *models.WhatIfSettings) string { return years(s.HealthcareStartYears) }},
+	{"healthcare_inflation", "Healthcare", "Healthcare inflation", func(s *models.WhatIfSettings) string { return percent(s.HealthcareInflation) }},
+	{"inflation_rate", "Rates", "Inflation", func(s *models.WhatIfSettings) string { return percent(s.InflationRate) }},
+	{"investment_return", "Rates", "Investment return", func(s *models.WhatIfSettings) string { return percent(s.InvestmentReturn) }},
+	{"discount_rate", "Rates", "Discount rate", func(s *models.WhatIfSettings) string { return percent(s.DiscountRate) }},
+	{"crash_recovery", "Market shocks", "Crash recovery shape", func(s *models.WhatIfSettings) string { return strings.ToUpper(s.RecoveryShape()) + "-shaped" }},
+	{"bear_market_years", "Market shocks", "Longest bear market", func(s *models.WhatIfSettings) string { return years(max(s.BearMarketYears, 1)) }},
+	{"inflation_correlation", "Market shocks", "Inflation drag on returns", func(s *models.WhatIfSettings) string { return fmt.Sprintf("%.2f", s.InflationCorrelation) }},
+	{"current_age", "Longevity", "Current age", func(s *models.WhatIfSettings) string { return fmt.Sprint(s.CurrentAge) }},
+	{"sex", "Longevity", "Mortality table", func(s *models.WhatIfSettings) string { return orDefault(s.Sex, "Blended male and female") }},
+	{"health", "Longevity", "Health", func(s *models.WhatIfSettings) string { return orDefault(s.Health, models.HealthAverage) }},
+	{"projection_years", "Projection", "Projection length", func(s *models.WhatIfSettings) string { return years(s.ProjectionYears) }},
+	{"steady_state_override_year", "Projection", "Steady-state year", func(s *models.WhatIfSettings) string {
+		if s.SteadyStateOverrideYear == 0 {
+			return "Automatic"
+		}
+		return fmt.Sprintf("%.0f", s.SteadyStateOverrideYear)
+	}},
+	{"engine", "Projection", "Analysis engine", func(s *models.WhatIfSettings) string { return orDefault(s.Engine, DefaultEngine) }},
+	{"legacy_target", "Estate", "Legacy target", func(s *models.WhatIfSettings) string {
+		if s.LegacyTarget == 0 {
+			return "None"
+		}
+		return money(s.LegacyTarget)
+	}},
+}
+
+// Assumptions lists every input to the what-if projections: the settings,
+// each healthcare person, portfolio bucket and income and expense source,
+// and the built-in stress scenarios and mortality table. Each is marked
+// user-entered, synced or default, with when it last changed if known.
+func Assumptions(s *models.WhatIfSettings, now time.Time) *models.AssumptionsReport {
+	defaults := models.DefaultWhatIfSettings().SettingKeys()
+	current := s.SettingKeys()
+
+	var list []models.Assumption
+	add := func(key, group, name, value string) {
+		a := models.Assumption{Group: group, Name: name, Key: key, Value: value}
+		if change, ok := s.Changes[key]; ok {
+			a.Source = change.Source
+			at := change.At
+			a.Changed = &at
+		} else {
+			a.Source = untrackedSource(key, current[key], defaults)
+		}
+		list = append(list, a)
+	}
+
+	for _, f := range settingFields {
+		if f.group == "Healthcare" && s.HasMultiPersonHealthcare() {
+			continue
+		}
+		add(f.key, f.group, f.name, f.value(s))
+	}
+	for _, p := range s.HealthcarePersons {
+		value := fmt.Sprintf("Age %d, %s %s/mo rising %s, Medicare at %d %s/mo rising %s",
+			p.CurrentAge, strings.ToUpper(string(p.CurrentCoverage)), money(p.CurrentMonthlyCost), percent(p.PreMedicareInflation),
+			p.MedicareEligibleAge, money(p.MedicareMonthlyCost), percent(p.PostMedicareInflation))
+		add("healthcare_persons/"+p.ID, "Healthcare", p.Name, value)
+	}
+	for _, b := range s.PortfolioBuckets {
+		add("portfolio_buckets/"+b.ID, "Portfolio buckets", b.Name,
+			fmt.Sprintf("%s returning %s, withdrawal priority %d", money(b.Value), percent(b.Return), b.Priority))
+	}
+	for _, src := range s.IncomeSources {
+		add("income_sources/"+src.ID, "Income sources", src.Name, incomeValue(src))
+	}
+	for _, src := range s.ExpenseSources {
+		add("expense_sources/"+src.ID, "Expense sources", src.Name, expenseValue(src))
+	}
+
+	for _, scenario := range stressScenarios {
+		worst := scenario.Returns[0]
+		var inflation float64
+		for i, r := range scenario.Returns {
+			worst = min(worst, r)
+			inflation += scenario.Inflation[i]
+		}
+		list = append(list, models.Assumption{
+			Group:  "Stress scenarios",
+			Name:   scenario.Label,
+			Value:  fmt.Sprintf("%d years, worst return %s, average inflation %s", len(scenario.Returns), percent(worst), percent(inflation/float64(len(scenario.Inflation)))),
+			Source: models.AssumptionDefault,
+		})
+	}
+	list = append(list, models.Assumption{
+		Group:  "Longevity",
+		Name:   "Mortality rates",
+		Value:  "SSA 2019 period life table",
+		Source: models.AssumptionDefault,
+	})
+
+	return &models.AssumptionsReport{Generated: now, Assumptions: list}
+}
+
+// untrackedSource is the source of an input with no recorded change: one
+// saved before changes were recorded, or never changed. Auto-detected
+// income sources count as synced, other list entries as user-entered, and
+// settings as default unless they differ from the defaults.
+func untrackedSource(key, value string, defaults map[string]string) string {
+	if id, ok := strings.CutPrefix(key, "income_sources/"); ok && (strings.HasPrefix(id, "insights-") || id == "dashboard-income") {
+		return models.AssumptionSynced
+	}
+	if strings.Contains(key, "/") {
+		return models.AssumptionUser
+	}
+	if value == defaults[key] {
+		return models.AssumptionDefault
+	}
+	return models.AssumptionUser
+}
+
+// incomeValue describes an income source's amount, timing and COLA
+func incomeValue(src models.IncomeSource) string {
+	value := money(src.Amount) + "/mo"
+	switch {
+	case src.ByAge() && src.EndAge > 0:
+		value += fmt.Sprintf(", ages %d to %d", src.StartAge, src.EndAge)
+	case src.ByAge():
+		value += fmt.Sprintf(", from age %d", src.StartAge)
+	case src.EndMonth != nil:
+		value += fmt.Sprintf(", years %d to %d", src.StartMonth/12, *src.EndMonth/12)
+	case src.StartMonth > 0:
+		value += fmt.Sprintf(", from year %d", src.StartMonth/12)
+	}
+	switch {
+	case src.InflationAdjusted:
+		value += ", COLA with inflation"
+	case src.COLARate != 0:
+		value += ", " + percent(src.COLARate*100) + " COLA"
+	}
+	return value
+}
+
+// expenseValue describes an expense source's amount, timing and flags
+func expenseValue(src models.ExpenseSource) string {
+	value := money(src.Amount) + "/mo"
+	switch {
+	case src.EndYear > 0:
+		value += fmt.Sprintf(", years %d to %d", src.StartYear, src.EndYear)
+	case src.StartYear > 0:
+		value += fmt.Sprintf(", from year %d", src.StartYear)
+	}
+	if src.Inflation {
+		value += ", inflation-adjusted"
+	}
+	if src.Discretionary {
+		value += ", discretionary"
+	}
+	return value
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("$%.0f", v)
+}
+
+func percent(v float64) string {
+	return fmt.Sprintf("%.1f%%", v)
+}
+
+func years(n int) string {
+	if n == 1 {
+		return "1 year"
+	}
+	return fmt.Sprintf("%d years", n)
+}
+
+func orDefault(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
@@ -0,0 +1,65 @@
+package retirement
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+)
+
+func TestAssumptions(t *testing.T) {
+	changed := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	settings := models.DefaultWhatIfSettings()
+	settings.InvestmentReturn = 7 // changed before changes were recorded
+	settings.MonthlyLivingExpenses = 5100
+	settings.IncomeSources = []models.IncomeSource{
+		{ID: "insights-acme", Name: "Acme Payroll", Amount: 7583},
+		{ID: "pension", Name: "Pension", Amount: 1500, StartAge: 67, InflationAdjusted: true},
+	}
+	settings.Changes = map[string]models.SettingChange{
+		"monthly_living_expenses": {Source: models.AssumptionSynced, At: changed},
+	}
+
+	report := Assumptions(settings, changed)
+	byName := make(map[string]models.Assumption)
+	for _, a := range report.Assumptions {
+		byName[a.Name] = a
+	}
+
+	tests := []struct {
+		name, value, source string
+		dated               bool
+	}{
+		{"Monthly living expenses", "$5100/mo", models.AssumptionSynced, true},
+		{"Investment return", "7.0%", models.AssumptionUser, false},
+		{"Inflation", "3.0%", models.AssumptionDefault, false},
+		{"Acme Payroll", "$7583/mo", models.AssumptionSynced, false},
+		{"Pension", "$1500/mo, from age 67, COLA with inflation", models.AssumptionUser, false},
+		{"Stagflation", "10 years, worst return -26.5%, average inflation 8.7%", models.AssumptionDefault, false},
+		{"Mortality rates", "SSA 2019 period life table", models.AssumptionDefault, false},
+	}
+	for _, tt := range tests {
+		a, ok := byName[tt.name]
+		if !ok {
+			t.Errorf("%s missing from report", tt.name)
+			continue
+		}
+		if a.Value != tt.value || a.Source != tt.source || (a.Changed != nil) != tt.dated {
+			t.Errorf("%s = %q %s changed %v, want %q %s dated %v", tt.name, a.Value, a.Source, a.Changed, tt.value, tt.source, tt.dated)
+		}
+	}
+	if a := byName["Monthly living expenses"]; a.Changed != nil && !a.Changed.Equal(changed) {
+		t.Errorf("changed = %v, want %v", a.Changed, changed)
+	}
+
+	// Healthcare persons replace the single-person healthcare inputs
+	if _, ok := byName["Monthly healthcare"]; !ok {
+		t.Error("single-person healthcare should be listed without healthcare persons")
+	}
+	settings.HealthcarePersons = []models.HealthcarePerson{{ID: "p1", Name: "Pat", CurrentAge: 60, CurrentCoverage: models.CoverageACA, MedicareEligibleAge: 65}}
+	for _, a := range Assumptions(settings, changed).Assumptions {
+		if a.Name == "Monthly healthcare" {
+			t.Error("single-person healthcare shouldn't be listed with healthcare persons")
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package retirement
+
+import (
+	"math"
+
+	"budget2/internal/models"
+)
+
+// BridgeLadderYield is the assumed yield (%) on the Treasury or CD ladder
+// recommended to fund the bridge
+const BridgeLadderYield = 4.0
+
+// CalculateBridge measures the bridge from today until the last delayed
+// income source starts: how much more the portfolio must pay out before
+// that income arrives, and what a bond/cash ladder covering the extra
+// withdrawals would cost today
+func (c *Calculator) CalculateBridge() *models.BridgeAnalysis {
+	s := c.Settings
+	months := s.ProjectionYears * 12
+	bridge := &models.BridgeAnalysis{
+		DelayedSources: []string{},
+		LadderYield:    BridgeLadderYield,
+		Rungs:          []models.BridgeRung{},
+	}
+
+	// Delayed sources that actually pay out within the plan
+	var delayed []models.IncomeSource
+	for _, source := range s.IncomeSources {
+		if source.StartMonth <= 0 || source.StartMonth >= months {
+			continue
+		}
+		if source.EndMonth != nil && *source.EndMonth <= source.StartMonth {
+			continue
+		}
+		delayed = append(delayed, source)
+		bridge.DelayedSources = append(bridge.DelayedSources, source.Name)
+		bridge.Months = max(bridge.Months, source.StartMonth)
+	}
+	if len(delayed) == 0 {
+		return bridge
+	}
+	bridge.HasBridge = true
+	bridge.EndAge = s.CurrentAge + bridge.Months/12
+
+	for m := 0; m < bridge.Months; m++ {
+		withdrawal := math.Max(0, c.CalculateTotalExpenses(m)-c.CalculateTotalIncome(m))
+
+		// The extra is what the sources still to come would have paid,
+		// capped at the withdrawal itself
+		waiting := 0.0
+		for _, source := range delayed {
+			if m < source.StartMonth {
+				waiting += source.Amount
+			}
+		}
+		extra := math.Min(withdrawal, waiting)
+
+		year := m / 12
+		if year == len(bridge.Rungs) {
+			bridge.Rungs = append(bridge.Rungs, models.BridgeRung{Year: year, Age: s.CurrentAge + year})
+		}
+		bridge.Rungs[year].Withdrawal += withdrawal
+		bridge.Rungs[year].Amount += extra
+		bridge.TotalWithdrawals += withdrawal
+		bridge.ExtraWithdrawals += extra
+	}
+
+	for i := range bridge.Rungs {
+		rung := &bridge.Rungs[i]
+		rung.Cost = rung.Amount / math.Pow(1+BridgeLadderYield/100, float64(rung.Year))
+		bridge.LadderCost += rung.Cost
+	}
+	if s.PortfolioValue > 0 {
+		bridge.PortfolioShare = bridge.ExtraWithdrawals / s.PortfolioValue * 100
+		bridge.LadderShare = bridge.LadderCost / s.PortfolioValue * 100
+	}
+	return bridge
+}
@@ -0,0 +1,69 @@
+package retirement
+
+import (
+	"math"
+	"testing"
+
+	"budget2/internal/models"
+)
+
+// TestCalculateBridge verifies the bridge runs until the last delayed income
+// starts and the ladder covers only what that income would have paid
+func TestCalculateBridge(t *testing.T) {
+	settings := models.DefaultWhatIfSettings()
+	settings.CurrentAge = 62
+	settings.PortfolioValue = 1000000
+	settings.MonthlyLivingExpenses = 5000
+	settings.MonthlyHealthcare = 0
+	settings.HealthcarePersons = nil
+	settings.InflationRate = 0
+	settings.SpendingDeclineRate = 0
+	settings.IncomeSources = []models.IncomeSource{
+		{Name: "Pension", Amount: 1000},
+		{Name: "Social Security", Amount: 2500, StartAge: 67},
+		{Name: "Annuity", Amount: 500, StartMonth: 24},
+	}
+
+	bridge := NewCalculator(settings).CalculateBridge()
+	if !bridge.HasBridge || bridge.Months != 60 || bridge.EndAge != 67 {
+		t.Fatalf("bridge = %v for %d months to age %d, want 60 months to 67", bridge.HasBridge, bridge.Months, bridge.EndAge)
+	}
+	if len(bridge.DelayedSources) != 2 || len(bridge.Rungs) != 5 {
+		t.Fatalf("%d delayed sources and %d rungs, want 2 and 5", len(bridge.DelayedSources), len(bridge.Rungs))
+	}
+
+	// Years 1-2 wait on both sources, years 3-5 only on Social Security
+	wantExtra := 24*3000.0 + 36*2500.0
+	if math.Abs(bridge.ExtraWithdrawals-wantExtra) > 0.01 {
+		t.Errorf("ExtraWithdrawals = %.2f, want %.2f", bridge.ExtraWithdrawals, wantExtra)
+	}
+	if want := 24*4000.0 + 36*3500.0; math.Abs(bridge.TotalWithdrawals-want) > 0.01 {
+		t.Errorf("TotalWithdrawals = %.2f, want %.2f", bridge.TotalWithdrawals, want)
+	}
+	if got, want := bridge.PortfolioShare, wantExtra/1000000*100; math.Abs(got-want) > 0.001 {
+		t.Errorf("PortfolioShare = %.3f, want %.3f", got, want)
+	}
+
+	last := bridge.Rungs[4]
+	if last.Age != 66 || last.Amount != 30000 {
+		t.Errorf("last rung at age %d pays %.0f, want 66 and 30000", last.Age, last.Amount)
+	}
+	if want := 30000 / math.Pow(1+BridgeLadderYield/100, 4); math.Abs(last.Cost-want) > 0.01 {
+		t.Errorf("last rung costs %.2f, want %.2f", last.Cost, want)
+	}
+	if bridge.LadderCost >= bridge.ExtraWithdrawals {
+		t.Errorf("LadderCost %.0f should be discounted below %.0f", bridge.LadderCost, bridge.ExtraWithdrawals)
+	}
+
+	// Income covering spending leaves nothing extra to withdraw
+	settings.MonthlyLivingExpenses = 500
+	if covered := NewCalculator(settings).CalculateBridge(); covered.ExtraWithdrawals != 0 || covered.LadderCost != 0 {
+		t.Errorf("covered bridge extra = %.0f, ladder = %.0f; want 0", covered.ExtraWithdrawals, covered.LadderCost)
+	}
+
+	// No delayed income, no bridge
+	settings.IncomeSources = settings.IncomeSources[:1]
+	if none := NewCalculator(settings).CalculateBridge(); none.HasBridge || len(none.Rungs) != 0 {
+		t.Errorf("bridge without delayed income = %+v", none)
+	}
+}
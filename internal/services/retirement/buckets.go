@@ -0,0 +1,111 @@
+package retirement
+
+import (
+	"math"
+
+	"budget2/internal/models"
+)
+
+// bucketLedger tracks each portfolio bucket's balance through a projection.
+// Buckets are scaled to PortfolioValue and their returns shifted by
+// InvestmentReturn's distance from the blended return, which is zero for
+// saved settings, so sensitivity analysis and the solvers that move those
+// two inputs move every bucket with them.
+type bucketLedger struct {
+	buckets  []models.PortfolioBucket
+	balances []float64
+	rates    []float64 // Monthly returns
+	order    []int     // Withdrawal order
+	empty    []*float64
+}
+
+// newBucketLedger returns a ledger for the settings' buckets, or nil when
+// the portfolio isn't modeled as buckets or they hold nothing
+func newBucketLedger(s *models.WhatIfSettings) *bucketLedger {
+	total := s.BucketsTotal()
+	if !s.HasPortfolioBuckets() || total <= 0 {
+		return nil
+	}
+
+	scale := s.PortfolioValue / total
+	shift := s.InvestmentReturn - s.BlendedBucketReturn()
+	l := &bucketLedger{
+		buckets:  s.PortfolioBuckets,
+		balances: make([]float64, len(s.PortfolioBuckets)),
+		rates:    make([]float64, len(s.PortfolioBuckets)),
+		order:    s.BucketWithdrawalOrder(),
+		empty:    make([]*float64, len(s.PortfolioBuckets)),
+	}
+	for i, b := range s.PortfolioBuckets {
+		l.balances[i] = b.Value * scale
+		l.rates[i] = (b.Return + shift) / 100 / 12
+	}
+	return l
+}
+
+// grow applies a month of each bucket's return and returns the total growth
+func (l *bucketLedger) grow() float64 {
+	total := 0.0
+	for i, balance := range l.balances {
+		growth := balance * l.rates[i]
+		l.balances[i] += growth
+		total += growth
+	}
+	return total
+}
+
+// withdraw takes amount from the buckets in priority order, noting the
+// month each one runs out
+func (l *bucketLedger) withdraw(amount float64, month int) {
+	for _, i := range l.order {
+		if amount <= 0 {
+			break
+		}
+		taken := math.Min(amount, l.balances[i])
+		l.balances[i] -= taken
+		amount -= taken
+		if l.balances[i] <= 0.005 && taken > 0 {
+			l.markEmpty(i, month)
+		}
+	}
+}
+
+// clear empties every bucket when the portfolio is depleted
+func (l *bucketLedger) clear(month int) {
+	for i := range l.balances {
+		l.balances[i] = 0
+		l.markEmpty(i, month)
+	}
+}
+
+// markEmpty records the month a bucket first ran out
+func (l *bucketLedger) markEmpty(i, month int) {
+	l.balances[i] = 0
+	if l.empty[i] == nil {
+		year := float64(month) / 12
+		l.empty[i] = &year
+	}
+}
+
+// snapshot copies the current balances for a projection month
+func (l *bucketLedger) snapshot() []float64 {
+	return append([]float64(nil), l.balances...)
+}
+
+// results summarizes each bucket from its starting balance to the end of
+// the projection
+func (l *bucketLedger) results(start []float64) []models.BucketResult {
+	results := make([]models.BucketResult, len(l.buckets))
+	for i, b := range l.buckets {
+		results[i] = models.BucketResult{
+			ID:           b.ID,
+			Name:         b.Name,
+			Return:       b.Return,
+			Priority:     b.Priority,
+			StartBalance: start[i],
+			FinalBalance: l.balances[i],
+			EmptyYear:    l.empty[i],
+		}
+	}
+	return results
+}
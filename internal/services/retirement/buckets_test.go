@@ -0,0 +1,175 @@
+package retirement
+
+import (
+	"math"
+	"testing"
+
+	"budget2/internal/models"
+)
+
+func bucketSettings() *models.WhatIfSettings {
+	settings := models.DefaultWhatIfSettings()
+	settings.MonthlyLivingExpenses = 4000
+	settings.MonthlyHealthcare = 0
+	settings.HealthcarePersons = nil
+	settings.ProjectionYears = 30
+	settings.TaxDeferredPercent = 40
+	settings.PortfolioBuckets = []models.PortfolioBucket{
+		{ID: "cash", Name: "Cash", Value: 100000, Return: 2, Priority: 1},
+		{ID: "bonds", Name: "Bonds", Value: 300000, Return: 4, Priority: 2},
+		{ID: "stocks", Name: "Stocks", Value: 600000, Return: 8, Priority: 3},
+	}
+	settings.SyncPortfolioBuckets()
+	return settings
+}
+
+// TestBucketsMatchBlendedReturn verifies buckets that all earn the same
+// return project exactly like a single portfolio at that return
+func TestBucketsMatchBlendedReturn(t *testing.T) {
+	single := bucketSettings()
+	single.PortfolioBuckets = nil
+	single.InvestmentReturn = 5
+
+	buckets := bucketSettings()
+	for i := range buckets.PortfolioBuckets {
+		buckets.PortfolioBuckets[i].Return = 5
+	}
+	buckets.SyncPortfolioBuckets()
+
+	want := NewCalculator(single).RunProjection()
+	got := NewCalculator(buckets).RunProjection()
+	for m := range want.Months {
+		if math.Abs(got.Months[m].PortfolioBalance-want.Months[m].PortfolioBalance) > 0.01 {
+			t.Fatalf("month %d balance = %.2f, want %.2f", m, got.Months[m].PortfolioBalance, want.Months[m].PortfolioBalance)
+		}
+	}
+	if want.Buckets != nil {
+		t.Errorf("single portfolio has bucket results")
+	}
+}
+
+// TestBucketsWithdrawInPriorityOrder verifies withdrawals drain the lowest
+// priority bucket first while the others keep growing
+func TestBucketsWithdrawInPriorityOrder(t *testing.T) {
+	settings := bucketSettings()
+	projection := NewCalculator(settings).RunProjection()
+
+	if len(projection.Buckets) != 3 {
+		t.Fatalf("got %d bucket results, want 3", len(projection.Buckets))
+	}
+	cash, bonds, stocks := projection.Buckets[0], projection.Buckets[1], projection.Buckets[2]
+	if cash.EmptyYear == nil {
+		t.Fatalf("cash bucket never emptied")
+	}
+	// $100k of cash covers $4k a month for a bit over two years
+	if *cash.EmptyYear < 2 || *cash.EmptyYear > 2.5 {
+		t.Errorf("cash emptied in year %.2f, want about 2", *cash.EmptyYear)
+	}
+
+	first := projection.Months[0]
+	if len(first.BucketBalances) != 3 {
+		t.Fatalf("month 0 has %d bucket balances, want 3", len(first.BucketBalances))
+	}
+	if first.BucketBalances[0] >= cash.StartBalance {
+		t.Errorf("cash = %.2f after a withdrawal, want below %.2f", first.BucketBalances[0], cash.StartBalance)
+	}
+	wantStocks := stocks.StartBalance * (1 + 0.08/12)
+	if math.Abs(first.BucketBalances[2]-wantStocks) > 0.01 {
+		t.Errorf("stocks = %.2f after a month, want %.2f", first.BucketBalances[2], wantStocks)
+	}
+
+	var sum float64
+	for _, b := range projection.Months[len(projection.Months)-1].BucketBalances {
+		sum += b
+	}
+	if math.Abs(sum-projection.FinalBalance) > 1 {
+		t.Errorf("buckets sum to %.2f, final balance %.2f", sum, projection.FinalBalance)
+	}
+	if bonds.FinalBalance >= stocks.FinalBalance {
+		t.Errorf("bonds %.2f >= stocks %.2f at the end", bonds.FinalBalance, stocks.FinalBalance)
+	}
+}
+
+// TestBucketsFollowPortfolioInputs verifies analyses that move the
+// portfolio value or return move every bucket with them
+func TestBucketsFollowPortfolioInputs(t *testing.T) {
+	base := NewCalculator(bucketSettings()).RunProjection()
+
+	higher := bucketSettings()
+	higher.InvestmentReturn += 2
+	if got := NewCalculator(higher).RunProjection(); got.FinalBalance <= base.FinalBalance {
+		t.Errorf("final balance %.2f with +2%% return, want above %.2f", got.FinalBalance, base.FinalBalance)
+	}
+
+	halved := bucketSettings()
+	halved.PortfolioValue /= 2
+	got := NewCalculator(halved).RunProjection()
+	if math.Abs(got.Buckets[2].StartBalance-base.Buckets[2].StartBalance/2) > 0.01 {
+		t.Errorf("stocks start at %.2f with half the portfolio, want %.2f", got.Buckets[2].StartBalance, base.Buckets[2].StartBalance/2)
+	}
+}
+
+// TestBucketWithdrawalOrder verifies equal priorities keep list order
+func TestBucketWithdrawalOrder(t *testing.T) {
+	settings := &models.WhatIfSettings{PortfolioBuckets: []models.PortfolioBucket{
+		{Name: "Stocks", Priority: 3},
+		{Name: "Cash", Priority: 1},
+		{Name: "Rental", Priority: 3},
+		{Name: "Bonds", Priority: 2},
+	}}
+	want := []int{1, 3, 0, 2}
+	got := settings.BucketWithdrawalOrder()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+	if next := settings.NextBucketPriority(); next != 4 {
+		t.Errorf("NextBucketPriority = %d, want 4", next)
+	}
+}
+
+// TestSettingsPortfolioBuckets verifies bucket changes keep the portfolio
+// value and return in step
+func TestSettingsPortfolioBuckets(t *testing.T) {
+	sm := newMemorySettingsManager(t)
+
+	if _, err := sm.AddPortfolioBucket(models.PortfolioBucket{ID: "cash", Name: "Cash", Value: 100000, Return: 2, Priority: 1}); err != nil {
+		t.Fatalf("AddPortfolioBucket failed: %v", err)
+	}
+	settings, err := sm.AddPortfolioBucket(models.PortfolioBucket{ID: "stocks", Name: "Stocks", Value: 300000, Return: 8, Priority: 2})
+	if err != nil {
+		t.Fatalf("AddPortfolioBucket failed: %v", err)
+	}
+	if settings.PortfolioValue != 400000 || math.Abs(settings.InvestmentReturn-6.5) > 1e-9 {
+		t.Errorf("portfolio = %.0f at %.2f%%, want 400000 at 6.50%%", settings.PortfolioValue, settings.InvestmentReturn)
+	}
+
+	// The portfolio slider can't override the buckets
+	settings, err = sm.UpdateSettings(map[string]interface{}{"portfolio_value": 50000.0, "investment_return": 3.0})
+	if err != nil {
+		t.Fatalf("UpdateSettings failed: %v", err)
+	}
+	if settings.PortfolioValue != 400000 || math.Abs(settings.InvestmentReturn-6.5) > 1e-9 {
+		t.Errorf("after UpdateSettings portfolio = %.0f at %.2f%%, want 400000 at 6.50%%", settings.PortfolioValue, settings.InvestmentReturn)
+	}
+
+	settings, err = sm.UpdatePortfolioBucket("stocks", 100000, 6, 0)
+	if err != nil {
+		t.Fatalf("UpdatePortfolioBucket failed: %v", err)
+	}
+	if settings.PortfolioValue != 200000 || settings.BucketWithdrawalOrder()[0] != 1 {
+		t.Errorf("after update portfolio = %.0f, order %v", settings.PortfolioValue, settings.BucketWithdrawalOrder())
+	}
+
+	settings, err = sm.RemovePortfolioBucket("cash")
+	if err != nil {
+		t.Fatalf("RemovePortfolioBucket failed: %v", err)
+	}
+	if len(settings.PortfolioBuckets) != 1 || settings.PortfolioValue != 100000 || settings.InvestmentReturn != 6 {
+		t.Errorf("after remove: %d buckets, portfolio %.0f at %.2f%%", len(settings.PortfolioBuckets), settings.PortfolioValue, settings.InvestmentReturn)
+	}
+	if _, ok := settings.Changes["portfolio_buckets/stocks"]; !ok {
+		t.Errorf("bucket change not recorded: %v", settings.Changes)
+	}
+}
@@ -15,7 +15,15 @@ type Calculator struct {
 }
 
 // NewCalculator creates a new retirement calculator with the given settings
+// Age-timed income sources are resolved against CurrentAge on a copy, so the
+// caller's settings are left untouched.
 func NewCalculator(settings *models.WhatIfSettings) *Calculator {
+	if settings.HasAgeTimedIncome() {
+		resolved := *settings
+		resolved.IncomeSources = append([]models.IncomeSource{}, settings.IncomeSources...)
+		resolved.ResolveIncomeAges()
+		settings = &resolved
+	}
 	return &Calculator{Settings: settings}
 }
 
@@ -113,22 +121,34 @@ func (c *Calculator) calculateHealthcarePV(person models.HealthcarePerson, disco
 func (c *Calculator) CalculateTotalIncome(month int) float64 {
 	total := 0.0
 	for _, source := range c.Settings.IncomeSources {
-		total += source.GetAdjustedAmount(month)
+		total += source.GetAdjustedAmount(month, c.Settings.InflationRate)
 	}
 	return total
 }
 
+// livingExpenses returns monthly living expenses for a month split at the
+// essential floor: the floor grows with inflation, and the rest grows with
+// inflation less the spending decline
+func (c *Calculator) livingExpenses(month int) (essential, declining float64) {
+	s := c.Settings
+	essential = s.EssentialLivingExpenses()
+	declining = s.MonthlyLivingExpenses - essential
+	if month > 0 {
+		years := float64(month / 12)
+		netInflation := (s.InflationRate - s.SpendingDeclineRate) / 100
+		essential *= math.Pow(1+s.InflationRate/100, years)
+		declining *= math.Pow(1+netInflation, years)
+	}
+	return essential, declining
+}
+
 // CalculateTotalExpenses returns total expenses for a specific month
 func (c *Calculator) CalculateTotalExpenses(month int) float64 {
 	s := c.Settings
 
 	// Calculate living expenses with inflation and spending decline
-	livingExpenses := s.MonthlyLivingExpenses
-	if month > 0 {
-		years := month / 12
-		netInflation := (s.InflationRate - s.SpendingDeclineRate) / 100
-		livingExpenses = s.MonthlyLivingExpenses * math.Pow(1+netInflation, float64(years))
-	}
+	essential, declining := c.livingExpenses(month)
+	livingExpenses := essential + declining
 
 	// Calculate healthcare expenses using the settings helper (handles both legacy and multi-person)
 	healthcareExpenses := s.GetTotalHealthcareCost(month)
@@ -152,19 +172,18 @@ type ExpenseBreakdown struct {
 func (c *Calculator) CalculateExpenseBreakdown(month int) ExpenseBreakdown {
 	s := c.Settings
 
-	// Base living expenses are treated as essential (conservative approach)
-	livingExpenses := s.MonthlyLivingExpenses
-	if month > 0 {
-		years := month / 12
-		netInflation := (s.InflationRate - s.SpendingDeclineRate) / 100
-		livingExpenses = s.MonthlyLivingExpenses * math.Pow(1+netInflation, float64(years))
+	// Living expenses above the essential floor are discretionary. Without
+	// a floor they're all treated as essential (conservative approach).
+	livingEssential, livingDiscretionary := c.livingExpenses(month)
+	if !s.HasEssentialFloor() {
+		livingEssential, livingDiscretionary = livingEssential+livingDiscretionary, 0
 	}
 
 	// Healthcare is always essential
 	healthcareExpenses := s.GetTotalHealthcareCost(month)
 
-	essential := livingExpenses + healthcareExpenses
-	discretionary := 0.0
+	essential := livingEssential + healthcareExpenses
+	discretionary := livingDiscretionary
 
 	// Categorize expense sources
 	for _, source := range s.ExpenseSources {
@@ -193,10 +212,18 @@ func (c *Calculator) RunProjection() *models.ProjectionResult {
 	taxDeferredBalance := s.PortfolioValue * (s.TaxDeferredPercent / 100)
 	taxableBalance := s.PortfolioValue - taxDeferredBalance
 
+	// Buckets, when set, grow at their own returns and fund withdrawals in
+	// priority order; the tax split above shares their growth pro rata
+	buckets := newBucketLedger(s)
+	var bucketStart []float64
+	if buckets != nil {
+		bucketStart = buckets.snapshot()
+	}
+
 	var depletionMonth *int
 	var longevityYears *float64
 
-	currentLivingExpenses := s.MonthlyLivingExpenses
+	essentialExpenses, decliningExpenses := c.livingExpenses(0)
 
 	// Track annual RMD (calculated once per year, distributed monthly)
 	var annualRMD float64
@@ -209,7 +236,8 @@ func (c *Calculator) RunProjection() *models.ProjectionResult {
 		if m%12 == 0 {
 			if m > 0 {
 				netInflation := (s.InflationRate - s.SpendingDeclineRate) / 100
-				currentLivingExpenses *= (1 + netInflation)
+				essentialExpenses *= (1 + s.InflationRate/100)
+				decliningExpenses *= (1 + netInflation)
 			}
 
 			// Calculate annual RMD at start of each year (age 73+)
@@ -223,6 +251,7 @@ func (c *Calculator) RunProjection() *models.ProjectionResult {
 		}
 
 		// Calculate healthcare expenses using multi-person model
+		currentLivingExpenses := essentialExpenses + decliningExpenses
 		activeHealthcare := s.GetTotalHealthcareCost(m)
 		totalExpenses := currentLivingExpenses + activeHealthcare
 
@@ -240,6 +269,13 @@ func (c *Calculator) RunProjection() *models.ProjectionResult {
 		// Apply investment growth to both portions
 		taxDeferredGrowth := taxDeferredBalance * (s.InvestmentReturn / 100 / 12)
 		taxableGrowth := taxableBalance * (s.InvestmentReturn / 100 / 12)
+		if buckets != nil {
+			bucketGrowth := buckets.grow()
+			if balance := taxDeferredBalance + taxableBalance; balance > 0 {
+				taxDeferredGrowth = bucketGrowth * taxDeferredBalance / balance
+				taxableGrowth = bucketGrowth * taxableBalance / balance
+			}
+		}
 		totalGrowth := taxDeferredGrowth + taxableGrowth
 
 		taxDeferredBalance += taxDeferredGrowth
@@ -285,6 +321,10 @@ func (c *Calculator) RunProjection() *models.ProjectionResult {
 			}
 		}
 
+		if buckets != nil {
+			buckets.withdraw(actualWithdrawal, m)
+		}
+
 		totalBalance := taxDeferredBalance + taxableBalance
 		depleted := false
 		if totalBalance <= 0 {
@@ -292,6 +332,9 @@ func (c *Calculator) RunProjection() *models.ProjectionResult {
 			taxableBalance = 0
 			totalBalance = 0
 			depleted = true
+			if buckets != nil {
+				buckets.clear(m)
+			}
 			if depletionMonth == nil {
 				dm := m
 				depletionMonth = &dm
@@ -315,6 +358,9 @@ func (c *Calculator) RunProjection() *models.ProjectionResult {
 			PortfolioGrowth:    totalGrowth,
 			Depleted:           depleted,
 		})
+		if buckets != nil {
+			projection[len(projection)-1].BucketBalances = buckets.snapshot()
+		}
 	}
 
 	finalBalance := 0.0
@@ -322,13 +368,17 @@ func (c *Calculator) RunProjection() *models.ProjectionResult {
 		finalBalance = projection[len(projection)-1].PortfolioBalance
 	}
 
-	return &models.ProjectionResult{
+	result := &models.ProjectionResult{
 		Months:         projection,
 		LongevityYears: longevityYears,
 		FinalBalance:   finalBalance,
 		DepletionMonth: depletionMonth,
 		Survives:       depletionMonth == nil,
 	}
+	if buckets != nil {
+		result.Buckets = buckets.results(bucketStart)
+	}
+	return result
 }
 
 // CalculateBudgetFit analyzes monthly budget gap
@@ -473,9 +523,14 @@ func (c *Calculator) CalculatePresentValueAnalysis() *models.PresentValueAnalysi
 	// Calculate PV of expenses
 	pvExpenses := 0.0
 
-	// Living expenses with inflation - spending decline
+	// Living expenses with inflation - spending decline, except the
+	// essential floor which only inflates
 	netInflation := s.InflationRate - s.SpendingDeclineRate
-	pvExpenses += PresentValueAnnuity(s.MonthlyLivingExpenses, discountRate, netInflation, 0, months)
+	essential := s.EssentialLivingExpenses()
+	pvExpenses += PresentValueAnnuity(s.MonthlyLivingExpenses-essential, discountRate, netInflation, 0, months)
+	if essential > 0 {
+		pvExpenses += PresentValueAnnuity(essential, discountRate, s.InflationRate, 0, months)
+	}
 
 	// Healthcare expenses using multi-person model or legacy
 	if len(s.HealthcarePersons) > 0 {
@@ -518,7 +573,7 @@ func (c *Calculator) CalculatePresentValueAnalysis() *models.PresentValueAnalysi
 		}
 		duration := endMonth - source.StartMonth
 		if duration > 0 {
-			pvIncome += PresentValueAnnuity(source.Amount, discountRate, source.COLARate*100, source.StartMonth, duration)
+			pvIncome += PresentValueAnnuity(source.Amount, discountRate, source.EffectiveCOLA(s.InflationRate)*100, source.StartMonth, duration)
 		}
 	}
 
@@ -541,7 +596,9 @@ func (c *Calculator) CalculatePresentValueAnalysis() *models.PresentValueAnalysi
 // CalculateSustainabilityScore computes the sustainability score
 func (c *Calculator) CalculateSustainabilityScore(projection *models.ProjectionResult) *models.SustainabilityScore {
 	budgetFit := c.CalculateBudgetFit()
-	return models.CalculateSustainabilityScore(budgetFit.RequiredRate, projection.Survives)
+	score := models.CalculateSustainabilityScore(budgetFit.RequiredRate, projection.Survives)
+	score.ApplyLegacyTarget(c.realValue(projection.FinalBalance, float64(c.Settings.ProjectionYears)), c.Settings.LegacyTarget)
+	return score
 }
 
 // CalculateSensitivity runs sensitivity analysis on key parameters
@@ -884,6 +941,15 @@ type MonteCarloConfig struct {
 	CrashProbability    float64 // Annual probability of a crash (e.g., 0.05 for 5%)
 	CrashSeverity       float64 // How bad crashes are (e.g., -30 for -30% return)
 	RecoveryBoost       float64 // Extra return after crash years (mean reversion)
+	RecoveryShape       string  // models.RecoveryV, RecoveryU or RecoveryL
+	BearMarketYears     int     // Longest a crash can run on, in years (1 = single-year crashes)
+
+	// Inflation regimes
+	HighInflationProb        float64 // Annual chance of entering a high-inflation regime
+	HighInflationPersistence float64 // Chance a high-inflation year is followed by another
+	HighInflationPremium     float64 // Extra inflation in high-inflation years (percentage points)
+	InflationVolatility      float64 // Std dev of inflation around the regime's mean
+	InflationCorrelation     float64 // 0-1: how strongly excess inflation cuts the year's return
 
 	// Spending shocks
 	SpendingShockProb   float64 // Annual probability of spending shock
@@ -895,13 +961,20 @@ type MonteCarloConfig struct {
 	HealthShockMin      float64 // Minimum health shock ($)
 	HealthShockMax      float64 // Maximum health shock ($)
 
-	// Longevity
-	LongevityVariation  int     // Years +/- to vary projection length
+	// Longevity: sample each run's horizon from the mortality table instead of
+	// using the fixed ProjectionYears
+	MortalityHorizon    bool
+	MortalityMultiplier float64 // Scales death rates for health (1 = population average)
+	Sex                 string  // models.SexMale, SexFemale or "" to blend both
 
 	// Adaptive spending (reducing discretionary expenses during crashes)
 	AdaptiveSpending          bool    // Enable adaptive spending during crashes
 	DiscretionaryCutPercent   float64 // % to cut discretionary spending during crash (e.g., 40 for 40%)
 	AdaptationRecoveryYears   int     // Years to maintain reduced spending after crash
+
+	// Stress scenario: fixed returns and inflation for the first years
+	StressReturns   []float64 // Annual returns (%) replacing generated ones
+	StressInflation []float64 // Annual inflation (%) applied to living expenses
 }
 
 // DefaultMonteCarloConfig returns realistic simulation parameters
@@ -912,6 +985,16 @@ func DefaultMonteCarloConfig() *MonteCarloConfig {
 		CrashProbability:   0.05,
 		CrashSeverity:      -30.0,
 		RecoveryBoost:      5.0,
+		RecoveryShape:      models.RecoveryV,
+		BearMarketYears:    1,
+
+		// Inflation: 8% chance of a high-inflation regime (+5%) that tends to
+		// persist, with returns falling in those years
+		HighInflationProb:        0.08,
+		HighInflationPersistence: 0.6,
+		HighInflationPremium:     5.0,
+		InflationVolatility:      1.0,
+		InflationCorrelation:     0.5,
 
 		// Spending: 8% chance of $5K-$25K emergency per year
 		SpendingShockProb:  0.08,
@@ -923,18 +1006,36 @@ func DefaultMonteCarloConfig() *MonteCarloConfig {
 		HealthShockMin:     10000,
 		HealthShockMax:     50000,
 
-		// Longevity: +/- 5 years from base projection
-		LongevityVariation: 5,
+		// Longevity: lifetimes drawn from the SSA period life table
+		MortalityHorizon:    true,
+		MortalityMultiplier: 1.0,
 	}
 }
 
+// monteCarloConfig returns the default simulation parameters with the
+// scenario's crash recovery shape and bear market length
+func (c *Calculator) monteCarloConfig() *MonteCarloConfig {
+	config := DefaultMonteCarloConfig()
+	config.RecoveryShape = c.Settings.RecoveryShape()
+	config.BearMarketYears = max(1, c.Settings.BearMarketYears)
+	config.InflationCorrelation = c.Settings.InflationCorrelation
+	config.MortalityMultiplier = HealthMultiplier(c.Settings.Health)
+	config.Sex = c.Settings.Sex
+	return config
+}
+
 // RunMonteCarloSimulation runs enhanced randomized scenario analysis
 func (c *Calculator) RunMonteCarloSimulation(runs int) *models.MonteCarloAnalysis {
+	return c.runMonteCarlo(runs, c.monteCarloConfig(), time.Now().UnixNano())
+}
+
+// runMonteCarlo runs the simulation with the given config, seeding the random
+// source so that runs can be compared on the same sequence of draws
+func (c *Calculator) runMonteCarlo(runs int, config *MonteCarloConfig, seed int64) *models.MonteCarloAnalysis {
 	if runs <= 0 {
 		runs = 1000
 	}
 
-	config := DefaultMonteCarloConfig()
 	results := make([]models.MonteCarloResult, runs)
 	successCount := 0
 	totalDepletionYears := 0.0
@@ -948,8 +1049,7 @@ func (c *Calculator) RunMonteCarloSimulation(runs int) *models.MonteCarloAnalysi
 	runsWithSpendingShocks := 0
 	runsWithHealthShocks := 0
 
-	// Create a new random source seeded with current time
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	rng := rand.New(rand.NewSource(seed))
 
 	for i := 0; i < runs; i++ {
 		result := c.runSingleMonteCarloSimulation(rng, config)
@@ -1010,6 +1110,34 @@ func (c *Calculator) RunMonteCarloSimulation(runs int) *models.MonteCarloAnalysi
 		stats.AvgDepletionYr = totalDepletionYears / float64(depletionCount)
 	}
 
+	// Runs last different lengths, so each estate is deflated by its own run
+	realBalances := make([]float64, runs)
+	legacyCount := 0
+	for i, r := range results {
+		realBalances[i] = c.realValue(r.FinalBalance, float64(r.ProjectionYears))
+		if realBalances[i] >= c.Settings.LegacyTarget {
+			legacyCount++
+		}
+	}
+	sortFloat64s(realBalances)
+	stats.MedianEstateReal = realBalances[runs/2]
+	stats.LegacySuccessRate = float64(legacyCount) / float64(runs) * 100
+
+	if config.MortalityHorizon {
+		lifespans := make([]float64, runs)
+		outlived := 0
+		for i, r := range results {
+			lifespans[i] = float64(r.ProjectionYears)
+			if r.ProjectionYears > c.Settings.ProjectionYears {
+				outlived++
+			}
+		}
+		sortFloat64s(lifespans)
+		stats.MortalityHorizon = true
+		stats.MedianDeathAge = c.Settings.CurrentAge + int(lifespans[runs/2])
+		stats.OutlivePlanRate = float64(outlived) / float64(runs) * 100
+	}
+
 	// Calculate sequence risk impact by comparing early vs late crash outcomes
 	stats.SequenceRiskImpact = c.calculateSequenceRiskImpact(results)
 
@@ -1077,11 +1205,11 @@ func (c *Calculator) RunMonteCarloSimulation(runs int) *models.MonteCarloAnalysi
 func (c *Calculator) runSingleMonteCarloSimulation(rng *rand.Rand, config *MonteCarloConfig) models.MonteCarloResult {
 	s := c.Settings
 
-	// Vary projection length for longevity risk
+	// Run until death for longevity risk, so success means the money lasted
+	// as long as you did
 	projectionYears := s.ProjectionYears
-	if config.LongevityVariation > 0 {
-		variation := rng.Intn(config.LongevityVariation*2+1) - config.LongevityVariation
-		projectionYears = max(10, s.ProjectionYears+variation)
+	if config.MortalityHorizon {
+		projectionYears = SampleLifespan(rng, s.CurrentAge, config.Sex, config.MortalityMultiplier)
 	}
 	months := projectionYears * 12
 
@@ -1092,7 +1220,7 @@ func (c *Calculator) runSingleMonteCarloSimulation(rng *rand.Rand, config *Monte
 	var depletionYear float64
 	depleted := false
 
-	currentLivingExpenses := s.MonthlyLivingExpenses
+	essentialExpenses, decliningExpenses := c.livingExpenses(0)
 
 	// Track shocks for this run
 	crashTiming := &CrashTiming{}
@@ -1109,8 +1237,10 @@ func (c *Calculator) runSingleMonteCarloSimulation(rng *rand.Rand, config *Monte
 	// Adaptive spending: track when we're in reduced-spending mode
 	adaptationEndYear := -1 // Year when adaptation ends (-1 = not adapting)
 
-	// Generate year-by-year returns upfront for sequence of returns
+	// Generate year-by-year inflation and returns upfront for sequence of returns
+	yearlyInflation := c.generateYearlyInflation(rng, config, projectionYears)
 	yearlyReturns := c.generateYearlyReturns(rng, config, projectionYears, crashTiming, &lastCrashYear)
+	c.correlateReturns(config, yearlyReturns, yearlyInflation)
 
 	for m := 0; m < months; m++ {
 		if depleted {
@@ -1123,10 +1253,14 @@ func (c *Calculator) runSingleMonteCarloSimulation(rng *rand.Rand, config *Monte
 		// Annual adjustments at year boundaries
 		if m%12 == 0 {
 			if m > 0 {
-				// Apply inflation with some random variation
-				inflationVar := 1 + (rng.Float64()-0.5)*0.02 // +/- 1%
-				netInflation := (s.InflationRate - s.SpendingDeclineRate) / 100 * inflationVar
-				currentLivingExpenses *= (1 + netInflation)
+				// Apply last year's inflation; stressed years use the scenario's
+				inflationRate := yearlyInflation[currentYear-1]
+				if currentYear-1 < len(config.StressInflation) {
+					inflationRate = config.StressInflation[currentYear-1]
+				}
+				netInflation := (inflationRate - s.SpendingDeclineRate) / 100
+				essentialExpenses *= (1 + inflationRate/100)
+				decliningExpenses *= (1 + netInflation)
 			}
 
 			// Healthcare cost variation (healthcare is more volatile, +/- 2%)
@@ -1141,10 +1275,6 @@ func (c *Calculator) runSingleMonteCarloSimulation(rng *rand.Rand, config *Monte
 			}
 		}
 
-		// Calculate healthcare expenses using multi-person model with variation
-		activeHealthcare := s.GetTotalHealthcareCost(m) * healthcareVariation
-		totalExpenses := currentLivingExpenses + activeHealthcare
-
 		// Check if we should enter adaptation mode (crash detected this year)
 		if config.AdaptiveSpending && yearlyReturns[currentYear] < -15 {
 			// Crash year: start adapting spending
@@ -1152,6 +1282,17 @@ func (c *Calculator) runSingleMonteCarloSimulation(rng *rand.Rand, config *Monte
 		}
 		inAdaptationMode := config.AdaptiveSpending && currentYear <= adaptationEndYear
 
+		// Living expenses above an essential floor are cut along with
+		// discretionary expense sources during adaptation
+		currentLivingExpenses := essentialExpenses + decliningExpenses
+		if inAdaptationMode && s.HasEssentialFloor() {
+			currentLivingExpenses = essentialExpenses + decliningExpenses*(1-config.DiscretionaryCutPercent/100)
+		}
+
+		// Calculate healthcare expenses using multi-person model with variation
+		activeHealthcare := s.GetTotalHealthcareCost(m) * healthcareVariation
+		totalExpenses := currentLivingExpenses + activeHealthcare
+
 		// Add expense sources (with adaptive spending reduction if applicable)
 		for _, source := range s.ExpenseSources {
 			expenseAmount := source.GetAdjustedAmount(m, s.InflationRate)
@@ -1179,7 +1320,7 @@ func (c *Calculator) runSingleMonteCarloSimulation(rng *rand.Rand, config *Monte
 		// Calculate income
 		totalIncome := 0.0
 		for _, source := range s.IncomeSources {
-			totalIncome += source.GetAdjustedAmount(m)
+			totalIncome += source.GetAdjustedAmount(m, s.InflationRate)
 		}
 
 		// Monthly cash flow needed from portfolio
@@ -1260,6 +1401,7 @@ func (c *Calculator) runSingleMonteCarloSimulation(rng *rand.Rand, config *Monte
 		MidCrashes:      crashTiming.MidCrashes,
 		LateCrashes:     crashTiming.LateCrashes,
 		FirstCrashYear:  crashTiming.FirstCrashYear,
+		BearMarkets:     crashTiming.BearMarkets,
 	}
 }
 
@@ -1270,44 +1412,133 @@ type CrashTiming struct {
 	MidCrashes     int // Years 6-15 (index 5-14)
 	LateCrashes    int // Years 16+ (index 15+)
 	FirstCrashYear int // 0 means no crashes (1-indexed for display)
+	BearMarkets    int // Crashes that ran on for more than a year
+}
+
+// record counts a crash in year y (0-indexed)
+func (t *CrashTiming) record(y int) {
+	t.TotalCrashes++
+
+	// Track first crash year (1-indexed for human readability)
+	if t.FirstCrashYear == 0 {
+		t.FirstCrashYear = y + 1
+	}
+
+	// Categorize by timing
+	if y < 5 {
+		t.EarlyCrashes++
+	} else if y < 15 {
+		t.MidCrashes++
+	} else {
+		t.LateCrashes++
+	}
+}
+
+// inflationReturnPenalty is how many points of return a point of inflation
+// above the plan's rate costs at full correlation
+const inflationReturnPenalty = 1.5
+
+// generateYearlyInflation creates a sequence of annual inflation rates from a
+// two-state regime model. High-inflation years arrive at random and tend to
+// persist; normal years sit slightly below the plan's rate so that inflation
+// still averages InflationRate over the long run.
+func (c *Calculator) generateYearlyInflation(rng *rand.Rand, config *MonteCarloConfig, years int) []float64 {
+	inflation := make([]float64, years)
+	base := c.Settings.InflationRate
+
+	// Long-run share of high-inflation years for this Markov chain
+	highShare := 0.0
+	if config.HighInflationProb > 0 {
+		highShare = config.HighInflationProb / (config.HighInflationProb + 1 - config.HighInflationPersistence)
+	}
+	normalMean := base - config.HighInflationPremium*highShare
+
+	high := false
+	for y := 0; y < years; y++ {
+		if high {
+			high = rng.Float64() < config.HighInflationPersistence
+		} else {
+			high = rng.Float64() < config.HighInflationProb
+		}
+
+		mean := normalMean
+		if high {
+			mean = normalMean + config.HighInflationPremium
+		}
+		inflation[y] = math.Max(-2, mean+rng.NormFloat64()*config.InflationVolatility)
+	}
+
+	return inflation
 }
 
-// generateYearlyReturns creates a sequence of annual returns with crashes and volatility
+// correlateReturns lowers returns in years of above-plan inflation, and
+// raises them when inflation runs below plan, in proportion to
+// config.InflationCorrelation. Stressed years already carry their own history.
+func (c *Calculator) correlateReturns(config *MonteCarloConfig, returns, inflation []float64) {
+	if config.InflationCorrelation == 0 {
+		return
+	}
+	for y := len(config.StressReturns); y < len(returns); y++ {
+		excess := inflation[y] - c.Settings.InflationRate
+		adjusted := returns[y] - config.InflationCorrelation*inflationReturnPenalty*excess
+		returns[y] = math.Max(-50, math.Min(50, adjusted))
+	}
+}
+
+// Bear market and recovery parameters
+const (
+	bearDeclineFraction = 1.0 / 3 // Each year after the first falls by this fraction of CrashSeverity
+	uRecoveryFlatYears  = 2       // Years of flat returns before a U-shaped rebound
+	uRecoveryBoostYears = 2       // Years of boosted returns after the flat bottom
+	lRecoveryYears      = 5       // Years of half returns after an L-shaped crash
+)
+
+// generateYearlyReturns creates a sequence of annual returns with crashes and volatility.
+// A crash may run on as a multi-year bear market of smaller declines, and is
+// followed by the configured recovery shape once the declines end.
 func (c *Calculator) generateYearlyReturns(rng *rand.Rand, config *MonteCarloConfig, years int, timing *CrashTiming, lastCrashYear *int) []float64 {
 	returns := make([]float64, years)
 	baseReturn := c.Settings.InvestmentReturn
+	bearYearsLeft := 0 // Remaining decline years of the current bear market
 
 	for y := 0; y < years; y++ {
 		var yearReturn float64
+		crashed := false
 
-		// Check for market crash
-		if rng.Float64() < config.CrashProbability {
+		if bearYearsLeft > 0 {
+			// Bear market continues: a smaller decline than the initial crash
+			yearReturn = config.CrashSeverity*bearDeclineFraction + (rng.Float64()-0.5)*10
+			bearYearsLeft--
+			*lastCrashYear = y
+		} else if rng.Float64() < config.CrashProbability {
 			// Crash year: severe negative return
 			yearReturn = config.CrashSeverity + (rng.Float64()-0.5)*10 // -35% to -25%
-			timing.TotalCrashes++
+			crashed = true
 			*lastCrashYear = y
 
-			// Track first crash year (1-indexed for human readability)
-			if timing.FirstCrashYear == 0 {
-				timing.FirstCrashYear = y + 1
-			}
-
-			// Categorize by timing
-			if y < 5 {
-				timing.EarlyCrashes++
-			} else if y < 15 {
-				timing.MidCrashes++
-			} else {
-				timing.LateCrashes++
+			if config.BearMarketYears > 1 {
+				bearYearsLeft = rng.Intn(config.BearMarketYears)
+				if bearYearsLeft > 0 {
+					timing.BearMarkets++
+				}
 			}
-		} else if y == *lastCrashYear+1 {
-			// Recovery year after crash: typically strong
-			yearReturn = baseReturn + config.RecoveryBoost + rng.NormFloat64()*8
+		} else if since := y - *lastCrashYear; since <= recoveryYears(config.RecoveryShape) {
+			yearReturn = recoveryReturn(rng, config, baseReturn, since)
 		} else {
 			// Normal year: base return with volatility (normal distribution)
 			yearReturn = baseReturn + rng.NormFloat64()*config.ReturnVolatility
 		}
 
+		// Stressed years replay the scenario instead. The random draws above
+		// still happen so the rest of the run matches an unstressed one.
+		if y < len(config.StressReturns) {
+			yearReturn = config.StressReturns[y]
+			crashed = yearReturn < StressCrashReturn
+		}
+		if crashed {
+			timing.record(y)
+		}
+
 		// Clamp to reasonable bounds (-50% to +50%)
 		yearReturn = math.Max(-50, math.Min(50, yearReturn))
 		returns[y] = yearReturn
@@ -1316,6 +1547,38 @@ func (c *Calculator) generateYearlyReturns(rng *rand.Rand, config *MonteCarloCon
 	return returns
 }
 
+// recoveryYears returns how many years a recovery shape lasts after the
+// last decline
+func recoveryYears(shape string) int {
+	switch shape {
+	case models.RecoveryU:
+		return uRecoveryFlatYears + uRecoveryBoostYears
+	case models.RecoveryL:
+		return lRecoveryYears
+	default:
+		return 1
+	}
+}
+
+// recoveryReturn returns the year's return the given number of years into a
+// recovery, shaped by config.RecoveryShape
+func recoveryReturn(rng *rand.Rand, config *MonteCarloConfig, baseReturn float64, since int) float64 {
+	switch config.RecoveryShape {
+	case models.RecoveryU:
+		// Flat bottom, then a strong rebound
+		if since <= uRecoveryFlatYears {
+			return rng.NormFloat64() * 8
+		}
+		return baseReturn + config.RecoveryBoost + rng.NormFloat64()*8
+	case models.RecoveryL:
+		// No rebound: returns stay depressed for years
+		return baseReturn/2 + rng.NormFloat64()*config.ReturnVolatility
+	default:
+		// Recovery year after crash: typically strong
+		return baseReturn + config.RecoveryBoost + rng.NormFloat64()*8
+	}
+}
+
 // calculateSequenceRiskImpact measures how sequence of returns affected outcomes
 func (c *Calculator) calculateSequenceRiskImpact(results []models.MonteCarloResult) float64 {
 	// Compare success rates of runs with crashes vs without
@@ -1366,6 +1629,10 @@ func (c *Calculator) calculateSequenceRiskBreakdown(results []models.MonteCarloR
 	var midCrashSurvived, midCrashTotal int
 	var lateCrashSurvived, lateCrashTotal int
 
+	// Track survival by crash shape
+	var bearSurvived, bearTotal int
+	var singleSurvived, singleTotal int
+
 	// For recovery analysis
 	var earlyRecoveries int
 	var totalFirstCrashYears float64
@@ -1390,6 +1657,19 @@ func (c *Calculator) calculateSequenceRiskBreakdown(results []models.MonteCarloR
 				firstCrashCount++
 			}
 
+			// Categorize by whether any crash ran on into a bear market
+			if r.BearMarkets > 0 {
+				bearTotal++
+				if r.Survives {
+					bearSurvived++
+				}
+			} else {
+				singleTotal++
+				if r.Survives {
+					singleSurvived++
+				}
+			}
+
 			// Categorize by earliest crash (most impactful)
 			if hasEarlyCrash {
 				earlyCrashTotal++
@@ -1523,6 +1803,13 @@ func (c *Calculator) calculateSequenceRiskBreakdown(results []models.MonteCarloR
 		HasDiscretionary:     hasDiscretionary,
 		MonthlyDiscretionary: expenseBreakdown.Discretionary,
 		MonthlyEssential:     expenseBreakdown.Essential,
+
+		// Crash shape fields
+		RecoveryShape:       c.Settings.RecoveryShape(),
+		BearMarketSurvival:  safeDiv(bearSurvived, bearTotal),
+		BearMarketCount:     bearTotal,
+		SingleCrashSurvival: safeDiv(singleSurvived, singleTotal),
+		SingleCrashCount:    singleTotal,
 	}
 }
 
@@ -1631,17 +1918,27 @@ func mean(a []float64) float64 {
 
 // RunFullAnalysis performs complete what-if analysis
 func (c *Calculator) RunFullAnalysis() *models.WhatIfAnalysis {
-	projection := c.RunProjection()
+	return c.runFullAnalysis(DefaultEngine, c)
+}
+
+// runFullAnalysis performs complete what-if analysis, taking the projection,
+// Monte Carlo and sensitivity results from the named engine
+func (c *Calculator) runFullAnalysis(engineName string, engine RetirementEngine) *models.WhatIfAnalysis {
+	projection := engine.Project()
 	budgetFit := c.CalculateBudgetFit()
 	presentValue := c.CalculatePresentValueAnalysis()
 	sustainability := c.CalculateSustainabilityScore(projection)
-	sensitivity := c.CalculateSensitivity()
+	sensitivity := engine.Sensitivity()
 	failurePoints := c.CalculateFailurePoints()
-	monteCarlo := c.RunMonteCarloSimulation(1000)
+	monteCarlo := engine.MonteCarlo(1000)
 	rmd := c.CalculateRMDAnalysis()
+	estate := c.CalculateEstate(projection, monteCarlo)
+	bridge := c.CalculateBridge()
+	warnings := SanityWarnings(c.Settings)
 
 	return &models.WhatIfAnalysis{
 		Settings:       c.Settings,
+		Engine:         engineName,
 		Projection:     projection,
 		BudgetFit:      budgetFit,
 		PresentValue:   presentValue,
@@ -1650,5 +1947,8 @@ func (c *Calculator) RunFullAnalysis() *models.WhatIfAnalysis {
 		FailurePoints:  failurePoints,
 		MonteCarlo:     monteCarlo,
 		RMD:            rmd,
+		Estate:         estate,
+		Bridge:         bridge,
+		Warnings:       warnings,
 	}
 }
@@ -37,8 +37,8 @@ func TestDefaultMonteCarloConfig(t *testing.T) {
 		})
 	}
 
-	if config.LongevityVariation != 5 {
-		t.Errorf("LongevityVariation = %d, want 5", config.LongevityVariation)
+	if !config.MortalityHorizon || config.MortalityMultiplier != 1 {
+		t.Errorf("MortalityHorizon = %v at %.1fx, want mortality table horizons at 1x", config.MortalityHorizon, config.MortalityMultiplier)
 	}
 }
 
@@ -144,6 +144,134 @@ func TestGenerateYearlyReturns(t *testing.T) {
 			t.Errorf("expected first crash in year 1, got %d", timing.FirstCrashYear)
 		}
 	})
+
+	t.Run("bear markets extend declines", func(t *testing.T) {
+		config := &MonteCarloConfig{
+			ReturnVolatility: 15.0,
+			CrashProbability: 0.1,
+			CrashSeverity:    -30.0,
+			RecoveryBoost:    5.0,
+			BearMarketYears:  3,
+		}
+
+		bears, crashes := 0, 0
+		for seed := int64(0); seed < 200; seed++ {
+			rng := rand.New(rand.NewSource(seed))
+			timing := &CrashTiming{}
+			lastCrash := -999
+			calc.generateYearlyReturns(rng, config, 30, timing, &lastCrash)
+			bears += timing.BearMarkets
+			crashes += timing.TotalCrashes
+		}
+
+		// A crash runs on for 0, 1 or 2 more years with equal chance
+		if crashes == 0 {
+			t.Fatal("expected crashes with 10% probability")
+		}
+		if share := float64(bears) / float64(crashes); share < 0.55 || share > 0.8 {
+			t.Errorf("%d of %d crashes became bear markets, want about 2/3", bears, crashes)
+		}
+
+		// With a crash every chance, every year is a crash or a bear market decline
+		config.CrashProbability = 1.0
+		timing := &CrashTiming{}
+		lastCrash := -999
+		returns := calc.generateYearlyReturns(rand.New(rand.NewSource(7)), config, 30, timing, &lastCrash)
+		if timing.TotalCrashes >= 30 || timing.BearMarkets == 0 {
+			t.Errorf("got %d crashes and %d bear markets in 30 years", timing.TotalCrashes, timing.BearMarkets)
+		}
+		for y, r := range returns {
+			if r < -35 || r > -5 {
+				t.Errorf("year %d return %.1f, want a decline between -35 and -5", y, r)
+			}
+		}
+	})
+
+	t.Run("recovery shapes follow the last decline", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		config := &MonteCarloConfig{ReturnVolatility: 0, RecoveryBoost: 5.0}
+		average := func(since int) float64 {
+			total := 0.0
+			for i := 0; i < 2000; i++ {
+				total += recoveryReturn(rng, config, 7, since)
+			}
+			return total / 2000
+		}
+
+		config.RecoveryShape = models.RecoveryV
+		if got := average(1); math.Abs(got-12) > 1 || recoveryYears(config.RecoveryShape) != 1 {
+			t.Errorf("V recovery averages %.1f over %d years, want about 12 over 1", got, recoveryYears(config.RecoveryShape))
+		}
+
+		config.RecoveryShape = models.RecoveryU
+		if flat, boosted := average(2), average(3); math.Abs(flat) > 1 || math.Abs(boosted-12) > 1 {
+			t.Errorf("U recovery averages %.1f then %.1f, want about 0 then 12", flat, boosted)
+		}
+		if recoveryYears(config.RecoveryShape) != 4 {
+			t.Errorf("U recovery lasts %d years, want 4", recoveryYears(config.RecoveryShape))
+		}
+
+		config.RecoveryShape = models.RecoveryL
+		if got := recoveryReturn(rng, config, 7, 5); got != 3.5 || recoveryYears(config.RecoveryShape) != 5 {
+			t.Errorf("L recovery = %.1f over %d years, want 3.5 over 5", got, recoveryYears(config.RecoveryShape))
+		}
+	})
+}
+
+// TestGenerateYearlyInflation verifies the regime model averages the plan's
+// inflation rate while producing persistent high-inflation spells
+func TestGenerateYearlyInflation(t *testing.T) {
+	settings := models.DefaultWhatIfSettings()
+	calc := NewCalculator(settings)
+	config := DefaultMonteCarloConfig()
+	rng := rand.New(rand.NewSource(42))
+
+	inflation := calc.generateYearlyInflation(rng, config, 20000)
+	if avg := mean(inflation); math.Abs(avg-settings.InflationRate) > 0.15 {
+		t.Errorf("average inflation = %.2f, want about %.1f", avg, settings.InflationRate)
+	}
+
+	// Long-run share of high years is 0.08 / (0.08 + 0.4) = 1/6
+	high, runs := 0, 0
+	for y, v := range inflation {
+		if v > settings.InflationRate+2.5 {
+			high++
+			if y > 0 && inflation[y-1] > settings.InflationRate+2.5 {
+				runs++
+			}
+		}
+	}
+	if share := float64(high) / float64(len(inflation)); share < 0.12 || share > 0.22 {
+		t.Errorf("high-inflation share = %.2f, want about 0.17", share)
+	}
+	if persisted := float64(runs) / float64(high); persisted < 0.45 {
+		t.Errorf("%.2f of high-inflation years followed another, want regimes to persist", persisted)
+	}
+}
+
+// TestCorrelateReturns verifies excess inflation lowers returns in proportion
+// to the correlation, leaving stressed years alone
+func TestCorrelateReturns(t *testing.T) {
+	calc := NewCalculator(models.DefaultWhatIfSettings()) // 3% inflation
+	config := &MonteCarloConfig{InflationCorrelation: 0.5, StressReturns: []float64{-37}}
+
+	returns := []float64{-37, 6, 6, 6}
+	calc.correlateReturns(config, returns, []float64{13, 9, 3, 1})
+
+	// 0.5 x 1.5 = 0.75 points of return per point of excess inflation
+	want := []float64{-37, 1.5, 6, 7.5}
+	for y := range want {
+		if math.Abs(returns[y]-want[y]) > 1e-9 {
+			t.Errorf("year %d return = %.2f, want %.2f", y, returns[y], want[y])
+		}
+	}
+
+	config.InflationCorrelation = 0
+	independent := []float64{6}
+	calc.correlateReturns(config, independent, []float64{13})
+	if independent[0] != 6 {
+		t.Errorf("uncorrelated return = %.2f, want 6", independent[0])
+	}
 }
 
 // TestRunSingleMonteCarloSimulation tests individual simulation runs
@@ -158,7 +286,7 @@ func TestRunSingleMonteCarloSimulation(t *testing.T) {
 
 		rng := rand.New(rand.NewSource(42))
 		config := DefaultMonteCarloConfig()
-		config.LongevityVariation = 0 // No variation for predictable testing
+		config.MortalityHorizon = false // Fixed horizon for predictable testing
 
 		result := calc.runSingleMonteCarloSimulation(rng, config)
 
@@ -180,7 +308,7 @@ func TestRunSingleMonteCarloSimulation(t *testing.T) {
 
 		rng := rand.New(rand.NewSource(42))
 		config := DefaultMonteCarloConfig()
-		config.LongevityVariation = 0
+		config.MortalityHorizon = false
 
 		result := calc.runSingleMonteCarloSimulation(rng, config)
 
@@ -200,13 +328,12 @@ func TestRunSingleMonteCarloSimulation(t *testing.T) {
 
 		rng := rand.New(rand.NewSource(42))
 		config := &MonteCarloConfig{
-			ReturnVolatility:   15.0,
-			CrashProbability:   0.5, // High crash probability
-			CrashSeverity:      -30.0,
-			RecoveryBoost:      5.0,
-			SpendingShockProb:  0,
-			HealthShockProb:    0,
-			LongevityVariation: 0,
+			ReturnVolatility:  15.0,
+			CrashProbability:  0.5, // High crash probability
+			CrashSeverity:     -30.0,
+			RecoveryBoost:     5.0,
+			SpendingShockProb: 0,
+			HealthShockProb:   0,
 		}
 
 		result := calc.runSingleMonteCarloSimulation(rng, config)
@@ -224,13 +351,12 @@ func TestRunSingleMonteCarloSimulation(t *testing.T) {
 
 		rng := rand.New(rand.NewSource(42))
 		config := &MonteCarloConfig{
-			ReturnVolatility:   15.0,
-			CrashProbability:   0,
-			SpendingShockProb:  0.5, // High shock probability
-			SpendingShockMin:   5000,
-			SpendingShockMax:   25000,
-			HealthShockProb:    0,
-			LongevityVariation: 0,
+			ReturnVolatility:  15.0,
+			CrashProbability:  0,
+			SpendingShockProb: 0.5, // High shock probability
+			SpendingShockMin:  5000,
+			SpendingShockMax:  25000,
+			HealthShockProb:   0,
 		}
 
 		result := calc.runSingleMonteCarloSimulation(rng, config)
@@ -248,13 +374,12 @@ func TestRunSingleMonteCarloSimulation(t *testing.T) {
 
 		rng := rand.New(rand.NewSource(42))
 		config := &MonteCarloConfig{
-			ReturnVolatility:   15.0,
-			CrashProbability:   0,
-			SpendingShockProb:  0,
-			HealthShockProb:    0.5, // High shock probability
-			HealthShockMin:     10000,
-			HealthShockMax:     50000,
-			LongevityVariation: 0,
+			ReturnVolatility:  15.0,
+			CrashProbability:  0,
+			SpendingShockProb: 0,
+			HealthShockProb:   0.5, // High shock probability
+			HealthShockMin:    10000,
+			HealthShockMax:    50000,
 		}
 
 		result := calc.runSingleMonteCarloSimulation(rng, config)
@@ -264,15 +389,16 @@ func TestRunSingleMonteCarloSimulation(t *testing.T) {
 		}
 	})
 
-	t.Run("longevity variation changes projection years", func(t *testing.T) {
+	t.Run("mortality horizon varies projection years", func(t *testing.T) {
 		settings := models.DefaultWhatIfSettings()
 		settings.PortfolioValue = 2000000
 		settings.ProjectionYears = 30
 		calc := NewCalculator(settings)
 
 		config := &MonteCarloConfig{
-			ReturnVolatility:   15.0,
-			LongevityVariation: 5,
+			ReturnVolatility:    15.0,
+			MortalityHorizon:    true,
+			MortalityMultiplier: 1.0,
 		}
 
 		// Run multiple times and check for variation
@@ -572,6 +698,28 @@ func TestCalculateSequenceRiskBreakdown(t *testing.T) {
 			t.Errorf("expected recommended buffer >= 2, got %d", resultLowRisk.Stats.SequenceRisk.RecommendedBuffer)
 		}
 	})
+
+	t.Run("splits crash runs by bear markets", func(t *testing.T) {
+		settings := models.DefaultWhatIfSettings()
+		settings.PortfolioValue = 1000000
+		settings.CrashRecovery = models.RecoveryL
+		settings.BearMarketYears = 3
+		calc := NewCalculator(settings)
+
+		breakdown := calc.RunMonteCarloSimulation(500).Stats.SequenceRisk
+		if breakdown == nil {
+			t.Fatal("expected sequence risk breakdown to be populated")
+		}
+		if breakdown.RecoveryShape != models.RecoveryL {
+			t.Errorf("recovery shape = %q, want %q", breakdown.RecoveryShape, models.RecoveryL)
+		}
+		if breakdown.BearMarketCount == 0 || breakdown.SingleCrashCount == 0 {
+			t.Errorf("bear market runs = %d, single crash runs = %d, want both", breakdown.BearMarketCount, breakdown.SingleCrashCount)
+		}
+		if crashRuns := 500 - breakdown.NoCrashCount; breakdown.BearMarketCount+breakdown.SingleCrashCount != crashRuns {
+			t.Errorf("bear (%d) and single (%d) runs should cover all %d crash runs", breakdown.BearMarketCount, breakdown.SingleCrashCount, crashRuns)
+		}
+	})
 }
 
 // TestMonteCarloWithIncomeAndExpenses tests simulation with income sources
@@ -740,6 +888,77 @@ func TestSteadyStateBudgetFit(t *testing.T) {
 	})
 }
 
+// TestIncomeSourceCOLA verifies custom and inflation-linked COLAs in both
+// the monthly amounts and the present value analysis
+func TestIncomeSourceCOLA(t *testing.T) {
+	custom := models.IncomeSource{Amount: 1000, COLARate: 0.03}
+	if got := custom.GetAdjustedAmount(24, 2.5); math.Abs(got-1000*1.03*1.03) > 0.001 {
+		t.Errorf("custom COLA after 2 years = %.2f, want %.2f", got, 1000*1.03*1.03)
+	}
+
+	linked := models.IncomeSource{Amount: 1000, COLARate: 0.03, InflationAdjusted: true}
+	if got := linked.GetAdjustedAmount(24, 2.5); math.Abs(got-1000*1.025*1.025) > 0.001 {
+		t.Errorf("inflation-linked COLA after 2 years = %.2f, want %.2f", got, 1000*1.025*1.025)
+	}
+	if got := linked.GetAdjustedAmount(11, 2.5); got != 1000 {
+		t.Errorf("COLA applied before the first anniversary: %.2f", got)
+	}
+
+	pvIncome := func(source models.IncomeSource) float64 {
+		settings := models.DefaultWhatIfSettings()
+		settings.InflationRate = 4
+		settings.IncomeSources = []models.IncomeSource{source}
+		return NewCalculator(settings).CalculatePresentValueAnalysis().PVIncome
+	}
+	flat := pvIncome(models.IncomeSource{Amount: 2000})
+	twoPct := pvIncome(models.IncomeSource{Amount: 2000, COLARate: 0.02})
+	inflation := pvIncome(models.IncomeSource{Amount: 2000, COLARate: 0.02, InflationAdjusted: true})
+	if !(flat < twoPct && twoPct < inflation) {
+		t.Errorf("PV income should grow with COLA: flat %.0f, 2%% %.0f, 4%% inflation %.0f", flat, twoPct, inflation)
+	}
+}
+
+// TestAgeTimedIncome verifies age-timed income sources are resolved against
+// CurrentAge without touching the caller's settings
+func TestAgeTimedIncome(t *testing.T) {
+	settings := models.DefaultWhatIfSettings()
+	settings.CurrentAge = 60
+	settings.IncomeSources = []models.IncomeSource{
+		{ID: "ss", Name: "Social Security", Amount: 2500, StartAge: 67},
+		{ID: "pension", Name: "Pension", Amount: 1000, StartAge: 55, EndAge: 70},
+	}
+
+	calc := NewCalculator(settings)
+	ss, pension := calc.Settings.IncomeSources[0], calc.Settings.IncomeSources[1]
+	if ss.StartMonth != 84 || ss.EndMonth != nil {
+		t.Errorf("Social Security at 67 from 60: StartMonth = %d, EndMonth = %v; want 84, nil", ss.StartMonth, ss.EndMonth)
+	}
+	if pension.StartMonth != 0 || pension.EndMonth == nil || *pension.EndMonth != 120 {
+		t.Errorf("pension from 55 to 70: StartMonth = %d, EndMonth = %v; want 0, 120", pension.StartMonth, pension.EndMonth)
+	}
+	if settings.IncomeSources[0].StartMonth != 0 {
+		t.Error("NewCalculator should not modify the caller's settings")
+	}
+	if got := calc.CalculateTotalIncome(83); got != 1000 {
+		t.Errorf("income the month before 67 = %.0f, want 1000", got)
+	}
+	if got := calc.CalculateTotalIncome(84); got != 3500 {
+		t.Errorf("income at 67 = %.0f, want 3500", got)
+	}
+
+	// Retiring later moves the start closer
+	settings.CurrentAge = 64
+	if got := NewCalculator(settings).Settings.IncomeSources[0].StartMonth; got != 36 {
+		t.Errorf("Social Security at 67 from 64: StartMonth = %d, want 36", got)
+	}
+
+	// Year-based timing clears the ages
+	models.IncomeTiming{StartYear: 2, EndYear: 5}.Apply(&settings.IncomeSources[1])
+	if p := settings.IncomeSources[1]; p.ByAge() || p.StartMonth != 24 || p.EndMonth == nil || *p.EndMonth != 60 {
+		t.Errorf("year timing = %+v, want months 24-60 and no ages", p)
+	}
+}
+
 // BenchmarkMonteCarloSimulation benchmarks the simulation performance
 func BenchmarkMonteCarloSimulation(b *testing.B) {
 	settings := models.DefaultWhatIfSettings()
@@ -759,3 +978,54 @@ func BenchmarkMonteCarloSimulation(b *testing.B) {
 		}
 	})
 }
+
+// TestEssentialFloor verifies living expenses under the floor keep pace
+// with inflation while the rest declines, and that the rest counts as
+// discretionary for adaptive spending
+func TestEssentialFloor(t *testing.T) {
+	settings := models.DefaultWhatIfSettings()
+	settings.MonthlyLivingExpenses = 5000
+	settings.InflationRate = 3
+	settings.SpendingDeclineRate = 2
+	settings.MonthlyHealthcare = 0
+	settings.HealthcarePersons = nil
+	settings.ExpenseSources = nil
+
+	noFloor := NewCalculator(settings)
+	if b := noFloor.CalculateExpenseBreakdown(0); b.Discretionary != 0 || b.Essential != 5000 {
+		t.Errorf("without a floor breakdown = %+v, want all essential", b)
+	}
+	floorless := noFloor.CalculateTotalExpenses(240)
+
+	settings.EssentialFloor = 3000
+	calc := NewCalculator(settings)
+	want := 3000*math.Pow(1.03, 20) + 2000*math.Pow(1.01, 20)
+	if got := calc.CalculateTotalExpenses(240); math.Abs(got-want) > 0.01 {
+		t.Errorf("expenses in year 20 = %.2f, want %.2f", got, want)
+	}
+	if got := calc.RunProjection().Months[239].GeneralExpenses; math.Abs(got-3000*math.Pow(1.03, 19)-2000*math.Pow(1.01, 19)) > 0.01 {
+		t.Errorf("projected living expenses in year 19 = %.2f", got)
+	}
+	if got := calc.CalculateTotalExpenses(240); got <= floorless {
+		t.Errorf("floor expenses %.2f should stay above %.2f without one", got, floorless)
+	}
+
+	b := calc.CalculateExpenseBreakdown(0)
+	if b.Essential != 3000 || b.Discretionary != 2000 {
+		t.Errorf("breakdown = %+v, want 3000 essential and 2000 discretionary", b)
+	}
+
+	// A floor above living expenses makes them all essential
+	settings.EssentialFloor = 8000
+	if b := NewCalculator(settings).CalculateExpenseBreakdown(0); b.Essential != 5000 || b.Discretionary != 0 {
+		t.Errorf("floor above expenses breakdown = %+v, want 5000 essential", b)
+	}
+
+	// The floor costs more in present value than declining spending
+	settings.EssentialFloor = 0
+	pvWithout := NewCalculator(settings).CalculatePresentValueAnalysis().PVExpenses
+	settings.EssentialFloor = 3000
+	if pv := NewCalculator(settings).CalculatePresentValueAnalysis().PVExpenses; pv <= pvWithout {
+		t.Errorf("PV expenses with floor %.0f, want above %.0f", pv, pvWithout)
+	}
+}
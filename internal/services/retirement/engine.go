@@ -0,0 +1,107 @@
+package retirement
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"budget2/internal/models"
+)
+
+// DefaultEngine is the name of the built-in Calculator engine
+const DefaultEngine = "standard"
+
+// RetirementEngine produces the core retirement analyses for a scenario.
+// Alternative engines (historical backtests, external actuarial services)
+// implement it and register a factory with RegisterEngine; the remaining
+// analyses (budget fit, present value, RMDs) always come from Calculator.
+type RetirementEngine interface {
+	// Project runs the deterministic month-by-month projection
+	Project() *models.ProjectionResult
+	// MonteCarlo runs the given number of randomized simulations
+	MonteCarlo(runs int) *models.MonteCarloAnalysis
+	// Sensitivity reports how results change as key assumptions move
+	Sensitivity() []models.SensitivityResult
+}
+
+// EngineFactory creates an engine for a scenario's settings
+type EngineFactory func(settings *models.WhatIfSettings) RetirementEngine
+
+var (
+	enginesMu sync.RWMutex
+	engines   = map[string]EngineFactory{
+		DefaultEngine: func(settings *models.WhatIfSettings) RetirementEngine {
+			return NewCalculator(settings)
+		},
+	}
+)
+
+// RegisterEngine makes an engine selectable by name in WhatIfSettings.Engine
+func RegisterEngine(name string, factory EngineFactory) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+
+	engines[name] = factory
+}
+
+// Engines returns the registered engine names in sorted order
+func Engines() []string {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// engineName returns the engine selected by the settings
+func engineName(settings *models.WhatIfSettings) string {
+	if settings.Engine == "" {
+		return DefaultEngine
+	}
+	return settings.Engine
+}
+
+// NewEngine returns the engine selected by the settings, defaulting to the
+// standard Calculator when none is set
+func NewEngine(settings *models.WhatIfSettings) (RetirementEngine, error) {
+	name := engineName(settings)
+
+	enginesMu.RLock()
+	factory, ok := engines[name]
+	enginesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown retirement engine %q", name)
+	}
+	return factory(settings), nil
+}
+
+// Analyze runs the full what-if analysis using the scenario's engine
+func Analyze(settings *models.WhatIfSettings) (*models.WhatIfAnalysis, error) {
+	engine, err := NewEngine(settings)
+	if err != nil {
+		return nil, err
+	}
+	return NewCalculator(settings).runFullAnalysis(engineName(settings), engine), nil
+}
+
+var _ RetirementEngine = (*Calculator)(nil)
+
+// Project implements RetirementEngine
+func (c *Calculator) Project() *models.ProjectionResult {
+	return c.RunProjection()
+}
+
+// MonteCarlo implements RetirementEngine
+func (c *Calculator) MonteCarlo(runs int) *models.MonteCarloAnalysis {
+	return c.RunMonteCarloSimulation(runs)
+}
+
+// Sensitivity implements RetirementEngine
+func (c *Calculator) Sensitivity() []models.SensitivityResult {
+	return c.CalculateSensitivity()
+}
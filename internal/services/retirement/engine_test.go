@@ -0,0 +1,67 @@
+package retirement
+
+import (
+	"testing"
+
+	"budget2/internal/models"
+)
+
+// fixedEngine returns canned results so tests can tell which engine ran
+type fixedEngine struct {
+	finalBalance float64
+}
+
+func (e fixedEngine) Project() *models.ProjectionResult {
+	return &models.ProjectionResult{FinalBalance: e.finalBalance}
+}
+
+func (e fixedEngine) MonteCarlo(runs int) *models.MonteCarloAnalysis {
+	return &models.MonteCarloAnalysis{Stats: &models.MonteCarloStats{Runs: runs}}
+}
+
+func (e fixedEngine) Sensitivity() []models.SensitivityResult {
+	return nil
+}
+
+func TestAnalyzeUsesSelectedEngine(t *testing.T) {
+	RegisterEngine("fixed-test", func(settings *models.WhatIfSettings) RetirementEngine {
+		return fixedEngine{finalBalance: 42}
+	})
+
+	settings := models.DefaultWhatIfSettings()
+	settings.PortfolioValue = 1000000
+	settings.Engine = "fixed-test"
+
+	analysis, err := Analyze(settings)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if analysis.Engine != "fixed-test" || analysis.Projection.FinalBalance != 42 {
+		t.Errorf("expected fixed-test engine results, got engine %q balance %.0f", analysis.Engine, analysis.Projection.FinalBalance)
+	}
+	if analysis.MonteCarlo.Stats.Runs != 1000 {
+		t.Errorf("MonteCarlo runs = %d, want 1000", analysis.MonteCarlo.Stats.Runs)
+	}
+	if analysis.BudgetFit == nil || analysis.RMD == nil {
+		t.Error("calculator analyses should still be included")
+	}
+}
+
+func TestAnalyzeDefaultsToCalculator(t *testing.T) {
+	settings := models.DefaultWhatIfSettings()
+	settings.PortfolioValue = 1000000
+
+	analysis, err := Analyze(settings)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	want := NewCalculator(settings).RunProjection().FinalBalance
+	if analysis.Engine != DefaultEngine || analysis.Projection.FinalBalance != want {
+		t.Errorf("default engine = %q balance %.0f, want %q balance %.0f", analysis.Engine, analysis.Projection.FinalBalance, DefaultEngine, want)
+	}
+
+	settings.Engine = "no-such-engine"
+	if _, err := Analyze(settings); err == nil {
+		t.Error("expected error for unregistered engine")
+	}
+}
@@ -0,0 +1,39 @@
+package retirement
+
+import (
+	"math"
+
+	"budget2/internal/models"
+)
+
+// realValue deflates a balance years from now to today's dollars at the
+// plan's inflation rate
+func (c *Calculator) realValue(nominal, years float64) float64 {
+	return nominal / math.Pow(1+c.Settings.InflationRate/100, years)
+}
+
+// CalculateEstate projects the estate left at the end of the plan on the
+// deterministic path and the median Monte Carlo path, and checks both
+// against the legacy target
+func (c *Calculator) CalculateEstate(projection *models.ProjectionResult, monteCarlo *models.MonteCarloAnalysis) *models.EstateProjection {
+	s := c.Settings
+	years := float64(s.ProjectionYears)
+
+	estate := &models.EstateProjection{
+		Age:                 s.CurrentAge + s.ProjectionYears,
+		Years:               s.ProjectionYears,
+		Deterministic:       projection.FinalBalance,
+		DeterministicReal:   c.realValue(projection.FinalBalance, years),
+		LegacyTarget:        s.LegacyTarget,
+		LegacyTargetNominal: s.LegacyTarget * math.Pow(1+s.InflationRate/100, years),
+	}
+	estate.MeetsTarget = estate.DeterministicReal >= s.LegacyTarget
+
+	if monteCarlo != nil && monteCarlo.Stats != nil {
+		estate.MonteCarloMedian = monteCarlo.Stats.MedianBalance
+		estate.MedianReal = monteCarlo.Stats.MedianEstateReal
+		estate.MedianMeetsTarget = estate.MedianReal >= s.LegacyTarget
+		estate.LegacySuccessRate = monteCarlo.Stats.LegacySuccessRate
+	}
+	return estate
+}
@@ -0,0 +1,77 @@
+package retirement
+
+import (
+	"math"
+	"testing"
+
+	"budget2/internal/models"
+)
+
+// TestCalculateEstate verifies estate values are deflated to today's dollars
+// and compared with the legacy target
+func TestCalculateEstate(t *testing.T) {
+	settings := models.DefaultWhatIfSettings()
+	settings.PortfolioValue = 2000000
+	settings.MonthlyLivingExpenses = 4000
+	settings.CurrentAge = 60
+	settings.ProjectionYears = 30
+	settings.LegacyTarget = 250000
+	calc := NewCalculator(settings)
+
+	projection := calc.RunProjection()
+	monteCarlo := calc.RunMonteCarloSimulation(200)
+	estate := calc.CalculateEstate(projection, monteCarlo)
+
+	if estate.Age != 90 || estate.Years != 30 {
+		t.Errorf("estate at age %d in %d years, want 90 in 30", estate.Age, estate.Years)
+	}
+
+	deflator := math.Pow(1.03, 30)
+	if got, want := estate.DeterministicReal, projection.FinalBalance/deflator; math.Abs(got-want) > 0.01 {
+		t.Errorf("DeterministicReal = %.2f, want %.2f", got, want)
+	}
+	if got, want := estate.LegacyTargetNominal, 250000*deflator; math.Abs(got-want) > 0.01 {
+		t.Errorf("LegacyTargetNominal = %.2f, want %.2f", got, want)
+	}
+	if estate.MeetsTarget != (estate.DeterministicReal >= 250000) {
+		t.Errorf("MeetsTarget = %v with %.0f real estate", estate.MeetsTarget, estate.DeterministicReal)
+	}
+	if estate.MonteCarloMedian != monteCarlo.Stats.MedianBalance {
+		t.Errorf("MonteCarloMedian = %.2f, want %.2f", estate.MonteCarloMedian, monteCarlo.Stats.MedianBalance)
+	}
+	if estate.LegacySuccessRate < 0 || estate.LegacySuccessRate > 100 {
+		t.Errorf("LegacySuccessRate %.1f out of bounds", estate.LegacySuccessRate)
+	}
+}
+
+// TestLegacyTargetLowersScore verifies an unmet legacy target lowers the
+// sustainability score and a met one leaves it alone
+func TestLegacyTargetLowersScore(t *testing.T) {
+	settings := models.DefaultWhatIfSettings()
+	settings.PortfolioValue = 1500000
+	settings.MonthlyLivingExpenses = 4000
+	settings.MonthlyHealthcare = 0
+	calc := NewCalculator(settings)
+
+	projection := calc.RunProjection()
+	base := calc.CalculateSustainabilityScore(projection)
+	if !projection.Survives {
+		t.Fatalf("test plan should survive, got %+v", base)
+	}
+
+	estateReal := calc.realValue(projection.FinalBalance, float64(settings.ProjectionYears))
+
+	settings.LegacyTarget = estateReal / 2
+	if met := calc.CalculateSustainabilityScore(projection); met.Score != base.Score {
+		t.Errorf("met target changed score from %d to %d", base.Score, met.Score)
+	}
+
+	settings.LegacyTarget = estateReal * 2
+	short := calc.CalculateSustainabilityScore(projection)
+	if want := base.Score - 15; short.Score != want {
+		t.Errorf("score with half the target funded = %d, want %d", short.Score, want)
+	}
+	if short.Description == base.Description {
+		t.Error("description should mention the legacy shortfall")
+	}
+}
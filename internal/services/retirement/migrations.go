@@ -0,0 +1,107 @@
+package retirement
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"budget2/internal/models"
+)
+
+// settingsMigration upgrades settings from version-1 to version. raw holds
+// the top-level keys of the file as persisted, so migrations can tell a
+// missing field apart from an explicit zero.
+type settingsMigration struct {
+	version     int
+	description string
+	apply       func(settings *models.WhatIfSettings, raw map[string]json.RawMessage) error
+}
+
+// settingsMigrations is the ordered upgrade pipeline. Append new entries;
+// never reorder or edit ones that have shipped.
+var settingsMigrations = []settingsMigration{
+	{
+		version:     1,
+		description: "convert legacy single healthcare value to healthcare persons",
+		apply:       migrateLegacyHealthcare,
+	},
+	{
+		version:     2,
+		description: "default rates that predate the settings file",
+		apply:       migrateMissingRates,
+	},
+	{
+		version:     3,
+		description: "default inflation and return correlation",
+		apply:       migrateInflationCorrelation,
+	},
+}
+
+// SettingsSchemaVersion is the schema version written with every save
+var SettingsSchemaVersion = settingsMigrations[len(settingsMigrations)-1].version
+
+// migrateSettings applies every migration newer than the file's version and
+// returns the versions that ran
+func migrateSettings(settings *models.WhatIfSettings, raw map[string]json.RawMessage) ([]int, error) {
+	var applied []int
+	for _, m := range settingsMigrations {
+		if m.version <= settings.SchemaVersion {
+			continue
+		}
+		if err := m.apply(settings, raw); err != nil {
+			return applied, fmt.Errorf("settings migration %d (%s): %w", m.version, m.description, err)
+		}
+		settings.SchemaVersion = m.version
+		applied = append(applied, m.version)
+	}
+	return applied, nil
+}
+
+// migrateLegacyHealthcare creates a single healthcare person from the legacy
+// monthly_healthcare value when no persons exist
+func migrateLegacyHealthcare(settings *models.WhatIfSettings, raw map[string]json.RawMessage) error {
+	if len(settings.HealthcarePersons) > 0 || settings.MonthlyHealthcare <= 0 {
+		return nil
+	}
+
+	coverage := models.CoverageMedicare
+	if settings.CurrentAge < 65 {
+		coverage = models.CoverageACA
+	}
+	settings.HealthcarePersons = []models.HealthcarePerson{
+		{
+			ID:                    "migrated-user",
+			Name:                  "User",
+			CurrentAge:            settings.CurrentAge,
+			CurrentCoverage:       coverage,
+			CurrentMonthlyCost:    settings.MonthlyHealthcare,
+			PreMedicareInflation:  settings.HealthcareInflation,
+			MedicareMonthlyCost:   settings.MonthlyHealthcare,
+			PostMedicareInflation: settings.HealthcareInflation,
+			MedicareEligibleAge:   65,
+		},
+	}
+	return nil
+}
+
+// migrateMissingRates fills in rates added after the first settings files
+// were written; without this they load as 0% and skew every projection
+func migrateMissingRates(settings *models.WhatIfSettings, raw map[string]json.RawMessage) error {
+	defaults := models.DefaultWhatIfSettings()
+
+	if _, ok := raw["spending_decline_rate"]; !ok {
+		settings.SpendingDeclineRate = defaults.SpendingDeclineRate
+	}
+	if _, ok := raw["discount_rate"]; !ok {
+		settings.DiscountRate = defaults.DiscountRate
+	}
+	return nil
+}
+
+// migrateInflationCorrelation turns on the default correlation between
+// inflation and returns for files written before it existed
+func migrateInflationCorrelation(settings *models.WhatIfSettings, raw map[string]json.RawMessage) error {
+	if _, ok := raw["inflation_correlation"]; !ok {
+		settings.InflationCorrelation = models.DefaultWhatIfSettings().InflationCorrelation
+	}
+	return nil
+}
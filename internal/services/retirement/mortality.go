@@ -0,0 +1,115 @@
+package retirement
+
+import (
+	"math"
+	"math/rand"
+
+	"budget2/internal/models"
+)
+
+// MaxAge is the oldest age a simulated lifetime can reach
+const MaxAge = 120
+
+// mortalityAges are the anchor ages of the mortality tables below
+var mortalityAges = []int{50, 55, 60, 65, 70, 75, 80, 85, 90, 95, 100, 105, 110, 115}
+
+// Annual probability of dying within the year at each anchor age,
+// approximating the SSA 2019 period life table (the last before the pandemic
+// skewed rates). Rates between anchors are interpolated geometrically.
+var (
+	maleMortality = []float64{
+		0.0049, 0.0076, 0.0110, 0.0156, 0.0231, 0.0357, 0.0565,
+		0.0935, 0.156, 0.246, 0.352, 0.469, 0.585, 0.700,
+	}
+	femaleMortality = []float64{
+		0.0031, 0.0045, 0.0065, 0.0097, 0.0152, 0.0243, 0.0398,
+		0.0673, 0.118, 0.200, 0.306, 0.427, 0.553, 0.680,
+	}
+)
+
+// healthMultipliers scale mortality rates for self-reported health
+var healthMultipliers = map[string]float64{
+	models.HealthExcellent: 0.6,
+	models.HealthGood:      0.8,
+	models.HealthAverage:   1.0,
+	models.HealthPoor:      1.6,
+}
+
+// HealthMultiplier returns how much a health rating scales mortality rates,
+// treating unknown ratings as average
+func HealthMultiplier(health string) float64 {
+	if m, ok := healthMultipliers[health]; ok {
+		return m
+	}
+	return 1.0
+}
+
+// mortalityRate returns the table's death probability at age for one sex
+func mortalityRate(table []float64, age int) float64 {
+	if age >= MaxAge {
+		return 1
+	}
+
+	// Below the table, extend the first segment's growth backwards; past it,
+	// extend the last segment's growth forwards
+	i := 0
+	for i < len(mortalityAges)-2 && age >= mortalityAges[i+1] {
+		i++
+	}
+	span := float64(mortalityAges[i+1] - mortalityAges[i])
+	frac := float64(age-mortalityAges[i]) / span
+	return table[i] * math.Pow(table[i+1]/table[i], frac)
+}
+
+// DeathProbability returns the chance of dying within the year at age,
+// averaging the sexes when sex is unset and scaling by multiplier
+func DeathProbability(age int, sex string, multiplier float64) float64 {
+	var q float64
+	switch sex {
+	case models.SexMale:
+		q = mortalityRate(maleMortality, age)
+	case models.SexFemale:
+		q = mortalityRate(femaleMortality, age)
+	default:
+		q = (mortalityRate(maleMortality, age) + mortalityRate(femaleMortality, age)) / 2
+	}
+	return math.Min(1, q*multiplier)
+}
+
+// SampleLifespan draws the number of years lived from age, counting the year
+// of death, so the result is at least 1
+func SampleLifespan(rng *rand.Rand, age int, sex string, multiplier float64) int {
+	years := 1
+	for a := age; a < MaxAge; a++ {
+		if rng.Float64() < DeathProbability(a, sex, multiplier) {
+			return years
+		}
+		years++
+	}
+	return max(1, MaxAge-age)
+}
+
+// LifeExpectancy returns the expected remaining years of life at age,
+// assuming deaths fall mid-year
+func LifeExpectancy(age int, sex string, multiplier float64) float64 {
+	expected := 0.0
+	alive := 1.0
+	for a := age; a < MaxAge && alive > 0; a++ {
+		q := DeathProbability(a, sex, multiplier)
+		expected += alive * (1 - q/2)
+		alive *= 1 - q
+	}
+	return expected
+}
+
+// SurvivalProbability returns the chance of living at least years more from age
+func SurvivalProbability(age, years int, sex string, multiplier float64) float64 {
+	alive := 1.0
+	for a := age; a < age+years && a < MaxAge; a++ {
+		alive *= 1 - DeathProbability(a, sex, multiplier)
+	}
+	if age+years >= MaxAge {
+		return 0
+	}
+	return alive
+}
@@ -0,0 +1,110 @@
+package retirement
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"budget2/internal/models"
+)
+
+// TestLifeExpectancy verifies the table reproduces SSA period life
+// expectancies at 65 and that health shifts them the right way
+func TestLifeExpectancy(t *testing.T) {
+	tests := []struct {
+		sex  string
+		want float64
+	}{
+		{models.SexMale, 18.1},
+		{models.SexFemale, 20.7},
+	}
+	for _, tt := range tests {
+		if got := LifeExpectancy(65, tt.sex, 1); math.Abs(got-tt.want) > 0.7 {
+			t.Errorf("LifeExpectancy(65, %s) = %.1f, want about %.1f", tt.sex, got, tt.want)
+		}
+	}
+
+	male, female, blend := LifeExpectancy(65, models.SexMale, 1), LifeExpectancy(65, models.SexFemale, 1), LifeExpectancy(65, "", 1)
+	if blend <= male || blend >= female {
+		t.Errorf("blended expectancy %.1f should fall between male %.1f and female %.1f", blend, male, female)
+	}
+
+	excellent := LifeExpectancy(65, "", HealthMultiplier(models.HealthExcellent))
+	poor := LifeExpectancy(65, "", HealthMultiplier(models.HealthPoor))
+	if excellent <= blend || poor >= blend {
+		t.Errorf("expectancy excellent %.1f, average %.1f, poor %.1f; want descending", excellent, blend, poor)
+	}
+	if HealthMultiplier("") != 1 {
+		t.Errorf("unset health multiplier = %.1f, want 1", HealthMultiplier(""))
+	}
+}
+
+// TestDeathProbability verifies rates interpolate between anchors and end
+// at MaxAge
+func TestDeathProbability(t *testing.T) {
+	if got := DeathProbability(65, models.SexMale, 1); got != 0.0156 {
+		t.Errorf("male rate at 65 = %.4f, want the table's 0.0156", got)
+	}
+	mid := DeathProbability(67, models.SexMale, 1)
+	if mid <= 0.0156 || mid >= 0.0231 {
+		t.Errorf("male rate at 67 = %.4f, want between the 65 and 70 anchors", mid)
+	}
+	if got := DeathProbability(45, models.SexFemale, 1); got <= 0 || got >= 0.0031 {
+		t.Errorf("female rate at 45 = %.4f, want below the age 50 anchor", got)
+	}
+	if got := DeathProbability(MaxAge, "", 1); got != 1 {
+		t.Errorf("rate at MaxAge = %.2f, want 1", got)
+	}
+	if got := DeathProbability(118, "", 5); got != 1 {
+		t.Errorf("scaled rate = %.2f, want capped at 1", got)
+	}
+}
+
+// TestSampleLifespan verifies sampled lifetimes match the table
+func TestSampleLifespan(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	const samples = 20000
+
+	total, pastNinety := 0, 0
+	for i := 0; i < samples; i++ {
+		years := SampleLifespan(rng, 65, "", 1)
+		if years < 1 || 65+years > MaxAge {
+			t.Fatalf("lifespan %d years from 65 out of range", years)
+		}
+		total += years
+		if years > 25 {
+			pastNinety++
+		}
+	}
+
+	// Deaths are counted at the end of the year, half a year after the
+	// mid-year convention LifeExpectancy uses
+	want := LifeExpectancy(65, "", 1) + 0.5
+	if got := float64(total) / samples; math.Abs(got-want) > 0.3 {
+		t.Errorf("mean lifespan = %.1f years, want about %.1f", got, want)
+	}
+	want = SurvivalProbability(65, 25, "", 1)
+	if got := float64(pastNinety) / samples; math.Abs(got-want) > 0.02 {
+		t.Errorf("share alive past 90 = %.3f, want about %.3f", got, want)
+	}
+}
+
+// TestMonteCarloMortalityStats verifies runs last a sampled lifetime and the
+// longevity stats are reported
+func TestMonteCarloMortalityStats(t *testing.T) {
+	settings := models.DefaultWhatIfSettings()
+	settings.PortfolioValue = 1500000
+	settings.Sex = models.SexFemale
+	stats := NewCalculator(settings).RunMonteCarloSimulation(1000).Stats
+
+	if !stats.MortalityHorizon {
+		t.Fatal("expected mortality table horizons")
+	}
+	if stats.MedianDeathAge < 84 || stats.MedianDeathAge > 90 {
+		t.Errorf("median death age = %d, want about 87 for a 65-year-old woman", stats.MedianDeathAge)
+	}
+	want := SurvivalProbability(65, settings.ProjectionYears, models.SexFemale, 1) * 100
+	if math.Abs(stats.OutlivePlanRate-want) > 4 {
+		t.Errorf("outlive plan rate = %.1f%%, want about %.1f%%", stats.OutlivePlanRate, want)
+	}
+}
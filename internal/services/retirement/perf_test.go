@@ -0,0 +1,28 @@
+package retirement
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/testutil"
+)
+
+// BenchmarkRunFullAnalysis measures the what-if page's analysis, including
+// 1000 Monte Carlo runs
+func BenchmarkRunFullAnalysis(b *testing.B) {
+	settings := models.DefaultWhatIfSettings()
+	settings.PortfolioValue = 1000000
+	settings.ProjectionYears = 30
+	settings.IncomeSources = []models.IncomeSource{
+		{Name: "Social Security", Amount: 2000, StartMonth: 24},
+	}
+
+	for i := 0; i < b.N; i++ {
+		NewCalculator(settings).RunFullAnalysis()
+	}
+}
+
+func TestRunFullAnalysisBudget(t *testing.T) {
+	testutil.CheckBudget(t, 250*time.Millisecond, BenchmarkRunFullAnalysis)
+}
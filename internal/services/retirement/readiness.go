@@ -0,0 +1,275 @@
+package retirement
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// readinessFile holds the quarterly readiness snapshots
+var readinessFile = storage.SettingsFile("readiness.json", func() interface{} { return &[]models.ReadinessSnapshot{} })
+
+// Readiness report tuning
+const (
+	ReadinessRuns     = 1000 // Monte Carlo runs behind the baseline and each risk
+	TargetSuccessRate = 85.0 // Success rate the recommended actions aim for
+	MaxReadinessRisks = 3    // Risks listed, biggest first
+)
+
+// readinessRisks are the adverse shifts in an assumption the readiness
+// report tests, each on a setting a sweep can vary
+var readinessRisks = []struct {
+	name, param, change string
+	shift               func(v float64) float64
+}{
+	{"Lower returns", "investment_return", "-2 points", func(v float64) float64 { return v - 2 }},
+	{"Higher inflation", "inflation_rate", "+1 point", func(v float64) float64 { return v + 1 }},
+	{"Higher spending", "monthly_living_expenses", "+10%", func(v float64) float64 { return v * 1.1 }},
+	{"Higher healthcare costs", "monthly_healthcare", "+50%", func(v float64) float64 { return v * 1.5 }},
+	{"Spending doesn't decline", "spending_decline_rate", "to 0%", func(v float64) float64 { return 0 }},
+}
+
+// riskAdvice is the recommended action when an assumption is the plan's
+// biggest risk
+var riskAdvice = map[string]string{
+	"investment_return":       "Returns matter most to this plan: hold a few years of spending in a cash or bond bucket so a bad market doesn't force selling stocks.",
+	"inflation_rate":          "Inflation matters most to this plan: favor income that rises with prices, such as TIPS, a COLA pension or delaying Social Security.",
+	"monthly_living_expenses": "Spending matters most to this plan: set an essential floor and keep the rest of your budget flexible for down years.",
+	"monthly_healthcare":      "Healthcare costs matter most to this plan: price Medigap or long-term care coverage before relying on the current estimate.",
+	"spending_decline_rate":   "The plan counts on spending falling with age: test it with no decline before relying on that.",
+}
+
+// Quarter names the calendar quarter t falls in, e.g. "2025-Q3"
+func Quarter(t time.Time) string {
+	return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+}
+
+// Readiness builds the quarterly retirement readiness report as of now:
+// the score and success rate, the change since previous (the latest report
+// from an earlier quarter, or nil), the assumptions whose adverse shift
+// costs the most success, and recommended actions. Every simulation shares
+// a seed, so differences come from the assumptions.
+func (c *Calculator) Readiness(now time.Time, previous *models.ReadinessSnapshot, runs int) *models.ReadinessReport {
+	seed := now.UnixNano()
+	base := c.runMonteCarlo(runs, c.monteCarloConfig(), seed)
+	score := c.CalculateSustainabilityScore(c.RunProjection())
+
+	report := &models.ReadinessReport{
+		ReadinessSnapshot: models.ReadinessSnapshot{
+			Quarter:       Quarter(now),
+			TakenAt:       now,
+			Score:         score.Score,
+			SuccessRate:   base.Stats.SuccessRate,
+			MedianBalance: base.Stats.MedianBalance,
+		},
+		Label:     score.Label,
+		Previous:  previous,
+		Risks:     []models.ReadinessRisk{},
+		Warnings:  SanityWarnings(c.Settings),
+		Generated: now,
+	}
+	if previous != nil {
+		change := report.SuccessRate - previous.SuccessRate
+		report.Change = &change
+	}
+
+	for _, r := range readinessRisks {
+		p := sweepParams[r.param]
+		current := p.get(c.Settings)
+		shifted := r.shift(current)
+		if shifted == current {
+			continue
+		}
+		calc := c.withSetting(p, shifted)
+		rate := calc.runMonteCarlo(runs, calc.monteCarloConfig(), seed).Stats.SuccessRate
+		report.Risks = append(report.Risks, models.ReadinessRisk{
+			Name:        r.name,
+			Param:       r.param,
+			Change:      r.change,
+			SuccessRate: rate,
+			Impact:      rate - report.SuccessRate,
+		})
+	}
+	sort.SliceStable(report.Risks, func(i, j int) bool {
+		return report.Risks[i].Impact < report.Risks[j].Impact
+	})
+	if len(report.Risks) > MaxReadinessRisks {
+		report.Risks = report.Risks[:MaxReadinessRisks]
+	}
+
+	report.Actions = c.readinessActions(report, base.Stats, runs, seed)
+	return report
+}
+
+// readinessActions recommends next steps for a readiness report, most
+// important first
+func (c *Calculator) readinessActions(r *models.ReadinessReport, stats *models.MonteCarloStats, runs int, seed int64) []string {
+	var actions []string
+	s := c.Settings
+
+	// Find the smallest spending cut, in 5% steps, that reaches the target
+	if r.SuccessRate < TargetSuccessRate && s.MonthlyLivingExpenses > 0 {
+		p := sweepParams["monthly_living_expenses"]
+		var rate float64
+		cut := 0
+		for cut < 30 && rate < TargetSuccessRate {
+			cut += 5
+			calc := c.withSetting(p, s.MonthlyLivingExpenses*float64(100-cut)/100)
+			rate = calc.runMonteCarlo(runs, calc.monteCarloConfig(), seed).Stats.SuccessRate
+		}
+		if rate >= TargetSuccessRate {
+			actions = append(actions, fmt.Sprintf("Cut living expenses %d%%, to %s a month, to bring the success rate to %s.",
+				cut, money(s.MonthlyLivingExpenses*float64(100-cut)/100), percent(rate)))
+		} else {
+			actions = append(actions, fmt.Sprintf("Even a 30%% cut in living expenses only reaches %s success; consider working longer, delaying Social Security or adding income.",
+				percent(rate)))
+		}
+	}
+
+	if r.Change != nil && *r.Change <= -5 {
+		actions = append(actions, fmt.Sprintf("The success rate fell %.1f points since %s; the assumptions report shows which inputs changed.",
+			-*r.Change, r.Previous.Quarter))
+	}
+
+	if len(r.Risks) > 0 && r.Risks[0].Impact <= -10 {
+		actions = append(actions, riskAdvice[r.Risks[0].Param])
+	}
+
+	if len(r.Warnings) > 0 {
+		actions = append(actions, fmt.Sprintf("Revisit the %d flagged %s below; results built on them may be optimistic.",
+			len(r.Warnings), plural(len(r.Warnings), "input", "inputs")))
+	}
+
+	if s.LegacyTarget > 0 && stats.LegacySuccessRate < 50 {
+		actions = append(actions, fmt.Sprintf("Only %s of simulations leave your %s legacy target; spend less or lower the target.",
+			percent(stats.LegacySuccessRate), money(s.LegacyTarget)))
+	}
+
+	if len(actions) == 0 {
+		if r.SuccessRate >= 95 {
+			actions = append(actions, "The plan has room to spare; you could afford more spending or an earlier retirement.")
+		} else {
+			actions = append(actions, "Stay the course and check again next quarter.")
+		}
+	}
+	return actions
+}
+
+// plural picks the singular or plural form for n
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// WriteReadinessText writes the readiness report as plain text, for the
+// body of an email
+func WriteReadinessText(w io.Writer, r *models.ReadinessReport) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Retirement readiness, %s\n\n", r.Quarter)
+	fmt.Fprintf(&b, "Score: %d (%s)\n", r.Score, r.Label)
+	fmt.Fprintf(&b, "Success rate: %s", percent(r.SuccessRate))
+	if r.Change != nil {
+		fmt.Fprintf(&b, " (%+.1f points since %s)", *r.Change, r.Previous.Quarter)
+	}
+	fmt.Fprintf(&b, "\nMedian final balance: %s\n", money(r.MedianBalance))
+
+	if len(r.Risks) > 0 {
+		b.WriteString("\nBiggest assumption risks\n")
+		for _, risk := range r.Risks {
+			fmt.Fprintf(&b, "- %s (%s): %s success, %+.1f points\n", risk.Name, risk.Change, percent(risk.SuccessRate), risk.Impact)
+		}
+	}
+
+	b.WriteString("\nRecommended actions\n")
+	for _, a := range r.Actions {
+		fmt.Fprintf(&b, "- %s\n", a)
+	}
+
+	if len(r.Warnings) > 0 {
+		b.WriteString("\nFlagged inputs\n")
+		for _, warning := range r.Warnings {
+			fmt.Fprintf(&b, "- %s\n", warning.Message)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ReadinessHistory keeps one readiness snapshot per quarter, so each
+// report can show the change since the last
+type ReadinessHistory struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewReadinessHistory creates a history stored in settingsDir
+func NewReadinessHistory(settingsDir string, store *storage.Storage) *ReadinessHistory {
+	return &ReadinessHistory{
+		path:  filepath.Join(settingsDir, readinessFile),
+		store: store,
+	}
+}
+
+// Previous returns the snapshot of the latest quarter before quarter, or
+// nil if there is none
+func (h *ReadinessHistory) Previous(quarter string) (*models.ReadinessSnapshot, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	snapshots, err := h.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if snapshots[i].Quarter < quarter {
+			return &snapshots[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Record saves snap as its quarter's snapshot, replacing an earlier one
+// from the same quarter so the latest report of each quarter is kept
+func (h *ReadinessHistory) Record(snap models.ReadinessSnapshot) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	snapshots, err := h.loadInternal()
+	if err != nil {
+		return err
+	}
+	kept := snapshots[:0]
+	for _, s := range snapshots {
+		if s.Quarter != snap.Quarter {
+			kept = append(kept, s)
+		}
+	}
+	kept = append(kept, snap)
+	sort.Slice(kept, func(i, j int) bool { return kept[i].Quarter < kept[j].Quarter })
+	return h.store.WriteJSON(h.path, kept)
+}
+
+// loadInternal reads the snapshots, oldest quarter first, without
+// acquiring lock (caller must hold lock)
+func (h *ReadinessHistory) loadInternal() ([]models.ReadinessSnapshot, error) {
+	var snapshots []models.ReadinessSnapshot
+	if err := h.store.ReadJSON(h.path, &snapshots); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return snapshots, nil
+}
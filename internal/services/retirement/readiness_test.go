@@ -0,0 +1,88 @@
+package retirement
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func TestQuarter(t *testing.T) {
+	for date, want := range map[string]string{"2025-01-01": "2025-Q1", "2025-06-30": "2025-Q2", "2025-07-01": "2025-Q3", "2025-12-31": "2025-Q4"} {
+		d, _ := time.Parse("2006-01-02", date)
+		if got := Quarter(d); got != want {
+			t.Errorf("Quarter(%s) = %s, want %s", date, got, want)
+		}
+	}
+}
+
+// TestReadiness verifies a strained plan lists its risks biggest first,
+// compares with the previous quarter and recommends a spending cut
+func TestReadiness(t *testing.T) {
+	settings := models.DefaultWhatIfSettings()
+	settings.PortfolioValue = 800000
+	settings.MonthlyLivingExpenses = 5000
+	settings.MonthlyHealthcare = 0
+	now := time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)
+	previous := &models.ReadinessSnapshot{Quarter: "2025-Q2", SuccessRate: 99}
+
+	report := NewCalculator(settings).Readiness(now, previous, 300)
+	if report.Quarter != "2025-Q3" || report.Label == "" {
+		t.Errorf("report = %s %q, want 2025-Q3 with a label", report.Quarter, report.Label)
+	}
+	if report.Change == nil || *report.Change != report.SuccessRate-99 {
+		t.Errorf("change = %v, want success rate less 99", report.Change)
+	}
+	if len(report.Risks) == 0 || len(report.Risks) > MaxReadinessRisks {
+		t.Fatalf("risks = %+v, want 1 to %d", report.Risks, MaxReadinessRisks)
+	}
+	for i, risk := range report.Risks {
+		if risk.Param == "monthly_healthcare" {
+			t.Error("healthcare of 0 can't be a risk")
+		}
+		if i > 0 && risk.Impact < report.Risks[i-1].Impact {
+			t.Errorf("risks out of order: %+v", report.Risks)
+		}
+	}
+
+	if report.SuccessRate >= TargetSuccessRate {
+		t.Fatalf("success rate %.1f%%, want a strained plan", report.SuccessRate)
+	}
+	actions := strings.Join(report.Actions, "\n")
+	if !strings.Contains(actions, "living expenses") || !strings.Contains(actions, "since 2025-Q2") {
+		t.Errorf("actions = %q, want a spending cut and the drop since 2025-Q2", report.Actions)
+	}
+
+	var b strings.Builder
+	if err := WriteReadinessText(&b, report); err != nil {
+		t.Fatalf("WriteReadinessText: %v", err)
+	}
+	if text := b.String(); !strings.Contains(text, "Retirement readiness, 2025-Q3") || !strings.Contains(text, "since 2025-Q2") {
+		t.Errorf("text = %q", text)
+	}
+}
+
+// TestReadinessHistory verifies one snapshot is kept per quarter and the
+// previous quarter's is found
+func TestReadinessHistory(t *testing.T) {
+	store, _ := storage.NewMemory("/data")
+	history := NewReadinessHistory("/data/settings", store)
+
+	if prev, err := history.Previous("2025-Q3"); prev != nil || err != nil {
+		t.Fatalf("Previous with no history = %+v, %v; want nil", prev, err)
+	}
+	history.Record(models.ReadinessSnapshot{Quarter: "2025-Q1", SuccessRate: 80})
+	history.Record(models.ReadinessSnapshot{Quarter: "2025-Q2", SuccessRate: 85})
+	history.Record(models.ReadinessSnapshot{Quarter: "2025-Q2", SuccessRate: 90})
+	history.Record(models.ReadinessSnapshot{Quarter: "2025-Q3", SuccessRate: 70})
+
+	prev, _ := history.Previous("2025-Q3")
+	if prev == nil || prev.Quarter != "2025-Q2" || prev.SuccessRate != 90 {
+		t.Errorf("Previous(2025-Q3) = %+v, want the latest 2025-Q2 snapshot", prev)
+	}
+	if prev, _ := history.Previous("2025-Q1"); prev != nil {
+		t.Errorf("Previous(2025-Q1) = %+v, want nil", prev)
+	}
+}
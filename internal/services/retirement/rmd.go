@@ -1,10 +1,35 @@
 package retirement
 
-import "budget2/internal/models"
+import (
+	"math"
+
+	"budget2/internal/models"
+)
 
 // RMD start age per IRS rules (SECURE 2.0 Act)
 const RMDStartAge = 73
 
+// QCDLimit is the most that can be given as qualified charitable
+// distributions in a year (2025, indexed for inflation)
+const QCDLimit = 108000
+
+// SocialSecurityTaxablePercent is the most of Social Security benefits
+// counted as taxable income
+const SocialSecurityTaxablePercent = 85
+
+// irmaaTiers are the 2025 single-filer MAGI thresholds for Medicare IRMAA and
+// the combined monthly Part B and Part D surcharge above each
+var irmaaTiers = []struct {
+	MAGI             float64
+	MonthlySurcharge float64
+}{
+	{106000, 87.70},
+	{133000, 220.30},
+	{167000, 352.90},
+	{200000, 485.50},
+	{500000, 529.70},
+}
+
 // uniformLifetimeTable contains IRS Uniform Lifetime Table factors
 // Used when the sole beneficiary is not a spouse more than 10 years younger
 // Source: IRS Publication 590-B, Table III
@@ -83,7 +108,37 @@ func CalculateRMD(taxDeferredBalance float64, age int) (amount float64, percent
 	return amount, percent
 }
 
-// CalculateRMDAnalysis generates RMD projections based on current settings
+// IRMAASurcharge returns the IRMAA tier for a MAGI and the annual Medicare
+// premium surcharge it brings (tier 0 has none)
+func IRMAASurcharge(magi float64) (tier int, annual float64) {
+	for i, t := range irmaaTiers {
+		if magi > t.MAGI {
+			tier, annual = i+1, t.MonthlySurcharge*12
+		}
+	}
+	return tier, annual
+}
+
+// taxableIncomeSources estimates the year's taxable income from income
+// sources, counting SocialSecurityTaxablePercent of Social Security
+func (c *Calculator) taxableIncomeSources(year int) float64 {
+	total := 0.0
+	for _, source := range c.Settings.IncomeSources {
+		annual := source.GetAdjustedAmount(year*12, c.Settings.InflationRate) * 12
+		if source.IsSocialSecurity() {
+			annual *= SocialSecurityTaxablePercent / 100.0
+		}
+		total += annual
+	}
+	return total
+}
+
+// CalculateRMDAnalysis generates RMD projections based on current settings.
+// The configured QCD is given from each year's RMD, up to QCDLimit, which
+// keeps it out of taxable income and can lower the IRMAA tier. QCDs are
+// assumed to replace giving that would happen anyway, so balances are
+// unchanged. Each year's federal tax on the RMD is estimated with brackets
+// grown by inflation, for withholding or quarterly payments.
 func (c *Calculator) CalculateRMDAnalysis() *models.RMDAnalysis {
 	s := c.Settings
 
@@ -99,6 +154,8 @@ func (c *Calculator) CalculateRMDAnalysis() *models.RMDAnalysis {
 	// Generate projections for the projection period
 	projections := make([]models.RMDProjection, 0)
 	totalRMDs10Yr := 0.0
+	totalQCDs10Yr := 0.0
+	irmaaSaved10Yr := 0.0
 
 	// Estimate future tax-deferred balance using investment return
 	// This is a simplified projection - actual will depend on withdrawals
@@ -114,17 +171,35 @@ func (c *Calculator) CalculateRMDAnalysis() *models.RMDAnalysis {
 			factor := GetLifeExpectancyFactor(age)
 			rmdAmount, rmdPercent := CalculateRMD(currentBalance, age)
 
-			projections = append(projections, models.RMDProjection{
+			qcd := 0.0
+			if s.AnnualQCD > 0 {
+				qcd = min(s.AnnualQCD, rmdAmount, QCDLimit)
+			}
+			otherIncome := c.taxableIncomeSources(year)
+			tier, surcharge := IRMAASurcharge(rmdAmount - qcd + otherIncome)
+			_, surchargeWithoutQCD := IRMAASurcharge(rmdAmount + otherIncome)
+
+			projection := models.RMDProjection{
 				Age:            age,
 				Year:           year,
 				TaxDeferredBal: currentBalance,
 				LifeExpFactor:  factor,
 				RMDAmount:      rmdAmount,
 				RMDPercent:     rmdPercent,
-			})
+				QCD:            qcd,
+				TaxableRMD:     rmdAmount - qcd,
+				TaxableIncome:  rmdAmount - qcd + otherIncome,
+				IRMAATier:      tier,
+				IRMAASurcharge: surcharge,
+				IRMAASaved:     surchargeWithoutQCD - surcharge,
+			}
+			rmdWithholding(&projection, math.Pow(1+s.InflationRate/100, float64(year)))
+			projections = append(projections, projection)
 
 			if rmdCount < 10 {
 				totalRMDs10Yr += rmdAmount
+				totalQCDs10Yr += qcd
+				irmaaSaved10Yr += surchargeWithoutQCD - surcharge
 			}
 			rmdCount++
 
@@ -148,5 +223,8 @@ func (c *Calculator) CalculateRMDAnalysis() *models.RMDAnalysis {
 		TaxDeferredValue:  taxDeferredValue,
 		Projections:       projections,
 		TotalRMDsOver10Yr: totalRMDs10Yr,
+		AnnualQCD:         s.AnnualQCD,
+		TotalQCDsOver10Yr: totalQCDs10Yr,
+		IRMAASaved10Yr:    irmaaSaved10Yr,
 	}
 }
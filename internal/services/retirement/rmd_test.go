@@ -0,0 +1,81 @@
+package retirement
+
+import (
+	"math"
+	"testing"
+
+	"budget2/internal/models"
+)
+
+// TestIRMAASurcharge verifies MAGI maps to the right IRMAA tier
+func TestIRMAASurcharge(t *testing.T) {
+	tests := []struct {
+		magi   float64
+		tier   int
+		annual float64
+	}{
+		{50000, 0, 0},
+		{106000, 0, 0},
+		{106001, 1, 87.70 * 12},
+		{150000, 2, 220.30 * 12},
+		{600000, 5, 529.70 * 12},
+	}
+
+	for _, tt := range tests {
+		tier, annual := IRMAASurcharge(tt.magi)
+		if tier != tt.tier || math.Abs(annual-tt.annual) > 0.001 {
+			t.Errorf("IRMAASurcharge(%.0f) = tier %d, %.2f; want tier %d, %.2f", tt.magi, tier, annual, tt.tier, tt.annual)
+		}
+	}
+}
+
+// TestQCDReducesTaxableIncome verifies the QCD comes out of each RMD before
+// taxable income and IRMAA are estimated
+func TestQCDReducesTaxableIncome(t *testing.T) {
+	settings := models.DefaultWhatIfSettings()
+	settings.CurrentAge = 75
+	settings.PortfolioValue = 3000000
+	settings.TaxDeferredPercent = 100
+	settings.IncomeSources = []models.IncomeSource{
+		{ID: "ss", Name: "Social Security", Amount: 4000, Type: models.IncomeFixed},
+	}
+
+	without := NewCalculator(settings).CalculateRMDAnalysis()
+	first := without.Projections[0]
+	// 3M / 24.6 = 121,951 RMD plus 85% of 48,000 Social Security
+	if want := first.RMDAmount + 48000*0.85; math.Abs(first.TaxableIncome-want) > 0.01 {
+		t.Fatalf("TaxableIncome = %.2f, want %.2f", first.TaxableIncome, want)
+	}
+	if first.QCD != 0 || first.IRMAATier != 2 {
+		t.Errorf("without QCD: QCD = %.2f, tier = %d; want 0 and tier 2", first.QCD, first.IRMAATier)
+	}
+
+	settings.AnnualQCD = 30000
+	with := NewCalculator(settings).CalculateRMDAnalysis()
+	first = with.Projections[0]
+	if first.QCD != 30000 || first.TaxableRMD != first.RMDAmount-30000 {
+		t.Errorf("QCD = %.2f, TaxableRMD = %.2f; want 30000 out of %.2f", first.QCD, first.TaxableRMD, first.RMDAmount)
+	}
+	if first.IRMAATier != 1 || first.IRMAASaved <= 0 {
+		t.Errorf("with QCD: tier = %d, saved = %.2f; want tier 1 and savings", first.IRMAATier, first.IRMAASaved)
+	}
+	if with.TotalQCDsOver10Yr != 300000 || with.IRMAASaved10Yr <= 0 {
+		t.Errorf("10-yr QCDs = %.2f, IRMAA saved = %.2f", with.TotalQCDsOver10Yr, with.IRMAASaved10Yr)
+	}
+	if with.TotalRMDsOver10Yr != without.TotalRMDsOver10Yr {
+		t.Error("QCDs should not change the RMDs themselves")
+	}
+
+	// The QCD is capped at the annual limit and at the RMD itself
+	settings.AnnualQCD = 1000000
+	capped := NewCalculator(settings).CalculateRMDAnalysis().Projections[0]
+	if capped.QCD != QCDLimit {
+		t.Errorf("QCD = %.2f, want capped at limit %d", capped.QCD, QCDLimit)
+	}
+
+	settings.PortfolioValue = 500000
+	capped = NewCalculator(settings).CalculateRMDAnalysis().Projections[0]
+	if capped.QCD != capped.RMDAmount || capped.TaxableRMD != 0 {
+		t.Errorf("QCD = %.2f, want capped at RMD %.2f", capped.QCD, capped.RMDAmount)
+	}
+}
@@ -0,0 +1,129 @@
+package retirement
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// SandboxTTL is how long a what-if sandbox lasts without being used
+const SandboxTTL = 12 * time.Hour
+
+// ErrNoSandbox is returned for a sandbox that expired or never existed
+var ErrNoSandbox = errors.New("the sandbox has expired or was closed")
+
+// Sandboxes holds scratch copies of the saved what-if settings. Each has a
+// settings manager of its own over in-memory storage, so edits made in it
+// never reach disk until it's committed. Restarting the server discards
+// every sandbox.
+type Sandboxes struct {
+	saved *SettingsManager
+	ttl   time.Duration
+	mu    sync.Mutex
+	open  map[string]*sandbox
+}
+
+// sandbox is one scratch copy and when it was last used
+type sandbox struct {
+	settings *SettingsManager
+	used     time.Time
+}
+
+// NewSandboxes creates sandboxes copying saved, each lasting ttl past its
+// last use
+func NewSandboxes(saved *SettingsManager, ttl time.Duration) *Sandboxes {
+	return &Sandboxes{
+		saved: saved,
+		ttl:   ttl,
+		open:  make(map[string]*sandbox),
+	}
+}
+
+// Open starts a sandbox holding a copy of the saved settings, returning its
+// ID and the manager to edit it through
+func (s *Sandboxes) Open() (string, *SettingsManager, error) {
+	current, err := s.saved.Load()
+	if err != nil {
+		return "", nil, err
+	}
+	store, err := storage.NewMemory(s.saved.settingsDir)
+	if err != nil {
+		return "", nil, err
+	}
+	copied := NewSettingsManager(s.saved.settingsDir, store)
+	// Unstamped, so the copy keeps the saved plan's change history
+	if err := copied.save(current, ""); err != nil {
+		return "", nil, err
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	id := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune()
+	s.open[id] = &sandbox{settings: copied, used: time.Now()}
+	return id, copied, nil
+}
+
+// Get returns the settings manager of the sandbox with id, keeping it
+// alive, or false when it has expired or never existed
+func (s *Sandboxes) Get(id string) (*SettingsManager, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	box, ok := s.open[id]
+	if !ok || time.Since(box.used) >= s.ttl {
+		return nil, false
+	}
+	box.used = time.Now()
+	return box.settings, true
+}
+
+// Commit replaces the saved settings with the sandbox's and closes it. The
+// saved plan's change history records every input the sandbox changed.
+func (s *Sandboxes) Commit(id string) (*models.WhatIfSettings, error) {
+	copied, ok := s.Get(id)
+	if !ok {
+		return nil, ErrNoSandbox
+	}
+	edited, err := copied.Load()
+	if err != nil {
+		return nil, err
+	}
+	saved, err := s.saved.Modify(func(settings *models.WhatIfSettings) error {
+		*settings = *edited
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.Discard(id)
+	return saved, nil
+}
+
+// Discard closes a sandbox, dropping its changes
+func (s *Sandboxes) Discard(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.open, id)
+}
+
+// prune drops sandboxes unused for the TTL (caller must hold lock)
+func (s *Sandboxes) prune() {
+	for id, box := range s.open {
+		if time.Since(box.used) >= s.ttl {
+			delete(s.open, id)
+		}
+	}
+}
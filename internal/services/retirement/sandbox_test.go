@@ -0,0 +1,72 @@
+package retirement
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+)
+
+func TestSandboxes(t *testing.T) {
+	saved, dir := newTestSettingsManager(t)
+	if _, err := saved.Modify(func(s *models.WhatIfSettings) error {
+		s.MonthlyLivingExpenses = 5000
+		return nil
+	}); err != nil {
+		t.Fatalf("Modify failed: %v", err)
+	}
+	sandboxes := NewSandboxes(saved, time.Hour)
+
+	id, sandbox, err := sandboxes.Open()
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if got, _ := sandbox.Load(); got.MonthlyLivingExpenses != 5000 {
+		t.Errorf("sandbox starts with living expenses %.0f, want the saved 5000", got.MonthlyLivingExpenses)
+	}
+	if _, err := sandbox.UpdateSettings(map[string]interface{}{"monthly_living_expenses": 9000.0}); err != nil {
+		t.Fatalf("UpdateSettings failed: %v", err)
+	}
+	if got, _ := saved.Load(); got.MonthlyLivingExpenses != 5000 {
+		t.Errorf("saved living expenses = %.0f after a sandbox edit, want 5000", got.MonthlyLivingExpenses)
+	}
+
+	if got, ok := sandboxes.Get(id); !ok || got != sandbox {
+		t.Fatal("Get didn't return the open sandbox")
+	}
+	// The sandbox writes nothing to disk
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("settings directory holds %d files, want just whatif.json and its backup", len(entries))
+	}
+
+	committed, err := sandboxes.Commit(id)
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if committed.MonthlyLivingExpenses != 9000 || committed.Changes["monthly_living_expenses"].Source != models.AssumptionUser {
+		t.Errorf("committed = %.0f (%+v), want 9000 recorded as the user's", committed.MonthlyLivingExpenses, committed.Changes["monthly_living_expenses"])
+	}
+	if _, ok := sandboxes.Get(id); ok {
+		t.Error("a committed sandbox should be closed")
+	}
+	if _, err := sandboxes.Commit(id); err != ErrNoSandbox {
+		t.Errorf("committing twice = %v, want ErrNoSandbox", err)
+	}
+
+	// Discarding drops the edits
+	id, sandbox, _ = sandboxes.Open()
+	sandbox.UpdateSettings(map[string]interface{}{"monthly_living_expenses": 100.0})
+	sandboxes.Discard(id)
+	if got, _ := saved.Load(); got.MonthlyLivingExpenses != 9000 {
+		t.Errorf("saved living expenses = %.0f after discarding, want 9000", got.MonthlyLivingExpenses)
+	}
+
+	// An idle sandbox expires
+	expiring := NewSandboxes(saved, time.Millisecond)
+	id, _, _ = expiring.Open()
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := expiring.Get(id); ok {
+		t.Error("expected an idle sandbox to expire")
+	}
+}
@@ -2,27 +2,73 @@ package retirement
 
 import (
 	"encoding/json"
+	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"budget2/internal/models"
 	"budget2/internal/services/storage"
 )
 
+// Suffixes for the last good copy and a preserved unreadable copy. They keep
+// the .json extension so encryption migration picks them up.
+const (
+	backupSuffix  = ".bak.json"
+	corruptSuffix = ".corrupt.json"
+)
+
+// SettingsStore is the file access SettingsManager needs. *storage.Storage
+// provides it over any storage.Backend, including storage.NewMemory for
+// tests that shouldn't touch disk.
+type SettingsStore interface {
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte, perm os.FileMode) error
+	Stat(path string) (os.FileInfo, error)
+	MkdirAll(path string, perm os.FileMode) error
+}
+
+var _ SettingsStore = (*storage.Storage)(nil)
+
+// whatifFile holds the what-if settings
+var whatifFile = storage.SettingsFile("whatif.json", func() interface{} { return &storedSettings{} })
+
+// storedSettings is the what-if settings file as a backup checks it
+type storedSettings struct {
+	models.WhatIfSettings
+}
+
+// CheckSettings refuses settings written by a newer version, as loading does
+func (s *storedSettings) CheckSettings() error {
+	return checkSchemaVersion(s.SchemaVersion)
+}
+
+// checkSchemaVersion refuses settings written by a newer version rather than
+// silently dropping their fields
+func checkSchemaVersion(version int) error {
+	if version > SettingsSchemaVersion {
+		return fmt.Errorf("settings schema version %d is newer than supported version %d",
+			version, SettingsSchemaVersion)
+	}
+	return nil
+}
+
 // SettingsManager handles persistence of what-if settings
 type SettingsManager struct {
 	settingsDir string
 	filename    string
-	store       *storage.Storage
+	store       SettingsStore
 	mu          sync.RWMutex
 }
 
 // NewSettingsManager creates a new settings manager
-func NewSettingsManager(settingsDir string, store *storage.Storage) *SettingsManager {
+func NewSettingsManager(settingsDir string, store SettingsStore) *SettingsManager {
 	return &SettingsManager{
 		settingsDir: settingsDir,
-		filename:    "whatif.json",
+		filename:    whatifFile,
 		store:       store,
 	}
 }
@@ -32,14 +78,58 @@ func (sm *SettingsManager) filepath() string {
 	return filepath.Join(sm.settingsDir, sm.filename)
 }
 
-// Load reads settings from disk, returning defaults if file doesn't exist
+// backupPath returns the full path to the last known good copy
+func (sm *SettingsManager) backupPath() string {
+	return sm.siblingPath(backupSuffix)
+}
+
+// siblingPath returns the settings path with its .json extension replaced by suffix
+func (sm *SettingsManager) siblingPath(suffix string) string {
+	return strings.TrimSuffix(sm.filepath(), filepath.Ext(sm.filename)) + suffix
+}
+
+// Load reads settings from disk, returning defaults if file doesn't exist.
+// It takes the write lock because a corrupted file is repaired from backup.
 func (sm *SettingsManager) Load() (*models.WhatIfSettings, error) {
-	sm.mu.RLock()
-	defer sm.mu.RUnlock()
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 
 	return sm.loadInternal()
 }
 
+// Modify runs fn on the current settings and saves the result, holding the
+// lock for the whole read-modify-write so concurrent requests can't interleave
+func (sm *SettingsManager) Modify(fn func(*models.WhatIfSettings) error) (*models.WhatIfSettings, error) {
+	return sm.modify(fn, models.AssumptionUser)
+}
+
+// Sync is Modify for changes derived from transaction data, which the
+// assumptions report lists as synced rather than user-entered
+func (sm *SettingsManager) Sync(fn func(*models.WhatIfSettings) error) (*models.WhatIfSettings, error) {
+	return sm.modify(fn, models.AssumptionSynced)
+}
+
+// modify runs fn on the current settings and saves the changes as source
+func (sm *SettingsManager) modify(fn func(*models.WhatIfSettings) error, source string) (*models.WhatIfSettings, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	settings, err := sm.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fn(settings); err != nil {
+		return nil, err
+	}
+
+	if err := sm.save(settings, source); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
 // loadInternal reads settings without acquiring lock (caller must hold lock)
 func (sm *SettingsManager) loadInternal() (*models.WhatIfSettings, error) {
 	// Ensure settings directory exists
@@ -55,16 +145,21 @@ func (sm *SettingsManager) loadInternal() (*models.WhatIfSettings, error) {
 		return models.DefaultWhatIfSettings(), nil
 	}
 
-	// Read file (storage handles decryption)
-	data, err := sm.store.ReadFile(path)
+	settings, raw, err := sm.readSettingsFile(path)
 	if err != nil {
-		return models.DefaultWhatIfSettings(), err
-	}
-
-	// Parse JSON
-	var settings models.WhatIfSettings
-	if err := json.Unmarshal(data, &settings); err != nil {
-		return models.DefaultWhatIfSettings(), err
+		// Fall back to the last good backup and repair the main file
+		recovered, recoveredRaw, backupErr := sm.readSettingsFile(sm.backupPath())
+		if backupErr != nil {
+			return models.DefaultWhatIfSettings(), err
+		}
+		log.Printf("Settings file %s is unreadable (%v); recovered from backup", path, err)
+		sm.preserveCorrupted(path)
+		if data, marshalErr := json.MarshalIndent(recovered, "", "  "); marshalErr == nil {
+			if writeErr := sm.store.WriteFile(path, data, 0644); writeErr != nil {
+				log.Printf("Error restoring settings file from backup: %v", writeErr)
+			}
+		}
+		settings, raw = recovered, recoveredRaw
 	}
 
 	// Ensure slices are initialized
@@ -84,29 +179,71 @@ func (sm *SettingsManager) loadInternal() (*models.WhatIfSettings, error) {
 		settings.HealthcarePersons = []models.HealthcarePerson{}
 	}
 
-	// Migration: if no healthcare persons but legacy healthcare value exists,
-	// create a single person from legacy values
-	if len(settings.HealthcarePersons) == 0 && settings.MonthlyHealthcare > 0 {
-		coverage := models.CoverageMedicare
-		if settings.CurrentAge < 65 {
-			coverage = models.CoverageACA
+	// Upgrade files written by older versions and persist the result
+	if settings.SchemaVersion < SettingsSchemaVersion {
+		fromVersion := settings.SchemaVersion
+		applied, err := migrateSettings(settings, raw)
+		if err != nil {
+			return models.DefaultWhatIfSettings(), err
 		}
-		settings.HealthcarePersons = []models.HealthcarePerson{
-			{
-				ID:                    "migrated-user",
-				Name:                  "User",
-				CurrentAge:            settings.CurrentAge,
-				CurrentCoverage:       coverage,
-				CurrentMonthlyCost:    settings.MonthlyHealthcare,
-				PreMedicareInflation:  settings.HealthcareInflation,
-				MedicareMonthlyCost:   settings.MonthlyHealthcare,
-				PostMedicareInflation: settings.HealthcareInflation,
-				MedicareEligibleAge:   65,
-			},
+		log.Printf("Migrated settings from schema version %d to %d (applied %v)", fromVersion, settings.SchemaVersion, applied)
+		sm.preserveVersion(path, fromVersion)
+		if err := sm.save(settings, ""); err != nil {
+			log.Printf("Error saving migrated settings: %v", err)
 		}
 	}
 
-	return &settings, nil
+	return settings, nil
+}
+
+// readSettingsFile reads and parses a settings file, also returning its
+// top-level keys for migrations
+func (sm *SettingsManager) readSettingsFile(path string) (*models.WhatIfSettings, map[string]json.RawMessage, error) {
+	// Read file (storage handles decryption)
+	data, err := sm.store.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Parse JSON
+	var settings models.WhatIfSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, nil, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkSchemaVersion(settings.SchemaVersion); err != nil {
+		return nil, nil, err
+	}
+
+	return &settings, raw, nil
+}
+
+// preserveVersion keeps a copy of the file as it was before migration
+func (sm *SettingsManager) preserveVersion(path string, version int) {
+	data, err := sm.store.ReadFile(path)
+	if err != nil {
+		return
+	}
+	versionPath := sm.siblingPath(fmt.Sprintf(".v%d.json", version))
+	if err := sm.store.WriteFile(versionPath, data, 0644); err != nil {
+		log.Printf("Error preserving pre-migration settings: %v", err)
+	}
+}
+
+// preserveCorrupted keeps a copy of an unreadable settings file for inspection
+func (sm *SettingsManager) preserveCorrupted(path string) {
+	data, err := sm.store.ReadFile(path)
+	if err != nil {
+		return
+	}
+	corruptPath := sm.siblingPath(corruptSuffix)
+	if err := sm.store.WriteFile(corruptPath, data, 0644); err != nil {
+		log.Printf("Error preserving corrupted settings file: %v", err)
+	}
 }
 
 // Save writes settings to disk
@@ -117,21 +254,49 @@ func (sm *SettingsManager) Save(settings *models.WhatIfSettings) error {
 	return sm.saveInternal(settings)
 }
 
-// saveInternal writes settings without acquiring lock (caller must hold lock)
+// saveInternal writes settings without acquiring lock (caller must hold lock),
+// recording what changed as entered by the user
 func (sm *SettingsManager) saveInternal(settings *models.WhatIfSettings) error {
+	return sm.save(settings, models.AssumptionUser)
+}
+
+// save writes settings, stamping the inputs that differ from the saved copy
+// with source, or recording nothing when source is "" (caller must hold lock)
+func (sm *SettingsManager) save(settings *models.WhatIfSettings, source string) error {
 	// Ensure settings directory exists
 	if err := sm.store.MkdirAll(sm.settingsDir, 0755); err != nil {
 		return err
 	}
 
+	settings.SchemaVersion = SettingsSchemaVersion
+	// Keep stored month offsets in step with CurrentAge for age-timed income
+	settings.ResolveIncomeAges()
+
+	if source != "" {
+		before, _, err := sm.readSettingsFile(sm.filepath())
+		if err != nil {
+			before = models.DefaultWhatIfSettings()
+		}
+		settings.RecordChanges(before, source, time.Now())
+	}
+
 	// Marshal to JSON with indentation for readability
 	data, err := json.MarshalIndent(settings, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	// Write file (storage handles encryption)
-	return sm.store.WriteFile(sm.filepath(), data, 0644)
+	// Write file (storage handles encryption and atomic temp+rename)
+	if err := sm.store.WriteFile(sm.filepath(), data, 0644); err != nil {
+		return err
+	}
+
+	// Keep a copy of the last good write for corruption recovery
+	if err := sm.store.WriteFile(sm.backupPath(), data, 0644); err != nil {
+		log.Printf("Error writing settings backup: %v", err)
+	}
+
+	return nil
 }
 
 // AddIncomeSource adds a new income source and saves atomically
@@ -210,7 +375,7 @@ func (sm *SettingsManager) RestoreIncomeSource(id string) (*models.WhatIfSetting
 }
 
 // UpdateIncomeSource updates an existing income source by ID atomically
-func (sm *SettingsManager) UpdateIncomeSource(id string, startYear, endYear int, colaRate float64) (*models.WhatIfSettings, error) {
+func (sm *SettingsManager) UpdateIncomeSource(id string, timing models.IncomeTiming, colaRate float64, inflationAdjusted bool) (*models.WhatIfSettings, error) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
@@ -221,14 +386,9 @@ func (sm *SettingsManager) UpdateIncomeSource(id string, startYear, endYear int,
 
 	for i := range settings.IncomeSources {
 		if settings.IncomeSources[i].ID == id {
-			settings.IncomeSources[i].StartMonth = startYear * 12
+			timing.Apply(&settings.IncomeSources[i])
 			settings.IncomeSources[i].COLARate = colaRate
-			if endYear > 0 {
-				endMonth := endYear * 12
-				settings.IncomeSources[i].EndMonth = &endMonth
-			} else {
-				settings.IncomeSources[i].EndMonth = nil
-			}
+			settings.IncomeSources[i].InflationAdjusted = inflationAdjusted
 			break
 		}
 	}
@@ -342,6 +502,82 @@ func (sm *SettingsManager) RestoreExpenseSource(id string) (*models.WhatIfSettin
 	return settings, nil
 }
 
+// AddPortfolioBucket adds a portfolio bucket and saves atomically
+func (sm *SettingsManager) AddPortfolioBucket(bucket models.PortfolioBucket) (*models.WhatIfSettings, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	settings, err := sm.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	settings.PortfolioBuckets = append(settings.PortfolioBuckets, bucket)
+	settings.SyncPortfolioBuckets()
+
+	if err := sm.saveInternal(settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// UpdatePortfolioBucket updates a portfolio bucket's value, return and
+// withdrawal priority by ID atomically
+func (sm *SettingsManager) UpdatePortfolioBucket(id string, value, annualReturn float64, priority int) (*models.WhatIfSettings, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	settings, err := sm.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range settings.PortfolioBuckets {
+		if settings.PortfolioBuckets[i].ID == id {
+			settings.PortfolioBuckets[i].Value = value
+			settings.PortfolioBuckets[i].Return = annualReturn
+			settings.PortfolioBuckets[i].Priority = priority
+			break
+		}
+	}
+	settings.SyncPortfolioBuckets()
+
+	if err := sm.saveInternal(settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// RemovePortfolioBucket removes a portfolio bucket by ID and saves
+// atomically. Removing the last bucket leaves the portfolio value and
+// return where the buckets had them.
+func (sm *SettingsManager) RemovePortfolioBucket(id string) (*models.WhatIfSettings, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	settings, err := sm.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.PortfolioBucket, 0, len(settings.PortfolioBuckets))
+	for _, bucket := range settings.PortfolioBuckets {
+		if bucket.ID != id {
+			filtered = append(filtered, bucket)
+		}
+	}
+	settings.PortfolioBuckets = filtered
+	settings.SyncPortfolioBuckets()
+
+	if err := sm.saveInternal(settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
 // UpdateSettings updates all settings fields from form data and saves atomically
 func (sm *SettingsManager) UpdateSettings(updates map[string]interface{}) (*models.WhatIfSettings, error) {
 	sm.mu.Lock()
@@ -380,6 +616,9 @@ func (sm *SettingsManager) UpdateSettings(updates map[string]interface{}) (*mode
 	if v, ok := updates["spending_decline_rate"].(float64); ok {
 		settings.SpendingDeclineRate = v
 	}
+	if v, ok := updates["essential_floor"].(float64); ok {
+		settings.EssentialFloor = v
+	}
 	if v, ok := updates["investment_return"].(float64); ok {
 		settings.InvestmentReturn = v
 	}
@@ -392,6 +631,29 @@ func (sm *SettingsManager) UpdateSettings(updates map[string]interface{}) (*mode
 	if v, ok := updates["steady_state_override_year"].(float64); ok {
 		settings.SteadyStateOverrideYear = v
 	}
+	if v, ok := updates["annual_qcd"].(float64); ok {
+		settings.AnnualQCD = v
+	}
+	if v, ok := updates["legacy_target"].(float64); ok {
+		settings.LegacyTarget = v
+	}
+	if v, ok := updates["crash_recovery"].(string); ok {
+		settings.CrashRecovery = v
+	}
+	if v, ok := updates["bear_market_years"].(int); ok {
+		settings.BearMarketYears = v
+	}
+	if v, ok := updates["inflation_correlation"].(float64); ok {
+		settings.InflationCorrelation = v
+	}
+	if v, ok := updates["sex"].(string); ok {
+		settings.Sex = v
+	}
+	if v, ok := updates["health"].(string); ok {
+		settings.Health = v
+	}
+	// Buckets set the portfolio value and return when there are any
+	settings.SyncPortfolioBuckets()
 
 	if err := sm.saveInternal(settings); err != nil {
 		return nil, err
@@ -0,0 +1,300 @@
+package retirement
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func newTestSettingsManager(t *testing.T) (*SettingsManager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := storage.New(dir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	settingsDir := filepath.Join(dir, "settings")
+	return NewSettingsManager(settingsDir, store), settingsDir
+}
+
+// newMemorySettingsManager returns a settings manager that never touches
+// disk, for tests that don't inspect the files themselves
+func newMemorySettingsManager(t *testing.T) *SettingsManager {
+	t.Helper()
+	store, err := storage.NewMemory("/data")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	return NewSettingsManager("/data/settings", store)
+}
+
+// TestSettingsSaveStampsSchemaVersion verifies saved files carry the schema version
+func TestSettingsSaveStampsSchemaVersion(t *testing.T) {
+	sm, dir := newTestSettingsManager(t)
+
+	if err := sm.Save(models.DefaultWhatIfSettings()); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := sm.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.SchemaVersion != SettingsSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", loaded.SchemaVersion, SettingsSchemaVersion)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "whatif.bak.json")); err != nil {
+		t.Errorf("expected backup file to be written: %v", err)
+	}
+}
+
+// TestSettingsRecoverFromCorruption verifies a corrupted file falls back to the last good backup
+func TestSettingsRecoverFromCorruption(t *testing.T) {
+	sm, dir := newTestSettingsManager(t)
+
+	settings := models.DefaultWhatIfSettings()
+	settings.PortfolioValue = 750000
+	if err := sm.Save(settings); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Simulate a torn write
+	mainPath := filepath.Join(dir, "whatif.json")
+	if err := os.WriteFile(mainPath, []byte(`{"portfolio_value": 75`), 0644); err != nil {
+		t.Fatalf("Failed to corrupt file: %v", err)
+	}
+
+	loaded, err := sm.Load()
+	if err != nil {
+		t.Fatalf("Load should recover from backup, got error: %v", err)
+	}
+	if loaded.PortfolioValue != 750000 {
+		t.Errorf("PortfolioValue = %v, want 750000 from backup", loaded.PortfolioValue)
+	}
+
+	// Main file is repaired and the bad copy is preserved
+	data, _ := os.ReadFile(mainPath)
+	if !strings.Contains(string(data), "750000") {
+		t.Error("main settings file was not repaired from backup")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "whatif.corrupt.json")); err != nil {
+		t.Errorf("expected corrupted copy to be preserved: %v", err)
+	}
+}
+
+// TestSettingsRejectNewerSchema verifies files from a newer version are not overwritten with defaults
+func TestSettingsRejectNewerSchema(t *testing.T) {
+	sm, dir := newTestSettingsManager(t)
+	os.MkdirAll(dir, 0755)
+
+	future := `{"schema_version": 999, "portfolio_value": 1}`
+	if err := os.WriteFile(filepath.Join(dir, "whatif.json"), []byte(future), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if _, err := sm.AddIncomeSource(models.IncomeSource{ID: "x"}); err == nil {
+		t.Fatal("expected error for newer schema version")
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "whatif.json"))
+	if string(data) != future {
+		t.Error("settings file from newer version should be left untouched")
+	}
+}
+
+// TestSettingsConcurrentModify verifies concurrent read-modify-writes don't lose updates
+func TestSettingsConcurrentModify(t *testing.T) {
+	sm := newMemorySettingsManager(t)
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := sm.AddIncomeSource(models.IncomeSource{
+				ID:     "src-" + string(rune('a'+i)),
+				Name:   "Source",
+				Amount: 100,
+			})
+			if err != nil {
+				t.Errorf("AddIncomeSource failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	settings, err := sm.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(settings.IncomeSources) != writers {
+		t.Errorf("got %d income sources, want %d", len(settings.IncomeSources), writers)
+	}
+}
+
+// TestSettingsMigrateLegacyFile verifies an unversioned file is upgraded and persisted on load
+func TestSettingsMigrateLegacyFile(t *testing.T) {
+	sm, dir := newTestSettingsManager(t)
+	os.MkdirAll(dir, 0755)
+
+	legacy := `{
+  "portfolio_value": 500000,
+  "monthly_healthcare": 1200,
+  "current_age": 62,
+  "healthcare_inflation": 5,
+  "investment_return": 7
+}`
+	mainPath := filepath.Join(dir, "whatif.json")
+	if err := os.WriteFile(mainPath, []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write legacy file: %v", err)
+	}
+
+	settings, err := sm.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if settings.SchemaVersion != SettingsSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", settings.SchemaVersion, SettingsSchemaVersion)
+	}
+	if len(settings.HealthcarePersons) != 1 || settings.HealthcarePersons[0].CurrentCoverage != models.CoverageACA {
+		t.Errorf("expected one migrated ACA healthcare person, got %+v", settings.HealthcarePersons)
+	}
+	if settings.DiscountRate != 5.0 || settings.SpendingDeclineRate != 1.0 {
+		t.Errorf("missing rates not defaulted: discount=%v decline=%v", settings.DiscountRate, settings.SpendingDeclineRate)
+	}
+	if settings.InflationCorrelation != 0.5 {
+		t.Errorf("InflationCorrelation = %v, want default 0.5", settings.InflationCorrelation)
+	}
+
+	// Upgraded file is persisted and the original kept alongside it
+	data, _ := os.ReadFile(mainPath)
+	if !strings.Contains(string(data), fmt.Sprintf(`"schema_version": %d`, SettingsSchemaVersion)) {
+		t.Error("migrated settings were not persisted")
+	}
+	if original, err := os.ReadFile(filepath.Join(dir, "whatif.v0.json")); err != nil || string(original) != legacy {
+		t.Errorf("expected pre-migration copy to be preserved: %v", err)
+	}
+
+	// Removing the migrated person must stick: migrations don't re-run on current files
+	if _, err := sm.RemoveHealthcarePerson("migrated-user"); err != nil {
+		t.Fatalf("RemoveHealthcarePerson failed: %v", err)
+	}
+	settings, _ = sm.Load()
+	if len(settings.HealthcarePersons) != 0 {
+		t.Errorf("healthcare person reappeared after removal: %+v", settings.HealthcarePersons)
+	}
+}
+
+// TestSettingsMigrateV0Fixture verifies a complete v0 file migrates to the
+// current schema without losing any of its values
+func TestSettingsMigrateV0Fixture(t *testing.T) {
+	sm, dir := newTestSettingsManager(t)
+	os.MkdirAll(dir, 0755)
+
+	fixture, err := os.ReadFile(filepath.Join("testdata", "whatif_v0.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "whatif.json"), fixture, 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	settings, err := sm.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if settings.SchemaVersion != SettingsSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", settings.SchemaVersion, SettingsSchemaVersion)
+	}
+	if settings.PortfolioValue != 500000 || settings.MonthlyLivingExpenses != 4000 || settings.CurrentAge != 65 {
+		t.Errorf("v0 values not kept: portfolio=%v expenses=%v age=%d",
+			settings.PortfolioValue, settings.MonthlyLivingExpenses, settings.CurrentAge)
+	}
+	if settings.InflationRate != 3 || settings.InvestmentReturn != 7 || settings.ProjectionYears != 30 {
+		t.Errorf("v0 rates not kept: inflation=%v return=%v years=%d",
+			settings.InflationRate, settings.InvestmentReturn, settings.ProjectionYears)
+	}
+	if len(settings.IncomeSources) != 2 || settings.IncomeSources[0].ID != "test-ss" || settings.IncomeSources[1].ID != "test-pension" {
+		t.Errorf("v0 income sources not kept: %+v", settings.IncomeSources)
+	}
+
+	// v1: the single healthcare value becomes a Medicare person at 65
+	if len(settings.HealthcarePersons) != 1 {
+		t.Fatalf("expected one migrated healthcare person, got %+v", settings.HealthcarePersons)
+	}
+	person := settings.HealthcarePersons[0]
+	if person.CurrentCoverage != models.CoverageMedicare || person.CurrentMonthlyCost != 1200 || person.PreMedicareInflation != 5 {
+		t.Errorf("migrated healthcare person = %+v", person)
+	}
+	// v2 and v3: rates the file predates get their defaults
+	if settings.DiscountRate != 5.0 || settings.SpendingDeclineRate != 1.0 || settings.InflationCorrelation != 0.5 {
+		t.Errorf("missing rates not defaulted: discount=%v decline=%v correlation=%v",
+			settings.DiscountRate, settings.SpendingDeclineRate, settings.InflationCorrelation)
+	}
+
+	if original, err := os.ReadFile(filepath.Join(dir, "whatif.v0.json")); err != nil || string(original) != string(fixture) {
+		t.Errorf("expected pre-migration copy to be preserved: %v", err)
+	}
+}
+
+// TestSettingsMigrationsOrdered verifies migration versions are strictly increasing
+func TestSettingsMigrationsOrdered(t *testing.T) {
+	for i, m := range settingsMigrations {
+		if m.version != i+1 {
+			t.Errorf("migration %d has version %d, want %d", i, m.version, i+1)
+		}
+	}
+}
+
+// TestSettingsRecordChanges verifies saves stamp the inputs they change with
+// their source
+func TestSettingsRecordChanges(t *testing.T) {
+	sm := newMemorySettingsManager(t)
+
+	settings, err := sm.UpdateSettings(map[string]interface{}{"investment_return": 7.5, "inflation_rate": 3.0})
+	if err != nil {
+		t.Fatalf("UpdateSettings failed: %v", err)
+	}
+	change, ok := settings.Changes["investment_return"]
+	if !ok || change.Source != models.AssumptionUser || change.At.IsZero() {
+		t.Errorf("investment_return change = %+v, want a dated user change", change)
+	}
+	if _, ok := settings.Changes["inflation_rate"]; ok {
+		t.Error("inflation_rate was saved at its default and shouldn't count as changed")
+	}
+
+	settings, err = sm.Sync(func(s *models.WhatIfSettings) error {
+		s.MonthlyLivingExpenses = 5100
+		s.IncomeSources = append(s.IncomeSources, models.IncomeSource{ID: "insights-payroll", Name: "Payroll", Amount: 6000})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	for _, key := range []string{"monthly_living_expenses", "income_sources/insights-payroll"} {
+		if settings.Changes[key].Source != models.AssumptionSynced {
+			t.Errorf("%s change = %+v, want synced", key, settings.Changes[key])
+		}
+	}
+	if settings.Changes["investment_return"].Source != models.AssumptionUser {
+		t.Error("a sync shouldn't restamp inputs it didn't change")
+	}
+
+	settings, err = sm.RemoveIncomeSource("insights-payroll")
+	if err != nil {
+		t.Fatalf("RemoveIncomeSource failed: %v", err)
+	}
+	if _, ok := settings.Changes["income_sources/insights-payroll"]; ok {
+		t.Error("a removed source's change should be forgotten")
+	}
+}
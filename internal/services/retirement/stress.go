@@ -0,0 +1,80 @@
+package retirement
+
+import (
+	"fmt"
+	"time"
+
+	"budget2/internal/models"
+)
+
+// StressCrashReturn is the annual return below which a stressed year counts
+// as a market crash, matching the adaptive spending trigger
+const StressCrashReturn = -15.0
+
+// stressScenarios are the built-in stress presets. Returns are S&P 500 total
+// returns and inflation is December-to-December CPI for the years replayed.
+var stressScenarios = []models.StressScenario{
+	{
+		Name:        "2008",
+		Label:       "2008 Replay",
+		Description: "Retire into the 2008 financial crisis and the recovery that followed (2008-2012)",
+		Returns:     []float64{-37.0, 26.5, 15.1, 2.1, 16.0},
+		Inflation:   []float64{0.1, 2.7, 1.5, 3.0, 1.7},
+	},
+	{
+		Name:        "stagflation",
+		Label:       "Stagflation",
+		Description: "A 1970s decade of high inflation and choppy markets (1973-1982)",
+		Returns:     []float64{-14.7, -26.5, 37.2, 23.8, -7.2, 6.6, 18.4, 32.4, -4.9, 21.4},
+		Inflation:   []float64{8.7, 12.3, 6.9, 4.9, 6.7, 9.0, 13.3, 12.5, 8.9, 3.8},
+	},
+	{
+		Name:        "lost-decade",
+		Label:       "Lost Decade",
+		Description: "Ten years of flat stock returns from the dot-com bust through 2008 (2000-2009)",
+		Returns:     []float64{-9.1, -11.9, -22.1, 28.7, 10.9, 4.9, 15.8, 5.5, -37.0, 26.5},
+		Inflation:   []float64{3.4, 1.6, 2.4, 1.9, 3.3, 3.4, 2.5, 4.1, 0.1, 2.7},
+	},
+}
+
+// StressScenarios returns the built-in stress presets
+func StressScenarios() []models.StressScenario {
+	return stressScenarios
+}
+
+// FindStressScenario looks up a stress preset by name
+func FindStressScenario(name string) (models.StressScenario, bool) {
+	for _, scenario := range stressScenarios {
+		if scenario.Name == name {
+			return scenario, true
+		}
+	}
+	return models.StressScenario{}, false
+}
+
+// RunStressTest runs the Monte Carlo simulation with the named scenario's
+// returns and inflation replacing the first years of every run, and compares
+// it with an unstressed simulation on the same seed. Randomness resumes once
+// the scenario's years are over.
+func (c *Calculator) RunStressTest(name string, runs int) (*models.StressTestResult, error) {
+	scenario, ok := FindStressScenario(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown stress scenario %q", name)
+	}
+
+	seed := time.Now().UnixNano()
+	baseline := c.runMonteCarlo(runs, c.monteCarloConfig(), seed)
+
+	config := c.monteCarloConfig()
+	config.StressReturns = scenario.Returns
+	config.StressInflation = scenario.Inflation
+	stressed := c.runMonteCarlo(runs, config, seed)
+
+	return &models.StressTestResult{
+		Scenario:            scenario,
+		Stressed:            stressed.Stats,
+		Baseline:            baseline.Stats,
+		SuccessRateChange:   stressed.Stats.SuccessRate - baseline.Stats.SuccessRate,
+		MedianBalanceChange: stressed.Stats.MedianBalance - baseline.Stats.MedianBalance,
+	}, nil
+}
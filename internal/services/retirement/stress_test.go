@@ -0,0 +1,72 @@
+package retirement
+
+import (
+	"math/rand"
+	"testing"
+
+	"budget2/internal/models"
+)
+
+// TestStressReturnsReplaceGenerated verifies a scenario's returns open the
+// sequence and its steep losses count as crashes
+func TestStressReturnsReplaceGenerated(t *testing.T) {
+	calc := NewCalculator(models.DefaultWhatIfSettings())
+	scenario, ok := FindStressScenario("lost-decade")
+	if !ok {
+		t.Fatal("lost-decade scenario not found")
+	}
+
+	config := DefaultMonteCarloConfig()
+	config.CrashProbability = 0
+	config.StressReturns = scenario.Returns
+	timing := &CrashTiming{}
+	lastCrashYear := -999
+	returns := calc.generateYearlyReturns(rand.New(rand.NewSource(1)), config, 30, timing, &lastCrashYear)
+
+	for i, want := range scenario.Returns {
+		if returns[i] != want {
+			t.Errorf("year %d return = %.1f, want %.1f", i+1, returns[i], want)
+		}
+	}
+	// 2002 (-22.1%) and 2008 (-37.0%)
+	if timing.TotalCrashes != 2 || timing.EarlyCrashes != 1 || timing.MidCrashes != 1 || timing.FirstCrashYear != 3 {
+		t.Errorf("crash timing = %+v, want 2 crashes starting in year 3", timing)
+	}
+}
+
+// TestRunStressTest verifies each preset runs and a decade of flat returns at
+// retirement hurts the plan compared with the baseline
+func TestRunStressTest(t *testing.T) {
+	settings := models.DefaultWhatIfSettings()
+	settings.PortfolioValue = 1000000
+	settings.MonthlyLivingExpenses = 4000
+	settings.MonthlyHealthcare = 0
+	calc := NewCalculator(settings)
+
+	for _, scenario := range StressScenarios() {
+		if len(scenario.Returns) != len(scenario.Inflation) {
+			t.Errorf("%s has %d returns but %d inflation rates", scenario.Name, len(scenario.Returns), len(scenario.Inflation))
+		}
+		result, err := calc.RunStressTest(scenario.Name, 200)
+		if err != nil {
+			t.Fatalf("RunStressTest(%s): %v", scenario.Name, err)
+		}
+		if result.Stressed.Runs != 200 || result.Baseline.Runs != 200 {
+			t.Errorf("%s runs = %d/%d, want 200", scenario.Name, result.Stressed.Runs, result.Baseline.Runs)
+		}
+	}
+
+	result, _ := calc.RunStressTest("lost-decade", 500)
+	if result.MedianBalanceChange >= 0 || result.SuccessRateChange >= 0 {
+		t.Errorf("lost decade changes = %.0f median, %.1f success, want both lower", result.MedianBalanceChange, result.SuccessRateChange)
+	}
+
+	result, _ = calc.RunStressTest("2008", 500)
+	if result.Stressed.MarketCrashCount != 500 {
+		t.Errorf("2008 replay crash runs = %d, want all 500", result.Stressed.MarketCrashCount)
+	}
+
+	if _, err := calc.RunStressTest("tulip-mania", 100); err == nil {
+		t.Error("unknown scenario should fail")
+	}
+}
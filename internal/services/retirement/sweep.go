@@ -0,0 +1,153 @@
+package retirement
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"budget2/internal/models"
+)
+
+// MaxSweepPoints caps how many values one sweep runs, since every point is
+// a full Monte Carlo simulation
+const MaxSweepPoints = 50
+
+// sweepParam reads and overrides one setting for a sweep
+type sweepParam struct {
+	get func(s *models.WhatIfSettings) float64
+	set func(s *models.WhatIfSettings, v float64)
+}
+
+// sweepParams are the settings a sweep can vary, keyed like the settings
+// form. Whole-year settings are rounded.
+var sweepParams = map[string]sweepParam{
+	"monthly_living_expenses": {
+		func(s *models.WhatIfSettings) float64 { return s.MonthlyLivingExpenses },
+		func(s *models.WhatIfSettings, v float64) { s.MonthlyLivingExpenses = v },
+	},
+	"essential_floor": {
+		func(s *models.WhatIfSettings) float64 { return s.EssentialFloor },
+		func(s *models.WhatIfSettings, v float64) { s.EssentialFloor = v },
+	},
+	"monthly_healthcare": {
+		func(s *models.WhatIfSettings) float64 { return s.MonthlyHealthcare },
+		func(s *models.WhatIfSettings, v float64) { s.MonthlyHealthcare = v },
+	},
+	"portfolio_value": {
+		func(s *models.WhatIfSettings) float64 { return s.PortfolioValue },
+		func(s *models.WhatIfSettings, v float64) { s.PortfolioValue = v },
+	},
+	"investment_return": {
+		func(s *models.WhatIfSettings) float64 { return s.InvestmentReturn },
+		func(s *models.WhatIfSettings, v float64) { s.InvestmentReturn = v },
+	},
+	"inflation_rate": {
+		func(s *models.WhatIfSettings) float64 { return s.InflationRate },
+		func(s *models.WhatIfSettings, v float64) { s.InflationRate = v },
+	},
+	"spending_decline_rate": {
+		func(s *models.WhatIfSettings) float64 { return s.SpendingDeclineRate },
+		func(s *models.WhatIfSettings, v float64) { s.SpendingDeclineRate = v },
+	},
+	"tax_deferred_percent": {
+		func(s *models.WhatIfSettings) float64 { return s.TaxDeferredPercent },
+		func(s *models.WhatIfSettings, v float64) { s.TaxDeferredPercent = v },
+	},
+	"annual_qcd": {
+		func(s *models.WhatIfSettings) float64 { return s.AnnualQCD },
+		func(s *models.WhatIfSettings, v float64) { s.AnnualQCD = v },
+	},
+	"legacy_target": {
+		func(s *models.WhatIfSettings) float64 { return s.LegacyTarget },
+		func(s *models.WhatIfSettings, v float64) { s.LegacyTarget = v },
+	},
+	"current_age": {
+		func(s *models.WhatIfSettings) float64 { return float64(s.CurrentAge) },
+		func(s *models.WhatIfSettings, v float64) { s.CurrentAge = int(math.Round(v)) },
+	},
+	"projection_years": {
+		func(s *models.WhatIfSettings) float64 { return float64(s.ProjectionYears) },
+		func(s *models.WhatIfSettings, v float64) { s.ProjectionYears = int(math.Round(v)) },
+	},
+}
+
+// SweepParams returns the setting keys a sweep can vary, sorted
+func SweepParams() []string {
+	names := make([]string, 0, len(sweepParams))
+	for name := range sweepParams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SweepValues expands an inclusive from..to range in step increments
+func SweepValues(from, to, step float64) ([]float64, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+	if to < from {
+		return nil, fmt.Errorf("to must not be less than from")
+	}
+	// Count steps rather than accumulating so 0.1 steps don't drift
+	n := int(math.Floor((to-from)/step+1e-9)) + 1
+	if n > MaxSweepPoints {
+		return nil, fmt.Errorf("sweep has %d points, the most is %d", n, MaxSweepPoints)
+	}
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = from + float64(i)*step
+	}
+	return values, nil
+}
+
+// RunSweep runs the projection and Monte Carlo simulation once per value,
+// with param overridden on a copy of the settings. Every point shares a
+// seed so success rates differ only because of the setting.
+func (c *Calculator) RunSweep(param string, values []float64, runs int) (*models.SweepResult, error) {
+	p, ok := sweepParams[param]
+	if !ok {
+		return nil, fmt.Errorf("unknown sweep parameter %q (one of %s)", param, strings.Join(SweepParams(), ", "))
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no values to sweep")
+	}
+	if len(values) > MaxSweepPoints {
+		return nil, fmt.Errorf("sweep has %d points, the most is %d", len(values), MaxSweepPoints)
+	}
+
+	seed := time.Now().UnixNano()
+	result := &models.SweepResult{
+		Param:   param,
+		Current: p.get(c.Settings),
+		Runs:    runs,
+		Points:  make([]models.SweepPoint, 0, len(values)),
+	}
+	for _, v := range values {
+		calc := c.withSetting(p, v)
+		projection := calc.RunProjection()
+		monteCarlo := calc.runMonteCarlo(runs, calc.monteCarloConfig(), seed)
+		result.Points = append(result.Points, models.SweepPoint{
+			Value:          v,
+			SuccessRate:    monteCarlo.Stats.SuccessRate,
+			MedianBalance:  monteCarlo.Stats.MedianBalance,
+			FinalBalance:   projection.FinalBalance,
+			Survives:       projection.Survives,
+			LongevityYears: projection.LongevityYears,
+			Score:          calc.CalculateSustainabilityScore(projection).Score,
+		})
+	}
+	return result, nil
+}
+
+// withSetting returns a calculator for a copy of the settings with one
+// setting set to v
+func (c *Calculator) withSetting(p sweepParam, v float64) *Calculator {
+	modified := *c.Settings
+	modified.IncomeSources = append([]models.IncomeSource{}, c.Settings.IncomeSources...)
+	modified.ExpenseSources = append([]models.ExpenseSource{}, c.Settings.ExpenseSources...)
+	p.set(&modified, v)
+	return NewCalculator(&modified)
+}
@@ -0,0 +1,61 @@
+package retirement
+
+import (
+	"testing"
+
+	"budget2/internal/models"
+)
+
+// TestSweepValues verifies ranges expand inclusively and bad ranges fail
+func TestSweepValues(t *testing.T) {
+	values, err := SweepValues(4000, 7000, 500)
+	if err != nil || len(values) != 7 || values[0] != 4000 || values[6] != 7000 {
+		t.Errorf("SweepValues(4000, 7000, 500) = %v (%v), want 4000..7000", values, err)
+	}
+	if values, _ := SweepValues(2, 3, 0.1); len(values) != 11 {
+		t.Errorf("0.1 steps from 2 to 3 = %d values, want 11", len(values))
+	}
+	for _, r := range [][3]float64{{1, 2, 0}, {2, 1, 1}, {0, 100, 1}} {
+		if _, err := SweepValues(r[0], r[1], r[2]); err == nil {
+			t.Errorf("SweepValues(%v) should fail", r)
+		}
+	}
+}
+
+// TestRunSweep verifies spending more lowers the outcome at every point and
+// the saved settings are left alone
+func TestRunSweep(t *testing.T) {
+	settings := models.DefaultWhatIfSettings()
+	settings.PortfolioValue = 1000000
+	settings.MonthlyHealthcare = 0
+	settings.MonthlyLivingExpenses = 5000
+	calc := NewCalculator(settings)
+
+	result, err := calc.RunSweep("monthly_living_expenses", []float64{3000, 5000, 8000}, 300)
+	if err != nil {
+		t.Fatalf("RunSweep: %v", err)
+	}
+	if result.Current != 5000 || result.Runs != 300 || len(result.Points) != 3 {
+		t.Fatalf("result = %+v, want 3 points at 300 runs around 5000", result)
+	}
+	for i := 1; i < len(result.Points); i++ {
+		prev, p := result.Points[i-1], result.Points[i]
+		if p.SuccessRate > prev.SuccessRate || p.FinalBalance > prev.FinalBalance {
+			t.Errorf("at %.0f success %.1f%%, balance %.0f; at %.0f success %.1f%%, balance %.0f",
+				prev.Value, prev.SuccessRate, prev.FinalBalance, p.Value, p.SuccessRate, p.FinalBalance)
+		}
+	}
+	if first, last := result.Points[0], result.Points[2]; first.SuccessRate <= last.SuccessRate {
+		t.Errorf("success at 3000 = %.1f%%, at 8000 = %.1f%%; want lower spending to do better", first.SuccessRate, last.SuccessRate)
+	}
+	if settings.MonthlyLivingExpenses != 5000 {
+		t.Errorf("settings expenses = %.0f, want 5000 untouched", settings.MonthlyLivingExpenses)
+	}
+
+	if _, err := calc.RunSweep("pets", []float64{1}, 100); err == nil {
+		t.Error("unknown parameter should fail")
+	}
+	if _, err := calc.RunSweep("inflation_rate", make([]float64, MaxSweepPoints+1), 100); err == nil {
+		t.Error("too many points should fail")
+	}
+}
@@ -0,0 +1,104 @@
+package retirement
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"budget2/internal/models"
+)
+
+// federalBrackets are the 2025 single-filer federal income tax brackets:
+// the top of each bracket's taxable income and its rate. Single-filer to
+// match irmaaTiers.
+var federalBrackets = []struct {
+	Upto float64
+	Rate float64
+}{
+	{11925, 10},
+	{48475, 12},
+	{103350, 22},
+	{197300, 24},
+	{250525, 32},
+	{626350, 35},
+	{math.Inf(1), 37},
+}
+
+// StandardDeduction is the 2025 single-filer standard deduction, including
+// the additional amount for filers 65 and older (everyone taking RMDs)
+const StandardDeduction = 15750 + 2000
+
+// FederalTax estimates federal income tax on gross income in a year whose
+// brackets and standard deduction have grown by inflation (1.0 = 2025),
+// returning the tax and the marginal rate the last dollar falls in
+func FederalTax(income, inflation float64) (tax, marginal float64) {
+	taxable := income - StandardDeduction*inflation
+	if taxable <= 0 {
+		return 0, 0
+	}
+	lower := 0.0
+	for _, b := range federalBrackets {
+		upper := b.Upto * inflation
+		tax += (math.Min(taxable, upper) - lower) * b.Rate / 100
+		marginal = b.Rate
+		if taxable <= upper {
+			break
+		}
+		lower = upper
+	}
+	return tax, marginal
+}
+
+// rmdWithholding fills in the federal tax a year's RMD brings and how to
+// pay it: the whole percent of the RMD to have withheld (W-4R accepts
+// whole percents), or four equal estimated payments. Other income is
+// assumed to cover its own tax.
+func rmdWithholding(p *models.RMDProjection, inflation float64) {
+	total, marginal := FederalTax(p.TaxableIncome, inflation)
+	without, _ := FederalTax(p.TaxableIncome-p.TaxableRMD, inflation)
+
+	p.FederalTax = total
+	p.MarginalRate = marginal
+	p.RMDTax = total - without
+	if p.RMDAmount > 0 {
+		p.WithholdingPercent = math.Ceil(p.RMDTax / p.RMDAmount * 100)
+	}
+	p.QuarterlyEstimate = p.RMDTax / 4
+}
+
+// withholdingHeader is the first row of the withholding schedule
+var withholdingHeader = []string{
+	"Tax Year", "Age", "RMD", "QCD", "Est. MAGI", "Federal Tax", "Tax on RMD", "Marginal Rate",
+	"Withhold % of RMD", "Q1 (Apr 15)", "Q2 (Jun 15)", "Q3 (Sep 15)", "Q4 (Jan 15)",
+}
+
+// WriteWithholdingCSV writes each RMD year's suggested withholding and
+// quarterly estimated payments as CSV, numbering tax years from startYear
+func WriteWithholdingCSV(w io.Writer, rmd *models.RMDAnalysis, startYear int) error {
+	writer := csv.NewWriter(w)
+	writer.Write(withholdingHeader)
+	for _, p := range rmd.Projections {
+		quarter := dollars(p.QuarterlyEstimate)
+		writer.Write([]string{
+			strconv.Itoa(startYear + p.Year),
+			strconv.Itoa(p.Age),
+			dollars(p.RMDAmount),
+			dollars(p.QCD),
+			dollars(p.TaxableIncome),
+			dollars(p.FederalTax),
+			dollars(p.RMDTax),
+			fmt.Sprintf("%.0f%%", p.MarginalRate),
+			fmt.Sprintf("%.0f%%", p.WithholdingPercent),
+			quarter, quarter, quarter, quarter,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// dollars formats an amount to whole dollars for the schedule
+func dollars(v float64) string {
+	return strconv.FormatFloat(math.Round(v), 'f', 0, 64)
+}
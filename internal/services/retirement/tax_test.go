@@ -0,0 +1,83 @@
+package retirement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"testing"
+
+	"budget2/internal/models"
+)
+
+// TestFederalTax verifies tax is built up bracket by bracket after the
+// standard deduction, with brackets scaling by inflation
+func TestFederalTax(t *testing.T) {
+	tests := []struct {
+		income, inflation float64
+		tax, marginal     float64
+	}{
+		{10000, 1, 0, 0},
+		{StandardDeduction + 10000, 1, 1000, 10},
+		// 82,250 taxable: 1,192.50 + 4,386 + 7,430.50
+		{100000, 1, 13009, 22},
+		{200000, 2, 26018, 22},
+	}
+
+	for _, tt := range tests {
+		tax, marginal := FederalTax(tt.income, tt.inflation)
+		if math.Abs(tax-tt.tax) > 0.01 || marginal != tt.marginal {
+			t.Errorf("FederalTax(%.0f, %.1f) = %.2f at %.0f%%; want %.2f at %.0f%%", tt.income, tt.inflation, tax, marginal, tt.tax, tt.marginal)
+		}
+	}
+}
+
+// TestRMDWithholding verifies withholding covers only the tax the RMD adds
+// on top of other income, rounded up to a whole percent
+func TestRMDWithholding(t *testing.T) {
+	p := models.RMDProjection{RMDAmount: 60000, TaxableRMD: 60000, TaxableIncome: 100000}
+	rmdWithholding(&p, 1)
+
+	// 13,009 on all income less 2,431.50 on the 40,000 without the RMD
+	if math.Abs(p.RMDTax-10577.5) > 0.01 || p.FederalTax != 13009 {
+		t.Errorf("RMDTax = %.2f, FederalTax = %.2f; want 10577.50 of 13009", p.RMDTax, p.FederalTax)
+	}
+	if p.WithholdingPercent != 18 || math.Abs(p.QuarterlyEstimate-p.RMDTax/4) > 0.001 {
+		t.Errorf("withhold %.0f%% or %.2f a quarter; want 18%% or %.2f", p.WithholdingPercent, p.QuarterlyEstimate, p.RMDTax/4)
+	}
+
+	// A QCD covering the whole RMD leaves nothing to withhold
+	p = models.RMDProjection{RMDAmount: 20000, QCD: 20000, TaxableIncome: 100000}
+	rmdWithholding(&p, 1)
+	if p.RMDTax != 0 || p.WithholdingPercent != 0 {
+		t.Errorf("with full QCD RMDTax = %.2f, withhold %.0f%%; want none", p.RMDTax, p.WithholdingPercent)
+	}
+}
+
+// TestWithholdingSchedule verifies the CSV has a row per RMD year numbered
+// by tax year
+func TestWithholdingSchedule(t *testing.T) {
+	settings := models.DefaultWhatIfSettings()
+	settings.CurrentAge = 75
+	settings.PortfolioValue = 2000000
+	settings.TaxDeferredPercent = 100
+	settings.ProjectionYears = 5
+
+	rmd := NewCalculator(settings).CalculateRMDAnalysis()
+	var buf bytes.Buffer
+	if err := WriteWithholdingCSV(&buf, rmd, 2026); err != nil {
+		t.Fatalf("WriteWithholdingCSV failed: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != len(rmd.Projections)+1 || len(rmd.Projections) != 6 {
+		t.Fatalf("got %d rows for %d RMD years, want a header and 6", len(rows), len(rmd.Projections))
+	}
+	if rows[1][0] != "2026" || rows[1][1] != "75" || rows[6][0] != "2031" {
+		t.Errorf("first row %v, last row %v", rows[1], rows[6])
+	}
+	if rows[1][8] == "0%" || rows[1][9] != rows[1][12] {
+		t.Errorf("first year withholding %s, quarters %s and %s", rows[1][8], rows[1][9], rows[1][12])
+	}
+}
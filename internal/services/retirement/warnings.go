@@ -0,0 +1,53 @@
+package retirement
+
+import (
+	"fmt"
+
+	"budget2/internal/models"
+)
+
+// Bounds for sanity warnings, from long-run U.S. history
+const (
+	MaxRealisticReturn     = 12.0 // Nominal; stocks have averaged about 10%
+	MaxRealisticRealReturn = 7.0  // Above inflation; stocks have averaged about 7%
+	MaxRealisticInflation  = 8.0  // Only sustained in the late 1970s
+	MinPlanningAge         = 90   // Projections should reach at least this age
+)
+
+// SanityWarnings returns caveats for inputs that are allowed but outside
+// what history supports. They don't stop the analysis.
+func SanityWarnings(s *models.WhatIfSettings) []models.SanityWarning {
+	var warnings []models.SanityWarning
+	warn := func(field, format string, args ...interface{}) {
+		warnings = append(warnings, models.SanityWarning{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.InvestmentReturn > MaxRealisticReturn {
+		warn("investment_return", "A %.1f%% return is historically unrealistic; stocks have averaged about 10%% a year over the long run", s.InvestmentReturn)
+	} else if real := s.InvestmentReturn - s.InflationRate; real > MaxRealisticRealReturn {
+		warn("investment_return", "A return %.1f points above inflation is optimistic; stocks have averaged about 7%% above inflation", real)
+	}
+	if s.InvestmentReturn < 0 {
+		warn("investment_return", "A %.1f%% return assumes the portfolio loses money every year", s.InvestmentReturn)
+	}
+
+	if s.InflationRate <= 0 {
+		warn("inflation_rate", "%.1f%% inflation hasn't lasted in the U.S. since the 1930s; the long-run average is about 3%%", s.InflationRate)
+	} else if s.InflationRate > MaxRealisticInflation {
+		warn("inflation_rate", "Inflation above %.0f%% has only been sustained in the late 1970s", MaxRealisticInflation)
+	}
+
+	if s.SpendingDeclineRate > s.InflationRate && !s.HasEssentialFloor() {
+		warn("spending_decline_rate", "Spending declines faster than inflation, so living expenses shrink in dollars every year; consider an essential floor")
+	}
+
+	if s.MonthlyLivingExpenses <= 0 && len(s.ExpenseSources) == 0 {
+		warn("monthly_living_expenses", "No living expenses are entered, so the portfolio only grows")
+	}
+
+	if end := s.CurrentAge + s.ProjectionYears; end < MinPlanningAge {
+		warn("projection_years", "The projection ends at age %d; plan to at least %d in case you live longer than average", end, MinPlanningAge)
+	}
+
+	return warnings
+}
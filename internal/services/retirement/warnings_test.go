@@ -0,0 +1,68 @@
+package retirement
+
+import (
+	"strings"
+	"testing"
+
+	"budget2/internal/models"
+)
+
+// TestSanityWarnings verifies unrealistic inputs are flagged by field
+// without stopping the analysis
+func TestSanityWarnings(t *testing.T) {
+	realistic := func() *models.WhatIfSettings {
+		s := models.DefaultWhatIfSettings()
+		s.CurrentAge = 65
+		s.ProjectionYears = 30
+		s.InvestmentReturn = 7
+		s.InflationRate = 3
+		s.SpendingDeclineRate = 1
+		return s
+	}
+	if got := SanityWarnings(realistic()); len(got) != 0 {
+		t.Fatalf("realistic settings warned: %v", got)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(s *models.WhatIfSettings)
+		field  string
+		want   string
+	}{
+		{"high return", func(s *models.WhatIfSettings) { s.InvestmentReturn = 25 }, "investment_return", "historically unrealistic"},
+		{"high real return", func(s *models.WhatIfSettings) { s.InvestmentReturn = 11; s.InflationRate = 2 }, "investment_return", "9.0 points above inflation"},
+		{"negative return", func(s *models.WhatIfSettings) { s.InvestmentReturn = -2 }, "investment_return", "loses money"},
+		{"no inflation", func(s *models.WhatIfSettings) { s.InflationRate = 0; s.SpendingDeclineRate = 0 }, "inflation_rate", "since the 1930s"},
+		{"high inflation", func(s *models.WhatIfSettings) { s.InflationRate = 12; s.InvestmentReturn = 12 }, "inflation_rate", "late 1970s"},
+		{"decline above inflation", func(s *models.WhatIfSettings) { s.SpendingDeclineRate = 4 }, "spending_decline_rate", "essential floor"},
+		{"no expenses", func(s *models.WhatIfSettings) { s.MonthlyLivingExpenses = 0; s.ExpenseSources = nil }, "monthly_living_expenses", "only grows"},
+		{"short projection", func(s *models.WhatIfSettings) { s.ProjectionYears = 15 }, "projection_years", "ends at age 80"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := realistic()
+			tt.modify(s)
+			got := SanityWarnings(s)
+			if len(got) != 1 || got[0].Field != tt.field || !strings.Contains(got[0].Message, tt.want) {
+				t.Errorf("warnings = %v, want one on %s containing %q", got, tt.field, tt.want)
+			}
+		})
+	}
+
+	// A floor keeps essentials from shrinking, so a steep decline is fine
+	s := realistic()
+	s.SpendingDeclineRate = 4
+	s.EssentialFloor = 2000
+	if got := SanityWarnings(s); len(got) != 0 {
+		t.Errorf("decline with a floor warned: %v", got)
+	}
+
+	// Warnings come back with the analysis
+	s = realistic()
+	s.InvestmentReturn = 25
+	s.InflationRate = 0
+	s.SpendingDeclineRate = 0
+	if got := NewCalculator(s).RunFullAnalysis().Warnings; len(got) != 2 {
+		t.Errorf("analysis warnings = %v, want return and inflation", got)
+	}
+}
@@ -0,0 +1,233 @@
+package routestats
+
+import (
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"budget2/internal/models"
+)
+
+// request is one recorded request
+type request struct {
+	route    string // Method and pattern
+	status   int
+	duration time.Duration
+	at       time.Time
+}
+
+// Recorder keeps the latest requests in a fixed-size ring buffer, so route
+// analytics cost a bounded amount of memory and reset on restart
+type Recorder struct {
+	mu   sync.Mutex
+	buf  []request
+	next int
+	full bool
+}
+
+// NewRecorder creates a recorder holding the latest size requests
+func NewRecorder(size int) *Recorder {
+	return &Recorder{buf: make([]request, size)}
+}
+
+// Middleware records each request's route pattern, status and latency.
+// Static files and requests matching no route are left out.
+func (rec *Recorder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/static/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		rctx := chi.RouteContext(r.Context())
+		if rctx == nil || rctx.RoutePattern() == "" {
+			return
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		rec.Record(r.Method+" "+rctx.RoutePattern(), status, time.Since(start), start)
+	})
+}
+
+// Record adds a request, dropping the oldest once the buffer is full
+func (rec *Recorder) Record(route string, status int, duration time.Duration, at time.Time) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if len(rec.buf) == 0 {
+		return
+	}
+	rec.buf[rec.next] = request{route: route, status: status, duration: duration, at: at}
+	rec.next = (rec.next + 1) % len(rec.buf)
+	if rec.next == 0 {
+		rec.full = true
+	}
+}
+
+// requests returns the recorded requests, oldest first
+func (rec *Recorder) requests() []request {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if !rec.full {
+		return slices.Clone(rec.buf[:rec.next])
+	}
+	return append(slices.Clone(rec.buf[rec.next:]), rec.buf[:rec.next]...)
+}
+
+// Stats summarizes the recorded requests per route, busiest first. Routes
+// in registered that have no recorded requests are listed as unused.
+func (rec *Recorder) Stats(registered []string) models.RouteStats {
+	reqs := rec.requests()
+	stats := models.RouteStats{
+		Recorded: len(reqs),
+		Capacity: len(rec.buf),
+		Routes:   []models.RouteStat{},
+		Unused:   []string{},
+	}
+	if len(reqs) > 0 {
+		stats.Since = reqs[0].at
+	}
+
+	byRoute := make(map[string][]request)
+	for _, req := range reqs {
+		byRoute[req.route] = append(byRoute[req.route], req)
+	}
+	for route, list := range byRoute {
+		stat := models.RouteStat{Route: route, Requests: len(list)}
+		durations := make([]time.Duration, len(list))
+		for i, req := range list {
+			durations[i] = req.duration
+			if req.status >= 500 {
+				stat.Errors++
+			}
+			if req.at.After(stat.LastSeen) {
+				stat.LastSeen = req.at
+			}
+		}
+		slices.Sort(durations)
+		stat.P50Ms = percentile(durations, 50)
+		stat.P95Ms = percentile(durations, 95)
+		stat.MaxMs = milliseconds(durations[len(durations)-1])
+		stats.Routes = append(stats.Routes, stat)
+	}
+	sort.Slice(stats.Routes, func(i, j int) bool {
+		if stats.Routes[i].Requests != stats.Routes[j].Requests {
+			return stats.Routes[i].Requests > stats.Routes[j].Requests
+		}
+		return stats.Routes[i].Route < stats.Routes[j].Route
+	})
+
+	for _, route := range registered {
+		if _, ok := byRoute[route]; !ok {
+			stats.Unused = append(stats.Unused, route)
+		}
+	}
+	sort.Strings(stats.Unused)
+	return stats
+}
+
+// Hourly counts the requests to route, or to every route when it is empty,
+// in each of the last hours hours up to now, oldest first
+func (rec *Recorder) Hourly(route string, hours int, now time.Time) []models.RouteHour {
+	end := now.Truncate(time.Hour)
+	start := end.Add(-time.Duration(hours-1) * time.Hour)
+
+	durations := make([][]time.Duration, hours)
+	for _, req := range rec.requests() {
+		if route != "" && req.route != route {
+			continue
+		}
+		i := int(req.at.Truncate(time.Hour).Sub(start) / time.Hour)
+		if i >= 0 && i < hours {
+			durations[i] = append(durations[i], req.duration)
+		}
+	}
+
+	result := make([]models.RouteHour, hours)
+	for i, list := range durations {
+		slices.Sort(list)
+		result[i] = models.RouteHour{
+			Hour:     start.Add(time.Duration(i) * time.Hour),
+			Requests: len(list),
+			P95Ms:    percentile(list, 95),
+		}
+	}
+	return result
+}
+
+// percentile returns the p-th percentile of sorted durations in
+// milliseconds, by the nearest-rank method
+func percentile(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return milliseconds(sorted[max(rank, 1)-1])
+}
+
+// milliseconds converts a duration to milliseconds, to a tenth
+func milliseconds(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Millisecond)*10) / 10
+}
+
+// HourlyChart plots requests per hour as bars with the 95th percentile
+// latency as a line on a second axis
+func HourlyChart(hours []models.RouteHour) map[string]interface{} {
+	x := make([]string, len(hours))
+	requests := make([]int, len(hours))
+	p95 := make([]interface{}, len(hours))
+	for i, h := range hours {
+		x[i] = h.Hour.Format("2006-01-02 15:04")
+		requests[i] = h.Requests
+		if h.Requests > 0 {
+			p95[i] = h.P95Ms
+		}
+	}
+
+	return map[string]interface{}{
+		"data": []map[string]interface{}{
+			{
+				"type":   "bar",
+				"name":   "Requests",
+				"x":      x,
+				"y":      requests,
+				"marker": map[string]interface{}{"color": "#6366f1"},
+			},
+			{
+				"type":        "scatter",
+				"mode":        "lines+markers",
+				"name":        "p95 Latency",
+				"x":           x,
+				"y":           p95,
+				"yaxis":       "y2",
+				"connectgaps": false,
+				"line":        map[string]interface{}{"color": "#f59e0b", "width": 2},
+			},
+		},
+		"layout": map[string]interface{}{
+			"yaxis": map[string]interface{}{
+				"title": "Requests",
+			},
+			"yaxis2": map[string]interface{}{
+				"title":      "p95 (ms)",
+				"overlaying": "y",
+				"side":       "right",
+				"rangemode":  "tozero",
+			},
+		},
+	}
+}
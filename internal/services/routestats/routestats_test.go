@@ -0,0 +1,94 @@
+package routestats
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestStats(t *testing.T) {
+	rec := NewRecorder(4)
+	now := time.Date(2026, 3, 10, 12, 30, 0, 0, time.UTC)
+
+	// Five requests into a buffer of four drops the first
+	rec.Record("GET /old", 200, time.Millisecond, now.Add(-5*time.Minute))
+	rec.Record("GET /dashboard", 200, 10*time.Millisecond, now.Add(-4*time.Minute))
+	rec.Record("GET /dashboard", 500, 30*time.Millisecond, now.Add(-3*time.Minute))
+	rec.Record("GET /dashboard", 200, 20*time.Millisecond, now.Add(-2*time.Minute))
+	rec.Record("POST /rules", 303, 5*time.Millisecond, now.Add(-time.Minute))
+
+	stats := rec.Stats([]string{"GET /dashboard", "POST /rules", "GET /old", "GET /budgets"})
+	if stats.Recorded != 4 || stats.Capacity != 4 {
+		t.Fatalf("Recorded/Capacity = %d/%d, want 4/4", stats.Recorded, stats.Capacity)
+	}
+	if !stats.Since.Equal(now.Add(-4 * time.Minute)) {
+		t.Errorf("Since = %v, want the oldest kept request", stats.Since)
+	}
+	if len(stats.Routes) != 2 {
+		t.Fatalf("got %d routes, want 2: %+v", len(stats.Routes), stats.Routes)
+	}
+
+	dash := stats.Routes[0]
+	if dash.Route != "GET /dashboard" || dash.Requests != 3 || dash.Errors != 1 {
+		t.Errorf("busiest route = %+v, want GET /dashboard with 3 requests and 1 error", dash)
+	}
+	if dash.P50Ms != 20 || dash.P95Ms != 30 || dash.MaxMs != 30 {
+		t.Errorf("latency = %v/%v/%v, want 20/30/30", dash.P50Ms, dash.P95Ms, dash.MaxMs)
+	}
+	if !dash.LastSeen.Equal(now.Add(-2 * time.Minute)) {
+		t.Errorf("LastSeen = %v", dash.LastSeen)
+	}
+	if want := []string{"GET /budgets", "GET /old"}; !slices.Equal(stats.Unused, want) {
+		t.Errorf("Unused = %v, want %v", stats.Unused, want)
+	}
+}
+
+func TestHourly(t *testing.T) {
+	rec := NewRecorder(10)
+	now := time.Date(2026, 3, 10, 12, 30, 0, 0, time.UTC)
+	rec.Record("GET /dashboard", 200, 10*time.Millisecond, now.Add(-2*time.Hour))
+	rec.Record("GET /dashboard", 200, 40*time.Millisecond, now)
+	rec.Record("GET /dashboard", 200, 20*time.Millisecond, now.Add(-time.Minute))
+	rec.Record("GET /insights", 200, 90*time.Millisecond, now)
+	rec.Record("GET /dashboard", 200, time.Millisecond, now.Add(-5*time.Hour)) // Before the window
+
+	hours := rec.Hourly("GET /dashboard", 3, now)
+	if len(hours) != 3 {
+		t.Fatalf("got %d hours, want 3", len(hours))
+	}
+	if !hours[0].Hour.Equal(time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("first hour = %v, want 10:00", hours[0].Hour)
+	}
+	counts := []int{hours[0].Requests, hours[1].Requests, hours[2].Requests}
+	if !slices.Equal(counts, []int{1, 0, 2}) {
+		t.Errorf("counts = %v, want [1 0 2]", counts)
+	}
+	if hours[2].P95Ms != 40 {
+		t.Errorf("p95 = %v, want 40", hours[2].P95Ms)
+	}
+
+	if all := rec.Hourly("", 3, now); all[2].Requests != 3 {
+		t.Errorf("all routes in the last hour = %d, want 3", all[2].Requests)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	rec := NewRecorder(10)
+	r := chi.NewRouter()
+	r.Use(rec.Middleware)
+	r.Get("/accounts/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	r.Get("/static/*", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, path := range []string{"/accounts/1", "/accounts/2", "/static/app.js", "/missing"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	stats := rec.Stats(nil)
+	if len(stats.Routes) != 1 || stats.Routes[0].Route != "GET /accounts/{id}" || stats.Routes[0].Requests != 2 {
+		t.Errorf("routes = %+v, want only GET /accounts/{id} twice", stats.Routes)
+	}
+}
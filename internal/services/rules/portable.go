@@ -0,0 +1,179 @@
+package rules
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"budget2/internal/models"
+)
+
+// csvHeader is the column order rules are exported in
+var csvHeader = []string{"Match", "Pattern", "Min Amount", "Max Amount", "Category"}
+
+// WriteCSV writes rules as CSV in the order they are tried, for sharing
+// with another install or editing in a spreadsheet
+func WriteCSV(w io.Writer, list []models.CategoryRule) error {
+	writer := csv.NewWriter(w)
+	writer.Write(csvHeader)
+	for _, r := range list {
+		writer.Write([]string{r.Match, r.Pattern, formatAmount(r.MinAmount), formatAmount(r.MaxAmount), r.Category})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatAmount leaves an unset bound blank
+func formatAmount(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Parse reads rules exported as JSON or CSV, telling them apart by whether
+// the file starts with "[". CSV columns are found by name, so only Pattern
+// and Category are required. Every rule must validate; the error names the
+// first that doesn't.
+func Parse(r io.Reader) ([]models.CategoryRule, error) {
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(3); err == nil && string(bom) == "\ufeff" {
+		br.Discard(3)
+	}
+
+	var list []models.CategoryRule
+	var err error
+	if isJSON(br) {
+		err = json.NewDecoder(br).Decode(&list)
+	} else {
+		list, err = parseCSV(br)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, rule := range list {
+		if list[i], err = Validate(rule); err != nil {
+			return nil, fmt.Errorf("rule %d (%q): %w", i+1, rule.Pattern, err)
+		}
+	}
+	return list, nil
+}
+
+// isJSON reports whether the first non-blank byte starts a JSON array
+func isJSON(br *bufio.Reader) bool {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return false
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			br.UnreadByte()
+			return b == '['
+		}
+	}
+}
+
+// parseCSV reads rules from CSV with a header row in any column order
+func parseCSV(r io.Reader) ([]models.CategoryRule, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header: %w", err)
+	}
+	colIndex := make(map[string]int)
+	for i, col := range header {
+		colIndex[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+	for _, key := range []string{"pattern", "category"} {
+		if _, ok := colIndex[key]; !ok {
+			return nil, fmt.Errorf("not a category rules file: missing %q column", key)
+		}
+	}
+
+	field := func(record []string, key string) string {
+		if idx, ok := colIndex[key]; ok && idx < len(record) {
+			return strings.TrimSpace(record[idx])
+		}
+		return ""
+	}
+
+	var list []models.CategoryRule
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rule := models.CategoryRule{
+			Match:    strings.ToLower(field(record, "match")),
+			Pattern:  field(record, "pattern"),
+			Category: field(record, "category"),
+		}
+		for _, amount := range []struct {
+			name  string
+			value *float64
+		}{{"min amount", &rule.MinAmount}, {"max amount", &rule.MaxAmount}} {
+			s := strings.TrimPrefix(field(record, amount.name), "$")
+			if s == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s must be a number", line, amount.name)
+			}
+			*amount.value = v
+		}
+		list = append(list, rule)
+	}
+	return list, nil
+}
+
+// ruleKey identifies what a rule does, ignoring its ID and case, so the
+// same rule imported twice is recognized
+func ruleKey(r models.CategoryRule) string {
+	return fmt.Sprintf("%s|%s|%g|%g|%s", r.Match, strings.ToLower(r.Pattern), r.MinAmount, r.MaxAmount, strings.ToLower(r.Category))
+}
+
+// Import adds rules after the existing ones, skipping any the manager
+// already has, or replaces every rule when replace is set. The rules should
+// come from Parse, given fresh IDs. It returns how many rules were added.
+func (m *Manager) Import(list []models.CategoryRule, replace bool) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var existing []models.CategoryRule
+	if !replace {
+		var err error
+		if existing, err = m.loadInternal(); err != nil {
+			return 0, err
+		}
+	}
+
+	seen := make(map[string]bool, len(existing))
+	for _, r := range existing {
+		seen[ruleKey(r)] = true
+	}
+	added := 0
+	for _, r := range list {
+		if key := ruleKey(r); !seen[key] {
+			seen[key] = true
+			existing = append(existing, r)
+			added++
+		}
+	}
+	if existing == nil {
+		existing = []models.CategoryRule{}
+	}
+	return added, m.saveInternal(existing)
+}
@@ -0,0 +1,300 @@
+package rules
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// rulesFile holds the categorization rules
+var rulesFile = storage.SettingsFile("category_rules.json", func() interface{} { return &[]models.CategoryRule{} })
+
+// Manager persists user category rules and applies them while data loads
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing rules in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, rulesFile),
+		store: store,
+	}
+}
+
+// List returns the rules in the order they are tried
+func (m *Manager) List() ([]models.CategoryRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Add appends a rule, so it is tried after the existing ones
+func (m *Manager) Add(rule models.CategoryRule) ([]models.CategoryRule, error) {
+	rule, err := Validate(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+	list = append(list, rule)
+	return list, m.saveInternal(list)
+}
+
+// Update replaces the conditions and category of the rule with rule.ID,
+// keeping its place in the order
+func (m *Manager) Update(rule models.CategoryRule) ([]models.CategoryRule, error) {
+	rule, err := Validate(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+	i := indexOf(list, rule.ID)
+	if i < 0 {
+		return nil, fmt.Errorf("rule %s not found", rule.ID)
+	}
+	rule.CreatedAt = list[i].CreatedAt
+	list[i] = rule
+	return list, m.saveInternal(list)
+}
+
+// Remove deletes a rule by ID
+func (m *Manager) Remove(id string) ([]models.CategoryRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.CategoryRule, 0, len(list))
+	for _, r := range list {
+		if r.ID != id {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, m.saveInternal(filtered)
+}
+
+// Move swaps a rule with its neighbor, earlier when up is set, changing
+// which rule wins when several match
+func (m *Manager) Move(id string, up bool) ([]models.CategoryRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+	i := indexOf(list, id)
+	if i < 0 {
+		return nil, fmt.Errorf("rule %s not found", id)
+	}
+	j := i + 1
+	if up {
+		j = i - 1
+	}
+	if j < 0 || j >= len(list) {
+		return list, nil
+	}
+	list[i], list[j] = list[j], list[i]
+	return list, m.saveInternal(list)
+}
+
+// Version changes whenever the rules do, so the loader reloads data
+func (m *Manager) Version() string {
+	info, err := m.store.Stat(m.path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("rules|%d|%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// Categorize applies the rules to freshly loaded transactions. Transactions
+// are returned unchanged if the rules can't be read.
+func (m *Manager) Categorize(transactions []models.Transaction) []models.Transaction {
+	list, err := m.List()
+	if err != nil {
+		log.Printf("Warning: failed to load category rules: %v", err)
+		return transactions
+	}
+	if n := Apply(list, transactions); n > 0 {
+		log.Printf("Category rules recategorized %d transactions", n)
+	}
+	return transactions
+}
+
+// loadInternal reads the rules without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() ([]models.CategoryRule, error) {
+	var list []models.CategoryRule
+	if err := m.store.ReadJSON(m.path, &list); err != nil {
+		if os.IsNotExist(err) {
+			return []models.CategoryRule{}, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+// saveInternal writes the rules (caller must hold lock)
+func (m *Manager) saveInternal(list []models.CategoryRule) error {
+	return m.store.WriteJSON(m.path, list)
+}
+
+func indexOf(list []models.CategoryRule, id string) int {
+	for i, r := range list {
+		if r.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Validate normalizes a rule and checks it can match something: a category,
+// a known match type, a pattern that compiles, and at least one condition
+func Validate(rule models.CategoryRule) (models.CategoryRule, error) {
+	rule.Category = strings.TrimSpace(rule.Category)
+	rule.Pattern = strings.TrimSpace(rule.Pattern)
+	if rule.Match == "" {
+		rule.Match = models.RuleContains
+	}
+
+	if rule.Category == "" {
+		return rule, fmt.Errorf("category is required")
+	}
+	switch rule.Match {
+	case models.RuleContains:
+	case models.RuleRegex:
+		if _, err := regexp.Compile("(?i)" + rule.Pattern); err != nil {
+			return rule, fmt.Errorf("invalid regular expression: %v", err)
+		}
+	default:
+		return rule, fmt.Errorf("unknown match type %q", rule.Match)
+	}
+	if rule.MinAmount < 0 || rule.MaxAmount < 0 {
+		return rule, fmt.Errorf("amounts must not be negative")
+	}
+	if rule.MaxAmount > 0 && rule.MinAmount > rule.MaxAmount {
+		return rule, fmt.Errorf("minimum amount %.2f is above maximum %.2f", rule.MinAmount, rule.MaxAmount)
+	}
+	if rule.Pattern == "" && rule.MinAmount == 0 && rule.MaxAmount == 0 {
+		return rule, fmt.Errorf("a pattern or amount range is required")
+	}
+	return rule, nil
+}
+
+// matcher is a rule ready to test transactions against
+type matcher struct {
+	rule    models.CategoryRule
+	pattern string // Lowercase, for contains rules
+	re      *regexp.Regexp
+}
+
+// compile prepares rules for matching, skipping any that no longer validate
+// (e.g. a hand-edited settings file)
+func compile(list []models.CategoryRule) []matcher {
+	matchers := make([]matcher, 0, len(list))
+	for _, r := range list {
+		r, err := Validate(r)
+		if err != nil {
+			log.Printf("Warning: skipping category rule %s: %v", r.ID, err)
+			continue
+		}
+		m := matcher{rule: r, pattern: strings.ToLower(r.Pattern)}
+		if r.Match == models.RuleRegex {
+			m.re = regexp.MustCompile("(?i)" + r.Pattern)
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers
+}
+
+// matches reports whether t meets every condition of the rule
+func (m matcher) matches(t *models.Transaction) bool {
+	amount := math.Abs(t.Amount)
+	if m.rule.MinAmount > 0 && amount < m.rule.MinAmount {
+		return false
+	}
+	if m.rule.MaxAmount > 0 && amount > m.rule.MaxAmount {
+		return false
+	}
+	switch {
+	case m.re != nil:
+		return m.re.MatchString(t.Description)
+	case m.pattern != "":
+		return strings.Contains(strings.ToLower(t.Description), m.pattern)
+	}
+	return true
+}
+
+// first returns the index of the first matcher matching t, or -1
+func first(matchers []matcher, t *models.Transaction) int {
+	for i, m := range matchers {
+		if m.matches(t) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Apply sets the category of each transaction from the first rule it
+// matches, returning how many categories changed
+func Apply(list []models.CategoryRule, transactions []models.Transaction) int {
+	matchers := compile(list)
+	if len(matchers) == 0 {
+		return 0
+	}
+
+	changed := 0
+	for i := range transactions {
+		if j := first(matchers, &transactions[i]); j >= 0 && transactions[i].Category != matchers[j].rule.Category {
+			transactions[i].Category = matchers[j].rule.Category
+			changed++
+		}
+	}
+	return changed
+}
+
+// Evaluate counts the transactions in ts each rule wins, so the rules page
+// can show which rules are doing anything
+func Evaluate(list []models.CategoryRule, ts *models.TransactionSet) []models.CategoryRuleStatus {
+	statuses := make([]models.CategoryRuleStatus, len(list))
+	index := make(map[string]int, len(list))
+	for i, r := range list {
+		statuses[i].CategoryRule = r
+		index[r.ID] = i
+	}
+
+	matchers := compile(list)
+	for i := range ts.Transactions {
+		if j := first(matchers, &ts.Transactions[i]); j >= 0 {
+			statuses[index[matchers[j].rule.ID]].Matches++
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,160 @@
+package rules
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func txn(desc string, amount float64, category string) models.Transaction {
+	d, _ := time.Parse("2006-01-02", "2024-03-01")
+	return models.Transaction{Date: d, Description: desc, Amount: amount, Category: category}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule models.CategoryRule
+		ok   bool
+	}{
+		{"contains", models.CategoryRule{Pattern: "starbucks", Category: "Coffee"}, true},
+		{"amount only", models.CategoryRule{MinAmount: 1000, Category: "Large Purchases"}, true},
+		{"missing category", models.CategoryRule{Pattern: "starbucks"}, false},
+		{"no conditions", models.CategoryRule{Category: "Coffee"}, false},
+		{"bad regex", models.CategoryRule{Match: models.RuleRegex, Pattern: "sq \\*(", Category: "Coffee"}, false},
+		{"unknown match", models.CategoryRule{Match: "glob", Pattern: "sq*", Category: "Coffee"}, false},
+		{"min above max", models.CategoryRule{MinAmount: 50, MaxAmount: 10, Category: "Coffee"}, false},
+		{"negative amount", models.CategoryRule{MinAmount: -5, Category: "Coffee"}, false},
+	}
+	for _, tt := range tests {
+		rule, err := Validate(tt.rule)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: Validate error = %v, want ok %v", tt.name, err, tt.ok)
+		}
+		if err == nil && rule.Match != models.RuleContains && tt.rule.Match == "" {
+			t.Errorf("%s: blank match should default to contains, got %q", tt.name, rule.Match)
+		}
+	}
+}
+
+func TestApplyFirstMatchWins(t *testing.T) {
+	list := []models.CategoryRule{
+		{ID: "1", Match: models.RuleRegex, Pattern: `^SQ \*BLUE`, Category: "Coffee"},
+		{ID: "2", Pattern: "costco", MinAmount: 200, Category: "Bulk Groceries"},
+		{ID: "3", Pattern: "costco", Category: "Groceries"},
+		{ID: "4", Pattern: "amazon", MaxAmount: 10, Category: "Digital"},
+	}
+	transactions := []models.Transaction{
+		txn("SQ *BLUE BOTTLE OAKLAND", -6.5, "Restaurants"),
+		txn("COSTCO WHSE #123", -250, "Shopping"),
+		txn("Costco Gas", -45, "Shopping"),
+		txn("AMAZON DIGITAL", 4.99, "Shopping"),
+		txn("AMAZON MKTPL", -60, "Shopping"),
+		txn("sq *blue bottle", -5, "Coffee"),
+	}
+
+	if n := Apply(list, transactions); n != 4 {
+		t.Errorf("Apply changed %d categories, want 4", n)
+	}
+	want := []string{"Coffee", "Bulk Groceries", "Groceries", "Digital", "Shopping", "Coffee"}
+	for i, w := range want {
+		if transactions[i].Category != w {
+			t.Errorf("%s: category = %q, want %q", transactions[i].Description, transactions[i].Category, w)
+		}
+	}
+
+	statuses := Evaluate(list, models.NewTransactionSet(transactions))
+	counts := []int{2, 1, 1, 1}
+	for i, c := range counts {
+		if statuses[i].Matches != c {
+			t.Errorf("rule %s matched %d, want %d", statuses[i].ID, statuses[i].Matches, c)
+		}
+	}
+}
+
+func TestManagerOrderAndVersion(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	if v := manager.Version(); v != "" {
+		t.Errorf("version without rules = %q, want empty", v)
+	}
+
+	manager.Add(models.CategoryRule{ID: "a", Pattern: "costco", Category: "Groceries"})
+	list, err := manager.Add(models.CategoryRule{ID: "b", Pattern: "costco", MinAmount: 200, Category: "Bulk"})
+	if err != nil || len(list) != 2 {
+		t.Fatalf("Add = %v, %v", list, err)
+	}
+	v1 := manager.Version()
+
+	list, _ = manager.Move("b", true)
+	if list[0].ID != "b" {
+		t.Errorf("moving b up should put it first, got %+v", list)
+	}
+	if list, _ = manager.Move("b", true); list[0].ID != "b" {
+		t.Error("moving the first rule up should leave it first")
+	}
+
+	if _, err := manager.Update(models.CategoryRule{ID: "a", Pattern: "costco", Category: ""}); err == nil {
+		t.Error("Update should validate the rule")
+	}
+	list, _ = manager.Update(models.CategoryRule{ID: "a", Pattern: "costco whse", Category: "Warehouse"})
+	if list[1].Category != "Warehouse" || list[1].Pattern != "costco whse" {
+		t.Errorf("Update should replace the rule in place, got %+v", list)
+	}
+
+	list, _ = manager.Remove("b")
+	if len(list) != 1 || list[0].ID != "a" {
+		t.Errorf("expected only rule a after removing b, got %+v", list)
+	}
+	if manager.Version() == v1 {
+		t.Error("version should change when rules change")
+	}
+}
+
+func TestParseAndImport(t *testing.T) {
+	rules, err := Parse(strings.NewReader("\ufeffCategory,Pattern,Match,Min Amount\nBulk,costco,,$200\nCoffee,^SQ \\*BLUE,Regex,\n"))
+	if err != nil || len(rules) != 2 {
+		t.Fatalf("Parse CSV = %+v, %v", rules, err)
+	}
+	if rules[0].Match != models.RuleContains || rules[0].MinAmount != 200 || rules[1].Match != models.RuleRegex {
+		t.Errorf("parsed rules = %+v, want contains from $200 then regex", rules)
+	}
+
+	var buf bytes.Buffer
+	WriteCSV(&buf, rules)
+	roundTrip, err := Parse(&buf)
+	if err != nil || len(roundTrip) != 2 || roundTrip[1].Pattern != `^SQ \*BLUE` {
+		t.Errorf("CSV round trip = %+v, %v", roundTrip, err)
+	}
+
+	if _, err := Parse(strings.NewReader(`[{"pattern":"x","category":"A"},{"match":"regex","pattern":"(","category":"B"}]`)); err == nil || !strings.Contains(err.Error(), "rule 2") {
+		t.Errorf("Parse should name the invalid rule, got %v", err)
+	}
+	if _, err := Parse(strings.NewReader("Date,Description,Amount\n")); err == nil {
+		t.Error("Parse should reject a CSV without rule columns")
+	}
+
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+	manager.Add(models.CategoryRule{ID: "a", Pattern: "COSTCO", MinAmount: 200, Category: "bulk"})
+
+	if added, err := manager.Import(rules, false); err != nil || added != 1 {
+		t.Errorf("Import = %d, %v; want 1 with the Costco rule already kept", added, err)
+	}
+	if list, _ := manager.List(); len(list) != 2 || list[0].ID != "a" {
+		t.Errorf("Import should append after existing rules, got %+v", list)
+	}
+	if added, _ := manager.Import(rules[1:], true); added != 1 {
+		t.Errorf("replacing Import added %d, want 1", added)
+	}
+	if list, _ := manager.List(); len(list) != 1 || list[0].Category != "Coffee" {
+		t.Errorf("replacing Import should leave only its rules, got %+v", list)
+	}
+}
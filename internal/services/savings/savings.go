@@ -0,0 +1,266 @@
+package savings
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// planFile holds the savings plan
+var planFile = storage.SettingsFile("savings_plan.json", func() interface{} { return &models.SavingsPlan{} })
+
+// SafetyMargin is the share of the monthly surplus suggested for automatic
+// transfers; the rest is left as a buffer for irregular spending
+const SafetyMargin = 0.8
+
+// RoundTo rounds suggested transfers down to a whole number of dollars
+const RoundTo = 5.0
+
+// MaxProjectionMonths bounds the emergency fund and FIRE projections
+const MaxProjectionMonths = 50 * 12
+
+// Spending windows: recent spending catches a rising burn rate, the yearly
+// average catches seasonal spikes; the higher of the two is used
+const (
+	recentDays = 90
+	yearDays   = 365
+)
+
+// Manager persists the emergency fund and FIRE settings
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing the savings plan in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, planFile),
+		store: store,
+	}
+}
+
+// Get returns the saved plan, or the default plan if none is saved
+func (m *Manager) Get() (models.SavingsPlan, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Save validates and saves the plan
+func (m *Manager) Save(plan models.SavingsPlan) (models.SavingsPlan, error) {
+	if plan.EmergencyFund < 0 {
+		return plan, fmt.Errorf("emergency fund balance can't be negative")
+	}
+	if plan.TargetMonths < 1 || plan.TargetMonths > 24 {
+		return plan, fmt.Errorf("target must be between 1 and 24 months")
+	}
+	if plan.WithdrawalRate <= 0 || plan.WithdrawalRate > 10 {
+		return plan, fmt.Errorf("withdrawal rate must be between 0 and 10%%")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.store.WriteJSON(m.path, plan); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// loadInternal reads the plan without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() (models.SavingsPlan, error) {
+	plan := models.DefaultSavingsPlan()
+	if err := m.store.ReadJSON(m.path, &plan); err != nil {
+		if os.IsNotExist(err) {
+			return models.DefaultSavingsPlan(), nil
+		}
+		return plan, err
+	}
+	return plan, nil
+}
+
+// PaydaysPerMonth returns the average number of paydays per month for an
+// income frequency, or 0 if the frequency isn't regular
+func PaydaysPerMonth(frequency string) float64 {
+	switch frequency {
+	case "weekly":
+		return 52.0 / 12
+	case "biweekly":
+		return 26.0 / 12
+	case "monthly":
+		return 1
+	}
+	return 0
+}
+
+// MonthlySpending returns average monthly outflows over the 90 days and the
+// year ending at end, whichever is higher. Windows longer than the data are
+// shortened to the data so a short history isn't diluted.
+func MonthlySpending(ts *models.TransactionSet, end time.Time) float64 {
+	if ts.Len() == 0 {
+		return 0
+	}
+	first := ts.MinDate()
+
+	average := func(days int) float64 {
+		start := end.AddDate(0, 0, -days+1)
+		if first.After(start) {
+			start = first
+		}
+		span := end.Sub(start).Hours()/24 + 1
+		if span < 1 {
+			span = 1
+		}
+		total := ts.FilterByDateRange(start, end).FilterByType(models.Outflow).SumAbsAmount()
+		return total / span * 365.25 / 12
+	}
+
+	return math.Max(average(recentDays), average(yearDays))
+}
+
+// monthsToReach compounds balance monthly at annualReturn (percent), adding
+// contribution(month) at the end of each month, and returns the months until
+// it reaches target, or -1 if it doesn't within MaxProjectionMonths
+func monthsToReach(balance, target, annualReturn float64, contribution func(month int) float64) int {
+	rate := annualReturn / 100 / 12
+	for month := 0; month <= MaxProjectionMonths; month++ {
+		if balance >= target {
+			return month
+		}
+		balance = balance*(1+rate) + contribution(month)
+	}
+	return -1
+}
+
+// addMonths returns the date months after from, or the zero time for -1
+func addMonths(from time.Time, months int) time.Time {
+	if months < 0 {
+		return time.Time{}
+	}
+	return from.AddDate(0, months, 0)
+}
+
+// IncomeGrowthRate annualizes how regular income has changed through raises
+// and cuts: monthly regular income at each source's first amount against
+// its current one, compounded over the years between the first and latest
+// payments, counting at least a year so a recent raise isn't overstated.
+// It's a percent, and 0 when no regular income changed.
+func IncomeGrowthRate(patterns []models.IncomePattern) float64 {
+	var before, after float64
+	var first, last time.Time
+	changed := false
+	for _, p := range patterns {
+		paydays := PaydaysPerMonth(p.Frequency)
+		if !p.IsRegular || paydays == 0 {
+			continue
+		}
+		current := p.CurrentAmount
+		if current == 0 {
+			current = p.AvgAmount
+		}
+		start := current
+		if len(p.Changes) > 0 {
+			start = p.Changes[0].From
+			changed = true
+		}
+		before += start * paydays
+		after += current * paydays
+		if first.IsZero() || p.FirstDate.Before(first) {
+			first = p.FirstDate
+		}
+		if p.LastDate.After(last) {
+			last = p.LastDate
+		}
+	}
+	if !changed || before <= 0 || after <= 0 {
+		return 0
+	}
+
+	years := math.Max(last.Sub(first).Hours()/24/365.25, 1)
+	return math.Round((math.Pow(after/before, 1/years)-1)*1000) / 10
+}
+
+// Suggest picks a safe automatic transfer per payday from the regular income
+// patterns and monthly spending, and projects when the emergency fund fills
+// and when the portfolio reaches the FIRE number. Transfers fill the
+// emergency fund first, then go to the portfolio. A nonzero incomeGrowth
+// (percent a year) adds a FIRE date with transfers growing at that rate.
+func Suggest(patterns []models.IncomePattern, monthlySpending float64, plan models.SavingsPlan, portfolio, annualReturn, incomeGrowth float64, from time.Time) *models.SavingsSuggestion {
+	s := &models.SavingsSuggestion{
+		Plan:             plan,
+		MonthlySpending:  monthlySpending,
+		PortfolioValue:   portfolio,
+		IncomeGrowthRate: incomeGrowth,
+		GrowthFIREMonths: -1,
+	}
+
+	var largest float64
+	for _, p := range patterns {
+		paydays := PaydaysPerMonth(p.Frequency)
+		if !p.IsRegular || paydays == 0 || p.AvgAmount <= 0 {
+			continue
+		}
+		monthly := p.AvgAmount * paydays
+		s.MonthlyIncome += monthly
+		if monthly > largest {
+			largest = monthly
+			s.Paycheck = p.Description
+			s.Frequency = p.Frequency
+			s.PaydaysPerMonth = paydays
+		}
+	}
+
+	s.MonthlySurplus = s.MonthlyIncome - monthlySpending
+	if s.MonthlySurplus > 0 && s.PaydaysPerMonth > 0 {
+		perPayday := s.MonthlySurplus * SafetyMargin / s.PaydaysPerMonth
+		s.PerPayday = math.Floor(perPayday/RoundTo) * RoundTo
+		s.Monthly = s.PerPayday * s.PaydaysPerMonth
+	}
+
+	// The emergency fund is cash, so it doesn't compound
+	s.EmergencyTarget = monthlySpending * float64(plan.TargetMonths)
+	s.EmergencyMonths = monthsToReach(plan.EmergencyFund, s.EmergencyTarget, 0, func(int) float64 {
+		return s.Monthly
+	})
+	s.EmergencyDate = addMonths(from, s.EmergencyMonths)
+
+	if plan.WithdrawalRate > 0 {
+		s.FIRENumber = monthlySpending * 12 / (plan.WithdrawalRate / 100)
+	}
+	s.BaselineMonths = monthsToReach(portfolio, s.FIRENumber, annualReturn, func(int) float64 {
+		return 0
+	})
+	s.BaselineDate = addMonths(from, s.BaselineMonths)
+
+	s.FIREMonths = monthsToReach(portfolio, s.FIRENumber, annualReturn, func(month int) float64 {
+		if s.EmergencyMonths < 0 || month < s.EmergencyMonths {
+			return 0
+		}
+		return s.Monthly
+	})
+	s.FIREDate = addMonths(from, s.FIREMonths)
+
+	if incomeGrowth != 0 {
+		s.GrowthFIREMonths = monthsToReach(portfolio, s.FIRENumber, annualReturn, func(month int) float64 {
+			if s.EmergencyMonths < 0 || month < s.EmergencyMonths {
+				return 0
+			}
+			return s.Monthly * math.Pow(1+incomeGrowth/100, float64(month/12))
+		})
+		s.GrowthFIREDate = addMonths(from, s.GrowthFIREMonths)
+	}
+
+	if s.FIREMonths >= 0 && s.BaselineMonths >= 0 {
+		s.MonthsGained = s.BaselineMonths - s.FIREMonths
+	}
+	return s
+}
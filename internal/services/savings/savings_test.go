@@ -0,0 +1,158 @@
+package savings
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func day(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+func TestSuggest(t *testing.T) {
+	patterns := []models.IncomePattern{
+		{Description: "acme payroll", AvgAmount: 2000, Frequency: "biweekly", IsRegular: true},
+		{Description: "rental", AvgAmount: 500, Frequency: "monthly", IsRegular: true},
+		{Description: "bonus", AvgAmount: 5000, Frequency: "irregular"},
+	}
+	plan := models.SavingsPlan{EmergencyFund: 10000, TargetMonths: 6, WithdrawalRate: 4}
+	from := day("2026-01-01")
+
+	s := Suggest(patterns, 3000, plan, 100000, 6, 0, from)
+
+	if s.Paycheck != "acme payroll" || s.Frequency != "biweekly" {
+		t.Errorf("paycheck = %q (%s), want acme payroll (biweekly)", s.Paycheck, s.Frequency)
+	}
+	// 2000*26/12 + 500 = 4833.33 income, 1833.33 surplus, 80% over 2.1667
+	// paydays = 676.92, rounded down to 675
+	if s.PerPayday != 675 {
+		t.Errorf("PerPayday = %.2f, want 675", s.PerPayday)
+	}
+	if s.EmergencyTarget != 18000 {
+		t.Errorf("EmergencyTarget = %.2f, want 18000", s.EmergencyTarget)
+	}
+	// 8000 short at 1462.50/mo
+	if s.EmergencyMonths != 6 || !s.EmergencyDate.Equal(day("2026-07-01")) {
+		t.Errorf("emergency fund reached in %d months (%s), want 6 (2026-07-01)", s.EmergencyMonths, s.EmergencyDate)
+	}
+	if s.FIRENumber != 900000 {
+		t.Errorf("FIRENumber = %.2f, want 900000", s.FIRENumber)
+	}
+	if s.FIREMonths < 0 || s.BaselineMonths < 0 || s.FIREMonths >= s.BaselineMonths {
+		t.Errorf("FIRE in %d months with transfers vs %d without, want sooner with transfers", s.FIREMonths, s.BaselineMonths)
+	}
+	if s.MonthsGained != s.BaselineMonths-s.FIREMonths {
+		t.Errorf("MonthsGained = %d, want %d", s.MonthsGained, s.BaselineMonths-s.FIREMonths)
+	}
+}
+
+func TestSuggestWithoutSurplus(t *testing.T) {
+	patterns := []models.IncomePattern{
+		{Description: "payroll", AvgAmount: 1000, Frequency: "weekly", IsRegular: true},
+	}
+	s := Suggest(patterns, 5000, models.DefaultSavingsPlan(), 0, 6, 0, day("2026-01-01"))
+
+	if s.PerPayday != 0 || s.Monthly != 0 {
+		t.Errorf("transfer = %.2f/payday, want none when spending exceeds income", s.PerPayday)
+	}
+	if s.EmergencyMonths != -1 || !s.EmergencyDate.IsZero() {
+		t.Errorf("EmergencyMonths = %d, want -1 without transfers", s.EmergencyMonths)
+	}
+	if s.FIREMonths != -1 || s.MonthsGained != 0 {
+		t.Errorf("FIREMonths = %d, MonthsGained = %d, want -1 and 0", s.FIREMonths, s.MonthsGained)
+	}
+}
+
+func TestIncomeGrowthRate(t *testing.T) {
+	patterns := []models.IncomePattern{
+		{
+			Description: "acme payroll", Frequency: "biweekly", IsRegular: true, CurrentAmount: 2205,
+			FirstDate: day("2024-01-05"), LastDate: day("2026-01-02"),
+			Changes: []models.IncomeChange{
+				{Date: day("2025-01-03"), From: 2000, To: 2100, Percent: 5},
+				{Date: day("2026-01-02"), From: 2100, To: 2205, Percent: 5},
+			},
+		},
+		{Description: "bonus", AvgAmount: 5000, Frequency: "irregular"},
+	}
+	// 10.25% over two years is 5% a year
+	if got := IncomeGrowthRate(patterns); got != 5 {
+		t.Errorf("IncomeGrowthRate = %.2f, want 5", got)
+	}
+
+	// A raise within the last year isn't annualized upward
+	recent := []models.IncomePattern{{
+		Description: "payroll", Frequency: "monthly", IsRegular: true, CurrentAmount: 5300,
+		FirstDate: day("2025-07-01"), LastDate: day("2025-12-01"),
+		Changes: []models.IncomeChange{{Date: day("2025-10-01"), From: 5000, To: 5300, Percent: 6}},
+	}}
+	if got := IncomeGrowthRate(recent); got != 6 {
+		t.Errorf("IncomeGrowthRate = %.2f, want 6", got)
+	}
+
+	// Without raises there's no growth, and no growth projection
+	flat := []models.IncomePattern{{Description: "payroll", AvgAmount: 4000, Frequency: "monthly", IsRegular: true}}
+	if got := IncomeGrowthRate(flat); got != 0 {
+		t.Errorf("IncomeGrowthRate = %.2f, want 0", got)
+	}
+	plan := models.SavingsPlan{EmergencyFund: 20000, TargetMonths: 3, WithdrawalRate: 4}
+	s := Suggest(flat, 3000, plan, 100000, 6, 0, day("2026-01-01"))
+	if s.GrowthFIREMonths != -1 || !s.GrowthFIREDate.IsZero() {
+		t.Errorf("GrowthFIREMonths = %d, want -1 without growth", s.GrowthFIREMonths)
+	}
+
+	// Growing transfers reach FIRE sooner
+	s = Suggest(flat, 3000, plan, 100000, 6, 5, day("2026-01-01"))
+	if s.IncomeGrowthRate != 5 || s.GrowthFIREMonths < 0 || s.GrowthFIREMonths >= s.FIREMonths {
+		t.Errorf("FIRE in %d months with growth vs %d without, want sooner", s.GrowthFIREMonths, s.FIREMonths)
+	}
+}
+
+func TestMonthlySpending(t *testing.T) {
+	var txns []models.Transaction
+	// $100 a day for the first 9 months, then $200 a day for the last 3
+	for d := day("2025-01-01"); !d.After(day("2025-12-31")); d = d.AddDate(0, 0, 1) {
+		amount := -100.0
+		if !d.Before(day("2025-10-03")) {
+			amount = -200
+		}
+		txns = append(txns, models.Transaction{Date: d, Amount: amount, TransactionType: models.Outflow})
+	}
+	ts := models.NewTransactionSet(txns)
+
+	got := MonthlySpending(ts, day("2025-12-31"))
+	if want := 200 * 365.25 / 12; got < want-1 || got > want+1 {
+		t.Errorf("MonthlySpending = %.2f, want recent rate %.2f", got, want)
+	}
+}
+
+func TestPlanDefaultsAndSave(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	m := NewManager(dir, store)
+
+	plan, err := m.Get()
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if plan != models.DefaultSavingsPlan() {
+		t.Errorf("Get = %+v, want defaults", plan)
+	}
+
+	plan.EmergencyFund = 5000
+	if _, err := m.Save(plan); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if got, _ := m.Get(); got.EmergencyFund != 5000 || got.TargetMonths != 6 {
+		t.Errorf("Get after Save = %+v", got)
+	}
+
+	plan.TargetMonths = 0
+	if _, err := m.Save(plan); err == nil {
+		t.Error("Save with 0 target months should fail")
+	}
+}
@@ -0,0 +1,68 @@
+package sessions
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Store keeps login sessions in memory, so restarting the server signs
+// everyone out
+type Store struct {
+	ttl      time.Duration
+	mu       sync.Mutex
+	sessions map[string]time.Time // Token to expiry
+}
+
+// NewStore creates a store whose sessions last ttl
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		ttl:      ttl,
+		sessions: make(map[string]time.Time),
+	}
+}
+
+// Create starts a session, returning its token and when it expires
+func (s *Store) Create() (string, time.Time, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(b)
+	expires := time.Now().Add(s.ttl)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune()
+	s.sessions[token] = expires
+	return token, expires, nil
+}
+
+// Valid reports whether token belongs to a session that hasn't expired
+func (s *Store) Valid(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expires, ok := s.sessions[token]
+	return ok && time.Now().Before(expires)
+}
+
+// Revoke ends a session, e.g. on sign out
+func (s *Store) Revoke(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, token)
+}
+
+// prune drops expired sessions (caller must hold lock)
+func (s *Store) prune() {
+	now := time.Now()
+	for token, expires := range s.sessions {
+		if !now.Before(expires) {
+			delete(s.sessions, token)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	s := NewStore(time.Hour)
+	token, expires, err := s.Create()
+	if err != nil || len(token) != 64 || time.Until(expires) < 59*time.Minute {
+		t.Fatalf("Create = %q, %v, %v", token, expires, err)
+	}
+	other, _, _ := s.Create()
+	if other == token {
+		t.Error("Create should return a new token each time")
+	}
+	if !s.Valid(token) || s.Valid("") || s.Valid("not-a-session") {
+		t.Error("only created tokens should be valid")
+	}
+
+	s.Revoke(token)
+	if s.Valid(token) || !s.Valid(other) {
+		t.Error("Revoke should end only its own session")
+	}
+
+	expired := NewStore(-time.Second)
+	token, _, _ = expired.Create()
+	if expired.Valid(token) {
+		t.Error("an expired session should not be valid")
+	}
+	expired.Create()
+	if len(expired.sessions) != 1 {
+		t.Errorf("Create should prune expired sessions, %d kept", len(expired.sessions))
+	}
+}
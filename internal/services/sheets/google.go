@@ -0,0 +1,285 @@
+package sheets
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// readonlyScope is the OAuth scope a service account asks for
+const readonlyScope = "https://www.googleapis.com/auth/spreadsheets.readonly"
+
+// sheetURL picks the spreadsheet ID out of a Google Sheets link
+var sheetURL = regexp.MustCompile(`/spreadsheets/d/([A-Za-z0-9_-]+)`)
+
+// ParseID returns the spreadsheet ID from a Google Sheets link such as
+// https://docs.google.com/spreadsheets/d/<id>/edit, or s itself when it's
+// already an ID
+func ParseID(s string) string {
+	s = strings.TrimSpace(s)
+	if m := sheetURL.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	return s
+}
+
+// ServiceAccount is the part of a Google service account's JSON key the
+// client signs in with
+type ServiceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// ParseServiceAccount reads a service account's JSON key
+func ParseServiceAccount(data []byte) (*ServiceAccount, error) {
+	var sa ServiceAccount
+	if err := json.Unmarshal(data, &sa); err != nil {
+		return nil, fmt.Errorf("reading service account key: %w", err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, fmt.Errorf("service account key needs client_email and private_key")
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &sa, nil
+}
+
+// signingKey parses the account's PEM private key
+func (sa *ServiceAccount) signingKey() (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("service account private key isn't PEM")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing service account private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("service account private key isn't RSA")
+	}
+	return key, nil
+}
+
+// assertion builds the signed JWT exchanged for an access token
+func (sa *ServiceAccount) assertion(now time.Time) (string, error) {
+	key, err := sa.signingKey()
+	if err != nil {
+		return "", err
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   sa.ClientEmail,
+		"scope": readonlyScope,
+		"aud":   sa.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// Client reads spreadsheets from the Google Sheets API, signed in with a
+// service account or, for sheets anyone with the link can view, an API key
+type Client struct {
+	APIKey         string
+	ServiceAccount *ServiceAccount
+	BaseURL        string       // Empty uses https://sheets.googleapis.com
+	HTTP           *http.Client // Nil uses a client with a one-minute timeout
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// Sheet is a spreadsheet's title and the rows of one of its tabs, with
+// each cell a string, number or boolean
+type Sheet struct {
+	Title  string
+	Tab    string
+	Values [][]interface{}
+}
+
+// apiError is the error body the Google APIs return
+type apiError struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error"`
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTP != nil {
+		return c.HTTP
+	}
+	return &http.Client{Timeout: time.Minute}
+}
+
+// Fetch reads the rows of a spreadsheet's tab or A1 range, the first tab
+// when rng is empty. Numbers come back unformatted, so amounts shown as
+// "$1,250.00" arrive as 1250, while dates keep the format the sheet shows.
+func (c *Client) Fetch(id, rng string) (*Sheet, error) {
+	var meta struct {
+		Properties struct {
+			Title string `json:"title"`
+		} `json:"properties"`
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+	if err := c.get("/v4/spreadsheets/"+url.PathEscape(id), url.Values{"fields": {"properties.title,sheets.properties.title"}}, &meta); err != nil {
+		return nil, err
+	}
+	if rng == "" {
+		if len(meta.Sheets) == 0 {
+			return nil, fmt.Errorf("spreadsheet has no tabs")
+		}
+		rng = meta.Sheets[0].Properties.Title
+	}
+
+	var values struct {
+		Range  string          `json:"range"`
+		Values [][]interface{} `json:"values"`
+	}
+	query := url.Values{
+		"valueRenderOption":    {"UNFORMATTED_VALUE"},
+		"dateTimeRenderOption": {"FORMATTED_STRING"},
+	}
+	if err := c.get("/v4/spreadsheets/"+url.PathEscape(id)+"/values/"+url.PathEscape(rng), query, &values); err != nil {
+		return nil, err
+	}
+
+	// The range comes back as 'Tab name'!A1:E200
+	tab := values.Range
+	if i := strings.LastIndex(tab, "!"); i >= 0 {
+		tab = tab[:i]
+	}
+	tab = strings.ReplaceAll(strings.Trim(tab, "'"), "''", "'")
+	return &Sheet{Title: meta.Properties.Title, Tab: tab, Values: values.Values}, nil
+}
+
+// get sends an authorized GET to the Sheets API and decodes the response
+func (c *Client) get(path string, query url.Values, v interface{}) error {
+	base := c.BaseURL
+	if base == "" {
+		base = "https://sheets.googleapis.com"
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(base, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case c.ServiceAccount != nil:
+		token, err := c.accessToken()
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case c.APIKey != "":
+		query.Set("key", c.APIKey)
+	default:
+		return fmt.Errorf("no Google credentials; set an API key or a service account")
+	}
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return c.responseError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// responseError describes a failed API call, with a hint when the sheet
+// isn't shared with the credentials in use
+func (c *Client) responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var e apiError
+	msg := resp.Status
+	if json.Unmarshal(body, &e) == nil && e.Error.Message != "" {
+		msg = e.Error.Message
+	}
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound {
+		if c.ServiceAccount != nil {
+			return fmt.Errorf("%s; share the sheet with %s", msg, c.ServiceAccount.ClientEmail)
+		}
+		return fmt.Errorf("%s; an API key can only read sheets anyone with the link can view", msg)
+	}
+	return errors.New(msg)
+}
+
+// accessToken returns the service account's access token, signing in again
+// a minute before the last one expires
+func (c *Client) accessToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.token != "" && now.Before(c.expiry.Add(-time.Minute)) {
+		return c.token, nil
+	}
+
+	assertion, err := c.ServiceAccount.assertion(now)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.httpClient().PostForm(c.ServiceAccount.TokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		if body.Description != "" {
+			return "", fmt.Errorf("service account sign-in failed: %s", body.Description)
+		}
+		return "", fmt.Errorf("service account sign-in failed: %s", resp.Status)
+	}
+	c.token = body.AccessToken
+	c.expiry = now.Add(time.Duration(body.ExpiresIn) * time.Second)
+	return c.token, nil
+}
@@ -0,0 +1,279 @@
+// Package sheets imports spending logs kept in Google Sheets on a schedule,
+// writing each sheet's rows to its own CSV file in the data directory so
+// they load like any uploaded export: columns are detected (or mapped in
+// the file manager) and amounts signed the same way.
+package sheets
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/dataloader"
+	"budget2/internal/services/storage"
+)
+
+// statusFile holds the Google Sheets import status
+var statusFile = storage.SettingsFile("sheets_sync.json", func() interface{} { return &models.SheetSyncStatus{} })
+
+// Config holds the sheets to import, where they go and how often
+type Config struct {
+	DataDir     string
+	SettingsDir string
+	Interval    time.Duration
+	Sheets      []string // Spreadsheet IDs or links
+	Range       string   // Tab name or A1 range to read; empty reads each sheet's first tab
+}
+
+// Service imports the configured sheets
+type Service struct {
+	cfg    Config
+	client *Client
+	store  *storage.Storage
+	loader *dataloader.DataLoader
+	path   string
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+// NewService creates an import service; loader may be nil
+func NewService(cfg Config, client *Client, store *storage.Storage, loader *dataloader.DataLoader) *Service {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	ids := make([]string, len(cfg.Sheets))
+	for i, s := range cfg.Sheets {
+		ids[i] = ParseID(s)
+	}
+	cfg.Sheets = ids
+	return &Service{
+		cfg:    cfg,
+		client: client,
+		store:  store,
+		loader: loader,
+		path:   filepath.Join(cfg.SettingsDir, statusFile),
+		now:    time.Now,
+	}
+}
+
+// Interval is how often the sheets are imported
+func (s *Service) Interval() time.Duration {
+	return s.cfg.Interval
+}
+
+// Start imports in a background goroutine, once at startup and then every
+// interval
+func (s *Service) Start() {
+	go func() {
+		for {
+			if _, err := s.Sync(); err != nil {
+				log.Printf("Warning: Google Sheets import failed: %v", err)
+			}
+			time.Sleep(s.cfg.Interval)
+		}
+	}()
+}
+
+// Status returns how the last import went
+func (s *Service) Status() (*models.SheetSyncStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load()
+}
+
+// Sync reads every sheet and rewrites its file when the rows changed. The
+// sheet is the record, so rows edited or deleted there change here too. A
+// sheet that can't be read keeps its file as it was.
+func (s *Service) Sync() (*models.SheetSyncStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	var errs []error
+	written := false
+	for i := range st.Sheets {
+		entry := &st.Sheets[i]
+		entry.LastRun = now
+
+		changed, err := s.syncSheet(st, entry)
+		if err != nil {
+			entry.Error = err.Error()
+			errs = append(errs, fmt.Errorf("sheet %s: %w", entry.ID, err))
+			continue
+		}
+		entry.LastSuccess = now
+		entry.Error = ""
+		written = written || changed
+	}
+
+	if err := s.store.WriteJSON(s.path, st); err != nil {
+		return nil, err
+	}
+	if written && s.loader != nil {
+		s.loader.Invalidate()
+	}
+	return st, errors.Join(errs...)
+}
+
+// syncSheet reads one sheet into its file, reporting whether the file changed
+func (s *Service) syncSheet(st *models.SheetSyncStatus, entry *models.SheetSync) (bool, error) {
+	sheet, err := s.client.Fetch(entry.ID, s.cfg.Range)
+	if err != nil {
+		return false, err
+	}
+	data, rows, err := toCSV(sheet.Values)
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, fmt.Errorf("no rows below the header in %s", sheet.Tab)
+	}
+
+	entry.Title = sheet.Title
+	entry.Tab = sheet.Tab
+	entry.Rows = rows
+	if entry.File == "" {
+		entry.File = s.fileName(st, sheet.Title)
+	}
+
+	path := filepath.Join(s.cfg.DataDir, entry.File)
+	if existing, err := s.store.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return false, nil
+	}
+	if err := s.store.WriteFile(path, data, 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// toCSV turns a sheet's rows into a CSV file: the first row with anything
+// in it is the header, blank rows are dropped, and rows are padded or cut to
+// the header's width since the API leaves out empty cells at a row's end.
+// It returns the file and how many rows it has below the header.
+func toCSV(values [][]interface{}) ([]byte, int, error) {
+	var header []string
+	var rows [][]string
+	for _, row := range values {
+		cells := make([]string, len(row))
+		blank := true
+		for i, v := range row {
+			cells[i] = cell(v)
+			blank = blank && cells[i] == ""
+		}
+		if blank {
+			continue
+		}
+		if header == nil {
+			header = cells
+			continue
+		}
+		if len(cells) < len(header) {
+			cells = append(cells, make([]string, len(header)-len(cells))...)
+		}
+		rows = append(rows, cells[:len(header)])
+	}
+	if header == nil {
+		return nil, 0, fmt.Errorf("the sheet is empty")
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(header)
+	w.WriteAll(rows)
+	if err := w.Error(); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), len(rows), nil
+}
+
+// cell formats one value from the API: numbers without exponents or
+// trailing zeros, booleans as the sheet shows them
+func cell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v))
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// fileName picks a data file name for a newly imported sheet from its
+// title, numbering it if another sheet or an uploaded file has the name
+func (s *Service) fileName(st *models.SheetSyncStatus, title string) string {
+	base := "sheet-" + slug(title)
+	taken := func(name string) bool {
+		if st.File(name) != nil {
+			return true
+		}
+		_, err := s.store.Stat(filepath.Join(s.cfg.DataDir, name))
+		return err == nil
+	}
+	name := base + ".csv"
+	for n := 2; taken(name); n++ {
+		name = fmt.Sprintf("%s-%d.csv", base, n)
+	}
+	return name
+}
+
+// slug lowercases s and replaces runs of anything but letters and digits
+// with a dash
+func slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "log"
+	}
+	return b.String()
+}
+
+// load reads the import status (caller must hold lock), keeping only the
+// sheets still configured, in the configured order
+func (s *Service) load() (*models.SheetSyncStatus, error) {
+	saved := &models.SheetSyncStatus{}
+	if err := s.store.ReadJSON(s.path, saved); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	st := &models.SheetSyncStatus{Sheets: []models.SheetSync{}}
+	for _, id := range s.cfg.Sheets {
+		entry := models.SheetSync{ID: id}
+		for _, prev := range saved.Sheets {
+			if prev.ID == id {
+				entry = prev
+			}
+		}
+		st.Sheets = append(st.Sheets, entry)
+	}
+	return st, nil
+}
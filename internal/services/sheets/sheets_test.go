@@ -0,0 +1,225 @@
+package sheets
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"budget2/internal/services/dataloader"
+	"budget2/internal/services/storage"
+)
+
+func TestParseID(t *testing.T) {
+	tests := map[string]string{
+		"https://docs.google.com/spreadsheets/d/1AbC-d_9/edit#gid=0": "1AbC-d_9",
+		"https://docs.google.com/spreadsheets/d/1AbC-d_9":            "1AbC-d_9",
+		" 1AbC-d_9 ": "1AbC-d_9",
+	}
+	for in, want := range tests {
+		if got := ParseID(in); got != want {
+			t.Errorf("ParseID(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestToCSV(t *testing.T) {
+	data, rows, err := toCSV([][]interface{}{
+		{},
+		{"Date", "What", "Cost", "Category"},
+		{"3/1/2025", "Farmers market ", 42.5, "Groceries"},
+		{"", "", ""},
+		{"3/2/2025", "Gas, fill-up", 61.0}, // Trailing empty cells left out
+		{"3/3/2025", "Lunch", 12.25, "Eating Out", "note past the header"},
+		{"3/4/2025", "Refund", -20, false},
+	})
+	if err != nil {
+		t.Fatalf("toCSV: %v", err)
+	}
+	want := "Date,What,Cost,Category\n" +
+		"3/1/2025,Farmers market,42.5,Groceries\n" +
+		"3/2/2025,\"Gas, fill-up\",61,\n" +
+		"3/3/2025,Lunch,12.25,Eating Out\n" +
+		"3/4/2025,Refund,-20,FALSE\n"
+	if string(data) != want || rows != 4 {
+		t.Errorf("csv (%d rows) =\n%s\nwant\n%s", rows, data, want)
+	}
+
+	if _, _, err := toCSV([][]interface{}{{""}, {}}); err == nil {
+		t.Error("expected an error for an empty sheet")
+	}
+}
+
+// fakeSheets serves a spreadsheet's metadata and values the way the Sheets
+// API does, checking the request's credentials with auth
+func fakeSheets(t *testing.T, values *[][]interface{}, auth func(r *http.Request) bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !auth(r) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":{"code":403,"message":"The caller does not have permission","status":"PERMISSION_DENIED"}}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v4/spreadsheets/sheet1":
+			w.Write([]byte(`{"properties":{"title":"Household Spending"},"sheets":[{"properties":{"title":"Log"}},{"properties":{"title":"Summary"}}]}`))
+		case "/v4/spreadsheets/sheet1/values/Log":
+			if r.URL.Query().Get("valueRenderOption") != "UNFORMATTED_VALUE" {
+				t.Errorf("values requested as %q", r.URL.Query().Get("valueRenderOption"))
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"range": "Log!A1:D20", "values": *values})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestSync(t *testing.T) {
+	values := [][]interface{}{
+		{"Date", "Description", "Amount", "Category"},
+		{"2025-03-01", "Farmers market", -42.5, "Groceries"},
+		{"2025-03-02", "Paycheck", 1800, "Salary"},
+	}
+	server := fakeSheets(t, &values, func(r *http.Request) bool {
+		return r.URL.Query().Get("key") == "test-key"
+	})
+	defer server.Close()
+
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	loader := dataloader.New(dir, store)
+	// An uploaded file already has the name the sheet would get
+	os.WriteFile(filepath.Join(dir, "sheet-household-spending.csv"), []byte("Date,Description,Amount\n"), 0644)
+
+	client := &Client{APIKey: "test-key", BaseURL: server.URL}
+	svc := NewService(Config{
+		DataDir:     dir,
+		SettingsDir: filepath.Join(dir, "settings"),
+		Sheets:      []string{"https://docs.google.com/spreadsheets/d/sheet1/edit"},
+	}, client, store, loader)
+	now := time.Date(2025, 3, 2, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	status, err := svc.Sync()
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	sheet := status.File("sheet-household-spending-2.csv")
+	if sheet == nil || sheet.Title != "Household Spending" || sheet.Tab != "Log" || sheet.Rows != 2 || !sheet.LastSuccess.Equal(now) {
+		t.Fatalf("status = %+v", status.Sheets)
+	}
+
+	loaded, err := loader.LoadData()
+	if err != nil {
+		t.Fatalf("LoadData: %v", err)
+	}
+	if loaded.Len() != 2 || loaded.FilterByCategory("Groceries").SumAmount() != -42.5 {
+		t.Errorf("loaded %+v, want the sheet's two rows", loaded.Transactions)
+	}
+
+	// Rows edited in the sheet replace the file's
+	values[1][2] = -45.0
+	if _, err := svc.Sync(); err != nil {
+		t.Fatalf("second Sync: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "sheet-household-spending-2.csv"))
+	if !strings.Contains(string(data), "Farmers market,-45,") || strings.Contains(string(data), "-42.5") {
+		t.Errorf("file after an edit =\n%s", data)
+	}
+
+	// A failed import keeps the file and reports why
+	client.APIKey = "revoked"
+	now = now.Add(time.Hour)
+	status, err = svc.Sync()
+	if err == nil || !strings.Contains(err.Error(), "anyone with the link") {
+		t.Fatalf("expected a sharing hint, got %v", err)
+	}
+	sheet = status.File("sheet-household-spending-2.csv")
+	if sheet.Error == "" || !sheet.LastRun.Equal(now) || sheet.LastSuccess.Equal(now) {
+		t.Errorf("status after failure = %+v", sheet)
+	}
+	if after, _ := os.ReadFile(filepath.Join(dir, "sheet-household-spending-2.csv")); string(after) != string(data) {
+		t.Error("a failed import changed the file")
+	}
+}
+
+func TestServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	signIns := 0
+	values := [][]interface{}{{"Date", "Description", "Amount"}, {"2025-03-01", "Hardware store", -18}}
+	var tokenServer *httptest.Server
+	tokenServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		parts := strings.Split(r.PostForm.Get("assertion"), ".")
+		if len(parts) != 3 {
+			t.Fatalf("assertion = %q", r.PostForm.Get("assertion"))
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, sum[:], sig); err != nil {
+			t.Errorf("assertion signature: %v", err)
+		}
+		var claims map[string]interface{}
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		json.Unmarshal(payload, &claims)
+		if claims["iss"] != "budget@example.iam.gserviceaccount.com" || claims["scope"] != readonlyScope || claims["aud"] != tokenServer.URL {
+			t.Errorf("claims = %v", claims)
+		}
+		signIns++
+		w.Write([]byte(`{"access_token":"token-1","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	server := fakeSheets(t, &values, func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer token-1" && r.URL.Query().Get("key") == ""
+	})
+	defer server.Close()
+
+	keyJSON, _ := json.Marshal(map[string]string{
+		"client_email": "budget@example.iam.gserviceaccount.com",
+		"private_key":  string(keyPEM),
+		"token_uri":    tokenServer.URL,
+	})
+	sa, err := ParseServiceAccount(keyJSON)
+	if err != nil {
+		t.Fatalf("ParseServiceAccount: %v", err)
+	}
+	client := &Client{ServiceAccount: sa, BaseURL: server.URL}
+
+	sheet, err := client.Fetch("sheet1", "")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if sheet.Tab != "Log" || len(sheet.Values) != 2 {
+		t.Errorf("sheet = %+v", sheet)
+	}
+	// The token is reused until it's about to expire
+	if _, err := client.Fetch("sheet1", "Log"); err != nil || signIns != 1 {
+		t.Errorf("second fetch signed in %d times, err %v", signIns, err)
+	}
+
+	// A sheet not shared with the account says who to share it with
+	if _, err := client.Fetch("other", ""); err == nil || !strings.Contains(err.Error(), "share the sheet with budget@example.iam.gserviceaccount.com") {
+		t.Errorf("expected a sharing hint, got %v", err)
+	}
+
+	if _, err := ParseServiceAccount([]byte(`{"client_email":"x"}`)); err == nil {
+		t.Error("expected an error for a key without a private key")
+	}
+}
@@ -0,0 +1,97 @@
+package signs
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// conventionsFile holds each account's sign convention
+var conventionsFile = storage.SettingsFile("sign_conventions.json", func() interface{} { return &map[string]string{} })
+
+// Manager persists the sign convention the user chose for each data file,
+// overriding the one the loader detects
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing sign conventions in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, conventionsFile),
+		store: store,
+	}
+}
+
+// List returns the chosen conventions by file name
+func (m *Manager) List() (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Set chooses one of models.SignConventions for file. A blank convention
+// goes back to detecting it.
+func (m *Manager) Set(file, convention string) error {
+	if file == "" {
+		return fmt.Errorf("no file given")
+	}
+	if convention != "" && !slices.Contains(models.SignConventions, convention) {
+		return fmt.Errorf("unknown sign convention %q", convention)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	conventions, err := m.loadInternal()
+	if err != nil {
+		return err
+	}
+	if convention == "" {
+		delete(conventions, file)
+	} else {
+		conventions[file] = convention
+	}
+	return m.store.WriteJSON(m.path, conventions)
+}
+
+// Convention returns the convention chosen for file, or "" to detect it
+func (m *Manager) Convention(file string) string {
+	conventions, err := m.List()
+	if err != nil {
+		log.Printf("Warning: failed to load sign conventions: %v", err)
+		return ""
+	}
+	return conventions[file]
+}
+
+// Version changes whenever the chosen conventions do, so the loader reloads
+// data
+func (m *Manager) Version() string {
+	info, err := m.store.Stat(m.path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("signs|%d|%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// loadInternal reads the conventions without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() (map[string]string, error) {
+	conventions := make(map[string]string)
+	if err := m.store.ReadJSON(m.path, &conventions); err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]string), nil
+		}
+		return nil, err
+	}
+	return conventions, nil
+}
@@ -0,0 +1,45 @@
+package signs
+
+import (
+	"testing"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func TestSetConvention(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	if v := manager.Version(); v != "" {
+		t.Errorf("version without conventions = %q, want empty", v)
+	}
+
+	if err := manager.Set("amex.csv", models.SignDebitsPositive); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if c := manager.Convention("amex.csv"); c != models.SignDebitsPositive {
+		t.Errorf("convention = %q, want %q", c, models.SignDebitsPositive)
+	}
+	if c := manager.Convention("checking.csv"); c != "" {
+		t.Errorf("unset file convention = %q, want empty", c)
+	}
+	v1 := manager.Version()
+
+	// A blank convention goes back to detecting it
+	manager.Set("amex.csv", "")
+	if list, _ := manager.List(); len(list) != 0 {
+		t.Errorf("conventions = %v, want none", list)
+	}
+	if manager.Version() == v1 {
+		t.Error("version should change when conventions do")
+	}
+
+	if err := manager.Set("amex.csv", "sideways"); err == nil {
+		t.Error("Set should reject an unknown convention")
+	}
+	if err := manager.Set("", models.SignDebitsNegative); err == nil {
+		t.Error("Set should reject a blank file name")
+	}
+}
@@ -0,0 +1,152 @@
+package splits
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// splitsFile holds the transaction splits
+var splitsFile = storage.SettingsFile("splits.json", func() interface{} { return &map[string]models.Split{} })
+
+// Manager persists transactions the user split among categories, keyed by
+// transaction hash, and splits them again while data loads
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing splits in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, splitsFile),
+		store: store,
+	}
+}
+
+// List returns the splits by transaction hash
+func (m *Manager) List() (map[string]models.Split, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Set splits the transaction t among parts, whose amounts must add up to
+// the size of t's amount. Parts without a category or amount are dropped;
+// fewer than two parts left removes the split.
+func (m *Manager) Set(t models.Transaction, parts []models.SplitPart) error {
+	if t.Hash == "" {
+		return fmt.Errorf("transaction has no hash")
+	}
+
+	var kept []models.SplitPart
+	total := 0.0
+	for _, p := range parts {
+		p.Category = strings.TrimSpace(p.Category)
+		p.Amount = math.Round(p.Amount*100) / 100
+		if p.Category == "" && p.Amount == 0 {
+			continue
+		}
+		if p.Category == "" {
+			return fmt.Errorf("a part of %.2f has no category", p.Amount)
+		}
+		if p.Amount <= 0 {
+			return fmt.Errorf("%s needs a positive amount", p.Category)
+		}
+		total += p.Amount
+		kept = append(kept, p)
+	}
+	if len(kept) >= 2 && math.Abs(total-math.Abs(t.Amount)) >= 0.005 {
+		return fmt.Errorf("parts add up to %.2f, not %.2f", total, math.Abs(t.Amount))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	splits, err := m.loadInternal()
+	if err != nil {
+		return err
+	}
+	if len(kept) < 2 {
+		delete(splits, t.Hash)
+	} else {
+		splits[t.Hash] = models.Split{Parts: kept, UpdatedAt: time.Now()}
+	}
+	return m.store.WriteJSON(m.path, splits)
+}
+
+// Version changes whenever the splits do, so the loader reloads data
+func (m *Manager) Version() string {
+	info, err := m.store.Stat(m.path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("splits|%d|%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// Enrich replaces each split transaction with one row per part, so every
+// total, trend and drilldown counts each share under its own category.
+// Parts keep the transaction's date, description, type and source file.
+// Transactions are returned unchanged if the splits can't be read.
+func (m *Manager) Enrich(transactions []models.Transaction) []models.Transaction {
+	splits, err := m.List()
+	if err != nil {
+		log.Printf("Warning: failed to load splits: %v", err)
+		return transactions
+	}
+	if len(splits) == 0 {
+		return transactions
+	}
+
+	result := make([]models.Transaction, 0, len(transactions))
+	for _, t := range transactions {
+		split, ok := splits[t.Hash]
+		if !ok {
+			result = append(result, t)
+			continue
+		}
+		result = append(result, splitRows(t, split)...)
+	}
+	return result
+}
+
+// splitRows returns the rows split makes of transaction t
+func splitRows(t models.Transaction, split models.Split) []models.Transaction {
+	sign := 1.0
+	if t.Amount < 0 {
+		sign = -1
+	}
+	parts := make([]models.Transaction, len(split.Parts))
+	for i, p := range split.Parts {
+		part := t
+		part.Amount = sign * p.Amount
+		part.Category = p.Category
+		part.CategoryEdited = false
+		part.SplitOf = t.Hash
+		part.Hash = fmt.Sprintf("%s-%d", t.Hash, i+1)
+		parts[i] = part
+	}
+	return parts
+}
+
+// loadInternal reads the splits without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() (map[string]models.Split, error) {
+	splits := make(map[string]models.Split)
+	if err := m.store.ReadJSON(m.path, &splits); err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]models.Split), nil
+		}
+		return nil, err
+	}
+	return splits, nil
+}
@@ -0,0 +1,97 @@
+package splits
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func txn(desc string, amount float64, category string) models.Transaction {
+	d, _ := time.Parse("2006-01-02", "2024-03-01")
+	t := models.Transaction{Date: d, Description: desc, Amount: amount, Category: category, TransactionType: models.Outflow}
+	t.Hash = t.ComputeHash()
+	return t
+}
+
+func TestSetAndEnrich(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	if v := manager.Version(); v != "" {
+		t.Errorf("version without splits = %q, want empty", v)
+	}
+
+	costco := txn("COSTCO WHSE #0117", -250, "Groceries")
+	parts := []models.SplitPart{
+		{Category: " Groceries ", Amount: 180},
+		{Category: "Household", Amount: 70},
+		{}, // Blank rows from the form are dropped
+	}
+	if err := manager.Set(costco, parts); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	loaded := manager.Enrich([]models.Transaction{costco, txn("SAFEWAY", -80, "Groceries")})
+	if len(loaded) != 3 {
+		t.Fatalf("got %d rows, want the split's 2 and the other row", len(loaded))
+	}
+	want := []struct {
+		category string
+		amount   float64
+	}{{"Groceries", -180}, {"Household", -70}}
+	for i, w := range want {
+		part := loaded[i]
+		if part.Category != w.category || part.Amount != w.amount || part.SplitOf != costco.Hash {
+			t.Errorf("part %d = %s %.2f of %q, want %s %.2f of %q", i, part.Category, part.Amount, part.SplitOf, w.category, w.amount, costco.Hash)
+		}
+		if part.Hash == costco.Hash || part.Description != costco.Description || part.TransactionType != models.Outflow {
+			t.Errorf("part %d = %+v", i, part)
+		}
+	}
+	if loaded[0].Hash == loaded[1].Hash {
+		t.Error("parts should have distinct hashes")
+	}
+	if loaded[2].SplitOf != "" || loaded[2].Amount != -80 {
+		t.Errorf("unsplit row = %+v", loaded[2])
+	}
+
+	// A single part left removes the split
+	v1 := manager.Version()
+	if err := manager.Set(costco, []models.SplitPart{{Category: "Groceries", Amount: 250}}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if list, _ := manager.List(); len(list) != 0 {
+		t.Errorf("splits = %+v, want none", list)
+	}
+	if manager.Version() == v1 {
+		t.Error("version should change when splits change")
+	}
+}
+
+func TestSetRejectsBadParts(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+	costco := txn("COSTCO WHSE #0117", -250, "Groceries")
+
+	tests := []struct {
+		name  string
+		parts []models.SplitPart
+	}{
+		{"wrong total", []models.SplitPart{{Category: "Groceries", Amount: 180}, {Category: "Household", Amount: 60}}},
+		{"no category", []models.SplitPart{{Category: "Groceries", Amount: 180}, {Amount: 70}}},
+		{"negative amount", []models.SplitPart{{Category: "Groceries", Amount: 320}, {Category: "Household", Amount: -70}}},
+	}
+	for _, tt := range tests {
+		if err := manager.Set(costco, tt.parts); err == nil {
+			t.Errorf("%s: Set should fail", tt.name)
+		}
+	}
+
+	if err := manager.Set(models.Transaction{Description: "no hash"}, nil); err == nil {
+		t.Error("Set should reject a transaction without a hash")
+	}
+}
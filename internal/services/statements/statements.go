@@ -0,0 +1,229 @@
+package statements
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/analytics"
+	"budget2/internal/services/storage"
+)
+
+// cyclesFile holds the statement cycles
+var cyclesFile = storage.SettingsFile("statement_cycles.json", func() interface{} { return &[]models.StatementCycle{} })
+
+// MaxPeriods caps how many statements are listed per card
+const MaxPeriods = 12
+
+// Manager persists statement cycle settings
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing statement cycles in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, cyclesFile),
+		store: store,
+	}
+}
+
+// List returns all configured cards sorted by name
+func (m *Manager) List() ([]models.StatementCycle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Set configures the cycle for a source file, replacing any earlier one. A
+// blank name uses the file name.
+func (m *Manager) Set(c models.StatementCycle) ([]models.StatementCycle, error) {
+	c.Source = strings.TrimSpace(c.Source)
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Source == "" {
+		return nil, fmt.Errorf("source file is required")
+	}
+	if c.ClosingDay < 1 || c.ClosingDay > 31 {
+		return nil, fmt.Errorf("closing day must be between 1 and 31, got %d", c.ClosingDay)
+	}
+	if c.Name == "" {
+		c.Name = strings.TrimSuffix(c.Source, filepath.Ext(c.Source))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.StatementCycle, 0, len(list)+1)
+	for _, existing := range list {
+		if existing.Source != c.Source {
+			filtered = append(filtered, existing)
+		}
+	}
+	filtered = append(filtered, c)
+	sort.Slice(filtered, func(i, j int) bool {
+		return strings.ToLower(filtered[i].Name) < strings.ToLower(filtered[j].Name)
+	})
+
+	if err := m.store.WriteJSON(m.path, filtered); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// Remove drops the cycle for a source file
+func (m *Manager) Remove(source string) ([]models.StatementCycle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.StatementCycle, 0, len(list))
+	for _, c := range list {
+		if c.Source != source {
+			filtered = append(filtered, c)
+		}
+	}
+
+	if err := m.store.WriteJSON(m.path, filtered); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// loadInternal reads statement cycles without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() ([]models.StatementCycle, error) {
+	var list []models.StatementCycle
+	if err := m.store.ReadJSON(m.path, &list); err != nil {
+		if os.IsNotExist(err) {
+			return []models.StatementCycle{}, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+// closingDate returns the statement closing date in the given month. Closing
+// days past the end of a short month close on its last day.
+func closingDate(year int, month time.Month, closingDay int) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if closingDay > last {
+		closingDay = last
+	}
+	return time.Date(year, month, closingDay, 0, 0, 0, 0, time.UTC)
+}
+
+// PeriodFor returns the statement period containing date: from the day after
+// the previous closing date through the next closing date
+func PeriodFor(date time.Time, closingDay int) (start, end time.Time) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	end = closingDate(day.Year(), day.Month(), closingDay)
+	if day.After(end) {
+		end = closingDate(day.Year(), day.Month()+1, closingDay)
+	}
+	prev := closingDate(end.Year(), end.Month()-1, closingDay)
+	return prev.AddDate(0, 0, 1), end
+}
+
+// Build groups a card's transactions in ts into statement periods up to the
+// period containing asOf. The period containing asOf is open and gets a
+// projected closing balance at its pace so far, with a range from the card's
+// daily charges before it.
+func Build(c models.StatementCycle, ts *models.TransactionSet, asOf time.Time) models.CardStatements {
+	result := models.CardStatements{StatementCycle: c, Periods: []models.StatementPeriod{}}
+
+	var txns []models.Transaction
+	for _, t := range ts.Transactions {
+		if t.SourceFile == c.Source {
+			txns = append(txns, t)
+		}
+	}
+	if len(txns) == 0 {
+		return result
+	}
+	sort.Slice(txns, func(i, j int) bool {
+		return txns[i].Date.Before(txns[j].Date)
+	})
+
+	asOfDay := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	if last := txns[len(txns)-1].Date; asOfDay.Before(last) {
+		asOfDay = time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	start, end := PeriodFor(txns[0].Date, c.ClosingDay)
+	i := 0
+	for !start.After(asOfDay) {
+		p := models.StatementPeriod{
+			Start:     start,
+			End:       end,
+			DaysTotal: int(end.Sub(start).Hours()/24+0.5) + 1,
+		}
+		for ; i < len(txns); i++ {
+			d := txns[i].Date
+			day := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+			if day.After(end) {
+				break
+			}
+			if txns[i].TransactionType == models.Income {
+				p.Credits += math.Abs(txns[i].Amount)
+			} else {
+				p.Charges += math.Abs(txns[i].Amount)
+			}
+			p.TransactionCount++
+		}
+		p.Balance = p.Charges - p.Credits
+
+		if !asOfDay.After(end) {
+			p.Open = true
+			p.DaysElapsed = int(asOfDay.Sub(start).Hours()/24+0.5) + 1
+			p.Projected = p.Balance / float64(p.DaysElapsed) * float64(p.DaysTotal)
+			stdDev := analytics.DailySpendStdDev(models.NewTransactionSet(txns), txns[0].Date, start.AddDate(0, 0, -1))
+			p.ProjectedRange = analytics.ProjectionRange(p.Projected, stdDev, p.DaysTotal-p.DaysElapsed)
+		}
+		result.Periods = append(result.Periods, p)
+
+		start = end.AddDate(0, 0, 1)
+		_, end = PeriodFor(start, c.ClosingDay)
+	}
+
+	// Newest first
+	for l, r := 0, len(result.Periods)-1; l < r; l, r = l+1, r-1 {
+		result.Periods[l], result.Periods[r] = result.Periods[r], result.Periods[l]
+	}
+	if len(result.Periods) > MaxPeriods {
+		result.Periods = result.Periods[:MaxPeriods]
+	}
+
+	var closedTotal float64
+	var closed int
+	for idx := range result.Periods {
+		p := &result.Periods[idx]
+		if p.Open {
+			result.Current = p
+			continue
+		}
+		closedTotal += p.Balance
+		closed++
+	}
+	if closed > 0 {
+		result.AverageStatement = closedTotal / float64(closed)
+	}
+	return result
+}
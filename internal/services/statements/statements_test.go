@@ -0,0 +1,102 @@
+package statements
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func day(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+func TestPeriodFor(t *testing.T) {
+	tests := []struct {
+		date       string
+		closingDay int
+		start, end string
+	}{
+		{"2025-03-10", 15, "2025-02-16", "2025-03-15"},
+		{"2025-03-15", 15, "2025-02-16", "2025-03-15"},
+		{"2025-03-16", 15, "2025-03-16", "2025-04-15"},
+		// Closing on the 31st closes on the last day of short months
+		{"2025-02-10", 31, "2025-02-01", "2025-02-28"},
+		{"2025-03-01", 31, "2025-03-01", "2025-03-31"},
+		{"2025-12-20", 5, "2025-12-06", "2026-01-05"},
+	}
+	for _, tt := range tests {
+		start, end := PeriodFor(day(tt.date), tt.closingDay)
+		if start.Format("2006-01-02") != tt.start || end.Format("2006-01-02") != tt.end {
+			t.Errorf("PeriodFor(%s, %d) = %s..%s, want %s..%s", tt.date, tt.closingDay,
+				start.Format("2006-01-02"), end.Format("2006-01-02"), tt.start, tt.end)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		{Date: day("2025-01-20"), Amount: -100, TransactionType: models.Outflow, SourceFile: "visa.csv"},
+		{Date: day("2025-02-10"), Amount: -50, TransactionType: models.Outflow, SourceFile: "visa.csv"},
+		{Date: day("2025-02-12"), Amount: 20, TransactionType: models.Income, SourceFile: "visa.csv"},
+		{Date: day("2025-02-20"), Amount: -60, TransactionType: models.Outflow, SourceFile: "visa.csv"},
+		{Date: day("2025-02-20"), Amount: -999, TransactionType: models.Outflow, SourceFile: "checking.csv"},
+	})
+	cycle := models.StatementCycle{Source: "visa.csv", Name: "Visa", ClosingDay: 15}
+
+	// Ten days into the Feb 16 - Mar 15 cycle
+	cards := Build(cycle, ts, day("2025-02-25"))
+	if len(cards.Periods) != 2 {
+		t.Fatalf("expected 2 periods, got %+v", cards.Periods)
+	}
+
+	closed := cards.Periods[1]
+	if closed.Open || closed.Charges != 150 || closed.Credits != 20 || closed.Balance != 130 {
+		t.Errorf("closed period = %+v, want 150 charges less 20 credits", closed)
+	}
+	if cards.AverageStatement != 130 {
+		t.Errorf("average statement = %.2f, want 130", cards.AverageStatement)
+	}
+
+	current := cards.Current
+	if current == nil || !current.Open || current.Balance != 60 {
+		t.Fatalf("current period = %+v, want open with 60 so far", current)
+	}
+	if current.DaysElapsed != 10 || current.DaysTotal != 28 || current.Projected != 168 {
+		t.Errorf("projection = day %d of %d, %.2f; want day 10 of 28, 168", current.DaysElapsed, current.DaysTotal, current.Projected)
+	}
+	// Charges from Jan 20 to Feb 15 spread the 18 days left around it
+	if r := current.ProjectedRange; r == nil || r.Low >= 168 || r.High <= 168 || math.Abs((r.High-168)-(168-r.Low)) > 1e-9 {
+		t.Errorf("projected range = %+v, want it around 168", r)
+	}
+
+	// Under two weeks of history isn't enough to go on
+	first := models.NewTransactionSet(ts.Transactions[:1])
+	if cards := Build(cycle, first, day("2025-01-25")); cards.Current.ProjectedRange != nil {
+		t.Errorf("first period range = %+v, want none", cards.Current.ProjectedRange)
+	}
+}
+
+func TestSetValidates(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	if _, err := manager.Set(models.StatementCycle{Source: "visa.csv", ClosingDay: 0}); err == nil {
+		t.Error("expected error for closing day 0")
+	}
+	list, err := manager.Set(models.StatementCycle{Source: "visa.csv", ClosingDay: 15})
+	if err != nil || len(list) != 1 || list[0].Name != "visa" {
+		t.Fatalf("Set = %+v, %v; want one card named after the file", list, err)
+	}
+	list, _ = manager.Set(models.StatementCycle{Source: "visa.csv", Name: "Visa", ClosingDay: 20})
+	if len(list) != 1 || list[0].ClosingDay != 20 {
+		t.Errorf("Set should replace the card's cycle, got %+v", list)
+	}
+	if list, _ = manager.Remove("visa.csv"); len(list) != 0 {
+		t.Errorf("Remove left %+v", list)
+	}
+}
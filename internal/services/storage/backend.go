@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Backend is the raw byte store underneath Storage. Paths passed to a
+// backend are the same absolute paths handlers already build from the data
+// directory; remote backends map them to keys relative to that directory.
+type Backend interface {
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte, perm os.FileMode) error
+	Stat(path string) (os.FileInfo, error)
+	Glob(pattern string) ([]string, error)
+	MkdirAll(path string, perm os.FileMode) error
+	Remove(path string) error
+	Walk(root string, fn filepath.WalkFunc) error
+}
+
+// Backend type names accepted by NewBackend
+const (
+	BackendLocal  = "local"
+	BackendS3     = "s3"
+	BackendWebDAV = "webdav"
+)
+
+// BackendOptions configures a non-local backend
+type BackendOptions struct {
+	Type string
+
+	// S3-compatible object storage
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3Prefix    string
+	S3AccessKey string
+	S3SecretKey string
+
+	// WebDAV
+	WebDAVURL      string
+	WebDAVUser     string
+	WebDAVPassword string
+}
+
+// NewBackend creates the backend described by opts for the given base directory
+func NewBackend(baseDir string, opts BackendOptions) (Backend, error) {
+	switch strings.ToLower(opts.Type) {
+	case "", BackendLocal:
+		return NewLocalBackend(), nil
+	case BackendS3:
+		return NewS3Backend(baseDir, S3Config{
+			Endpoint:  opts.S3Endpoint,
+			Region:    opts.S3Region,
+			Bucket:    opts.S3Bucket,
+			Prefix:    opts.S3Prefix,
+			AccessKey: opts.S3AccessKey,
+			SecretKey: opts.S3SecretKey,
+		})
+	case BackendWebDAV:
+		return NewWebDAVBackend(baseDir, WebDAVConfig{
+			URL:      opts.WebDAVURL,
+			User:     opts.WebDAVUser,
+			Password: opts.WebDAVPassword,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", opts.Type)
+	}
+}
+
+// LocalBackend stores files on the local filesystem
+type LocalBackend struct{}
+
+// NewLocalBackend creates a local disk backend
+func NewLocalBackend() *LocalBackend {
+	return &LocalBackend{}
+}
+
+// ReadFile reads a file from disk
+func (b *LocalBackend) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// WriteFile writes data to a file atomically using a temp file
+func (b *LocalBackend) WriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	// Write to temp file
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, perm); err != nil {
+		return err
+	}
+
+	// Atomic rename
+	return os.Rename(tmpPath, path)
+}
+
+// Stat returns file info
+func (b *LocalBackend) Stat(path string) (os.FileInfo, error) {
+	return os.Stat(path)
+}
+
+// Glob returns files matching a pattern
+func (b *LocalBackend) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+// MkdirAll creates a directory and all parents
+func (b *LocalBackend) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+// Remove removes a file
+func (b *LocalBackend) Remove(path string) error {
+	return os.Remove(path)
+}
+
+// Walk walks the file tree rooted at root
+func (b *LocalBackend) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, fn)
+}
+
+// remoteKeyMapper translates local-style paths under baseDir into
+// slash-separated keys for remote backends
+type remoteKeyMapper struct {
+	baseDir string
+}
+
+// key returns the remote key for path, or an error if path is outside baseDir
+func (m remoteKeyMapper) key(path string) (string, error) {
+	rel, err := filepath.Rel(m.baseDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside the data directory", path)
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// path converts a remote key back into a local-style path
+func (m remoteKeyMapper) path(key string) string {
+	return filepath.Join(m.baseDir, filepath.FromSlash(key))
+}
+
+// remoteFileInfo implements os.FileInfo for remote objects
+type remoteFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (fi remoteFileInfo) Name() string       { return fi.name }
+func (fi remoteFileInfo) Size() int64        { return fi.size }
+func (fi remoteFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi remoteFileInfo) IsDir() bool        { return fi.dir }
+func (fi remoteFileInfo) Sys() interface{}   { return nil }
+
+func (fi remoteFileInfo) Mode() os.FileMode {
+	if fi.dir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+// globKeys filters a flat list of keys with a local-style glob pattern
+func (m remoteKeyMapper) globKeys(pattern string, keys []string) ([]string, error) {
+	var matches []string
+	for _, k := range keys {
+		p := m.path(k)
+		ok, err := filepath.Match(pattern, p)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, p)
+		}
+	}
+	return matches, nil
+}
+
+// walkObjects calls fn for every object under root in lexical order,
+// synthesizing directory entries from key prefixes
+func (m remoteKeyMapper) walkObjects(root string, objects []remoteObject, fn filepath.WalkFunc) error {
+	rootKey, err := m.key(root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+
+	if err := fn(root, remoteFileInfo{name: filepath.Base(root), dir: true}, nil); err != nil {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+
+	seenDirs := make(map[string]bool)
+	var skipped []string
+
+	for _, obj := range objects {
+		if rootKey != "" && !strings.HasPrefix(obj.Key, rootKey+"/") {
+			continue
+		}
+		if hasAnyPrefix(obj.Key, skipped) {
+			continue
+		}
+
+		// Emit intermediate directories first
+		rel := strings.TrimPrefix(strings.TrimPrefix(obj.Key, rootKey), "/")
+		parts := strings.Split(rel, "/")
+		dirKey := rootKey
+		skip := false
+		for _, part := range parts[:len(parts)-1] {
+			if dirKey == "" {
+				dirKey = part
+			} else {
+				dirKey = dirKey + "/" + part
+			}
+			if seenDirs[dirKey] {
+				continue
+			}
+			seenDirs[dirKey] = true
+			err := fn(m.path(dirKey), remoteFileInfo{name: part, dir: true}, nil)
+			if err == filepath.SkipDir {
+				skipped = append(skipped, dirKey+"/")
+				skip = true
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if skip {
+			continue
+		}
+
+		info := remoteFileInfo{
+			name:    parts[len(parts)-1],
+			size:    obj.Size,
+			modTime: obj.ModTime,
+		}
+		if err := fn(m.path(obj.Key), info, nil); err != nil && err != filepath.SkipDir {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// remoteObject is a single file listed by a remote backend
+type remoteObject struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
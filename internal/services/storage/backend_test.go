@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/webdav"
+)
+
+// fakeS3 is a minimal in-memory S3 server supporting the calls S3Backend makes
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/bucket")
+	key = strings.TrimPrefix(key, "/")
+
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		prefix := r.URL.Query().Get("prefix")
+		var keys []string
+		for k := range f.objects {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		io.WriteString(w, "<ListBucketResult>")
+		for _, k := range keys {
+			io.WriteString(w, "<Contents><Key>"+k+"</Key><Size>1</Size></Contents>")
+		}
+		io.WriteString(w, "</ListBucketResult>")
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = data
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// exerciseBackend runs the same round trip against any backend
+func exerciseBackend(t *testing.T, base string, b Backend) {
+	t.Helper()
+
+	csvPath := filepath.Join(base, "checking.csv")
+	settingsPath := filepath.Join(base, "settings", "whatif.json")
+
+	if err := b.WriteFile(csvPath, []byte("Date,Description,Amount\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := b.WriteFile(settingsPath, []byte(`{}`), 0644); err != nil {
+		t.Fatalf("WriteFile nested: %v", err)
+	}
+
+	data, err := b.ReadFile(csvPath)
+	if err != nil || string(data) != "Date,Description,Amount\n" {
+		t.Fatalf("ReadFile = %q, %v", data, err)
+	}
+
+	if _, err := b.ReadFile(filepath.Join(base, "missing.csv")); !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error for missing file, got %v", err)
+	}
+	if _, err := b.Stat(filepath.Join(base, "missing.csv")); !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error from Stat, got %v", err)
+	}
+
+	matches, err := b.Glob(filepath.Join(base, "*.csv"))
+	if err != nil || len(matches) != 1 || matches[0] != csvPath {
+		t.Errorf("Glob = %v, %v; want [%s]", matches, err, csvPath)
+	}
+
+	var walked []string
+	err = b.Walk(base, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			walked = append(walked, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+	if len(walked) != 2 {
+		t.Errorf("Walk visited %v, want 2 files", walked)
+	}
+
+	if err := b.Remove(csvPath); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := b.Stat(csvPath); !os.IsNotExist(err) {
+		t.Errorf("file should be gone after Remove, got %v", err)
+	}
+}
+
+func TestLocalBackend(t *testing.T) {
+	dir := t.TempDir()
+	exerciseBackend(t, dir, NewLocalBackend())
+}
+
+func TestS3Backend(t *testing.T) {
+	srv := httptest.NewServer(&fakeS3{objects: make(map[string][]byte)})
+	defer srv.Close()
+
+	base := "/data"
+	b, err := NewS3Backend(base, S3Config{
+		Endpoint:  srv.URL,
+		Bucket:    "bucket",
+		AccessKey: "key",
+		SecretKey: "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Backend: %v", err)
+	}
+	exerciseBackend(t, base, b)
+}
+
+func TestWebDAVBackend(t *testing.T) {
+	srv := httptest.NewServer(&webdav.Handler{
+		FileSystem: webdav.NewMemFS(),
+		LockSystem: webdav.NewMemLS(),
+	})
+	defer srv.Close()
+
+	base := "/data"
+	b, err := NewWebDAVBackend(base, WebDAVConfig{URL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewWebDAVBackend: %v", err)
+	}
+	exerciseBackend(t, base, b)
+}
+
+func TestMemoryBackend(t *testing.T) {
+	base := "/data"
+	b := NewMemoryBackend(base)
+	exerciseBackend(t, base, b)
+
+	// Callers can't change stored data through the slices they pass or get
+	data := []byte("original")
+	path := filepath.Join(base, "copy.txt")
+	b.WriteFile(path, data, 0644)
+	data[0] = 'X'
+	read, _ := b.ReadFile(path)
+	read[1] = 'X'
+	if again, _ := b.ReadFile(path); string(again) != "original" {
+		t.Errorf("stored data = %q, want it unchanged", again)
+	}
+	if err := b.WriteFile("/etc/passwd", nil, 0644); err == nil {
+		t.Error("expected error for path outside the data directory")
+	}
+}
+
+func TestRemoteBackendRejectsOutsidePaths(t *testing.T) {
+	m := remoteKeyMapper{baseDir: "/data"}
+	if _, err := m.key("/etc/passwd"); err == nil {
+		t.Error("expected error for path outside the data directory")
+	}
+	if key, err := m.key("/data/settings/whatif.json"); err != nil || key != "settings/whatif.json" {
+		t.Errorf("key = %q, %v", key, err)
+	}
+}
+
+func TestEncryptionOverRemoteBackend(t *testing.T) {
+	srv := httptest.NewServer(&fakeS3{objects: make(map[string][]byte)})
+	defer srv.Close()
+
+	base := "/data"
+	backend, _ := NewS3Backend(base, S3Config{Endpoint: srv.URL, Bucket: "bucket", AccessKey: "key", SecretKey: "secret"})
+	store, err := NewWithBackend(base, backend)
+	if err != nil {
+		t.Fatalf("NewWithBackend: %v", err)
+	}
+
+	path := filepath.Join(base, "test.csv")
+	if err := store.WriteFile(path, []byte("a,b,c"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := store.EnableEncryption("testpassword123"); err != nil {
+		t.Fatalf("EnableEncryption: %v", err)
+	}
+
+	raw, _ := backend.ReadFile(path)
+	if !isAgeEncrypted(raw) {
+		t.Error("object should be encrypted in the bucket")
+	}
+
+	// A fresh Storage over the same bucket sees the marker and can unlock
+	reopened, _ := NewWithBackend(base, backend)
+	if !reopened.IsEncrypted() {
+		t.Fatal("expected reopened storage to detect encryption")
+	}
+	if err := reopened.Unlock("testpassword123"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	data, err := reopened.ReadFile(path)
+	if err != nil || string(data) != "a,b,c" {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+}
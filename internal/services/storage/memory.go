@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryBackend keeps files in memory, for tests that shouldn't touch disk.
+// Like the remote backends it maps paths under baseDir to keys, and
+// directories exist implicitly.
+type MemoryBackend struct {
+	keys    remoteKeyMapper
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+type memoryObject struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemoryBackend creates an empty in-memory backend for baseDir
+func NewMemoryBackend(baseDir string) *MemoryBackend {
+	return &MemoryBackend{
+		keys:    remoteKeyMapper{baseDir: baseDir},
+		objects: make(map[string]memoryObject),
+	}
+}
+
+// NewMemory creates a Storage instance for baseDir backed by memory
+func NewMemory(baseDir string) (*Storage, error) {
+	return NewWithBackend(baseDir, NewMemoryBackend(baseDir))
+}
+
+// ReadFile returns a copy of a file's contents
+func (b *MemoryBackend) ReadFile(path string) ([]byte, error) {
+	key, err := b.keys.key(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	obj, ok := b.objects[key]
+	if !ok {
+		return nil, &os.PathError{Op: "read", Path: path, Err: os.ErrNotExist}
+	}
+	return append([]byte(nil), obj.data...), nil
+}
+
+// WriteFile stores a copy of data
+func (b *MemoryBackend) WriteFile(path string, data []byte, perm os.FileMode) error {
+	key, err := b.keys.key(path)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[key] = memoryObject{data: append([]byte(nil), data...), modTime: time.Now()}
+	return nil
+}
+
+// Stat returns file info, treating any key prefix as a directory
+func (b *MemoryBackend) Stat(path string) (os.FileInfo, error) {
+	key, err := b.keys.key(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if obj, ok := b.objects[key]; ok {
+		return remoteFileInfo{name: filepath.Base(path), size: int64(len(obj.data)), modTime: obj.modTime}, nil
+	}
+	if key == "" {
+		return remoteFileInfo{name: filepath.Base(path), dir: true}, nil
+	}
+	for k := range b.objects {
+		if strings.HasPrefix(k, key+"/") {
+			return remoteFileInfo{name: filepath.Base(path), dir: true}, nil
+		}
+	}
+	return nil, &os.PathError{Op: "stat", Path: path, Err: os.ErrNotExist}
+}
+
+// Glob returns files matching a pattern
+func (b *MemoryBackend) Glob(pattern string) ([]string, error) {
+	objects := b.list()
+	keys := make([]string, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Key
+	}
+	return b.keys.globKeys(pattern, keys)
+}
+
+// MkdirAll is a no-op; directories exist implicitly
+func (b *MemoryBackend) MkdirAll(path string, perm os.FileMode) error {
+	return nil
+}
+
+// Remove deletes a file
+func (b *MemoryBackend) Remove(path string) error {
+	key, err := b.keys.key(path)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.objects[key]; !ok {
+		return &os.PathError{Op: "remove", Path: path, Err: os.ErrNotExist}
+	}
+	delete(b.objects, key)
+	return nil
+}
+
+// Walk visits every file under root
+func (b *MemoryBackend) Walk(root string, fn filepath.WalkFunc) error {
+	return b.keys.walkObjects(root, b.list(), fn)
+}
+
+// list returns every file sorted by key
+func (b *MemoryBackend) list() []remoteObject {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	objects := make([]remoteObject, 0, len(b.objects))
+	for key, obj := range b.objects {
+		objects = append(objects, remoteObject{Key: key, Size: int64(len(obj.data)), ModTime: obj.modTime})
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects
+}
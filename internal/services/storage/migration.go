@@ -39,13 +39,13 @@ func (s *Storage) EnableEncryption(password string) error {
 	if err != nil {
 		return fmt.Errorf("failed to encrypt verification file: %w", err)
 	}
-	if err := os.WriteFile(verifyPath, encrypted, 0644); err != nil {
+	if err := s.backend.WriteFile(verifyPath, encrypted, 0644); err != nil {
 		return fmt.Errorf("failed to write verification file: %w", err)
 	}
 
 	// Collect files to encrypt
 	var filesToEncrypt []string
-	err = filepath.Walk(s.baseDir, func(path string, info os.FileInfo, err error) error {
+	err = s.backend.Walk(s.baseDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -58,9 +58,9 @@ func (s *Storage) EnableEncryption(password string) error {
 			return nil
 		}
 
-		// Only encrypt CSV and JSON files
+		// Only encrypt data and settings files
 		ext := strings.ToLower(filepath.Ext(path))
-		if ext == ".csv" || ext == ".json" {
+		if ext == ".csv" || ext == ".qif" || ext == ".json" {
 			filesToEncrypt = append(filesToEncrypt, path)
 		}
 
@@ -68,7 +68,7 @@ func (s *Storage) EnableEncryption(password string) error {
 	})
 	if err != nil {
 		// Cleanup verification file on error
-		os.Remove(verifyPath)
+		s.backend.Remove(verifyPath)
 		return fmt.Errorf("failed to scan files: %w", err)
 	}
 
@@ -77,14 +77,14 @@ func (s *Storage) EnableEncryption(password string) error {
 		if err := s.encryptFile(path, recipient); err != nil {
 			// Attempt to rollback encrypted files (best effort)
 			s.rollbackEncryption(filesToEncrypt, identity)
-			os.Remove(verifyPath)
+			s.backend.Remove(verifyPath)
 			return fmt.Errorf("failed to encrypt %s: %w", filepath.Base(path), err)
 		}
 	}
 
 	// Create marker file
 	markerPath := filepath.Join(s.baseDir, markerFile)
-	if err := os.WriteFile(markerPath, []byte("encrypted"), 0644); err != nil {
+	if err := s.backend.WriteFile(markerPath, []byte("encrypted"), 0644); err != nil {
 		return fmt.Errorf("failed to create marker file: %w", err)
 	}
 
@@ -112,7 +112,7 @@ func (s *Storage) DisableEncryption(password string) error {
 	}
 
 	verifyPath := filepath.Join(s.baseDir, verifyFile)
-	encrypted, err := os.ReadFile(verifyPath)
+	encrypted, err := s.backend.ReadFile(verifyPath)
 	if err != nil {
 		return fmt.Errorf("failed to read verification file: %w", err)
 	}
@@ -128,7 +128,7 @@ func (s *Storage) DisableEncryption(password string) error {
 
 	// Collect files to decrypt
 	var filesToDecrypt []string
-	err = filepath.Walk(s.baseDir, func(path string, info os.FileInfo, err error) error {
+	err = s.backend.Walk(s.baseDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -137,7 +137,7 @@ func (s *Storage) DisableEncryption(password string) error {
 		}
 
 		// Check if file is encrypted
-		data, err := os.ReadFile(path)
+		data, err := s.backend.ReadFile(path)
 		if err != nil {
 			return nil // Skip unreadable files
 		}
@@ -160,8 +160,8 @@ func (s *Storage) DisableEncryption(password string) error {
 	}
 
 	// Remove marker and verification files
-	os.Remove(filepath.Join(s.baseDir, markerFile))
-	os.Remove(verifyPath)
+	s.backend.Remove(filepath.Join(s.baseDir, markerFile))
+	s.backend.Remove(verifyPath)
 
 	// Update storage state
 	s.encrypted = false
@@ -174,7 +174,7 @@ func (s *Storage) DisableEncryption(password string) error {
 // encryptFile encrypts a single file in place
 func (s *Storage) encryptFile(path string, recipient *age.ScryptRecipient) error {
 	// Read original file
-	data, err := os.ReadFile(path)
+	data, err := s.backend.ReadFile(path)
 	if err != nil {
 		return err
 	}
@@ -190,19 +190,14 @@ func (s *Storage) encryptFile(path string, recipient *age.ScryptRecipient) error
 		return err
 	}
 
-	// Write to temp file then atomic rename
-	tmpPath := path + ".tmp"
-	if err := os.WriteFile(tmpPath, encrypted, 0644); err != nil {
-		return err
-	}
-
-	return os.Rename(tmpPath, path)
+	// Backend writes are atomic (temp file + rename on local disk)
+	return s.backend.WriteFile(path, encrypted, 0644)
 }
 
 // decryptFile decrypts a single file in place
 func (s *Storage) decryptFile(path string, identity *age.ScryptIdentity) error {
 	// Read encrypted file
-	data, err := os.ReadFile(path)
+	data, err := s.backend.ReadFile(path)
 	if err != nil {
 		return err
 	}
@@ -218,19 +213,14 @@ func (s *Storage) decryptFile(path string, identity *age.ScryptIdentity) error {
 		return err
 	}
 
-	// Write to temp file then atomic rename
-	tmpPath := path + ".tmp"
-	if err := os.WriteFile(tmpPath, decrypted, 0644); err != nil {
-		return err
-	}
-
-	return os.Rename(tmpPath, path)
+	// Backend writes are atomic (temp file + rename on local disk)
+	return s.backend.WriteFile(path, decrypted, 0644)
 }
 
 // rollbackEncryption attempts to decrypt files that were encrypted during a failed migration
 func (s *Storage) rollbackEncryption(files []string, identity *age.ScryptIdentity) {
 	for _, path := range files {
-		data, err := os.ReadFile(path)
+		data, err := s.backend.ReadFile(path)
 		if err != nil {
 			continue
 		}
@@ -244,6 +234,6 @@ func (s *Storage) rollbackEncryption(files []string, identity *age.ScryptIdentit
 			continue
 		}
 
-		os.WriteFile(path, decrypted, 0644)
+		s.backend.WriteFile(path, decrypted, 0644)
 	}
 }
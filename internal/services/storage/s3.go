@@ -0,0 +1,392 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config configures an S3-compatible backend (AWS, MinIO, Backblaze, etc.)
+type S3Config struct {
+	Endpoint  string // e.g. https://s3.us-east-1.amazonaws.com or http://nas:9000
+	Region    string
+	Bucket    string
+	Prefix    string // optional key prefix inside the bucket
+	AccessKey string
+	SecretKey string
+}
+
+// S3Backend stores files as objects in an S3-compatible bucket using
+// path-style requests signed with AWS Signature Version 4
+type S3Backend struct {
+	cfg    S3Config
+	keys   remoteKeyMapper
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Backend creates an S3 backend rooted at baseDir
+func NewS3Backend(baseDir string, cfg S3Config) (*S3Backend, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 backend requires a bucket")
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("s3 backend requires access and secret keys")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+
+	return &S3Backend{
+		cfg:    cfg,
+		keys:   remoteKeyMapper{baseDir: baseDir},
+		client: &http.Client{Timeout: 60 * time.Second},
+		now:    time.Now,
+	}, nil
+}
+
+// objectKey returns the full bucket key for a local-style path
+func (b *S3Backend) objectKey(path string) (string, error) {
+	key, err := b.keys.key(path)
+	if err != nil {
+		return "", err
+	}
+	if b.cfg.Prefix == "" {
+		return key, nil
+	}
+	if key == "" {
+		return b.cfg.Prefix, nil
+	}
+	return b.cfg.Prefix + "/" + key, nil
+}
+
+// ReadFile downloads an object
+func (b *S3Backend) ReadFile(path string) ([]byte, error) {
+	key, err := b.objectKey(path)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.do(http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &os.PathError{Op: "read", Path: path, Err: os.ErrNotExist}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error("GET", key, resp)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// WriteFile uploads an object. Single PUTs are atomic in S3.
+func (b *S3Backend) WriteFile(path string, data []byte, perm os.FileMode) error {
+	key, err := b.objectKey(path)
+	if err != nil {
+		return err
+	}
+
+	resp, err := b.do(http.MethodPut, key, nil, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3Error("PUT", key, resp)
+	}
+	return nil
+}
+
+// Stat returns object metadata. Prefixes that contain objects are reported
+// as directories since S3 has no real directories.
+func (b *S3Backend) Stat(path string) (os.FileInfo, error) {
+	key, err := b.objectKey(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if key != "" {
+		resp, err := b.do(http.MethodHead, key, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusOK {
+			size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+			modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+			return remoteFileInfo{name: filepath.Base(path), size: size, modTime: modTime}, nil
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			return nil, s3Error("HEAD", key, resp)
+		}
+	}
+
+	objects, err := b.list(path, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) > 0 || key == "" {
+		return remoteFileInfo{name: filepath.Base(path), dir: true}, nil
+	}
+
+	return nil, &os.PathError{Op: "stat", Path: path, Err: os.ErrNotExist}
+}
+
+// Glob lists the pattern's directory and matches keys locally
+func (b *S3Backend) Glob(pattern string) ([]string, error) {
+	objects, err := b.list(filepath.Dir(pattern), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Key
+	}
+	return b.keys.globKeys(pattern, keys)
+}
+
+// MkdirAll is a no-op; prefixes exist implicitly
+func (b *S3Backend) MkdirAll(path string, perm os.FileMode) error {
+	return nil
+}
+
+// Remove deletes an object
+func (b *S3Backend) Remove(path string) error {
+	key, err := b.objectKey(path)
+	if err != nil {
+		return err
+	}
+
+	resp, err := b.do(http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return s3Error("DELETE", key, resp)
+	}
+	return nil
+}
+
+// Walk visits every object under root
+func (b *S3Backend) Walk(root string, fn filepath.WalkFunc) error {
+	objects, err := b.list(root, 0)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+	return b.keys.walkObjects(root, objects, fn)
+}
+
+// listBucketResult is the subset of the ListObjectsV2 response we need
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// list returns all objects below dir (recursively), with keys relative to
+// the configured prefix and sorted. A limit of 0 means no limit.
+func (b *S3Backend) list(dir string, limit int) ([]remoteObject, error) {
+	dirKey, err := b.objectKey(dir)
+	if err != nil {
+		return nil, err
+	}
+	listPrefix := ""
+	if dirKey != "" {
+		listPrefix = dirKey + "/"
+	}
+
+	var objects []remoteObject
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", listPrefix)
+		if limit > 0 {
+			query.Set("max-keys", strconv.Itoa(limit))
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := b.do(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := s3Error("LIST", listPrefix, resp)
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse bucket listing: %w", err)
+		}
+
+		for _, c := range result.Contents {
+			key := c.Key
+			if b.cfg.Prefix != "" {
+				key = strings.TrimPrefix(key, b.cfg.Prefix+"/")
+			}
+			objects = append(objects, remoteObject{Key: key, Size: c.Size, ModTime: c.LastModified})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" || (limit > 0 && len(objects) >= limit) {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// do sends a signed request for the given object key (empty for bucket-level)
+func (b *S3Backend) do(method, key string, query url.Values, body []byte) (*http.Response, error) {
+	canonicalURI := "/" + s3Escape(b.cfg.Bucket, false)
+	if key != "" {
+		canonicalURI += "/" + s3Escape(key, false)
+	}
+	canonicalQuery := s3CanonicalQuery(query)
+
+	rawURL := b.cfg.Endpoint + canonicalURI
+	if canonicalQuery != "" {
+		rawURL += "?" + canonicalQuery
+	}
+
+	req, err := http.NewRequest(method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+
+	b.sign(req, canonicalURI, canonicalQuery, body)
+	return b.client.Do(req)
+}
+
+// sign adds AWS Signature Version 4 headers to req
+func (b *S3Backend) sign(req *http.Request, canonicalURI, canonicalQuery string, body []byte) {
+	now := b.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+
+	payloadHash := sha256Hex(body)
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		canonicalQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := dateStamp + "/" + b.cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	signingKey := hmacSHA256([]byte("AWS4"+b.cfg.SecretKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, b.cfg.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.cfg.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+// s3CanonicalQuery encodes query parameters sorted by key as SigV4 requires
+func s3CanonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape percent-encodes everything except unreserved characters. Slashes
+// are kept when encoding paths.
+func s3Escape(s string, encodeSlash bool) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9'),
+			c == '-', c == '_', c == '.', c == '~':
+			buf.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			buf.WriteByte(c)
+		default:
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3Error builds an error from a failed S3 response
+func s3Error(op, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3 %s %s: %s: %s", op, key, resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// SettingsChecker is implemented by settings schemas that need more than a
+// successful decode, such as a supported schema version
+type SettingsChecker interface {
+	CheckSettings() error
+}
+
+// settingsSchemas maps each settings file to the value its JSON decodes
+// into, registered by the service that writes the file
+var (
+	settingsMu      sync.RWMutex
+	settingsSchemas = map[string]func() interface{}{}
+)
+
+// SettingsFile registers name as a settings file whose JSON decodes into the
+// value schema returns, and returns name so the writer builds its path from
+// the registration. A value implementing SettingsChecker is also checked.
+func SettingsFile(name string, schema func() interface{}) string {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	if _, dup := settingsSchemas[name]; dup {
+		panic(fmt.Sprintf("settings file %s registered twice", name))
+	}
+	settingsSchemas[name] = schema
+	return name
+}
+
+// SettingsFiles lists the registered settings files by name
+func SettingsFiles() []string {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+
+	names := make([]string, 0, len(settingsSchemas))
+	for name := range settingsSchemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckSettings checks a settings file's contents are JSON of the shape its
+// writer reads. Files no service registered only need to be valid JSON.
+func CheckSettings(name string, data []byte) error {
+	settingsMu.RLock()
+	schema, ok := settingsSchemas[name]
+	settingsMu.RUnlock()
+
+	if !ok {
+		if !json.Valid(data) {
+			return fmt.Errorf("not valid JSON")
+		}
+		return nil
+	}
+	v := schema()
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("doesn't match the %s schema: %v", name, err)
+	}
+	if checker, ok := v.(SettingsChecker); ok {
+		if err := checker.CheckSettings(); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
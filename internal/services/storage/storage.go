@@ -2,6 +2,7 @@ package storage
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -32,18 +33,25 @@ type Storage struct {
 	encrypted bool
 	identity  *age.ScryptIdentity
 	recipient *age.ScryptRecipient
+	backend   Backend
 	mu        sync.RWMutex
 }
 
-// New creates a new Storage instance for the given base directory
+// New creates a new Storage instance for the given base directory on local disk
 func New(baseDir string) (*Storage, error) {
+	return NewWithBackend(baseDir, NewLocalBackend())
+}
+
+// NewWithBackend creates a Storage instance that keeps its files in backend
+func NewWithBackend(baseDir string, backend Backend) (*Storage, error) {
 	s := &Storage{
 		baseDir: baseDir,
+		backend: backend,
 	}
 
 	// Check if encryption is enabled
 	markerPath := filepath.Join(baseDir, markerFile)
-	if _, err := os.Stat(markerPath); err == nil {
+	if _, err := backend.Stat(markerPath); err == nil {
 		s.encrypted = true
 	}
 
@@ -86,7 +94,7 @@ func (s *Storage) Unlock(password string) error {
 
 	// Verify password by decrypting the verification file
 	verifyPath := filepath.Join(s.baseDir, verifyFile)
-	encrypted, err := os.ReadFile(verifyPath)
+	encrypted, err := s.backend.ReadFile(verifyPath)
 	if err != nil {
 		return fmt.Errorf("failed to read verification file: %w", err)
 	}
@@ -121,7 +129,7 @@ func (s *Storage) ReadFile(path string) ([]byte, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	data, err := os.ReadFile(path)
+	data, err := s.backend.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -144,7 +152,7 @@ func (s *Storage) WriteFile(path string, data []byte, perm os.FileMode) error {
 
 	// Skip encryption for certain files
 	if s.shouldSkipEncryption(path) {
-		return s.atomicWrite(path, data, perm)
+		return s.backend.WriteFile(path, data, perm)
 	}
 
 	// Encrypt if enabled and unlocked
@@ -156,33 +164,35 @@ func (s *Storage) WriteFile(path string, data []byte, perm os.FileMode) error {
 		data = encrypted
 	}
 
-	return s.atomicWrite(path, data, perm)
+	return s.backend.WriteFile(path, data, perm)
 }
 
-// OpenFile returns a reader for a potentially encrypted file
-func (s *Storage) OpenFile(path string) (io.ReadCloser, error) {
+// ReadJSON reads a JSON file into v. A missing file leaves v untouched and
+// returns an error satisfying os.IsNotExist.
+func (s *Storage) ReadJSON(path string, v interface{}) error {
 	data, err := s.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return io.NopCloser(bytes.NewReader(data)), nil
+	return json.Unmarshal(data, v)
 }
 
-// atomicWrite writes data to a file atomically using a temp file
-func (s *Storage) atomicWrite(path string, data []byte, perm os.FileMode) error {
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+// WriteJSON writes v as indented JSON
+func (s *Storage) WriteJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
 		return err
 	}
+	return s.WriteFile(path, data, 0644)
+}
 
-	// Write to temp file
-	tmpPath := path + ".tmp"
-	if err := os.WriteFile(tmpPath, data, perm); err != nil {
-		return err
+// OpenFile returns a reader for a potentially encrypted file
+func (s *Storage) OpenFile(path string) (io.ReadCloser, error) {
+	data, err := s.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
-
-	// Atomic rename
-	return os.Rename(tmpPath, path)
+	return io.NopCloser(bytes.NewReader(data)), nil
 }
 
 // shouldSkipEncryption returns true for files that shouldn't be encrypted
@@ -209,20 +219,25 @@ func isAgeEncrypted(data []byte) bool {
 
 // Stat returns file info, useful for checking existence
 func (s *Storage) Stat(path string) (os.FileInfo, error) {
-	return os.Stat(path)
+	return s.backend.Stat(path)
 }
 
 // Glob returns files matching a pattern
 func (s *Storage) Glob(pattern string) ([]string, error) {
-	return filepath.Glob(pattern)
+	return s.backend.Glob(pattern)
 }
 
 // MkdirAll creates a directory and all parents
 func (s *Storage) MkdirAll(path string, perm os.FileMode) error {
-	return os.MkdirAll(path, perm)
+	return s.backend.MkdirAll(path, perm)
 }
 
 // Remove removes a file
 func (s *Storage) Remove(path string) error {
-	return os.Remove(path)
+	return s.backend.Remove(path)
+}
+
+// Walk walks the file tree rooted at root
+func (s *Storage) Walk(root string, fn filepath.WalkFunc) error {
+	return s.backend.Walk(root, fn)
 }
@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -185,3 +188,50 @@ func TestNewFilesEncrypted(t *testing.T) {
 		t.Errorf("Content mismatch: got %q, want %q", string(read), string(content))
 	}
 }
+
+// checkedSchema is a test settings schema with a version check
+type checkedSchema struct {
+	Version int `json:"version"`
+}
+
+func (s *checkedSchema) CheckSettings() error {
+	if s.Version > 1 {
+		return fmt.Errorf("version %d is too new", s.Version)
+	}
+	return nil
+}
+
+func TestCheckSettings(t *testing.T) {
+	name := SettingsFile("test_checked.json", func() interface{} { return &checkedSchema{} })
+	if name != "test_checked.json" {
+		t.Errorf("SettingsFile returned %q", name)
+	}
+	if !slices.Contains(SettingsFiles(), name) {
+		t.Errorf("SettingsFiles() = %v, missing %s", SettingsFiles(), name)
+	}
+
+	tests := []struct {
+		name, file, data string
+		wantErr          string
+	}{
+		{"matches", name, `{"version":1}`, ""},
+		{"wrong shape", name, `{"version":"one"}`, "doesn't match the test_checked.json schema"},
+		{"fails its check", name, `{"version":2}`, "version 2 is too new"},
+		{"unregistered JSON", "other.json", `{"anything":[1,2]}`, ""},
+		{"unregistered garbage", "other.json", `{`, "not valid JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSettings(tt.file, []byte(tt.data))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckSettings() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckSettings() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
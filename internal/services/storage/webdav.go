@@ -0,0 +1,344 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebDAVConfig configures a WebDAV backend (Nextcloud, Synology, Apache mod_dav, etc.)
+type WebDAVConfig struct {
+	URL      string // collection URL the data directory maps to
+	User     string
+	Password string
+}
+
+// WebDAVBackend stores files on a WebDAV server
+type WebDAVBackend struct {
+	cfg    WebDAVConfig
+	base   *url.URL
+	keys   remoteKeyMapper
+	client *http.Client
+}
+
+// NewWebDAVBackend creates a WebDAV backend rooted at baseDir
+func NewWebDAVBackend(baseDir string, cfg WebDAVConfig) (*WebDAVBackend, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("webdav backend requires a URL")
+	}
+	base, err := url.Parse(strings.TrimRight(cfg.URL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid webdav URL: %w", err)
+	}
+
+	return &WebDAVBackend{
+		cfg:    cfg,
+		base:   base,
+		keys:   remoteKeyMapper{baseDir: baseDir},
+		client: &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// ReadFile downloads a file
+func (b *WebDAVBackend) ReadFile(p string) ([]byte, error) {
+	key, err := b.keys.key(p)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.do(http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &os.PathError{Op: "read", Path: p, Err: os.ErrNotExist}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, webdavError("GET", key, resp)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// WriteFile uploads to a temp name and MOVEs it into place so readers never
+// see a partial file
+func (b *WebDAVBackend) WriteFile(p string, data []byte, perm os.FileMode) error {
+	key, err := b.keys.key(p)
+	if err != nil {
+		return err
+	}
+	if err := b.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	tmpKey := key + ".tmp"
+	resp, err := b.do(http.MethodPut, tmpKey, nil, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return webdavError("PUT", tmpKey, resp)
+	}
+
+	headers := map[string]string{
+		"Destination": b.resolve(key).String(),
+		"Overwrite":   "T",
+	}
+	resp, err = b.do("MOVE", tmpKey, headers, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return webdavError("MOVE", tmpKey, resp)
+	}
+	return nil
+}
+
+// Stat returns file or collection info via PROPFIND
+func (b *WebDAVBackend) Stat(p string) (os.FileInfo, error) {
+	key, err := b.keys.key(p)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := b.propfind(key, "0")
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, &os.PathError{Op: "stat", Path: p, Err: os.ErrNotExist}
+	}
+
+	e := entries[0]
+	return remoteFileInfo{name: filepath.Base(p), size: e.size, modTime: e.modTime, dir: e.dir}, nil
+}
+
+// Glob lists the pattern's directory and matches names locally
+func (b *WebDAVBackend) Glob(pattern string) ([]string, error) {
+	dirKey, err := b.keys.key(filepath.Dir(pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := b.propfind(dirKey, "1")
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, e := range entries {
+		if !e.dir && e.key != dirKey {
+			keys = append(keys, e.key)
+		}
+	}
+	sort.Strings(keys)
+	return b.keys.globKeys(pattern, keys)
+}
+
+// MkdirAll creates each missing collection along the path
+func (b *WebDAVBackend) MkdirAll(p string, perm os.FileMode) error {
+	key, err := b.keys.key(p)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return nil
+	}
+
+	current := ""
+	for _, part := range strings.Split(key, "/") {
+		current = path.Join(current, part)
+		resp, err := b.do("MKCOL", current+"/", nil, nil)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		// 405 means the collection already exists
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+			return webdavError("MKCOL", current, resp)
+		}
+	}
+	return nil
+}
+
+// Remove deletes a file
+func (b *WebDAVBackend) Remove(p string) error {
+	key, err := b.keys.key(p)
+	if err != nil {
+		return err
+	}
+
+	resp, err := b.do(http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return &os.PathError{Op: "remove", Path: p, Err: os.ErrNotExist}
+	}
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return webdavError("DELETE", key, resp)
+	}
+	return nil
+}
+
+// Walk visits every file under root, descending one collection at a time
+func (b *WebDAVBackend) Walk(root string, fn filepath.WalkFunc) error {
+	rootKey, err := b.keys.key(root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+
+	var objects []remoteObject
+	pending := []string{rootKey}
+	for len(pending) > 0 {
+		dirKey := pending[0]
+		pending = pending[1:]
+
+		entries, err := b.propfind(dirKey, "1")
+		if err != nil {
+			return fn(b.keys.path(dirKey), nil, err)
+		}
+		for _, e := range entries {
+			if e.key == dirKey {
+				continue
+			}
+			if e.dir {
+				pending = append(pending, e.key)
+				continue
+			}
+			objects = append(objects, remoteObject{Key: e.key, Size: e.size, ModTime: e.modTime})
+		}
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return b.keys.walkObjects(root, objects, fn)
+}
+
+// davEntry is a single resource from a PROPFIND response
+type davEntry struct {
+	key     string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+// multistatus is the subset of a PROPFIND response we need
+type multistatus struct {
+	Responses []struct {
+		Href string `xml:"href"`
+		Prop struct {
+			ContentLength string `xml:"getcontentlength"`
+			LastModified  string `xml:"getlastmodified"`
+			ResourceType  struct {
+				Collection *struct{} `xml:"collection"`
+			} `xml:"resourcetype"`
+		} `xml:"propstat>prop"`
+	} `xml:"response"`
+}
+
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:"><d:prop><d:resourcetype/><d:getcontentlength/><d:getlastmodified/></d:prop></d:propfind>`
+
+// propfind lists key (depth 0) or its children (depth 1). A missing
+// resource returns nil entries and no error.
+func (b *WebDAVBackend) propfind(key, depth string) ([]davEntry, error) {
+	reqKey := key
+	if reqKey != "" && depth == "1" {
+		reqKey += "/"
+	}
+
+	headers := map[string]string{
+		"Depth":        depth,
+		"Content-Type": "application/xml",
+	}
+	resp, err := b.do("PROPFIND", reqKey, headers, []byte(propfindBody))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, webdavError("PROPFIND", key, resp)
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("failed to parse webdav listing: %w", err)
+	}
+
+	entries := make([]davEntry, 0, len(ms.Responses))
+	for _, r := range ms.Responses {
+		entryKey, ok := b.hrefKey(r.Href)
+		if !ok {
+			continue
+		}
+		size, _ := strconv.ParseInt(r.Prop.ContentLength, 10, 64)
+		modTime, _ := http.ParseTime(r.Prop.LastModified)
+		entries = append(entries, davEntry{
+			key:     entryKey,
+			size:    size,
+			modTime: modTime,
+			dir:     r.Prop.ResourceType.Collection != nil,
+		})
+	}
+	return entries, nil
+}
+
+// hrefKey converts a PROPFIND href back to a key relative to the base URL
+func (b *WebDAVBackend) hrefKey(href string) (string, bool) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	p := u.Path
+	if !strings.HasPrefix(p, b.base.Path) {
+		return "", false
+	}
+	return strings.Trim(strings.TrimPrefix(p, b.base.Path), "/"), true
+}
+
+// resolve returns the absolute URL for a key
+func (b *WebDAVBackend) resolve(key string) *url.URL {
+	return b.base.ResolveReference(&url.URL{Path: key})
+}
+
+// do sends an authenticated request for key
+func (b *WebDAVBackend) do(method, key string, headers map[string]string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, b.resolve(key).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	if b.cfg.User != "" {
+		req.SetBasicAuth(b.cfg.User, b.cfg.Password)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return b.client.Do(req)
+}
+
+// webdavError builds an error from a failed WebDAV response
+func webdavError(op, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("webdav %s %s: %s: %s", op, key, resp.Status, strings.TrimSpace(string(body)))
+}
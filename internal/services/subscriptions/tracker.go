@@ -0,0 +1,327 @@
+package subscriptions
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// cancellationsFile holds the subscription cancellations
+var cancellationsFile = storage.SettingsFile("cancellations.json", func() interface{} { return &[]models.SubscriptionCancellation{} })
+
+// candidatesFile holds the subscriptions marked to cancel
+var candidatesFile = storage.SettingsFile("cancel_candidates.json", func() interface{} { return &[]models.CancelCandidate{} })
+
+// Tracker persists subscription cancellations and the recurring payments
+// being considered for one
+type Tracker struct {
+	path           string
+	candidatesPath string
+	store          *storage.Storage
+	mu             sync.Mutex
+}
+
+// NewTracker creates a tracker storing cancellations and cancel candidates
+// in settingsDir
+func NewTracker(settingsDir string, store *storage.Storage) *Tracker {
+	return &Tracker{
+		path:           filepath.Join(settingsDir, cancellationsFile),
+		candidatesPath: filepath.Join(settingsDir, candidatesFile),
+		store:          store,
+	}
+}
+
+// List returns all recorded cancellations
+func (t *Tracker) List() ([]models.SubscriptionCancellation, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.loadInternal()
+}
+
+// Add records a cancellation, replacing any earlier one for the same
+// merchant. A cancel candidate for the payment is no longer a candidate.
+func (t *Tracker) Add(c models.SubscriptionCancellation) ([]models.SubscriptionCancellation, error) {
+	if c.Merchant == "" {
+		c.Merchant = models.MerchantKey(c.Description)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	list, err := t.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.SubscriptionCancellation, 0, len(list)+1)
+	for _, existing := range list {
+		if existing.Merchant != c.Merchant {
+			filtered = append(filtered, existing)
+		}
+	}
+	filtered = append(filtered, c)
+
+	if err := t.store.WriteJSON(t.path, filtered); err != nil {
+		return nil, err
+	}
+	if _, err := t.removeCandidates(func(cc models.CancelCandidate) bool {
+		return cc.Merchant == c.Merchant
+	}); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// Remove deletes a cancellation by ID
+func (t *Tracker) Remove(id string) ([]models.SubscriptionCancellation, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	list, err := t.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.SubscriptionCancellation, 0, len(list))
+	for _, c := range list {
+		if c.ID != id {
+			filtered = append(filtered, c)
+		}
+	}
+
+	if err := t.store.WriteJSON(t.path, filtered); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// Candidates returns the recurring payments marked as cancel candidates, in
+// the order they were marked
+func (t *Tracker) Candidates() ([]models.CancelCandidate, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.loadCandidates()
+}
+
+// AddCandidate marks a payment as a cancel candidate, replacing any earlier
+// candidate for the same merchant
+func (t *Tracker) AddCandidate(c models.CancelCandidate) ([]models.CancelCandidate, error) {
+	if c.Merchant == "" {
+		c.Merchant = models.MerchantKey(c.Description)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	list, err := t.loadCandidates()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.CancelCandidate, 0, len(list)+1)
+	for _, existing := range list {
+		if existing.Merchant != c.Merchant {
+			filtered = append(filtered, existing)
+		}
+	}
+	filtered = append(filtered, c)
+
+	if err := t.store.WriteJSON(t.candidatesPath, filtered); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// RemoveCandidate unmarks the cancel candidate with id
+func (t *Tracker) RemoveCandidate(id string) ([]models.CancelCandidate, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.removeCandidates(func(c models.CancelCandidate) bool { return c.ID == id })
+}
+
+// ClearCandidates unmarks every cancel candidate
+func (t *Tracker) ClearCandidates() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.store.WriteJSON(t.candidatesPath, []models.CancelCandidate{})
+}
+
+// removeCandidates drops the candidates match picks, writing the list only
+// when one was dropped (caller must hold lock)
+func (t *Tracker) removeCandidates(match func(models.CancelCandidate) bool) ([]models.CancelCandidate, error) {
+	list, err := t.loadCandidates()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.CancelCandidate, 0, len(list))
+	for _, c := range list {
+		if !match(c) {
+			filtered = append(filtered, c)
+		}
+	}
+	if len(filtered) == len(list) {
+		return list, nil
+	}
+
+	if err := t.store.WriteJSON(t.candidatesPath, filtered); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// loadCandidates reads cancel candidates without acquiring lock (caller
+// must hold lock)
+func (t *Tracker) loadCandidates() ([]models.CancelCandidate, error) {
+	var list []models.CancelCandidate
+	if err := t.store.ReadJSON(t.candidatesPath, &list); err != nil {
+		if os.IsNotExist(err) {
+			return []models.CancelCandidate{}, nil
+		}
+		return nil, err
+	}
+	// Candidates saved before they kept a merchant
+	for i := range list {
+		if list[i].Merchant == "" {
+			list[i].Merchant = models.MerchantKey(list[i].Description)
+		}
+	}
+	return list, nil
+}
+
+// loadInternal reads cancellations without acquiring lock (caller must hold lock)
+func (t *Tracker) loadInternal() ([]models.SubscriptionCancellation, error) {
+	var list []models.SubscriptionCancellation
+	if err := t.store.ReadJSON(t.path, &list); err != nil {
+		if os.IsNotExist(err) {
+			return []models.SubscriptionCancellation{}, nil
+		}
+		return nil, err
+	}
+	// Cancellations saved before they kept a merchant
+	for i := range list {
+		if list[i].Merchant == "" {
+			list[i].Merchant = models.MerchantKey(list[i].Description)
+		}
+	}
+	return list, nil
+}
+
+// frequencyDays returns the billing interval used to decide when a
+// cancellation can be considered verified
+func frequencyDays(frequency string) int {
+	switch frequency {
+	case "weekly":
+		return 7
+	case "biweekly":
+		return 14
+	case "quarterly":
+		return 92
+	case "yearly":
+		return 366
+	default:
+		return 31
+	}
+}
+
+// Evaluate checks each cancellation against transactions: any outflow from
+// the same merchant after the cancel date means charges continued, whatever
+// card it's on and however that card's export words it.
+// Savings accrue from the cancel date to now for cancellations that held.
+func Evaluate(cancellations []models.SubscriptionCancellation, ts *models.TransactionSet, now time.Time) models.CancellationSummary {
+	summary := models.CancellationSummary{Items: []models.CancellationStatus{}}
+
+	outflows := ts.FilterByType(models.Outflow)
+	dataEnd := ts.MaxDate()
+
+	for _, c := range cancellations {
+		status := models.CancellationStatus{SubscriptionCancellation: c}
+		merchant := c.Merchant
+		if merchant == "" {
+			merchant = models.MerchantKey(c.Description)
+		}
+
+		for _, txn := range outflows.Transactions {
+			if !txn.Date.After(c.CancelledOn) {
+				continue
+			}
+			if models.MerchantKey(txn.Description) != merchant {
+				continue
+			}
+			status.ChargesAfter++
+			if txn.Date.After(status.LastChargeDate) {
+				status.LastChargeDate = txn.Date
+			}
+		}
+
+		switch {
+		case status.ChargesAfter > 0:
+			status.Status = models.CancellationCharged
+		case !dataEnd.Before(c.CancelledOn.AddDate(0, 0, frequencyDays(c.Frequency))):
+			status.Status = models.CancellationVerified
+		default:
+			status.Status = models.CancellationPending
+		}
+
+		if status.Status != models.CancellationCharged {
+			days := now.Sub(c.CancelledOn).Hours() / 24
+			if days > 0 {
+				status.RealizedSavings = c.AnnualCost * days / 365.25
+			}
+			summary.TotalRealized += status.RealizedSavings
+			summary.TotalAnnual += c.AnnualCost
+		}
+
+		summary.Items = append(summary.Items, status)
+	}
+
+	// Most recent cancellations first
+	sort.Slice(summary.Items, func(i, j int) bool {
+		return summary.Items[i].CancelledOn.After(summary.Items[j].CancelledOn)
+	})
+
+	return summary
+}
+
+// Impact totals what cancelling every candidate would save, and the savings
+// rate over the year of data up to the latest transaction in ts as it was
+// and had the candidates' cost over that year been saved instead
+func Impact(candidates []models.CancelCandidate, ts *models.TransactionSet) models.CancellationImpact {
+	impact := models.CancellationImpact{Candidates: candidates}
+	for _, c := range candidates {
+		impact.AnnualSavings += c.AnnualCost
+	}
+	impact.MonthlySavings = impact.AnnualSavings / 12
+
+	end := ts.MaxDate()
+	if end.IsZero() {
+		return impact
+	}
+	yearStart := end.AddDate(-1, 0, 1)
+	start := yearStart
+	if first := ts.MinDate(); first.After(start) {
+		start = first
+	}
+	impact.Start = start.Format("2006-01-02")
+	impact.End = end.Format("2006-01-02")
+
+	year := ts.FilterByDateRange(start, end)
+	impact.Income = year.FilterByType(models.Income).SumAmount()
+	impact.Spending = year.FilterByType(models.Outflow).SumAbsAmount()
+	if impact.Income > 0 {
+		// Less than a year of data saves only that part of a year's cost
+		saved := impact.AnnualSavings * (end.Sub(start).Hours()/24 + 1) / (end.Sub(yearStart).Hours()/24 + 1)
+		impact.SavingsRate = (impact.Income - impact.Spending) / impact.Income * 100
+		impact.NewSavingsRate = (impact.Income - impact.Spending + saved) / impact.Income * 100
+	}
+	return impact
+}
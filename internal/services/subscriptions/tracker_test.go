@@ -0,0 +1,189 @@
+package subscriptions
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func date(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func outflow(d, desc string, amount float64) models.Transaction {
+	return models.Transaction{Date: date(d), Description: desc, Amount: -amount, TransactionType: models.Outflow}
+}
+
+func TestEvaluate(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		outflow("2025-01-05", "NETFLIX", 15.99),
+		outflow("2025-02-05", "NETFLIX", 15.99),
+		outflow("2025-01-10", "GYM", 40),
+		outflow("2025-02-10", "GYM", 40),
+		outflow("2025-03-10", "GYM", 40), // charged after cancelling
+		outflow("2025-01-20", "SPOTIFY", 10),
+		outflow("2025-04-30", "GROCERY", 80),
+	})
+
+	cancellations := []models.SubscriptionCancellation{
+		{ID: "1", Description: "netflix", Frequency: "monthly", AnnualCost: 191.88, CancelledOn: date("2025-02-06")},
+		{ID: "2", Description: "GYM", Frequency: "monthly", AnnualCost: 480, CancelledOn: date("2025-02-11")},
+		{ID: "3", Description: "SPOTIFY", Frequency: "yearly", AnnualCost: 120, CancelledOn: date("2025-04-01")},
+	}
+
+	now := date("2025-08-06")
+	summary := Evaluate(cancellations, ts, now)
+
+	status := make(map[string]models.CancellationStatus)
+	for _, item := range summary.Items {
+		status[item.ID] = item
+	}
+
+	if got := status["1"].Status; got != models.CancellationVerified {
+		t.Errorf("netflix status = %q, want verified", got)
+	}
+	if got := status["2"]; got.Status != models.CancellationCharged || got.ChargesAfter != 1 || got.RealizedSavings != 0 {
+		t.Errorf("gym = %+v, want charged once with no savings", got)
+	}
+	if got := status["3"].Status; got != models.CancellationPending {
+		t.Errorf("spotify status = %q, want pending (data ends before a yearly cycle)", got)
+	}
+
+	// Netflix: 181 days at 191.88/year
+	wantNetflix := 191.88 * 181 / 365.25
+	if diff := status["1"].RealizedSavings - wantNetflix; diff > 0.01 || diff < -0.01 {
+		t.Errorf("netflix savings = %.2f, want %.2f", status["1"].RealizedSavings, wantNetflix)
+	}
+
+	if summary.TotalAnnual != 191.88+120 {
+		t.Errorf("TotalAnnual = %v, want %v", summary.TotalAnnual, 191.88+120)
+	}
+}
+
+func TestEvaluateAcrossCards(t *testing.T) {
+	// Cancelled on the Visa, where the export says "NETFLIX.COM", but the
+	// subscription moved to the Amex, which words it differently
+	visa := outflow("2025-01-15", "NETFLIX.COM", 15.49)
+	visa.Account = "Visa"
+	amex := outflow("2025-03-15", "Netflix.com 866-579-7172 CA", 15.49)
+	amex.Account = "Amex"
+	ts := models.NewTransactionSet([]models.Transaction{visa, amex, outflow("2025-06-30", "GROCERY", 80)})
+
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	tracker := NewTracker(dir, store)
+	list, err := tracker.Add(models.SubscriptionCancellation{ID: "1", Description: "netflix.com", Frequency: "monthly", CancelledOn: date("2025-02-01")})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if list[0].Merchant != "netflix" {
+		t.Errorf("Merchant = %q, want netflix", list[0].Merchant)
+	}
+
+	got := Evaluate(list, ts, date("2025-07-01")).Items[0]
+	if got.Status != models.CancellationCharged || got.ChargesAfter != 1 || !got.LastChargeDate.Equal(date("2025-03-15")) {
+		t.Errorf("status = %+v, want charged on the Amex on 2025-03-15", got)
+	}
+}
+
+func TestTrackerAddReplacesAndRemoves(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	tracker := NewTracker(dir, store)
+
+	if list, err := tracker.List(); err != nil || len(list) != 0 {
+		t.Fatalf("List() on empty tracker = %v, %v", list, err)
+	}
+
+	tracker.Add(models.SubscriptionCancellation{ID: "a", Description: "Netflix", CancelledOn: date("2025-01-01")})
+	list, err := tracker.Add(models.SubscriptionCancellation{ID: "b", Description: "NETFLIX", CancelledOn: date("2025-02-01")})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "b" {
+		t.Errorf("re-cancelling the same subscription should replace it, got %+v", list)
+	}
+
+	list, _ = tracker.Remove("b")
+	if len(list) != 0 {
+		t.Errorf("Remove left %+v", list)
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	tracker := NewTracker(dir, store)
+
+	tracker.AddCandidate(models.CancelCandidate{ID: "a", Description: "netflix", AnnualCost: 191.88})
+	tracker.AddCandidate(models.CancelCandidate{ID: "b", Description: "gym", AnnualCost: 480})
+	list, err := tracker.AddCandidate(models.CancelCandidate{ID: "c", Description: "GYM", AnnualCost: 600})
+	if err != nil {
+		t.Fatalf("AddCandidate failed: %v", err)
+	}
+	if len(list) != 2 || list[1].ID != "c" {
+		t.Errorf("marking the same payment again should replace it, got %+v", list)
+	}
+
+	// Cancelling a candidate takes it off the list
+	tracker.Add(models.SubscriptionCancellation{ID: "x", Description: "Netflix", CancelledOn: date("2025-03-01")})
+	if list, _ := tracker.Candidates(); len(list) != 1 || list[0].ID != "c" {
+		t.Errorf("candidates after cancelling netflix = %+v, want only the gym", list)
+	}
+
+	if list, _ := tracker.RemoveCandidate("c"); len(list) != 0 {
+		t.Errorf("RemoveCandidate left %+v", list)
+	}
+	tracker.AddCandidate(models.CancelCandidate{ID: "d", Description: "spotify"})
+	if err := tracker.ClearCandidates(); err != nil {
+		t.Fatalf("ClearCandidates failed: %v", err)
+	}
+	if list, _ := tracker.Candidates(); len(list) != 0 {
+		t.Errorf("candidates after clearing = %+v", list)
+	}
+}
+
+func TestImpact(t *testing.T) {
+	income := func(d string, amount float64) models.Transaction {
+		return models.Transaction{Date: date(d), Description: "PAYROLL", Amount: amount, TransactionType: models.Income}
+	}
+	candidates := []models.CancelCandidate{
+		{Description: "netflix", AnnualCost: 240},
+		{Description: "gym", AnnualCost: 480},
+	}
+
+	// A full year: $10,000 in, $8,000 out
+	year := models.NewTransactionSet([]models.Transaction{
+		income("2024-07-01", 5000),
+		outflow("2024-07-02", "RENT", 8000),
+		income("2025-06-30", 5000),
+	})
+	impact := Impact(candidates, year)
+	if impact.AnnualSavings != 720 || impact.MonthlySavings != 60 {
+		t.Errorf("savings = %.2f/year, %.2f/month, want 720 and 60", impact.AnnualSavings, impact.MonthlySavings)
+	}
+	if impact.Start != "2024-07-01" || impact.End != "2025-06-30" || impact.SavingsRate != 20 {
+		t.Errorf("impact = %+v, want a 20%% savings rate over the year to 2025-06-30", impact)
+	}
+	if diff := impact.NewSavingsRate - 27.2; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("new savings rate = %v, want 27.2 with $720 more saved", impact.NewSavingsRate)
+	}
+
+	// Half a year of data saves half a year's cost
+	half := models.NewTransactionSet([]models.Transaction{
+		income("2025-01-01", 10000),
+		outflow("2025-07-02", "RENT", 8000),
+	})
+	impact = Impact(candidates, half)
+	want := 20 + 720*float64(183)/365/100
+	if diff := impact.NewSavingsRate - want; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("new savings rate over half a year = %v, want %v", impact.NewSavingsRate, want)
+	}
+
+	if impact := Impact(candidates, models.NewTransactionSet(nil)); impact.AnnualSavings != 720 || impact.SavingsRate != 0 {
+		t.Errorf("impact without data = %+v", impact)
+	}
+}
@@ -0,0 +1,115 @@
+package tags
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// tagsFile holds the tags by transaction
+var tagsFile = storage.SettingsFile("tags.json", func() interface{} { return &map[string]models.TransactionTags{} })
+
+// Manager persists the tags the user put on transactions, keyed by
+// transaction hash, and puts them back on while data loads
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing tags in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, tagsFile),
+		store: store,
+	}
+}
+
+// List returns the tags by transaction hash
+func (m *Manager) List() (map[string]models.TransactionTags, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Set replaces the tags of the transaction with the given hash. Tags are
+// normalized and deduplicated; none left removes the entry.
+func (m *Manager) Set(hash string, tags []string) error {
+	if hash == "" {
+		return fmt.Errorf("transaction has no hash")
+	}
+
+	var kept []string
+	seen := make(map[string]bool)
+	for _, t := range tags {
+		if t = models.NormalizeTag(t); t != "" && !seen[t] {
+			seen[t] = true
+			kept = append(kept, t)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	all, err := m.loadInternal()
+	if err != nil {
+		return err
+	}
+	if len(kept) == 0 {
+		delete(all, hash)
+	} else {
+		sort.Strings(kept)
+		all[hash] = models.TransactionTags{Tags: kept, UpdatedAt: time.Now()}
+	}
+	return m.store.WriteJSON(m.path, all)
+}
+
+// Version changes whenever the tags do, so the loader reloads data
+func (m *Manager) Version() string {
+	info, err := m.store.Stat(m.path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("tags|%d|%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// Enrich puts the saved tags on freshly loaded transactions. It runs before
+// splitting, so each share of a split transaction carries its tags.
+// Transactions are returned unchanged if the tags can't be read.
+func (m *Manager) Enrich(transactions []models.Transaction) []models.Transaction {
+	all, err := m.List()
+	if err != nil {
+		log.Printf("Warning: failed to load tags: %v", err)
+		return transactions
+	}
+	if len(all) == 0 {
+		return transactions
+	}
+
+	for i := range transactions {
+		if t, ok := all[transactions[i].Hash]; ok {
+			transactions[i].Tags = t.Tags
+		}
+	}
+	return transactions
+}
+
+// loadInternal reads the tags without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() (map[string]models.TransactionTags, error) {
+	all := make(map[string]models.TransactionTags)
+	if err := m.store.ReadJSON(m.path, &all); err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]models.TransactionTags), nil
+		}
+		return nil, err
+	}
+	return all, nil
+}
@@ -0,0 +1,54 @@
+package tags
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func txn(desc string, amount float64) models.Transaction {
+	d, _ := time.Parse("2006-01-02", "2024-03-01")
+	t := models.Transaction{Date: d, Description: desc, Amount: amount}
+	t.Hash = t.ComputeHash()
+	return t
+}
+
+func TestSetAndEnrich(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	if v := manager.Version(); v != "" {
+		t.Errorf("version without tags = %q, want empty", v)
+	}
+
+	hotel := txn("MARRIOTT MAUI", -640)
+	if err := manager.Set(hotel.Hash, []string{"Vacation2024", " #reimbursable", "vacation2024", ""}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	v1 := manager.Version()
+
+	// A reloaded file gets the tags; other rows are untouched
+	loaded := manager.Enrich([]models.Transaction{hotel, txn("SAFEWAY", -80)})
+	if got := loaded[0].Tags; len(got) != 2 || got[0] != "reimbursable" || got[1] != "vacation2024" {
+		t.Errorf("tagged row tags = %q, want reimbursable and vacation2024", got)
+	}
+	if loaded[1].Tags != nil {
+		t.Errorf("other row tags = %q, want none", loaded[1].Tags)
+	}
+
+	// No tags left removes the entry
+	manager.Set(hotel.Hash, []string{" "})
+	if list, _ := manager.List(); len(list) != 0 {
+		t.Errorf("tags = %+v, want none", list)
+	}
+	if manager.Version() == v1 {
+		t.Error("version should change when tags change")
+	}
+
+	if err := manager.Set("", []string{"x"}); err == nil {
+		t.Error("Set should reject a transaction without a hash")
+	}
+}
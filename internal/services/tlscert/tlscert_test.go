@@ -0,0 +1,51 @@
+package tlscert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEnsureSelfSigned(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls", "cert.pem")
+	keyFile := filepath.Join(dir, "tls", "key.pem")
+
+	generated, err := EnsureSelfSigned(certFile, keyFile)
+	if err != nil || !generated {
+		t.Fatalf("first call: generated=%v err=%v, want a new certificate", generated, err)
+	}
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("generated pair doesn't load: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(pair.Certificate[0])
+	if err := cert.VerifyHostname("localhost"); err != nil {
+		t.Errorf("certificate doesn't cover localhost: %v", err)
+	}
+	if err := cert.VerifyHostname("127.0.0.1"); err != nil {
+		t.Errorf("certificate doesn't cover 127.0.0.1: %v", err)
+	}
+	if info, _ := os.Stat(keyFile); info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// A valid certificate is kept
+	if generated, err := EnsureSelfSigned(certFile, keyFile); err != nil || generated {
+		t.Errorf("second call: generated=%v err=%v, want the existing certificate kept", generated, err)
+	}
+
+	// One about to expire is replaced
+	certPEM, keyPEM, err := Generate([]string{"localhost"}, time.Now().Add(-360*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(certFile, certPEM, 0644)
+	os.WriteFile(keyFile, keyPEM, 0600)
+	if generated, err := EnsureSelfSigned(certFile, keyFile); err != nil || !generated {
+		t.Errorf("expiring certificate: generated=%v err=%v, want it replaced", generated, err)
+	}
+}
@@ -0,0 +1,374 @@
+package txstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"budget2/internal/models"
+)
+
+var (
+	// filesBucket maps a data file name to its FileRecord
+	filesBucket = []byte("files")
+	// rowsBucket holds one nested bucket per data file, keyed by row number
+	// so rows come back in file order
+	rowsBucket = []byte("rows")
+	// byDateBucket indexes the loaded transactions by date: keys are
+	// "YYYY-MM-DD/<hash>/<row>", so a date range is one cursor walk, and
+	// values are the transactions
+	byDateBucket = []byte("by_date")
+	// byCategoryBucket holds one nested bucket per lowercased category
+	// path, mapping the by_date keys of its transactions to nothing
+	byCategoryBucket = []byte("by_category")
+	// metaBucket records which data version the indexes hold
+	metaBucket = []byte("meta")
+	// indexedKey is the meta key holding the indexed data version
+	indexedKey = []byte("indexed_version")
+)
+
+// FileRecord describes the ingested copy of one data file
+type FileRecord struct {
+	Name         string    `json:"name"`
+	Fingerprint  string    `json:"fingerprint"`
+	Transactions int       `json:"transactions"`
+	IngestedAt   time.Time `json:"ingested_at"`
+}
+
+// Store keeps the parsed rows of each data file in a bbolt database, so
+// files are parsed once rather than on every start or data change. Rows
+// are stored as parsed, before transfer filtering, classification and
+// deduplication, since those depend on which other files are loaded.
+// Alongside them it indexes the loaded transactions, after all of that, by
+// date and by category for Range and Category lookups.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens or creates the database at path. Only one process can hold it,
+// so opening fails after a short wait if the server already has it open.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening transaction database %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{filesBucket, rowsBucket, byDateBucket, byCategoryBucket, metaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Get returns the stored rows for file if they were ingested from the same
+// fingerprint; ok is false when the file is missing or has changed
+func (s *Store) Get(file, fingerprint string) (transactions []models.Transaction, ok bool, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		record, found := fileRecord(tx, file)
+		if !found || record.Fingerprint != fingerprint {
+			return nil
+		}
+		rows := tx.Bucket(rowsBucket).Bucket([]byte(file))
+		if rows == nil {
+			return nil
+		}
+
+		transactions = make([]models.Transaction, 0, record.Transactions)
+		err := rows.ForEach(func(_, v []byte) error {
+			var t models.Transaction
+			if err := json.Unmarshal(v, &t); err != nil {
+				return err
+			}
+			transactions = append(transactions, t)
+			return nil
+		})
+		ok = err == nil
+		return err
+	})
+	return transactions, ok, err
+}
+
+// Put replaces file's stored rows with transactions parsed at fingerprint
+func (s *Store) Put(file, fingerprint string, transactions []models.Transaction) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		rowsRoot := tx.Bucket(rowsBucket)
+		if rowsRoot.Bucket([]byte(file)) != nil {
+			if err := rowsRoot.DeleteBucket([]byte(file)); err != nil {
+				return err
+			}
+		}
+		rows, err := rowsRoot.CreateBucket([]byte(file))
+		if err != nil {
+			return err
+		}
+
+		key := make([]byte, 4)
+		for i, t := range transactions {
+			v, err := json.Marshal(t)
+			if err != nil {
+				return err
+			}
+			binary.BigEndian.PutUint32(key, uint32(i))
+			if err := rows.Put(key, v); err != nil {
+				return err
+			}
+		}
+
+		record, err := json.Marshal(FileRecord{
+			Name:         file,
+			Fingerprint:  fingerprint,
+			Transactions: len(transactions),
+			IngestedAt:   time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(filesBucket).Put([]byte(file), record)
+	})
+}
+
+// Files lists the ingested files in name order
+func (s *Store) Files() ([]FileRecord, error) {
+	var records []FileRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(filesBucket).ForEach(func(_, v []byte) error {
+			var r FileRecord
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			records = append(records, r)
+			return nil
+		})
+	})
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	return records, err
+}
+
+// Prune removes files not in keep, returning how many were removed. It only
+// writes to the database when something is stale.
+func (s *Store) Prune(keep []string) (int, error) {
+	wanted := make(map[string]bool, len(keep))
+	for _, name := range keep {
+		wanted[name] = true
+	}
+
+	var stale [][]byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(filesBucket).ForEach(func(k, _ []byte) error {
+			if !wanted[string(k)] {
+				stale = append(stale, append([]byte(nil), k...))
+			}
+			return nil
+		})
+	})
+	if err != nil || len(stale) == 0 {
+		return 0, err
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		for _, k := range stale {
+			if err := deleteFile(tx, k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(stale), nil
+}
+
+// Destroy closes the database and deletes its file, e.g. once data is
+// encrypted and plain copies of it must not linger on disk
+func (s *Store) Destroy() error {
+	path := s.db.Path()
+	if err := s.db.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// fileRecord reads file's record within a transaction
+func fileRecord(tx *bolt.Tx, file string) (FileRecord, bool) {
+	v := tx.Bucket(filesBucket).Get([]byte(file))
+	if v == nil {
+		return FileRecord{}, false
+	}
+	var r FileRecord
+	if err := json.Unmarshal(v, &r); err != nil {
+		return FileRecord{}, false
+	}
+	return r, true
+}
+
+// deleteFile removes a file's record and rows
+func deleteFile(tx *bolt.Tx, file []byte) error {
+	if err := tx.Bucket(filesBucket).Delete(file); err != nil {
+		return err
+	}
+	if tx.Bucket(rowsBucket).Bucket(file) != nil {
+		return tx.Bucket(rowsBucket).DeleteBucket(file)
+	}
+	return nil
+}
+
+// Index replaces the date and category indexes with transactions, the
+// loaded set for data version
+func (s *Store) Index(version string, transactions []models.Transaction) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{byDateBucket, byCategoryBucket} {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		byDate := tx.Bucket(byDateBucket)
+		byCategory := tx.Bucket(byCategoryBucket)
+
+		for i, t := range transactions {
+			key := []byte(fmt.Sprintf("%s/%s/%08d", t.Date.Format("2006-01-02"), t.Hash, i))
+			v, err := json.Marshal(t)
+			if err != nil {
+				return err
+			}
+			if err := byDate.Put(key, v); err != nil {
+				return err
+			}
+			category, err := byCategory.CreateBucketIfNotExists([]byte(categoryKey(t.Category)))
+			if err != nil {
+				return err
+			}
+			if err := category.Put(key, nil); err != nil {
+				return err
+			}
+		}
+		return tx.Bucket(metaBucket).Put(indexedKey, []byte(version))
+	})
+}
+
+// IndexedVersion returns the data version the indexes hold, or "" before
+// anything is indexed
+func (s *Store) IndexedVersion() (string, error) {
+	var version string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		version = string(tx.Bucket(metaBucket).Get(indexedKey))
+		return nil
+	})
+	return version, err
+}
+
+// Range returns the indexed transactions dated start through end, oldest
+// first. ok is false when the indexes hold another data version.
+func (s *Store) Range(version string, start, end time.Time) (transactions []models.Transaction, ok bool, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		if string(tx.Bucket(metaBucket).Get(indexedKey)) != version {
+			return nil
+		}
+		ok = true
+		from, to := dateBounds(start, end)
+		c := tx.Bucket(byDateBucket).Cursor()
+		for k, v := c.Seek(from); k != nil && string(k) < to; k, v = c.Next() {
+			var t models.Transaction
+			if err := json.Unmarshal(v, &t); err != nil {
+				return err
+			}
+			transactions = append(transactions, t)
+		}
+		return nil
+	})
+	return transactions, ok, err
+}
+
+// Categories returns the indexed transactions in any of categories or
+// their subcategories, ignoring case, dated start through end and oldest
+// first. ok is false when the indexes hold another data version.
+func (s *Store) Categories(version string, categories []string, start, end time.Time) (transactions []models.Transaction, ok bool, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		if string(tx.Bucket(metaBucket).Get(indexedKey)) != version {
+			return nil
+		}
+		ok = true
+
+		// Each category's own bucket, then its subcategories', which sort
+		// together after the parent's path and a separator
+		byCategory := tx.Bucket(byCategoryBucket)
+		var buckets []*bolt.Bucket
+		for _, category := range categories {
+			parent := categoryKey(category)
+			buckets = append(buckets, byCategory.Bucket([]byte(parent)))
+			prefix := []byte(parent + models.CategorySeparator)
+			c := byCategory.Cursor()
+			for name, _ := c.Seek(prefix); name != nil && bytes.HasPrefix(name, prefix); name, _ = c.Next() {
+				buckets = append(buckets, byCategory.Bucket(name))
+			}
+		}
+
+		// A category and its parent can both be asked for, so keys are
+		// gathered as a set
+		found := make(map[string]bool)
+		from, to := dateBounds(start, end)
+		for _, b := range buckets {
+			if b == nil {
+				continue
+			}
+			rows := b.Cursor()
+			for k, _ := rows.Seek(from); k != nil && string(k) < to; k, _ = rows.Next() {
+				found[string(k)] = true
+			}
+		}
+		keys := make([]string, 0, len(found))
+		for k := range found {
+			keys = append(keys, k)
+		}
+
+		byDate := tx.Bucket(byDateBucket)
+		sort.Strings(keys)
+		for _, k := range keys {
+			var t models.Transaction
+			if err := json.Unmarshal(byDate.Get([]byte(k)), &t); err != nil {
+				return err
+			}
+			transactions = append(transactions, t)
+		}
+		return nil
+	})
+	return transactions, ok, err
+}
+
+// categoryKey is the category bucket name for a category: its lowercased
+// path, so Food and food share a bucket and Food:Groceries sorts under Food
+func categoryKey(category string) string {
+	return strings.ToLower(strings.Join(models.CategoryPath(category), models.CategorySeparator))
+}
+
+// dateBounds returns the index keys from start's date up to, but not
+// including, the day after end
+func dateBounds(start, end time.Time) (from []byte, to string) {
+	return []byte(start.Format("2006-01-02")), end.AddDate(0, 0, 1).Format("2006-01-02")
+}
@@ -0,0 +1,164 @@
+package txstore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+)
+
+func TestPutGet(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "cache", "transactions.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer s.Close()
+
+	date := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	rows := []models.Transaction{
+		{Date: date, Description: "Zebra Cafe", Amount: -4.5, SourceFile: "checking.csv"},
+		{Date: date.AddDate(0, 0, -2), Description: "Apple Store", Amount: -99, SourceFile: "checking.csv"},
+	}
+	if err := s.Put("checking.csv", "v1", rows); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	got, ok, err := s.Get("checking.csv", "v1")
+	if err != nil || !ok {
+		t.Fatalf("Get = ok %v, err %v; want stored rows", ok, err)
+	}
+	if len(got) != 2 || got[0].Description != "Zebra Cafe" || !got[1].Date.Equal(rows[1].Date) {
+		t.Errorf("rows should round-trip in file order, got %+v", got)
+	}
+
+	if _, ok, _ := s.Get("checking.csv", "v2"); ok {
+		t.Error("a changed fingerprint should miss")
+	}
+	if _, ok, _ := s.Get("savings.csv", "v1"); ok {
+		t.Error("an unknown file should miss")
+	}
+
+	// Replacing drops the old rows
+	if err := s.Put("checking.csv", "v2", rows[:1]); err != nil {
+		t.Fatalf("second Put failed: %v", err)
+	}
+	if got, _, _ := s.Get("checking.csv", "v2"); len(got) != 1 {
+		t.Errorf("expected 1 row after replacing, got %d", len(got))
+	}
+}
+
+func TestPruneAndDestroy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transactions.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	s.Put("a.csv", "v1", []models.Transaction{{Description: "A"}})
+	s.Put("b.qif", "v1", nil)
+
+	removed, err := s.Prune([]string{"b.qif"})
+	if err != nil || removed != 1 {
+		t.Fatalf("Prune = %d, %v; want 1 removed", removed, err)
+	}
+	files, _ := s.Files()
+	if len(files) != 1 || files[0].Name != "b.qif" || files[0].Transactions != 0 {
+		t.Errorf("expected only b.qif to remain, got %+v", files)
+	}
+	if removed, _ := s.Prune([]string{"b.qif"}); removed != 0 {
+		t.Errorf("nothing stale should be pruned, got %d", removed)
+	}
+
+	if err := s.Destroy(); err != nil {
+		t.Fatalf("Destroy failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("database file should be deleted, stat err = %v", err)
+	}
+}
+
+func TestIndexLookups(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "transactions.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer s.Close()
+
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	loaded := []models.Transaction{
+		{Date: day(9), Description: "Kroger", Category: "Food:Groceries", Hash: "a"},
+		{Date: day(1), Description: "Rent", Category: "Housing", Hash: "b"},
+		{Date: day(5), Description: "Diner", Category: "food : Restaurants", Hash: "c"},
+		{Date: day(5), Description: "Food truck", Category: "Food Court", Hash: "d"},
+		{Date: day(7), Description: "Snacks", Category: "Food", Hash: "e"},
+		{Date: day(8), Description: "Mystery", Hash: "f"},
+		{Date: day(9), Description: "Kroger", Category: "Food:Groceries", Hash: "a"},
+	}
+
+	if _, ok, _ := s.Range("v1", day(1), day(31)); ok {
+		t.Error("nothing is indexed yet, lookups should miss")
+	}
+	if err := s.Index("v1", loaded); err != nil {
+		t.Fatalf("Index failed: %v", err)
+	}
+	if v, _ := s.IndexedVersion(); v != "v1" {
+		t.Errorf("IndexedVersion = %q, want v1", v)
+	}
+
+	descriptions := func(ts []models.Transaction) string {
+		var names []string
+		for _, t := range ts {
+			names = append(names, t.Description)
+		}
+		return strings.Join(names, ",")
+	}
+
+	got, ok, err := s.Range("v1", day(5), day(8))
+	if err != nil || !ok {
+		t.Fatalf("Range = ok %v, err %v", ok, err)
+	}
+	// Both ends are included, oldest first
+	if d := descriptions(got); d != "Diner,Food truck,Snacks,Mystery" {
+		t.Errorf("Range(5th-8th) = %s", d)
+	}
+	if got, _, _ := s.Range("v1", day(9), day(9)); len(got) != 2 {
+		t.Errorf("Range(9th) should keep both rows with the same hash, got %d", len(got))
+	}
+
+	tests := []struct {
+		category string
+		want     string
+	}{
+		{"Food", "Diner,Snacks,Kroger,Kroger"},
+		{"FOOD:groceries", "Kroger,Kroger"},
+		{"Food Court", "Food truck"},
+		{"Uncategorized", "Mystery"},
+		{"Travel", ""},
+	}
+	for _, tt := range tests {
+		got, ok, err := s.Categories("v1", []string{tt.category}, day(1), day(31))
+		if err != nil || !ok {
+			t.Fatalf("Categories(%q) = ok %v, err %v", tt.category, ok, err)
+		}
+		if d := descriptions(got); d != tt.want {
+			t.Errorf("Categories(%q) = %s, want %s", tt.category, d, tt.want)
+		}
+	}
+	if got, _, _ := s.Categories("v1", []string{"Food", "Food:Groceries"}, day(6), day(9)); descriptions(got) != "Snacks,Kroger,Kroger" {
+		t.Errorf("Categories(Food and Food:Groceries, 6th-9th) = %s, want each row once", descriptions(got))
+	}
+
+	// Reindexing replaces everything, and the old version misses
+	if err := s.Index("v2", loaded[:1]); err != nil {
+		t.Fatalf("second Index failed: %v", err)
+	}
+	if _, ok, _ := s.Categories("v1", []string{"Food"}, day(1), day(31)); ok {
+		t.Error("lookups for a replaced version should miss")
+	}
+	if got, _, _ := s.Categories("v2", []string{"Housing"}, day(1), day(31)); len(got) != 0 {
+		t.Errorf("reindexing should drop old categories, got %d rows", len(got))
+	}
+}
@@ -0,0 +1,209 @@
+package visits
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/analytics"
+	"budget2/internal/services/storage"
+)
+
+// visitsFile holds the page visit log
+var visitsFile = storage.SettingsFile("visits.json", func() interface{} { return &models.VisitLog{} })
+
+// VisitGap is how long the dashboard must go unseen before the next load
+// counts as a new visit. Reloads within a visit keep the same baseline.
+const VisitGap = time.Hour
+
+// MaxNewTransactions caps the transactions listed in the changes panel
+const MaxNewTransactions = 10
+
+// Tracker persists dashboard snapshots between visits
+type Tracker struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewTracker creates a tracker storing snapshots in settingsDir
+func NewTracker(settingsDir string, store *storage.Storage) *Tracker {
+	return &Tracker{
+		path:  filepath.Join(settingsDir, visitsFile),
+		store: store,
+	}
+}
+
+// Record saves current as the latest snapshot and returns the baseline to
+// compare it with, or nil on the first visit. When more than VisitGap has
+// passed since the latest snapshot, that snapshot becomes the new baseline.
+func (t *Tracker) Record(current models.VisitSnapshot) (*models.VisitSnapshot, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	log, err := t.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	if log.Latest != nil && current.TakenAt.Sub(log.Latest.TakenAt) >= VisitGap {
+		log.Previous = log.Latest
+	}
+	log.Latest = &current
+
+	if err := t.store.WriteJSON(t.path, log); err != nil {
+		return nil, err
+	}
+	return log.Previous, nil
+}
+
+// Dismiss marks the latest snapshot as seen, clearing the changes panel
+// until something changes again
+func (t *Tracker) Dismiss() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	log, err := t.loadInternal()
+	if err != nil {
+		return err
+	}
+	if log.Latest == nil {
+		return nil
+	}
+	log.Previous = log.Latest
+	return t.store.WriteJSON(t.path, log)
+}
+
+// loadInternal reads the visit log without acquiring lock (caller must hold lock)
+func (t *Tracker) loadInternal() (models.VisitLog, error) {
+	var log models.VisitLog
+	if err := t.store.ReadJSON(t.path, &log); err != nil {
+		if os.IsNotExist(err) {
+			return models.VisitLog{}, nil
+		}
+		return models.VisitLog{}, err
+	}
+	return log, nil
+}
+
+// alertKey identifies an alert across visits
+func alertKey(a models.SpendingAlert) string {
+	key := a.Type + "|" + a.Title
+	if a.Date != nil {
+		key += "|" + a.Date.Format("2006-01-02")
+	}
+	return key
+}
+
+// transactionHash returns the dedupe hash, computing it if the loader didn't
+func transactionHash(t models.Transaction) string {
+	if t.Hash != "" {
+		return t.Hash
+	}
+	return t.ComputeHash()
+}
+
+// TakeSnapshot captures the dashboard's KPIs, category spending and alerts for
+// ts (the dashboard's default range) and the hashes of every transaction in
+// all, so later visits can tell which transactions are new
+func TakeSnapshot(all, ts *models.TransactionSet, alerts []models.SpendingAlert, now time.Time) models.VisitSnapshot {
+	metrics := analytics.CalculateMetrics(ts)
+	snap := models.VisitSnapshot{
+		TakenAt:          now,
+		TotalIncome:      metrics.TotalIncome,
+		TotalExpenses:    metrics.TotalExpenses,
+		NetSavings:       metrics.NetSavings,
+		SavingsRate:      metrics.SavingsRate,
+		TransactionCount: metrics.TransactionCount,
+		CategoryTotals:   ts.FilterByType(models.Outflow).CategoryTotals(),
+	}
+
+	for _, a := range alerts {
+		snap.AlertKeys = append(snap.AlertKeys, alertKey(a))
+	}
+	for _, t := range all.Transactions {
+		snap.TransactionHashes = append(snap.TransactionHashes, transactionHash(t))
+	}
+	return snap
+}
+
+// Diff compares the current data against a baseline snapshot. all is the
+// full transaction set and alerts are the current alerts; both are needed to
+// list what's new rather than just count it.
+func Diff(previous, current models.VisitSnapshot, all *models.TransactionSet, alerts []models.SpendingAlert) *models.VisitChanges {
+	changes := &models.VisitChanges{
+		Since:           previous.TakenAt,
+		NewTransactions: []models.Transaction{},
+		NewAlerts:       []models.SpendingAlert{},
+		MetricDeltas:    []models.MetricDelta{},
+		CategoryDeltas:  []models.MetricDelta{},
+	}
+
+	seen := make(map[string]bool, len(previous.TransactionHashes))
+	for _, h := range previous.TransactionHashes {
+		seen[h] = true
+	}
+	var added []models.Transaction
+	for _, t := range all.Transactions {
+		if seen[transactionHash(t)] {
+			continue
+		}
+		added = append(added, t)
+		if t.TransactionType == models.Income {
+			changes.NewIncome += t.Amount
+		} else {
+			changes.NewSpending += math.Abs(t.Amount)
+		}
+	}
+	sort.Slice(added, func(i, j int) bool {
+		return added[i].Date.After(added[j].Date)
+	})
+	changes.NewTransactionCount = len(added)
+	if len(added) > MaxNewTransactions {
+		added = added[:MaxNewTransactions]
+	}
+	changes.NewTransactions = append(changes.NewTransactions, added...)
+
+	seenAlerts := make(map[string]bool, len(previous.AlertKeys))
+	for _, k := range previous.AlertKeys {
+		seenAlerts[k] = true
+	}
+	for _, a := range alerts {
+		if !seenAlerts[alertKey(a)] {
+			changes.NewAlerts = append(changes.NewAlerts, a)
+		}
+	}
+
+	addDelta := func(list []models.MetricDelta, name string, prev, cur float64, percent bool) []models.MetricDelta {
+		// Ignore rounding noise
+		if math.Abs(cur-prev) < 0.005 {
+			return list
+		}
+		return append(list, models.MetricDelta{Name: name, Previous: prev, Current: cur, Change: cur - prev, Percent: percent})
+	}
+	changes.MetricDeltas = addDelta(changes.MetricDeltas, "Income", previous.TotalIncome, current.TotalIncome, false)
+	changes.MetricDeltas = addDelta(changes.MetricDeltas, "Expenses", previous.TotalExpenses, current.TotalExpenses, false)
+	changes.MetricDeltas = addDelta(changes.MetricDeltas, "Net Savings", previous.NetSavings, current.NetSavings, false)
+	changes.MetricDeltas = addDelta(changes.MetricDeltas, "Savings Rate", previous.SavingsRate, current.SavingsRate, true)
+
+	categories := make(map[string]bool)
+	for name := range previous.CategoryTotals {
+		categories[name] = true
+	}
+	for name := range current.CategoryTotals {
+		categories[name] = true
+	}
+	for name := range categories {
+		changes.CategoryDeltas = addDelta(changes.CategoryDeltas, name, previous.CategoryTotals[name], current.CategoryTotals[name], false)
+	}
+	// Biggest movers first
+	sort.Slice(changes.CategoryDeltas, func(i, j int) bool {
+		return math.Abs(changes.CategoryDeltas[i].Change) > math.Abs(changes.CategoryDeltas[j].Change)
+	})
+
+	return changes
+}
@@ -0,0 +1,86 @@
+package visits
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func txn(date, desc, category string, amount float64) models.Transaction {
+	d, _ := time.Parse("2006-01-02", date)
+	t := models.Transaction{Date: d, Description: desc, Category: category, Amount: amount, TransactionType: models.Outflow}
+	if amount > 0 {
+		t.TransactionType = models.Income
+	}
+	t.Hash = t.ComputeHash()
+	return t
+}
+
+func TestRecordRotatesBaselineBetweenVisits(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	tracker := NewTracker(dir, store)
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	if prev, err := tracker.Record(models.VisitSnapshot{TakenAt: start, TotalIncome: 1}); err != nil || prev != nil {
+		t.Fatalf("first visit should have no baseline, got %+v, %v", prev, err)
+	}
+
+	// A reload within the same visit keeps comparing against nothing
+	if prev, _ := tracker.Record(models.VisitSnapshot{TakenAt: start.Add(10 * time.Minute), TotalIncome: 2}); prev != nil {
+		t.Errorf("reload within a visit should not create a baseline, got %+v", prev)
+	}
+
+	// The next day the last snapshot of the previous visit becomes the baseline
+	prev, _ := tracker.Record(models.VisitSnapshot{TakenAt: start.Add(24 * time.Hour), TotalIncome: 3})
+	if prev == nil || prev.TotalIncome != 2 {
+		t.Fatalf("baseline = %+v, want the snapshot with income 2", prev)
+	}
+
+	// Dismissing makes the latest snapshot the baseline
+	if err := tracker.Dismiss(); err != nil {
+		t.Fatalf("Dismiss failed: %v", err)
+	}
+	prev, _ = tracker.Record(models.VisitSnapshot{TakenAt: start.Add(24*time.Hour + time.Minute), TotalIncome: 3})
+	if prev == nil || prev.TotalIncome != 3 {
+		t.Errorf("after dismiss baseline = %+v, want the snapshot with income 3", prev)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := models.NewTransactionSet([]models.Transaction{
+		txn("2025-03-01", "PAYROLL", "Paycheck", 3000),
+		txn("2025-03-02", "GROCERY", "Groceries", -100),
+	})
+	after := models.NewTransactionSet(append(append([]models.Transaction{}, before.Transactions...),
+		txn("2025-03-05", "GROCERY", "Groceries", -50),
+		txn("2025-03-06", "CINEMA", "Entertainment", -20),
+	))
+
+	oldAlert := models.SpendingAlert{Type: "large_transaction", Title: "Large purchase"}
+	newAlert := models.SpendingAlert{Type: "unusual_day", Title: "Unusual spending"}
+
+	previous := TakeSnapshot(before, before, []models.SpendingAlert{oldAlert}, time.Now())
+	current := TakeSnapshot(after, after, []models.SpendingAlert{oldAlert, newAlert}, time.Now())
+	changes := Diff(previous, current, after, []models.SpendingAlert{oldAlert, newAlert})
+
+	if changes.NewTransactionCount != 2 || changes.NewSpending != 70 {
+		t.Errorf("new transactions = %d totalling %.2f, want 2 totalling 70", changes.NewTransactionCount, changes.NewSpending)
+	}
+	if changes.NewTransactions[0].Description != "CINEMA" {
+		t.Errorf("newest transaction should be listed first, got %s", changes.NewTransactions[0].Description)
+	}
+	if len(changes.NewAlerts) != 1 || changes.NewAlerts[0].Title != "Unusual spending" {
+		t.Errorf("new alerts = %+v", changes.NewAlerts)
+	}
+	if len(changes.CategoryDeltas) != 2 || changes.CategoryDeltas[0].Name != "Groceries" || changes.CategoryDeltas[0].Change != 50 {
+		t.Errorf("category deltas = %+v, want Groceries +50 first", changes.CategoryDeltas)
+	}
+
+	// Nothing changed: nothing to show
+	if same := Diff(current, current, after, []models.SpendingAlert{oldAlert, newAlert}); same.HasChanges() {
+		t.Errorf("identical snapshots reported changes: %+v", same)
+	}
+}
@@ -0,0 +1,176 @@
+package warmup
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Step is one unit of startup work, e.g. parsing CSV data
+type Step struct {
+	Name string
+	Run  func() error
+}
+
+// StepStatus reports how a step went
+type StepStatus struct {
+	Name     string `json:"name"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// Status is the readiness snapshot reported by /api/health
+type Status struct {
+	Ready bool         `json:"ready"`
+	Steps []StepStatus `json:"steps,omitempty"`
+	// Refresh reports the scheduled refresh, when one is set up
+	Refresh *RefreshStatus `json:"refresh,omitempty"`
+}
+
+// RefreshStatus reports how the scheduled refresh is going
+type RefreshStatus struct {
+	Interval string       `json:"interval"`
+	LastRun  *time.Time   `json:"last_run,omitempty"` // nil until the first refresh finishes
+	Steps    []StepStatus `json:"steps"`
+}
+
+// Warmer runs startup steps in the background so the first page load after a
+// restart doesn't pay for parsing and analysis. A warmer with no steps is
+// ready immediately, which is the behaviour when warm-up is disabled.
+type Warmer struct {
+	mu     sync.Mutex
+	steps  []Step
+	status []StepStatus
+	ready  bool
+
+	// Scheduled refresh, set up by Refresh
+	interval  time.Duration
+	refresh   []Step
+	refreshed []StepStatus
+	lastRun   time.Time
+}
+
+// New creates a warmer for steps, run in order
+func New(steps ...Step) *Warmer {
+	status := make([]StepStatus, len(steps))
+	for i, s := range steps {
+		status[i].Name = s.Name
+	}
+	return &Warmer{
+		steps:  steps,
+		status: status,
+		ready:  len(steps) == 0,
+	}
+}
+
+// Refresh schedules steps to run every interval after the startup steps
+// finish, so precomputed results stay current without a request paying to
+// rebuild them. The startup steps should precompute the same results. Call
+// it before Start.
+func (w *Warmer) Refresh(interval time.Duration, steps ...Step) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.interval = interval
+	w.refresh = steps
+	w.refreshed = make([]StepStatus, len(steps))
+	for i, s := range steps {
+		w.refreshed[i].Name = s.Name
+	}
+}
+
+// Start runs the steps in a background goroutine, then the scheduled
+// refresh if there is one
+func (w *Warmer) Start() {
+	go func() {
+		w.Run()
+		if len(w.refresh) == 0 || w.interval <= 0 {
+			return
+		}
+		for {
+			time.Sleep(w.interval)
+			w.RunRefresh()
+		}
+	}()
+}
+
+// RunRefresh runs the refresh steps once. Like Run, a failing step is
+// logged and recorded without stopping the rest.
+func (w *Warmer) RunRefresh() {
+	for i, step := range w.refresh {
+		stepStart := time.Now()
+		err := step.Run()
+		elapsed := time.Since(stepStart).Round(time.Millisecond)
+
+		w.mu.Lock()
+		w.refreshed[i] = StepStatus{Name: step.Name, Done: true, Duration: elapsed.String()}
+		if err != nil {
+			w.refreshed[i].Error = err.Error()
+			log.Printf("Warning: refresh step %s failed: %v", step.Name, err)
+		}
+		w.mu.Unlock()
+	}
+
+	w.mu.Lock()
+	w.lastRun = time.Now()
+	w.mu.Unlock()
+}
+
+// Run executes every step and marks the warmer ready. A failing step is
+// logged and recorded but doesn't stop the rest: the server still works,
+// it's just slower on first use.
+func (w *Warmer) Run() {
+	begin := time.Now()
+	for i, step := range w.steps {
+		stepStart := time.Now()
+		err := step.Run()
+		elapsed := time.Since(stepStart).Round(time.Millisecond)
+
+		w.mu.Lock()
+		w.status[i].Done = true
+		w.status[i].Duration = elapsed.String()
+		if err != nil {
+			w.status[i].Error = err.Error()
+			log.Printf("Warning: warm-up step %s failed: %v", step.Name, err)
+		}
+		w.mu.Unlock()
+	}
+
+	w.mu.Lock()
+	w.ready = true
+	w.mu.Unlock()
+
+	if len(w.steps) > 0 {
+		log.Printf("Warm-up complete in %s", time.Since(begin).Round(time.Millisecond))
+	}
+}
+
+// Ready reports whether every step has finished
+func (w *Warmer) Ready() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.ready
+}
+
+// Status returns a snapshot of readiness and per-step progress
+func (w *Warmer) Status() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	steps := make([]StepStatus, len(w.status))
+	copy(steps, w.status)
+	status := Status{Ready: w.ready, Steps: steps}
+
+	if len(w.refresh) > 0 {
+		refresh := &RefreshStatus{Interval: w.interval.String(), Steps: make([]StepStatus, len(w.refreshed))}
+		copy(refresh.Steps, w.refreshed)
+		if !w.lastRun.IsZero() {
+			lastRun := w.lastRun
+			refresh.LastRun = &lastRun
+		}
+		status.Refresh = refresh
+	}
+	return status
+}
@@ -0,0 +1,71 @@
+package warmup
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWarmerWithoutStepsIsReady(t *testing.T) {
+	if !New().Ready() {
+		t.Error("a warmer with no steps should be ready immediately")
+	}
+}
+
+func TestRefreshReportsSteps(t *testing.T) {
+	w := New()
+	if w.Status().Refresh != nil {
+		t.Fatal("a warmer without a refresh shouldn't report one")
+	}
+
+	runs := 0
+	w.Refresh(time.Hour,
+		Step{Name: "dashboard", Run: func() error { runs++; return nil }},
+		Step{Name: "insights", Run: func() error { return errors.New("no data") }},
+	)
+	refresh := w.Status().Refresh
+	if refresh == nil || refresh.Interval != "1h0m0s" || refresh.LastRun != nil || refresh.Steps[0].Done {
+		t.Fatalf("before a refresh got %+v", refresh)
+	}
+
+	w.RunRefresh()
+	w.RunRefresh()
+	refresh = w.Status().Refresh
+	if runs != 2 || refresh.LastRun == nil {
+		t.Errorf("ran %d times, last run %v", runs, refresh.LastRun)
+	}
+	if !refresh.Steps[0].Done || refresh.Steps[0].Error != "" || refresh.Steps[1].Error != "no data" {
+		t.Errorf("unexpected refresh steps: %+v", refresh.Steps)
+	}
+}
+
+func TestRunRecordsStepsAndBecomesReady(t *testing.T) {
+	var order []string
+	w := New(
+		Step{Name: "data", Run: func() error { order = append(order, "data"); return nil }},
+		Step{Name: "whatif", Run: func() error { order = append(order, "whatif"); return errors.New("no settings") }},
+		Step{Name: "insights", Run: func() error { order = append(order, "insights"); return nil }},
+	)
+
+	if w.Ready() {
+		t.Fatal("warmer should not be ready before running")
+	}
+	w.Run()
+
+	if !w.Ready() {
+		t.Error("warmer should be ready after running, even with a failed step")
+	}
+	if len(order) != 3 || order[0] != "data" || order[2] != "insights" {
+		t.Errorf("steps ran as %v, want data, whatif, insights", order)
+	}
+
+	status := w.Status()
+	for _, s := range status.Steps {
+		if !s.Done {
+			t.Errorf("step %s not marked done", s.Name)
+		}
+	}
+	if status.Steps[1].Error != "no settings" || status.Steps[0].Error != "" {
+		t.Errorf("unexpected step errors: %+v", status.Steps)
+	}
+}
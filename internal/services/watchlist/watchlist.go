@@ -0,0 +1,201 @@
+package watchlist
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+// watchlistFile holds the merchant watchlist
+var watchlistFile = storage.SettingsFile("watchlist.json", func() interface{} { return &[]models.WatchedMerchant{} })
+
+// WarnPercent is the share of a limit at which a merchant starts alerting
+const WarnPercent = 80.0
+
+// Manager persists the merchant watchlist
+type Manager struct {
+	path  string
+	store *storage.Storage
+	mu    sync.Mutex
+}
+
+// NewManager creates a manager storing the watchlist in settingsDir
+func NewManager(settingsDir string, store *storage.Storage) *Manager {
+	return &Manager{
+		path:  filepath.Join(settingsDir, watchlistFile),
+		store: store,
+	}
+}
+
+// List returns all watched merchants sorted by name
+func (m *Manager) List() ([]models.WatchedMerchant, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loadInternal()
+}
+
+// Add watches a merchant, replacing any earlier entry with the same name.
+// A blank match uses the name.
+func (m *Manager) Add(w models.WatchedMerchant) ([]models.WatchedMerchant, error) {
+	w.Name = strings.TrimSpace(w.Name)
+	w.Match = strings.TrimSpace(w.Match)
+	if w.Name == "" {
+		return nil, fmt.Errorf("merchant name is required")
+	}
+	if w.Match == "" {
+		w.Match = w.Name
+	}
+	if w.MonthlyLimit <= 0 {
+		return nil, fmt.Errorf("monthly limit must be positive, got %.2f", w.MonthlyLimit)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.WatchedMerchant, 0, len(list)+1)
+	for _, existing := range list {
+		if !strings.EqualFold(existing.Name, w.Name) {
+			filtered = append(filtered, existing)
+		}
+	}
+	filtered = append(filtered, w)
+	sortByName(filtered)
+
+	if err := m.store.WriteJSON(m.path, filtered); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// Remove stops watching a merchant by ID
+func (m *Manager) Remove(id string) ([]models.WatchedMerchant, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list, err := m.loadInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.WatchedMerchant, 0, len(list))
+	for _, w := range list {
+		if w.ID != id {
+			filtered = append(filtered, w)
+		}
+	}
+
+	if err := m.store.WriteJSON(m.path, filtered); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// loadInternal reads the watchlist without acquiring lock (caller must hold lock)
+func (m *Manager) loadInternal() ([]models.WatchedMerchant, error) {
+	var list []models.WatchedMerchant
+	if err := m.store.ReadJSON(m.path, &list); err != nil {
+		if os.IsNotExist(err) {
+			return []models.WatchedMerchant{}, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+func sortByName(list []models.WatchedMerchant) {
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+}
+
+// Evaluate totals month-to-date outflows for each watched merchant. The month
+// is the one holding the latest transaction in ts, so the watchlist follows
+// the data rather than the wall clock when imports lag behind.
+func Evaluate(list []models.WatchedMerchant, ts *models.TransactionSet) models.MerchantWatchSummary {
+	summary := models.MerchantWatchSummary{Merchants: []models.MerchantWatchStatus{}}
+
+	end := ts.MaxDate()
+	if end.IsZero() {
+		end = time.Now()
+	}
+	start := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, end.Location())
+	summary.Month = start.Format("Jan 2006")
+	summary.Start = start.Format("2006-01-02")
+	summary.End = end.Format("2006-01-02")
+
+	outflows := ts.FilterByDateRange(start, end).FilterByType(models.Outflow)
+
+	for _, w := range list {
+		status := models.MerchantWatchStatus{WatchedMerchant: w}
+		match := strings.ToLower(w.Match)
+		for _, t := range outflows.Transactions {
+			if strings.Contains(strings.ToLower(t.Description), match) {
+				status.Spent += math.Abs(t.Amount)
+				status.TransactionCount++
+			}
+		}
+
+		status.Remaining = w.MonthlyLimit - status.Spent
+		if w.MonthlyLimit > 0 {
+			status.Percent = status.Spent / w.MonthlyLimit * 100
+		}
+		switch {
+		case status.Percent > 100:
+			status.Level = models.WatchOver
+		case status.Percent >= WarnPercent:
+			status.Level = models.WatchWarning
+		default:
+			status.Level = models.WatchOK
+		}
+		summary.Merchants = append(summary.Merchants, status)
+	}
+
+	// Closest to (or furthest past) the limit first
+	sort.SliceStable(summary.Merchants, func(i, j int) bool {
+		return summary.Merchants[i].Percent > summary.Merchants[j].Percent
+	})
+	return summary
+}
+
+// Alerts turns merchants at or over their warning threshold into spending
+// alerts for the dashboard
+func Alerts(summary models.MerchantWatchSummary) []models.SpendingAlert {
+	var alerts []models.SpendingAlert
+	for _, s := range summary.Merchants {
+		switch s.Level {
+		case models.WatchOver:
+			alerts = append(alerts, models.SpendingAlert{
+				Type:     "merchant_limit_exceeded",
+				Severity: "error",
+				Title:    s.Name + " Over Limit",
+				Message:  fmt.Sprintf("$%.0f of $%.0f spent in %s ($%.0f over)", s.Spent, s.MonthlyLimit, summary.Month, -s.Remaining),
+				Detail:   s.Match,
+				Amount:   s.Spent,
+			})
+		case models.WatchWarning:
+			alerts = append(alerts, models.SpendingAlert{
+				Type:     "merchant_limit_warning",
+				Severity: "warning",
+				Title:    s.Name + " Near Limit",
+				Message:  fmt.Sprintf("$%.0f of $%.0f spent in %s (%.0f%%)", s.Spent, s.MonthlyLimit, summary.Month, s.Percent),
+				Detail:   s.Match,
+				Amount:   s.Spent,
+			})
+		}
+	}
+	return alerts
+}
@@ -0,0 +1,87 @@
+package watchlist
+
+import (
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+	"budget2/internal/services/storage"
+)
+
+func txn(date, desc string, amount float64) models.Transaction {
+	d, _ := time.Parse("2006-01-02", date)
+	t := models.Transaction{Date: d, Description: desc, Amount: amount, TransactionType: models.Outflow}
+	if amount > 0 {
+		t.TransactionType = models.Income
+	}
+	return t
+}
+
+func TestAddReplacesAndValidates(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.New(dir)
+	manager := NewManager(dir, store)
+
+	if _, err := manager.Add(models.WatchedMerchant{Name: "DoorDash"}); err == nil {
+		t.Error("expected error for missing limit")
+	}
+
+	manager.Add(models.WatchedMerchant{ID: "1", Name: "DoorDash", MonthlyLimit: 100})
+	list, err := manager.Add(models.WatchedMerchant{ID: "2", Name: "doordash", Match: "DOORDASH*", MonthlyLimit: 150})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if len(list) != 1 || list[0].MonthlyLimit != 150 {
+		t.Errorf("same name should replace the entry, got %+v", list)
+	}
+
+	list, _ = manager.Add(models.WatchedMerchant{ID: "3", Name: "Amazon", MonthlyLimit: 200})
+	if list[0].Name != "Amazon" || list[1].Match != "DOORDASH*" {
+		t.Errorf("list should be sorted by name and keep the match text, got %+v", list)
+	}
+	if list[0].Match != "Amazon" {
+		t.Errorf("blank match should default to the name, got %q", list[0].Match)
+	}
+
+	list, _ = manager.Remove("3")
+	if len(list) != 1 {
+		t.Errorf("Remove left %d merchants, want 1", len(list))
+	}
+}
+
+func TestEvaluateMonthToDate(t *testing.T) {
+	ts := models.NewTransactionSet([]models.Transaction{
+		txn("2025-02-20", "DOORDASH*TACOS", -90), // previous month
+		txn("2025-03-02", "DOORDASH*PIZZA", -40),
+		txn("2025-03-09", "DoorDash Sushi", -45),
+		txn("2025-03-10", "AMZN Mktp US", -30),
+		txn("2025-03-11", "AMAZON.COM", -250),
+		txn("2025-03-12", "AMAZON REFUND", 20),
+	})
+	list := []models.WatchedMerchant{
+		{Name: "DoorDash", Match: "doordash", MonthlyLimit: 100},
+		{Name: "Amazon", Match: "amazon", MonthlyLimit: 200},
+		{Name: "Uber", Match: "uber", MonthlyLimit: 50},
+	}
+
+	summary := Evaluate(list, ts)
+	if summary.Month != "Mar 2025" || summary.End != "2025-03-12" {
+		t.Errorf("month = %s through %s, want Mar 2025 through 2025-03-12", summary.Month, summary.End)
+	}
+
+	amazon, doordash, uber := summary.Merchants[0], summary.Merchants[1], summary.Merchants[2]
+	if amazon.Name != "Amazon" || amazon.Spent != 250 || amazon.Level != models.WatchOver {
+		t.Errorf("Amazon = %+v, want 250 spent and over", amazon)
+	}
+	if doordash.Spent != 85 || doordash.TransactionCount != 2 || doordash.Level != models.WatchWarning {
+		t.Errorf("DoorDash = %+v, want 85 across 2 transactions and a warning", doordash)
+	}
+	if uber.Spent != 0 || uber.Level != models.WatchOK {
+		t.Errorf("Uber = %+v, want nothing spent", uber)
+	}
+
+	alerts := Alerts(summary)
+	if len(alerts) != 2 || alerts[0].Severity != "error" || alerts[1].Severity != "warning" {
+		t.Errorf("alerts = %+v, want an error then a warning", alerts)
+	}
+}
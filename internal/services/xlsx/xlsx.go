@@ -0,0 +1,310 @@
+// Package xlsx writes simple Excel workbooks: named sheets of rows with bold
+// headers, a frozen header row, column widths and money, percent and date
+// formats. Text goes in a shared strings table, as Excel writes it, so a
+// workbook is a handful of XML parts in a ZIP file that Excel, LibreOffice,
+// Google Sheets and spreadsheet libraries all read.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Typed cell values pick their number format. Other values are written as
+// they are: strings as text, integers and floats as plain numbers and
+// time.Time as a date.
+type (
+	Money   float64 // Dollars, e.g. $1,234.50, negatives in red
+	Percent float64 // A share of 100, e.g. 12.5 shows as 12.5%
+	Bold    string  // Text in bold, for labels and section titles
+)
+
+// Cell styles, indexes into cellXfs in styles.xml
+const (
+	styleDefault = iota
+	styleBold
+	styleHeader
+	styleMoney
+	stylePercent
+	styleDate
+)
+
+// Workbook is a spreadsheet being built, one sheet after another
+type Workbook struct {
+	sheets []*Sheet
+
+	// The shared strings table: every distinct text, in first-use order,
+	// and each one's index
+	strings []string
+	index   map[string]int
+}
+
+// Sheet is one worksheet's rows
+type Sheet struct {
+	wb     *Workbook
+	name   string
+	widths []float64
+	rows   []string // <row> elements
+	frozen bool     // the first row is a header kept in view
+}
+
+// New starts an empty workbook
+func New() *Workbook {
+	return &Workbook{}
+}
+
+// shared returns the index of s in the shared strings table, adding it
+func (wb *Workbook) shared(s string) int {
+	if wb.index == nil {
+		wb.index = make(map[string]int)
+	}
+	i, ok := wb.index[s]
+	if !ok {
+		i = len(wb.strings)
+		wb.strings = append(wb.strings, s)
+		wb.index[s] = i
+	}
+	return i
+}
+
+// Sheet adds a sheet with the given name and column widths in characters.
+// Excel limits names to 31 characters without []:*?/\, so others are
+// shortened and cleaned.
+func (wb *Workbook) Sheet(name string, widths ...float64) *Sheet {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if name == "" {
+		name = fmt.Sprintf("Sheet%d", len(wb.sheets)+1)
+	}
+	s := &Sheet{wb: wb, name: name, widths: widths}
+	wb.sheets = append(wb.sheets, s)
+	return s
+}
+
+// Header writes a row of bold, shaded column titles. A header in the first
+// row stays in view while scrolling.
+func (s *Sheet) Header(titles ...string) {
+	if len(s.rows) == 0 {
+		s.frozen = true
+	}
+	var b strings.Builder
+	for i, title := range titles {
+		b.WriteString(s.text(ref(i, len(s.rows)), styleHeader, title))
+	}
+	s.addRow(b.String())
+}
+
+// Row writes a row of cells; nil leaves a cell empty
+func (s *Sheet) Row(cells ...interface{}) {
+	var b strings.Builder
+	for i, v := range cells {
+		b.WriteString(s.cell(ref(i, len(s.rows)), v))
+	}
+	s.addRow(b.String())
+}
+
+// Blank writes an empty row, e.g. between sections
+func (s *Sheet) Blank() {
+	s.addRow("")
+}
+
+func (s *Sheet) addRow(cells string) {
+	s.rows = append(s.rows, fmt.Sprintf(`<row r="%d">%s</row>`, len(s.rows)+1, cells))
+}
+
+// text renders a text cell at ref, pointing into the shared strings table
+func (s *Sheet) text(ref string, style int, text string) string {
+	return fmt.Sprintf(`<c r="%s" t="s" s="%d"><v>%d</v></c>`, ref, style, s.wb.shared(text))
+}
+
+// cell renders one value at ref
+func (s *Sheet) cell(ref string, v interface{}) string {
+	number := func(style int, n float64) string {
+		return fmt.Sprintf(`<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(n, 'f', -1, 64))
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s.text(ref, styleDefault, v)
+	case Bold:
+		return s.text(ref, styleBold, string(v))
+	case Money:
+		return number(styleMoney, float64(v))
+	case Percent:
+		return number(stylePercent, float64(v)/100)
+	case int:
+		return number(styleDefault, float64(v))
+	case float64:
+		return number(styleDefault, v)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return number(styleDate, serial(v))
+	default:
+		return s.text(ref, styleDefault, fmt.Sprint(v))
+	}
+}
+
+// excelEpoch is day zero of Excel's date serials, chosen so serials after
+// February 1900 match Excel's (which counts a February 29, 1900)
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// serial converts t's calendar date to an Excel date serial
+func serial(t time.Time) float64 {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return float64(day.Sub(excelEpoch) / (24 * time.Hour))
+}
+
+// ref names the cell in the zero-based column and row, e.g. "B3"
+func ref(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row+1)
+}
+
+// escape escapes text for XML, replacing characters XML can't hold
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// WriteTo writes the workbook as an .xlsx file
+func (wb *Workbook) WriteTo(w io.Writer) (int64, error) {
+	sheets := wb.sheets
+	if len(sheets) == 0 {
+		// A workbook needs at least one sheet to open
+		sheets = []*Sheet{{wb: wb, name: "Sheet1"}}
+	}
+
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	part := func(name, body string) error {
+		f, err := z.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(f, xml.Header+body)
+		return err
+	}
+
+	var overrides, entries, rels strings.Builder
+	for i, s := range sheets {
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&entries, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(s.name), i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1)
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings" Target="sharedStrings.xml"/>`, len(sheets)+2)
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			`<Override PartName="/xl/sharedStrings.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sharedStrings+xml"/>` +
+			overrides.String() + `</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` + entries.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+		{"xl/styles.xml", styles},
+		{"xl/sharedStrings.xml", wb.sharedStrings()},
+	}
+	for i, s := range sheets {
+		parts = append(parts, struct{ name, body string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), s.xml()})
+	}
+	for _, p := range parts {
+		if err := part(p.name, p.body); err != nil {
+			return 0, err
+		}
+	}
+	if err := z.Close(); err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// sharedStrings renders the shared strings table. Spaces are preserved so
+// text with leading or trailing spaces reads back as written.
+func (wb *Workbook) sharedStrings() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" uniqueCount="%d">`, len(wb.strings))
+	for _, s := range wb.strings {
+		fmt.Fprintf(&b, `<si><t xml:space="preserve">%s</t></si>`, escape(s))
+	}
+	b.WriteString("</sst>")
+	return b.String()
+}
+
+// xml renders the sheet's worksheet part
+func (s *Sheet) xml() string {
+	var b strings.Builder
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if s.frozen {
+		b.WriteString(`<sheetViews><sheetView workbookViewId="0">` +
+			`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>` +
+			`</sheetView></sheetViews>`)
+	}
+	if len(s.widths) > 0 {
+		b.WriteString("<cols>")
+		for i, w := range s.widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%g" customWidth="1"/>`, i+1, i+1, w)
+		}
+		b.WriteString("</cols>")
+	}
+	b.WriteString("<sheetData>")
+	for _, row := range s.rows {
+		b.WriteString(row)
+	}
+	b.WriteString("</sheetData></worksheet>")
+	return b.String()
+}
+
+// styles defines the number formats, fonts and fills behind the cell
+// styles, in the order of the style constants
+const styles = `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="3">` +
+	`<numFmt numFmtId="164" formatCode="&quot;$&quot;#,##0.00;[Red]\-&quot;$&quot;#,##0.00"/>` +
+	`<numFmt numFmtId="165" formatCode="0.0%"/>` +
+	`<numFmt numFmtId="166" formatCode="yyyy\-mm\-dd"/>` +
+	`</numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+	`<fill><patternFill patternType="solid"><fgColor rgb="FFE5E7EB"/><bgColor indexed="64"/></patternFill></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="6">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="166" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
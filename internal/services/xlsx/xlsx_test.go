@@ -0,0 +1,232 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkbook(t *testing.T) {
+	wb := New()
+	summary := wb.Sheet("Summary: 2025/Q1 with a name far too long for Excel", 20, 14)
+	summary.Row(Bold("Income"), Money(1234.5))
+	summary.Row("Savings rate", Percent(12.5))
+	txns := wb.Sheet("Transactions")
+	txns.Header("Date", "Description", "Amount")
+	txns.Row(time.Date(2025, 3, 1, 14, 0, 0, 0, time.Local), "Coffee & <cake>", Money(-4.25))
+
+	var buf bytes.Buffer
+	if _, err := wb.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("output isn't a ZIP file: %v", err)
+	}
+
+	parts := make(map[string]string)
+	for _, f := range z.File {
+		r, _ := f.Open()
+		body, _ := io.ReadAll(r)
+		r.Close()
+		parts[f.Name] = string(body)
+
+		// Every part must be well-formed XML
+		d := xml.NewDecoder(bytes.NewReader(body))
+		for {
+			if _, err := d.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: %v", f.Name, err)
+			}
+		}
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels",
+		"xl/styles.xml", "xl/sharedStrings.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Summary- 2025-Q1 with a name fa"`) {
+		t.Errorf("sheet name should be cleaned and cut to 31 characters: %s", parts["xl/workbook.xml"])
+	}
+
+	sheet1 := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A1" t="s" s="1"><v>0</v></c>`,
+		`<c r="B1" s="3"><v>1234.5</v></c>`,
+		`<c r="B2" s="4"><v>0.125</v></c>`,
+		`<col min="1" max="1" width="20" customWidth="1"/>`,
+	} {
+		if !strings.Contains(sheet1, want) {
+			t.Errorf("sheet1 missing %s", want)
+		}
+	}
+	if strings.Contains(sheet1, "<pane") {
+		t.Error("a sheet without a header row shouldn't freeze one")
+	}
+
+	sheet2 := parts["xl/worksheets/sheet2.xml"]
+	for _, want := range []string{
+		`state="frozen"`,
+		`<c r="A1" t="s" s="2"><v>2</v></c>`,
+		`<c r="A2" s="5"><v>45717</v></c>`, // 2025-03-01
+		`<c r="B2" t="s" s="0"><v>5</v></c>`,
+		`<c r="C2" s="3"><v>-4.25</v></c>`,
+	} {
+		if !strings.Contains(sheet2, want) {
+			t.Errorf("sheet2 missing %s", want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	texts := []string{
+		"Coffee & <cake>",
+		`AT&T "Wireless" <autopay>`,
+		"Café Déjà Vu",
+		"東京 ラーメン",
+		"Crème brûlée 🍮",
+		"  padded  ",
+		"Coffee & <cake>", // repeated text shares its table entry
+	}
+	wb := New()
+	s := wb.Sheet("R&D <café>")
+	s.Header("Description", "Amount")
+	for i, text := range texts {
+		s.Row(text, Money(-float64(i)))
+	}
+	var buf bytes.Buffer
+	if _, err := wb.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	sheets := readWorkbook(t, buf.Bytes())
+	rows, ok := sheets["R&D <café>"]
+	if !ok {
+		t.Fatalf("sheet names read back as %v", sheets)
+	}
+	if len(rows) != len(texts)+1 || rows[0][0] != "Description" {
+		t.Fatalf("read back %d rows starting %v, want a header and %d rows", len(rows), rows[0], len(texts))
+	}
+	for i, text := range texts {
+		if got := rows[i+1][0]; got != text {
+			t.Errorf("row %d reads back as %q, want %q", i+2, got, text)
+		}
+	}
+	if n := strings.Count(readPart(t, buf.Bytes(), "xl/sharedStrings.xml"), "<si>"); n != len(texts)+1 {
+		t.Errorf("shared strings table has %d entries, want %d distinct texts", n, len(texts)+1)
+	}
+}
+
+// readWorkbook reads every sheet's cells as a spreadsheet reader does:
+// sheets found through the workbook's relationships, and text cells looked
+// up in the shared strings table
+func readWorkbook(t *testing.T, data []byte) map[string][][]string {
+	t.Helper()
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Type   string `xml:"Type,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	var sst struct {
+		Items []struct {
+			Text string `xml:"t"`
+		} `xml:"si"`
+	}
+	unmarshal := func(name string, v interface{}) {
+		if err := xml.Unmarshal([]byte(readPart(t, data, name)), v); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	unmarshal("xl/workbook.xml", &workbook)
+	unmarshal("xl/_rels/workbook.xml.rels", &rels)
+
+	targets := make(map[string]string)
+	for _, r := range rels.Relationships {
+		targets[r.ID] = "xl/" + r.Target
+		if strings.HasSuffix(r.Type, "/sharedStrings") {
+			unmarshal("xl/"+r.Target, &sst)
+		}
+	}
+
+	sheets := make(map[string][][]string)
+	for _, entry := range workbook.Sheets {
+		var sheet struct {
+			Rows []struct {
+				Cells []struct {
+					Ref   string `xml:"r,attr"`
+					Type  string `xml:"t,attr"`
+					Value string `xml:"v"`
+				} `xml:"c"`
+			} `xml:"sheetData>row"`
+		}
+		unmarshal(targets[entry.RID], &sheet)
+
+		var rows [][]string
+		for _, row := range sheet.Rows {
+			var cells []string
+			for _, c := range row.Cells {
+				value := c.Value
+				if c.Type == "s" {
+					i, err := strconv.Atoi(c.Value)
+					if err != nil || i < 0 || i >= len(sst.Items) {
+						t.Fatalf("%s: cell %s points at shared string %q of %d", entry.Name, c.Ref, c.Value, len(sst.Items))
+					}
+					value = sst.Items[i].Text
+				}
+				cells = append(cells, value)
+			}
+			rows = append(rows, cells)
+		}
+		sheets[entry.Name] = rows
+	}
+	return sheets
+}
+
+// readPart returns a part of an .xlsx file
+func readPart(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("output isn't a ZIP file: %v", err)
+	}
+	f, err := z.Open(name)
+	if err != nil {
+		t.Fatalf("missing part %s", name)
+	}
+	defer f.Close()
+	body, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return string(body)
+}
+
+func TestRef(t *testing.T) {
+	for _, tc := range []struct {
+		col, row int
+		want     string
+	}{
+		{0, 0, "A1"}, {25, 9, "Z10"}, {26, 0, "AA1"}, {701, 0, "ZZ1"}, {702, 0, "AAA1"},
+	} {
+		if got := ref(tc.col, tc.row); got != tc.want {
+			t.Errorf("ref(%d, %d) = %s, want %s", tc.col, tc.row, got, tc.want)
+		}
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"budget2/internal/services/categories"
 )
 
 // Renderer handles template rendering
@@ -91,7 +93,10 @@ func getFuncMap() template.FuncMap {
 		"percentOf":      percentOf,
 		"percentDiff":    percentDiff,
 		"deref":          deref,
+		"inList":         inList,
 		"urlEncode":      url.PathEscape,
+		"categoryColor":  categories.Color,
+		"categoryIcon":   categories.Icon,
 	}
 }
 
@@ -614,6 +619,16 @@ func deref(v *float64) float64 {
 	return *v
 }
 
+// inList reports whether s is one of list
+func inList(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
 // isNonNegative returns true if v >= 0
 func isNonNegative(v float64) bool {
 	return v >= 0
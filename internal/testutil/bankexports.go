@@ -0,0 +1,161 @@
+package testutil
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"budget2/internal/models"
+)
+
+// BankExport is the CSV layout a bank uses for transaction downloads, so
+// tests can check the loader's column mappings against real-world files
+type BankExport struct {
+	Name   string // Short name used for file names, e.g. "chase"
+	Title  string // e.g. "Chase credit card"
+	Header []string
+
+	// ChargesPositive marks banks that list purchases as positive amounts,
+	// the reverse of SimpleBudget's convention
+	ChargesPositive bool
+
+	// HasCategory marks exports whose category column the loader reads
+	HasCategory bool
+
+	// Preamble and Footer are lines written around the table, such as
+	// blank lines and disclaimers the loader has to skip
+	Preamble []string
+	Footer   []string
+
+	row func(i int, t models.Transaction) []string
+}
+
+// BankExports are the layouts of the major banks' CSV downloads
+var BankExports = []BankExport{
+	{
+		Name:        "chase",
+		Title:       "Chase credit card",
+		Header:      []string{"Transaction Date", "Post Date", "Description", "Category", "Type", "Amount", "Memo"},
+		HasCategory: true,
+		row: func(i int, t models.Transaction) []string {
+			kind := "Sale"
+			if t.Amount > 0 {
+				kind = "Payment"
+			}
+			return []string{
+				t.Date.Format("01/02/2006"),
+				t.Date.AddDate(0, 0, 1).Format("01/02/2006"),
+				t.Description,
+				t.Category,
+				kind,
+				fmt.Sprintf("%.2f", t.Amount),
+				"",
+			}
+		},
+	},
+	{
+		Name:   "bofa",
+		Title:  "Bank of America credit card",
+		Header: []string{"Posted Date", "Reference Number", "Payee", "Address", "Amount"},
+		row: func(i int, t models.Transaction) []string {
+			return []string{
+				t.Date.Format("01/02/2006"),
+				fmt.Sprintf("2469216%016d", i+1),
+				t.Description,
+				"SEATTLE WA",
+				fmt.Sprintf("%.2f", t.Amount),
+			}
+		},
+	},
+	{
+		Name:            "amex",
+		Title:           "American Express",
+		Header:          []string{"Date", "Description", "Card Member", "Account #", "Amount"},
+		ChargesPositive: true,
+		row: func(i int, t models.Transaction) []string {
+			return []string{
+				t.Date.Format("01/02/2006"),
+				t.Description,
+				"JANE DOE",
+				"-41007",
+				fmt.Sprintf("%.2f", -t.Amount),
+			}
+		},
+	},
+	{
+		Name:  "fidelity",
+		Title: "Fidelity cash management account",
+		Header: []string{"Run Date", "Action", "Symbol", "Security Description", "Security Type",
+			"Quantity", "Price ($)", "Commission ($)", "Fees ($)", "Accrued Interest ($)", "Amount ($)", "Settlement Date"},
+		Preamble: []string{"", ""},
+		Footer: []string{
+			"",
+			`"The data and information in this spreadsheet is provided to you solely for your use and is not for distribution."`,
+			`"Date downloaded 01/02/2021 9:00 am"`,
+		},
+		row: func(i int, t models.Transaction) []string {
+			action := "DEBIT CARD PURCHASE " + t.Description
+			if t.Amount > 0 {
+				action = "DIRECT DEPOSIT " + t.Description
+			}
+			return []string{
+				t.Date.Format("01/02/2006"),
+				action,
+				"",
+				"No Description",
+				"Cash",
+				"0.000",
+				"",
+				"",
+				"",
+				"",
+				fmt.Sprintf("%.2f", t.Amount),
+				"",
+			}
+		},
+	},
+}
+
+// FindBankExport returns the layout with the given short name
+func FindBankExport(name string) (BankExport, bool) {
+	for _, b := range BankExports {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return BankExport{}, false
+}
+
+// Write renders transactions in the bank's layout
+func (b BankExport) Write(w io.Writer, transactions []models.Transaction) error {
+	for _, line := range b.Preamble {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write(b.Header)
+	for i, t := range transactions {
+		cw.Write(b.row(i, t))
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+
+	for _, line := range b.Footer {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CSV renders transactions in the bank's layout as a string
+func (b BankExport) CSV(transactions []models.Transaction) string {
+	var sb strings.Builder
+	b.Write(&sb, transactions)
+	return sb.String()
+}
@@ -0,0 +1,107 @@
+package testutil
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"budget2/internal/models"
+)
+
+// PerfBudgetEnv enables performance budget tests. They're skipped by default
+// because timings depend on the machine; CI runs them via `make test-perf`.
+const PerfBudgetEnv = "BUDGET_PERF_BUDGETS"
+
+// CheckBudget benchmarks fn and fails if a single operation takes longer than
+// budget. Budgets are deliberately loose (several times a typical laptop
+// timing) so they only trip on real regressions, not noisy runners.
+func CheckBudget(t *testing.T, budget time.Duration, fn func(b *testing.B)) {
+	t.Helper()
+	if os.Getenv(PerfBudgetEnv) == "" {
+		t.Skipf("set %s=1 to run performance budgets", PerfBudgetEnv)
+	}
+
+	result := testing.Benchmark(fn)
+	if result.N == 0 {
+		t.Fatal("benchmark did not run")
+	}
+	perOp := time.Duration(result.NsPerOp())
+	t.Logf("%s/op over %d runs (budget %s)", perOp, result.N, budget)
+	if perOp > budget {
+		t.Errorf("took %s/op, over the %s budget", perOp, budget)
+	}
+}
+
+// syntheticMerchants cycles through a mix of one-off and recurring payees
+var syntheticMerchants = []struct {
+	description string
+	category    string
+	amount      float64
+}{
+	{"WALMART GROCERY", "Groceries", -87.34},
+	{"SHELL GAS STATION", "Gas & Fuel", -45.23},
+	{"AMAZON MKTPLACE", "Shopping", -32.10},
+	{"STARBUCKS", "Coffee Shops", -6.45},
+	{"CHIPOTLE", "Restaurants", -14.20},
+	{"TARGET", "Shopping", -58.99},
+	{"CVS PHARMACY", "Pharmacy", -21.75},
+	{"UBER TRIP", "Rideshare", -18.40},
+}
+
+// SyntheticTransactions returns n days of realistic activity starting at
+// 2020-01-01: a few purchases a day, semi-monthly paychecks and monthly rent
+// and subscriptions, so recurring detection and charts have work to do.
+func SyntheticTransactions(days int) []models.Transaction {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var txns []models.Transaction
+
+	add := func(date time.Time, desc, category string, amount float64) {
+		t := models.Transaction{
+			Date:            date,
+			Description:     desc,
+			Category:        category,
+			Amount:          amount,
+			TransactionType: models.Outflow,
+			SourceFile:      "synthetic.csv",
+		}
+		if amount > 0 {
+			t.TransactionType = models.Income
+		}
+		t.Hash = t.ComputeHash()
+		t.ID = t.Hash
+		t.ComputeDerivedFields()
+		txns = append(txns, t)
+	}
+
+	for d := 0; d < days; d++ {
+		date := start.AddDate(0, 0, d)
+		switch date.Day() {
+		case 1:
+			add(date, "RENT PAYMENT APT 204", "Rent", -1850)
+			add(date, "DIRECT DEP ACME CORP PAYROLL", "Paycheck", 3500)
+		case 15:
+			add(date, "DIRECT DEP ACME CORP PAYROLL", "Paycheck", 3500)
+			add(date, "NETFLIX SUBSCRIPTION", "Entertainment", -15.99)
+		case 20:
+			add(date, "SPOTIFY", "Entertainment", -10.99)
+		}
+		for i := 0; i < 3; i++ {
+			m := syntheticMerchants[(d*3+i)%len(syntheticMerchants)]
+			// Vary amounts so purchases don't look recurring or get deduplicated
+			add(date, m.description, m.category, m.amount-float64(d%17)-float64(i)*0.31)
+		}
+	}
+	return txns
+}
+
+// SyntheticCSV renders SyntheticTransactions as a bank export
+func SyntheticCSV(days int) string {
+	var sb strings.Builder
+	sb.WriteString("Date,Description,Amount,Category\n")
+	for _, t := range SyntheticTransactions(days) {
+		fmt.Fprintf(&sb, "%s,%s,%.2f,%s\n", t.Date.Format("2006-01-02"), t.Description, t.Amount, t.Category)
+	}
+	return sb.String()
+}
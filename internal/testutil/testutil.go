@@ -45,6 +45,33 @@ func TestDataDir() string {
 	return filepath.Join(ProjectRoot(), "testdata")
 }
 
+// SettingsDir returns a copy of testdata/settings in a temporary directory
+// removed when the test ends, so settings a test writes, or pages save as
+// they render, never touch the tracked fixtures
+func SettingsDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	src := filepath.Join(TestDataDir(), "settings")
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		t.Fatalf("reading test settings: %v", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(src, e.Name()))
+		if err != nil {
+			t.Fatalf("reading test settings: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, e.Name()), data, 0644); err != nil {
+			t.Fatalf("copying test settings: %v", err)
+		}
+	}
+	return dir
+}
+
 // TestConfig returns a config suitable for testing
 func TestConfig() map[string]string {
 	root := ProjectRoot()
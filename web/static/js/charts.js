@@ -106,6 +106,55 @@ function renderChart(containerId, chartData) {
             }
         });
     }
+
+    // Treemap parents open their subcategories; categories without any open
+    // their spending in the explorer
+    if (containerId === 'chart-treemap') {
+        const container = document.getElementById(containerId);
+        container.on('plotly_treemapclick', function(eventData) {
+            const point = eventData.points && eventData.points[0];
+            const form = document.getElementById('date-filter-form');
+            if (!point || !point.customdata || !form) {
+                return;
+            }
+            const start = form.querySelector('input[name="start"]').value;
+            const end = form.querySelector('input[name="end"]').value;
+            window.location.href = `/explorer?type=Outflow&category=${encodeURIComponent(point.id)}&start=${start}&end=${end}`;
+            return false;
+        });
+    }
+
+    // Income slices open that category's income in the explorer
+    if (containerId === 'chart-income') {
+        const container = document.getElementById(containerId);
+        container.on('plotly_click', function(eventData) {
+            if (eventData.points && eventData.points.length > 0) {
+                const category = eventData.points[0].label;
+                const form = document.getElementById('date-filter-form');
+                if (category && category !== 'Other' && form) {
+                    const start = form.querySelector('input[name="start"]').value;
+                    const end = form.querySelector('input[name="end"]').value;
+                    window.location.href = `/explorer?type=Income&category=${encodeURIComponent(category)}&start=${start}&end=${end}`;
+                }
+            }
+        });
+    }
+
+    // Account bars open that account's transactions in the explorer
+    if (containerId === 'chart-accounts') {
+        const container = document.getElementById(containerId);
+        container.on('plotly_click', function(eventData) {
+            if (eventData.points && eventData.points.length > 0) {
+                const account = eventData.points[0].x;
+                const form = document.getElementById('date-filter-form');
+                if (account && form) {
+                    const start = form.querySelector('input[name="start"]').value;
+                    const end = form.querySelector('input[name="end"]').value;
+                    window.location.href = `/explorer?account=${encodeURIComponent(account)}&start=${start}&end=${end}`;
+                }
+            }
+        });
+    }
 }
 
 /**
@@ -113,8 +162,9 @@ function renderChart(containerId, chartData) {
  * @param {string} containerId - The ID of the container element
  * @param {number[]} values - The data values
  * @param {string} color - The line color
+ * @param {number[]} [yoyValues] - The same months a year earlier, drawn as a dashed overlay
  */
-function renderSparkline(containerId, values, color) {
+function renderSparkline(containerId, values, color, yoyValues) {
     const container = document.getElementById(containerId);
     if (!container || !values || values.length === 0) {
         return;
@@ -132,6 +182,19 @@ function renderSparkline(containerId, values, color) {
         fillcolor: (color || '#6366f1') + '20'
     }];
 
+    if (yoyValues && yoyValues.length === values.length) {
+        data.unshift({
+            type: 'scatter',
+            mode: 'lines',
+            y: yoyValues,
+            line: {
+                color: '#9ca3af',
+                width: 1,
+                dash: 'dot'
+            }
+        });
+    }
+
     const layout = {
         margin: { t: 0, r: 0, b: 0, l: 0 },
         paper_bgcolor: 'transparent',
@@ -197,12 +260,14 @@ function initSparklines() {
     document.querySelectorAll('[id^="sparkline-"]').forEach(function(el) {
         const valuesAttr = el.getAttribute('data-values');
         const color = el.getAttribute('data-color') || '#6366f1';
+        const yoyAttr = el.getAttribute('data-yoy');
 
         if (valuesAttr && valuesAttr !== 'null' && valuesAttr !== '[]') {
             try {
                 const values = JSON.parse(valuesAttr);
+                const yoy = yoyAttr ? JSON.parse(yoyAttr) : null;
                 if (values && values.length > 0) {
-                    renderSparkline(el.id, values, color);
+                    renderSparkline(el.id, values, color, yoy);
                 }
             } catch (e) {
                 console.error('Error parsing sparkline data:', e);
@@ -1,17 +1,30 @@
 // Dashboard-specific JavaScript functionality
 
+// sourcesParam carries a temporary source-file filter into detail requests
+function sourcesParam(form) {
+    const input = form.querySelector('input[name="sources"]');
+    return input && input.value ? '&sources=' + encodeURIComponent(input.value) : '';
+}
+
 // Category drilldown functions
 function openCategoryDrilldown(category) {
     const form = document.getElementById('date-filter-form');
     const start = form.querySelector('input[name="start"]').value;
     const end = form.querySelector('input[name="end"]').value;
 
-    htmx.ajax('GET', `/dashboard/category/${encodeURIComponent(category)}?start=${start}&end=${end}`, {
+    htmx.ajax('GET', `/dashboard/category/${encodeURIComponent(category)}?start=${start}&end=${end}` + sourcesParam(form), {
         target: '#category-drilldown-container',
         swap: 'innerHTML'
     });
 }
 
+function exportCategoryToCSV(category) {
+    const form = document.getElementById('date-filter-form');
+    const start = form.querySelector('input[name="start"]').value;
+    const end = form.querySelector('input[name="end"]').value;
+    window.location.href = `/dashboard/category/${encodeURIComponent(category)}/export?start=${start}&end=${end}` + sourcesParam(form);
+}
+
 function closeCategoryModal(event) {
     if (event && event.target !== event.currentTarget) return;
     document.getElementById('category-drilldown-container').innerHTML = '';
@@ -22,8 +35,10 @@ function openKPIDetail(kpiType) {
     const form = document.getElementById('date-filter-form');
     const start = form.querySelector('input[name="start"]').value;
     const end = form.querySelector('input[name="end"]').value;
+    const comparison = form.querySelector('select[name="comparison"]').value;
+    const compare = comparison ? '&comparison=' + encodeURIComponent(comparison) : '';
 
-    htmx.ajax('GET', `/dashboard/kpi/${encodeURIComponent(kpiType)}?start=${start}&end=${end}`, {
+    htmx.ajax('GET', `/dashboard/kpi/${encodeURIComponent(kpiType)}?start=${start}&end=${end}` + compare + sourcesParam(form), {
         target: '#kpi-detail-container',
         swap: 'innerHTML'
     });
@@ -38,7 +53,7 @@ function exportKPIToCSV(kpiType) {
     const form = document.getElementById('date-filter-form');
     const start = form.querySelector('input[name="start"]').value;
     const end = form.querySelector('input[name="end"]').value;
-    window.location.href = `/dashboard/kpi/${encodeURIComponent(kpiType)}/export?start=${start}&end=${end}`;
+    window.location.href = `/dashboard/kpi/${encodeURIComponent(kpiType)}/export?start=${start}&end=${end}` + sourcesParam(form);
 }
 
 // Close modal on escape key
@@ -108,7 +123,7 @@ function setPreset(preset) {
     });
 
     // Update each chart
-    const charts = ['monthly', 'category', 'cashflow', 'merchants', 'weekly', 'cumulative'];
+    const charts = ['monthly', 'category', 'treemap', 'income', 'accounts', 'cashflow', 'merchants', 'weekly', 'cumulative'];
     charts.forEach(function(chart) {
         htmx.ajax('GET', '/dashboard/charts/data/' + chart + '?' + params, {
             target: '#chart-' + chart,
@@ -196,11 +211,12 @@ function handleFileSelect(files) {
     if (files.length === 0) return;
 
     const file = files[0];
-    const isCSV = file.name.toLowerCase().endsWith('.csv');
-    const isZIP = file.name.toLowerCase().endsWith('.zip');
+    const name = file.name.toLowerCase();
+    const isData = name.endsWith('.csv') || name.endsWith('.qif');
+    const isZIP = name.endsWith('.zip');
 
-    if (!isCSV && !isZIP) {
-        showDropZoneState('error', 'Only CSV or ZIP backup files are accepted');
+    if (!isData && !isZIP) {
+        showDropZoneState('error', 'Only CSV, QIF or ZIP backup files are accepted');
         return;
     }
 